@@ -0,0 +1,204 @@
+//go:build linux || darwin
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/renameio/v2"
+)
+
+// Adopt defaults
+const (
+	// DefaultAdoptStopTimeout bounds waiting for the original process to exit
+	DefaultAdoptStopTimeout = 10 * time.Second
+	// DefaultAdoptTimeout bounds waiting for supervision and the new process
+	DefaultAdoptTimeout = 30 * time.Second
+)
+
+// AdoptOptions configures Adopt
+type AdoptOptions struct {
+	// Name is the service name
+	Name string
+	// Dir is the directory the service directory is created in; it is
+	// either a scan directory or, with ScanDir set, a directory such as
+	// DefaultSvDir that services are linked from
+	Dir string
+	// ScanDir, when set, is the scan directory the service is linked into
+	// with EnableService
+	ScanDir string
+
+	// Cmd is the command to supervise; empty reads the process's command
+	// line from /proc
+	Cmd []string
+	// Cwd is the working directory; empty reads the process's from /proc
+	Cwd string
+	// Configure adjusts the service before it is built (environment,
+	// chpst, logging, ...)
+	Configure func(*ServiceBuilder)
+
+	// StopSignal asks the original process to exit; zero means SIGTERM
+	StopSignal syscall.Signal
+	// StopTimeout bounds waiting for the original process to exit
+	StopTimeout time.Duration
+	// Timeout bounds waiting for the supervisor and the new process
+	Timeout time.Duration
+	// ProcDir is the proc filesystem; empty means DefaultProcDir
+	ProcDir string
+	// PollInterval is how often progress is checked
+	PollInterval time.Duration
+}
+
+// AdoptResult reports a completed handoff
+type AdoptResult struct {
+	// ServiceDir is the created service directory
+	ServiceDir string
+	// OldPID is the hand-started process that was replaced
+	OldPID int
+	// NewPID is the supervised process that replaced it
+	NewPID int
+}
+
+// Adopt brings a hand-started daemon under supervision. It generates a
+// service directory for the process's command line with ServiceBuilder,
+// marked down so the supervisor does not start a second copy, waits for the
+// supervisor to pick it up, stops the original process, starts the
+// supervised one and verifies it is running under a new PID. The down file
+// is then removed so the service starts at boot.
+//
+// The daemon is briefly unavailable between the original process exiting
+// and the supervised one starting. On failure after the original process
+// was stopped, the supervised service is left in place for inspection.
+func Adopt(ctx context.Context, pid int, opts AdoptOptions) (*AdoptResult, error) {
+	if err := validServiceName(opts.Name); err != nil {
+		return nil, err
+	}
+	procDir := opts.ProcDir
+	if procDir == "" {
+		procDir = DefaultProcDir
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultReadyPollInterval
+	}
+
+	cmd, cwd := opts.Cmd, opts.Cwd
+	if len(cmd) == 0 {
+		info, err := readProcessInfo(procDir, pid)
+		if err != nil {
+			return nil, fmt.Errorf("adopt: reading process %d: %w", pid, err)
+		}
+		if len(info.Args) == 0 {
+			return nil, fmt.Errorf("adopt: process %d has no command line", pid)
+		}
+		cmd = info.Args
+	}
+	if cwd == "" {
+		cwd, _ = os.Readlink(filepath.Join(procDir, strconv.Itoa(pid), "cwd"))
+	}
+
+	serviceDir, err := filepath.Abs(filepath.Join(opts.Dir, opts.Name))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(serviceDir); err == nil {
+		return nil, fmt.Errorf("adopt: %s already exists", serviceDir)
+	}
+
+	// The supervisor must not start a second copy while the original runs
+	if err := os.MkdirAll(serviceDir, DirMode); err != nil {
+		return nil, fmt.Errorf("adopt: creating service directory: %w", err)
+	}
+	downFile := filepath.Join(serviceDir, "down")
+	if err := renameio.WriteFile(downFile, nil, FileMode); err != nil {
+		return nil, fmt.Errorf("adopt: writing down file: %w", err)
+	}
+
+	b := NewServiceBuilder(opts.Name, opts.Dir).WithCmd(cmd)
+	if cwd != "" {
+		b.WithCwd(cwd)
+	}
+	if opts.Configure != nil {
+		opts.Configure(b)
+	}
+	if err := b.Build(); err != nil {
+		return nil, fmt.Errorf("adopt: building %s: %w", opts.Name, err)
+	}
+	if opts.ScanDir != "" {
+		if err := EnableService(opts.Dir, opts.ScanDir, opts.Name); err != nil {
+			return nil, fmt.Errorf("adopt: %w", err)
+		}
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAdoptTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var client ServiceClient
+	err = pollUntil(waitCtx, interval, func() (bool, error) {
+		c, err := NewClient(serviceDir)
+		if err != nil {
+			return false, nil
+		}
+		client = c
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adopt: waiting for %s to be supervised: %w", opts.Name, err)
+	}
+
+	if err := stopProcess(ctx, pid, opts.StopSignal, opts.StopTimeout, interval); err != nil {
+		return nil, fmt.Errorf("adopt: %w", err)
+	}
+
+	if err := client.Up(waitCtx); err != nil {
+		return nil, fmt.Errorf("adopt: starting %s: %w", opts.Name, err)
+	}
+	st, err := waitFunc(waitCtx, client, func(st Status) bool {
+		return st.State == StateRunning && st.PID > 0 && st.PID != pid
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adopt: waiting for %s to run: %w", opts.Name, err)
+	}
+
+	if err := os.Remove(downFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("adopt: removing down file: %w", err)
+	}
+	return &AdoptResult{ServiceDir: serviceDir, OldPID: pid, NewPID: st.PID}, nil
+}
+
+// stopProcess signals pid and waits until it has exited
+func stopProcess(ctx context.Context, pid int, sig syscall.Signal, timeout, interval time.Duration) error {
+	if sig == 0 {
+		sig = syscall.SIGTERM
+	}
+	if timeout <= 0 {
+		timeout = DefaultAdoptStopTimeout
+	}
+	if err := syscall.Kill(pid, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		return fmt.Errorf("signalling process %d: %w", pid, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := pollUntil(ctx, interval, func() (bool, error) {
+		return errors.Is(syscall.Kill(pid, 0), syscall.ESRCH), nil
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for process %d to exit: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdopt(t *testing.T) {
+	dir := t.TempDir()
+	serviceDir := filepath.Join(dir, "sleeper")
+
+	// A hand-started daemon, reaped as soon as it exits
+	daemon := exec.Command("sleep", "60")
+	daemon.Dir = dir
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = daemon.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() { _ = daemon.Process.Kill() })
+
+	// The fake scanner supervises the service once built and reports it
+	// running under a new PID when told to start
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		var mock *MockSupervisor
+		for ctx.Err() == nil {
+			time.Sleep(time.Millisecond)
+			if mock == nil {
+				if _, err := os.Stat(filepath.Join(serviceDir, "run")); err != nil {
+					continue
+				}
+				m, err := NewMockSupervisor(serviceDir)
+				if err != nil {
+					return
+				}
+				mock = m
+				continue
+			}
+			if data, _ := os.ReadFile(mock.ControlFile); len(data) > 0 && data[0] == OpUp.Byte() {
+				_ = mock.UpdateStatus(true, 4242)
+				return
+			}
+		}
+	}()
+
+	result, err := Adopt(ctx, daemon.Process.Pid, AdoptOptions{
+		Name:         "sleeper",
+		Dir:          dir,
+		PollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OldPID != daemon.Process.Pid || result.NewPID != 4242 || result.ServiceDir != serviceDir {
+		t.Errorf("result = %+v", result)
+	}
+
+	select {
+	case <-exited:
+	default:
+		t.Error("original process still running")
+	}
+	run, err := os.ReadFile(filepath.Join(serviceDir, "run"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(run), "cd "+dir) || !strings.Contains(string(run), "exec sleep 60\n") {
+		t.Errorf("run script does not reproduce the process:\n%s", run)
+	}
+	if _, err := os.Stat(filepath.Join(serviceDir, "down")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("down file left behind: %v", err)
+	}
+
+	if _, err := Adopt(ctx, daemon.Process.Pid, AdoptOptions{Name: "sleeper", Dir: dir}); err == nil {
+		t.Error("Adopt over an existing service succeeded")
+	}
+}
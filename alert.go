@@ -0,0 +1,345 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAlertInterval is how often AlertEngine.Run evaluates duration rules
+const DefaultAlertInterval = time.Second
+
+// AlertRule is a condition on a service's state or restart rate.
+// A rule with Within set counts restarts; otherwise it matches State.
+type AlertRule struct {
+	// Name labels the rule in alerts; ParseAlertRule uses the expression
+	Name string
+	// Services limits the rule to these service names; empty matches all
+	Services []string
+
+	// State is the state matched by a state rule
+	State State
+	// For is how long the service must stay in State before the rule fires
+	For time.Duration
+
+	// Restarts is the restart count a restart rule must exceed
+	Restarts int
+	// Within is the sliding window restarts are counted over
+	Within time.Duration
+}
+
+var (
+	alertStateExpr   = regexp.MustCompile(`^state\s*==?\s*(\w+)(?:\s+for\s*>?=?\s*(\S+))?$`)
+	alertRestartExpr = regexp.MustCompile(`^(?:>\s*(\d+)\s+restarts|restarts\s*>\s*(\d+))\s+(?:in|within)\s+(\S+)$`)
+)
+
+// ParseAlertRule parses a rule expression:
+//
+//	state==crashed for >30s   crashed for more than 30 seconds
+//	state==down               down at all
+//	>3 restarts in 5m         more than 3 restarts within 5 minutes
+//	restarts>3 in 5m          same as above
+func ParseAlertRule(expr string) (AlertRule, error) {
+	s := strings.ToLower(strings.TrimSpace(expr))
+	rule := AlertRule{Name: strings.TrimSpace(expr)}
+
+	if m := alertStateExpr.FindStringSubmatch(s); m != nil {
+		state, err := ParseState(m[1])
+		if err != nil {
+			return AlertRule{}, fmt.Errorf("alert rule %q: %w", expr, err)
+		}
+		rule.State = state
+		if m[2] != "" {
+			if rule.For, err = time.ParseDuration(m[2]); err != nil {
+				return AlertRule{}, fmt.Errorf("alert rule %q: %w", expr, err)
+			}
+		}
+		return rule, rule.Validate()
+	}
+
+	if m := alertRestartExpr.FindStringSubmatch(s); m != nil {
+		n := m[1] + m[2]
+		var err error
+		if rule.Restarts, err = strconv.Atoi(n); err != nil {
+			return AlertRule{}, fmt.Errorf("alert rule %q: %w", expr, err)
+		}
+		if rule.Within, err = time.ParseDuration(m[3]); err != nil {
+			return AlertRule{}, fmt.Errorf("alert rule %q: %w", expr, err)
+		}
+		return rule, rule.Validate()
+	}
+
+	return AlertRule{}, fmt.Errorf("alert rule %q: unrecognized expression", expr)
+}
+
+// Validate checks the rule's durations and threshold
+func (r AlertRule) Validate() error {
+	if r.For < 0 || r.Within < 0 || r.Restarts < 0 {
+		return fmt.Errorf("alert rule %q: negative duration or threshold", r.Name)
+	}
+	return nil
+}
+
+// restartRule reports whether r counts restarts
+func (r AlertRule) restartRule() bool {
+	return r.Within > 0
+}
+
+// matches reports whether r applies to service
+func (r AlertRule) matches(service string) bool {
+	return len(r.Services) == 0 || slices.Contains(r.Services, service)
+}
+
+// Alert is a fired rule
+type Alert struct {
+	// Rule is the rule that fired
+	Rule AlertRule `json:"-"`
+	// RuleName is Rule.Name
+	RuleName string `json:"rule"`
+	// Service names the service
+	Service string `json:"service"`
+	// Status is the service's latest status
+	Status Status `json:"status"`
+	// Restarts is the restart count in the window, for restart rules
+	Restarts int `json:"restarts,omitempty"`
+	// Time is when the rule fired
+	Time time.Time `json:"time"`
+}
+
+// AlertFunc handles a fired alert
+type AlertFunc func(Alert)
+
+// alertService tracks one service for rule evaluation
+type alertService struct {
+	status   Status
+	since    time.Time
+	lastPID  int // last non-zero PID, surviving the down states between runs
+	restarts []time.Time
+	fired    map[int]bool
+}
+
+// AlertEngine evaluates rules against service status changes and calls
+// OnAlert when a rule fires. A rule fires once per episode and re-arms when
+// its condition clears: when the service leaves the state, or when the
+// restart count drops back to the threshold.
+type AlertEngine struct {
+	// Rules are the rules evaluated
+	Rules []AlertRule
+	// OnAlert is called for each fired rule, without holding engine locks
+	OnAlert AlertFunc
+	// Interval is how often Run evaluates duration rules
+	Interval time.Duration
+	// Clock supplies the evaluation time; nil uses the wall clock
+	Clock Clock
+
+	mu       sync.Mutex
+	services map[string]*alertService
+}
+
+// NewAlertEngine creates an engine calling onAlert for rules
+func NewAlertEngine(onAlert AlertFunc, rules ...AlertRule) *AlertEngine {
+	return &AlertEngine{Rules: rules, OnAlert: onAlert, Interval: DefaultAlertInterval}
+}
+
+// Observe records the latest status of service and evaluates the rules
+func (e *AlertEngine) Observe(service string, st Status) {
+	e.observeAt(service, st, clockNow(e.Clock))
+}
+
+// observeAt is Observe with the status change happening at now
+func (e *AlertEngine) observeAt(service string, st Status, now time.Time) {
+	e.mu.Lock()
+	if e.services == nil {
+		e.services = make(map[string]*alertService)
+	}
+	s, ok := e.services[service]
+	if !ok {
+		s = &alertService{status: st, since: now, lastPID: st.PID, fired: make(map[int]bool)}
+		e.services[service] = s
+	} else {
+		if st.PID != 0 {
+			if s.lastPID != 0 && st.PID != s.lastPID {
+				s.restarts = append(s.restarts, now)
+			}
+			s.lastPID = st.PID
+		}
+		if st.State != s.status.State {
+			s.since = now
+		}
+		s.status = st
+	}
+	alerts := e.evaluate(service, s, now)
+	e.mu.Unlock()
+
+	e.fire(alerts)
+}
+
+// Tick evaluates the rules for every observed service, firing state rules
+// whose duration has elapsed since the last status change
+func (e *AlertEngine) Tick() {
+	e.tickAt(clockNow(e.Clock))
+}
+
+// tickAt is Tick evaluating at now
+func (e *AlertEngine) tickAt(now time.Time) {
+	e.mu.Lock()
+	var alerts []Alert
+	for name, s := range e.services {
+		alerts = append(alerts, e.evaluate(name, s, now)...)
+	}
+	e.mu.Unlock()
+
+	e.fire(alerts)
+}
+
+// Run watches every client, keyed by service name, feeding status changes
+// to Observe and calling Tick every Interval until ctx is done. It returns
+// ctx.Err(), the error from starting a watch, or ErrWatchClosed if every
+// watch ended first.
+func (e *AlertEngine) Run(ctx context.Context, clients map[string]ServiceClient) error {
+	for name, client := range clients {
+		if st, err := client.Status(ctx); err == nil {
+			e.Observe(name, st)
+		}
+	}
+
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultAlertInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.Tick()
+			}
+		}
+	}()
+
+	err := watchAll(ctx, clients, func(name string, _ Status, ev WatchEvent) {
+		if ev.Err == nil {
+			e.Observe(name, ev.Status)
+		}
+	})
+	cancel()
+	<-done
+	return err
+}
+
+// evaluate returns the rules newly firing for s; e.mu must be held
+func (e *AlertEngine) evaluate(service string, s *alertService, now time.Time) []Alert {
+	var alerts []Alert
+	for i, rule := range e.Rules {
+		if !rule.matches(service) {
+			continue
+		}
+
+		var active bool
+		var restarts int
+		if rule.restartRule() {
+			cutoff := now.Add(-rule.Within)
+			for _, t := range s.restarts {
+				if t.After(cutoff) {
+					restarts++
+				}
+			}
+			active = restarts > rule.Restarts
+		} else {
+			active = s.status.State == rule.State && now.Sub(s.since) >= rule.For
+		}
+
+		if !active {
+			if s.fired[i] && (rule.restartRule() || s.status.State != rule.State) {
+				delete(s.fired, i)
+			}
+			continue
+		}
+		if s.fired[i] {
+			continue
+		}
+		s.fired[i] = true
+		alerts = append(alerts, Alert{
+			Rule:     rule,
+			RuleName: rule.Name,
+			Service:  service,
+			Status:   s.status,
+			Restarts: restarts,
+			Time:     now,
+		})
+	}
+	s.restarts = pruneRestarts(s.restarts, now, e.longestWindow())
+	return alerts
+}
+
+// longestWindow is the longest restart window among the rules
+func (e *AlertEngine) longestWindow() time.Duration {
+	var d time.Duration
+	for _, r := range e.Rules {
+		d = max(d, r.Within)
+	}
+	return d
+}
+
+// pruneRestarts drops restarts older than window
+func pruneRestarts(restarts []time.Time, now time.Time, window time.Duration) []time.Time {
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(restarts) && !restarts[i].After(cutoff) {
+		i++
+	}
+	return restarts[i:]
+}
+
+// fire calls OnAlert for each alert
+func (e *AlertEngine) fire(alerts []Alert) {
+	if e.OnAlert == nil {
+		return
+	}
+	for _, a := range alerts {
+		e.OnAlert(a)
+	}
+}
+
+// WebhookAlerts returns an AlertFunc POSTing each alert as JSON through n,
+// with n's signing and retries. Delivery errors are passed to onError when
+// it is not nil.
+func WebhookAlerts(ctx context.Context, n *WebhookNotifier, onError func(error)) AlertFunc {
+	return func(a Alert) {
+		if err := n.NotifyAlert(ctx, a); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// errNoAlertRules is returned when an engine is built from no expressions
+var errNoAlertRules = errors.New("runit: no alert rules")
+
+// ParseAlertRules parses each expression with ParseAlertRule
+func ParseAlertRules(exprs ...string) ([]AlertRule, error) {
+	if len(exprs) == 0 {
+		return nil, errNoAlertRules
+	}
+	rules := make([]AlertRule, 0, len(exprs))
+	for _, expr := range exprs {
+		rule, err := ParseAlertRule(expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,107 @@
+package svcmgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    AlertRule
+		wantErr bool
+	}{
+		{expr: "state==crashed for >30s", want: AlertRule{State: StateCrashed, For: 30 * time.Second}},
+		{expr: "state == down", want: AlertRule{State: StateDown}},
+		{expr: ">3 restarts in 5m", want: AlertRule{Restarts: 3, Within: 5 * time.Minute}},
+		{expr: "restarts>1 within 1h", want: AlertRule{Restarts: 1, Within: time.Hour}},
+		{expr: "state==sleepy", wantErr: true},
+		{expr: "cpu > 90%", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAlertRule(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAlertRule(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		tt.want.Name = tt.expr
+		if got.Name != tt.want.Name || got.State != tt.want.State || got.For != tt.want.For ||
+			got.Restarts != tt.want.Restarts || got.Within != tt.want.Within {
+			t.Errorf("ParseAlertRule(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestAlertEngine(t *testing.T) {
+	rules, err := ParseAlertRules("state==crashed for >30s", ">2 restarts in 5m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var fired []Alert
+	e := NewAlertEngine(func(a Alert) { fired = append(fired, a) }, rules...)
+	e.Clock = ClockFunc(func() time.Time { return now })
+	advance := func(d time.Duration) { now = now.Add(d) }
+
+	// The third restart within the window fires the rule, the fourth does not fire again
+	for pid := 10; pid <= 13; pid++ {
+		e.Observe("web", Status{State: StateRunning, PID: pid})
+		advance(time.Minute)
+	}
+	e.Observe("web", Status{State: StateRunning, PID: 14})
+	if len(fired) != 1 || fired[0].RuleName != ">2 restarts in 5m" || fired[0].Restarts != 3 {
+		t.Fatalf("fired = %+v, want one restart alert", fired)
+	}
+
+	// Crashed fires only after 30s and only once per episode
+	fired = nil
+	e.Observe("web", Status{State: StateCrashed})
+	advance(10 * time.Second)
+	e.Tick()
+	if len(fired) != 0 {
+		t.Fatalf("fired early: %+v", fired)
+	}
+	advance(30 * time.Second)
+	e.Tick()
+	e.Tick()
+	if len(fired) != 1 || fired[0].RuleName != "state==crashed for >30s" {
+		t.Fatalf("fired = %+v, want one crashed alert", fired)
+	}
+
+	// Leaving the state re-arms the rule
+	fired = nil
+	e.Observe("web", Status{State: StateDown})
+	e.Observe("web", Status{State: StateCrashed})
+	advance(time.Minute)
+	e.Tick()
+	if len(fired) != 1 {
+		t.Errorf("re-armed rule fired %d times, want 1", len(fired))
+	}
+}
+
+func TestAlertEngineRestartsThroughDown(t *testing.T) {
+	rule, err := ParseAlertRule(">1 restarts in 5m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var fired []Alert
+	e := NewAlertEngine(func(a Alert) { fired = append(fired, a) }, rule)
+	e.Clock = ClockFunc(func() time.Time { return now })
+
+	// crash -> down (no PID) -> up again is the usual restart
+	e.Observe("web", Status{State: StateRunning, PID: 10})
+	for pid := 11; pid <= 12; pid++ {
+		now = now.Add(time.Minute)
+		e.Observe("web", Status{State: StateCrashed})
+		e.Observe("web", Status{State: StateDown})
+		e.Observe("web", Status{State: StateRunning, PID: pid})
+	}
+	if len(fired) != 1 || fired[0].Restarts != 2 {
+		t.Fatalf("fired = %+v, want one alert counting 2 restarts", fired)
+	}
+}
@@ -0,0 +1,223 @@
+package svcmgr
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// runtimeDirs are the paths, relative to the service directory, that
+// supervisors create at run time and that are never archived, whether
+// directories or symlinks into /run: supervise holds status and control
+// files, event is s6's fifodir, for the service and its log service
+var runtimeDirs = []string{SuperviseDir, "event", "log/" + SuperviseDir, "log/event"}
+
+// inRuntimeDir reports whether the slash-separated path rel, relative to
+// the service directory, is or lies inside one of runtimeDirs
+func inRuntimeDir(rel string) bool {
+	for _, dir := range runtimeDirs {
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreOptions configures Restore
+type RestoreOptions struct {
+	// StopStart takes a supervised destination service down before
+	// restoring, waiting for it to stop, and brings it back up afterwards
+	// if it was running or wanted up
+	StopStart bool
+}
+
+// Backup writes a tar archive of the service directory dir to w, with
+// paths relative to dir. Supervisor runtime state, the supervise and s6
+// event directories of the service and its log service, is left out, as
+// are FIFOs, sockets and devices; files keep their modes and modification
+// times and symlinks are stored as links.
+func Backup(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if inRuntimeDir(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular() && !info.IsDir():
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		// Ownership is the destination's business
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("backup %s: %w", dir, err)
+	}
+	return tw.Close()
+}
+
+// Restore extracts an archive written by Backup into destDir, creating it
+// if needed. Files are replaced atomically and files not in the archive are
+// kept. Entries escaping destDir, also through symlinks the archive holds,
+// or inside runtime directories are rejected. With StopStart the service is
+// stopped around the restore.
+func Restore(ctx context.Context, r io.Reader, destDir string, opts RestoreOptions) (err error) {
+	if opts.StopStart {
+		if client, cerr := NewClient(destDir); cerr == nil {
+			st, serr := client.Status(ctx)
+			wasUp := serr == nil && (st.State == StateRunning || st.Flags.WantUp)
+			if err := client.Down(ctx); err != nil {
+				return fmt.Errorf("stopping %s: %w", destDir, err)
+			}
+			stopped := func(st Status) bool { return st.State == StateDown || st.State == StateExited }
+			if _, err := waitFunc(ctx, client, stopped); err != nil {
+				return fmt.Errorf("waiting for %s to stop: %w", destDir, err)
+			}
+			if wasUp {
+				defer func() {
+					if uerr := client.Up(ctx); uerr != nil && err == nil {
+						err = fmt.Errorf("starting %s: %w", destDir, uerr)
+					}
+				}()
+			}
+		}
+	}
+
+	if err := os.MkdirAll(destDir, DirMode); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	// Every path is resolved within root, so symlinks the archive plants,
+	// even pointing outside, can never be written through
+	root, err := os.OpenRoot(destDir)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("restore: %w", err)
+		}
+		if err := restoreEntry(tr, hdr, root); err != nil {
+			return fmt.Errorf("restore %s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// restoreEntry extracts one archive entry into root
+func restoreEntry(tr *tar.Reader, hdr *tar.Header, root *os.Root) error {
+	name := path.Clean(strings.TrimSuffix(hdr.Name, "/"))
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return errors.New("path escapes the destination")
+	}
+	if inRuntimeDir(name) {
+		return errors.New("path is inside a supervisor runtime directory")
+	}
+	if err := root.MkdirAll(path.Dir(name), DirMode); err != nil {
+		return err
+	}
+	// Files and links are created under a temporary name and renamed over
+	// the target, so they replace it atomically
+	tmp := path.Join(path.Dir(name), ".restore-"+path.Base(name))
+	if err := root.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	mode := hdr.FileInfo().Mode().Perm()
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := root.MkdirAll(name, mode); err != nil {
+			return err
+		}
+		return root.Chmod(name, mode)
+	case tar.TypeReg:
+		f, err := root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		if serr := f.Sync(); err == nil {
+			err = serr
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = root.Chmod(tmp, mode)
+		}
+		if err == nil {
+			err = root.Chtimes(tmp, hdr.ModTime, hdr.ModTime)
+		}
+		if err == nil {
+			err = root.Rename(tmp, name)
+		}
+		if err != nil {
+			_ = root.Remove(tmp)
+		}
+		return err
+	case tar.TypeSymlink:
+		if err := root.Symlink(hdr.Linkname, tmp); err != nil {
+			return err
+		}
+		if err := root.Rename(tmp, name); err != nil {
+			_ = root.Remove(tmp)
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported entry type %q", hdr.Typeflag)
+	}
+}
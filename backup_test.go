@@ -0,0 +1,189 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupRestore(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "web")
+	b := NewServiceBuilder("web", filepath.Dir(src)).
+		WithCmd([]string{"/usr/bin/web"}).
+		WithEnv("PORT", "8080").
+		WithSvlogd(func(*ConfigSvlogd) {})
+	if err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMockSupervisor(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(src, "log", SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/web.conf", filepath.Join(src, "conf")); err != nil {
+		t.Fatal(err)
+	}
+	// Files only named like runtime directories are service data
+	if err := os.WriteFile(filepath.Join(src, "env", "event"), []byte("deploy\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(src, "data", "event"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "data", "event", "supervise"), []byte("app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := Backup(src, &archive); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "restored")
+	if err := Restore(context.Background(), bytes.NewReader(archive.Bytes()), dest, RestoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"run", filepath.Join("env", "PORT"), filepath.Join("log", "run"),
+		filepath.Join("env", "event"), filepath.Join("data", "event", "supervise")} {
+		want, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dest, name))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dest, "run")); err != nil || info.Mode().Perm() != ExecMode {
+		t.Errorf("run mode = %v, %v", info, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "conf")); err != nil || link != "/etc/web.conf" {
+		t.Errorf("conf link = %q, %v", link, err)
+	}
+	for _, runtime := range []string{SuperviseDir, filepath.Join("log", SuperviseDir)} {
+		if _, err := os.Stat(filepath.Join(dest, runtime)); !os.IsNotExist(err) {
+			t.Errorf("%s restored: %v", runtime, err)
+		}
+	}
+}
+
+func TestBackupSymlinkedSupervise(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "web")
+	if err := NewServiceBuilder("web", filepath.Dir(src)).WithCmd([]string{"/usr/bin/web"}).Build(); err != nil {
+		t.Fatal(err)
+	}
+	// As on installs keeping runtime state in /run
+	runDir := t.TempDir()
+	if err := os.Symlink(runDir, filepath.Join(src, SuperviseDir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(runDir, "event"), filepath.Join(src, "event")); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := Backup(src, &archive); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(t.TempDir(), "restored")
+	if err := Restore(context.Background(), &archive, dest, RestoreOptions{}); err != nil {
+		t.Fatalf("Restore() of an archive with a symlinked supervise = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "run")); err != nil {
+		t.Errorf("run not restored: %v", err)
+	}
+	for _, runtime := range []string{SuperviseDir, "event"} {
+		if _, err := os.Lstat(filepath.Join(dest, runtime)); !os.IsNotExist(err) {
+			t.Errorf("%s restored: %v", runtime, err)
+		}
+	}
+}
+
+func TestRestoreRejectsUnsafePaths(t *testing.T) {
+	for _, name := range []string{"../escape", "/abs", "supervise/status", "log/event/x"} {
+		var archive bytes.Buffer
+		tw := tar.NewWriter(&archive)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte("x"))
+		_ = tw.Close()
+
+		if err := Restore(context.Background(), &archive, t.TempDir(), RestoreOptions{}); err == nil {
+			t.Errorf("Restore() accepted %q", name)
+		}
+	}
+}
+
+func TestRestoreRejectsSymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	_ = tw.WriteHeader(&tar.Header{Name: "x", Linkname: outside, Typeflag: tar.TypeSymlink})
+	_ = tw.WriteHeader(&tar.Header{Name: "x/passwd", Mode: 0o644, Size: 5, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("owned"))
+	_ = tw.WriteHeader(&tar.Header{Name: "x/sub/", Mode: 0o777, Typeflag: tar.TypeDir})
+	_ = tw.Close()
+
+	dest := t.TempDir()
+	if err := Restore(context.Background(), &archive, dest, RestoreOptions{}); err == nil {
+		t.Error("Restore() wrote through a symlink from the archive")
+	}
+	entries, err := os.ReadDir(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Restore() created %v outside the destination", entries)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "x")); err != nil || link != outside {
+		t.Errorf("x link = %q, %v, want the archived link kept as is", link, err)
+	}
+}
+
+func TestRestoreStopStart(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "web")
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// Play the supervisor: stop once asked to
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			if data, _ := os.ReadFile(mock.ControlFile); string(data) == "d" {
+				_ = mock.UpdateStatus(false, 0)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	_ = tw.WriteHeader(&tar.Header{Name: "run", Mode: 0o755, Size: 3, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("new"))
+	_ = tw.Close()
+
+	if err := Restore(ctx, &archive, dir, RestoreOptions{StopStart: true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "run")); string(data) != "new" {
+		t.Errorf("run = %q", data)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); string(data) != "u" {
+		t.Errorf("last control byte = %q, want the service brought back up", data)
+	}
+}
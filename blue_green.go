@@ -0,0 +1,180 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultBlueGreenPollInterval is how often BlueGreenRestart checks supervision and health
+	DefaultBlueGreenPollInterval = 200 * time.Millisecond
+	// DefaultBlueGreenRollbackTimeout bounds the rollback of a failed
+	// BlueGreenRestart
+	DefaultBlueGreenRollbackTimeout = 30 * time.Second
+)
+
+// BlueGreenConfig describes a blue/green restart between two instances of
+// the same service living side by side in the builder's directory
+type BlueGreenConfig struct {
+	// Builder describes the service. It is built as StandbyName in its
+	// directory, which should be watched by the supervisor's scanner.
+	Builder *ServiceBuilder
+
+	// ActiveName is the instance currently serving traffic (e.g. "web-blue")
+	ActiveName string
+
+	// StandbyName is the instance to bring up (e.g. "web-green")
+	StandbyName string
+
+	// Type is the supervision system of both instances; zero means runit
+	Type ServiceType
+
+	// Health is polled after the standby is started until it returns nil.
+	// When nil, the standby is healthy once it is running.
+	Health func(ctx context.Context, client ServiceClient) error
+
+	// Switch moves traffic from one service directory to the other
+	Switch func(ctx context.Context, fromDir, toDir string) error
+
+	// PollInterval is how often supervision and health are checked
+	PollInterval time.Duration
+
+	// RollbackTimeout bounds switching traffic back and stopping the
+	// standby after a failure, which run even when ctx is done; zero means
+	// DefaultBlueGreenRollbackTimeout
+	RollbackTimeout time.Duration
+}
+
+// BlueGreenRestart builds and starts the standby instance, waits until it is
+// supervised and healthy, switches traffic to it and stops the active one.
+// If the standby never becomes healthy or the switch fails, traffic is
+// switched back (when it had moved) and the standby is stopped again, leaving
+// the active instance untouched. The whole sequence is bounded by ctx and
+// the rollback by RollbackTimeout; the manager's Timeout bounds each control
+// operation and Switch call.
+func (m *Manager) BlueGreenRestart(ctx context.Context, cfg BlueGreenConfig) error {
+	if cfg.Builder == nil || cfg.Switch == nil {
+		return errors.New("blue/green: Builder and Switch are required")
+	}
+	if cfg.ActiveName == "" || cfg.StandbyName == "" || cfg.ActiveName == cfg.StandbyName {
+		return fmt.Errorf("blue/green: invalid instance names %q and %q", cfg.ActiveName, cfg.StandbyName)
+	}
+	if cfg.Type == ServiceTypeUnknown {
+		cfg.Type = ServiceTypeRunit
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = DefaultBlueGreenPollInterval
+	}
+	rollbackTimeout := cfg.RollbackTimeout
+	if rollbackTimeout <= 0 {
+		rollbackTimeout = DefaultBlueGreenRollbackTimeout
+	}
+	health := cfg.Health
+	if health == nil {
+		health = runningHealth
+	}
+
+	builderCfg := cfg.Builder.Config()
+	builderCfg.Name = cfg.StandbyName
+	activeDir := filepath.Join(builderCfg.Dir, cfg.ActiveName)
+	standbyDir := filepath.Join(builderCfg.Dir, cfg.StandbyName)
+
+	if err := (&ServiceBuilder{config: builderCfg}).Build(); err != nil {
+		return fmt.Errorf("blue/green: building %s: %w", cfg.StandbyName, err)
+	}
+
+	// The scanner creates the supervise directory once it notices the service
+	var standby ServiceClient
+	err := pollUntil(ctx, interval, func() (bool, error) {
+		client, err := m.newClient(standbyDir, WithServiceType(cfg.Type))
+		if err != nil {
+			return false, nil
+		}
+		standby = client
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("blue/green: waiting for %s to be supervised: %w", cfg.StandbyName, err)
+	}
+
+	if err := m.withTimeout(ctx, standby.Up); err != nil {
+		return fmt.Errorf("blue/green: starting %s: %w", cfg.StandbyName, err)
+	}
+
+	var healthErr error
+	err = pollUntil(ctx, interval, func() (bool, error) {
+		healthErr = health(ctx, standby)
+		return healthErr == nil, nil
+	})
+	if err != nil {
+		if healthErr != nil {
+			err = fmt.Errorf("%w (last check: %w)", err, healthErr)
+		}
+		return m.rollbackBlueGreen(ctx, rollbackTimeout, standby, nil,
+			fmt.Errorf("blue/green: %s not healthy: %w", cfg.StandbyName, err))
+	}
+
+	switchErr := m.withTimeout(ctx, func(ctx context.Context) error {
+		return cfg.Switch(ctx, activeDir, standbyDir)
+	})
+	if switchErr != nil {
+		switchBack := func(ctx context.Context) error { return cfg.Switch(ctx, standbyDir, activeDir) }
+		return m.rollbackBlueGreen(ctx, rollbackTimeout, standby, switchBack,
+			fmt.Errorf("blue/green: switching traffic to %s: %w", cfg.StandbyName, switchErr))
+	}
+
+	active, err := m.newClient(activeDir, WithServiceType(cfg.Type))
+	if err != nil {
+		return fmt.Errorf("blue/green: traffic switched but stopping %s failed: %w", cfg.ActiveName, err)
+	}
+	if err := m.withTimeout(ctx, active.Down); err != nil {
+		return fmt.Errorf("blue/green: traffic switched but stopping %s failed: %w", cfg.ActiveName, err)
+	}
+	return nil
+}
+
+// rollbackBlueGreen undoes a failed blue/green restart within timeout: it
+// runs switchBack, if any, and stops the standby. Rollback failures are
+// joined to cause.
+func (m *Manager) rollbackBlueGreen(ctx context.Context, timeout time.Duration, standby ServiceClient, switchBack func(context.Context) error, cause error) error {
+	// The caller's context may already be done; rollback gets its own budget
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	errs := []error{cause}
+	if switchBack != nil {
+		if err := m.withTimeout(ctx, switchBack); err != nil {
+			errs = append(errs, fmt.Errorf("rollback: switching traffic back: %w", err))
+		}
+	}
+	if err := m.withTimeout(ctx, standby.Down); err != nil {
+		errs = append(errs, fmt.Errorf("rollback: stopping standby: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// withTimeout runs fn with the manager's per-operation timeout applied
+func (m *Manager) withTimeout(ctx context.Context, fn func(context.Context) error) error {
+	if m.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// runningHealth reports a service healthy once it is running
+func runningHealth(ctx context.Context, client ServiceClient) error {
+	st, err := client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if st.State != StateRunning {
+		return fmt.Errorf("state is %v", st.State)
+	}
+	return nil
+}
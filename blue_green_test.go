@@ -0,0 +1,175 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeScanner supervises the standby instance once its run script appears,
+// reporting it as running after it is told to go up
+func fakeScanner(t *testing.T, standbyDir string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	go func() {
+		defer close(done)
+		var mock *MockSupervisor
+		for ctx.Err() == nil {
+			time.Sleep(time.Millisecond)
+			if mock == nil {
+				if _, err := os.Stat(filepath.Join(standbyDir, "run")); err != nil {
+					continue
+				}
+				m, err := NewMockSupervisor(standbyDir)
+				if err != nil {
+					return
+				}
+				mock = m
+				continue
+			}
+			if data, _ := os.ReadFile(mock.ControlFile); len(data) > 0 && data[0] == OpUp.Byte() {
+				_ = mock.UpdateStatus(true, 200)
+				return
+			}
+		}
+	}()
+}
+
+func TestBlueGreenRestart(t *testing.T) {
+	healthErr := errors.New("health check failed")
+	switchErr := errors.New("load balancer unavailable")
+
+	tests := []struct {
+		name         string
+		health       func(context.Context, ServiceClient) error
+		switchFails  bool
+		wantErr      error
+		wantSwitches [][2]string
+		wantActive   byte
+		wantStandby  byte
+	}{
+		{
+			name:         "success",
+			wantSwitches: [][2]string{{"web-blue", "web-green"}},
+			wantActive:   OpDown.Byte(),
+			wantStandby:  OpUp.Byte(),
+		},
+		{
+			name:        "unhealthy standby",
+			health:      func(context.Context, ServiceClient) error { return healthErr },
+			wantErr:     healthErr,
+			wantStandby: OpDown.Byte(),
+		},
+		{
+			name:         "switch fails",
+			switchFails:  true,
+			wantErr:      switchErr,
+			wantSwitches: [][2]string{{"web-blue", "web-green"}, {"web-green", "web-blue"}},
+			wantStandby:  OpDown.Byte(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanDir := t.TempDir()
+			active, err := NewMockSupervisor(filepath.Join(scanDir, "web-blue"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fakeScanner(t, filepath.Join(scanDir, "web-green"))
+
+			var switches [][2]string
+			cfg := BlueGreenConfig{
+				Builder:      NewServiceBuilder("web", scanDir).WithCmd([]string{"sleep", "3600"}),
+				ActiveName:   "web-blue",
+				StandbyName:  "web-green",
+				Health:       tt.health,
+				PollInterval: time.Millisecond,
+				Switch: func(_ context.Context, from, to string) error {
+					switches = append(switches, [2]string{filepath.Base(from), filepath.Base(to)})
+					if tt.switchFails && len(switches) == 1 {
+						return switchErr
+					}
+					return nil
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err = NewManager().BlueGreenRestart(ctx, cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BlueGreenRestart() = %v, want %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(switches, tt.wantSwitches) {
+				t.Errorf("switches = %v, want %v", switches, tt.wantSwitches)
+			}
+			if got := lastControlByte(t, active.ControlFile); got != tt.wantActive {
+				t.Errorf("active control = %q, want %q", got, tt.wantActive)
+			}
+			standbyControl := filepath.Join(scanDir, "web-green", SuperviseDir, ControlFile)
+			if got := lastControlByte(t, standbyControl); got != tt.wantStandby {
+				t.Errorf("standby control = %q, want %q", got, tt.wantStandby)
+			}
+		})
+	}
+}
+
+func lastControlByte(t *testing.T, path string) byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		return 0
+	}
+	return data[0]
+}
+
+func TestBlueGreenRollbackTimeout(t *testing.T) {
+	scanDir := t.TempDir()
+	if _, err := NewMockSupervisor(filepath.Join(scanDir, "web-blue")); err != nil {
+		t.Fatal(err)
+	}
+	fakeScanner(t, filepath.Join(scanDir, "web-green"))
+
+	switchErr := errors.New("switch failed")
+	cfg := BlueGreenConfig{
+		Builder:         NewServiceBuilder("web", scanDir).WithCmd([]string{"sleep", "3600"}),
+		ActiveName:      "web-blue",
+		StandbyName:     "web-green",
+		PollInterval:    time.Millisecond,
+		RollbackTimeout: 50 * time.Millisecond,
+		Switch: func(ctx context.Context, from, _ string) error {
+			if filepath.Base(from) == "web-blue" {
+				return switchErr
+			}
+			// Switching back hangs
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	// Without a manager Timeout only RollbackTimeout bounds the rollback
+	start := time.Now()
+	err := NewManager(WithTimeout(0)).BlueGreenRestart(context.Background(), cfg)
+	if !errors.Is(err, switchErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("BlueGreenRestart() = %v, want the switch error and a rollback timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("BlueGreenRestart() took %v, want the rollback bounded", elapsed)
+	}
+}
@@ -0,0 +1,95 @@
+package svcmgr
+
+import "slices"
+
+// Capabilities describes what a supervision backend supports, so callers can
+// adapt without issuing operations and inspecting the errors
+type Capabilities struct {
+	// Type is the supervision system
+	Type ServiceType
+
+	// Operations lists the supported operations in ascending order
+	Operations []Operation
+
+	// Readiness reports whether Status.Ready is backed by readiness
+	// notification (s6 ready flag, systemd Type=notify)
+	Readiness bool
+
+	// Pause reports whether the service process can be paused and continued
+	Pause bool
+
+	// Once reports whether the service can be started without restart on exit
+	Once bool
+
+	// WantState reports whether Status.Flags carry the recorded want up or
+	// down, telling crashed services from stopped ones; s6 status files
+	// written before 2.11.0.0 have no want flag
+	WantState bool
+}
+
+// Supports reports whether op is among the supported operations
+func (c Capabilities) Supports(op Operation) bool {
+	_, found := slices.BinarySearch(c.Operations, op)
+	return found
+}
+
+// SupportedOperations returns the supported operations in ascending order,
+// those sent through the control file as given by ControlBytes when set
+func (c *ServiceConfig) SupportedOperations() []Operation {
+	ops := make([]Operation, 0, len(c.SupportedOps))
+	for op := range c.SupportedOps {
+		if c.IsOperationSupported(op) {
+			ops = append(ops, op)
+		}
+	}
+	for op := range c.ControlBytes {
+		if _, ok := c.SupportedOps[op]; !ok {
+			ops = append(ops, op)
+		}
+	}
+	slices.Sort(ops)
+	return ops
+}
+
+// Capabilities returns the capabilities of the configured supervision system
+func (c *ServiceConfig) Capabilities() Capabilities {
+	return Capabilities{
+		Type:       c.Type,
+		Operations: c.SupportedOperations(),
+		Readiness:  c.Type == ServiceTypeS6 || c.Type == ServiceTypeSystemd,
+		Pause:      c.IsOperationSupported(OpPause) && c.IsOperationSupported(OpCont),
+		Once:       c.IsOperationSupported(OpOnce),
+		WantState:  true,
+	}
+}
+
+// Capabilities returns what runit supports, with the operations of
+// ControlBytes when set
+func (rc *ClientRunit) Capabilities() Capabilities {
+	return rc.serviceConfig().Capabilities()
+}
+
+// Capabilities returns what daemontools supports, with the operations of
+// ControlBytes when set
+func (cd *ClientDaemontools) Capabilities() Capabilities {
+	return cd.serviceConfig().Capabilities()
+}
+
+// Capabilities returns what s6 supports, with the operations of
+// ControlBytes when set, given the status layout when S6Format is set
+func (cs *ClientS6) Capabilities() Capabilities {
+	return s6Capabilities(cs.serviceConfig(), cs.S6Format)
+}
+
+// s6Capabilities returns what the s6 config supports with the given status
+// layout
+func s6Capabilities(config *ServiceConfig, layout S6FormatVersion) Capabilities {
+	caps := config.Capabilities()
+	caps.WantState = layout != S6FormatPre220
+	return caps
+}
+
+// Capabilities returns what the systemd adapter supports on this platform
+func (c *ClientSystemd) Capabilities() Capabilities {
+	return ConfigSystemd().Capabilities()
+}
@@ -0,0 +1,39 @@
+package svcmgr
+
+import "testing"
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		name      string
+		caps      Capabilities
+		readiness bool
+		pause     bool
+		once      bool
+		missing   []Operation
+	}{
+		{name: "runit", caps: (&ClientRunit{}).Capabilities(), pause: true, once: true},
+		{name: "daemontools", caps: (&ClientDaemontools{}).Capabilities(), pause: true, missing: []Operation{OpOnce, OpQuit}},
+		{name: "s6", caps: (&ClientS6{}).Capabilities(), readiness: true, once: true, missing: []Operation{OpPause, OpCont}},
+		{
+			name:    "control bytes override",
+			caps:    (&ClientRunit{ControlBytes: map[Operation]byte{OpUp: 'U', OpDown: 'D'}}).Capabilities(),
+			missing: []Operation{OpOnce, OpTerm, OpPause, OpCont, OpExit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.caps.Readiness != tt.readiness || tt.caps.Pause != tt.pause || tt.caps.Once != tt.once {
+				t.Errorf("Capabilities() = %+v", tt.caps)
+			}
+			if !tt.caps.Supports(OpUp) || !tt.caps.Supports(OpDown) || !tt.caps.Supports(OpStatus) {
+				t.Error("up/down/status must always be supported")
+			}
+			for _, op := range tt.missing {
+				if tt.caps.Supports(op) {
+					t.Errorf("unexpected support for %v", op)
+				}
+			}
+		})
+	}
+}
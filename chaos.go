@@ -0,0 +1,222 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Chaos defaults
+const (
+	// DefaultChaosInterval is the default time between chaos actions
+	DefaultChaosInterval = time.Minute
+	// DefaultChaosPause is how long ChaosPause keeps a service stopped
+	DefaultChaosPause = 5 * time.Second
+)
+
+// ChaosAction is a disruption Chaos can inflict on a service
+type ChaosAction int
+
+const (
+	// ChaosKill sends SIGKILL, leaving the supervisor to restart the service
+	ChaosKill ChaosAction = iota
+	// ChaosPause stops the process with SIGSTOP and continues it after
+	// Chaos.PauseDuration, simulating a hang
+	ChaosPause
+	// ChaosRestart restarts the service through its client
+	ChaosRestart
+)
+
+// String returns the name of the action
+func (a ChaosAction) String() string {
+	switch a {
+	case ChaosKill:
+		return "kill"
+	case ChaosPause:
+		return "pause"
+	case ChaosRestart:
+		return "restart"
+	default:
+		return fmt.Sprintf("ChaosAction(%d)", int(a))
+	}
+}
+
+// ChaosEvent records one chaos action
+type ChaosEvent struct {
+	// Time is when the action started
+	Time time.Time
+	// Service is the targeted service directory
+	Service string
+	// Action is the disruption
+	Action ChaosAction
+	// DryRun reports that the action was only chosen, not performed
+	DryRun bool
+	// Err is the error performing the action, if any
+	Err error
+}
+
+// errChaosNoTargets is returned when no service passes the allow-list
+var errChaosNoTargets = errors.New("runit: chaos: no services match the allow-list")
+
+// Chaos randomly disrupts a subset of supervised services at intervals for
+// resilience testing, performing actions through Manager.
+//
+// Only services matching an Allow pattern are ever targeted, so a typo in
+// Services cannot take down something critical; with no Allow patterns
+// nothing is targeted. DryRun chooses and reports actions without
+// performing them.
+//
+// Example:
+//
+//	c := &svcmgr.Chaos{
+//		Manager:  svcmgr.NewManager(),
+//		Services: []string{"/etc/service/api-1", "/etc/service/api-2", "/etc/service/db"},
+//		Allow:    []string{"api-*"},
+//		OnEvent:  func(e svcmgr.ChaosEvent) { log.Printf("chaos: %s %s: %v", e.Action, e.Service, e.Err) },
+//	}
+//	err := c.Run(ctx)
+type Chaos struct {
+	// Manager performs the actions
+	Manager *Manager
+	// Services are the candidate service directories
+	Services []string
+	// Allow lists filepath.Match patterns matched against each service's
+	// directory and its base name; a service must match one to be targeted
+	Allow []string
+	// Actions are the disruptions chosen from; empty means all
+	Actions []ChaosAction
+	// Interval is the time between actions in Run
+	Interval time.Duration
+	// PauseDuration is how long ChaosPause keeps a service stopped
+	PauseDuration time.Duration
+	// DryRun reports actions without performing them
+	DryRun bool
+	// Rand chooses targets and actions; nil uses the global source. A
+	// seeded source makes a chaos run reproducible.
+	Rand *rand.Rand
+	// OnEvent is called after each action
+	OnEvent func(ChaosEvent)
+}
+
+// Targets returns the services eligible for disruption
+func (c *Chaos) Targets() []string {
+	var targets []string
+	for _, svc := range c.Services {
+		if c.allowed(svc) {
+			targets = append(targets, svc)
+		}
+	}
+	return targets
+}
+
+// allowed reports whether svc matches an Allow pattern
+func (c *Chaos) allowed(svc string) bool {
+	return slices.ContainsFunc(c.Allow, func(pattern string) bool {
+		if ok, _ := filepath.Match(pattern, svc); ok {
+			return true
+		}
+		ok, _ := filepath.Match(pattern, filepath.Base(svc))
+		return ok
+	})
+}
+
+// Step performs one randomly chosen action on one random target. The
+// event's Err is also returned. A paused service is continued even if ctx
+// is cancelled during the pause.
+func (c *Chaos) Step(ctx context.Context) (ChaosEvent, error) {
+	targets := c.Targets()
+	if len(targets) == 0 {
+		return ChaosEvent{}, errChaosNoTargets
+	}
+	actions := c.Actions
+	if len(actions) == 0 {
+		actions = []ChaosAction{ChaosKill, ChaosPause, ChaosRestart}
+	}
+
+	ev := ChaosEvent{
+		Time:    time.Now(),
+		Service: targets[c.intN(len(targets))],
+		Action:  actions[c.intN(len(actions))],
+		DryRun:  c.DryRun,
+	}
+	if !c.DryRun {
+		ev.Err = c.perform(ctx, ev.Service, ev.Action)
+	}
+	if c.OnEvent != nil {
+		c.OnEvent(ev)
+	}
+	return ev, ev.Err
+}
+
+// Run performs a Step every Interval until ctx is done, returning
+// ctx.Err(). Failed actions are reported through OnEvent and do not stop
+// the run; an empty target list does.
+func (c *Chaos) Run(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultChaosInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := c.Step(ctx); errors.Is(err, errChaosNoTargets) {
+				return err
+			}
+		}
+	}
+}
+
+// perform carries out action on svc
+func (c *Chaos) perform(ctx context.Context, svc string, action ChaosAction) error {
+	m := c.Manager
+	if m == nil {
+		m = NewManager()
+	}
+
+	switch action {
+	case ChaosKill:
+		return m.Kill(ctx, svc)
+	case ChaosRestart:
+		return m.execute(ctx, OpRestart, []string{svc}, func(ctx context.Context, client ServiceClient) error {
+			return client.Restart(ctx)
+		})
+	case ChaosPause:
+		err := m.execute(ctx, OpPause, []string{svc}, func(ctx context.Context, client ServiceClient) error {
+			return client.Pause(ctx)
+		})
+		if err != nil {
+			return err
+		}
+		pause := c.PauseDuration
+		if pause <= 0 {
+			pause = DefaultChaosPause
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(pause):
+		}
+		// Never leave a service stopped, even when the run is cancelled
+		return m.execute(context.WithoutCancel(ctx), OpCont, []string{svc}, func(ctx context.Context, client ServiceClient) error {
+			return client.Continue(ctx)
+		})
+	default:
+		return fmt.Errorf("chaos: unknown action %v", action)
+	}
+}
+
+// intN returns a random int in [0, n)
+func (c *Chaos) intN(n int) int {
+	if c.Rand != nil {
+		return c.Rand.IntN(n)
+	}
+	return rand.IntN(n)
+}
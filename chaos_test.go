@@ -0,0 +1,91 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChaos(t *testing.T) {
+	root := t.TempDir()
+	mocks := make(map[string]*MockSupervisor)
+	var services []string
+	for _, name := range []string{"api-1", "api-2", "db"} {
+		mock, err := NewMockSupervisor(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.UpdateStatus(true, 100); err != nil {
+			t.Fatal(err)
+		}
+		mocks[name] = mock
+		services = append(services, mock.ServiceDir)
+	}
+	lastControl := func(name string) string {
+		data, err := os.ReadFile(mocks[name].ControlFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) == 0 {
+			return ""
+		}
+		return string(data[len(data)-1:])
+	}
+
+	c := &Chaos{
+		Manager:       NewManager(),
+		Services:      services,
+		Allow:         []string{"api-*"},
+		PauseDuration: time.Millisecond,
+		Rand:          rand.New(rand.NewPCG(1, 2)),
+	}
+	if got := c.Targets(); len(got) != 2 {
+		t.Fatalf("Targets() = %v, want the api services", got)
+	}
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		action ChaosAction
+		want   string
+	}{
+		{ChaosKill, "k"},
+		{ChaosPause, "c"},
+	} {
+		c.Actions = []ChaosAction{tt.action}
+		ev, err := c.Step(ctx)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.action, err)
+		}
+		if got := lastControl(filepath.Base(ev.Service)); got != tt.want {
+			t.Errorf("%v: last control byte %q, want %q", tt.action, got, tt.want)
+		}
+	}
+	if lastControl("db") != "" {
+		t.Error("chaos touched a service outside the allow-list")
+	}
+
+	// Dry runs report without acting
+	for _, name := range []string{"api-1", "api-2"} {
+		if err := os.WriteFile(mocks[name].ControlFile, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.DryRun = true
+	if ev, err := c.Step(ctx); err != nil || !ev.DryRun {
+		t.Fatalf("dry run Step = %+v, %v", ev, err)
+	}
+	if lastControl("api-1")+lastControl("api-2") != "" {
+		t.Error("dry run sent a control command")
+	}
+
+	c.Allow = nil
+	if _, err := c.Step(ctx); !errors.Is(err, errChaosNoTargets) {
+		t.Errorf("Step without allow-list = %v, want errChaosNoTargets", err)
+	}
+}
@@ -1,10 +1,10 @@
 package svcmgr
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"path/filepath"
 	"sync"
@@ -26,7 +26,8 @@ type ClientDaemontools struct {
 	// WriteTimeout is the timeout for writing control commands
 	WriteTimeout time.Duration
 
-	// ReadTimeout is the timeout for reading status information
+	// ReadTimeout bounds Status, including the status tool fallback, when
+	// its context has no deadline; zero means DefaultTimeout
 	ReadTimeout time.Duration
 
 	// BackoffMin is the minimum duration between retry attempts
@@ -41,6 +42,54 @@ type ClientDaemontools struct {
 	// WatchDebounce is the debounce duration for watch events to coalesce rapid changes
 	WatchDebounce time.Duration
 
+	// WatchBuffer is the capacity of Watch channels; zero means
+	// DefaultWatchBuffer
+	WatchBuffer int
+
+	// WatchOverflow is what Watch does when its channel is full
+	WatchOverflow WatchOverflow
+
+	// DefaultTimeout bounds control and status operations whose context has
+	// no deadline; zero leaves such operations unbounded
+	DefaultTimeout time.Duration
+
+	// Clock computes Status.Uptime; nil uses the wall clock
+	Clock Clock
+
+	// ControlBytes overrides the bytes written to the control file, for
+	// forks whose control protocol differs from upstream; nil uses the
+	// defaults of ConfigDaemontools
+	ControlBytes map[Operation]byte
+
+	// StrictDecode rejects status files with unknown flag values or impossible
+	// timestamps instead of decoding them best-effort
+	StrictDecode bool
+
+	// StatusFallback makes Status parse the output of the native status tool
+	// (svstat) when the status file cannot be read, e.g. due to permissions
+	StatusFallback bool
+
+	// StatusToolPath is the path to the status tool used by StatusFallback
+	StatusToolPath string
+
+	// VerifyPID makes Status check that the process the status file names
+	// exists, downgrading the state when it does not (see WithVerifyPID)
+	VerifyPID bool
+
+	// Pinned, when set, makes status reads and control writes open the
+	// supervise files relative to a held directory, refusing symlinks,
+	// instead of resolving ServiceDir on every operation. Control sockets
+	// are not dialed, as they can only be reached by path.
+	Pinned *PinnedDir
+
+	// Instrumentation, when set, observes control commands and status
+	// reads (see WithInstrumentation)
+	Instrumentation Instrumentation
+
+	// SkipIfRunning makes Up a no-op when the service is already running
+	// and wanted up (see WithSkipIfRunning)
+	SkipIfRunning bool
+
 	// mu protects concurrent access to send operations
 	mu sync.Mutex
 }
@@ -54,14 +103,15 @@ func NewClientDaemontools(serviceDir string) (*ClientDaemontools, error) {
 	}
 
 	cd := &ClientDaemontools{
-		ServiceDir:    absPath,
-		DialTimeout:   DefaultDialTimeout,
-		WriteTimeout:  DefaultWriteTimeout,
-		ReadTimeout:   DefaultReadTimeout,
-		BackoffMin:    DefaultBackoffMin,
-		BackoffMax:    DefaultBackoffMax,
-		MaxAttempts:   DefaultMaxAttempts,
-		WatchDebounce: DefaultWatchDebounce,
+		ServiceDir:     absPath,
+		DialTimeout:    DefaultDialTimeout,
+		WriteTimeout:   DefaultWriteTimeout,
+		ReadTimeout:    DefaultReadTimeout,
+		BackoffMin:     DefaultBackoffMin,
+		BackoffMax:     DefaultBackoffMax,
+		MaxAttempts:    DefaultMaxAttempts,
+		WatchDebounce:  DefaultWatchDebounce,
+		StatusToolPath: DefaultSvstatPath,
 	}
 
 	superviseDir := filepath.Join(cd.ServiceDir, SuperviseDir)
@@ -74,13 +124,21 @@ func NewClientDaemontools(serviceDir string) (*ClientDaemontools, error) {
 
 // send writes a single control byte to the service's control socket/FIFO.
 // It implements exponential backoff and retries for transient failures.
-func (cd *ClientDaemontools) send(ctx context.Context, op Operation) error {
+func (cd *ClientDaemontools) send(ctx context.Context, op Operation) (err error) {
+	start := time.Now()
+	retries := 0
+	defer func() { observeOperation(ctx, cd.Instrumentation, cd.ServiceDir, op, start, retries, err) }()
+
 	cd.mu.Lock()
 	defer cd.mu.Unlock()
 
+	ctx, cancel := withDefaultTimeout(ctx, cd.DefaultTimeout)
+	defer cancel()
+
 	// Check if this operation is supported by daemontools
-	config := ConfigDaemontools()
-	if !config.IsOperationSupported(op) {
+	config := cd.serviceConfig()
+	cmd, ok := config.ControlByte(op)
+	if !ok {
 		return &OpError{
 			Op:   op,
 			Path: cd.ServiceDir,
@@ -89,13 +147,13 @@ func (cd *ClientDaemontools) send(ctx context.Context, op Operation) error {
 	}
 
 	controlPath := filepath.Join(cd.ServiceDir, SuperviseDir, ControlFile)
-	cmd := op.Byte()
 
 	var lastErr error
 	backoff := cd.BackoffMin
 
 	for attempt := 0; attempt < cd.MaxAttempts; attempt++ {
 		if attempt > 0 {
+			retries = attempt
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -108,7 +166,7 @@ func (cd *ClientDaemontools) send(ctx context.Context, op Operation) error {
 			}
 		}
 
-		conn, err := net.DialTimeout("unix", controlPath, cd.DialTimeout)
+		conn, err := dialControl(controlPath, cd.DialTimeout, cd.Pinned)
 		if err == nil {
 			defer func() { _ = conn.Close() }()
 
@@ -116,18 +174,24 @@ func (cd *ClientDaemontools) send(ctx context.Context, op Operation) error {
 				_ = conn.SetWriteDeadline(time.Now().Add(cd.WriteTimeout))
 			}
 
-			if _, err := conn.Write([]byte{cmd}); err == nil {
+			_, err = conn.Write([]byte{cmd})
+			if err == nil {
 				return nil
 			}
 			lastErr = err
 			continue
 		}
 
-		file, err := os.OpenFile(controlPath, os.O_WRONLY|unix.ONonblock, 0)
+		file, err := openSuperviseFile(cd.Pinned, controlPath, os.O_WRONLY|unix.ONonblock)
 		if err == nil {
 			defer func() { _ = file.Close() }()
 
-			if _, err := file.Write([]byte{cmd}); err == nil {
+			if cd.WriteTimeout > 0 {
+				_ = file.SetWriteDeadline(time.Now().Add(cd.WriteTimeout))
+			}
+
+			_, err = file.Write([]byte{cmd})
+			if err == nil {
 				return nil
 			}
 			lastErr = err
@@ -145,6 +209,9 @@ func (cd *ClientDaemontools) send(ctx context.Context, op Operation) error {
 
 // Up starts the service (sets want up)
 func (cd *ClientDaemontools) Up(ctx context.Context) error {
+	if cd.SkipIfRunning && skipUp(ctx, cd.Status, cd.Instrumentation, cd.ServiceDir) {
+		return nil
+	}
 	return cd.send(ctx, OpUp)
 }
 
@@ -240,12 +307,18 @@ func (cd *ClientDaemontools) ExitSupervise(ctx context.Context) error {
 
 // Status reads and decodes the service's binary status file.
 // It returns typed Status information.
-func (cd *ClientDaemontools) Status(_ context.Context) (Status, error) {
+func (cd *ClientDaemontools) Status(ctx context.Context) (_ Status, err error) {
+	start := time.Now()
+	defer func() { observeOperation(ctx, cd.Instrumentation, cd.ServiceDir, OpStatus, start, 0, err) }()
+
+	ctx, cancel := withDefaultTimeout(ctx, cmp.Or(cd.ReadTimeout, cd.DefaultTimeout))
+	defer cancel()
+
 	statusPath := filepath.Join(cd.ServiceDir, SuperviseDir, StatusFile)
 
-	file, err := os.Open(statusPath)
+	file, err := openSuperviseFile(cd.Pinned, statusPath, os.O_RDONLY)
 	if err != nil {
-		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
+		return cd.statusFallback(ctx, statusPath, err)
 	}
 	defer func() { _ = file.Close() }()
 
@@ -263,14 +336,66 @@ func (cd *ClientDaemontools) Status(_ context.Context) (Status, error) {
 		}
 	}
 
+	if cd.StrictDecode {
+		if err := validateStatusDaemontools(buf); err != nil {
+			return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
+		}
+	}
+
 	// Decode using daemontools-specific decoder
-	status, err := decodeStatusDaemontools(buf)
+	status, err := decodeStatusDaemontoolsAt(buf, clockNow(cd.Clock))
 	if err != nil {
 		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
 	}
 
+	if cd.VerifyPID {
+		status = verifyPID(status, cd.ServiceDir, DefaultProcDir)
+	}
 	return status, nil
 }
 
+// statusFallback parses svstat output when the status file cannot be opened,
+// or returns the original error if StatusFallback is disabled
+func (cd *ClientDaemontools) statusFallback(ctx context.Context, statusPath string, cause error) (Status, error) {
+	if !cd.StatusFallback {
+		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: cause}
+	}
+
+	status, err := runStatusTool(ctx, cd.Clock, parseSvstatAt, cd.StatusToolPath, cd.ServiceDir)
+	if err != nil {
+		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: fmt.Errorf("%w (fallback: %w)", cause, err)}
+	}
+	if cd.VerifyPID {
+		status = verifyPID(status, cd.ServiceDir, DefaultProcDir)
+	}
+	return status, nil
+}
+
+// StatusExtended returns the status together with resource usage of the
+// main process read from /proc
+func (cd *ClientDaemontools) StatusExtended(ctx context.Context) (StatusExtended, error) {
+	return statusExtended(ctx, cd.Status, DefaultProcDir)
+}
+
+// ProcessTree returns the main process and all of its descendants from /proc,
+// or nil when the service has no process
+func (cd *ClientDaemontools) ProcessTree(ctx context.Context) ([]ProcessInfo, error) {
+	return processTree(ctx, cd.Status, DefaultProcDir)
+}
+
 // Ensure ClientDaemontools implements ServiceClient
-var _ ServiceClient = (*ClientDaemontools)(nil)
+var (
+	_ ServiceClient      = (*ClientDaemontools)(nil)
+	_ ProcessInspector   = (*ClientDaemontools)(nil)
+	_ FuncWaiter         = (*ClientDaemontools)(nil)
+	_ CapabilityReporter = (*ClientDaemontools)(nil)
+)
+
+// serviceConfig returns the daemontools configuration with ControlBytes applied
+func (cd *ClientDaemontools) serviceConfig() *ServiceConfig {
+	config := ConfigDaemontools()
+	if cd.ControlBytes != nil {
+		config.ControlBytes = cd.ControlBytes
+	}
+	return config
+}
@@ -4,14 +4,32 @@ import (
 	"context"
 )
 
-// ServiceClient is the main interface all supervision clients implement.
-// It provides a unified API for controlling services across different
-// supervision systems (runit, daemontools, s6, systemd).
-type ServiceClient interface {
+// StatusReader reads a service's status. Read-only backends, such as a
+// remote status endpoint, implement only this.
+type StatusReader interface {
+	Status(ctx context.Context) (Status, error)
+}
+
+// ProcessInspector reads the resource usage and descendants of a service's
+// main process. It is optional: the clients of this package implement it,
+// backends without access to the process table need not, so callers check
+// for it with a type assertion.
+//
+// Example:
+//
+//	if pi, ok := client.(svcmgr.ProcessInspector); ok {
+//		ext, err := pi.StatusExtended(ctx)
+//	}
+type ProcessInspector interface {
+	StatusExtended(ctx context.Context) (StatusExtended, error)
+	ProcessTree(ctx context.Context) ([]ProcessInfo, error)
+}
+
+// Controller changes a service's state and signals its process
+type Controller interface {
 	// Basic operations
 	Up(ctx context.Context) error
 	Down(ctx context.Context) error
-	Status(ctx context.Context) (Status, error)
 
 	// Signal operations
 	Term(ctx context.Context) error
@@ -35,12 +53,58 @@ type ServiceClient interface {
 
 	// Supervision control
 	ExitSupervise(ctx context.Context) error
+}
 
+// Watcher reports changes to a service's status
+type Watcher interface {
 	// Watch monitors the service's status for changes
 	// Returns a channel of events and a stop function
 	Watch(ctx context.Context) (<-chan WatchEvent, WatchCleanupFunc, error)
+}
 
+// ReadinessWaiter blocks until a service reaches a wanted status
+type ReadinessWaiter interface {
 	// Wait blocks until the service reaches one of the specified states
 	// If states is nil or empty, waits for any status change
 	Wait(ctx context.Context, states []State) (Status, error)
 }
+
+// FuncWaiter blocks until a predicate holds for a service's status. It is
+// optional: the clients of this package implement it, and helpers fall back
+// to polling Status for backends that do not.
+//
+// Example:
+//
+//	if fw, ok := client.(svcmgr.FuncWaiter); ok {
+//		st, err := fw.WaitFunc(ctx, func(st svcmgr.Status) bool { return st.Ready })
+//	}
+type FuncWaiter interface {
+	// WaitFunc blocks until pred returns true for the service's status
+	WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error)
+}
+
+// ServiceClient is the main interface all supervision clients implement.
+// It provides a unified API for controlling services across different
+// supervision systems (runit, daemontools, s6, systemd). Consumers that
+// need only part of it can depend on StatusReader, Controller, Watcher or
+// ReadinessWaiter instead.
+type ServiceClient interface {
+	StatusReader
+	Controller
+	Watcher
+	ReadinessWaiter
+}
+
+// CapabilityReporter describes the operations and features a backend
+// supports. It is optional: the clients of this package implement it, so
+// callers check for it with a type assertion.
+//
+// Example:
+//
+//	if cr, ok := client.(svcmgr.CapabilityReporter); ok && cr.Capabilities().Pause {
+//		err = client.Pause(ctx)
+//	}
+type CapabilityReporter interface {
+	// Capabilities describes the operations and features the backend supports
+	Capabilities() Capabilities
+}
@@ -0,0 +1,260 @@
+package svcmgr
+
+import (
+	"cmp"
+	"context"
+	"time"
+)
+
+// ClientOption configures a client created by NewClient.
+// A ServiceType is itself a ClientOption selecting the supervision system.
+type ClientOption interface {
+	applyClient(*clientConfig)
+}
+
+// clientOptionFunc adapts a function to the ClientOption interface
+type clientOptionFunc func(*clientConfig)
+
+func (f clientOptionFunc) applyClient(c *clientConfig) { f(c) }
+
+// applyClient makes a ServiceType usable as a ClientOption
+func (st ServiceType) applyClient(c *clientConfig) { c.serviceType = st }
+
+// clientConfig collects the settings requested through ClientOptions.
+// Zero values leave the client's defaults in place.
+type clientConfig struct {
+	serviceType    ServiceType
+	dialTimeout    time.Duration
+	writeTimeout   time.Duration
+	statusTimeout  time.Duration
+	watchDebounce  time.Duration
+	maxAttempts    int
+	backoffMin     time.Duration
+	backoffMax     time.Duration
+	defaultTimeout time.Duration
+	clock          Clock
+	controlBytes   map[Operation]byte
+	pinned         *PinnedDir
+	verifyPID      bool
+	instrument     Instrumentation
+	watchBuffer    int
+	watchOverflow  WatchOverflow
+	s6Format       S6FormatVersion
+	skipIfRunning  bool
+}
+
+// WithServiceType selects the supervision system instead of detecting it
+// from the service directory
+func WithServiceType(st ServiceType) ClientOption {
+	return st
+}
+
+// WithServiceConfig selects the supervision system and control bytes of
+// config, typically a preset registered with RegisterPreset for a fork of
+// runit, daemontools or s6. A nil config leaves the client unchanged.
+func WithServiceConfig(config *ServiceConfig) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		if config == nil {
+			return
+		}
+		c.serviceType = config.Type
+		c.controlBytes = config.ControlBytes
+	})
+}
+
+// WithControlBytes overrides the byte written to the control file for each
+// operation, for forks whose control protocol differs from upstream.
+// Operations missing from table are rejected as unsupported.
+func WithControlBytes(table map[Operation]byte) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.controlBytes = table
+	})
+}
+
+// WithDialTimeout sets the timeout for connecting to the control socket
+func WithDialTimeout(d time.Duration) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.dialTimeout = d
+	})
+}
+
+// WithWriteTimeout sets the timeout for writing control commands
+func WithWriteTimeout(d time.Duration) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.writeTimeout = d
+	})
+}
+
+// WithStatusTimeout bounds Status calls whose context has no deadline,
+// including the status tool fallback. For systemd it bounds each systemctl
+// invocation.
+func WithStatusTimeout(d time.Duration) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.statusTimeout = d
+	})
+}
+
+// WithWatchDebounce sets the debounce duration used to coalesce watch events
+func WithWatchDebounce(d time.Duration) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.watchDebounce = d
+	})
+}
+
+// WithWatchBuffer sets the capacity of Watch channels and what Watch does
+// when a slow consumer lets the buffer fill up: block (WatchOverflowBlock),
+// discard the oldest event (WatchOverflowDropOldest) or keep only the
+// latest (WatchOverflowCoalesce). Dropped events are counted in
+// WatchEvent.Dropped. size <= 0 keeps DefaultWatchBuffer.
+func WithWatchBuffer(size int, overflow WatchOverflow) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.watchBuffer = size
+		c.watchOverflow = overflow
+	})
+}
+
+// WithControlRetry sets how control commands are retried: up to maxAttempts
+// tries with exponential backoff between backoffMin and backoffMax
+func WithControlRetry(maxAttempts int, backoffMin, backoffMax time.Duration) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.maxAttempts = maxAttempts
+		c.backoffMin = backoffMin
+		c.backoffMax = backoffMax
+	})
+}
+
+// WithDefaultTimeout bounds every control and status operation whose
+// context has no deadline, so callers passing context.Background() cannot
+// hang indefinitely. It replaces the DefaultReadTimeout bounding Status
+// unless WithStatusTimeout is also given. Watch and Wait are long-lived and
+// are not bounded.
+func WithDefaultTimeout(d time.Duration) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.defaultTimeout = d
+	})
+}
+
+// WithClock computes Status.Uptime against clock instead of the wall clock
+func WithClock(clock Clock) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.clock = clock
+	})
+}
+
+// WithPinnedDir makes the client open its status and control files relative
+// to pin, so a symlink swapped under the service's path cannot redirect
+// them. pin must be of the service directory passed to NewClient, which
+// otherwise fails. The caller keeps ownership of pin and closes it after
+// the client's last use. Ignored for systemd.
+func WithPinnedDir(pin *PinnedDir) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.pinned = pin
+	})
+}
+
+// WithVerifyPID makes Status cross-check the PID decoded from the status
+// file with kill(pid, 0) and, where /proc is available, the process start
+// time against Status.Since. A process that started late but is still a
+// child of the service's supervisor is kept, since a stepped wall clock
+// also makes start times look late. When the process no longer exists, as
+// after the supervisor itself was killed, or the PID was reused by a
+// process started later, the PID is cleared and the state becomes
+// StateCrashed if the service wants to be up, StateUnknown otherwise. The
+// check is only meaningful in the supervisor's PID namespace. Ignored for
+// systemd.
+func WithVerifyPID() ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.verifyPID = true
+	})
+}
+
+// WithInstrumentation reports every control command, with its latency and
+// retries, and every status read to inst. For systemd these are the
+// systemctl commands behind them, which are not retried.
+func WithInstrumentation(inst Instrumentation) ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.instrument = inst
+	})
+}
+
+// WithSkipIfRunning makes Up read the status first and skip the control
+// write when the service is already running and wanted up, so reconcile
+// loops calling Up on every pass cause no churn. The skipped Up is still
+// reported to the Instrumentation, with OperationEvent.Skipped set. The
+// status read is an OpStatus of its own and is reported as one before the
+// OpUp, skipped or not. When the status cannot be read, Up writes the
+// control byte as usual. Ignored for systemd, whose start job is already a
+// no-op on an active unit.
+func WithSkipIfRunning() ClientOption {
+	return clientOptionFunc(func(c *clientConfig) {
+		c.skipIfRunning = true
+	})
+}
+
+// skipUp reports whether Up can skip its control write because status shows
+// the service running and wanted up, reporting the skipped Up to inst;
+// status reports its own read
+func skipUp(ctx context.Context, status func(context.Context) (Status, error), inst Instrumentation, service string) bool {
+	start := time.Now()
+	st, err := status(ctx)
+	if err != nil || st.State != StateRunning || !st.Flags.WantUp {
+		return false
+	}
+	observeSkipped(ctx, inst, service, OpUp, start)
+	return true
+}
+
+// withDefaultTimeout applies d as a timeout when ctx has no deadline
+func withDefaultTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// setDuration overwrites dst when v is set
+func setDuration(dst *time.Duration, v time.Duration) {
+	if v > 0 {
+		*dst = v
+	}
+}
+
+// clientFields points at the settings shared by the daemontools-family
+// clients, so NewClient applies its options to all of them in one place.
+// A nil field panics in applyTo rather than leaving an option unapplied.
+type clientFields struct {
+	dialTimeout, writeTimeout, readTimeout, watchDebounce *time.Duration
+	backoffMin, backoffMax, defaultTimeout                *time.Duration
+	maxAttempts, watchBuffer                              *int
+	watchOverflow                                         *WatchOverflow
+	clock                                                 *Clock
+	controlBytes                                          *map[Operation]byte
+	pinned                                                **PinnedDir
+	verifyPID, skipIfRunning                              *bool
+	instrument                                            *Instrumentation
+}
+
+// applyTo copies the configured settings onto a daemontools-family client
+func (c *clientConfig) applyTo(f clientFields) {
+	setDuration(f.dialTimeout, c.dialTimeout)
+	setDuration(f.writeTimeout, c.writeTimeout)
+	setDuration(f.readTimeout, cmp.Or(c.statusTimeout, c.defaultTimeout))
+	setDuration(f.watchDebounce, c.watchDebounce)
+	setDuration(f.backoffMin, c.backoffMin)
+	setDuration(f.backoffMax, c.backoffMax)
+	setDuration(f.defaultTimeout, c.defaultTimeout)
+	if c.maxAttempts > 0 {
+		*f.maxAttempts = c.maxAttempts
+	}
+	*f.watchBuffer = c.watchBuffer
+	*f.watchOverflow = c.watchOverflow
+	*f.clock = c.clock
+	*f.controlBytes = c.controlBytes
+	*f.pinned = c.pinned
+	*f.verifyPID = c.verifyPID
+	*f.skipIfRunning = c.skipIfRunning
+	*f.instrument = c.instrument
+}
@@ -0,0 +1,338 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewMockSupervisorWithType(dir, ServiceTypeS6); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(dir,
+		WithDialTimeout(3*time.Second),
+		WithWriteTimeout(4*time.Second),
+		WithStatusTimeout(5*time.Second),
+		WithWatchDebounce(6*time.Second),
+		WithControlRetry(7, 8*time.Millisecond, 9*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mock's status file identifies the directory as s6
+	s6, ok := client.(*ClientS6)
+	if !ok {
+		t.Fatalf("NewClient() returned %T, want *ClientS6", client)
+	}
+	if s6.DialTimeout != 3*time.Second || s6.WriteTimeout != 4*time.Second || s6.ReadTimeout != 5*time.Second {
+		t.Errorf("timeouts = %v/%v/%v", s6.DialTimeout, s6.WriteTimeout, s6.ReadTimeout)
+	}
+	if s6.WatchDebounce != 6*time.Second {
+		t.Errorf("WatchDebounce = %v", s6.WatchDebounce)
+	}
+	if s6.MaxAttempts != 7 || s6.BackoffMin != 8*time.Millisecond || s6.BackoffMax != 9*time.Second {
+		t.Errorf("retry = %d %v %v", s6.MaxAttempts, s6.BackoffMin, s6.BackoffMax)
+	}
+
+	// An explicit service type overrides detection
+	client, err = NewClient(dir, WithServiceType(ServiceTypeRunit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc, ok := client.(*ClientRunit); !ok || rc.MaxAttempts != DefaultMaxAttempts {
+		t.Errorf("NewClient(WithServiceType(runit)) = %T %+v", client, client)
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	ctx, cancel := withDefaultTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("expected a deadline on a context without one")
+	}
+
+	parent, parentCancel := context.WithTimeout(context.Background(), time.Hour)
+	defer parentCancel()
+	ctx, cancel = withDefaultTimeout(parent, time.Second)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) < time.Minute {
+		t.Error("caller's deadline should be kept")
+	}
+
+	// Control retries stop at the default timeout instead of exhausting all attempts
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(dir,
+		WithServiceType(ServiceTypeRunit),
+		WithDefaultTimeout(50*time.Millisecond),
+		WithControlRetry(1000, 10*time.Millisecond, 10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := client.Up(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Up() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Up() took %v", elapsed)
+	}
+}
+
+func TestNewClientWithClock(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 4321); err != nil {
+		t.Fatal(err)
+	}
+
+	var now time.Time
+	client, err := NewClient(dir, WithClock(ClockFunc(func() time.Time { return now })))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := client.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = st.Since.Add(42 * time.Second)
+	st, err = client.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Uptime != 42*time.Second {
+		t.Errorf("Uptime = %v, want 42s", st.Uptime)
+	}
+}
+
+func TestWithControlBytes(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fork that uses upper-case commands and has no pause
+	fork := func() *ServiceConfig {
+		c := ConfigRunit()
+		c.ControlBytes[OpUp] = 'U'
+		delete(c.ControlBytes, OpPause)
+		return c
+	}
+	if err := RegisterPreset("test-runit-fork", fork); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Preset("test-runit-fork")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(dir, WithServiceConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(mock.ControlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "U" {
+		t.Errorf("control byte = %q, want \"U\"", data)
+	}
+
+	var opErr *OpError
+	if err := client.Pause(ctx); !errors.As(err, &opErr) || opErr.Op != OpPause {
+		t.Errorf("Pause() = %v, want an OpError for an operation missing from the table", err)
+	}
+
+	// Without overrides the upstream bytes are sent
+	client, err = NewClient(dir, WithControlBytes(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Pause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); string(data) != "p" {
+		t.Errorf("control byte = %q, want \"p\"", data)
+	}
+}
+
+func TestWithSkipIfRunning(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []OperationEvent
+	inst := InstrumentationFunc(func(ev OperationEvent) { events = append(events, ev) })
+	client, err := NewClient(dir, ServiceTypeRunit, WithSkipIfRunning(), WithInstrumentation(inst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// A stopped service is started as usual
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); string(data) != "u" {
+		t.Errorf("control byte = %q, want \"u\"", data)
+	}
+
+	// A running one is left alone, and the skipped Up reported
+	if err := mock.UpdateStatus(true, 1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mock.ControlFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	events = nil
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); len(data) != 0 {
+		t.Errorf("control bytes = %q, want none for a running service", data)
+	}
+	if len(events) != 2 || events[0].Op != OpStatus || events[1].Op != OpUp || !events[1].Skipped {
+		t.Errorf("events = %+v, want the status read, then a skipped OpUp", events)
+	}
+
+	// Without the option the control byte is always written
+	plain, err := NewClient(dir, ServiceTypeRunit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); string(data) != "u" {
+		t.Errorf("control byte = %q, want \"u\"", data)
+	}
+}
+
+func TestStatusTimeoutBoundsStatus(t *testing.T) {
+	// No status file, so Status falls back to a status tool that hangs
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := filepath.Join(t.TempDir(), "sv")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\nexec sleep 5\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(dir, WithServiceType(ServiceTypeRunit), WithStatusTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := client.(*ClientRunit)
+	rc.StatusFallback = true
+	rc.StatusToolPath = tool
+
+	start := time.Now()
+	if _, err := rc.Status(context.Background()); err == nil {
+		t.Fatal("Status() error = nil, want the status timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Status() took %v, want it bounded by the 50ms status timeout", elapsed)
+	}
+}
+
+func TestDefaultTimeoutBoundsStatus(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := filepath.Join(t.TempDir(), "sv")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\nexec sleep 5\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(dir, WithServiceType(ServiceTypeRunit), WithDefaultTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := client.(*ClientRunit)
+	rc.StatusFallback = true
+	rc.StatusToolPath = tool
+
+	// Shorter than DefaultReadTimeout, so only the default timeout can end it in time
+	start := time.Now()
+	if _, err := rc.Status(context.Background()); err == nil {
+		t.Fatal("Status() error = nil, want the default timeout")
+	}
+	if elapsed := time.Since(start); elapsed >= DefaultReadTimeout {
+		t.Errorf("Status() took %v, want it bounded by the 50ms default timeout", elapsed)
+	}
+
+	// An explicit status timeout still takes precedence
+	client, err = NewClient(dir, WithServiceType(ServiceTypeRunit), WithDefaultTimeout(time.Minute), WithStatusTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.(*ClientRunit).ReadTimeout; got != 50*time.Millisecond {
+		t.Errorf("ReadTimeout = %v, want the status timeout", got)
+	}
+}
+
+func TestNewClientSystemdTimeouts(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want time.Duration
+	}{
+		{name: "default timeout", opts: []ClientOption{WithDefaultTimeout(time.Minute)}, want: time.Minute},
+		{name: "status timeout", opts: []ClientOption{WithStatusTimeout(50 * time.Millisecond)}, want: 50 * time.Millisecond},
+		{
+			name: "status timeout wins",
+			opts: []ClientOption{WithStatusTimeout(50 * time.Millisecond), WithDefaultTimeout(time.Minute)},
+			want: 50 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient("/etc/service/web", append([]ClientOption{ServiceTypeSystemd}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := client.(*ClientSystemd).Timeout; got != tt.want {
+				t.Errorf("Timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithServiceConfigNil(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewMockSupervisor(dir); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(dir, WithServiceConfig(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*ClientRunit); !ok {
+		t.Errorf("NewClient with a nil config = %T, want the detected *ClientRunit", client)
+	}
+}
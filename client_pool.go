@@ -0,0 +1,143 @@
+package svcmgr
+
+import (
+	"container/list"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// ClientPool lazily creates and caches ServiceClients keyed by service
+// directory. It is safe for concurrent use. When MaxSize is set, the least
+// recently used client is evicted once the pool is full.
+type ClientPool struct {
+	// MaxSize is the maximum number of cached clients; zero means unlimited
+	MaxSize int
+
+	// Options are passed to NewClient for every client the pool creates
+	Options []ClientOption
+
+	// OnEvict, if set, is called with clients removed by eviction, Remove or
+	// Clear, after the pool's lock is released so it may use the pool
+	OnEvict func(serviceDir string, client ServiceClient)
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+// poolEntry is the value stored in the pool's LRU list
+type poolEntry struct {
+	dir    string
+	client ServiceClient
+}
+
+// NewClientPool creates a ClientPool holding at most maxSize clients
+// (zero for unlimited), each created with opts
+func NewClientPool(maxSize int, opts ...ClientOption) *ClientPool {
+	return &ClientPool{
+		MaxSize: maxSize,
+		Options: opts,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached client for serviceDir, creating it on first use
+func (p *ClientPool) Get(serviceDir string) (ServiceClient, error) {
+	dir, err := filepath.Abs(serviceDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving service dir: %w", err)
+	}
+
+	p.mu.Lock()
+	p.init()
+
+	if elem, ok := p.entries[dir]; ok {
+		p.lru.MoveToFront(elem)
+		p.mu.Unlock()
+		return elem.Value.(*poolEntry).client, nil
+	}
+
+	client, err := NewClient(dir, p.Options...)
+	if err != nil {
+		p.mu.Unlock()
+		return nil, err
+	}
+	p.entries[dir] = p.lru.PushFront(&poolEntry{dir: dir, client: client})
+
+	var evicted []*poolEntry
+	for p.MaxSize > 0 && p.lru.Len() > p.MaxSize {
+		evicted = append(evicted, p.removeElement(p.lru.Back()))
+	}
+	p.mu.Unlock()
+
+	p.evict(evicted)
+	return client, nil
+}
+
+// Remove drops the client for serviceDir from the pool, if present
+func (p *ClientPool) Remove(serviceDir string) {
+	dir, err := filepath.Abs(serviceDir)
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.init()
+
+	var evicted []*poolEntry
+	if elem, ok := p.entries[dir]; ok {
+		evicted = append(evicted, p.removeElement(elem))
+	}
+	p.mu.Unlock()
+
+	p.evict(evicted)
+}
+
+// Clear removes every client from the pool
+func (p *ClientPool) Clear() {
+	p.mu.Lock()
+	p.init()
+
+	var evicted []*poolEntry
+	for p.lru.Len() > 0 {
+		evicted = append(evicted, p.removeElement(p.lru.Back()))
+	}
+	p.mu.Unlock()
+
+	p.evict(evicted)
+}
+
+// Len returns the number of cached clients
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.init()
+	return p.lru.Len()
+}
+
+// init prepares a zero-value pool for use; callers must hold mu
+func (p *ClientPool) init() {
+	if p.entries == nil {
+		p.lru = list.New()
+		p.entries = make(map[string]*list.Element)
+	}
+}
+
+// removeElement removes one entry and returns it; callers must hold mu
+func (p *ClientPool) removeElement(elem *list.Element) *poolEntry {
+	entry := p.lru.Remove(elem).(*poolEntry)
+	delete(p.entries, entry.dir)
+	return entry
+}
+
+// evict reports removed entries to OnEvict; callers must not hold mu
+func (p *ClientPool) evict(entries []*poolEntry) {
+	if p.OnEvict == nil {
+		return
+	}
+	for _, entry := range entries {
+		p.OnEvict(entry.dir, entry.client)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestClientPool(t *testing.T) {
+	root := t.TempDir()
+	var dirs []string
+	for _, name := range []string{"a", "b", "c"} {
+		dir := filepath.Join(root, name)
+		if _, err := NewMockSupervisor(dir); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	var evicted []string
+	pool := NewClientPool(2, WithServiceType(ServiceTypeRunit))
+	pool.OnEvict = func(dir string, _ ServiceClient) {
+		// The pool is unlocked, so OnEvict may use it
+		if n := pool.Len(); n > 2 {
+			t.Errorf("Len() in OnEvict = %d, want the entry already removed", n)
+		}
+		evicted = append(evicted, filepath.Base(dir))
+	}
+
+	first, err := pool.Get(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := pool.Get(dirs[0] + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != again {
+		t.Error("Get returned a new client for a cached directory")
+	}
+
+	// a is most recently used, so adding c evicts b
+	for _, dir := range []string{dirs[1], dirs[0], dirs[2]} {
+		if _, err := pool.Get(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pool.Len() != 2 || len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("Len() = %d, evicted = %v", pool.Len(), evicted)
+	}
+
+	if _, err := pool.Get(filepath.Join(root, "missing")); err == nil {
+		t.Error("expected error for unsupervised directory")
+	}
+
+	pool.Clear()
+	if pool.Len() != 0 || len(evicted) != 3 {
+		t.Errorf("Len() after Clear = %d, evicted = %v", pool.Len(), evicted)
+	}
+}
+
+func TestClientPoolConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewMockSupervisor(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var pool ClientPool
+	clients := make([]ServiceClient, 16)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = pool.Get(dir)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range clients {
+		if c == nil || c != clients[0] {
+			t.Fatal("concurrent Get calls returned different clients")
+		}
+	}
+}
@@ -1,10 +1,10 @@
 package svcmgr
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"path/filepath"
 	"sync"
@@ -26,7 +26,8 @@ type ClientRunit struct {
 	// WriteTimeout is the timeout for writing control commands
 	WriteTimeout time.Duration
 
-	// ReadTimeout is the timeout for reading status information
+	// ReadTimeout bounds Status, including the status tool fallback, when
+	// its context has no deadline; zero means DefaultTimeout
 	ReadTimeout time.Duration
 
 	// BackoffMin is the minimum duration between retry attempts
@@ -41,6 +42,54 @@ type ClientRunit struct {
 	// WatchDebounce is the debounce duration for watch events to coalesce rapid changes
 	WatchDebounce time.Duration
 
+	// WatchBuffer is the capacity of Watch channels; zero means
+	// DefaultWatchBuffer
+	WatchBuffer int
+
+	// WatchOverflow is what Watch does when its channel is full
+	WatchOverflow WatchOverflow
+
+	// DefaultTimeout bounds control and status operations whose context has
+	// no deadline; zero leaves such operations unbounded
+	DefaultTimeout time.Duration
+
+	// Clock computes Status.Uptime; nil uses the wall clock
+	Clock Clock
+
+	// ControlBytes overrides the bytes written to the control file, for
+	// forks whose control protocol differs from upstream; nil uses the
+	// defaults of ConfigRunit
+	ControlBytes map[Operation]byte
+
+	// StrictDecode rejects status files with unknown flag values or impossible
+	// timestamps instead of decoding them best-effort
+	StrictDecode bool
+
+	// StatusFallback makes Status parse the output of the native status tool
+	// (sv status) when the status file cannot be read, e.g. due to permissions
+	StatusFallback bool
+
+	// StatusToolPath is the path to the status tool used by StatusFallback
+	StatusToolPath string
+
+	// VerifyPID makes Status check that the process the status file names
+	// exists, downgrading the state when it does not (see WithVerifyPID)
+	VerifyPID bool
+
+	// Pinned, when set, makes status reads and control writes open the
+	// supervise files relative to a held directory, refusing symlinks,
+	// instead of resolving ServiceDir on every operation. Control sockets
+	// are not dialed, as they can only be reached by path.
+	Pinned *PinnedDir
+
+	// Instrumentation, when set, observes control commands and status
+	// reads (see WithInstrumentation)
+	Instrumentation Instrumentation
+
+	// SkipIfRunning makes Up a no-op when the service is already running
+	// and wanted up (see WithSkipIfRunning)
+	SkipIfRunning bool
+
 	// mu protects concurrent access to send operations
 	mu sync.Mutex
 }
@@ -54,14 +103,15 @@ func NewClientRunit(serviceDir string) (*ClientRunit, error) {
 	}
 
 	rc := &ClientRunit{
-		ServiceDir:    absPath,
-		DialTimeout:   DefaultDialTimeout,
-		WriteTimeout:  DefaultWriteTimeout,
-		ReadTimeout:   DefaultReadTimeout,
-		BackoffMin:    DefaultBackoffMin,
-		BackoffMax:    DefaultBackoffMax,
-		MaxAttempts:   DefaultMaxAttempts,
-		WatchDebounce: DefaultWatchDebounce,
+		ServiceDir:     absPath,
+		DialTimeout:    DefaultDialTimeout,
+		WriteTimeout:   DefaultWriteTimeout,
+		ReadTimeout:    DefaultReadTimeout,
+		BackoffMin:     DefaultBackoffMin,
+		BackoffMax:     DefaultBackoffMax,
+		MaxAttempts:    DefaultMaxAttempts,
+		WatchDebounce:  DefaultWatchDebounce,
+		StatusToolPath: DefaultSvPath,
 	}
 
 	superviseDir := filepath.Join(rc.ServiceDir, SuperviseDir)
@@ -74,19 +124,35 @@ func NewClientRunit(serviceDir string) (*ClientRunit, error) {
 
 // send writes a single control byte to the service's control socket/FIFO.
 // It implements exponential backoff and retries for transient failures.
-func (rc *ClientRunit) send(ctx context.Context, op Operation) error {
+func (rc *ClientRunit) send(ctx context.Context, op Operation) (err error) {
+	start := time.Now()
+	retries := 0
+	defer func() { observeOperation(ctx, rc.Instrumentation, rc.ServiceDir, op, start, retries, err) }()
+
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 
-	// Runit supports all operations
+	ctx, cancel := withDefaultTimeout(ctx, rc.DefaultTimeout)
+	defer cancel()
+
+	config := rc.serviceConfig()
+	cmd, ok := config.ControlByte(op)
+	if !ok {
+		return &OpError{
+			Op:   op,
+			Path: rc.ServiceDir,
+			Err:  fmt.Errorf("operation %s not supported by runit", op),
+		}
+	}
+
 	controlPath := filepath.Join(rc.ServiceDir, SuperviseDir, ControlFile)
-	cmd := op.Byte()
 
 	var lastErr error
 	backoff := rc.BackoffMin
 
 	for attempt := 0; attempt < rc.MaxAttempts; attempt++ {
 		if attempt > 0 {
+			retries = attempt
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -99,7 +165,7 @@ func (rc *ClientRunit) send(ctx context.Context, op Operation) error {
 			}
 		}
 
-		conn, err := net.DialTimeout("unix", controlPath, rc.DialTimeout)
+		conn, err := dialControl(controlPath, rc.DialTimeout, rc.Pinned)
 		if err == nil {
 			defer func() { _ = conn.Close() }()
 
@@ -107,18 +173,24 @@ func (rc *ClientRunit) send(ctx context.Context, op Operation) error {
 				_ = conn.SetWriteDeadline(time.Now().Add(rc.WriteTimeout))
 			}
 
-			if _, err := conn.Write([]byte{cmd}); err == nil {
+			_, err = conn.Write([]byte{cmd})
+			if err == nil {
 				return nil
 			}
 			lastErr = err
 			continue
 		}
 
-		file, err := os.OpenFile(controlPath, os.O_WRONLY|unix.ONonblock, 0)
+		file, err := openSuperviseFile(rc.Pinned, controlPath, os.O_WRONLY|unix.ONonblock)
 		if err == nil {
 			defer func() { _ = file.Close() }()
 
-			if _, err := file.Write([]byte{cmd}); err == nil {
+			if rc.WriteTimeout > 0 {
+				_ = file.SetWriteDeadline(time.Now().Add(rc.WriteTimeout))
+			}
+
+			_, err = file.Write([]byte{cmd})
+			if err == nil {
 				return nil
 			}
 			lastErr = err
@@ -136,6 +208,9 @@ func (rc *ClientRunit) send(ctx context.Context, op Operation) error {
 
 // Up starts the service (sets want up)
 func (rc *ClientRunit) Up(ctx context.Context) error {
+	if rc.SkipIfRunning && skipUp(ctx, rc.Status, rc.Instrumentation, rc.ServiceDir) {
+		return nil
+	}
 	return rc.send(ctx, OpUp)
 }
 
@@ -226,12 +301,18 @@ func (rc *ClientRunit) ExitSupervise(ctx context.Context) error {
 
 // Status reads and decodes the service's binary status file.
 // It returns typed Status information without shelling out to sv.
-func (rc *ClientRunit) Status(_ context.Context) (Status, error) {
+func (rc *ClientRunit) Status(ctx context.Context) (_ Status, err error) {
+	start := time.Now()
+	defer func() { observeOperation(ctx, rc.Instrumentation, rc.ServiceDir, OpStatus, start, 0, err) }()
+
+	ctx, cancel := withDefaultTimeout(ctx, cmp.Or(rc.ReadTimeout, rc.DefaultTimeout))
+	defer cancel()
+
 	statusPath := filepath.Join(rc.ServiceDir, SuperviseDir, StatusFile)
 
-	file, err := os.Open(statusPath)
+	file, err := openSuperviseFile(rc.Pinned, statusPath, os.O_RDONLY)
 	if err != nil {
-		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
+		return rc.statusFallback(ctx, statusPath, err)
 	}
 	defer func() { _ = file.Close() }()
 
@@ -249,14 +330,66 @@ func (rc *ClientRunit) Status(_ context.Context) (Status, error) {
 		}
 	}
 
+	if rc.StrictDecode {
+		if err := validateStatusRunit(buf); err != nil {
+			return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
+		}
+	}
+
 	// Decode using runit-specific decoder
-	status, err := decodeStatusRunit(buf)
+	status, err := decodeStatusRunitAt(buf, clockNow(rc.Clock))
 	if err != nil {
 		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
 	}
 
+	if rc.VerifyPID {
+		status = verifyPID(status, rc.ServiceDir, DefaultProcDir)
+	}
+	return status, nil
+}
+
+// statusFallback parses sv status output when the status file cannot be opened,
+// or returns the original error if StatusFallback is disabled
+func (rc *ClientRunit) statusFallback(ctx context.Context, statusPath string, cause error) (Status, error) {
+	if !rc.StatusFallback {
+		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: cause}
+	}
+
+	status, err := runStatusTool(ctx, rc.Clock, parseSvStatusAt, rc.StatusToolPath, "status", rc.ServiceDir)
+	if err != nil {
+		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: fmt.Errorf("%w (fallback: %w)", cause, err)}
+	}
+	if rc.VerifyPID {
+		status = verifyPID(status, rc.ServiceDir, DefaultProcDir)
+	}
 	return status, nil
 }
 
+// StatusExtended returns the status together with resource usage of the
+// main process read from /proc
+func (rc *ClientRunit) StatusExtended(ctx context.Context) (StatusExtended, error) {
+	return statusExtended(ctx, rc.Status, DefaultProcDir)
+}
+
+// ProcessTree returns the main process and all of its descendants from /proc,
+// or nil when the service has no process
+func (rc *ClientRunit) ProcessTree(ctx context.Context) ([]ProcessInfo, error) {
+	return processTree(ctx, rc.Status, DefaultProcDir)
+}
+
 // Ensure ClientRunit implements ServiceClient
-var _ ServiceClient = (*ClientRunit)(nil)
+var (
+	_ ServiceClient      = (*ClientRunit)(nil)
+	_ ProcessInspector   = (*ClientRunit)(nil)
+	_ FuncWaiter         = (*ClientRunit)(nil)
+	_ CapabilityReporter = (*ClientRunit)(nil)
+)
+
+// serviceConfig returns the runit configuration with ControlBytes applied
+func (rc *ClientRunit) serviceConfig() *ServiceConfig {
+	config := ConfigRunit()
+	if rc.ControlBytes != nil {
+		config.ControlBytes = rc.ControlBytes
+	}
+	return config
+}
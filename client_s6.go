@@ -1,10 +1,10 @@
 package svcmgr
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"path/filepath"
 	"sync"
@@ -26,7 +26,8 @@ type ClientS6 struct {
 	// WriteTimeout is the timeout for writing control commands
 	WriteTimeout time.Duration
 
-	// ReadTimeout is the timeout for reading status information
+	// ReadTimeout bounds Status, including the status tool fallback, when
+	// its context has no deadline; zero means DefaultTimeout
 	ReadTimeout time.Duration
 
 	// BackoffMin is the minimum duration between retry attempts
@@ -41,6 +42,59 @@ type ClientS6 struct {
 	// WatchDebounce is the debounce duration for watch events to coalesce rapid changes
 	WatchDebounce time.Duration
 
+	// WatchBuffer is the capacity of Watch channels; zero means
+	// DefaultWatchBuffer
+	WatchBuffer int
+
+	// WatchOverflow is what Watch does when its channel is full
+	WatchOverflow WatchOverflow
+
+	// DefaultTimeout bounds control and status operations whose context has
+	// no deadline; zero leaves such operations unbounded
+	DefaultTimeout time.Duration
+
+	// Clock computes Status.Uptime; nil uses the wall clock
+	Clock Clock
+
+	// ControlBytes overrides the bytes written to the control file, for
+	// forks whose control protocol differs from upstream; nil uses the
+	// defaults of ConfigS6
+	ControlBytes map[Operation]byte
+
+	// StrictDecode rejects status files with unknown flag values or impossible
+	// timestamps instead of decoding them best-effort
+	StrictDecode bool
+
+	// S6Format is the status layout of the installed s6 release (see
+	// S6FormatForVersion), consulted only for status files of unknown size;
+	// S6FormatUnknown tries every known layout
+	S6Format S6FormatVersion
+
+	// StatusFallback makes Status parse the output of the native status tool
+	// (s6-svstat) when the status file cannot be read, e.g. due to permissions
+	StatusFallback bool
+
+	// StatusToolPath is the path to the status tool used by StatusFallback
+	StatusToolPath string
+
+	// VerifyPID makes Status check that the process the status file names
+	// exists, downgrading the state when it does not (see WithVerifyPID)
+	VerifyPID bool
+
+	// Pinned, when set, makes status reads and control writes open the
+	// supervise files relative to a held directory, refusing symlinks,
+	// instead of resolving ServiceDir on every operation. Control sockets
+	// are not dialed, as they can only be reached by path.
+	Pinned *PinnedDir
+
+	// Instrumentation, when set, observes control commands and status
+	// reads (see WithInstrumentation)
+	Instrumentation Instrumentation
+
+	// SkipIfRunning makes Up a no-op when the service is already running
+	// and wanted up (see WithSkipIfRunning)
+	SkipIfRunning bool
+
 	// mu protects concurrent access to send operations
 	mu sync.Mutex
 }
@@ -54,14 +108,15 @@ func NewClientS6(serviceDir string) (*ClientS6, error) {
 	}
 
 	cs := &ClientS6{
-		ServiceDir:    absPath,
-		DialTimeout:   DefaultDialTimeout,
-		WriteTimeout:  DefaultWriteTimeout,
-		ReadTimeout:   DefaultReadTimeout,
-		BackoffMin:    DefaultBackoffMin,
-		BackoffMax:    DefaultBackoffMax,
-		MaxAttempts:   DefaultMaxAttempts,
-		WatchDebounce: DefaultWatchDebounce,
+		ServiceDir:     absPath,
+		DialTimeout:    DefaultDialTimeout,
+		WriteTimeout:   DefaultWriteTimeout,
+		ReadTimeout:    DefaultReadTimeout,
+		BackoffMin:     DefaultBackoffMin,
+		BackoffMax:     DefaultBackoffMax,
+		MaxAttempts:    DefaultMaxAttempts,
+		WatchDebounce:  DefaultWatchDebounce,
+		StatusToolPath: DefaultS6SvstatPath,
 	}
 
 	superviseDir := filepath.Join(cs.ServiceDir, SuperviseDir)
@@ -74,13 +129,21 @@ func NewClientS6(serviceDir string) (*ClientS6, error) {
 
 // send writes a single control byte to the service's control socket/FIFO.
 // It implements exponential backoff and retries for transient failures.
-func (cs *ClientS6) send(ctx context.Context, op Operation) error {
+func (cs *ClientS6) send(ctx context.Context, op Operation) (err error) {
+	start := time.Now()
+	retries := 0
+	defer func() { observeOperation(ctx, cs.Instrumentation, cs.ServiceDir, op, start, retries, err) }()
+
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
+	ctx, cancel := withDefaultTimeout(ctx, cs.DefaultTimeout)
+	defer cancel()
+
 	// Check if this operation is supported by s6
-	config := ConfigS6()
-	if !config.IsOperationSupported(op) {
+	config := cs.serviceConfig()
+	cmd, ok := config.ControlByte(op)
+	if !ok {
 		return &OpError{
 			Op:   op,
 			Path: cs.ServiceDir,
@@ -89,13 +152,13 @@ func (cs *ClientS6) send(ctx context.Context, op Operation) error {
 	}
 
 	controlPath := filepath.Join(cs.ServiceDir, SuperviseDir, ControlFile)
-	cmd := op.Byte()
 
 	var lastErr error
 	backoff := cs.BackoffMin
 
 	for attempt := 0; attempt < cs.MaxAttempts; attempt++ {
 		if attempt > 0 {
+			retries = attempt
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -108,7 +171,7 @@ func (cs *ClientS6) send(ctx context.Context, op Operation) error {
 			}
 		}
 
-		conn, err := net.DialTimeout("unix", controlPath, cs.DialTimeout)
+		conn, err := dialControl(controlPath, cs.DialTimeout, cs.Pinned)
 		if err == nil {
 			defer func() { _ = conn.Close() }()
 
@@ -116,18 +179,24 @@ func (cs *ClientS6) send(ctx context.Context, op Operation) error {
 				_ = conn.SetWriteDeadline(time.Now().Add(cs.WriteTimeout))
 			}
 
-			if _, err := conn.Write([]byte{cmd}); err == nil {
+			_, err = conn.Write([]byte{cmd})
+			if err == nil {
 				return nil
 			}
 			lastErr = err
 			continue
 		}
 
-		file, err := os.OpenFile(controlPath, os.O_WRONLY|unix.ONonblock, 0)
+		file, err := openSuperviseFile(cs.Pinned, controlPath, os.O_WRONLY|unix.ONonblock)
 		if err == nil {
 			defer func() { _ = file.Close() }()
 
-			if _, err := file.Write([]byte{cmd}); err == nil {
+			if cs.WriteTimeout > 0 {
+				_ = file.SetWriteDeadline(time.Now().Add(cs.WriteTimeout))
+			}
+
+			_, err = file.Write([]byte{cmd})
+			if err == nil {
 				return nil
 			}
 			lastErr = err
@@ -145,6 +214,9 @@ func (cs *ClientS6) send(ctx context.Context, op Operation) error {
 
 // Up starts the service (sets want up)
 func (cs *ClientS6) Up(ctx context.Context) error {
+	if cs.SkipIfRunning && skipUp(ctx, cs.Status, cs.Instrumentation, cs.ServiceDir) {
+		return nil
+	}
 	return cs.send(ctx, OpUp)
 }
 
@@ -245,41 +317,89 @@ func (cs *ClientS6) ExitSupervise(ctx context.Context) error {
 
 // Status reads and decodes the service's binary status file.
 // It returns typed Status information.
-func (cs *ClientS6) Status(_ context.Context) (Status, error) {
+func (cs *ClientS6) Status(ctx context.Context) (_ Status, err error) {
+	start := time.Now()
+	defer func() { observeOperation(ctx, cs.Instrumentation, cs.ServiceDir, OpStatus, start, 0, err) }()
+
+	ctx, cancel := withDefaultTimeout(ctx, cmp.Or(cs.ReadTimeout, cs.DefaultTimeout))
+	defer cancel()
+
 	statusPath := filepath.Join(cs.ServiceDir, SuperviseDir, StatusFile)
 
-	file, err := os.Open(statusPath)
+	file, err := openSuperviseFile(cs.Pinned, statusPath, os.O_RDONLY)
 	if err != nil {
-		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
+		return cs.statusFallback(ctx, statusPath, err)
 	}
 	defer func() { _ = file.Close() }()
 
-	// S6 status files can be either 35 or 43 bytes
-	// Allocate for the maximum
-	buf := make([]byte, S6MaxStatusSize)
+	// S6 status files are 35 or 43 bytes; read more so layouts of newer
+	// releases can be negotiated
+	buf := make([]byte, S6MaxNegotiatedStatusSize)
 	n, err := io.ReadFull(file, buf)
 	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
 		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
 	}
 
-	// Validate size
-	if n != S6StatusSizePre220 && n != S6StatusSizeCurrent {
-		return Status{}, &OpError{
-			Op:   OpStatus,
-			Path: statusPath,
-			Err: fmt.Errorf("invalid S6 status file size: %d bytes (expected %d or %d)",
-				n, S6StatusSizePre220, S6StatusSizeCurrent),
+	if cs.StrictDecode {
+		if err := validateStatusS6(buf[:n], cs.S6Format); err != nil {
+			return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
 		}
 	}
 
 	// Decode using s6-specific decoder
-	status, err := decodeStatusS6(buf[:n])
+	status, err := decodeStatusS6Hint(buf[:n], clockNow(cs.Clock), cs.S6Format)
 	if err != nil {
 		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: err}
 	}
 
+	if cs.VerifyPID {
+		status = verifyPID(status, cs.ServiceDir, DefaultProcDir)
+	}
+	return status, nil
+}
+
+// statusFallback parses s6-svstat output when the status file cannot be opened,
+// or returns the original error if StatusFallback is disabled
+func (cs *ClientS6) statusFallback(ctx context.Context, statusPath string, cause error) (Status, error) {
+	if !cs.StatusFallback {
+		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: cause}
+	}
+
+	status, err := runStatusTool(ctx, cs.Clock, parseS6SvstatAt, cs.StatusToolPath, cs.ServiceDir)
+	if err != nil {
+		return Status{}, &OpError{Op: OpStatus, Path: statusPath, Err: fmt.Errorf("%w (fallback: %w)", cause, err)}
+	}
+	if cs.VerifyPID {
+		status = verifyPID(status, cs.ServiceDir, DefaultProcDir)
+	}
 	return status, nil
 }
 
+// StatusExtended returns the status together with resource usage of the
+// main process read from /proc
+func (cs *ClientS6) StatusExtended(ctx context.Context) (StatusExtended, error) {
+	return statusExtended(ctx, cs.Status, DefaultProcDir)
+}
+
+// ProcessTree returns the main process and all of its descendants from /proc,
+// or nil when the service has no process
+func (cs *ClientS6) ProcessTree(ctx context.Context) ([]ProcessInfo, error) {
+	return processTree(ctx, cs.Status, DefaultProcDir)
+}
+
 // Ensure ClientS6 implements ServiceClient
-var _ ServiceClient = (*ClientS6)(nil)
+var (
+	_ ServiceClient      = (*ClientS6)(nil)
+	_ ProcessInspector   = (*ClientS6)(nil)
+	_ FuncWaiter         = (*ClientS6)(nil)
+	_ CapabilityReporter = (*ClientS6)(nil)
+)
+
+// serviceConfig returns the s6 configuration with ControlBytes applied
+func (cs *ClientS6) serviceConfig() *ServiceConfig {
+	config := ConfigS6()
+	if cs.ControlBytes != nil {
+		config.ControlBytes = cs.ControlBytes
+	}
+	return config
+}
@@ -0,0 +1,31 @@
+package svcmgr
+
+import "time"
+
+// Clock supplies the current time used to compute Status.Uptime.
+// Setting a fixed clock makes Uptime deterministic in tests and when
+// replaying recorded status files.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock returns a Clock that always reports t
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// clockNow returns the time from clock, or the wall clock when clock is nil
+func clockNow(clock Clock) time.Time {
+	if clock == nil {
+		return time.Now()
+	}
+	return clock.Now()
+}
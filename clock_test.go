@@ -0,0 +1,89 @@
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeWithClock(t *testing.T) {
+	data := makeStatusData(1234, 'u', 0, 1)
+	st, err := DecodeStatusRunit(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoders := []struct {
+		name   string
+		decode func([]byte, ...DecodeOption) (Status, error)
+		data   []byte
+	}{
+		{name: "runit", decode: DecodeStatusRunit, data: data},
+		{name: "daemontools", decode: DecodeStatusDaemontools, data: data[:DaemontoolsStatusSize]},
+		{name: "s6", decode: DecodeStatusS6, data: makeS6StatusCurrent(1234, 0x04)},
+	}
+
+	for _, tt := range decoders {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.decode(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clock := FixedClock(got.Since.Add(90 * time.Second))
+			for range 2 {
+				got, err = tt.decode(tt.data, WithDecodeClock(clock))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Uptime != 90*time.Second {
+					t.Errorf("Uptime = %v, want 90s", got.Uptime)
+				}
+			}
+
+			// A clock before the record's timestamp never yields a negative uptime
+			got, err = tt.decode(tt.data, WithDecodeClock(FixedClock(st.Since.Add(-time.Hour))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Uptime != 0 {
+				t.Errorf("Uptime = %v, want 0", got.Uptime)
+			}
+		})
+	}
+}
+
+func TestStatusFallbackWithClock(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := filepath.Join(t.TempDir(), "sv")
+	script := "#!/bin/sh\necho 'run: " + dir + ": (pid 123) 45s; run: log: (pid 124) 45s'\n"
+	if err := os.WriteFile(tool, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(dir, WithServiceType(ServiceTypeRunit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := client.(*ClientRunit)
+	rc.StatusFallback = true
+	rc.StatusToolPath = tool
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rc.Clock = FixedClock(now)
+
+	st, err := rc.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Uptime != 45*time.Second {
+		t.Errorf("Uptime = %v, want 45s", st.Uptime)
+	}
+	if want := now.Add(-45 * time.Second); !st.Since.Equal(want) {
+		t.Errorf("Since = %v, want %v", st.Since, want)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// stdin is read by apply without -f; a variable so tests can replace it
+var stdin io.Reader = os.Stdin
+
+// apply runs a batch of "<service> <operation>" lines from -f or stdin
+// through a Manager, printing each step's outcome and a summary. It exits
+// non-zero when any step failed or was skipped.
+func (c *cli) apply(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	file := fs.String("f", "-", "batch file; - reads stdin")
+	concurrency := fs.Int("concurrency", 10, "number of services operated on at once")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(c.stderr, "usage: svcmgr apply [-f file] [-concurrency N]")
+		return exitUsage
+	}
+
+	in := stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "svcmgr: apply: %v\n", err)
+			return exitError
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	steps, err := svcmgr.ParseBatch(in)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "svcmgr: apply: %v\n", err)
+		return exitUsage
+	}
+
+	// Report services as written in the batch
+	names := make(map[string]string, len(steps))
+	for i := range steps {
+		dir := c.serviceDir(steps[i].Service)
+		names[dir] = steps[i].Service
+		steps[i].Service = dir
+	}
+
+	var done, failed, skipped int
+	mgr := svcmgr.NewManager(svcmgr.WithConcurrency(*concurrency), svcmgr.WithTimeout(c.timeout))
+	_ = mgr.Apply(ctx, steps, func(res svcmgr.BatchResult) {
+		done++
+		step := fmt.Sprintf("[%d/%d] %s %s", done, len(steps), names[res.Step.Service], res.Step.Op)
+		switch {
+		case errors.Is(res.Err, svcmgr.ErrStepSkipped):
+			skipped++
+			fmt.Fprintf(c.stdout, "%s: skipped\n", step)
+		case res.Err != nil:
+			failed++
+			fmt.Fprintf(c.stdout, "%s: failed: %v\n", step, res.Err)
+		default:
+			fmt.Fprintf(c.stdout, "%s: ok (%v)\n", step, res.Duration.Round(time.Millisecond))
+		}
+	})
+	fmt.Fprintf(c.stdout, "%d steps: %d ok, %d failed, %d skipped\n", len(steps), len(steps)-failed-skipped, failed, skipped)
+	if failed+skipped > 0 {
+		return exitError
+	}
+	return exitOK
+}
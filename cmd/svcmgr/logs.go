@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// journalctl runs journalctl; a variable so tests can stub it out
+var journalctl = func(ctx context.Context, c *cli, args []string) error {
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	cmd.Stdout, cmd.Stderr = c.stdout, c.stderr
+	return cmd.Run()
+}
+
+// logs prints the last lines of a service's log and optionally follows it.
+// svlogd, s6-log and multilog directories are read directly; services
+// without one are assumed to log to the journal.
+func (c *cli) logs(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	follow := fs.Bool("f", false, "follow the log until interrupted")
+	n := fs.Int("n", 10, "number of lines to print; 0 prints the whole log")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "usage: svcmgr logs [-f] [-n N] <service>")
+		return exitUsage
+	}
+	service := fs.Arg(0)
+
+	if *follow {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
+
+	dir, err := svcmgr.FindLogDir(c.serviceDir(service))
+	if errors.Is(err, os.ErrNotExist) {
+		jargs := []string{"-u", filepath.Base(service), "-n", strconv.Itoa(*n), "--no-pager"}
+		if *n <= 0 {
+			jargs[3] = "all"
+		}
+		if *follow {
+			jargs = append(jargs, "-f")
+		}
+		if err := journalctl(ctx, c, jargs); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(c.stderr, "svcmgr: logs %s: %v\n", service, err)
+			return exitError
+		}
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(c.stderr, "svcmgr: logs %s: %v\n", service, err)
+		return exitError
+	}
+
+	lines, err := svcmgr.TailLog(dir, *n)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "svcmgr: logs %s: %v\n", service, err)
+		return exitError
+	}
+	for _, line := range lines {
+		c.printLogLine(line)
+	}
+	if !*follow {
+		return exitOK
+	}
+	if err := svcmgr.FollowLog(ctx, dir, 0, c.printLogLine); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(c.stderr, "svcmgr: logs %s: %v\n", service, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// printLogLine prints a log line, prefixed by its decoded time when it has one
+func (c *cli) printLogLine(line svcmgr.LogLine) {
+	if line.Time.IsZero() {
+		fmt.Fprintln(c.stdout, line.Text)
+		return
+	}
+	fmt.Fprintf(c.stdout, "%s %s\n", line.Time.Local().Format(time.RFC3339Nano), line.Text)
+}
@@ -0,0 +1,178 @@
+// Command svcmgr controls and inspects services supervised by runit,
+// daemontools, s6 or systemd.
+//
+// Usage:
+//
+//	svcmgr [-d dir] [-timeout d] <command> [flags] <service>...
+//
+// Services are service directories, or names looked up in the scan
+// directory given by -d, $SVDIR or /etc/service. The supervision system is
+// detected from each service's supervise directory.
+//
+// Commands:
+//
+//	status     print the status; exits with the LSB status code
+//	logs       print the last lines of the log (-n N), following it with -f
+//	watch      show a live status table of the services, or of a scan directory
+//	apply      run "<service> <operation>" lines from -f file or stdin
+//	up, down, once, restart, pause, cont, exit
+//	term, kill, hup, alarm, interrupt, quit, usr1, usr2
+//
+// logs reads svlogd, s6-log and multilog directories (log/main or log) of
+// runit, daemontools and s6 services, decoding TAI64N timestamps and
+// following rotations; services without one are read from the journal.
+//
+// # Machine-readable output
+//
+// status -format json (or -json) prints one JSON object per service in the
+// stable Status schema:
+//
+//	{"service":"web","status":{"schema_version":1,"state":"running",...}}
+//
+// Services whose status cannot be read carry an "error" instead of
+// "status". The exit code is the highest LSB status code of the services,
+// so scripts can rely on it rather than parsing text.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// defaultScanDir is used when neither -d nor $SVDIR is set
+const defaultScanDir = "/etc/service"
+
+// Exit codes besides the LSB status codes of the status command
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// controls maps control command names to the Controller methods performing them
+var controls = map[string]func(svcmgr.Controller, context.Context) error{
+	"up":        svcmgr.Controller.Up,
+	"down":      svcmgr.Controller.Down,
+	"once":      svcmgr.Controller.Once,
+	"restart":   svcmgr.Controller.Restart,
+	"pause":     svcmgr.Controller.Pause,
+	"cont":      svcmgr.Controller.Continue,
+	"exit":      svcmgr.Controller.ExitSupervise,
+	"term":      svcmgr.Controller.Term,
+	"kill":      svcmgr.Controller.Kill,
+	"hup":       svcmgr.Controller.HUP,
+	"alarm":     svcmgr.Controller.Alarm,
+	"interrupt": svcmgr.Controller.Interrupt,
+	"quit":      svcmgr.Controller.Quit,
+	"usr1":      svcmgr.Controller.USR1,
+	"usr2":      svcmgr.Controller.USR2,
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// cli holds the global flags shared by every command
+type cli struct {
+	scanDir string
+	timeout time.Duration
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// run executes the command line args and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet("svcmgr", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.scanDir, "d", "", "scan directory services are looked up in (default $SVDIR or "+defaultScanDir+")")
+	fs.DurationVar(&c.timeout, "timeout", 7*time.Second, "timeout for each operation")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: svcmgr [-d dir] [-timeout d] <command> [flags] <service>...")
+		fmt.Fprintln(stderr, "commands: status, logs, watch, apply, up, down, once, restart, pause, cont, exit, term, kill, hup, alarm, interrupt, quit, usr1, usr2")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if c.scanDir == "" {
+		c.scanDir = os.Getenv("SVDIR")
+	}
+	if c.scanDir == "" {
+		c.scanDir = defaultScanDir
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "status":
+		return c.status(ctx, rest)
+	case "logs":
+		return c.logs(ctx, rest)
+	case "watch":
+		return c.watch(ctx, rest)
+	case "apply":
+		return c.apply(ctx, rest)
+	}
+	if op, ok := controls[cmd]; ok {
+		return c.control(ctx, cmd, op, rest)
+	}
+	fmt.Fprintf(stderr, "svcmgr: unknown command %q\n", cmd)
+	fs.Usage()
+	return exitUsage
+}
+
+// serviceDir resolves a service argument to its directory
+func (c *cli) serviceDir(service string) string {
+	if service == "." || service == ".." || strings.ContainsRune(service, filepath.Separator) {
+		return service
+	}
+	return filepath.Join(c.scanDir, service)
+}
+
+// control performs op on every service, reporting failures on stderr
+func (c *cli) control(ctx context.Context, name string, op func(svcmgr.Controller, context.Context) error, args []string) int {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintf(c.stderr, "usage: svcmgr %s <service>...\n", name)
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, service := range fs.Args() {
+		err := c.withClient(ctx, service, func(ctx context.Context, client svcmgr.ServiceClient) error {
+			return op(client, ctx)
+		})
+		if err != nil {
+			fmt.Fprintf(c.stderr, "svcmgr: %s %s: %v\n", name, service, err)
+			code = exitError
+		}
+	}
+	return code
+}
+
+// withClient calls fn with a client for service, bounded by the timeout
+func (c *cli) withClient(ctx context.Context, service string, fn func(context.Context, svcmgr.ServiceClient) error) error {
+	client, err := svcmgr.NewClient(c.serviceDir(service))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return fn(ctx, client)
+}
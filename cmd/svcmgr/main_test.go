@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+// newScanDir creates a scan directory with a running "web" and a stopped "db"
+func newScanDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	web, err := svcmgrtest.NewMockSupervisor(filepath.Join(dir, "web"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := web.UpdateStatus(true, 123); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svcmgrtest.NewMockSupervisor(filepath.Join(dir, "db")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStatusJSON(t *testing.T) {
+	dir := newScanDir(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode svcmgr.LSBStatus
+		want     map[string]svcmgr.State
+	}{
+		{name: "running", args: []string{"status", "-json", "web"}, wantCode: svcmgr.LSBRunning, want: map[string]svcmgr.State{"web": svcmgr.StateRunning}},
+		{name: "highest code wins", args: []string{"status", "-format", "json", "web", "db"}, wantCode: svcmgr.LSBNotRunning, want: map[string]svcmgr.State{"web": svcmgr.StateRunning, "db": svcmgr.StateDown}},
+		{name: "path", args: []string{"status", "-json", filepath.Join(dir, "db")}, wantCode: svcmgr.LSBNotRunning, want: map[string]svcmgr.State{filepath.Join(dir, "db"): svcmgr.StateDown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), append([]string{"-d", dir}, tt.args...), &stdout, &stderr)
+			if code != int(tt.wantCode) {
+				t.Errorf("exit code = %d, want %d (stderr %q)", code, tt.wantCode, stderr.String())
+			}
+
+			got := make(map[string]svcmgr.State)
+			dec := json.NewDecoder(&stdout)
+			for dec.More() {
+				var rec statusRecord
+				if err := dec.Decode(&rec); err != nil {
+					t.Fatal(err)
+				}
+				if rec.Status == nil {
+					t.Fatalf("%s: no status: %s", rec.Service, rec.Error)
+				}
+				got[rec.Service] = rec.Status.State
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+			for svc, state := range tt.want {
+				if got[svc] != state {
+					t.Errorf("%s state = %v, want %v", svc, got[svc], state)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	dir := newScanDir(t)
+
+	// Unsupervised services are reported with an error and LSB "not running"
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-d", dir, "status", "-json", "missing"}, &stdout, &stderr)
+	if code != int(svcmgr.LSBNotRunning) {
+		t.Errorf("exit code = %d, want %d", code, svcmgr.LSBNotRunning)
+	}
+	var rec statusRecord
+	if err := json.Unmarshal(stdout.Bytes(), &rec); err != nil || rec.Error == "" || rec.Status != nil {
+		t.Errorf("record = %+v, %v, want an error", rec, err)
+	}
+
+	stdout.Reset()
+	if code := run(context.Background(), []string{"-d", dir, "status", "web"}, &stdout, &stderr); code != 0 {
+		t.Errorf("text status exit code = %d", code)
+	}
+	if !strings.HasPrefix(stdout.String(), "running: web: (pid 123)") {
+		t.Errorf("text status = %q", stdout.String())
+	}
+
+	for _, args := range [][]string{{}, {"status"}, {"status", "-format", "xml", "web"}, {"frobnicate", "web"}} {
+		if code := run(context.Background(), append([]string{"-d", dir}, args...), &stdout, &stderr); code != exitUsage {
+			t.Errorf("run(%q) = %d, want usage error", args, code)
+		}
+	}
+}
+
+func TestControl(t *testing.T) {
+	dir := newScanDir(t)
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-d", dir, "down", "web"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code = %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "web", "supervise", "control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "d" {
+		t.Errorf("control = %q, want \"d\"", data)
+	}
+
+	if code := run(context.Background(), []string{"-d", dir, "up", "missing"}, &stdout, &stderr); code != exitError {
+		t.Errorf("exit code for a missing service = %d, want %d", code, exitError)
+	}
+}
+
+func TestLogs(t *testing.T) {
+	dir := newScanDir(t)
+	logDir := filepath.Join(dir, "web", "log", "main")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(logDir, svcmgr.CurrentLogFile), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var journalArgs []string
+	orig := journalctl
+	journalctl = func(_ context.Context, _ *cli, args []string) error {
+		journalArgs = args
+		return nil
+	}
+	t.Cleanup(func() { journalctl = orig })
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantOut     string
+		wantJournal []string
+	}{
+		{name: "default", args: []string{"logs", "web"}, wantOut: "one\ntwo\nthree\n"},
+		{name: "last lines", args: []string{"logs", "-n", "2", "web"}, wantOut: "two\nthree\n"},
+		{name: "journald", args: []string{"logs", "-n", "5", "db"}, wantJournal: []string{"-u", "db", "-n", "5", "--no-pager"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journalArgs = nil
+			var stdout, stderr bytes.Buffer
+			if code := run(context.Background(), append([]string{"-d", dir}, tt.args...), &stdout, &stderr); code != exitOK {
+				t.Fatalf("exit code = %d (stderr %q)", code, stderr.String())
+			}
+			if stdout.String() != tt.wantOut {
+				t.Errorf("output = %q, want %q", stdout.String(), tt.wantOut)
+			}
+			if strings.Join(journalArgs, " ") != strings.Join(tt.wantJournal, " ") {
+				t.Errorf("journalctl args = %q, want %q", journalArgs, tt.wantJournal)
+			}
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for a command writing while the test reads
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatch(t *testing.T) {
+	dir := newScanDir(t)
+	web, err := svcmgrtest.NewMockSupervisor(filepath.Join(dir, "web"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := web.UpdateStatus(true, 123); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stdout, stderr syncBuffer
+	done := make(chan int, 1)
+	go func() {
+		// Watches the whole scan directory
+		done <- run(ctx, []string{"-d", dir, "watch", "-interval", "10ms", dir}, &stdout, &stderr)
+	}()
+
+	// lastFrame waits for a redraw satisfying ok and returns it
+	lastFrame := func(ok func(string) bool) string {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			out := stdout.String()
+			if i := strings.LastIndex(out, ansiClear); i >= 0 && ok(out[i+len(ansiClear):]) {
+				return out[i+len(ansiClear):]
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("no matching frame in %q (stderr %q)", stdout.String(), stderr.String())
+		return ""
+	}
+
+	frame := lastFrame(func(f string) bool { return strings.Contains(f, "web") })
+	if !strings.Contains(frame, "db") || !regexp.MustCompile(`web\s+running\s+123`).MatchString(frame) {
+		t.Errorf("initial frame = %q", frame)
+	}
+	if strings.Contains(frame, ansiHighlight) {
+		t.Errorf("initial frame highlights a service: %q", frame)
+	}
+
+	if err := web.UpdateStatus(false, 0); err != nil {
+		t.Fatal(err)
+	}
+	frame = lastFrame(func(f string) bool { return strings.Contains(f, ansiHighlight) })
+	if !regexp.MustCompile(regexp.QuoteMeta(ansiHighlight) + `web\s+down`).MatchString(frame) {
+		t.Errorf("frame after stopping web = %q", frame)
+	}
+
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Errorf("exit code = %d (stderr %q)", code, stderr.String())
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := newScanDir(t)
+	batch := filepath.Join(t.TempDir(), "batch.txt")
+	if err := os.WriteFile(batch, []byte("# runbook\nweb down\ndb up\nweb up\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-d", dir, "apply", "-f", batch}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code = %d (stdout %q, stderr %q)", code, stdout.String(), stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "3 steps: 3 ok, 0 failed, 0 skipped\n") {
+		t.Errorf("output = %q", stdout.String())
+	}
+	for svc, want := range map[string]string{"web": "u", "db": "u"} {
+		data, err := os.ReadFile(filepath.Join(dir, svc, "supervise", "control"))
+		if err != nil || string(data) != want {
+			t.Errorf("%s control = %q, %v, want %q", svc, data, err, want)
+		}
+	}
+
+	// Steps after a failure are skipped; the batch is read from stdin
+	orig := stdin
+	stdin = strings.NewReader("missing up\nmissing down\nweb down\n")
+	t.Cleanup(func() { stdin = orig })
+	stdout.Reset()
+	if code := run(context.Background(), []string{"-d", dir, "apply"}, &stdout, &stderr); code != exitError {
+		t.Errorf("exit code = %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stdout.String(), "missing down: skipped") || !strings.HasSuffix(stdout.String(), "3 steps: 1 ok, 1 failed, 1 skipped\n") {
+		t.Errorf("output = %q", stdout.String())
+	}
+
+	stdin = strings.NewReader("web frobnicate\n")
+	if code := run(context.Background(), []string{"-d", dir, "apply"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("exit code for an invalid batch = %d, want %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// Output formats of the status command
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// statusRecord is one line of "status -format json"
+type statusRecord struct {
+	Service string         `json:"service"`
+	Status  *svcmgr.Status `json:"status,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// status prints the status of every service and returns the highest LSB
+// status code among them
+func (c *cli) status(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	format := fs.String("format", formatText, "output format: text or json")
+	asJSON := fs.Bool("json", false, "shorthand for -format json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *asJSON {
+		*format = formatJSON
+	}
+	if *format != formatText && *format != formatJSON {
+		fmt.Fprintf(c.stderr, "svcmgr: unknown format %q\n", *format)
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "usage: svcmgr status [-format text|json] <service>...")
+		return exitUsage
+	}
+
+	enc := json.NewEncoder(c.stdout)
+	code := svcmgr.LSBRunning
+	for _, service := range fs.Args() {
+		var st svcmgr.Status
+		err := c.withClient(ctx, service, func(ctx context.Context, client svcmgr.ServiceClient) error {
+			var err error
+			st, err = client.Status(ctx)
+			return err
+		})
+		code = max(code, svcmgr.LSBStatusOf(st, err))
+
+		switch *format {
+		case formatJSON:
+			rec := statusRecord{Service: service}
+			if err != nil {
+				rec.Error = err.Error()
+			} else {
+				rec.Status = &st
+			}
+			if err := enc.Encode(rec); err != nil {
+				fmt.Fprintf(c.stderr, "svcmgr: %v\n", err)
+				return int(svcmgr.LSBUnknown)
+			}
+		default:
+			if err != nil {
+				fmt.Fprintf(c.stdout, "%s: %s: %v\n", svcmgr.StateUnknown, service, err)
+				continue
+			}
+			fmt.Fprintln(c.stdout, formatStatus(service, st))
+		}
+	}
+	return int(code)
+}
+
+// formatStatus renders st like sv status: "run: web: (pid 123) 45s"
+func formatStatus(service string, st svcmgr.Status) string {
+	s := fmt.Sprintf("%s: %s:", st.State, service)
+	if st.PID > 0 {
+		s += fmt.Sprintf(" (pid %d)", st.PID)
+	}
+	s += fmt.Sprintf(" %ds", int(st.Uptime/time.Second))
+	if st.Flags.WantUp && st.State != svcmgr.StateRunning {
+		s += ", want up"
+	}
+	if st.Flags.WantDown && st.State == svcmgr.StateRunning {
+		s += ", want down"
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// ANSI escape sequences used by the watch table
+const (
+	ansiClear     = "\x1b[H\x1b[2J"
+	ansiHighlight = "\x1b[1;7m"
+	ansiReset     = "\x1b[0m"
+)
+
+// watchRow is the last known status of a watched service
+type watchRow struct {
+	status  svcmgr.Status
+	err     error
+	changed time.Time // when the state last changed; zero before the first change
+}
+
+// watch renders a status table of the services that is redrawn on every
+// status change and every -interval, highlighting services whose state
+// changed within -highlight. With no services, or a single scan directory,
+// every service in the directory is watched.
+func (c *cli) watch(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	interval := fs.Duration("interval", time.Second, "redraw interval")
+	highlight := fs.Duration("highlight", 10*time.Second, "how long state changes stay highlighted")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	services := c.watchTargets(fs.Args())
+	if len(services) == 0 {
+		fmt.Fprintln(c.stderr, "svcmgr: watch: no services")
+		return exitError
+	}
+	slices.SortStableFunc(services, func(a, b string) int { return strings.Compare(displayName(a), displayName(b)) })
+	clients := make(map[string]svcmgr.ServiceClient, len(services))
+	rows := make(map[string]*watchRow, len(services))
+	for _, service := range services {
+		client, err := svcmgr.NewClient(c.serviceDir(service))
+		if err != nil {
+			fmt.Fprintf(c.stderr, "svcmgr: watch %s: %v\n", service, err)
+			return exitError
+		}
+		clients[service] = client
+		row := &watchRow{}
+		row.status, row.err = client.Status(ctx)
+		rows[service] = row
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	type event struct {
+		service string
+		ev      svcmgr.WatchEvent
+	}
+	events := make(chan event)
+	done := make(chan error, 1)
+	go func() {
+		done <- svcmgr.WatchMany(ctx, clients, func(service string, ev svcmgr.WatchEvent) {
+			select {
+			case events <- event{service, ev}:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		c.renderWatch(services, rows, time.Now(), *highlight)
+		select {
+		case <-ctx.Done():
+			<-done
+			return exitOK
+		case err := <-done:
+			if ctx.Err() != nil {
+				return exitOK
+			}
+			fmt.Fprintf(c.stderr, "svcmgr: watch: %v\n", err)
+			return exitError
+		case <-ticker.C:
+		case e := <-events:
+			row := rows[e.service]
+			if e.ev.Err != nil {
+				row.err = e.ev.Err
+				continue
+			}
+			if row.err != nil || row.status.State != e.ev.Status.State {
+				row.changed = time.Now()
+			}
+			row.status, row.err = e.ev.Status, nil
+		}
+	}
+}
+
+// watchTargets returns the services to watch: the arguments, or the
+// services of the scan directory given as the only argument, or of the
+// default scan directory without arguments
+func (c *cli) watchTargets(args []string) []string {
+	root := c.scanDir
+	switch len(args) {
+	case 0:
+	case 1:
+		dir := c.serviceDir(args[0])
+		if info, err := os.Stat(filepath.Join(dir, svcmgr.SuperviseDir)); err == nil && info.IsDir() {
+			return args
+		}
+		root = dir
+	default:
+		return args
+	}
+
+	var services []string
+	for dir := range svcmgr.Services(root) {
+		services = append(services, dir)
+	}
+	return services
+}
+
+// displayName is how a service argument is shown: the base name of paths
+func displayName(service string) string {
+	if strings.ContainsRune(service, filepath.Separator) {
+		return filepath.Base(service)
+	}
+	return service
+}
+
+// renderWatch redraws the status table
+func (c *cli) renderWatch(services []string, rows map[string]*watchRow, now time.Time, highlight time.Duration) {
+	width := len("SERVICE")
+	for _, service := range services {
+		width = max(width, len(displayName(service)))
+	}
+
+	var b strings.Builder
+	b.WriteString(ansiClear)
+	fmt.Fprintf(&b, "%-*s  %-9s  %7s  %8s  %s\n", width, "SERVICE", "STATE", "PID", "UPTIME", "CHANGED")
+	for _, service := range services {
+		row := rows[service]
+		state, pid, uptime := svcmgr.StateUnknown.String(), "-", "-"
+		if row.err == nil {
+			state = row.status.State.String()
+			if row.status.PID > 0 {
+				pid = strconv.Itoa(row.status.PID)
+			}
+			if !row.status.Since.IsZero() {
+				uptime = now.Sub(row.status.Since).Truncate(time.Second).String()
+			}
+		}
+		changed := "-"
+		if !row.changed.IsZero() {
+			changed = now.Sub(row.changed).Truncate(time.Second).String() + " ago"
+		}
+		line := fmt.Sprintf("%-*s  %-9s  %7s  %8s  %s", width, displayName(service), state, pid, uptime, changed)
+		if row.err != nil {
+			line += "  " + row.err.Error()
+		}
+		if !row.changed.IsZero() && now.Sub(row.changed) < highlight {
+			line = ansiHighlight + line + ansiReset
+		}
+		b.WriteString(line + "\n")
+	}
+	fmt.Fprint(c.stdout, b.String())
+}
@@ -0,0 +1,96 @@
+package svcmgr
+
+import (
+	"context"
+	"log/slog"
+)
+
+// CorrelationIDKey is the slog attribute key of correlation IDs
+const CorrelationIDKey = "correlation_id"
+
+// correlationIDKey is the context key of the correlation ID
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying id, which every operation
+// performed with it reports in OperationEvent.CorrelationID, and which
+// CorrelationHandler adds to log records, so a multi-step orchestration can
+// be followed end to end
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID attached to ctx
+func CorrelationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// CorrelationHandler wraps a slog.Handler to add the correlation ID of the
+// context passed to the logger's Context methods (InfoContext, ...) to
+// each record, as the CorrelationIDKey attribute
+func CorrelationHandler(h slog.Handler) slog.Handler {
+	return correlationHandler{h}
+}
+
+// correlationHandler implements CorrelationHandler
+type correlationHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler
+func (h correlationHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := CorrelationID(ctx); ok {
+		r = r.Clone()
+		r.AddAttrs(slog.String(CorrelationIDKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h correlationHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return correlationHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h correlationHandler) WithGroup(name string) slog.Handler {
+	return correlationHandler{h.Handler.WithGroup(name)}
+}
+
+// SlogInstrumentation is an Instrumentation writing one record per
+// operation to a slog.Logger, an audit trail of what was done to which
+// service, by which correlated request, and how it went. Successful
+// operations are logged at Info, failed ones at Warn.
+type SlogInstrumentation struct {
+	Logger *slog.Logger
+}
+
+// ObserveOperation implements Instrumentation
+func (s SlogInstrumentation) ObserveOperation(ev OperationEvent) {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	attrs := []slog.Attr{slog.String("op", ev.Op.String())}
+	if ev.Service != "" {
+		attrs = append(attrs, slog.String("service", ev.Service))
+	} else {
+		attrs = append(attrs, slog.Int("services", ev.Services))
+	}
+	attrs = append(attrs, slog.Duration("duration", ev.Duration))
+	if ev.Retries > 0 {
+		attrs = append(attrs, slog.Int("retries", ev.Retries))
+	}
+	if ev.Skipped {
+		attrs = append(attrs, slog.Bool("skipped", true))
+	}
+	if ev.CorrelationID != "" {
+		attrs = append(attrs, slog.String(CorrelationIDKey, ev.CorrelationID))
+	}
+
+	level := slog.LevelInfo
+	if ev.Err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", ev.Err.Error()), slog.String("category", ErrorCategory(ev.Err)))
+	}
+	logger.LogAttrs(context.Background(), level, "svcmgr operation", attrs...)
+}
@@ -0,0 +1,95 @@
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCorrelationID(t *testing.T) {
+	ctx := context.Background()
+	if id, ok := CorrelationID(ctx); ok {
+		t.Errorf("CorrelationID of a bare context = %q, want none", id)
+	}
+	ctx = WithCorrelationID(ctx, "deploy-42")
+	if id, ok := CorrelationID(ctx); !ok || id != "deploy-42" {
+		t.Errorf("CorrelationID = %q, %v, want deploy-42", id, ok)
+	}
+}
+
+func TestCorrelationHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(CorrelationHandler(slog.NewTextHandler(&buf, nil))).With("component", "rollout")
+
+	logger.InfoContext(WithCorrelationID(context.Background(), "deploy-42"), "restarting")
+	logger.InfoContext(context.Background(), "idle")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "component=rollout") || !strings.Contains(lines[0], "correlation_id=deploy-42") {
+		t.Errorf("record = %q, want component and correlation_id", lines[0])
+	}
+	if strings.Contains(lines[1], "correlation_id") {
+		t.Errorf("record without correlation ID = %q", lines[1])
+	}
+}
+
+func TestCorrelationIDReachesInstrumentation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	audit := SlogInstrumentation{Logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	client, err := NewClient(dir, ServiceTypeRunit,
+		WithControlRetry(1, time.Millisecond, time.Millisecond), WithInstrumentation(audit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager(WithManagerInstrumentation(audit))
+
+	ctx := WithCorrelationID(context.Background(), "deploy-42")
+	_ = client.Up(ctx)
+	_, _ = mgr.Status(ctx, dir)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// Client up, the manager's client status read, then the manager's status
+	if len(lines) != 3 {
+		t.Fatalf("logged %d records, want 3:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "correlation_id=deploy-42") {
+			t.Errorf("record without correlation ID: %s", line)
+		}
+	}
+	if !strings.Contains(lines[0], "level=WARN") || !strings.Contains(lines[0], "op=up") || !strings.Contains(lines[0], "category=") {
+		t.Errorf("failed up record = %s", lines[0])
+	}
+	if !strings.Contains(lines[2], "services=1") {
+		t.Errorf("manager record = %s", lines[2])
+	}
+}
+
+func TestSlogInstrumentationLevels(t *testing.T) {
+	var buf bytes.Buffer
+	audit := SlogInstrumentation{Logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	audit.ObserveOperation(OperationEvent{Service: "/etc/service/web", Op: OpRestart, Duration: time.Millisecond})
+	audit.ObserveOperation(OperationEvent{Service: "/etc/service/web", Op: OpTerm, Retries: 2, Err: errors.New("boom")})
+
+	out := buf.String()
+	if !strings.Contains(out, "level=INFO") || !strings.Contains(out, "op=restart") {
+		t.Errorf("success record missing:\n%s", out)
+	}
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "retries=2") || !strings.Contains(out, "category=other") {
+		t.Errorf("failure record missing:\n%s", out)
+	}
+}
@@ -0,0 +1,145 @@
+package svcmgr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CrashLoopEvent reports a service taken down by CrashLoopBreaker
+type CrashLoopEvent struct {
+	// Service names the service
+	Service string
+	// Crashes is the number of crashes within the window
+	Crashes int
+	// Status is the status that tripped the breaker
+	Status Status
+	// Time is when the breaker tripped
+	Time time.Time
+	// Err is the error bringing the service down, if any
+	Err error
+}
+
+// CrashLoopBreaker is a client-side circuit breaker for crash loops.
+// Supervisors restart a failing service forever by design; the breaker
+// brings a service down once it has crashed more than MaxCrashes times
+// within Window, and reports it through OnTrip.
+//
+// A crash is a process ending while the service is wanted up, counted as
+// History.Failures does. A tripped service is left alone until it is seen
+// wanted down, as the breaker's Down leaves it, and then wanted up again,
+// for example by an operator's Up, which re-arms the breaker with a clean
+// slate. Statuses still wanted up that were queued before the Down took
+// effect do not re-arm it. When the Down fails, the service stays tripped
+// until Reset.
+type CrashLoopBreaker struct {
+	// MaxCrashes is the number of crashes within Window that is tolerated
+	MaxCrashes int
+	// Window is the sliding window crashes are counted over
+	Window time.Duration
+	// OnTrip is called after a tripped service was brought down
+	OnTrip func(CrashLoopEvent)
+	// Clock supplies the crash times; nil uses the wall clock
+	Clock Clock
+
+	mu       sync.Mutex
+	services map[string]*crashLoopService
+}
+
+// crashLoopService tracks one service for the breaker
+type crashLoopService struct {
+	crashes []time.Time
+	tripped bool
+	// down is set once a tripped service was seen wanted down
+	down bool
+}
+
+// NewCrashLoopBreaker creates a breaker that trips after more than
+// maxCrashes crashes within window
+func NewCrashLoopBreaker(maxCrashes int, window time.Duration, onTrip func(CrashLoopEvent)) *CrashLoopBreaker {
+	return &CrashLoopBreaker{MaxCrashes: maxCrashes, Window: window, OnTrip: onTrip}
+}
+
+// Observe records a status change of service from prev to st and reports
+// whether the breaker trips, returning the crash count within the window.
+// It does not act on the service; Run does.
+func (b *CrashLoopBreaker) Observe(service string, prev, st Status) (bool, int) {
+	now := clockNow(b.Clock)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.services == nil {
+		b.services = make(map[string]*crashLoopService)
+	}
+	s, ok := b.services[service]
+	if !ok {
+		s = &crashLoopService{}
+		b.services[service] = s
+	}
+
+	if s.tripped {
+		if !st.Flags.WantUp {
+			s.down = true
+			return false, 0
+		}
+		if !s.down {
+			return false, 0
+		}
+		*s = crashLoopService{}
+	}
+
+	h := History{
+		{State: prev.State, PID: prev.PID, WantUp: prev.Flags.WantUp},
+		{State: st.State, PID: st.PID, WantUp: st.Flags.WantUp},
+	}
+	if !h.failedAt(1) {
+		return false, len(s.crashes)
+	}
+
+	cutoff := now.Add(-b.Window)
+	kept := s.crashes[:0]
+	for _, t := range s.crashes {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	s.crashes = append(kept, now)
+
+	if len(s.crashes) <= b.MaxCrashes {
+		return false, len(s.crashes)
+	}
+	s.tripped = true
+	return true, len(s.crashes)
+}
+
+// Reset clears the crash count of service and re-arms the breaker for it
+func (b *CrashLoopBreaker) Reset(service string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.services, service)
+}
+
+// Run watches every client, keyed by service name, bringing down services
+// that trip the breaker, until ctx is done. It returns ctx.Err(), the error
+// from starting a watch, or ErrWatchClosed if every watch ended first.
+func (b *CrashLoopBreaker) Run(ctx context.Context, clients map[string]ServiceClient) error {
+	return watchAll(ctx, clients, func(name string, prev Status, ev WatchEvent) {
+		if ev.Err != nil {
+			return
+		}
+		trip, crashes := b.Observe(name, prev, ev.Status)
+		if !trip {
+			return
+		}
+		e := CrashLoopEvent{
+			Service: name,
+			Crashes: crashes,
+			Status:  ev.Status,
+			Time:    clockNow(b.Clock),
+			Err:     clients[name].Down(ctx),
+		}
+		if b.OnTrip != nil {
+			b.OnTrip(e)
+		}
+	})
+}
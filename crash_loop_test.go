@@ -0,0 +1,99 @@
+package svcmgr_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func running(pid int) svcmgr.Status {
+	return svcmgr.Status{State: svcmgr.StateRunning, PID: pid, Flags: svcmgr.Flags{WantUp: true}}
+}
+
+func TestCrashLoopBreakerObserve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := svcmgr.NewCrashLoopBreaker(2, time.Minute, nil)
+	b.Clock = svcmgr.ClockFunc(func() time.Time { return now })
+
+	observe := func(prev, st svcmgr.Status) (bool, int) {
+		t.Helper()
+		now = now.Add(10 * time.Second)
+		return b.Observe("api", prev, st)
+	}
+
+	// An intentional stop is not a crash
+	down := svcmgr.Status{State: svcmgr.StateDown, Flags: svcmgr.Flags{WantDown: true}}
+	if trip, n := observe(running(10), down); trip || n != 0 {
+		t.Errorf("stop counted as crash: %v %d", trip, n)
+	}
+
+	if trip, n := observe(running(10), running(11)); trip || n != 1 {
+		t.Errorf("first crash = %v %d", trip, n)
+	}
+	// Crashes older than the window are forgotten
+	now = now.Add(2 * time.Minute)
+	if trip, n := observe(running(11), running(12)); trip || n != 1 {
+		t.Errorf("crash after window = %v %d, want 1", trip, n)
+	}
+	if trip, n := observe(running(12), running(13)); trip || n != 2 {
+		t.Errorf("second crash = %v %d", trip, n)
+	}
+	if trip, n := observe(running(13), running(14)); !trip || n != 3 {
+		t.Errorf("third crash = %v %d, want trip", trip, n)
+	}
+
+	// Tripped services are ignored until wanted up again, and crashes
+	// queued before the breaker's Down took effect do not re-arm it
+	if trip, n := observe(running(14), running(15)); trip || n != 0 {
+		t.Errorf("queued crash after trip = %v %d, want ignored", trip, n)
+	}
+	if trip, _ := observe(running(15), down); trip {
+		t.Error("tripped again while down")
+	}
+	if trip, n := observe(down, running(20)); trip || n != 0 {
+		t.Errorf("re-armed breaker = %v %d, want a clean slate", trip, n)
+	}
+
+	b.Reset("api")
+	if trip, n := observe(running(20), running(21)); trip || n != 1 {
+		t.Errorf("after Reset = %v %d", trip, n)
+	}
+}
+
+func TestCrashLoopBreakerRun(t *testing.T) {
+	client := svcmgrtest.NewFakeClient()
+	client.SetStatus(running(100))
+
+	tripped := make(chan svcmgr.CrashLoopEvent, 1)
+	b := svcmgr.NewCrashLoopBreaker(2, time.Minute, func(e svcmgr.CrashLoopEvent) { tripped <- e })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx, map[string]svcmgr.ServiceClient{"api": client}) }()
+
+	// Keep crashing until the breaker brings the service down
+	var e svcmgr.CrashLoopEvent
+	for pid := 101; e.Service == ""; pid++ {
+		client.SetStatus(running(pid))
+		select {
+		case e = <-tripped:
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("breaker did not trip")
+		}
+	}
+	if e.Service != "api" || e.Crashes != 3 || e.Err != nil {
+		t.Errorf("event = %+v", e)
+	}
+	if !slices.Equal(client.Ops(), []svcmgr.Operation{svcmgr.OpDown}) {
+		t.Errorf("ops = %v, want [down]", client.Ops())
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,40 @@
+package svcmgr
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer runs fn once events stop arriving for a delay. Bursts are
+// coalesced onto a single timer that is allocated once and reset per
+// event, so a watch costs no allocations per change however many watches
+// are active.
+type debouncer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+// newDebouncer creates an idle debouncer for fn
+func newDebouncer(fn func()) *debouncer {
+	d := &debouncer{timer: time.AfterFunc(time.Hour, fn)}
+	d.timer.Stop()
+	return d
+}
+
+// trigger (re)schedules fn to run after delay, superseding a pending run
+func (d *debouncer) trigger(delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.stopped {
+		d.timer.Reset(delay)
+	}
+}
+
+// stop cancels a pending run; later triggers are ignored
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	d.timer.Stop()
+}
@@ -0,0 +1,80 @@
+package svcmgr
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncerCoalesces(t *testing.T) {
+	var runs atomic.Int32
+	d := newDebouncer(func() { runs.Add(1) })
+	for range 5 {
+		d.trigger(20 * time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if n := runs.Load(); n != 1 {
+		t.Errorf("fn ran %d times for one burst, want 1", n)
+	}
+
+	d.trigger(5 * time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if n := runs.Load(); n != 2 {
+		t.Errorf("fn ran %d times after a second burst, want 2", n)
+	}
+}
+
+func TestDebouncerStop(t *testing.T) {
+	var runs atomic.Int32
+	d := newDebouncer(func() { runs.Add(1) })
+	d.trigger(5 * time.Millisecond)
+	d.stop()
+	d.trigger(5 * time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if n := runs.Load(); n != 0 {
+		t.Errorf("fn ran %d times after stop, want 0", n)
+	}
+}
+
+// BenchmarkWatchDebounce triggers the debounce of many active watches, as a
+// burst of status changes does, comparing the reused timer with allocating
+// one per event
+func BenchmarkWatchDebounce(b *testing.B) {
+	const watches = 5000
+	fn := func() {}
+
+	b.Run("reset", func(b *testing.B) {
+		ds := make([]*debouncer, watches)
+		for i := range ds {
+			ds[i] = newDebouncer(fn)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ds[i%watches].trigger(time.Hour)
+		}
+		b.StopTimer()
+		for _, d := range ds {
+			d.stop()
+		}
+	})
+
+	b.Run("afterfunc", func(b *testing.B) {
+		timers := make([]*time.Timer, watches)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if t := timers[i%watches]; t != nil {
+				t.Stop()
+			}
+			timers[i%watches] = time.AfterFunc(time.Hour, fn)
+		}
+		b.StopTimer()
+		for _, t := range timers {
+			if t != nil {
+				t.Stop()
+			}
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package svcmgr
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultDoctorLogLines is how many recent log lines a DoctorReport holds
+const DefaultDoctorLogLines = 20
+
+// ScriptCheck describes one of a service's scripts
+type ScriptCheck struct {
+	// Path is the script's path
+	Path string `json:"path"`
+	// Exists reports that the script is present
+	Exists bool `json:"exists"`
+	// Mode is the script's file mode
+	Mode fs.FileMode `json:"mode,omitempty"`
+	// Executable reports that the script has an execute bit set
+	Executable bool `json:"executable"`
+	// Interpreter is the #! line's interpreter, empty without one
+	Interpreter string `json:"interpreter,omitempty"`
+}
+
+// LoggerHealth describes a service's log service
+type LoggerHealth struct {
+	// Dir is the log directory holding the current file, empty when none
+	// was found
+	Dir string `json:"dir,omitempty"`
+	// Supervised reports that a supervisor is attached to the log service
+	Supervised bool `json:"supervised"`
+	// LastWrite is when the current log file was last written
+	LastWrite time.Time `json:"last_write,omitzero"`
+}
+
+// DoctorReport is a structured diagnosis of a service for support tooling
+type DoctorReport struct {
+	// ServiceDir is the service directory
+	ServiceDir string `json:"service_dir"`
+	// Type is the supervision system detected from the supervise directory
+	Type ServiceType `json:"type"`
+	// Run, Finish and LogRun describe the service's scripts; only Run is
+	// required
+	Run    ScriptCheck `json:"run"`
+	Finish ScriptCheck `json:"finish"`
+	LogRun ScriptCheck `json:"log_run"`
+	// Supervised reports that a supervisor is attached (see Ok)
+	Supervised bool `json:"supervised"`
+	// Stale reports a supervise directory left behind by a dead supervisor
+	Stale bool `json:"stale"`
+	// StatusHex is a hex dump of the raw status file
+	StatusHex string `json:"status_hex,omitempty"`
+	// Status is the decoded status, nil when it could not be read
+	Status *Status `json:"status,omitempty"`
+	// StatusError is why the status could not be read or decoded
+	StatusError string `json:"status_error,omitempty"`
+	// Logger describes the log service, nil when the service has none
+	Logger *LoggerHealth `json:"logger,omitempty"`
+	// LogLines are the most recent log lines
+	LogLines []LogLine `json:"log_lines,omitempty"`
+	// Problems lists the issues found, in plain words
+	Problems []string `json:"problems,omitempty"`
+}
+
+// doctor diagnoses the service in dir, decoding its status with status
+func doctor(ctx context.Context, dir string, status func(context.Context) (Status, error)) (*DoctorReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r := &DoctorReport{
+		ServiceDir: dir,
+		Type:       detectServiceType(dir),
+		Run:        checkScript(filepath.Join(dir, "run")),
+		Finish:     checkScript(filepath.Join(dir, "finish")),
+		LogRun:     checkScript(filepath.Join(dir, "log", "run")),
+	}
+	problem := func(format string, args ...any) {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, s := range []ScriptCheck{r.Run, r.Finish, r.LogRun} {
+		switch {
+		case !s.Exists && s.Path == r.Run.Path:
+			problem("run script %s is missing", s.Path)
+		case s.Exists && !s.Executable:
+			problem("%s is not executable (mode %04o)", s.Path, s.Mode.Perm())
+		}
+	}
+
+	r.Supervised, _ = Ok(dir)
+	if !r.Supervised {
+		r.Stale, _ = StaleSupervise(dir)
+	}
+	switch {
+	case r.Stale:
+		problem("supervise directory is stale: its supervisor is no longer running")
+	case !r.Supervised:
+		problem("no supervisor is attached")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, SuperviseDir, StatusFile)); err == nil {
+		r.StatusHex = hex.Dump(data)
+	}
+	if st, err := status(ctx); err != nil {
+		r.StatusError = err.Error()
+		problem("status cannot be read: %v", err)
+	} else {
+		r.Status = &st
+		if st.Flags.WantUp && st.State != StateRunning && r.Supervised {
+			problem("service wants up but is %s", st.State)
+		}
+	}
+
+	if r.LogRun.Exists {
+		r.Logger = &LoggerHealth{}
+		r.Logger.Supervised, _ = Ok(filepath.Join(dir, "log"))
+		if !r.Logger.Supervised && r.Supervised {
+			problem("log service is not supervised")
+		}
+	}
+	if logDir, err := FindLogDir(dir); err == nil {
+		if r.Logger == nil {
+			r.Logger = &LoggerHealth{}
+		}
+		r.Logger.Dir = logDir
+		if info, err := os.Stat(filepath.Join(logDir, CurrentLogFile)); err == nil {
+			r.Logger.LastWrite = info.ModTime()
+		}
+		r.LogLines, _ = TailLog(logDir, DefaultDoctorLogLines)
+	}
+	return r, nil
+}
+
+// checkScript inspects the script at path
+func checkScript(path string) ScriptCheck {
+	s := ScriptCheck{Path: path}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return s
+	}
+	s.Exists = true
+	s.Mode = info.Mode()
+	s.Executable = info.Mode().Perm()&0o111 != 0
+	if f, err := os.Open(path); err == nil {
+		buf := make([]byte, 256)
+		n, _ := f.Read(buf)
+		_ = f.Close()
+		if line, _, _ := strings.Cut(string(buf[:n]), "\n"); strings.HasPrefix(line, "#!") {
+			if fields := strings.Fields(line[2:]); len(fields) > 0 {
+				s.Interpreter = fields[0]
+			}
+		}
+	}
+	return s
+}
+
+// String formats the report for humans
+func (r *DoctorReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "service: %s (%s)\n", r.ServiceDir, r.Type)
+	for _, s := range []ScriptCheck{r.Run, r.Finish, r.LogRun} {
+		if s.Exists {
+			fmt.Fprintf(&b, "script: %s %04o %s\n", s.Path, s.Mode.Perm(), s.Interpreter)
+		}
+	}
+	fmt.Fprintf(&b, "supervised: %v, stale: %v\n", r.Supervised, r.Stale)
+	if r.Status != nil {
+		fmt.Fprintf(&b, "status: %s, pid %d, since %s\n", r.Status.State, r.Status.PID, r.Status.Since.Format(time.RFC3339))
+	}
+	if r.StatusHex != "" {
+		fmt.Fprintf(&b, "status file:\n%s", r.StatusHex)
+	}
+	if r.Logger != nil {
+		fmt.Fprintf(&b, "logger: dir %s, supervised %v", r.Logger.Dir, r.Logger.Supervised)
+		if !r.Logger.LastWrite.IsZero() {
+			fmt.Fprintf(&b, ", last write %s", r.Logger.LastWrite.Format(time.RFC3339))
+		}
+		b.WriteString("\n")
+	}
+	for _, line := range r.LogLines {
+		fmt.Fprintf(&b, "log: %s\n", line.Text)
+	}
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "problem: %s\n", p)
+	}
+	return b.String()
+}
+
+// Doctor diagnoses the service: its scripts and their permissions, the
+// supervisor, the raw and decoded status, the logger and recent log lines.
+// Findings are summarized in Problems.
+func (rc *ClientRunit) Doctor(ctx context.Context) (*DoctorReport, error) {
+	return doctor(ctx, rc.ServiceDir, rc.Status)
+}
+
+// Doctor diagnoses the service: its scripts and their permissions, the
+// supervisor, the raw and decoded status, the logger and recent log lines.
+// Findings are summarized in Problems.
+func (cd *ClientDaemontools) Doctor(ctx context.Context) (*DoctorReport, error) {
+	return doctor(ctx, cd.ServiceDir, cd.Status)
+}
+
+// Doctor diagnoses the service: its scripts and their permissions, the
+// supervisor, the raw and decoded status, the logger and recent log lines.
+// Findings are summarized in Problems.
+func (cs *ClientS6) Doctor(ctx context.Context) (*DoctorReport, error) {
+	return doctor(ctx, cs.ServiceDir, cs.Status)
+}
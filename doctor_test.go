@@ -0,0 +1,82 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	root := t.TempDir()
+	b := NewServiceBuilder("web", root).
+		WithCmd([]string{"/usr/bin/web"}).
+		WithFinish([]string{"/usr/bin/cleanup"}).
+		WithSvlogd(func(*ConfigSvlogd) {})
+	if err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "web")
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 4242); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "finish"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "log", "main", CurrentLogFile), []byte("starting\nlistening\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClientRunit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := client.Doctor(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.Run.Exists || !r.Run.Executable || r.Run.Interpreter != "/bin/sh" {
+		t.Errorf("Run = %+v", r.Run)
+	}
+	if !r.Finish.Exists || r.Finish.Executable {
+		t.Errorf("Finish = %+v, want present and not executable", r.Finish)
+	}
+	if r.Status == nil || r.Status.PID != 4242 || r.StatusHex == "" {
+		t.Errorf("Status = %+v, hex %q, error %q", r.Status, r.StatusHex, r.StatusError)
+	}
+	if r.Logger == nil || r.Logger.Dir != filepath.Join(dir, "log", "main") || r.Logger.LastWrite.IsZero() {
+		t.Errorf("Logger = %+v", r.Logger)
+	}
+	if len(r.LogLines) != 2 || r.LogLines[1].Text != "listening" {
+		t.Errorf("LogLines = %+v", r.LogLines)
+	}
+	if !slices.ContainsFunc(r.Problems, func(p string) bool { return strings.Contains(p, "finish is not executable") }) {
+		t.Errorf("Problems = %q, want the finish script reported", r.Problems)
+	}
+	if s := r.String(); !strings.Contains(s, "pid 4242") || !strings.Contains(s, "problem: ") {
+		t.Errorf("String() = %q", s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Doctor(ctx); err == nil {
+		t.Error("Doctor() with a canceled context succeeded")
+	}
+
+	diag, err := CollectServiceDiagnostics(dir, ServiceTypeRunit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diag.StatusHex != r.StatusHex || !strings.Contains(diag.ProcessInfo, "pid 4242") || !slices.Equal(diag.LastLogLines, []string{"starting", "listening"}) {
+		t.Errorf("CollectServiceDiagnostics() = %+v, want the Doctor report's findings", diag)
+	}
+}
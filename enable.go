@@ -0,0 +1,158 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Void Linux style service locations: services are installed in DefaultSvDir
+// and enabled by symlinking them into the directory runsvdir scans
+const (
+	// DefaultSvDir is where service directories are installed
+	DefaultSvDir = "/etc/sv"
+
+	// DefaultEnabledDir is the scan directory holding links to enabled services
+	DefaultEnabledDir = "/var/service"
+)
+
+// InstalledService is a service directory found in the installation directory
+type InstalledService struct {
+	// Name is the service name
+	Name string
+
+	// Dir is the installed service directory
+	Dir string
+
+	// Enabled reports whether the scan directory links to Dir
+	Enabled bool
+}
+
+// EnableService enables the service installed as svDir/name by linking it
+// into enabledDir as name. An existing link is replaced atomically, so the
+// scanner never sees the service missing; a non-link entry is left alone and
+// reported as an error.
+func EnableService(svDir, enabledDir, name string) error {
+	if err := validServiceName(name); err != nil {
+		return err
+	}
+
+	target, err := filepath.Abs(filepath.Join(svDir, name))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(target, "run")); err != nil {
+		return fmt.Errorf("service %s is not installed: %w", name, err)
+	}
+
+	link := filepath.Join(enabledDir, name)
+	if info, err := os.Lstat(link); err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("%s exists and is not a symlink", link)
+		}
+		if current, err := os.Readlink(link); err == nil && current == target {
+			return nil
+		}
+	}
+
+	// The temporary link is hidden so the scanner ignores it
+	tmp := filepath.Join(enabledDir, "."+name+".new")
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("creating link for %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("enabling %s: %w", name, err)
+	}
+	return nil
+}
+
+// DisableService disables the service linked into enabledDir as name. A
+// supervised service is brought down first and waited for, so its finish
+// script runs before the scanner stops supervising it; the link is then
+// removed. Disabling a service that is not enabled is not an error, but a
+// non-link entry is refused.
+func DisableService(ctx context.Context, enabledDir, name string) error {
+	if err := validServiceName(name); err != nil {
+		return err
+	}
+
+	link := filepath.Join(enabledDir, name)
+	info, err := os.Lstat(link)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a symlink; refusing to remove it", link)
+	}
+
+	if client, err := NewClient(link); err == nil {
+		if err := client.Down(ctx); err != nil {
+			return fmt.Errorf("stopping %s: %w", name, err)
+		}
+		stopped := func(st Status) bool { return st.State == StateDown || st.State == StateExited }
+		if _, err := waitFunc(ctx, client, stopped); err != nil {
+			return fmt.Errorf("waiting for %s to stop: %w", name, err)
+		}
+	}
+
+	if err := os.Remove(link); err != nil {
+		return fmt.Errorf("disabling %s: %w", name, err)
+	}
+	return nil
+}
+
+// InstalledServices lists the services installed in svDir in name order,
+// reporting for each whether enabledDir links to it, which finds services
+// that are installed but not enabled. Hidden entries and directories without
+// a run script are skipped.
+func InstalledServices(svDir, enabledDir string) ([]InstalledService, error) {
+	entries, err := os.ReadDir(svDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve what the scan directory links to, whatever the link names
+	enabled := make(map[string]bool)
+	if links, err := os.ReadDir(enabledDir); err == nil {
+		for _, link := range links {
+			if target, err := filepath.EvalSymlinks(filepath.Join(enabledDir, link.Name())); err == nil {
+				enabled[target] = true
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var services []InstalledService
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(svDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "run")); err != nil {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		services = append(services, InstalledService{Name: entry.Name(), Dir: dir, Enabled: enabled[resolved]})
+	}
+	return services, nil
+}
+
+// validServiceName rejects names that are not a single path element
+func validServiceName(name string) error {
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid service name: %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// installService creates a minimal service directory in svDir
+func installService(t *testing.T, svDir, name string) string {
+	t.Helper()
+	dir := filepath.Join(svDir, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run"), []byte("#!/bin/sh\nexec sleep 1000\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestEnableService(t *testing.T) {
+	svDir, enabledDir := t.TempDir(), t.TempDir()
+	installService(t, svDir, "web")
+	installService(t, svDir, "db")
+
+	if err := EnableService(svDir, enabledDir, "web"); err != nil {
+		t.Fatal(err)
+	}
+	// Enabling twice is a no-op
+	if err := EnableService(svDir, enabledDir, "web"); err != nil {
+		t.Fatal(err)
+	}
+	target, err := os.Readlink(filepath.Join(enabledDir, "web"))
+	if err != nil || target != filepath.Join(svDir, "web") {
+		t.Errorf("link target = %q, %v", target, err)
+	}
+
+	services, err := InstalledServices(svDir, enabledDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InstalledService{
+		{Name: "db", Dir: filepath.Join(svDir, "db")},
+		{Name: "web", Dir: filepath.Join(svDir, "web"), Enabled: true},
+	}
+	if len(services) != len(want) || services[0] != want[0] || services[1] != want[1] {
+		t.Errorf("InstalledServices() = %+v, want %+v", services, want)
+	}
+
+	for _, tt := range []struct {
+		name string
+		svc  string
+	}{
+		{name: "not installed", svc: "missing"},
+		{name: "path traversal", svc: "../web"},
+		{name: "hidden", svc: ".web"},
+	} {
+		if err := EnableService(svDir, enabledDir, tt.svc); err == nil {
+			t.Errorf("%s: EnableService(%q) succeeded", tt.name, tt.svc)
+		}
+	}
+
+	// A real directory in the scan directory is never replaced or removed
+	if err := os.Mkdir(filepath.Join(enabledDir, "db"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnableService(svDir, enabledDir, "db"); err == nil {
+		t.Error("EnableService replaced a directory")
+	}
+	if err := DisableService(t.Context(), enabledDir, "db"); err == nil {
+		t.Error("DisableService removed a directory")
+	}
+}
+
+func TestDisableService(t *testing.T) {
+	svDir, enabledDir := t.TempDir(), t.TempDir()
+	dir := installService(t, svDir, "web")
+	if err := EnableService(svDir, enabledDir, "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 4242); err != nil {
+		t.Fatal(err)
+	}
+
+	// The supervisor stops the service once it is told to go down
+	go func() {
+		for range 1000 {
+			time.Sleep(time.Millisecond)
+			if data, _ := os.ReadFile(mock.ControlFile); len(data) > 0 && data[0] == OpDown.Byte() {
+				_ = mock.UpdateStatus(false, 0)
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := DisableService(ctx, enabledDir, "web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(filepath.Join(enabledDir, "web")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("link not removed: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("installed service removed: %v", err)
+	}
+
+	// Disabling a service that is not enabled succeeds
+	if err := DisableService(ctx, enabledDir, "web"); err != nil {
+		t.Error(err)
+	}
+}
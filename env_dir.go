@@ -0,0 +1,117 @@
+package svcmgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/renameio/v2"
+)
+
+// ReadEnvDir reads an environment directory as used by chpst -e, envdir and
+// s6-envdir: each file names a variable and its first line is the value,
+// without trailing spaces and tabs, and with NUL bytes standing for
+// newlines. Empty files, which unset the variable, read as "". Hidden files
+// and subdirectories are ignored.
+func ReadEnvDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !envDirEntry(entry) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading env file %s: %w", entry.Name(), err)
+		}
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[:i]
+		}
+		data = bytes.TrimRight(data, " \t")
+		env[entry.Name()] = string(bytes.ReplaceAll(data, []byte{0}, []byte{'\n'}))
+	}
+	return env, nil
+}
+
+// WriteEnvDir makes dir hold exactly the variables in env, creating it if
+// needed. Each changed key is replaced atomically, so a service starting
+// meanwhile sees either the old or the new value of a variable, and files
+// of keys not in env are removed. Newlines in values are stored as NUL
+// bytes, the envdir convention, so multi-line values survive ReadEnvDir.
+func WriteEnvDir(dir string, env map[string]string) error {
+	for key := range env {
+		if err := validEnvKey(key); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return fmt.Errorf("creating env directory: %w", err)
+	}
+
+	for key, value := range env {
+		pending, err := writeEnvFile(dir, key, value)
+		if err != nil {
+			return err
+		}
+		if pending == nil {
+			continue
+		}
+		if err := pending.CloseAtomicallyReplace(); err != nil {
+			return fmt.Errorf("writing env file %s: %w", key, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, ok := env[entry.Name()]; ok || !envDirEntry(entry) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing env file %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// writeEnvFile stages value as the file of variable key in dir, returning
+// nil when the file already holds it. The file is replaced by the pending
+// file's CloseAtomicallyReplace; Cleanup discards it.
+func writeEnvFile(dir, key, value string) (*renameio.PendingFile, error) {
+	path := filepath.Join(dir, key)
+	data := []byte(strings.ReplaceAll(value, "\n", "\x00"))
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return nil, nil
+	}
+	pending, err := renameio.NewPendingFile(path, renameio.WithPermissions(FileMode), renameio.WithExistingPermissions())
+	if err != nil {
+		return nil, fmt.Errorf("writing env file %s: %w", key, err)
+	}
+	if _, err := pending.Write(data); err != nil {
+		_ = pending.Cleanup()
+		return nil, fmt.Errorf("writing env file %s: %w", key, err)
+	}
+	return pending, nil
+}
+
+// envDirEntry reports whether a directory entry is a variable of an env dir
+func envDirEntry(entry fs.DirEntry) bool {
+	return !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".")
+}
+
+// validEnvKey checks key can be stored as an env dir file
+func validEnvKey(key string) error {
+	if key == "" || strings.HasPrefix(key, ".") || strings.ContainsAny(key, "=/\x00") {
+		return fmt.Errorf("invalid environment variable name %q", key)
+	}
+	return nil
+}
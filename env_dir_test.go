@@ -0,0 +1,80 @@
+package svcmgr
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvDirRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "env")
+	env := map[string]string{
+		"PORT":  "8080",
+		"CERTS": "line one\nline two",
+		"UNSET": "",
+	}
+	if err := WriteEnvDir(dir, env); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadEnvDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, env) {
+		t.Errorf("ReadEnvDir() = %q, want %q", got, env)
+	}
+
+	// Stale keys are removed, hidden files and directories left alone
+	if err := os.WriteFile(filepath.Join(dir, ".keep"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEnvDir(dir, map[string]string{"PORT": "9090"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ReadEnvDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"PORT": "9090"}; !maps.Equal(got, want) {
+		t.Errorf("after update ReadEnvDir() = %q, want %q", got, want)
+	}
+	for _, name := range []string{".keep", "sub"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestReadEnvDirFormat(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"FIRST_LINE": "value\nignored\n",
+		"TRAILING":   "value \t",
+		"NUL":        "a\x00b",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ReadEnvDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"FIRST_LINE": "value", "TRAILING": "value", "NUL": "a\nb"}
+	if !maps.Equal(got, want) {
+		t.Errorf("ReadEnvDir() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteEnvDirInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "A=B", "../PATH", ".hidden"} {
+		if err := WriteEnvDir(t.TempDir(), map[string]string{key: "x"}); err == nil {
+			t.Errorf("WriteEnvDir(%q) succeeded", key)
+		}
+	}
+}
@@ -18,6 +18,22 @@ var (
 
 	// ErrDecode indicates the status file could not be decoded
 	ErrDecode = errors.New("runit: status decode")
+
+	// ErrDependencyFailed indicates a service was not started because a
+	// service it requires could not be started or did not become ready
+	ErrDependencyFailed = errors.New("runit: required dependency failed")
+
+	// ErrStepSkipped indicates a plan step or an ordered bulk operation was
+	// not run because an earlier step or service failed
+	ErrStepSkipped = errors.New("runit: skipped after an earlier step failed")
+
+	// ErrWatchClosed indicates a watch ended while its caller was still
+	// waiting for a status or watching for changes
+	ErrWatchClosed = errors.New("runit: watch closed")
+
+	// ErrStateUnreachable indicates a service cannot be brought from its
+	// current state to the desired one, such as pausing a stopped service
+	ErrStateUnreachable = errors.New("runit: desired state unreachable")
 )
 
 // OpError represents an error from a runit operation
@@ -64,6 +80,11 @@ func (m *MultiError) Add(err error) {
 	}
 }
 
+// Unwrap returns the accumulated errors for errors.Is and errors.As
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
 // Err returns nil if no errors occurred, otherwise returns the MultiError itself
 func (m *MultiError) Err() error {
 	if len(m.Errors) == 0 {
@@ -0,0 +1,92 @@
+package svcmgr
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Escalator runs commands with elevated privileges. Backends that shell out
+// for privileged operations (such as the systemd client and builder) use it
+// in place of plain sudo.
+type Escalator interface {
+	// Wrap returns the command line that runs name with args elevated
+	Wrap(name string, args []string) (string, []string)
+}
+
+// Sudo escalates through sudo(8)
+type Sudo struct {
+	// Path is the sudo binary; empty means "sudo"
+	Path string
+	// NonInteractive fails instead of prompting for a password (sudo -n)
+	NonInteractive bool
+}
+
+// Wrap implements Escalator
+func (s Sudo) Wrap(name string, args []string) (string, []string) {
+	var flags []string
+	if s.NonInteractive {
+		flags = append(flags, "-n")
+	}
+	return prefixCommand(s.Path, "sudo", flags, name, args)
+}
+
+// Doas escalates through OpenBSD's doas(1)
+type Doas struct {
+	// Path is the doas binary; empty means "doas"
+	Path string
+	// NonInteractive fails instead of prompting for a password (doas -n)
+	NonInteractive bool
+}
+
+// Wrap implements Escalator
+func (d Doas) Wrap(name string, args []string) (string, []string) {
+	var flags []string
+	if d.NonInteractive {
+		flags = append(flags, "-n")
+	}
+	return prefixCommand(d.Path, "doas", flags, name, args)
+}
+
+// Run0 escalates through systemd's run0(1), which runs the command as a
+// transient service authorized by polkit instead of a setuid binary
+type Run0 struct {
+	// Path is the run0 binary; empty means "run0"
+	Path string
+}
+
+// Wrap implements Escalator
+func (r Run0) Wrap(name string, args []string) (string, []string) {
+	return prefixCommand(r.Path, "run0", nil, name, args)
+}
+
+// Polkit runs commands unchanged and relies on the service manager
+// authorizing the caller through polkit over D-Bus, as systemctl,
+// systemd-run and portablectl do for unprivileged callers. It suits hosts
+// with polkit rules granting the caller the needed actions. Operations that
+// write files, such as installing unit files, are not covered by polkit and
+// need another escalator or sufficient permissions.
+type Polkit struct{}
+
+// Wrap implements Escalator
+func (Polkit) Wrap(name string, args []string) (string, []string) {
+	return name, args
+}
+
+// prefixCommand builds "tool flags... name args..."
+func prefixCommand(path, defaultPath string, flags []string, name string, args []string) (string, []string) {
+	if path == "" {
+		path = defaultPath
+	}
+	wrapped := make([]string, 0, len(flags)+1+len(args))
+	wrapped = append(wrapped, flags...)
+	wrapped = append(wrapped, name)
+	return path, append(wrapped, args...)
+}
+
+// escalatedCommand builds a command for name and args, elevated through e when set
+func escalatedCommand(ctx context.Context, e Escalator, name string, args ...string) *exec.Cmd {
+	if e != nil {
+		name, args = e.Wrap(name, args)
+	}
+	return exec.CommandContext(ctx, name, args...)
+}
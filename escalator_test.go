@@ -0,0 +1,35 @@
+package svcmgr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEscalators(t *testing.T) {
+	args := []string{"restart", "web.service"}
+	tests := []struct {
+		name      string
+		escalator Escalator
+		wantName  string
+		wantArgs  []string
+	}{
+		{name: "sudo", escalator: Sudo{}, wantName: "sudo", wantArgs: []string{"systemctl", "restart", "web.service"}},
+		{name: "sudo non-interactive", escalator: Sudo{Path: "/usr/bin/sudo", NonInteractive: true}, wantName: "/usr/bin/sudo", wantArgs: []string{"-n", "systemctl", "restart", "web.service"}},
+		{name: "doas", escalator: Doas{NonInteractive: true}, wantName: "doas", wantArgs: []string{"-n", "systemctl", "restart", "web.service"}},
+		{name: "run0", escalator: Run0{}, wantName: "run0", wantArgs: []string{"systemctl", "restart", "web.service"}},
+		{name: "polkit", escalator: Polkit{}, wantName: "systemctl", wantArgs: args},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, got := tt.escalator.Wrap("systemctl", args)
+			if name != tt.wantName || !slices.Equal(got, tt.wantArgs) {
+				t.Errorf("Wrap() = %q %q, want %q %q", name, got, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+
+	if !slices.Equal(args, []string{"restart", "web.service"}) {
+		t.Errorf("Wrap modified its arguments: %q", args)
+	}
+}
@@ -1,5 +1,5 @@
 // Package main demonstrates using go-runit with different supervision systems
-// (runit, daemontools, s6) through the factory functions.
+// (runit, daemontools, s6) through the factory configs and NewClient.
 package main
 
 import (
@@ -52,19 +52,11 @@ func run(system, service, action string, timeout time.Duration) error {
 	fmt.Printf("  Scanner: %s\n", config.RunsvdirPath)
 	fmt.Println()
 
-	// Create client based on system type
-	var client svcmgr.ServiceClient
-	var err error
-	switch config.Type {
-	case svcmgr.ServiceTypeRunit:
-		client, err = svcmgr.NewClientRunit(service)
-	case svcmgr.ServiceTypeDaemontools:
-		client, err = svcmgr.NewClientDaemontools(service)
-	case svcmgr.ServiceTypeS6:
-		client, err = svcmgr.NewClientS6(service)
-	default:
-		return fmt.Errorf("unsupported service type: %v", config.Type)
-	}
+	// Create client for the selected system
+	client, err := svcmgr.NewClient(service,
+		svcmgr.WithServiceType(config.Type),
+		svcmgr.WithStatusTimeout(timeout),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
@@ -0,0 +1,124 @@
+package svcmgr
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ExportFormat selects the output format of Manager.Export
+type ExportFormat string
+
+const (
+	// ExportJSONLines writes one JSON object per service:
+	// {"schema_version":1,"service":"web","dir":"/etc/service/web","status":{...}}
+	// with status in the Status JSON schema (see StatusSchemaVersion)
+	ExportJSONLines ExportFormat = "jsonl"
+	// ExportCSV writes a header row followed by one row per service with the
+	// columns in ExportCSVHeader
+	ExportCSV ExportFormat = "csv"
+)
+
+// ExportCSVHeader is the header row written by ExportCSV. Columns are only
+// ever appended, so readers may index them by position.
+var ExportCSVHeader = []string{
+	"service", "dir", "state", "pid", "since", "uptime_seconds",
+	"ready", "want_up", "want_down", "normally_up",
+}
+
+// exportRecord is one ExportJSONLines line
+type exportRecord struct {
+	SchemaVersion int    `json:"schema_version"`
+	Service       string `json:"service"`
+	Dir           string `json:"dir"`
+	Status        Status `json:"status"`
+}
+
+// Export streams the status of every service discovered in the scan
+// directories roots (as by Services) to w, in a stable format for inventory
+// jobs and fleet audits. Services whose status cannot be read are written
+// with state "unknown". Rows are written as they are read; Export stops
+// with ctx.Err() when ctx is done. A root that cannot be read fails Export
+// before anything is written.
+func (m *Manager) Export(ctx context.Context, w io.Writer, format ExportFormat, roots ...string) error {
+	if len(roots) == 0 {
+		return errors.New("export: no scan directories")
+	}
+	services := make([]iter.Seq2[string, Status], len(roots))
+	for i, root := range roots {
+		var err error
+		if services[i], err = readServices(root); err != nil {
+			return fmt.Errorf("export %s: %w", root, err)
+		}
+	}
+
+	var write func(dir string, st Status) error
+	var flush func() error
+	switch format {
+	case ExportJSONLines:
+		enc := json.NewEncoder(w)
+		write = func(dir string, st Status) error {
+			return enc.Encode(exportRecord{
+				SchemaVersion: StatusSchemaVersion,
+				Service:       filepath.Base(dir),
+				Dir:           dir,
+				Status:        st,
+			})
+		}
+		flush = func() error { return nil }
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(ExportCSVHeader); err != nil {
+			return err
+		}
+		write = func(dir string, st Status) error {
+			return cw.Write(exportCSVRow(dir, st))
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return fmt.Errorf("export: unknown format %q", format)
+	}
+
+	for _, seq := range services {
+		for dir, st := range seq {
+			if err := ctx.Err(); err != nil {
+				_ = flush()
+				return err
+			}
+			if err := write(dir, st); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// exportCSVRow formats a status as an ExportCSV row
+func exportCSVRow(dir string, st Status) []string {
+	since := ""
+	if !st.Since.IsZero() {
+		since = st.Since.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		filepath.Base(dir),
+		dir,
+		st.State.String(),
+		strconv.Itoa(st.PID),
+		since,
+		strconv.FormatFloat(st.Uptime.Seconds(), 'f', 0, 64),
+		strconv.FormatBool(st.Ready),
+		strconv.FormatBool(st.Flags.WantUp),
+		strconv.FormatBool(st.Flags.WantDown),
+		strconv.FormatBool(st.Flags.NormallyUp),
+	}
+}
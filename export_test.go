@@ -0,0 +1,75 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManagerExport(t *testing.T) {
+	root := t.TempDir()
+	for name, pid := range map[string]int{"db": 0, "web": 42} {
+		mock, err := NewMockSupervisor(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.UpdateStatus(pid != 0, pid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManager()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := m.Export(ctx, &buf, ExportJSONLines, root); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines:\n%s", len(lines), buf.String())
+	}
+	var rec exportRecord
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.SchemaVersion != StatusSchemaVersion || rec.Service != "web" || rec.Status.State != StateRunning || rec.Status.PID != 42 {
+		t.Errorf("record = %+v", rec)
+	}
+
+	buf.Reset()
+	if err := m.Export(ctx, &buf, ExportCSV, root); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(ExportCSVHeader, ",") {
+		t.Fatalf("rows = %q", rows)
+	}
+	if rows[1][0] != "db" || rows[1][2] != "down" || rows[2][0] != "web" || rows[2][3] != "42" {
+		t.Errorf("rows = %q", rows)
+	}
+
+	if err := m.Export(ctx, &buf, "xml", root); err == nil {
+		t.Error("Export with unknown format should fail")
+	}
+
+	buf.Reset()
+	missing := filepath.Join(root, "missing")
+	if err := m.Export(ctx, &buf, ExportCSV, root, missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Export of a missing scan directory = %v, want ErrNotExist", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Export of a missing scan directory wrote %q", buf.String())
+	}
+}
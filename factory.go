@@ -3,6 +3,7 @@ package svcmgr
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 // ServiceType represents the type of service supervision system
@@ -44,6 +45,11 @@ type ServiceConfig struct {
 	RunsvdirPath string
 	// SupportedOps contains the set of supported operations
 	SupportedOps map[Operation]struct{}
+	// ControlBytes maps each operation to the byte written to
+	// supervise/control. Forks whose control protocol differs from upstream
+	// override entries here; nil falls back to Operation.Byte for the
+	// supported operations.
+	ControlBytes map[Operation]byte
 }
 
 // allOperations returns a set with all operations enabled
@@ -67,22 +73,122 @@ func allOperations() map[Operation]struct{} {
 	}
 }
 
-// NewClient creates a ServiceClient based on the detected or specified supervision system
-func NewClient(serviceDir string, serviceType ServiceType) (ServiceClient, error) {
-	switch serviceType {
+// controlBytes returns the upstream control byte of each operation in ops
+// that is sent through the control file
+func controlBytes(ops map[Operation]struct{}) map[Operation]byte {
+	table := make(map[Operation]byte, len(ops))
+	for op := range ops {
+		if b := op.Byte(); b != 0 {
+			table[op] = b
+		}
+	}
+	return table
+}
+
+// NewClient creates a ServiceClient for the service directory.
+// The supervision system is detected from the supervise directory unless
+// selected with WithServiceType (or by passing a ServiceType directly).
+// For systemd, the base name of serviceDir is used as the unit name.
+//
+// Example:
+//
+//	client, err := svcmgr.NewClient("/etc/service/web",
+//	    svcmgr.WithServiceType(svcmgr.ServiceTypeRunit),
+//	    svcmgr.WithStatusTimeout(500*time.Millisecond),
+//	    svcmgr.WithControlRetry(5, 10*time.Millisecond, time.Second),
+//	)
+func NewClient(serviceDir string, opts ...ClientOption) (ServiceClient, error) {
+	var cfg clientConfig
+	for _, opt := range opts {
+		opt.applyClient(&cfg)
+	}
+	if cfg.pinned != nil && cfg.serviceType != ServiceTypeSystemd {
+		if err := checkPinned(serviceDir, cfg.pinned); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.serviceType == ServiceTypeUnknown {
+		cfg.serviceType = detectServiceType(serviceDir)
+	}
+
+	switch cfg.serviceType {
 	case ServiceTypeRunit:
-		return NewClientRunit(serviceDir)
+		c, err := NewClientRunit(serviceDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.applyTo(c.fields())
+		return c, nil
 	case ServiceTypeDaemontools:
-		return NewClientDaemontools(serviceDir)
+		c, err := NewClientDaemontools(serviceDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.applyTo(c.fields())
+		return c, nil
 	case ServiceTypeS6:
-		return NewClientS6(serviceDir)
+		c, err := NewClientS6(serviceDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.applyTo(c.fields())
+		c.S6Format = cfg.s6Format
+		return c, nil
 	case ServiceTypeSystemd:
 		// Systemd uses service names, not directories
 		// Extract service name from path
-		serviceName := filepath.Base(serviceDir)
-		return NewClientSystemd(serviceName), nil
+		return newClientSystemdWithOptions(filepath.Base(serviceDir), &cfg), nil
 	default:
-		return nil, fmt.Errorf("unsupported service type: %v", serviceType)
+		return nil, fmt.Errorf("unsupported service type: %v", cfg.serviceType)
+	}
+}
+
+// checkPinned rejects a pin of another directory than serviceDir, which
+// would send control commands and status reads to another service
+func checkPinned(serviceDir string, pin *PinnedDir) error {
+	abs, err := filepath.Abs(serviceDir)
+	if err != nil {
+		return fmt.Errorf("resolving service dir: %w", err)
+	}
+	if abs != pin.Path() {
+		return &OpError{Op: OpUnknown, Path: abs, Err: fmt.Errorf("pinned directory %s is not the service directory", pin.Path())}
+	}
+	return nil
+}
+
+// fields returns the settings of the client that NewClient options set
+func (c *ClientRunit) fields() clientFields {
+	return clientFields{
+		dialTimeout: &c.DialTimeout, writeTimeout: &c.WriteTimeout, readTimeout: &c.ReadTimeout,
+		watchDebounce: &c.WatchDebounce, watchBuffer: &c.WatchBuffer, watchOverflow: &c.WatchOverflow,
+		backoffMin: &c.BackoffMin, backoffMax: &c.BackoffMax, maxAttempts: &c.MaxAttempts,
+		defaultTimeout: &c.DefaultTimeout, clock: &c.Clock, controlBytes: &c.ControlBytes,
+		pinned: &c.Pinned, verifyPID: &c.VerifyPID, instrument: &c.Instrumentation,
+		skipIfRunning: &c.SkipIfRunning,
+	}
+}
+
+// fields returns the settings of the client that NewClient options set
+func (c *ClientDaemontools) fields() clientFields {
+	return clientFields{
+		dialTimeout: &c.DialTimeout, writeTimeout: &c.WriteTimeout, readTimeout: &c.ReadTimeout,
+		watchDebounce: &c.WatchDebounce, watchBuffer: &c.WatchBuffer, watchOverflow: &c.WatchOverflow,
+		backoffMin: &c.BackoffMin, backoffMax: &c.BackoffMax, maxAttempts: &c.MaxAttempts,
+		defaultTimeout: &c.DefaultTimeout, clock: &c.Clock, controlBytes: &c.ControlBytes,
+		pinned: &c.Pinned, verifyPID: &c.VerifyPID, instrument: &c.Instrumentation,
+		skipIfRunning: &c.SkipIfRunning,
+	}
+}
+
+// fields returns the settings of the client that NewClient options set
+func (c *ClientS6) fields() clientFields {
+	return clientFields{
+		dialTimeout: &c.DialTimeout, writeTimeout: &c.WriteTimeout, readTimeout: &c.ReadTimeout,
+		watchDebounce: &c.WatchDebounce, watchBuffer: &c.WatchBuffer, watchOverflow: &c.WatchOverflow,
+		backoffMin: &c.BackoffMin, backoffMax: &c.BackoffMax, maxAttempts: &c.MaxAttempts,
+		defaultTimeout: &c.DefaultTimeout, clock: &c.Clock, controlBytes: &c.ControlBytes,
+		pinned: &c.Pinned, verifyPID: &c.VerifyPID, instrument: &c.Instrumentation,
+		skipIfRunning: &c.SkipIfRunning,
 	}
 }
 
@@ -108,12 +214,36 @@ func NewServiceBuilderWithConfig(name, dir string, config *ServiceConfig) *Servi
 	return builder
 }
 
-// IsOperationSupported checks if an operation is supported by this service type
+// IsOperationSupported checks if an operation is supported by this service
+// type. Operations sent through the control file are supported when
+// ControlBytes, if set, has a byte for them.
 func (c *ServiceConfig) IsOperationSupported(op Operation) bool {
+	if c.ControlBytes != nil {
+		if _, ok := c.ControlBytes[op]; ok {
+			return true
+		}
+		if op.Byte() != 0 {
+			return false
+		}
+	}
 	_, ok := c.SupportedOps[op]
 	return ok
 }
 
+// ControlByte returns the byte written to the control file for op, and
+// false when op has no control byte in this configuration
+func (c *ServiceConfig) ControlByte(op Operation) (byte, bool) {
+	if c.ControlBytes != nil {
+		b, ok := c.ControlBytes[op]
+		return b, ok
+	}
+	if _, ok := c.SupportedOps[op]; !ok {
+		return 0, false
+	}
+	b := op.Byte()
+	return b, b != 0
+}
+
 // String returns the string representation of ServiceType
 func (st ServiceType) String() string {
 	switch st {
@@ -131,3 +261,38 @@ func (st ServiceType) String() string {
 		return serviceTypeUnknownStr
 	}
 }
+
+// ParseServiceType returns the ServiceType named by s, accepting the values
+// produced by ServiceType.String in any letter case
+func ParseServiceType(s string) (ServiceType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case serviceTypeRunitStr:
+		return ServiceTypeRunit, nil
+	case serviceTypeDaemontoolsStr:
+		return ServiceTypeDaemontools, nil
+	case serviceTypeS6Str:
+		return ServiceTypeS6, nil
+	case serviceTypeSystemdStr:
+		return ServiceTypeSystemd, nil
+	case serviceTypeUnknownStr:
+		return ServiceTypeUnknown, nil
+	default:
+		return ServiceTypeUnknown, fmt.Errorf("unknown service type: %q", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (st ServiceType) MarshalText() ([]byte, error) {
+	return []byte(st.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so a ServiceType can be
+// used with flag.TextVar, JSON configs and environment variables
+func (st *ServiceType) UnmarshalText(text []byte) error {
+	parsed, err := ParseServiceType(string(text))
+	if err != nil {
+		return err
+	}
+	*st = parsed
+	return nil
+}
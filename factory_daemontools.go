@@ -14,6 +14,7 @@ func ConfigDaemontools() *ServiceConfig {
 	// Daemontools doesn't support these operations
 	delete(config.SupportedOps, OpOnce) // No 'o' command
 	delete(config.SupportedOps, OpQuit) // No 'q' command
+	config.ControlBytes = controlBytes(config.SupportedOps)
 
 	return config
 }
@@ -2,7 +2,7 @@ package svcmgr
 
 // ConfigRunit returns the default configuration for runit
 func ConfigRunit() *ServiceConfig {
-	return &ServiceConfig{
+	config := &ServiceConfig{
 		Type:         ServiceTypeRunit,
 		ServiceDir:   "/etc/service",
 		ChpstPath:    "chpst",
@@ -10,6 +10,9 @@ func ConfigRunit() *ServiceConfig {
 		RunsvdirPath: "runsvdir",
 		SupportedOps: allOperations(),
 	}
+	config.ControlBytes = controlBytes(config.SupportedOps)
+
+	return config
 }
 
 // ServiceBuilderRunit creates a service builder configured for runit
@@ -14,6 +14,7 @@ func ConfigS6() *ServiceConfig {
 	// S6 doesn't support SIGSTOP/SIGCONT
 	delete(config.SupportedOps, OpPause)
 	delete(config.SupportedOps, OpCont)
+	config.ControlBytes = controlBytes(config.SupportedOps)
 
 	return config
 }
@@ -2,6 +2,8 @@
 
 package svcmgr
 
+import "cmp"
+
 // ConfigSystemd returns the default configuration for systemd
 //
 //nolint:revive // Clear naming for multiple config types
@@ -35,3 +37,14 @@ func ServiceBuilderSystemd(name, dir string) *BuilderSystemd {
 	sb := NewServiceBuilder(name, dir)
 	return NewBuilderSystemd(sb)
 }
+
+// newClientSystemdWithOptions creates a systemd client honoring the NewClient options that apply to it
+func newClientSystemdWithOptions(serviceName string, cfg *clientConfig) *ClientSystemd {
+	client := NewClientSystemd(serviceName)
+	setDuration(&client.Timeout, cmp.Or(cfg.statusTimeout, cfg.defaultTimeout))
+	client.Clock = cfg.clock
+	client.WatchBuffer = cfg.watchBuffer
+	client.WatchOverflow = cfg.watchOverflow
+	client.Instrumentation = cfg.instrument
+	return client
+}
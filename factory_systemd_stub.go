@@ -16,3 +16,8 @@ func ConfigSystemd() *ServiceConfig {
 		SupportedOps: make(map[Operation]struct{}), // No operations supported
 	}
 }
+
+// newClientSystemdWithOptions creates a systemd client stub; options have no effect off Linux
+func newClientSystemdWithOptions(serviceName string, _ *clientConfig) *ClientSystemd {
+	return NewClientSystemd(serviceName)
+}
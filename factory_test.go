@@ -1,6 +1,7 @@
 package svcmgr
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -96,10 +97,42 @@ func TestServiceConfigs(t *testing.T) {
 			if tt.config.IsOperationSupported(OpQuit) != tt.want.hasQuit {
 				t.Errorf("OpQuit supported = %v, want %v", tt.config.IsOperationSupported(OpQuit), tt.want.hasQuit)
 			}
+			// The control table covers exactly the supported control operations
+			for op := range tt.config.SupportedOps {
+				b, ok := tt.config.ControlByte(op)
+				if want := op.Byte(); ok != (want != 0) || b != want {
+					t.Errorf("ControlByte(%v) = %q %v, want %q", op, b, ok, want)
+				}
+			}
+			if _, ok := tt.config.ControlByte(OpOnce); ok != tt.want.hasOnce {
+				t.Errorf("ControlByte(OpOnce) ok = %v, want %v", ok, tt.want.hasOnce)
+			}
 		})
 	}
 }
 
+func TestServiceConfigControlByte(t *testing.T) {
+	// Without a table the upstream bytes of supported operations are used
+	config := &ServiceConfig{SupportedOps: map[Operation]struct{}{OpUp: {}, OpStatus: {}}}
+	if b, ok := config.ControlByte(OpUp); !ok || b != 'u' {
+		t.Errorf("ControlByte(OpUp) = %q %v, want 'u'", b, ok)
+	}
+	for _, op := range []Operation{OpDown, OpStatus} {
+		if _, ok := config.ControlByte(op); ok {
+			t.Errorf("ControlByte(%v) ok without a control byte", op)
+		}
+	}
+
+	// A table overrides the defaults
+	config.ControlBytes = map[Operation]byte{OpDown: 'D'}
+	if b, ok := config.ControlByte(OpDown); !ok || b != 'D' {
+		t.Errorf("ControlByte(OpDown) = %q %v, want 'D'", b, ok)
+	}
+	if _, ok := config.ControlByte(OpUp); ok {
+		t.Error("ControlByte(OpUp) ok although missing from the table")
+	}
+}
+
 func TestServiceBuilderRunit(t *testing.T) {
 	builder := ServiceBuilderRunit("test", "/tmp/services")
 	config := builder.Config()
@@ -155,3 +188,42 @@ func TestNewClientWithConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseServiceType(t *testing.T) {
+	for _, st := range []ServiceType{ServiceTypeUnknown, ServiceTypeRunit, ServiceTypeDaemontools, ServiceTypeS6, ServiceTypeSystemd} {
+		text, err := st.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got ServiceType
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q): %v", text, err)
+		}
+		if got != st {
+			t.Errorf("round trip of %v = %v", st, got)
+		}
+	}
+
+	if st, err := ParseServiceType(" S6 "); err != nil || st != ServiceTypeS6 {
+		t.Errorf("ParseServiceType(\" S6 \") = %v, %v", st, err)
+	}
+	if _, err := ParseServiceType("upstart"); err == nil {
+		t.Error("expected error for unknown service type")
+	}
+}
+
+func TestClientFieldsComplete(t *testing.T) {
+	clients := map[string]clientFields{
+		"runit":       (&ClientRunit{}).fields(),
+		"daemontools": (&ClientDaemontools{}).fields(),
+		"s6":          (&ClientS6{}).fields(),
+	}
+	for name, f := range clients {
+		v := reflect.ValueOf(f)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).IsNil() {
+				t.Errorf("%s client does not expose %s to NewClient options", name, v.Type().Field(i).Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package svcmgr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSSHPath is the ssh client used to reach remote hosts
+const DefaultSSHPath = "ssh"
+
+// FleetManager fans Manager operations out across the hosts of an
+// Inventory. Local hosts are managed in-process; remote hosts run the
+// svcmgr command over ssh, which must be installed there. Each host's
+// outcome is reported separately, so one unreachable host does not hide
+// the others.
+//
+// Example:
+//
+//	inv, err := svcmgr.LoadInventory("/etc/svcmgr/fleet.json")
+//	if err != nil {
+//		return err
+//	}
+//	fleet := svcmgr.NewFleetManager(inv, svcmgr.WithTimeout(10*time.Second))
+//	for _, res := range fleet.Exec(ctx, svcmgr.OpRestart, "proxy") {
+//		if res.Err != nil {
+//			log.Printf("%s: %v", res.Host, res.Err)
+//		}
+//	}
+type FleetManager struct {
+	// Inventory lists the hosts
+	Inventory *Inventory
+	// Manager bounds the work on each host: Concurrency and Ordered apply
+	// to local hosts, Timeout to every operation on a service
+	Manager *Manager
+	// HostConcurrency is the maximum number of hosts acted on at once;
+	// zero means all of them
+	HostConcurrency int
+	// SSHPath is the ssh client; empty means DefaultSSHPath
+	SSHPath string
+	// SSHOptions are extra ssh arguments placed before the destination,
+	// such as []string{"-o", "ConnectTimeout=5"}
+	SSHOptions []string
+}
+
+// HostResult is the outcome of a fleet operation on one host
+type HostResult struct {
+	// Host is the inventory name of the host
+	Host string
+	// Statuses holds the statuses read by FleetManager.Status, by service
+	// name as given
+	Statuses map[string]Status
+	// Err is nil when the operation succeeded on every service of the
+	// host; local hosts report a MultiError of the failed services
+	Err error
+	// Duration is how long the host took
+	Duration time.Duration
+}
+
+// NewFleetManager creates a FleetManager for inv, configuring the
+// per-host Manager with opts
+func NewFleetManager(inv *Inventory, opts ...ManagerOption) *FleetManager {
+	return &FleetManager{
+		Inventory: inv,
+		Manager:   NewManager(opts...),
+	}
+}
+
+// Exec performs op on services on every host, or on each host's inventory
+// services when none are given. Results are in inventory order.
+func (f *FleetManager) Exec(ctx context.Context, op Operation, services ...string) []HostResult {
+	return f.forEachHost(ctx, services, func(ctx context.Context, host InventoryHost, services []string) HostResult {
+		if host.Remote() {
+			return HostResult{Err: f.remoteExec(ctx, host, op, services)}
+		}
+		return HostResult{Err: f.localExec(ctx, host, op, services)}
+	})
+}
+
+// Status reads the status of services on every host, or of each host's
+// inventory services when none are given. Results are in inventory order;
+// services whose status could not be read are missing from Statuses and
+// reported in Err.
+func (f *FleetManager) Status(ctx context.Context, services ...string) []HostResult {
+	return f.forEachHost(ctx, services, func(ctx context.Context, host InventoryHost, services []string) HostResult {
+		var res HostResult
+		if host.Remote() {
+			res.Statuses, res.Err = f.remoteStatus(ctx, host, services)
+		} else {
+			res.Statuses, res.Err = f.localStatus(ctx, host, services)
+		}
+		return res
+	})
+}
+
+// manager returns the per-host Manager
+func (f *FleetManager) manager() *Manager {
+	if f.Manager == nil {
+		return NewManager()
+	}
+	return f.Manager
+}
+
+// forEachHost runs fn for every host concurrently, up to HostConcurrency
+func (f *FleetManager) forEachHost(ctx context.Context, services []string, fn func(context.Context, InventoryHost, []string) HostResult) []HostResult {
+	hosts := f.Inventory.Hosts
+	results := make([]HostResult, len(hosts))
+	limit := f.HostConcurrency
+	if limit <= 0 || limit > len(hosts) {
+		limit = len(hosts)
+	}
+	sem := make(chan struct{}, max(limit, 1))
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Host = host.Name
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+
+			names := services
+			if len(names) == 0 {
+				names = host.Services
+			}
+			start := time.Now()
+			res := fn(ctx, host, names)
+			res.Host, res.Duration = host.Name, time.Since(start)
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// localClient creates a client for service on a local host
+func (f *FleetManager) localClient(host InventoryHost, service string) (ServiceClient, error) {
+	st, err := host.serviceType()
+	if err != nil {
+		return nil, err
+	}
+	opts := []ClientOption{WithInstrumentation(f.manager().Instrumentation)}
+	if st != ServiceTypeUnknown {
+		opts = append(opts, WithServiceType(st))
+	}
+	return NewClient(host.serviceDir(service), opts...)
+}
+
+// localExec performs op on services of a local host
+func (f *FleetManager) localExec(ctx context.Context, host InventoryHost, op Operation, services []string) error {
+	m := f.manager()
+	var mu sync.Mutex
+	merr := &MultiError{}
+	m.forEach(ctx, op, services, func(ctx context.Context, svc string) error {
+		client, err := f.localClient(host, svc)
+		if err != nil {
+			return &OpError{Op: op, Path: svc, Err: err}
+		}
+		return m.withTimeout(ctx, func(ctx context.Context) error {
+			return controlClient(ctx, client, op)
+		})
+	}, func(_ string, err error) {
+		mu.Lock()
+		merr.Add(err)
+		mu.Unlock()
+	})
+	return merr.Err()
+}
+
+// localStatus reads the status of services of a local host
+func (f *FleetManager) localStatus(ctx context.Context, host InventoryHost, services []string) (map[string]Status, error) {
+	m := f.manager()
+	var mu sync.Mutex
+	statuses := make(map[string]Status, len(services))
+	merr := &MultiError{}
+	m.forEach(ctx, OpStatus, services, func(ctx context.Context, svc string) error {
+		client, err := f.localClient(host, svc)
+		if err != nil {
+			return &OpError{Op: OpStatus, Path: svc, Err: err}
+		}
+		return m.withTimeout(ctx, func(ctx context.Context) error {
+			st, err := client.Status(ctx)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			statuses[svc] = st
+			mu.Unlock()
+			return nil
+		})
+	}, func(_ string, err error) {
+		mu.Lock()
+		merr.Add(err)
+		mu.Unlock()
+	})
+	return statuses, merr.Err()
+}
+
+// remoteExec runs "svcmgr <op> <services>" on a remote host
+func (f *FleetManager) remoteExec(ctx context.Context, host InventoryHost, op Operation, services []string) error {
+	if len(services) == 0 {
+		return nil
+	}
+	_, err := f.ssh(ctx, host, append([]string{op.String()}, services...))
+	return err
+}
+
+// remoteStatusRecord is one line of "svcmgr status -json"
+type remoteStatusRecord struct {
+	Service string  `json:"service"`
+	Status  *Status `json:"status"`
+	Error   string  `json:"error"`
+}
+
+// remoteStatus runs "svcmgr status -json <services>" on a remote host
+func (f *FleetManager) remoteStatus(ctx context.Context, host InventoryHost, services []string) (map[string]Status, error) {
+	statuses := make(map[string]Status, len(services))
+	if len(services) == 0 {
+		return statuses, nil
+	}
+	out, err := f.ssh(ctx, host, append([]string{"status", "-json"}, services...))
+	// status exits with the highest LSB code, so a failed run is only an
+	// error when it printed no records
+	if err != nil && len(bytes.TrimSpace(out)) == 0 {
+		return statuses, err
+	}
+
+	merr := &MultiError{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		var rec remoteStatusRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return statuses, &OpError{Op: OpStatus, Path: host.Name, Err: fmt.Errorf("%w: %v", ErrDecode, err)}
+		}
+		switch {
+		case rec.Error != "":
+			merr.Add(&OpError{Op: OpStatus, Path: rec.Service, Err: errors.New(rec.Error)})
+		case rec.Status != nil:
+			statuses[rec.Service] = *rec.Status
+		}
+	}
+	if err := sc.Err(); err != nil {
+		merr.Add(err)
+	}
+	return statuses, merr.Err()
+}
+
+// ssh runs the svcmgr command with args on a remote host, returning its
+// standard output. A failure carries the command's standard error.
+func (f *FleetManager) ssh(ctx context.Context, host InventoryHost, args []string) ([]byte, error) {
+	command := host.Command
+	if command == "" {
+		command = DefaultRemoteCommand
+	}
+	words := []string{command}
+	if host.ServiceRoot != "" {
+		words = append(words, "-d", shellQuote(host.ServiceRoot))
+	}
+	if timeout := f.manager().Timeout; timeout > 0 {
+		words = append(words, "-timeout", timeout.String())
+	}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+
+	sshPath := f.SSHPath
+	if sshPath == "" {
+		sshPath = DefaultSSHPath
+	}
+	sshArgs := append([]string{"-o", "BatchMode=yes"}, f.SSHOptions...)
+	sshArgs = append(sshArgs, "--", host.SSH, strings.Join(words, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, sshPath, sshArgs...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return stdout.Bytes(), fmt.Errorf("host %s: %w", host.Name, err)
+	}
+	return stdout.Bytes(), nil
+}
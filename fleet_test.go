@@ -0,0 +1,105 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFleetManagerStatus(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "service")
+	createTestService(t, root, "web", 1001, 'u')
+	createTestService(t, root, "db", 0, 'd')
+
+	// The remote host answers with the records of "svcmgr status -json"
+	remote, err := json.Marshal(remoteStatusRecord{Service: "proxy", Status: &Status{State: StateRunning, PID: 42}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := filepath.Join(dir, "records")
+	lines := string(remote) + "\n" + `{"service":"cache","error":"no such service"}` + "\n"
+	if err := os.WriteFile(records, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "log")
+	ssh := fakeCommand(t, dir, "ssh", `printf '%s\n' "$@" > `+log+`
+cat `+records+`
+exit 3
+`)
+
+	inv := &Inventory{Hosts: []InventoryHost{
+		{Name: "local", Type: "runit", ServiceRoot: root, Services: []string{"web", "db"}},
+		{Name: "edge", SSH: "ops@edge", ServiceRoot: "/run/my service", Services: []string{"proxy", "cache"}},
+	}}
+	fleet := NewFleetManager(inv, WithTimeout(time.Second))
+	fleet.SSHPath = ssh
+
+	results := fleet.Status(context.Background())
+	if len(results) != 2 || results[0].Host != "local" || results[1].Host != "edge" {
+		t.Fatalf("results = %+v", results)
+	}
+
+	local := results[0]
+	if local.Err != nil {
+		t.Errorf("local error = %v", local.Err)
+	}
+	if local.Statuses["web"].PID != 1001 || local.Statuses["db"].State != StateDown {
+		t.Errorf("local statuses = %+v", local.Statuses)
+	}
+
+	edge := results[1]
+	if edge.Statuses["proxy"].PID != 42 || edge.Statuses["proxy"].State != StateRunning {
+		t.Errorf("edge statuses = %+v", edge.Statuses)
+	}
+	if edge.Err == nil || !strings.Contains(edge.Err.Error(), "no such service") {
+		t.Errorf("edge error = %v, want the cache failure", edge.Err)
+	}
+
+	args := readArgs(t, log)
+	want := []string{"-o", "BatchMode=yes", "--", "ops@edge", "svcmgr -d '/run/my service' -timeout 1s status -json proxy cache"}
+	if strings.Join(args, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ssh args = %q, want %q", args, want)
+	}
+}
+
+func TestFleetManagerExec(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	ssh := fakeCommand(t, dir, "ssh", `printf '%s\n' "$@" > `+log+`
+case "$5" in
+*db*) echo "svcmgr: restart db: not supervised" >&2; exit 1 ;;
+esac
+`)
+
+	inv := &Inventory{Hosts: []InventoryHost{
+		{Name: "a", SSH: "a", Command: "sudo svcmgr"},
+		{Name: "b", SSH: "b"},
+		{Name: "missing", Type: "runit", ServiceRoot: filepath.Join(dir, "none")},
+	}}
+	fleet := &FleetManager{Inventory: inv, Manager: NewManager(WithTimeout(0)), SSHPath: ssh, HostConcurrency: 1}
+
+	results := fleet.Exec(context.Background(), OpRestart, "web")
+	for _, res := range results[:2] {
+		if res.Err != nil {
+			t.Errorf("%s: error = %v", res.Host, res.Err)
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("missing local service: no error")
+	}
+	if got := readArgs(t, log); !strings.HasSuffix(got[len(got)-1], "svcmgr restart web") {
+		t.Errorf("remote command = %q", got[len(got)-1])
+	}
+
+	results = fleet.Exec(context.Background(), OpRestart, "db")
+	if err := results[0].Err; err == nil || !strings.Contains(err.Error(), "not supervised") {
+		t.Errorf("a: error = %v, want the remote stderr", err)
+	}
+}
@@ -6,12 +6,12 @@ require (
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/google/renameio/v2 v2.0.0
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/sys v0.13.0
 	vawter.tech/stopper v1.0.2
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/sys v0.13.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
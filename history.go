@@ -0,0 +1,306 @@
+package svcmgr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/google/renameio/v2"
+)
+
+// DefaultHistoryFile is the conventional name of a service's history file
+// inside its supervise directory
+const DefaultHistoryFile = "history.jsonl"
+
+// HistoryEntry is one recorded state transition
+type HistoryEntry struct {
+	// Time is when the transition happened, as reported by the supervisor
+	// when known, otherwise when it was observed
+	Time time.Time `json:"time"`
+	// State is the state entered
+	State State `json:"state"`
+	// PID is the service process, 0 when none
+	PID int `json:"pid,omitempty"`
+	// WantUp reports whether the service was wanted up, which separates
+	// failures from requested stops
+	WantUp bool `json:"want_up"`
+}
+
+// History is a service's recorded transitions, oldest first
+type History []HistoryEntry
+
+// ReadHistory reads a history file written by a HistoryRecorder.
+// A missing file yields an empty history.
+func ReadHistory(path string) (History, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var h History
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%w: %s line %d: %v", ErrDecode, path, line, err)
+		}
+		h = append(h, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Restarts counts the processes started at or after since that replaced an
+// earlier process, whether it crashed or was restarted on request.
+//
+// Example, restarts in the last hour:
+//
+//	n := history.Restarts(time.Now().Add(-time.Hour))
+func (h History) Restarts(since time.Time) int {
+	var n, lastPID int
+	for _, e := range h {
+		if e.State != StateRunning || e.PID == 0 || e.PID == lastPID {
+			continue
+		}
+		if lastPID != 0 && !e.Time.Before(since) {
+			n++
+		}
+		lastPID = e.PID
+	}
+	return n
+}
+
+// Failures counts the processes that ended while the service was wanted up,
+// at or after since
+func (h History) Failures(since time.Time) int {
+	n := 0
+	for i := 1; i < len(h); i++ {
+		if h.failedAt(i) && !h[i].Time.Before(since) {
+			n++
+		}
+	}
+	return n
+}
+
+// MTBF returns the mean time between failures: the total time spent running
+// up to now divided by the number of failures. It returns 0 when no failure
+// was recorded.
+func (h History) MTBF(now time.Time) time.Duration {
+	var up time.Duration
+	failures := 0
+	for i, e := range h {
+		if i > 0 && h.failedAt(i) {
+			failures++
+		}
+		if e.State != StateRunning {
+			continue
+		}
+		end := now
+		if i+1 < len(h) {
+			end = h[i+1].Time
+		}
+		if end.After(e.Time) {
+			up += end.Sub(e.Time)
+		}
+	}
+	if failures == 0 {
+		return 0
+	}
+	return up / time.Duration(failures)
+}
+
+// failedAt reports whether entry i ended the running process of entry i-1
+// without it being asked to stop
+func (h History) failedAt(i int) bool {
+	prev, e := h[i-1], h[i]
+	if prev.State != StateRunning || prev.PID == 0 {
+		return false
+	}
+	switch e.State {
+	case StateRunning, StatePaused:
+		return e.PID != 0 && e.PID != prev.PID
+	case StateStopping, StateFinishing:
+		return false
+	default:
+		return e.WantUp || e.State == StateCrashed
+	}
+}
+
+// HistoryRecorder appends a service's state transitions to a history file,
+// one JSON object per line. Supervisors only keep the current state, so the
+// recorder fills in restart counts and uptime over time.
+//
+// Without MaxEntries or MaxAge the file grows with every transition, which
+// for a crash-looping service is every restart. With either set, Record
+// rewrites the file atomically without the entries beyond the bound.
+type HistoryRecorder struct {
+	// Path is the history file
+	Path string
+	// Clock timestamps transitions the supervisor does not date and ages
+	// entries for MaxAge; nil uses the wall clock
+	Clock Clock
+	// MaxEntries, when positive, keeps only the latest MaxEntries entries
+	MaxEntries int
+	// MaxAge, when positive, drops entries older than MaxAge. The latest
+	// entry is always kept, as it holds the current state.
+	MaxAge time.Duration
+
+	last    *HistoryEntry
+	started bool
+	// count and oldest describe the file, to compact it only when needed
+	count  int
+	oldest time.Time
+}
+
+// NewHistoryRecorder creates a recorder appending to path
+func NewHistoryRecorder(path string) *HistoryRecorder {
+	return &HistoryRecorder{Path: path}
+}
+
+// Record appends st to the history when its state or PID differs from the
+// last recorded entry, reporting whether it was appended. The first call
+// resumes from the last entry already in the file.
+func (r *HistoryRecorder) Record(st Status) (bool, error) {
+	if !r.started {
+		h, err := ReadHistory(r.Path)
+		if err != nil {
+			return false, err
+		}
+		if len(h) > 0 {
+			r.last = &h[len(h)-1]
+			r.oldest = h[0].Time
+		}
+		r.count = len(h)
+		r.started = true
+	}
+	if r.last != nil && r.last.State == st.State && r.last.PID == st.PID {
+		return false, nil
+	}
+
+	e := HistoryEntry{Time: st.Since, State: st.State, PID: st.PID, WantUp: st.Flags.WantUp}
+	if e.Time.IsZero() {
+		e.Time = clockNow(r.Clock)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := os.OpenFile(r.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, fmt.Errorf("record history %s: %w", r.Path, err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return false, fmt.Errorf("record history %s: %w", r.Path, err)
+	}
+	r.last = &e
+	if r.count == 0 {
+		r.oldest = e.Time
+	}
+	r.count++
+	if err := r.compact(); err != nil {
+		return true, fmt.Errorf("compact history %s: %w", r.Path, err)
+	}
+	return true, nil
+}
+
+// compact rewrites the history file without the entries beyond MaxEntries
+// or older than MaxAge, when it holds any
+func (r *HistoryRecorder) compact() error {
+	overCount := r.MaxEntries > 0 && r.count > r.MaxEntries
+	var cutoff time.Time
+	if r.MaxAge > 0 {
+		cutoff = clockNow(r.Clock).Add(-r.MaxAge)
+	}
+	overAge := r.MaxAge > 0 && r.count > 1 && r.oldest.Before(cutoff)
+	if !overCount && !overAge {
+		return nil
+	}
+
+	h, err := ReadHistory(r.Path)
+	if err != nil {
+		return err
+	}
+	if r.MaxEntries > 0 && len(h) > r.MaxEntries {
+		h = h[len(h)-r.MaxEntries:]
+	}
+	if r.MaxAge > 0 {
+		i := 0
+		for i < len(h)-1 && h[i].Time.Before(cutoff) {
+			i++
+		}
+		h = h[i:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range h {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	if err := renameio.WriteFile(r.Path, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	r.count = len(h)
+	if len(h) > 0 {
+		r.oldest = h[0].Time
+	}
+	return nil
+}
+
+// Run records the client's current status and every change reported by
+// Watch until ctx is done, returning ctx.Err(). A watch that ends on its
+// own, e.g. because the service directory was removed, stops recording with
+// ErrWatchClosed.
+func (r *HistoryRecorder) Run(ctx context.Context, client ServiceClient) error {
+	events, stop, err := client.Watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stop() }()
+
+	if st, err := client.Status(ctx); err == nil {
+		if _, err := r.Record(st); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-events:
+			if !ok {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return fmt.Errorf("record history %s: %w", r.Path, ErrWatchClosed)
+			}
+			if ev.Err != nil {
+				continue
+			}
+			if _, err := r.Record(ev.Status); err != nil {
+				return err
+			}
+		}
+	}
+}
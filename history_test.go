@@ -0,0 +1,174 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHistoryRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultHistoryFile)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	up := Flags{WantUp: true}
+
+	r := NewHistoryRecorder(path)
+	r.Clock = FixedClock(base.Add(time.Hour))
+	statuses := []struct {
+		st   Status
+		want bool
+	}{
+		{Status{State: StateRunning, PID: 10, Since: base, Flags: up}, true},
+		{Status{State: StateRunning, PID: 10, Since: base, Flags: up}, false},
+		{Status{State: StateDown, Since: base.Add(10 * time.Minute), Flags: up}, true},
+		{Status{State: StateRunning, PID: 11, Since: base.Add(11 * time.Minute), Flags: up}, true},
+		{Status{State: StateRunning, PID: 12, Flags: up}, true},
+	}
+	for i, s := range statuses {
+		got, err := r.Record(s.st)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != s.want {
+			t.Errorf("Record #%d = %v, want %v", i, got, s.want)
+		}
+	}
+
+	// A new recorder resumes from the file
+	if got, err := NewHistoryRecorder(path).Record(statuses[4].st); err != nil || got {
+		t.Errorf("resumed Record = %v, %v; want false, nil", got, err)
+	}
+
+	h, err := ReadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 4 {
+		t.Fatalf("history has %d entries, want 4: %+v", len(h), h)
+	}
+	if !h[3].Time.Equal(base.Add(time.Hour)) {
+		t.Errorf("undated entry time = %v, want clock time", h[3].Time)
+	}
+}
+
+func TestHistoryRecorderRetention(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(t *testing.T, r *HistoryRecorder, n int) {
+		t.Helper()
+		for i := range n {
+			st := Status{State: StateRunning, PID: 100 + i, Since: base.Add(time.Duration(i) * time.Minute)}
+			if _, err := r.Record(st); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	pids := func(t *testing.T, path string) []int {
+		t.Helper()
+		h, err := ReadHistory(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pids []int
+		for _, e := range h {
+			pids = append(pids, e.PID)
+		}
+		return pids
+	}
+
+	t.Run("max entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), DefaultHistoryFile)
+		r := &HistoryRecorder{Path: path, MaxEntries: 3}
+		record(t, r, 10)
+		if got := pids(t, path); !slices.Equal(got, []int{107, 108, 109}) {
+			t.Errorf("kept PIDs %v, want the latest 3", got)
+		}
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), DefaultHistoryFile)
+		r := &HistoryRecorder{Path: path, MaxAge: 5 * time.Minute, Clock: FixedClock(base.Add(10 * time.Minute))}
+		record(t, r, 10)
+		if got := pids(t, path); !slices.Equal(got, []int{105, 106, 107, 108, 109}) {
+			t.Errorf("kept PIDs %v, want those of the last 5 minutes", got)
+		}
+
+		// The current state is kept however old it is
+		r = &HistoryRecorder{Path: path, MaxAge: time.Minute, Clock: FixedClock(base.Add(time.Hour))}
+		if _, err := r.Record(Status{State: StateDown, Since: base.Add(20 * time.Minute)}); err != nil {
+			t.Fatal(err)
+		}
+		if got := pids(t, path); !slices.Equal(got, []int{0}) {
+			t.Errorf("kept PIDs %v, want only the latest entry", got)
+		}
+	})
+}
+
+func TestHistoryStats(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+	h := History{
+		{Time: at(0), State: StateRunning, PID: 10, WantUp: true},
+		{Time: at(30), State: StateDown, WantUp: true}, // crash
+		{Time: at(31), State: StateRunning, PID: 11, WantUp: true},
+		{Time: at(61), State: StateRunning, PID: 12, WantUp: true}, // crash, restarted quickly
+		{Time: at(90), State: StateDown, WantUp: false},            // requested stop
+		{Time: at(100), State: StateRunning, PID: 13, WantUp: true},
+	}
+
+	if got := h.Restarts(time.Time{}); got != 3 {
+		t.Errorf("Restarts = %d, want 3", got)
+	}
+	if got := h.Restarts(at(60)); got != 2 {
+		t.Errorf("Restarts since 60m = %d, want 2", got)
+	}
+	if got := h.Failures(time.Time{}); got != 2 {
+		t.Errorf("Failures = %d, want 2", got)
+	}
+	// Up 30 + 30 + 29 + 20 minutes over 2 failures
+	if got, want := h.MTBF(at(120)), 109*time.Minute/2; got != want {
+		t.Errorf("MTBF = %v, want %v", got, want)
+	}
+	if got := h[:1].MTBF(at(120)); got != 0 {
+		t.Errorf("MTBF without failures = %v, want 0", got)
+	}
+}
+
+func TestReadHistory(t *testing.T) {
+	dir := t.TempDir()
+	if h, err := ReadHistory(filepath.Join(dir, "missing")); err != nil || h != nil {
+		t.Errorf("missing file = %v, %v; want empty", h, err)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	if err := os.WriteFile(bad, []byte("{\"state\":\"running\"}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadHistory(bad); !errors.Is(err, ErrDecode) {
+		t.Errorf("ReadHistory(bad) = %v, want ErrDecode", err)
+	}
+}
+
+// endedWatchClient is a client whose watch ends at once
+type endedWatchClient struct {
+	ServiceClient
+}
+
+func (endedWatchClient) Status(context.Context) (Status, error) {
+	return Status{State: StateRunning, PID: 10}, nil
+}
+
+func (endedWatchClient) Watch(context.Context) (<-chan WatchEvent, WatchCleanupFunc, error) {
+	events := make(chan WatchEvent)
+	close(events)
+	return events, func() error { return nil }, nil
+}
+
+func TestHistoryRecorderRunWatchEnded(t *testing.T) {
+	r := NewHistoryRecorder(filepath.Join(t.TempDir(), DefaultHistoryFile))
+	if err := r.Run(context.Background(), endedWatchClient{}); !errors.Is(err, ErrWatchClosed) {
+		t.Errorf("Run() error = %v, want ErrWatchClosed", err)
+	}
+}
@@ -0,0 +1,121 @@
+package svcmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRemoteCommand is the svcmgr command run on remote hosts
+const DefaultRemoteCommand = "svcmgr"
+
+// Inventory describes a fleet of hosts for a FleetManager
+//
+// Example inventory file:
+//
+//	{
+//	  "hosts": [
+//	    {"name": "local", "type": "runit", "service_root": "/etc/service", "services": ["web", "db"]},
+//	    {"name": "edge-1", "ssh": "ops@edge-1.example.com", "service_root": "/run/service", "services": ["proxy"]},
+//	    {"name": "edge-2", "ssh": "edge-2", "command": "sudo /usr/local/bin/svcmgr", "services": ["proxy"]}
+//	  ]
+//	}
+type Inventory struct {
+	// Hosts are the hosts of the fleet, in the order operations report them
+	Hosts []InventoryHost `json:"hosts"`
+}
+
+// InventoryHost is one host of an Inventory
+type InventoryHost struct {
+	// Name identifies the host in results; unique within the inventory
+	Name string `json:"name"`
+	// Type is the supervision system ("runit", "daemontools", "s6" or
+	// "systemd"); empty detects it from each service directory. Remote
+	// hosts always detect it.
+	Type string `json:"type,omitempty"`
+	// ServiceRoot is the directory service names are resolved in; empty
+	// means $SVDIR or /etc/service on remote hosts, and the current
+	// directory locally
+	ServiceRoot string `json:"service_root,omitempty"`
+	// SSH is the ssh destination ([user@]host) of a remote host; empty
+	// manages the host's services locally
+	SSH string `json:"ssh,omitempty"`
+	// Command is the shell command starting svcmgr on a remote host, such
+	// as "sudo svcmgr"; empty means DefaultRemoteCommand
+	Command string `json:"command,omitempty"`
+	// Services are the host's services, acted on when an operation names
+	// none
+	Services []string `json:"services,omitempty"`
+}
+
+// Remote reports whether the host is managed over ssh
+func (h InventoryHost) Remote() bool {
+	return h.SSH != ""
+}
+
+// serviceType returns the parsed Type, ServiceTypeUnknown when empty
+func (h InventoryHost) serviceType() (ServiceType, error) {
+	if h.Type == "" {
+		return ServiceTypeUnknown, nil
+	}
+	return ParseServiceType(h.Type)
+}
+
+// serviceDir resolves a service name to its directory on the host
+func (h InventoryHost) serviceDir(service string) string {
+	if h.ServiceRoot == "" || filepath.IsAbs(service) {
+		return service
+	}
+	return filepath.Join(h.ServiceRoot, service)
+}
+
+// Host returns the host named name
+func (inv *Inventory) Host(name string) (InventoryHost, bool) {
+	for _, h := range inv.Hosts {
+		if h.Name == name {
+			return h, true
+		}
+	}
+	return InventoryHost{}, false
+}
+
+// Validate checks that every host is named, uniquely, with a known type
+func (inv *Inventory) Validate() error {
+	seen := make(map[string]bool, len(inv.Hosts))
+	for i, h := range inv.Hosts {
+		if strings.TrimSpace(h.Name) == "" {
+			return fmt.Errorf("inventory host %d: missing name", i)
+		}
+		if seen[h.Name] {
+			return fmt.Errorf("inventory host %q: duplicate name", h.Name)
+		}
+		seen[h.Name] = true
+		if _, err := h.serviceType(); err != nil {
+			return fmt.Errorf("inventory host %q: %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// ParseInventory decodes and validates a JSON inventory
+func ParseInventory(data []byte) (*Inventory, error) {
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("inventory: %w", err)
+	}
+	if err := inv.Validate(); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// LoadInventory reads a JSON inventory file
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInventory(data)
+}
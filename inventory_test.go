@@ -0,0 +1,49 @@
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"hosts":[{"name":"a","type":"runit","service_root":"/etc/service"},{"name":"b","ssh":"ops@b"}]}`},
+		{name: "empty", data: `{}`},
+		{name: "missing name", data: `{"hosts":[{"type":"s6"}]}`, wantErr: true},
+		{name: "duplicate name", data: `{"hosts":[{"name":"a"},{"name":"a"}]}`, wantErr: true},
+		{name: "unknown type", data: `{"hosts":[{"name":"a","type":"upstart"}]}`, wantErr: true},
+		{name: "malformed", data: `{"hosts":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInventory([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fleet.json")
+	data := `{"hosts":[{"name":"edge","ssh":"edge.example.com","command":"sudo svcmgr","services":["proxy"]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, ok := inv.Host("edge")
+	if !ok || !host.Remote() || host.Command != "sudo svcmgr" || len(host.Services) != 1 {
+		t.Errorf("Host(edge) = %+v, %v", host, ok)
+	}
+	if _, ok := inv.Host("missing"); ok {
+		t.Error("Host(missing) found")
+	}
+}
@@ -0,0 +1,234 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/renameio/v2"
+)
+
+// DefaultJobPollInterval is how often Job.Run checks for supervision and completion
+const DefaultJobPollInterval = 100 * time.Millisecond
+
+// Files written to the service directory by a job's run script
+const (
+	jobExitFile   = "job.exit"
+	jobOutputFile = "job.out"
+)
+
+// Job is a run-to-completion task executed under supervision, such as a
+// database migration. For runit, daemontools and s6 a transient service is
+// built in a scan directory, started once and removed after it exits; for
+// systemd the command runs as a transient unit through systemd-run.
+type Job struct {
+	// Name is the transient service (or unit) name; it must not already exist
+	Name string
+
+	// Dir is the scan directory watched by the supervisor (unused for systemd)
+	Dir string
+
+	// Cmd is the command to run and its arguments
+	Cmd []string
+
+	// Type is the supervision system; zero means runit
+	Type ServiceType
+
+	// Configure, when set, customizes the service (environment, working
+	// directory, user, ...). For systemd the working directory, environment
+	// and chpst user and group are passed to systemd-run.
+	Configure func(*ServiceBuilder)
+
+	// CaptureOutput records the command's combined stdout and stderr in the
+	// result instead of sending it to the service's log
+	CaptureOutput bool
+
+	// PollInterval is how often supervision and completion are checked
+	PollInterval time.Duration
+
+	// KeepDir leaves the service directory in place after the job finishes
+	KeepDir bool
+
+	// SystemdClient, when set, supplies the sudo and user mode settings used
+	// to run systemd jobs
+	SystemdClient *ClientSystemd
+}
+
+// JobResult describes a finished job
+type JobResult struct {
+	// Name is the job's name
+	Name string
+
+	// ExitCode is the command's exit status; for runit, daemontools and s6,
+	// a command killed by a signal reports 128 plus the signal number
+	ExitCode int
+
+	// Output is the combined stdout and stderr when CaptureOutput is set
+	Output []byte
+
+	// Started and Finished bound the job's execution
+	Started  time.Time
+	Finished time.Time
+}
+
+// Success reports whether the command exited with status 0
+func (r *JobResult) Success() bool {
+	return r.ExitCode == 0
+}
+
+// Duration returns how long the job ran
+func (r *JobResult) Duration() time.Duration {
+	return r.Finished.Sub(r.Started)
+}
+
+// Run executes the job and waits for it to finish. A non-zero exit status is
+// reported in the result, not as an error. If ctx ends first, the job is
+// stopped and ctx's error returned.
+func (j *Job) Run(ctx context.Context) (*JobResult, error) {
+	if j.Name == "" || len(j.Cmd) == 0 {
+		return nil, errors.New("job: Name and Cmd are required")
+	}
+	if j.Type == ServiceTypeSystemd {
+		return j.runSystemd(ctx)
+	}
+	if j.Dir == "" {
+		return nil, errors.New("job: Dir is required")
+	}
+	return j.runSupervised(ctx)
+}
+
+// builder returns the job's service builder with Configure applied
+func (j *Job) builder() *ServiceBuilder {
+	var b *ServiceBuilder
+	switch j.Type {
+	case ServiceTypeDaemontools:
+		b = ServiceBuilderDaemontools(j.Name, j.Dir)
+	case ServiceTypeS6:
+		b = ServiceBuilderS6(j.Name, j.Dir)
+	default:
+		b = ServiceBuilderRunit(j.Name, j.Dir)
+	}
+	if j.Configure != nil {
+		j.Configure(b)
+	}
+	return b
+}
+
+// interval returns the polling interval
+func (j *Job) interval() time.Duration {
+	if j.PollInterval > 0 {
+		return j.PollInterval
+	}
+	return DefaultJobPollInterval
+}
+
+// runSupervised runs the job as a transient service in the scan directory
+func (j *Job) runSupervised(ctx context.Context) (result *JobResult, err error) {
+	serviceType := j.Type
+	if serviceType == ServiceTypeUnknown {
+		serviceType = ServiceTypeRunit
+	}
+	serviceDir, err := filepath.Abs(filepath.Join(j.Dir, j.Name))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(serviceDir); err == nil {
+		return nil, fmt.Errorf("job: %s already exists", serviceDir)
+	}
+
+	// The run script records the exit status, which the supervisors do not
+	// keep, in a file the job polls for
+	script := `"$@"`
+	if j.CaptureOutput {
+		script += " >" + shellQuote(filepath.Join(serviceDir, jobOutputFile)) + " 2>&1"
+	}
+	exitFile := shellQuote(filepath.Join(serviceDir, jobExitFile))
+	script += "; echo $? >" + exitFile + ".tmp && mv " + exitFile + ".tmp " + exitFile
+
+	b := j.builder()
+	b.WithCmd(append([]string{"/bin/sh", "-c", script, j.Name}, j.Cmd...))
+
+	// The service must not start until it is explicitly run once
+	if err := os.MkdirAll(serviceDir, DirMode); err != nil {
+		return nil, fmt.Errorf("job: creating service directory: %w", err)
+	}
+	if err := renameio.WriteFile(filepath.Join(serviceDir, "down"), nil, FileMode); err != nil {
+		return nil, fmt.Errorf("job: writing down file: %w", err)
+	}
+
+	var client ServiceClient
+	defer func() {
+		if cleanupErr := j.cleanup(ctx, client, serviceDir); cleanupErr != nil && err == nil {
+			err = cleanupErr
+		}
+	}()
+
+	if err := b.Build(); err != nil {
+		return nil, fmt.Errorf("job: building %s: %w", j.Name, err)
+	}
+
+	// The scanner creates the supervise directory once it notices the service
+	err = pollUntil(ctx, j.interval(), func() (bool, error) {
+		c, err := NewClient(serviceDir, WithServiceType(serviceType))
+		if err != nil {
+			return false, nil
+		}
+		client = c
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("job: waiting for %s to be supervised: %w", j.Name, err)
+	}
+
+	result = &JobResult{Name: j.Name, Started: time.Now()}
+	if err := client.Once(ctx); err != nil {
+		return nil, fmt.Errorf("job: starting %s: %w", j.Name, err)
+	}
+
+	var exitData []byte
+	err = pollUntil(ctx, j.interval(), func() (bool, error) {
+		data, err := os.ReadFile(filepath.Join(serviceDir, jobExitFile))
+		if err != nil {
+			return false, nil
+		}
+		exitData = data
+		return true, nil
+	})
+	result.Finished = time.Now()
+	if err != nil {
+		// Stop the job before its directory is removed
+		_ = client.Down(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("job: waiting for %s: %w", j.Name, err)
+	}
+
+	result.ExitCode, err = strconv.Atoi(strings.TrimSpace(string(exitData)))
+	if err != nil {
+		return nil, fmt.Errorf("job: %w: exit status %q", ErrDecode, exitData)
+	}
+	if j.CaptureOutput {
+		result.Output, err = os.ReadFile(filepath.Join(serviceDir, jobOutputFile))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("job: reading output: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// cleanup stops supervision of the job and removes its service directory
+func (j *Job) cleanup(ctx context.Context, client ServiceClient, serviceDir string) error {
+	if client != nil {
+		_ = client.ExitSupervise(context.WithoutCancel(ctx))
+	}
+	if j.KeepDir {
+		return nil
+	}
+	if err := os.RemoveAll(serviceDir); err != nil {
+		return fmt.Errorf("job: removing %s: %w", serviceDir, err)
+	}
+	return nil
+}
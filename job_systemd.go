@@ -0,0 +1,77 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// DefaultSystemdRunPath is the default path to the systemd-run binary
+const DefaultSystemdRunPath = "systemd-run"
+
+// runSystemd runs the job as a transient unit and waits for it to exit
+func (j *Job) runSystemd(ctx context.Context) (*JobResult, error) {
+	client := j.SystemdClient
+	if client == nil {
+		client = NewClientSystemd(j.Name)
+	}
+
+	args := j.systemdRunArgs()
+	if client.UserMode {
+		args = append([]string{"--user"}, args...)
+	}
+	cmd := client.command(ctx, client.systemdRunPath(), args...)
+
+	var output, stderr bytes.Buffer
+	if j.CaptureOutput {
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+	} else {
+		cmd.Stderr = &stderr
+	}
+
+	result := &JobResult{Name: j.Name, Started: time.Now()}
+	err := cmd.Run()
+	result.Finished = time.Now()
+	result.Output = output.Bytes()
+
+	if ctx.Err() != nil {
+		// systemd-run was killed; the unit itself keeps running
+		unit := *client
+		unit.ServiceName = j.Name
+		_ = unit.Down(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("job: waiting for %s: %w", j.Name, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		// With --wait, systemd-run exits with the unit's exit status
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		return nil, fmt.Errorf("job: running %s: %w (stderr: %s)", j.Name, err, stderr.String())
+	}
+	return result, nil
+}
+
+// systemdRunArgs returns the systemd-run arguments for the job
+func (j *Job) systemdRunArgs() []string {
+	args := []string{"--unit=" + j.Name, "--wait", "--collect", "--quiet", "--service-type=exec"}
+	if j.CaptureOutput {
+		args = append(args, "--pipe")
+	}
+
+	if j.Configure != nil {
+		b := NewServiceBuilder(j.Name, "")
+		j.Configure(b)
+		args = append(args, transientArgs(b.config)...)
+	}
+
+	args = append(args, "--")
+	return append(args, j.Cmd...)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+)
+
+// runSystemd is not supported off Linux
+func (j *Job) runSystemd(_ context.Context) (*JobResult, error) {
+	return nil, errors.New("job: systemd is only supported on Linux")
+}
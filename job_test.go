@@ -0,0 +1,141 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeJobScanner supervises serviceDir once its run script appears and runs
+// the script when told to start once
+func fakeJobScanner(t *testing.T, serviceDir string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	go func() {
+		defer close(done)
+		var mock *MockSupervisor
+		for ctx.Err() == nil {
+			time.Sleep(time.Millisecond)
+			if mock == nil {
+				if _, err := os.Stat(filepath.Join(serviceDir, "run")); err != nil {
+					continue
+				}
+				m, err := NewMockSupervisor(serviceDir)
+				if err != nil {
+					return
+				}
+				mock = m
+				continue
+			}
+			if data, _ := os.ReadFile(mock.ControlFile); len(data) > 0 && data[0] == OpOnce.Byte() {
+				cmd := exec.CommandContext(ctx, filepath.Join(serviceDir, "run"))
+				cmd.Dir = serviceDir
+				_ = cmd.Run()
+				return
+			}
+		}
+	}()
+}
+
+func TestJobRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		cmd        []string
+		capture    bool
+		wantCode   int
+		wantOutput string
+	}{
+		{
+			name:       "captured output",
+			cmd:        []string{"/bin/sh", "-c", "echo migrated; echo warning >&2; exit 3"},
+			capture:    true,
+			wantCode:   3,
+			wantOutput: "migrated\nwarning\n",
+		},
+		{
+			name: "success",
+			cmd:  []string{"/bin/true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fakeJobScanner(t, filepath.Join(dir, "migrate"))
+
+			job := &Job{Name: "migrate", Dir: dir, Cmd: tt.cmd, CaptureOutput: tt.capture, PollInterval: 5 * time.Millisecond}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := job.Run(ctx)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if result.ExitCode != tt.wantCode || result.Success() != (tt.wantCode == 0) {
+				t.Errorf("ExitCode = %d, want %d", result.ExitCode, tt.wantCode)
+			}
+			if string(result.Output) != tt.wantOutput {
+				t.Errorf("Output = %q, want %q", result.Output, tt.wantOutput)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "migrate")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("service directory not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestJobRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "taken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Job{Name: "taken", Dir: dir, Cmd: []string{"/bin/true"}}).Run(t.Context()); err == nil {
+		t.Error("Run succeeded over an existing service")
+	}
+
+	// Without a scanner the job is never supervised
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := (&Job{Name: "orphan", Dir: dir, Cmd: []string{"/bin/true"}, PollInterval: 5 * time.Millisecond}).Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run error = %v, want deadline exceeded", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "orphan")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("service directory not removed: %v", err)
+	}
+}
+
+func TestJobSystemdRunArgs(t *testing.T) {
+	job := &Job{
+		Name:          "migrate",
+		Cmd:           []string{"/bin/migrate", "--up"},
+		Type:          ServiceTypeSystemd,
+		CaptureOutput: true,
+		Configure: func(b *ServiceBuilder) {
+			b.WithCwd("/srv/app").WithEnv("B", "2").WithEnv("A", "1").
+				WithChpst(func(c *ChpstConfig) { c.User = "app" })
+		},
+	}
+
+	want := []string{
+		"--unit=migrate", "--wait", "--collect", "--quiet", "--service-type=exec", "--pipe",
+		"--working-directory=/srv/app", "--setenv=A=1", "--setenv=B=2", "--uid=app",
+		"--", "/bin/migrate", "--up",
+	}
+	if got := job.systemdRunArgs(); !slices.Equal(got, want) {
+		t.Errorf("systemdRunArgs() =\n%q\nwant\n%q", got, want)
+	}
+}
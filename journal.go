@@ -0,0 +1,108 @@
+package svcmgr
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is one WatchEvent recorded in a journal
+type JournalEntry struct {
+	// Time is when the event was recorded
+	Time time.Time `json:"time"`
+	// Service names the service the event is for
+	Service string `json:"service"`
+	// Event is the recorded event
+	Event WatchEvent `json:"event"`
+}
+
+// Journal appends WatchEvents to a file, one JSON JournalEntry per line, for
+// postmortem analysis and for replaying into an AlertEngine in tests
+type Journal struct {
+	// Clock timestamps entries; nil uses the wall clock
+	Clock Clock
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenJournal opens path for appending, creating it if needed
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	return &Journal{file: f}, nil
+}
+
+// Append records ev for service
+func (j *Journal) Append(service string, ev WatchEvent) error {
+	line, err := json.Marshal(JournalEntry{Time: clockNow(j.Clock), Service: service, Event: ev})
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("append journal: %w", err)
+	}
+	return nil
+}
+
+// Watch watches every client, keyed by service name, and records each event
+// until ctx is done, returning ctx.Err(), or ErrWatchClosed if every watch
+// ended first. Write errors are dropped.
+func (j *Journal) Watch(ctx context.Context, clients map[string]ServiceClient) error {
+	return watchAll(ctx, clients, func(name string, _ Status, ev WatchEvent) {
+		_ = j.Append(name, ev)
+	})
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// ReplayJournal reads the entries of a journal from r in order and calls fn
+// for each, stopping at the first error from fn or when ctx is done
+func ReplayJournal(ctx context.Context, r io.Reader, fn func(JournalEntry) error) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("%w: journal line %d: %v", ErrDecode, line, err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Replay feeds a journal through the engine as if the events were happening
+// at the entry times, so alerting rules can be tested deterministically
+// against recorded incidents. Duration rules are evaluated at each entry.
+// The engine's Clock is neither used nor changed.
+func (e *AlertEngine) Replay(ctx context.Context, r io.Reader) error {
+	return ReplayJournal(ctx, r, func(entry JournalEntry) error {
+		e.tickAt(entry.Time)
+		if entry.Event.Err == nil {
+			e.observeAt(entry.Service, entry.Event.Status, entry.Time)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	j.Clock = ClockFunc(func() time.Time { return now })
+	events := []struct {
+		after time.Duration
+		ev    WatchEvent
+	}{
+		{0, WatchEvent{Status: Status{State: StateRunning, PID: 10}}},
+		{time.Minute, WatchEvent{Status: Status{State: StateCrashed}}},
+		{10 * time.Second, WatchEvent{Err: errors.New("status unreadable")}},
+		{time.Minute, WatchEvent{Status: Status{State: StateRunning, PID: 11}}},
+	}
+	for _, e := range events {
+		now = now.Add(e.after)
+		if err := j.Append("web", e.ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := ParseAlertRule("state==crashed for >30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fired []Alert
+	engine := NewAlertEngine(func(a Alert) { fired = append(fired, a) }, rule)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := engine.Replay(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	// Crashed at 00:01:00, evaluated when the next status arrives at 00:02:10
+	if len(fired) != 1 {
+		t.Fatalf("fired %d alerts, want 1: %+v", len(fired), fired)
+	}
+	if want := time.Date(2026, 1, 1, 0, 2, 10, 0, time.UTC); !fired[0].Time.Equal(want) {
+		t.Errorf("alert time = %v, want %v", fired[0].Time, want)
+	}
+	if engine.Clock != nil {
+		t.Error("Replay changed the engine clock")
+	}
+}
@@ -0,0 +1,192 @@
+package svcmgr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+)
+
+// CurrentLogFile is the file svlogd, s6-log and multilog write to; rotated
+// files are named by their TAI64N label
+const CurrentLogFile = "current"
+
+// DefaultLogPollInterval is how often FollowLog checks for new lines
+const DefaultLogPollInterval = 250 * time.Millisecond
+
+// LogLine is one line of a service log
+type LogLine struct {
+	// Time is decoded from the line's TAI64N label; zero when the logger
+	// did not timestamp the line (svlogd -t, s6-log t and multilog t do)
+	Time time.Time
+	// Text is the line without its label and newline
+	Text string
+}
+
+// ParseLogLine splits a leading TAI64N label, as written by svlogd -t,
+// s6-log t and multilog t, from a log line
+func ParseLogLine(line string) LogLine {
+	line = strings.TrimSuffix(line, "\n")
+	label, text, ok := strings.Cut(line, " ")
+	if ok && len(label) == 1+2*tai64.SizeN && label[0] == '@' {
+		if l, err := tai64.Parse(label); err == nil {
+			return LogLine{Time: l.Time(), Text: text}
+		}
+	}
+	return LogLine{Text: line}
+}
+
+// FindLogDir returns the log directory of the service in serviceDir: the
+// first of DefaultScannerLogDirs holding a current log file
+func FindLogDir(serviceDir string) (string, error) {
+	for _, dir := range DefaultScannerLogDirs {
+		path := filepath.Join(serviceDir, dir)
+		if _, err := os.Stat(filepath.Join(path, CurrentLogFile)); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no log directory with a %s file in %s: %w", CurrentLogFile, serviceDir, os.ErrNotExist)
+}
+
+// TailLog returns the last n lines of the log in dir, reading back through
+// rotated files when the current one is shorter. n <= 0 returns every line.
+func TailLog(dir string, n int) ([]LogLine, error) {
+	files, err := logFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []LogLine
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Removed by the logger while reading
+				continue
+			}
+			return nil, err
+		}
+		var chunk []LogLine
+		for line := range strings.Lines(string(data)) {
+			chunk = append(chunk, ParseLogLine(line))
+		}
+		lines = append(chunk, lines...)
+		if n > 0 && len(lines) >= n {
+			return lines[len(lines)-n:], nil
+		}
+	}
+	return lines, nil
+}
+
+// logFiles returns the rotated log files in dir, oldest first, followed by
+// the current file
+func logFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type rotated struct {
+		label tai64.Label
+		path  string
+	}
+	var old []rotated
+	for _, entry := range entries {
+		if l, err := tai64.ParseFilename(entry.Name()); err == nil {
+			old = append(old, rotated{l, filepath.Join(dir, entry.Name())})
+		}
+	}
+	slices.SortFunc(old, func(a, b rotated) int { return a.label.Time().Compare(b.label.Time()) })
+
+	files := make([]string, 0, len(old)+1)
+	for _, r := range old {
+		files = append(files, r.path)
+	}
+	return append(files, filepath.Join(dir, CurrentLogFile)), nil
+}
+
+// FollowLog calls fn for every line appended to the current log file in dir
+// from now on, until ctx is done, returning ctx.Err(). When the logger
+// rotates the file, the rest of the old file is read before the new one is
+// followed from its start. interval is the polling interval; zero means
+// DefaultLogPollInterval.
+func FollowLog(ctx context.Context, dir string, interval time.Duration, fn func(LogLine)) error {
+	if interval <= 0 {
+		interval = DefaultLogPollInterval
+	}
+	path := filepath.Join(dir, CurrentLogFile)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	var partial string
+
+	// drain passes every complete line read from the open file to fn
+	drain := func() error {
+		for {
+			chunk, err := r.ReadString('\n')
+			partial += chunk
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			fn(ParseLogLine(partial))
+			partial = ""
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := drain(); err != nil {
+			return err
+		}
+
+		// Reopen when current was replaced (rotation) or truncated
+		if cur, err := os.Stat(path); err == nil {
+			open, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			pos, _ := f.Seek(0, io.SeekCurrent)
+			if !os.SameFile(open, cur) || cur.Size() < pos {
+				if next, err := os.Open(path); err == nil {
+					// Lines written just before the rotation
+					if err := drain(); err != nil {
+						_ = next.Close()
+						return err
+					}
+					_ = f.Close()
+					f = next
+					r.Reset(f)
+					if partial != "" {
+						fn(ParseLogLine(partial))
+						partial = ""
+					}
+					continue
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
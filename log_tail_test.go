@@ -0,0 +1,157 @@
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+)
+
+func TestParseLogLine(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	label := tai64.FromTime(ts).String()
+
+	got := ParseLogLine(label + " listening on :8080\n")
+	if !got.Time.Equal(ts) || got.Text != "listening on :8080" {
+		t.Errorf("ParseLogLine() = %+v", got)
+	}
+	for _, line := range []string{"plain line", "@notalabel text", "2024-05-01_10:00:00.00000 human timestamps"} {
+		if got := ParseLogLine(line); !got.Time.IsZero() || got.Text != line {
+			t.Errorf("ParseLogLine(%q) = %+v, want the line unchanged", line, got)
+		}
+	}
+}
+
+func TestTailLog(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	files := map[string]string{
+		tai64.FromTime(base).String() + ".s":                "one\ntwo\n",
+		tai64.FromTime(base.Add(time.Hour)).String() + ".s": "three\nfour\n",
+		CurrentLogFile: "five\n",
+		"lock":         "",
+		"state":        "",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{n: 1, want: []string{"five"}},
+		{n: 3, want: []string{"three", "four", "five"}},
+		{n: 0, want: []string{"one", "two", "three", "four", "five"}},
+		{n: 10, want: []string{"one", "two", "three", "four", "five"}},
+	}
+	for _, tt := range tests {
+		lines, err := TailLog(dir, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, l := range lines {
+			got = append(got, l.Text)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("TailLog(%d) = %q, want %q", tt.n, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("TailLog(%d) = %q, want %q", tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+
+	svc := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(svc, "log", "main"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(svc, "log", "main", CurrentLogFile), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := FindLogDir(svc); err != nil || got != filepath.Join(svc, "log", "main") {
+		t.Errorf("FindLogDir() = %q, %v", got, err)
+	}
+	if _, err := FindLogDir(dir); err == nil {
+		t.Error("FindLogDir() found a log directory in a plain directory")
+	}
+}
+
+func TestFollowLog(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, CurrentLogFile)
+	if err := os.WriteFile(current, []byte("old line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowLog(ctx, dir, 5*time.Millisecond, func(l LogLine) {
+			mu.Lock()
+			got = append(got, l.Text)
+			mu.Unlock()
+		})
+	}()
+	waitFor := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			l := len(got)
+			mu.Unlock()
+			if l >= n {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %d lines, got %q", n, got)
+	}
+	appendLine := func(path, line string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(line)
+		_ = f.Close()
+	}
+
+	// Give FollowLog time to open the file and seek to its end
+	time.Sleep(50 * time.Millisecond)
+	appendLine(current, "first\n")
+	waitFor(1)
+
+	// Rotate like svlogd: the tail of the old file is read, then the new one
+	appendLine(current, "before rotation\n")
+	if err := os.Rename(current, filepath.Join(dir, tai64.FromTime(time.Now()).String()+".s")); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(current, "after rotation\n")
+	waitFor(3)
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("FollowLog() = %v, want context.Canceled", err)
+	}
+	want := []string{"first", "before rotation", "after rotation"}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+}
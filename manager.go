@@ -13,6 +13,20 @@ type Manager struct {
 	Concurrency int
 	// Timeout is the per-operation timeout
 	Timeout time.Duration
+	// Ordered runs bulk operations one service at a time, in the order the
+	// services were given, ignoring Concurrency; the first failure stops
+	// the sequence and the remaining services fail with ErrStepSkipped
+	Ordered bool
+	// Instrumentation, when set, observes each bulk operation as a whole
+	// and, through the clients the manager creates, every service's
+	// control commands and status reads
+	Instrumentation Instrumentation
+	// EnsurePolicy sets how Ensure retries
+	EnsurePolicy EnsurePolicy
+	// Systemd, when set, is the systemd client StatusSystemd, SystemdUnits
+	// and UpSystemdUnits work through, carrying user mode, sudo or an
+	// escalator; nil uses NewClientSystemd's defaults
+	Systemd *ClientSystemd
 }
 
 // ManagerOption configures a Manager
@@ -32,6 +46,33 @@ func WithTimeout(d time.Duration) ManagerOption {
 	}
 }
 
+// WithOrdered makes bulk operations act on services strictly one after
+// another in the order they were given, for init sequences that need a
+// fixed order without a dependency graph. A failing service stops the
+// sequence: the services after it are not acted on and fail with
+// ErrStepSkipped. Use Stages to order groups of services while acting on
+// each group concurrently.
+func WithOrdered() ManagerOption {
+	return func(m *Manager) {
+		m.Ordered = true
+	}
+}
+
+// WithSystemdClient makes the manager's systemd operations run through a
+// copy of client, e.g. one set up with WithUserMode or WithSudo
+func WithSystemdClient(client *ClientSystemd) ManagerOption {
+	return func(m *Manager) {
+		m.Systemd = client
+	}
+}
+
+// WithManagerInstrumentation reports the manager's operations to inst
+func WithManagerInstrumentation(inst Instrumentation) ManagerOption {
+	return func(m *Manager) {
+		m.Instrumentation = inst
+	}
+}
+
 // NewManager creates a new Manager with default settings
 func NewManager(opts ...ManagerOption) *Manager {
 	m := &Manager{
@@ -50,22 +91,88 @@ func NewManager(opts ...ManagerOption) *Manager {
 	return m
 }
 
-func (m *Manager) execute(ctx context.Context, services []string, op func(context.Context, ServiceClient) error) error {
+// observe reports a bulk operation over n services that started at start
+func (m *Manager) observe(ctx context.Context, op Operation, n int, start time.Time, err error) {
+	if m.Instrumentation == nil {
+		return
+	}
+	id, _ := CorrelationID(ctx)
+	m.Instrumentation.ObserveOperation(OperationEvent{
+		Op:            op,
+		Services:      n,
+		Duration:      time.Since(start),
+		Err:           err,
+		CorrelationID: id,
+	})
+}
+
+func (m *Manager) execute(ctx context.Context, kind Operation, services []string, op func(context.Context, ServiceClient) error) (err error) {
 	if len(services) == 0 {
 		return nil
 	}
+	start := time.Now()
+	defer func() { m.observe(ctx, kind, len(services), start, err) }()
+
+	var mu sync.Mutex
+	merr := &MultiError{}
+
+	m.forEach(ctx, kind, services, func(ctx context.Context, svc string) error {
+		client, err := m.newClient(svc)
+		if err != nil {
+			return &OpError{Op: OpUnknown, Path: svc, Err: err}
+		}
+
+		// Create operation context with timeout if configured
+		if m.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+			defer cancel()
+		}
+
+		// Execute the operation
+		return op(ctx, client)
+	}, func(_ string, err error) {
+		mu.Lock()
+		merr.Add(err)
+		mu.Unlock()
+	})
+
+	return merr.Err()
+}
+
+// forEach runs fn for every service, passing its errors to fail along with
+// the service: one at a time in the given order when Ordered is set,
+// otherwise concurrently up to Concurrency. Services not yet started when ctx is done fail with
+// ctx.Err(). In Ordered mode the first failure stops the sequence, and the
+// services after it fail with an op OpError wrapping ErrStepSkipped.
+func (m *Manager) forEach(ctx context.Context, op Operation, services []string, fn func(context.Context, string) error, fail func(string, error)) {
+	if m.Ordered {
+		failed := false
+		for _, svc := range services {
+			if failed {
+				fail(svc, &OpError{Op: op, Path: svc, Err: ErrStepSkipped})
+				continue
+			}
+			err := ctx.Err()
+			if err == nil {
+				err = fn(ctx, svc)
+			}
+			if err != nil {
+				failed = true
+				fail(svc, err)
+			}
+		}
+		return
+	}
 
 	// Semaphore for concurrency control
 	sem := make(chan struct{}, m.Concurrency)
 
 	// Use WaitGroup for simpler goroutine management since we have finite work
 	var wg sync.WaitGroup
-	var mu sync.Mutex
-	merr := &MultiError{}
 
 	// Launch a goroutine for each service
 	for _, service := range services {
-
 		wg.Add(1)
 		go func(svc string) {
 			defer wg.Done()
@@ -75,140 +182,147 @@ func (m *Manager) execute(ctx context.Context, services []string, op func(contex
 			case sem <- struct{}{}:
 				defer func() { <-sem }()
 			case <-ctx.Done():
-				mu.Lock()
-				merr.Add(ctx.Err())
-				mu.Unlock()
-				return
-			}
-
-			// Default to runit for backward compatibility
-			client, err := NewClientRunit(svc)
-			if err != nil {
-				mu.Lock()
-				merr.Add(&OpError{Op: OpUnknown, Path: svc, Err: err})
-				mu.Unlock()
+				fail(svc, ctx.Err())
 				return
 			}
 
-			// Create operation context with timeout if configured
-			opCtx := ctx
-			if m.Timeout > 0 {
-				var cancel context.CancelFunc
-				opCtx, cancel = context.WithTimeout(ctx, m.Timeout)
-				defer cancel()
-			}
-
-			// Execute the operation
-			if err := op(opCtx, client); err != nil {
-				mu.Lock()
-				merr.Add(err)
-				mu.Unlock()
+			if err := fn(ctx, svc); err != nil {
+				fail(svc, err)
 			}
 		}(service)
 	}
 
 	// Wait for all goroutines to complete
 	wg.Wait()
-
-	return merr.Err()
 }
 
 // Up starts the specified services
 func (m *Manager) Up(ctx context.Context, services ...string) error {
-	return m.execute(ctx, services, func(ctx context.Context, c ServiceClient) error {
+	return m.execute(ctx, OpUp, services, func(ctx context.Context, c ServiceClient) error {
 		return c.Up(ctx)
 	})
 }
 
 // Down stops the specified services
 func (m *Manager) Down(ctx context.Context, services ...string) error {
-	return m.execute(ctx, services, func(ctx context.Context, c ServiceClient) error {
+	return m.execute(ctx, OpDown, services, func(ctx context.Context, c ServiceClient) error {
 		return c.Down(ctx)
 	})
 }
 
 // Term sends SIGTERM to the specified services
 func (m *Manager) Term(ctx context.Context, services ...string) error {
-	return m.execute(ctx, services, func(ctx context.Context, c ServiceClient) error {
+	return m.execute(ctx, OpTerm, services, func(ctx context.Context, c ServiceClient) error {
 		return c.Term(ctx)
 	})
 }
 
 // Kill sends SIGKILL to the specified services
 func (m *Manager) Kill(ctx context.Context, services ...string) error {
-	return m.execute(ctx, services, func(ctx context.Context, c ServiceClient) error {
+	return m.execute(ctx, OpKill, services, func(ctx context.Context, c ServiceClient) error {
 		return c.Kill(ctx)
 	})
 }
 
+// RestartWith restarts the specified services using strategy.
+// Each restart is bounded by the manager's Timeout.
+func (m *Manager) RestartWith(ctx context.Context, strategy RestartStrategy, services ...string) error {
+	return m.execute(ctx, OpRestart, services, strategy.Restart)
+}
+
 // Status retrieves the status of the specified services
-func (m *Manager) Status(ctx context.Context, services ...string) (map[string]Status, error) {
+func (m *Manager) Status(ctx context.Context, services ...string) (_ map[string]Status, err error) {
 	if len(services) == 0 {
 		return make(map[string]Status), nil
 	}
+	start := time.Now()
+	defer func() { m.observe(ctx, OpStatus, len(services), start, err) }()
 
-	// Semaphore for concurrency control
-	sem := make(chan struct{}, m.Concurrency)
-
-	// Use WaitGroup for simpler goroutine management since we have finite work
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	results := make(map[string]Status)
 	merr := &MultiError{}
 
-	// Launch a goroutine for each service
-	for _, service := range services {
-
-		wg.Add(1)
-		go func(svc string) {
-			defer wg.Done()
-
-			// Acquire semaphore slot
-			select {
-			case sem <- struct{}{}:
-				defer func() { <-sem }()
-			case <-ctx.Done():
-				mu.Lock()
-				merr.Add(ctx.Err())
-				mu.Unlock()
-				return
-			}
+	m.forEach(ctx, OpStatus, services, func(ctx context.Context, svc string) error {
+		client, err := m.newClient(svc)
+		if err != nil {
+			return &OpError{Op: OpStatus, Path: svc, Err: err}
+		}
+
+		// Create operation context with timeout if configured
+		if m.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+			defer cancel()
+		}
+
+		// Get status
+		status, err := client.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Store result
+		mu.Lock()
+		results[svc] = status
+		mu.Unlock()
+		return nil
+	}, func(_ string, err error) {
+		mu.Lock()
+		merr.Add(err)
+		mu.Unlock()
+	})
 
-			// Default to runit for backward compatibility
-			client, err := NewClientRunit(svc)
-			if err != nil {
-				mu.Lock()
-				merr.Add(&OpError{Op: OpStatus, Path: svc, Err: err})
-				mu.Unlock()
-				return
-			}
+	return results, merr.Err()
+}
 
-			// Create operation context with timeout if configured
-			opCtx := ctx
-			if m.Timeout > 0 {
-				var cancel context.CancelFunc
-				opCtx, cancel = context.WithTimeout(ctx, m.Timeout)
-				defer cancel()
-			}
+// Stages runs op on each group of services in turn, starting a group only
+// once op returned for the previous one; services within a group are
+// handled concurrently unless Ordered is set. A failing group stops the
+// sequence and its error is returned.
+//
+// Example, the database before the services using it:
+//
+//	err := mgr.Stages(ctx, mgr.Up, []string{"/etc/service/db"}, []string{"/etc/service/web", "/etc/service/worker"})
+func (m *Manager) Stages(ctx context.Context, op func(context.Context, ...string) error, groups ...[]string) error {
+	for _, group := range groups {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := op(ctx, group...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			// Get status
-			status, err := client.Status(opCtx)
-			if err != nil {
-				mu.Lock()
-				merr.Add(err)
-				mu.Unlock()
-				return
-			}
+// StatusSystemd retrieves the status of systemd units with a single
+// systemctl show for all of them, instead of one exec per unit, bounded by
+// the manager's Timeout. Names without a unit type suffix are services.
+func (m *Manager) StatusSystemd(ctx context.Context, units ...string) (map[string]Status, error) {
+	ctx, cancel := withDefaultTimeout(ctx, m.Timeout)
+	defer cancel()
+	return m.systemdClient().StatusMany(ctx, units...)
+}
 
-			// Store result
-			mu.Lock()
-			results[svc] = status
-			mu.Unlock()
-		}(service)
+// systemdClient returns a copy of Systemd not bound to a unit, or a default
+// client when it is unset, reporting to Instrumentation unless the copy
+// has its own
+func (m *Manager) systemdClient() *ClientSystemd {
+	c := NewClientSystemd("")
+	if m.Systemd != nil {
+		*c = *m.Systemd
+		c.ServiceName = ""
 	}
+	if c.Instrumentation == nil {
+		c.Instrumentation = m.Instrumentation
+	}
+	return c
+}
 
-	// Wait for all goroutines to complete
-	wg.Wait()
-
-	return results, merr.Err()
+// newClient creates the client every Manager method uses for a service
+// directory: reporting to Instrumentation, and for runit unless opts select
+// another supervision system
+func (m *Manager) newClient(serviceDir string, opts ...ClientOption) (ServiceClient, error) {
+	// Default to runit for backward compatibility
+	return NewClient(serviceDir, append([]ClientOption{ServiceTypeRunit, WithInstrumentation(m.Instrumentation)}, opts...)...)
 }
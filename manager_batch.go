@@ -0,0 +1,176 @@
+package svcmgr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchStep is one operation of a batch
+type BatchStep struct {
+	// Service is the service directory
+	Service string
+	// Op is the operation to perform; OpStatus only checks the status is readable
+	Op Operation
+	// Line is the step's line in the file it was parsed from, if any
+	Line int
+}
+
+// BatchResult is the outcome of a BatchStep
+type BatchResult struct {
+	Step BatchStep
+	// Err is nil when the step succeeded, and wraps ErrStepSkipped when it
+	// was not run
+	Err error
+	// Duration is how long the step took
+	Duration time.Duration
+}
+
+// ParseBatch reads a batch: one "<service> <operation>" pair per line,
+// with operations named as accepted by ParseOperation. Blank lines and
+// lines starting with # are ignored.
+//
+// Example batch:
+//
+//	# drain and restart the web tier
+//	web-1 term
+//	web-1 up
+//	web-2 restart
+func ParseBatch(r io.Reader) ([]BatchStep, error) {
+	var steps []BatchStep
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("batch line %d: want \"<service> <operation>\", got %q", n, line)
+		}
+		op, err := ParseOperation(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("batch line %d: %w", n, err)
+		}
+		steps = append(steps, BatchStep{Service: fields[0], Op: op, Line: n})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// Apply runs a batch. Steps for the same service run in order,
+// and once one fails the service's remaining steps are skipped; different
+// services run concurrently, up to Concurrency, or one at a time in the
+// order they first appear when Ordered is set, in which case a failing
+// service also skips the services after it. Each step is bounded by the
+// manager's Timeout. progress, if not nil, is called with every step's
+// result as it completes; calls are serialized. The returned MultiError
+// holds the failed and skipped steps. The batch is reported to
+// Instrumentation as a whole as OpUnknown, covering its services.
+func (m *Manager) Apply(ctx context.Context, steps []BatchStep, progress func(BatchResult)) (err error) {
+	var services []string
+	byService := make(map[string][]BatchStep)
+	for _, step := range steps {
+		if _, ok := byService[step.Service]; !ok {
+			services = append(services, step.Service)
+		}
+		byService[step.Service] = append(byService[step.Service], step)
+	}
+	if len(services) == 0 {
+		return nil
+	}
+	start := time.Now()
+	defer func() { m.observe(ctx, OpUnknown, len(services), start, err) }()
+
+	var mu sync.Mutex
+	merr := &MultiError{}
+	started := make(map[string]bool, len(services))
+
+	report := func(res BatchResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if res.Err != nil {
+			merr.Add(res.Err)
+		}
+		if progress != nil {
+			progress(res)
+		}
+	}
+
+	m.forEach(ctx, OpUnknown, services, func(ctx context.Context, svc string) error {
+		mu.Lock()
+		started[svc] = true
+		mu.Unlock()
+
+		steps := byService[svc]
+		client, err := m.newClient(svc)
+		if err != nil {
+			err = &OpError{Op: steps[0].Op, Path: svc, Err: err}
+			report(BatchResult{Step: steps[0], Err: err})
+			steps = steps[1:]
+		}
+		for _, step := range steps {
+			if err != nil {
+				report(BatchResult{Step: step, Err: &OpError{Op: step.Op, Path: step.Service, Err: ErrStepSkipped}})
+				continue
+			}
+			start := time.Now()
+			err = m.withTimeout(ctx, func(ctx context.Context) error {
+				return controlClient(ctx, client, step.Op)
+			})
+			report(BatchResult{Step: step, Err: err, Duration: time.Since(start)})
+		}
+		return err
+	}, func(svc string, err error) {
+		mu.Lock()
+		ran := started[svc]
+		mu.Unlock()
+		if ran {
+			// The failed step was already reported
+			return
+		}
+		// Not started: ctx is done, or an earlier service failed in
+		// Ordered mode
+		var oe *OpError
+		if errors.As(err, &oe) {
+			err = oe.Err
+		}
+		for _, step := range byService[svc] {
+			report(BatchResult{Step: step, Err: &OpError{Op: step.Op, Path: step.Service, Err: err}})
+		}
+	})
+
+	return merr.Err()
+}
+
+// controlClient performs op through client
+func controlClient(ctx context.Context, client ServiceClient, op Operation) error {
+	switch op {
+	case OpUp:
+		return client.Up(ctx)
+	case OpDown:
+		return client.Down(ctx)
+	case OpOnce:
+		return client.Once(ctx)
+	case OpPause:
+		return client.Pause(ctx)
+	case OpCont:
+		return client.Continue(ctx)
+	case OpRestart:
+		return client.Restart(ctx)
+	case OpExit:
+		return client.ExitSupervise(ctx)
+	case OpStatus:
+		_, err := client.Status(ctx)
+		return err
+	default:
+		return signalClient(ctx, client, op)
+	}
+}
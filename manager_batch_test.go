@@ -0,0 +1,142 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []BatchStep
+		wantErr bool
+	}{
+		{
+			name:  "steps and comments",
+			input: "# runbook\nweb term\n\n  web start\ndb RESTART\n",
+			want: []BatchStep{
+				{Service: "web", Op: OpTerm, Line: 2},
+				{Service: "web", Op: OpUp, Line: 4},
+				{Service: "db", Op: OpRestart, Line: 5},
+			},
+		},
+		{name: "empty", input: "\n# nothing\n"},
+		{name: "unknown operation", input: "web frobnicate\n", wantErr: true},
+		{name: "missing operation", input: "web\n", wantErr: true},
+		{name: "extra field", input: "web up now\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBatch(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseBatch() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("step %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestManagerApply(t *testing.T) {
+	dir := t.TempDir()
+	web := filepath.Join(dir, "web")
+	if _, err := NewMockSupervisor(web); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	steps := []BatchStep{
+		{Service: web, Op: OpDown},
+		{Service: missing, Op: OpUp},
+		{Service: web, Op: OpUp},
+		{Service: missing, Op: OpDown},
+	}
+	var results []BatchResult
+	err := NewManager(WithTimeout(time.Second)).Apply(context.Background(), steps, func(res BatchResult) {
+		results = append(results, res)
+	})
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 2 {
+		t.Fatalf("Apply() = %v, want the two steps of the missing service", err)
+	}
+	if len(results) != len(steps) {
+		t.Fatalf("got %d results, want %d", len(results), len(steps))
+	}
+	for _, res := range results {
+		switch {
+		case res.Step.Service == web && res.Err != nil:
+			t.Errorf("%v: %v", res.Step.Op, res.Err)
+		case res.Step.Service == missing && res.Step.Op == OpDown && !errors.Is(res.Err, ErrStepSkipped):
+			t.Errorf("step after a failure = %v, want ErrStepSkipped", res.Err)
+		case res.Step.Service == missing && res.Step.Op == OpUp && (res.Err == nil || errors.Is(res.Err, ErrStepSkipped)):
+			t.Errorf("first step of a missing service = %v, want a client error", res.Err)
+		}
+	}
+
+	// The web steps ran in order
+	data, err := os.ReadFile(filepath.Join(web, SuperviseDir, ControlFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "u" {
+		t.Errorf("last control byte = %q, want \"u\"", data)
+	}
+}
+
+func TestManagerApplyOrdered(t *testing.T) {
+	dir := t.TempDir()
+	var services []string
+	for _, name := range []string{"a", "b", "c"} {
+		svc := filepath.Join(dir, name)
+		if _, err := NewMockSupervisor(svc); err != nil {
+			t.Fatal(err)
+		}
+		services = append(services, svc)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	steps := []BatchStep{
+		{Service: services[0], Op: OpDown},
+		{Service: services[1], Op: OpDown},
+		{Service: services[0], Op: OpUp},
+		{Service: services[1], Op: OpUp},
+		{Service: missing, Op: OpUp},
+		{Service: services[2], Op: OpUp},
+		{Service: services[2], Op: OpDown},
+	}
+	var got []string
+	err := NewManager(WithConcurrency(4), WithOrdered(), WithTimeout(time.Second)).Apply(context.Background(), steps, func(res BatchResult) {
+		got = append(got, filepath.Base(res.Step.Service)+" "+res.Step.Op.String())
+		if res.Step.Service == services[2] && !errors.Is(res.Err, ErrStepSkipped) {
+			t.Errorf("step after the failed service = %v, want ErrStepSkipped", res.Err)
+		}
+	})
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 3 {
+		t.Fatalf("Apply() = %v, want the missing service and the two skipped steps", err)
+	}
+	want := []string{"a down", "a up", "b down", "b up", "missing up", "c up", "c down"}
+	if !slices.Equal(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(services[2], SuperviseDir, ControlFile)); len(data) != 0 {
+		t.Errorf("skipped service received control bytes %q", data)
+	}
+}
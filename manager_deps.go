@@ -0,0 +1,352 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultReadyPollInterval is how often UpUnits checks whether a started service is ready
+const DefaultReadyPollInterval = 100 * time.Millisecond
+
+// errDependencyCycle is returned by UpUnits when units depend on each other
+var errDependencyCycle = errors.New("dependency cycle")
+
+// ServiceUnit is a service directory with its relations to other services.
+// The relations follow systemd: Wants and Requires pull services in, Requires
+// additionally fails the unit when the dependency fails, and After (implied
+// by Requires) only orders startup.
+type ServiceUnit struct {
+	// Dir is the service directory
+	Dir string
+
+	// Wants lists services started along with this one; their failure is ignored
+	Wants []string
+
+	// Requires lists services that must be running and ready before this one
+	// is started; if one fails, this unit fails with ErrDependencyFailed
+	Requires []string
+
+	// After lists services that, when part of the same UpUnits call, are
+	// started and waited for before this one
+	After []string
+}
+
+// DependencyError reports a unit that was skipped because a required service failed
+type DependencyError struct {
+	// Service is the unit that was not started
+	Service string
+	// Dependency is the required service that failed
+	Dependency string
+}
+
+// Error returns a formatted error message
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("%s: required dependency %s failed", e.Service, e.Dependency)
+}
+
+// Unwrap returns ErrDependencyFailed
+func (e *DependencyError) Unwrap() error {
+	return ErrDependencyFailed
+}
+
+// UpUnits starts units and everything they pull in, honoring their relations.
+// Services start concurrently (up to Concurrency) as soon as the services
+// they are ordered after have started and become ready, i.e. are running and,
+// where the backend supports readiness notification, report ready. Each
+// service's start and readiness wait is bounded by the manager's Timeout.
+// Dependency cycles are rejected before anything is started.
+func (m *Manager) UpUnits(ctx context.Context, units ...ServiceUnit) error {
+	return m.upUnits(ctx, units, filepath.Abs, m.upAndWaitReady)
+}
+
+// upUnits starts units in dependency order, naming services as resolved by
+// resolve and starting each with up
+func (m *Manager) upUnits(ctx context.Context, units []ServiceUnit, resolve func(string) (string, error), up func(context.Context, string) error) (err error) {
+	start := time.Now()
+	defer func() { m.observe(ctx, OpUp, len(units), start, err) }()
+
+	graph, err := newUnitGraph(units, resolve)
+	if err != nil {
+		return err
+	}
+
+	errs := graph.run(ctx, m.Concurrency, func(ctx context.Context, dir string) error {
+		return m.withTimeout(ctx, func(ctx context.Context) error {
+			return up(ctx, dir)
+		})
+	})
+	merr := &MultiError{}
+	for _, dir := range graph.order {
+		merr.Add(errs[dir])
+	}
+	return merr.Err()
+}
+
+// upAndWaitReady starts the service in dir and waits until it is ready
+func (m *Manager) upAndWaitReady(ctx context.Context, dir string) error {
+	client, err := m.newClient(dir)
+	if err != nil {
+		return &OpError{Op: OpUp, Path: dir, Err: err}
+	}
+	if err := client.Up(ctx); err != nil {
+		return err
+	}
+
+	if err := waitGates(ctx, client, []ReadinessGate{StateGate()}, DefaultReadyPollInterval); err != nil {
+		return &OpError{Op: OpUp, Path: dir, Err: fmt.Errorf("not ready: %w", err)}
+	}
+	return nil
+}
+
+// unitGraph is the set of units to start, with their ordering
+type unitGraph struct {
+	// units maps each service directory to its unit
+	units map[string]*ServiceUnit
+	// dirs lists the services, declared ones first in declaration order
+	dirs []string
+	// before maps each service to the services that must finish first
+	before map[string][]string
+	// order lists the services in dependency order; set by sort
+	order []string
+}
+
+// newUnitGraph resolves service names with resolve, pulls in wanted and
+// required services and orders them, rejecting cycles
+func newUnitGraph(units []ServiceUnit, resolve func(string) (string, error)) (*unitGraph, error) {
+	g, err := buildUnitGraph(units, resolve)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.sort(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// buildUnitGraph resolves service names with resolve and pulls in wanted
+// and required services, without checking the ordering
+func buildUnitGraph(units []ServiceUnit, resolve func(string) (string, error)) (*unitGraph, error) {
+	g := &unitGraph{
+		units:  make(map[string]*ServiceUnit),
+		before: make(map[string][]string),
+	}
+
+	abs := func(dirs []string) ([]string, error) {
+		out := make([]string, 0, len(dirs))
+		for _, dir := range dirs {
+			a, err := resolve(dir)
+			if err != nil {
+				return nil, fmt.Errorf("resolving service dir: %w", err)
+			}
+			out = append(out, a)
+		}
+		return out, nil
+	}
+
+	for _, u := range units {
+		resolved := &ServiceUnit{}
+		var err error
+		if resolved.Dir, err = resolve(u.Dir); err != nil {
+			return nil, fmt.Errorf("resolving service dir: %w", err)
+		}
+		if resolved.Wants, err = abs(u.Wants); err != nil {
+			return nil, err
+		}
+		if resolved.Requires, err = abs(u.Requires); err != nil {
+			return nil, err
+		}
+		if resolved.After, err = abs(u.After); err != nil {
+			return nil, err
+		}
+		if _, dup := g.units[resolved.Dir]; dup {
+			return nil, fmt.Errorf("service %s declared more than once", resolved.Dir)
+		}
+		g.units[resolved.Dir] = resolved
+		g.dirs = append(g.dirs, resolved.Dir)
+	}
+
+	// Pull in wanted and required services that were not declared
+	for _, dir := range g.dirs[:len(g.dirs):len(g.dirs)] {
+		u := g.units[dir]
+		for _, dep := range append(append([]string(nil), u.Wants...), u.Requires...) {
+			if _, ok := g.units[dep]; !ok {
+				g.units[dep] = &ServiceUnit{Dir: dep}
+				g.dirs = append(g.dirs, dep)
+			}
+		}
+	}
+
+	for _, dir := range g.dirs {
+		u := g.units[dir]
+		for _, dep := range append(append([]string(nil), u.Requires...), u.After...) {
+			if _, ok := g.units[dep]; ok && dep != dir {
+				g.before[dir] = append(g.before[dir], dep)
+			}
+		}
+	}
+	return g, nil
+}
+
+// sort orders the services for starting, rejecting cycles with
+// errDependencyCycle. The depth-first sort keeps the declared order where
+// possible.
+func (g *unitGraph) sort() error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(g.units))
+	var visit func(dir string, path []string) error
+	visit = func(dir string, path []string) error {
+		switch state[dir] {
+		case visiting:
+			return fmt.Errorf("%w: %v", errDependencyCycle, append(path, dir))
+		case visited:
+			return nil
+		}
+		state[dir] = visiting
+		for _, dep := range g.before[dir] {
+			if err := visit(dep, append(path, dir)); err != nil {
+				return err
+			}
+		}
+		state[dir] = visited
+		g.order = append(g.order, dir)
+		return nil
+	}
+
+	g.order = nil
+	for _, dir := range g.dirs {
+		if err := visit(dir, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cycles finds the strongly connected components of the ordering (Tarjan's
+// algorithm), returning for every service on a cycle a non-zero component
+// number shared by the rest of its cycle. A service ordered after itself
+// is a cycle of its own.
+func (g *unitGraph) cycles() map[string]int {
+	index := make(map[string]int, len(g.units))
+	low := make(map[string]int, len(g.units))
+	onStack := make(map[string]bool)
+	var stack []string
+	next, component := 1, 0
+	cycle := make(map[string]int)
+
+	var connect func(v string)
+	connect = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.before[v] {
+			switch {
+			case index[w] == 0:
+				connect(w)
+				low[v] = min(low[v], low[w])
+			case onStack[w]:
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+
+		// v is the root of a component: pop it
+		var members []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			members = append(members, w)
+			if w == v {
+				break
+			}
+		}
+		u := g.units[v]
+		if len(members) > 1 || slices.Contains(u.Requires, v) || slices.Contains(u.After, v) {
+			component++
+			for _, w := range members {
+				cycle[w] = component
+			}
+		}
+	}
+
+	for _, v := range g.dirs {
+		if index[v] == 0 {
+			connect(v)
+		}
+	}
+	return cycle
+}
+
+// run starts the services of a sorted graph with start, up to concurrency
+// at once, each as soon as the services it comes after have finished. A
+// service whose required dependency failed is not started and fails with a
+// DependencyError. The error of every service is returned, nil for those
+// that started.
+func (g *unitGraph) run(ctx context.Context, concurrency int, start func(ctx context.Context, dir string) error) map[string]error {
+	done := make(map[string]chan struct{}, len(g.order))
+	for _, dir := range g.order {
+		done[dir] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[string]error, len(g.order))
+	setErr := func(dir string, err error) {
+		mu.Lock()
+		errs[dir] = err
+		mu.Unlock()
+	}
+	failed := func(dir string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return errs[dir] != nil
+	}
+
+	for _, dir := range g.order {
+		wg.Add(1)
+		go func(u *ServiceUnit) {
+			defer wg.Done()
+			defer close(done[u.Dir])
+
+			for _, dep := range g.before[u.Dir] {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					setErr(u.Dir, ctx.Err())
+					return
+				}
+			}
+			for _, dep := range u.Requires {
+				if failed(dep) {
+					setErr(u.Dir, &DependencyError{Service: u.Dir, Dependency: dep})
+					return
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				setErr(u.Dir, ctx.Err())
+				return
+			}
+			setErr(u.Dir, start(ctx, u.Dir))
+		}(g.units[dir])
+	}
+
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,70 @@
+package svcmgr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// WriteDOT writes the dependency graph of units in Graphviz DOT format,
+// for visualizing startup ordering, e.g. with "dot -Tsvg". Every unit and
+// every service it names is a node labeled with its base name; edges point
+// from a unit to the services it relates to: Requires solid, Wants dashed
+// and After dotted. Services are named by absolute path, resolved as
+// UpUnits does. Unlike UpUnits, cycles are not rejected: the edges of each
+// ordering cycle (through Requires and After) are drawn red so they can be
+// spotted before they bite at boot.
+func WriteDOT(w io.Writer, units ...ServiceUnit) error {
+	graph, err := buildUnitGraph(units, filepath.Abs)
+	if err != nil {
+		return err
+	}
+
+	type edge struct {
+		from, to string
+		kind     string
+	}
+	nodes := slices.Clone(graph.dirs)
+	var edges []edge
+	for _, dir := range graph.dirs {
+		u := graph.units[dir]
+		for _, rel := range []struct {
+			kind string
+			deps []string
+		}{{"requires", u.Requires}, {"wants", u.Wants}, {"after", u.After}} {
+			for _, dep := range rel.deps {
+				if _, ok := graph.units[dep]; !ok && !slices.Contains(nodes, dep) {
+					nodes = append(nodes, dep)
+				}
+				edges = append(edges, edge{u.Dir, dep, rel.kind})
+			}
+		}
+	}
+	cycle := graph.cycles()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph services {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	for _, n := range nodes {
+		fmt.Fprintf(bw, "\t%s [label=%s];\n", strconv.Quote(n), strconv.Quote(filepath.Base(n)))
+	}
+	for _, e := range edges {
+		attrs := "label=" + e.kind
+		switch e.kind {
+		case "wants":
+			attrs += ", style=dashed"
+		case "after":
+			attrs += ", style=dotted"
+		}
+		if e.kind != "wants" && cycle[e.from] != 0 && cycle[e.from] == cycle[e.to] {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [%s];\n", strconv.Quote(e.from), strconv.Quote(e.to), attrs)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,96 @@
+package svcmgr
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// absDir resolves dir as WriteDOT does
+func absDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		panic(err)
+	}
+	return abs
+}
+
+func TestWriteDOT(t *testing.T) {
+	tests := []struct {
+		name  string
+		units []ServiceUnit
+		want  []string
+		red   int
+	}{
+		{
+			name: "chain",
+			units: []ServiceUnit{
+				{Dir: "/etc/service/web", Requires: []string{"/etc/service/db"}, Wants: []string{"/etc/service/cache"}},
+				{Dir: "/etc/service/db", After: []string{"/etc/service/log"}},
+			},
+			want: []string{
+				`"/etc/service/web" [label="web"];`,
+				`"/etc/service/cache" [label="cache"];`,
+				`"/etc/service/web" -> "/etc/service/db" [label=requires];`,
+				`"/etc/service/web" -> "/etc/service/cache" [label=wants, style=dashed];`,
+				`"/etc/service/db" -> "/etc/service/log" [label=after, style=dotted];`,
+			},
+		},
+		{
+			name: "cycle",
+			units: []ServiceUnit{
+				{Dir: "/srv/a", Requires: []string{"/srv/b"}},
+				{Dir: "/srv/b", After: []string{"/srv/c"}},
+				{Dir: "/srv/c", Requires: []string{"/srv/a"}, Wants: []string{"/srv/b"}},
+				{Dir: "/srv/d", After: []string{"/srv/a"}},
+			},
+			want: []string{
+				`"/srv/a" -> "/srv/b" [label=requires, color=red];`,
+				`"/srv/b" -> "/srv/c" [label=after, style=dotted, color=red];`,
+				`"/srv/c" -> "/srv/a" [label=requires, color=red];`,
+				`"/srv/c" -> "/srv/b" [label=wants, style=dashed];`,
+				`"/srv/d" -> "/srv/a" [label=after, style=dotted];`,
+			},
+			red: 3,
+		},
+		{
+			name:  "self",
+			units: []ServiceUnit{{Dir: "/srv/a", After: []string{"/srv/a"}}},
+			want:  []string{`"/srv/a" -> "/srv/a" [label=after, style=dotted, color=red];`},
+			red:   1,
+		},
+		{
+			name: "relative names",
+			units: []ServiceUnit{
+				{Dir: "web", Requires: []string{"db"}},
+				{Dir: "db", After: []string{"./web"}},
+			},
+			want: []string{
+				strconv.Quote(absDir("web")) + " -> " + strconv.Quote(absDir("db")) + " [label=requires, color=red];",
+				strconv.Quote(absDir("db")) + " -> " + strconv.Quote(absDir("web")) + " [label=after, style=dotted, color=red];",
+			},
+			red: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteDOT(&sb, tt.units...); err != nil {
+				t.Fatal(err)
+			}
+			out := sb.String()
+			if !strings.HasPrefix(out, "digraph services {\n") || !strings.HasSuffix(out, "}\n") {
+				t.Errorf("not a digraph:\n%s", out)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(out, "\t"+line+"\n") {
+					t.Errorf("missing %s in:\n%s", line, out)
+				}
+			}
+			if got := strings.Count(out, "color=red"); got != tt.red {
+				t.Errorf("%d red edges, want %d:\n%s", got, tt.red, out)
+			}
+		})
+	}
+}
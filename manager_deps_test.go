@@ -0,0 +1,154 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeUnits creates mock services under a temporary directory. Services
+// report running once told to go up, except those listed in broken; the
+// order in which services were told to go up is returned through started.
+func fakeUnits(t *testing.T, names []string, broken ...string) (dir string, started func() []string) {
+	t.Helper()
+	dir = t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var order []string
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	for i, name := range names {
+		mock, err := NewMockSupervisor(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(name string, pid int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				time.Sleep(time.Millisecond)
+				if data, _ := os.ReadFile(mock.ControlFile); len(data) > 0 && data[0] == OpUp.Byte() {
+					mu.Lock()
+					order = append(order, name)
+					mu.Unlock()
+					if !slices.Contains(broken, name) {
+						_ = mock.UpdateStatus(true, pid)
+					}
+					return
+				}
+			}
+		}(name, 100+i)
+	}
+
+	return dir, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(order)
+	}
+}
+
+func TestManagerUpUnits(t *testing.T) {
+	tests := []struct {
+		name        string
+		units       func(dir string) []ServiceUnit
+		broken      []string
+		wantErr     error
+		wantStarted []string
+		wantFailed  []string
+	}{
+		{
+			name: "requires orders startup",
+			units: func(dir string) []ServiceUnit {
+				return []ServiceUnit{
+					{Dir: filepath.Join(dir, "app"), Requires: []string{filepath.Join(dir, "db")}},
+					{Dir: filepath.Join(dir, "db")},
+				}
+			},
+			wantStarted: []string{"db", "app"},
+		},
+		{
+			name: "requires pulls in undeclared services",
+			units: func(dir string) []ServiceUnit {
+				return []ServiceUnit{
+					{Dir: filepath.Join(dir, "app"), Requires: []string{filepath.Join(dir, "db")}},
+				}
+			},
+			wantStarted: []string{"db", "app"},
+		},
+		{
+			name: "failed requirement fails dependents",
+			units: func(dir string) []ServiceUnit {
+				return []ServiceUnit{
+					{Dir: filepath.Join(dir, "app"), Requires: []string{filepath.Join(dir, "db")}},
+				}
+			},
+			broken:      []string{"db"},
+			wantErr:     ErrDependencyFailed,
+			wantStarted: []string{"db"},
+			wantFailed:  []string{"app"},
+		},
+		{
+			name: "failed want is ignored",
+			units: func(dir string) []ServiceUnit {
+				return []ServiceUnit{
+					{
+						Dir:   filepath.Join(dir, "app"),
+						Wants: []string{filepath.Join(dir, "cache")},
+						After: []string{filepath.Join(dir, "cache")},
+					},
+				}
+			},
+			broken:      []string{"cache"},
+			wantErr:     context.DeadlineExceeded,
+			wantStarted: []string{"cache", "app"},
+		},
+		{
+			name: "cycle is rejected",
+			units: func(dir string) []ServiceUnit {
+				return []ServiceUnit{
+					{Dir: filepath.Join(dir, "app"), Requires: []string{filepath.Join(dir, "db")}},
+					{Dir: filepath.Join(dir, "db"), After: []string{filepath.Join(dir, "app")}},
+				}
+			},
+			wantErr: errDependencyCycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, started := fakeUnits(t, []string{"app", "db", "cache"}, tt.broken...)
+			m := NewManager(WithTimeout(300 * time.Millisecond))
+
+			err := m.UpUnits(context.Background(), tt.units(dir)...)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("UpUnits: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpUnits error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := started(); !slices.Equal(got, tt.wantStarted) {
+				t.Errorf("started %v, want %v", got, tt.wantStarted)
+			}
+
+			for _, name := range tt.wantFailed {
+				var depErr *DependencyError
+				if !errors.As(err, &depErr) || !strings.HasSuffix(depErr.Service, name) {
+					t.Errorf("error %v does not report %s as a failed dependent", err, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultEnsureAttempts is how often Ensure issues the operation before
+	// giving up
+	DefaultEnsureAttempts = 3
+	// DefaultEnsureBackoff is the delay before Ensure's second attempt
+	DefaultEnsureBackoff = 500 * time.Millisecond
+)
+
+// EnsurePolicy configures how Manager.Ensure retries
+type EnsurePolicy struct {
+	// Attempts is how often the operation is issued before giving up;
+	// zero means DefaultEnsureAttempts
+	Attempts int
+	// Backoff is the delay before the second attempt, doubled for every
+	// further one; zero means DefaultEnsureBackoff
+	Backoff time.Duration
+}
+
+// WithEnsurePolicy sets how Ensure retries
+func WithEnsurePolicy(p EnsurePolicy) ManagerOption {
+	return func(m *Manager) {
+		m.EnsurePolicy = p
+	}
+}
+
+// ensureOps maps the states Ensure converges to onto the operation
+// usually reaching them
+var ensureOps = map[State]Operation{
+	StateRunning: OpUp,
+	StateDown:    OpDown,
+	StatePaused:  OpPause,
+}
+
+// ensureOp returns the operation bringing a service in current to desired,
+// or ErrStateUnreachable when none can. current is StateUnknown when the
+// status could not be read.
+func ensureOp(current, desired State) (Operation, error) {
+	switch {
+	case current == StateExited:
+		// Without a supervisor nothing reads the control file
+		return OpUnknown, fmt.Errorf("%w: supervisor has exited", ErrStateUnreachable)
+	case desired == StateRunning && current == StatePaused:
+		// Up does not continue a stopped process
+		return OpCont, nil
+	case desired == StatePaused && current != StateRunning && current != StateUnknown:
+		return OpUnknown, fmt.Errorf("%w: cannot pause a service that is %v", ErrStateUnreachable, current)
+	}
+	return ensureOps[desired], nil
+}
+
+// Ensure converges service to desired, one of StateRunning, StateDown and
+// StatePaused, and verifies it got there. A service already in desired is
+// left alone. Otherwise the operation reaching desired from the current
+// state is issued, Continue for a paused service to run, and Ensure waits
+// for the supervisor to report the state, both bounded by the manager's
+// Timeout; failed attempts are retried per EnsurePolicy. Transitions no
+// operation makes, such as pausing a stopped service or anything once the
+// supervisor exited, fail at once with ErrStateUnreachable. The last
+// status read is returned, with an OpError when desired was not reached.
+//
+// Example:
+//
+//	st, err := mgr.Ensure(ctx, "/etc/service/web", svcmgr.StateRunning)
+//	if err != nil {
+//		return fmt.Errorf("web is %v: %w", st.State, err)
+//	}
+func (m *Manager) Ensure(ctx context.Context, service string, desired State) (_ Status, err error) {
+	op, ok := ensureOps[desired]
+	if !ok {
+		return Status{}, &OpError{Op: OpUnknown, Path: service, Err: fmt.Errorf("%w: cannot ensure state %v", errors.ErrUnsupported, desired)}
+	}
+	start := time.Now()
+	defer func() { m.observe(ctx, op, 1, start, err) }()
+
+	client, err := m.newClient(service)
+	if err != nil {
+		return Status{}, &OpError{Op: op, Path: service, Err: err}
+	}
+
+	attempts := m.EnsurePolicy.Attempts
+	if attempts <= 0 {
+		attempts = DefaultEnsureAttempts
+	}
+	backoff := m.EnsurePolicy.Backoff
+	if backoff <= 0 {
+		backoff = DefaultEnsureBackoff
+	}
+
+	var st Status
+	var lastErr error
+	attempt := 0
+	for attempt < attempts {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return st, &OpError{Op: op, Path: service, Err: ctx.Err()}
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+		attempt++
+
+		current := StateUnknown
+		if cur, err := client.Status(ctx); err == nil {
+			st, current = cur, cur.State
+			if current == desired {
+				return st, nil
+			}
+		}
+		attemptOp, err := ensureOp(current, desired)
+		if err != nil {
+			return st, &OpError{Op: op, Path: service, Err: err}
+		}
+		lastErr = m.withTimeout(ctx, func(ctx context.Context) error {
+			if err := controlClient(ctx, client, attemptOp); err != nil {
+				return err
+			}
+			cur, err := client.Wait(ctx, []State{desired})
+			if err == nil {
+				st = cur
+			}
+			return err
+		})
+		if lastErr == nil {
+			return st, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if cur, err := client.Status(ctx); err == nil {
+		st = cur
+	}
+	return st, &OpError{Op: op, Path: service, Err: fmt.Errorf("%v not reached after %d attempts: %w", desired, attempt, lastErr)}
+}
@@ -0,0 +1,148 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// respondToControl calls respond once cmd is written to the control file of
+// mock, until the test ends
+func respondToControl(t *testing.T, mock *MockSupervisor, cmd string, respond func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		_ = pollUntil(ctx, 5*time.Millisecond, func() (bool, error) {
+			data, _ := os.ReadFile(mock.ControlFile)
+			if string(data) != cmd {
+				return false, nil
+			}
+			return true, respond()
+		})
+	}()
+}
+
+func TestManagerEnsure(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respondToControl(t, mock, "u", func() error { return mock.UpdateStatus(true, 4242) })
+
+	mgr := NewManager(WithTimeout(2 * time.Second))
+	st, err := mgr.Ensure(context.Background(), dir, StateRunning)
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if st.State != StateRunning || st.PID != 4242 {
+		t.Errorf("Ensure() = %v pid %d, want running pid 4242", st.State, st.PID)
+	}
+
+	// A converged service gets no control command
+	if err := os.WriteFile(mock.ControlFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.Ensure(context.Background(), dir, StateRunning); err != nil {
+		t.Fatalf("Ensure() of a running service error = %v", err)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); len(data) != 0 {
+		t.Errorf("control bytes = %q, want none for a running service", data)
+	}
+}
+
+func TestManagerEnsureRetries(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewMockSupervisor(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	ups := 0
+	inst := InstrumentationFunc(func(ev OperationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ev.Op == OpUp && ev.Service != "" {
+			ups++
+		}
+	})
+	mgr := NewManager(
+		WithTimeout(50*time.Millisecond),
+		WithManagerInstrumentation(inst),
+		WithEnsurePolicy(EnsurePolicy{Attempts: 2, Backoff: time.Millisecond}),
+	)
+
+	st, err := mgr.Ensure(context.Background(), dir, StateRunning)
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != OpUp || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Ensure() error = %v, want an OpUp timeout", err)
+	}
+	if st.State != StateDown {
+		t.Errorf("Ensure() state = %v, want the last status read (down)", st.State)
+	}
+	if ups != 2 {
+		t.Errorf("issued Up %d times, want 2", ups)
+	}
+
+	if _, err := mgr.Ensure(context.Background(), dir, StateCrashed); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Ensure(StateCrashed) error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestManagerEnsureFromPaused(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mock.StatusFile, makeStatusData(4242, 'u', 1, 1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	respondToControl(t, mock, "c", func() error { return mock.UpdateStatus(true, 4242) })
+
+	mgr := NewManager(WithTimeout(2 * time.Second))
+	st, err := mgr.Ensure(context.Background(), dir, StateRunning)
+	if err != nil {
+		t.Fatalf("Ensure() of a paused service error = %v", err)
+	}
+	if st.State != StateRunning {
+		t.Errorf("Ensure() state = %v, want running", st.State)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); string(data) != "c" {
+		t.Errorf("control byte = %q, want \"c\" to continue the paused process", data)
+	}
+}
+
+func TestManagerEnsureUnreachable(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(WithTimeout(time.Second))
+	start := time.Now()
+	st, err := mgr.Ensure(context.Background(), dir, StatePaused)
+	if !errors.Is(err, ErrStateUnreachable) {
+		t.Fatalf("Ensure(StatePaused) of a down service error = %v, want ErrStateUnreachable", err)
+	}
+	if st.State != StateDown {
+		t.Errorf("Ensure() state = %v, want down", st.State)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Ensure() took %v, want it to fail up front", elapsed)
+	}
+	if data, _ := os.ReadFile(mock.ControlFile); len(data) != 0 {
+		t.Errorf("control bytes = %q, want none", data)
+	}
+}
@@ -0,0 +1,136 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/google/renameio/v2"
+)
+
+// EnvUpdateOptions configures Manager.UpdateEnv
+type EnvUpdateOptions struct {
+	// Restart restarts the services whose environment changed, so they
+	// pick up the new values
+	Restart bool
+	// Strategy restarts the services; nil means DownUpRestart
+	Strategy RestartStrategy
+	// EnvDir is the env directory relative to each service directory;
+	// empty means "env", as written by ServiceBuilder
+	EnvDir string
+}
+
+// UpdateEnv applies changes to the env directory of every service, for
+// fleet-wide configuration rollouts. A non-nil value sets the variable, a
+// nil one removes its file so the variable is inherited again. Each key
+// is replaced atomically and other files are left untouched. Services
+// whose environment already matches are left alone; with opts.Restart the
+// others are restarted, bounded by the manager's Timeout. Services are
+// updated concurrently, up to Concurrency, and the failed ones are
+// reported in a MultiError.
+//
+// Example, raising the log level of the web tier:
+//
+//	debug := "debug"
+//	err := mgr.UpdateEnv(ctx, webServices, map[string]*string{
+//		"LOG_LEVEL":   &debug,
+//		"LEGACY_MODE": nil,
+//	}, svcmgr.EnvUpdateOptions{Restart: true})
+func (m *Manager) UpdateEnv(ctx context.Context, services []string, changes map[string]*string, opts EnvUpdateOptions) error {
+	for key := range changes {
+		if err := validEnvKey(key); err != nil {
+			return err
+		}
+	}
+	envDir := opts.EnvDir
+	if envDir == "" {
+		envDir = "env"
+	}
+	strategy := opts.Strategy
+	if strategy == nil {
+		strategy = DownUpRestart
+	}
+
+	var mu sync.Mutex
+	merr := &MultiError{}
+	m.forEach(ctx, OpRestart, services, func(ctx context.Context, svc string) error {
+		dir := filepath.Join(svc, envDir)
+		changed, err := editEnvDir(dir, changes)
+		if err != nil {
+			return &OpError{Op: OpUnknown, Path: dir, Err: err}
+		}
+		if !changed || !opts.Restart {
+			return nil
+		}
+		client, err := m.newClient(svc)
+		if err != nil {
+			return &OpError{Op: OpRestart, Path: svc, Err: err}
+		}
+		return m.withTimeout(ctx, func(ctx context.Context) error {
+			return strategy.Restart(ctx, client)
+		})
+	}, func(_ string, err error) {
+		mu.Lock()
+		merr.Add(err)
+		mu.Unlock()
+	})
+	return merr.Err()
+}
+
+// editEnvDir sets and removes the variables of changes in the env
+// directory dir, leaving other files untouched, and reports whether any
+// variable changed. Every new value is staged before any file is replaced
+// or removed, so a failure to write one leaves the directory as it was.
+func editEnvDir(dir string, changes map[string]*string) (changed bool, err error) {
+	type staged struct {
+		key  string
+		file *renameio.PendingFile
+	}
+	var pending []staged
+	defer func() {
+		for _, p := range pending {
+			_ = p.file.Cleanup()
+		}
+	}()
+
+	var remove []string
+	for _, key := range slices.Sorted(maps.Keys(changes)) {
+		value := changes[key]
+		if value == nil {
+			if fi, err := os.Lstat(filepath.Join(dir, key)); err == nil && !fi.IsDir() {
+				remove = append(remove, key)
+			}
+			continue
+		}
+		if err := os.MkdirAll(dir, DirMode); err != nil {
+			return false, fmt.Errorf("creating env directory: %w", err)
+		}
+		f, err := writeEnvFile(dir, key, *value)
+		if err != nil {
+			return false, err
+		}
+		if f != nil {
+			pending = append(pending, staged{key, f})
+		}
+	}
+
+	for _, p := range pending {
+		if err := p.file.CloseAtomicallyReplace(); err != nil {
+			return changed, fmt.Errorf("writing env file %s: %w", p.key, err)
+		}
+		changed = true
+	}
+	for _, key := range remove {
+		if err := os.Remove(filepath.Join(dir, key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return changed, fmt.Errorf("removing env file %s: %w", key, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,129 @@
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManagerUpdateEnv(t *testing.T) {
+	root := t.TempDir()
+	web := createTestService(t, root, "web", 1001, 'u')
+	api := createTestService(t, root, "api", 1002, 'u')
+	worker := createTestService(t, root, "worker", 1003, 'u')
+	if err := WriteEnvDir(filepath.Join(web, "env"), map[string]string{"LOG_LEVEL": "info", "LEGACY_MODE": "1", "PORT": "8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEnvDir(filepath.Join(api, "env"), map[string]string{"LOG_LEVEL": "debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var restarted []string
+	strategy := RestartStrategyFunc(func(_ context.Context, client ServiceClient) error {
+		mu.Lock()
+		defer mu.Unlock()
+		restarted = append(restarted, filepath.Base(client.(*ClientRunit).ServiceDir))
+		return nil
+	})
+
+	debug := "debug"
+	mgr := NewManager(WithTimeout(time.Second))
+	err := mgr.UpdateEnv(context.Background(), []string{web, api, worker}, map[string]*string{
+		"LOG_LEVEL":   &debug,
+		"LEGACY_MODE": nil,
+	}, EnvUpdateOptions{Restart: true, Strategy: strategy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		web:    {"LOG_LEVEL": "debug", "PORT": "8080"},
+		api:    {"LOG_LEVEL": "debug"},
+		worker: {"LOG_LEVEL": "debug"},
+	}
+	for dir, env := range want {
+		got, err := ReadEnvDir(filepath.Join(dir, "env"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, env) {
+			t.Errorf("%s env = %v, want %v", filepath.Base(dir), got, env)
+		}
+	}
+
+	// api already had the new environment
+	slices.Sort(restarted)
+	if want := []string{"web", "worker"}; !slices.Equal(restarted, want) {
+		t.Errorf("restarted %v, want %v", restarted, want)
+	}
+}
+
+func TestManagerUpdateEnvInvalidKey(t *testing.T) {
+	dir := t.TempDir()
+	value := "x"
+	err := NewManager().UpdateEnv(context.Background(), []string{dir}, map[string]*string{"A=B": &value}, EnvUpdateOptions{})
+	if err == nil {
+		t.Fatal("UpdateEnv() with invalid key: no error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "env")); !os.IsNotExist(err) {
+		t.Errorf("env directory created despite the invalid key: %v", err)
+	}
+}
+
+func TestEditEnvDirComparesEncodedValue(t *testing.T) {
+	dir := t.TempDir()
+	// Reads as "debug", but a service sourcing the file verbatim sees the
+	// trailing blanks and second line
+	if err := os.WriteFile(filepath.Join(dir, "LOG_LEVEL"), []byte("debug \nstale\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	debug := "debug"
+	changed, err := editEnvDir(dir, map[string]*string{"LOG_LEVEL": &debug})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("editEnvDir() changed = false, want the file rewritten")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "LOG_LEVEL")); string(data) != "debug" {
+		t.Errorf("LOG_LEVEL = %q, want %q", data, "debug")
+	}
+
+	changed, err = editEnvDir(dir, map[string]*string{"LOG_LEVEL": &debug, "UNSET": nil})
+	if err != nil || changed {
+		t.Errorf("editEnvDir() of an up-to-date dir = %v, %v, want false, nil", changed, err)
+	}
+}
+
+func TestEditEnvDirFailureLeavesDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteEnvDir(dir, map[string]string{"LEGACY_MODE": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in the way of a variable fails replacing it
+	if err := os.MkdirAll(filepath.Join(dir, "A", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	value := "x"
+	if _, err := editEnvDir(dir, map[string]*string{"A": &value, "LEGACY_MODE": nil}); err == nil {
+		t.Fatal("editEnvDir() error = nil, want the failed write")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "LEGACY_MODE")); err != nil {
+		t.Errorf("LEGACY_MODE removed despite the failed write: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("dir holds %d entries, want the staged file cleaned up", len(entries))
+	}
+}
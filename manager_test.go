@@ -2,9 +2,12 @@ package svcmgr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -146,3 +149,86 @@ func TestMultiError(t *testing.T) {
 		t.Errorf("multiple errors message = %v, want '2 errors occurred'", merr.Error())
 	}
 }
+
+func TestManagerOrdered(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var services []string
+	for i := 0; i < 6; i++ {
+		services = append(services, createTestService(t, tmpDir, fmt.Sprintf("service%d", i), 1000+i, 'u'))
+	}
+
+	var mu sync.Mutex
+	var order []string
+	running := 0
+	record := RestartStrategyFunc(func(_ context.Context, client ServiceClient) error {
+		mu.Lock()
+		running++
+		if running > 1 {
+			t.Error("operations overlapped")
+		}
+		done := len(order)
+		mu.Unlock()
+
+		// Later services finish faster, so concurrent runs would reorder
+		time.Sleep(time.Duration(6-done) * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		running--
+		order = append(order, client.(*ClientRunit).ServiceDir)
+		return nil
+	})
+
+	mgr := NewManager(WithConcurrency(4), WithOrdered())
+	if err := mgr.RestartWith(context.Background(), record, services...); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(order, services) {
+		t.Errorf("order = %v, want %v", order, services)
+	}
+
+	// A failure stops the sequence and skips the rest
+	order = nil
+	fail := RestartStrategyFunc(func(ctx context.Context, client ServiceClient) error {
+		if client.(*ClientRunit).ServiceDir == services[2] {
+			return errors.New("restart failed")
+		}
+		return record(ctx, client)
+	})
+	err := mgr.RestartWith(context.Background(), fail, services...)
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 4 {
+		t.Fatalf("RestartWith() error = %v, want the failure and 3 skipped services", err)
+	}
+	for i, err := range merr.Errors[1:] {
+		var opErr *OpError
+		if !errors.As(err, &opErr) || opErr.Path != services[3+i] || !errors.Is(err, ErrStepSkipped) {
+			t.Errorf("error %d = %v, want %s skipped", i+1, err, services[3+i])
+		}
+	}
+	if !slices.Equal(order, services[:2]) {
+		t.Errorf("order = %v, want %v", order, services[:2])
+	}
+}
+
+func TestManagerStages(t *testing.T) {
+	var calls [][]string
+	op := func(_ context.Context, services ...string) error {
+		calls = append(calls, services)
+		if slices.Contains(services, "bad") {
+			return errors.New("stage failed")
+		}
+		return nil
+	}
+
+	mgr := NewManager()
+	err := mgr.Stages(context.Background(), op, []string{"db"}, []string{"web", "bad"}, []string{"late"})
+	if err == nil {
+		t.Fatal("Stages succeeded with a failing stage")
+	}
+	want := [][]string{{"db"}, {"web", "bad"}}
+	if !slices.EqualFunc(calls, want, slices.Equal) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
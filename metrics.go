@@ -0,0 +1,125 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Instrumentation observes the operations of clients and managers, e.g. to
+// record latency histograms. Implementations must be safe for concurrent
+// use and should return quickly, as they run on the operation's goroutine.
+type Instrumentation interface {
+	ObserveOperation(ev OperationEvent)
+}
+
+// InstrumentationFunc adapts a function to the Instrumentation interface
+type InstrumentationFunc func(ev OperationEvent)
+
+// ObserveOperation implements Instrumentation
+func (f InstrumentationFunc) ObserveOperation(ev OperationEvent) { f(ev) }
+
+// OperationEvent describes a finished operation
+type OperationEvent struct {
+	// Service is the service directory of a client operation; empty for
+	// Manager operations
+	Service string
+	// Op is the operation
+	Op Operation
+	// Services is the number of services a Manager operation covered; zero
+	// for client operations
+	Services int
+	// Duration is how long the operation took, including retries
+	Duration time.Duration
+	// Retries is how often a control command was retried after its first
+	// attempt failed
+	Retries int
+	// Err is the error the operation returned
+	Err error
+	// Skipped is set when the operation was a no-op, as Up of a running
+	// service with WithSkipIfRunning; Duration then covers the check
+	Skipped bool
+	// CorrelationID is the ID attached to the operation's context with
+	// WithCorrelationID
+	CorrelationID string
+}
+
+// Error categories returned by ErrorCategory
+const (
+	ErrorCategoryTimeout         = "timeout"
+	ErrorCategoryCanceled        = "canceled"
+	ErrorCategoryNotSupervised   = "not_supervised"
+	ErrorCategoryControlNotReady = "control_not_ready"
+	ErrorCategoryPermission      = "permission"
+	ErrorCategoryDecode          = "decode"
+	ErrorCategoryUnsupported     = "unsupported"
+	ErrorCategoryDependency      = "dependency"
+	ErrorCategoryOther           = "other"
+)
+
+// ErrorCategory classifies err into a small, fixed set of categories fit
+// for metric labels, or returns "" for nil. A MultiError is classified by
+// its first error.
+func ErrorCategory(err error) string {
+	var merr *MultiError
+	if errors.As(err, &merr) && len(merr.Errors) > 0 {
+		err = merr.Errors[0]
+	}
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded), errors.Is(err, ErrTimeout):
+		return ErrorCategoryTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCategoryCanceled
+	case errors.Is(err, ErrNotSupervised), errors.Is(err, fs.ErrNotExist):
+		return ErrorCategoryNotSupervised
+	case errors.Is(err, ErrControlNotReady):
+		return ErrorCategoryControlNotReady
+	case errors.Is(err, fs.ErrPermission):
+		return ErrorCategoryPermission
+	case errors.Is(err, ErrDecode):
+		return ErrorCategoryDecode
+	case errors.Is(err, errors.ErrUnsupported):
+		return ErrorCategoryUnsupported
+	case errors.Is(err, ErrDependencyFailed):
+		return ErrorCategoryDependency
+	default:
+		return ErrorCategoryOther
+	}
+}
+
+// observeOperation reports a client operation that started at start to
+// inst, when set
+func observeOperation(ctx context.Context, inst Instrumentation, service string, op Operation, start time.Time, retries int, err error) {
+	if inst == nil {
+		return
+	}
+	id, _ := CorrelationID(ctx)
+	inst.ObserveOperation(OperationEvent{
+		Service:       service,
+		Op:            op,
+		Duration:      time.Since(start),
+		Retries:       retries,
+		Err:           err,
+		CorrelationID: id,
+	})
+}
+
+// observeSkipped reports a client operation skipped as a no-op to inst,
+// when set
+func observeSkipped(ctx context.Context, inst Instrumentation, service string, op Operation, start time.Time) {
+	if inst == nil {
+		return
+	}
+	id, _ := CorrelationID(ctx)
+	inst.ObserveOperation(OperationEvent{
+		Service:       service,
+		Op:            op,
+		Duration:      time.Since(start),
+		Skipped:       true,
+		CorrelationID: id,
+	})
+}
@@ -0,0 +1,175 @@
+package svcmgr
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DefaultPrometheusNamespace prefixes the names of PrometheusMetrics' metrics
+const DefaultPrometheusNamespace = "svcmgr"
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histogram buckets of PrometheusMetrics
+var DefaultLatencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is an Instrumentation recording operations as Prometheus
+// metrics, which ServeHTTP exposes in the text exposition format:
+//
+//	svcmgr_operation_duration_seconds{op,scope}         latency histogram
+//	svcmgr_operation_retries_total{op,scope}            control command retries
+//	svcmgr_operation_errors_total{op,scope,category}    failures by ErrorCategory
+//	svcmgr_operation_skipped_total{op,scope}            no-op operations
+//
+// Skipped operations are only counted, keeping the latency histogram to
+// operations that did something. scope is "client" for single-service
+// operations and "manager" for bulk operations. Services are not a label,
+// keeping cardinality bounded.
+//
+// Example:
+//
+//	metrics := svcmgr.NewPrometheusMetrics()
+//	client, _ := svcmgr.NewClient(dir, svcmgr.WithInstrumentation(metrics))
+//	mgr := svcmgr.NewManager(svcmgr.WithManagerInstrumentation(metrics))
+//	http.Handle("/metrics", metrics)
+type PrometheusMetrics struct {
+	// Namespace prefixes metric names
+	Namespace string
+	// Buckets are the ascending histogram bucket upper bounds in seconds;
+	// they must not change once operations were observed
+	Buckets []float64
+
+	mu        sync.Mutex
+	durations map[promSeries]*promHistogram
+	retries   map[promSeries]uint64
+	errors    map[promErrorSeries]uint64
+	skipped   map[promSeries]uint64
+}
+
+// promSeries identifies the series of an operation
+type promSeries struct {
+	op    string
+	scope string
+}
+
+// promErrorSeries identifies an error counter series
+type promErrorSeries struct {
+	promSeries
+	category string
+}
+
+// promHistogram holds per-bucket (not cumulative) counts, with the +Inf
+// bucket last
+type promHistogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusMetrics creates metrics with the default namespace and buckets
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		Namespace: DefaultPrometheusNamespace,
+		Buckets:   DefaultLatencyBuckets,
+	}
+}
+
+// ObserveOperation implements Instrumentation
+func (p *PrometheusMetrics) ObserveOperation(ev OperationEvent) {
+	series := promSeries{op: ev.Op.String(), scope: "client"}
+	if ev.Service == "" {
+		series.scope = "manager"
+	}
+	seconds := ev.Duration.Seconds()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.durations == nil {
+		p.durations = make(map[promSeries]*promHistogram)
+		p.retries = make(map[promSeries]uint64)
+		p.errors = make(map[promErrorSeries]uint64)
+		p.skipped = make(map[promSeries]uint64)
+	}
+	if ev.Skipped {
+		p.skipped[series]++
+		return
+	}
+
+	h := p.durations[series]
+	if h == nil {
+		h = &promHistogram{counts: make([]uint64, len(p.Buckets)+1)}
+		p.durations[series] = h
+	}
+	i, _ := slices.BinarySearch(p.Buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+
+	if ev.Retries > 0 {
+		p.retries[series] += uint64(ev.Retries)
+	}
+	if ev.Err != nil {
+		p.errors[promErrorSeries{series, ErrorCategory(ev.Err)}]++
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	ns := cmp.Or(p.Namespace, DefaultPrometheusNamespace)
+	var bw bytes.Buffer
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	compare := func(a, b promSeries) int {
+		return cmp.Or(cmp.Compare(a.op, b.op), cmp.Compare(a.scope, b.scope))
+	}
+
+	name := ns + "_operation_duration_seconds"
+	fmt.Fprintf(&bw, "# HELP %s Latency of svcmgr operations.\n# TYPE %s histogram\n", name, name)
+	for _, s := range slices.SortedFunc(maps.Keys(p.durations), compare) {
+		h := p.durations[s]
+		var cumulative uint64
+		for i, le := range p.Buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&bw, "%s_bucket{op=%q,scope=%q,le=%q} %d\n", name, s.op, s.scope, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&bw, "%s_bucket{op=%q,scope=%q,le=\"+Inf\"} %d\n", name, s.op, s.scope, h.count)
+		fmt.Fprintf(&bw, "%s_sum{op=%q,scope=%q} %s\n", name, s.op, s.scope, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&bw, "%s_count{op=%q,scope=%q} %d\n", name, s.op, s.scope, h.count)
+	}
+
+	name = ns + "_operation_retries_total"
+	fmt.Fprintf(&bw, "# HELP %s Control command retries.\n# TYPE %s counter\n", name, name)
+	for _, s := range slices.SortedFunc(maps.Keys(p.retries), compare) {
+		fmt.Fprintf(&bw, "%s{op=%q,scope=%q} %d\n", name, s.op, s.scope, p.retries[s])
+	}
+
+	name = ns + "_operation_errors_total"
+	fmt.Fprintf(&bw, "# HELP %s Failed svcmgr operations by error category.\n# TYPE %s counter\n", name, name)
+	errorKeys := slices.SortedFunc(maps.Keys(p.errors), func(a, b promErrorSeries) int {
+		return cmp.Or(compare(a.promSeries, b.promSeries), cmp.Compare(a.category, b.category))
+	})
+	for _, s := range errorKeys {
+		fmt.Fprintf(&bw, "%s{op=%q,scope=%q,category=%q} %d\n", name, s.op, s.scope, s.category, p.errors[s])
+	}
+
+	name = ns + "_operation_skipped_total"
+	fmt.Fprintf(&bw, "# HELP %s Operations skipped as no-ops.\n# TYPE %s counter\n", name, name)
+	for _, s := range slices.SortedFunc(maps.Keys(p.skipped), compare) {
+		fmt.Fprintf(&bw, "%s{op=%q,scope=%q} %d\n", name, s.op, s.scope, p.skipped[s])
+	}
+
+	return bw.WriteTo(w)
+}
@@ -0,0 +1,159 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{context.DeadlineExceeded, ErrorCategoryTimeout},
+		{&OpError{Op: OpUp, Err: os.ErrDeadlineExceeded}, ErrorCategoryTimeout},
+		{context.Canceled, ErrorCategoryCanceled},
+		{&OpError{Op: OpUp, Err: ErrNotSupervised}, ErrorCategoryNotSupervised},
+		{&OpError{Op: OpUp, Err: ErrControlNotReady}, ErrorCategoryControlNotReady},
+		{fmt.Errorf("open: %w", fs.ErrPermission), ErrorCategoryPermission},
+		{fmt.Errorf("%w: short file", ErrDecode), ErrorCategoryDecode},
+		{errors.ErrUnsupported, ErrorCategoryUnsupported},
+		{&DependencyError{Service: "web", Dependency: "db"}, ErrorCategoryDependency},
+		{&MultiError{Errors: []error{context.Canceled, ErrDecode}}, ErrorCategoryCanceled},
+		{errors.New("boom"), ErrorCategoryOther},
+	}
+	for _, tt := range tests {
+		if got := ErrorCategory(tt.err); got != tt.want {
+			t.Errorf("ErrorCategory(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClientInstrumentation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var events []OperationEvent
+	inst := InstrumentationFunc(func(ev OperationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+	client, err := NewClient(dir, ServiceTypeRunit,
+		WithControlRetry(3, time.Millisecond, time.Millisecond), WithInstrumentation(inst))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upErr := client.Up(context.Background())
+	if upErr == nil {
+		t.Fatal("Up without a supervisor succeeded")
+	}
+	_, statusErr := client.Status(context.Background())
+
+	if len(events) != 2 {
+		t.Fatalf("observed %d events, want 2: %+v", len(events), events)
+	}
+	up, status := events[0], events[1]
+	if up.Op != OpUp || up.Retries != 2 || !errors.Is(up.Err, upErr) || up.Service == "" || up.Duration <= 0 {
+		t.Errorf("up event = %+v, want OpUp with 2 retries and the returned error", up)
+	}
+	if status.Op != OpStatus || status.Retries != 0 || !errors.Is(status.Err, statusErr) {
+		t.Errorf("status event = %+v, want OpStatus with the returned error", status)
+	}
+}
+
+func TestManagerInstrumentation(t *testing.T) {
+	var mu sync.Mutex
+	var events []OperationEvent
+	m := NewManager(WithManagerInstrumentation(InstrumentationFunc(func(ev OperationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})))
+
+	dirs := []string{filepath.Join(t.TempDir(), "a"), filepath.Join(t.TempDir(), "b")}
+	if err := m.Down(context.Background(), dirs...); err == nil {
+		t.Fatal("Down of unsupervised services succeeded")
+	}
+	if len(events) != 1 {
+		t.Fatalf("observed %d events, want 1: %+v", len(events), events)
+	}
+	if ev := events[0]; ev.Op != OpDown || ev.Services != 2 || ev.Service != "" || ErrorCategory(ev.Err) != ErrorCategoryNotSupervised {
+		t.Errorf("event = %+v, want OpDown over 2 services failing as not supervised", ev)
+	}
+
+	// Apply is reported as a whole, and its steps through the clients
+	events = nil
+	web := filepath.Join(t.TempDir(), "web")
+	if _, err := NewMockSupervisor(web); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Apply(context.Background(), []BatchStep{{Service: web, Op: OpDown}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("observed %d events, want 2: %+v", len(events), events)
+	}
+	if ev := events[0]; ev.Op != OpDown || ev.Service != web {
+		t.Errorf("step event = %+v, want OpDown of %s", ev, web)
+	}
+	if ev := events[1]; ev.Op != OpUnknown || ev.Services != 1 || ev.Err != nil {
+		t.Errorf("batch event = %+v, want the batch over 1 service", ev)
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	p := NewPrometheusMetrics()
+	p.Buckets = []float64{0.01, 0.1}
+	p.ObserveOperation(OperationEvent{Service: "/etc/service/web", Op: OpUp, Duration: 5 * time.Millisecond})
+	p.ObserveOperation(OperationEvent{Service: "/etc/service/web", Op: OpUp, Duration: 50 * time.Millisecond, Retries: 2, Err: ErrControlNotReady})
+	p.ObserveOperation(OperationEvent{Op: OpStatus, Services: 3, Duration: time.Second, Err: context.DeadlineExceeded})
+	p.ObserveOperation(OperationEvent{Service: "/etc/service/web", Op: OpUp, Duration: time.Millisecond, Skipped: true})
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	want := `# HELP svcmgr_operation_duration_seconds Latency of svcmgr operations.
+# TYPE svcmgr_operation_duration_seconds histogram
+svcmgr_operation_duration_seconds_bucket{op="status",scope="manager",le="0.01"} 0
+svcmgr_operation_duration_seconds_bucket{op="status",scope="manager",le="0.1"} 0
+svcmgr_operation_duration_seconds_bucket{op="status",scope="manager",le="+Inf"} 1
+svcmgr_operation_duration_seconds_sum{op="status",scope="manager"} 1
+svcmgr_operation_duration_seconds_count{op="status",scope="manager"} 1
+svcmgr_operation_duration_seconds_bucket{op="up",scope="client",le="0.01"} 1
+svcmgr_operation_duration_seconds_bucket{op="up",scope="client",le="0.1"} 2
+svcmgr_operation_duration_seconds_bucket{op="up",scope="client",le="+Inf"} 2
+svcmgr_operation_duration_seconds_sum{op="up",scope="client"} 0.055
+svcmgr_operation_duration_seconds_count{op="up",scope="client"} 2
+# HELP svcmgr_operation_retries_total Control command retries.
+# TYPE svcmgr_operation_retries_total counter
+svcmgr_operation_retries_total{op="up",scope="client"} 2
+# HELP svcmgr_operation_errors_total Failed svcmgr operations by error category.
+# TYPE svcmgr_operation_errors_total counter
+svcmgr_operation_errors_total{op="status",scope="manager",category="timeout"} 1
+svcmgr_operation_errors_total{op="up",scope="client",category="control_not_ready"} 1
+# HELP svcmgr_operation_skipped_total Operations skipped as no-ops.
+# TYPE svcmgr_operation_skipped_total counter
+svcmgr_operation_skipped_total{op="up",scope="client"} 1
+`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("metrics =\n%s\nwant\n%s", got, want)
+	}
+}
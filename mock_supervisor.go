@@ -0,0 +1,160 @@
+package svcmgr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+	"github.com/google/renameio/v2"
+)
+
+// MockSupervisor maintains a fake supervise directory for a service.
+// It allows tests to run without actual supervisor processes; package
+// svcmgrtest exposes it along with helpers for downstream tests.
+type MockSupervisor struct {
+	// ServiceDir is the service directory containing the supervise directory
+	ServiceDir string
+
+	// SuperviseDir is the fake supervise directory
+	SuperviseDir string
+
+	// ControlFile is the path of the control file (a regular file)
+	ControlFile string
+
+	// StatusFile is the path of the binary status file
+	StatusFile string
+
+	// ServiceType selects the status file format that is written
+	ServiceType ServiceType
+
+	// mu serializes status writes and guards the fault injection state
+	mu sync.Mutex
+
+	// running and pid are the last values written by UpdateStatus
+	running bool
+	pid     int
+
+	// controlFault is the active control endpoint fault
+	controlFault MockControlFault
+
+	// stallReader and stallWriter hold a stalled control FIFO open
+	stallReader *os.File
+	stallWriter *os.File
+
+	// stopFlap stops a running Flap, if any
+	stopFlap func()
+}
+
+// NewMockSupervisor creates a mock runit supervise directory in serviceDir
+func NewMockSupervisor(serviceDir string) (*MockSupervisor, error) {
+	return NewMockSupervisorWithType(serviceDir, ServiceTypeRunit)
+}
+
+// NewMockSupervisorWithType creates a mock supervise directory in serviceDir
+// for a specific supervision system, with an initial "down" status
+func NewMockSupervisorWithType(serviceDir string, serviceType ServiceType) (*MockSupervisor, error) {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+	m := &MockSupervisor{
+		ServiceDir:   serviceDir,
+		SuperviseDir: superviseDir,
+		ControlFile:  filepath.Join(superviseDir, ControlFile),
+		StatusFile:   filepath.Join(superviseDir, StatusFile),
+		ServiceType:  serviceType,
+	}
+
+	if err := os.MkdirAll(m.SuperviseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating supervise dir: %w", err)
+	}
+
+	if err := m.UpdateStatus(false, 0); err != nil {
+		return nil, fmt.Errorf("creating status file: %w", err)
+	}
+
+	// Create control as a regular file (not a real FIFO, but enough for client creation)
+	if err := os.WriteFile(m.ControlFile, []byte{}, 0o644); err != nil {
+		return nil, fmt.Errorf("creating control file: %w", err)
+	}
+
+	return m, nil
+}
+
+// UpdateStatus rewrites the status file. A running service with a non-zero
+// pid is reported as up (and ready, for s6); otherwise it is down.
+func (m *MockSupervisor) UpdateStatus(running bool, pid int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writeStatus(running, pid)
+}
+
+// writeStatus writes a status record; callers must hold m.mu
+func (m *MockSupervisor) writeStatus(running bool, pid int) error {
+	m.running, m.pid = running, pid
+	// Replaced atomically, as supervisors do, so readers never see a torn record
+	return renameio.WriteFile(m.StatusFile, m.encodeStatus(running, pid, time.Now()), 0o644)
+}
+
+// encodeStatus builds a status record in the format of m.ServiceType
+func (m *MockSupervisor) encodeStatus(running bool, pid int, now time.Time) []byte {
+	label := tai64.FromTime(now)
+
+	switch m.ServiceType {
+	case ServiceTypeS6:
+		// Pre-2.11.0.0 layout, which every s6 decoder still accepts
+		data := make([]byte, S6StatusSizePre220)
+		binary.BigEndian.PutUint64(data[S6TimestampStartPre220:], label.Sec)
+		binary.BigEndian.PutUint32(data[S6TimestampStartPre220+8:], label.Nano)
+
+		var flags byte
+		if running {
+			flags |= S6FlagNormallyUp
+		}
+		if running && pid > 0 {
+			binary.BigEndian.PutUint64(data[S6ReadyStartPre220:], label.Sec)
+			binary.BigEndian.PutUint32(data[S6ReadyStartPre220+8:], label.Nano)
+			flags |= S6FlagReady
+		}
+		if pid > 0 {
+			binary.BigEndian.PutUint32(data[S6PIDStartPre220:S6PIDEndPre220], uint32(pid))
+		}
+		data[S6FlagsBytePre220] = flags
+		return data
+
+	case ServiceTypeDaemontools:
+		data := make([]byte, DaemontoolsStatusSize)
+		binary.BigEndian.PutUint64(data[DaemontoolsTAI64Start:DaemontoolsTAI64End], label.Sec)
+		binary.BigEndian.PutUint32(data[DaemontoolsNanoStart:DaemontoolsNanoEnd], label.Nano)
+		binary.LittleEndian.PutUint32(data[DaemontoolsPIDStart:DaemontoolsPIDEnd], uint32(pid))
+		data[DaemontoolsWantFlag] = 'd'
+		if running {
+			data[DaemontoolsWantFlag] = 'u'
+		}
+		return data
+
+	default:
+		data := make([]byte, StatusFileSize)
+		binary.BigEndian.PutUint64(data[RunitTAI64Start:RunitTAI64End], label.Sec)
+		binary.BigEndian.PutUint32(data[RunitNanoStart:RunitNanoEnd], label.Nano)
+		binary.LittleEndian.PutUint32(data[RunitPIDStart:RunitPIDEnd], uint32(pid))
+		data[RunitWantFlag] = 'd'
+		if running {
+			data[RunitWantFlag] = 'u'
+			if pid > 0 {
+				data[RunitRunFlag] = 1
+			}
+		}
+		return data
+	}
+}
+
+// Cleanup stops any injected faults and removes the mock supervise directory
+func (m *MockSupervisor) Cleanup() error {
+	m.StopFlap()
+	m.mu.Lock()
+	m.closeStall()
+	m.mu.Unlock()
+	return os.RemoveAll(m.SuperviseDir)
+}
@@ -0,0 +1,229 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/axondata/go-svcmgr/internal/unix"
+)
+
+// MockControlFault selects how the control endpoint of a MockSupervisor behaves
+type MockControlFault int
+
+const (
+	// MockControlOK makes the control endpoint a regular file that accepts every write
+	MockControlOK MockControlFault = iota
+	// MockControlNoReader makes the control endpoint a FIFO with no reader, so
+	// non-blocking opens fail with ENXIO as if supervise were not running
+	MockControlNoReader
+	// MockControlStalled makes the control endpoint a FIFO whose reader never
+	// drains it, so writes block until the client's write deadline expires
+	MockControlStalled
+)
+
+// String returns the name of the fault
+func (f MockControlFault) String() string {
+	switch f {
+	case MockControlOK:
+		return "ok"
+	case MockControlNoReader:
+		return "no-reader"
+	case MockControlStalled:
+		return "stalled"
+	default:
+		return fmt.Sprintf("MockControlFault(%d)", int(f))
+	}
+}
+
+// SetControlFault replaces the control endpoint according to fault.
+// MockControlOK restores the default regular file.
+func (m *MockSupervisor) SetControlFault(fault MockControlFault) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closeStall()
+	if err := os.Remove(m.ControlFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing control file: %w", err)
+	}
+
+	switch fault {
+	case MockControlOK:
+		if err := os.WriteFile(m.ControlFile, []byte{}, 0o644); err != nil {
+			return fmt.Errorf("creating control file: %w", err)
+		}
+	case MockControlNoReader:
+		if err := syscall.Mkfifo(m.ControlFile, 0o600); err != nil {
+			return fmt.Errorf("creating control fifo: %w", err)
+		}
+	case MockControlStalled:
+		if err := syscall.Mkfifo(m.ControlFile, 0o600); err != nil {
+			return fmt.Errorf("creating control fifo: %w", err)
+		}
+		if err := m.stallControl(); err != nil {
+			m.closeStall()
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown control fault: %v", fault)
+	}
+
+	m.controlFault = fault
+	return nil
+}
+
+// ControlFault returns the active control endpoint fault
+func (m *MockSupervisor) ControlFault() MockControlFault {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.controlFault
+}
+
+// stallControl opens the control FIFO for reading and fills its buffer.
+// Callers must hold m.mu.
+func (m *MockSupervisor) stallControl() error {
+	reader, err := os.OpenFile(m.ControlFile, os.O_RDONLY|unix.ONonblock, 0)
+	if err != nil {
+		return fmt.Errorf("opening control fifo for reading: %w", err)
+	}
+	m.stallReader = reader
+
+	writer, err := os.OpenFile(m.ControlFile, os.O_WRONLY|unix.ONonblock, 0)
+	if err != nil {
+		return fmt.Errorf("opening control fifo for writing: %w", err)
+	}
+	m.stallWriter = writer
+
+	// Write through the raw descriptor: os.File would wait on the poller
+	// instead of returning EAGAIN once the buffer is full
+	raw, err := writer.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("filling control fifo: %w", err)
+	}
+	chunk := make([]byte, 4096)
+	var writeErr error
+	ctrlErr := raw.Write(func(fd uintptr) bool {
+		for {
+			if _, writeErr = syscall.Write(int(fd), chunk); writeErr != nil {
+				return true
+			}
+		}
+	})
+	if ctrlErr != nil {
+		return fmt.Errorf("filling control fifo: %w", ctrlErr)
+	}
+	if !errors.Is(writeErr, syscall.EAGAIN) {
+		return fmt.Errorf("filling control fifo: %w", writeErr)
+	}
+	return nil
+}
+
+// closeStall releases a stalled control FIFO. Callers must hold m.mu.
+func (m *MockSupervisor) closeStall() {
+	if m.stallWriter != nil {
+		_ = m.stallWriter.Close()
+		m.stallWriter = nil
+	}
+	if m.stallReader != nil {
+		_ = m.stallReader.Close()
+		m.stallReader = nil
+	}
+}
+
+// WriteRawStatus writes data verbatim as the status file
+func (m *MockSupervisor) WriteRawStatus(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return os.WriteFile(m.StatusFile, data, 0o644)
+}
+
+// TruncateStatus rewrites the current status record cut to its first n bytes
+func (m *MockSupervisor) TruncateStatus(n int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data := m.encodeStatus(m.running, m.pid, time.Now())
+	if n < 0 || n > len(data) {
+		return fmt.Errorf("truncate length %d out of range [0, %d]", n, len(data))
+	}
+	return os.WriteFile(m.StatusFile, data[:n], 0o644)
+}
+
+// CorruptStatus overwrites the status file with a correctly sized record
+// whose every byte is 0xff, which strict decoding rejects
+func (m *MockSupervisor) CorruptStatus() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data := m.encodeStatus(m.running, m.pid, time.Now())
+	for i := range data {
+		data[i] = 0xff
+	}
+	return os.WriteFile(m.StatusFile, data, 0o644)
+}
+
+// UpdateStatusAfter applies UpdateStatus(running, pid) once delay has elapsed,
+// simulating a supervisor that is slow to change state. The returned function
+// cancels the transition and reports whether it had not yet happened.
+func (m *MockSupervisor) UpdateStatusAfter(delay time.Duration, running bool, pid int) (cancel func() bool) {
+	timer := time.AfterFunc(delay, func() {
+		_ = m.UpdateStatus(running, pid)
+	})
+	return timer.Stop
+}
+
+// Flap makes the service cycle through pids every interval, as if it kept
+// crashing and being restarted. A pid of 0 is written as down. Flapping
+// continues until StopFlap or Cleanup is called; a new Flap replaces the
+// previous one.
+func (m *MockSupervisor) Flap(interval time.Duration, pids ...int) error {
+	if interval <= 0 {
+		return fmt.Errorf("flap interval must be positive, got %v", interval)
+	}
+	if len(pids) == 0 {
+		return errors.New("flap requires at least one pid")
+	}
+
+	m.StopFlap()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			pid := pids[i%len(pids)]
+			_ = m.UpdateStatus(pid > 0, pid)
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	m.mu.Lock()
+	m.stopFlap = func() {
+		close(stop)
+		<-done
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// StopFlap stops a running Flap and waits for it to exit
+func (m *MockSupervisor) StopFlap() {
+	m.mu.Lock()
+	stop := m.stopFlap
+	m.stopFlap = nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
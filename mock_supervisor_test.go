@@ -0,0 +1,42 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CreateMockService creates a service with a mock supervisor for testing
+func CreateMockService(serviceName string, config *ServiceConfig) (serviceDir string, mock *MockSupervisor, cleanup func(), err error) {
+	serviceDir = fmt.Sprintf("/tmp/test-services/%s", serviceName)
+
+	// Build the service
+	builder := NewServiceBuilderWithConfig(serviceName, filepath.Dir(serviceDir), config)
+	builder.WithCmd([]string{"/bin/sh", "-c", "while true; do echo 'Mock service'; sleep 2; done"})
+
+	if err := builder.Build(); err != nil {
+		return "", nil, nil, fmt.Errorf("failed to build service: %w", err)
+	}
+
+	// Create mock supervisor with the correct type
+	serviceType := ServiceTypeRunit
+	if config != nil {
+		serviceType = config.Type
+	}
+	mock, err = NewMockSupervisorWithType(serviceDir, serviceType)
+	if err != nil {
+		_ = os.RemoveAll(serviceDir)
+		return "", nil, nil, fmt.Errorf("failed to create mock supervisor: %w", err)
+	}
+
+	cleanup = func() {
+		if mock != nil {
+			_ = mock.Cleanup()
+		}
+		_ = os.RemoveAll(serviceDir)
+	}
+
+	return serviceDir, mock, cleanup, nil
+}
@@ -0,0 +1,84 @@
+package svcmgr
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// DefaultNATSPrefix is the default first token of published subjects
+const DefaultNATSPrefix = "svcmgr"
+
+// NATSPublisher publishes a message to a subject. *nats.Conn from
+// github.com/nats-io/nats.go satisfies it, so this package does not depend
+// on a NATS client.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSAdapter publishes WatchEvents to NATS subjects of the form
+// <prefix>.<host>.<service>.state, so supervision events from a fleet can
+// be consumed centrally, e.g. by subscribing to "svcmgr.*.*.state".
+// Payloads are WatchEvent JSON (see StatusSchemaVersion).
+type NATSAdapter struct {
+	// Conn publishes the events
+	Conn NATSPublisher
+	// Prefix is the first subject token
+	Prefix string
+	// Host is the host subject token; empty uses os.Hostname
+	Host string
+}
+
+// NewNATSAdapter creates an adapter publishing through conn under
+// DefaultNATSPrefix for the local host
+func NewNATSAdapter(conn NATSPublisher) *NATSAdapter {
+	return &NATSAdapter{Conn: conn, Prefix: DefaultNATSPrefix}
+}
+
+// Subject returns the subject events for service are published to
+func (a *NATSAdapter) Subject(service string) string {
+	host := a.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	prefix := a.Prefix
+	if prefix == "" {
+		prefix = DefaultNATSPrefix
+	}
+	return prefix + "." + natsToken(host) + "." + natsToken(service) + ".state"
+}
+
+// Publish publishes ev for service
+func (a *NATSAdapter) Publish(service string, ev WatchEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return a.Conn.Publish(a.Subject(service), data)
+}
+
+// Watch watches every client, keyed by service name, and publishes each
+// event until ctx is done, returning ctx.Err(), or ErrWatchClosed if every
+// watch ended first. Publish errors are dropped; NATS clients buffer and
+// reconnect on their own.
+func (a *NATSAdapter) Watch(ctx context.Context, clients map[string]ServiceClient) error {
+	return watchAll(ctx, clients, func(name string, _ Status, ev WatchEvent) {
+		_ = a.Publish(name, ev)
+	})
+}
+
+// natsToken makes s a single subject token by replacing separators,
+// wildcards and whitespace; an empty token becomes "_"
+func natsToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
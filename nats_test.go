@@ -0,0 +1,45 @@
+package svcmgr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type fakeNATS struct {
+	subjects []string
+	data     [][]byte
+}
+
+func (f *fakeNATS) Publish(subject string, data []byte) error {
+	f.subjects = append(f.subjects, subject)
+	f.data = append(f.data, data)
+	return nil
+}
+
+func TestNATSAdapter(t *testing.T) {
+	conn := &fakeNATS{}
+	a := NewNATSAdapter(conn)
+	a.Host = "web-01.example.com"
+
+	if err := a.Publish("api*v2", WatchEvent{Status: Status{State: StateRunning, PID: 42}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Publish("api*v2", WatchEvent{Err: errors.New("boom")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "svcmgr.web-01_example_com.api_v2.state"; conn.subjects[0] != want {
+		t.Errorf("subject = %q, want %q", conn.subjects[0], want)
+	}
+	var ev WatchEvent
+	if err := json.Unmarshal(conn.data[0], &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Status.State != StateRunning || ev.Status.PID != 42 {
+		t.Errorf("event = %+v", ev)
+	}
+	if err := json.Unmarshal(conn.data[1], &ev); err != nil || ev.Err == nil {
+		t.Errorf("error event = %+v, %v", ev, err)
+	}
+}
@@ -0,0 +1,98 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadStatusOpenRC(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	now := started.Add(90 * time.Second)
+
+	tests := []struct {
+		name       string
+		stateDir   string
+		options    map[string]string
+		wantState  State
+		wantPID    int
+		wantUptime time.Duration
+		wantErr    error
+	}{
+		{
+			name:       "running",
+			stateDir:   "started",
+			options:    map[string]string{"child_pid": "4242\n", "start_time": started.Format(openRCTimeLayout)},
+			wantState:  StateRunning,
+			wantPID:    4242,
+			wantUptime: 90 * time.Second,
+		},
+		{
+			name:      "running without start time",
+			stateDir:  "started",
+			options:   map[string]string{"child_pid": "4242"},
+			wantState: StateRunning,
+			wantPID:   4242,
+		},
+		{
+			name:      "stopped",
+			wantState: StateDown,
+		},
+		{
+			name:      "failed",
+			stateDir:  "failed",
+			wantState: StateCrashed,
+		},
+		{
+			name:     "not supervise-daemon",
+			stateDir: "started",
+			wantErr:  ErrNotSupervised,
+		},
+		{
+			name:     "corrupt pid",
+			stateDir: "started",
+			options:  map[string]string{"child_pid": "abc"},
+			wantErr:  ErrDecode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runDir := t.TempDir()
+			if tt.stateDir != "" {
+				if err := os.MkdirAll(filepath.Join(runDir, tt.stateDir), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink("/etc/init.d/sshd", filepath.Join(runDir, tt.stateDir, "sshd")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			options := filepath.Join(runDir, "options", "sshd")
+			if err := os.MkdirAll(options, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tt.options {
+				if err := os.WriteFile(filepath.Join(options, key), []byte(value), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			st, err := ReadStatusOpenRC(runDir, "sshd", WithDecodeClock(FixedClock(now)))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.State != tt.wantState || st.PID != tt.wantPID || st.Uptime != tt.wantUptime {
+				t.Errorf("status = %v pid %d uptime %v, want %v pid %d uptime %v",
+					st.State, st.PID, st.Uptime, tt.wantState, tt.wantPID, tt.wantUptime)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Orchestrator defaults
+const (
+	// DefaultOrchestratorTimeout bounds each attempt to start a step and pass its gates
+	DefaultOrchestratorTimeout = 30 * time.Second
+	// DefaultOrchestratorConcurrency is the default number of steps started at once
+	DefaultOrchestratorConcurrency = 10
+)
+
+// ReadinessGate reports whether a started service is ready. An error fails
+// the attempt immediately instead of polling again.
+type ReadinessGate func(ctx context.Context, client ServiceClient) (bool, error)
+
+// StateGate is ready once the service is running and, where the backend
+// reports through CapabilityReporter that it supports readiness
+// notification, reports ready. It is the default gate.
+func StateGate() ReadinessGate {
+	return func(ctx context.Context, client ServiceClient) (bool, error) {
+		st, err := client.Status(ctx)
+		if err != nil {
+			return false, nil
+		}
+		readiness := false
+		if cr, ok := client.(CapabilityReporter); ok {
+			readiness = cr.Capabilities().Readiness
+		}
+		return st.State == StateRunning && (st.Ready || !readiness), nil
+	}
+}
+
+// CommandGate is ready once the check command exits 0
+func CommandGate(name string, args ...string) ReadinessGate {
+	return func(ctx context.Context, _ ServiceClient) (bool, error) {
+		return exec.CommandContext(ctx, name, args...).Run() == nil, nil
+	}
+}
+
+// TCPGate is ready once a TCP connection to addr succeeds
+func TCPGate(addr string) ReadinessGate {
+	return func(ctx context.Context, _ ServiceClient) (bool, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return false, nil
+		}
+		_ = conn.Close()
+		return true, nil
+	}
+}
+
+// HTTPGate is ready once a GET of url returns a 2xx status
+func HTTPGate(url string) ReadinessGate {
+	return func(ctx context.Context, _ ServiceClient) (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return false, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false, nil
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode >= 200 && resp.StatusCode <= 299, nil
+	}
+}
+
+// OrchestratorStep is one service in a boot plan
+type OrchestratorStep struct {
+	// Name identifies the step in After lists and the report
+	Name string
+	// Client controls the service; any backend works
+	Client ServiceClient
+	// After lists steps that must be ready before this one starts
+	After []string
+	// Gates must all pass before the step is ready; empty means StateGate
+	Gates []ReadinessGate
+	// Timeout bounds each attempt; zero uses the orchestrator's Timeout
+	Timeout time.Duration
+	// Retries is how many times the service is restarted after an attempt
+	// times out
+	Retries int
+	// Optional steps do not block the steps after them when they fail
+	Optional bool
+}
+
+// StepResult reports how one step of a plan went
+type StepResult struct {
+	// Name is the step name
+	Name string
+	// Attempts is the number of start attempts made
+	Attempts int
+	// Skipped reports that the step was not started because a step it
+	// comes after failed
+	Skipped bool
+	// Duration is the time from the first start attempt until ready or failure
+	Duration time.Duration
+	// Err is why the step failed, nil when it became ready
+	Err error
+}
+
+// OrchestratorReport is the outcome of a plan, with results in plan order
+type OrchestratorReport struct {
+	// Results holds one entry per step
+	Results []StepResult
+	// Duration is the time the whole plan took
+	Duration time.Duration
+}
+
+// Failed returns the names of the steps that failed or were skipped
+func (r *OrchestratorReport) Failed() []string {
+	var names []string
+	for _, res := range r.Results {
+		if res.Err != nil {
+			names = append(names, res.Name)
+		}
+	}
+	return names
+}
+
+// String formats the report as one line per step
+func (r *OrchestratorReport) String() string {
+	var b strings.Builder
+	for _, res := range r.Results {
+		switch {
+		case res.Skipped:
+			fmt.Fprintf(&b, "%s: skipped (%v)\n", res.Name, res.Err)
+		case res.Err != nil:
+			fmt.Fprintf(&b, "%s: failed after %d attempt(s) in %v: %v\n", res.Name, res.Attempts, res.Duration, res.Err)
+		default:
+			fmt.Fprintf(&b, "%s: ready in %v\n", res.Name, res.Duration)
+		}
+	}
+	fmt.Fprintf(&b, "total %v", r.Duration)
+	return b.String()
+}
+
+// Orchestrator runs a boot plan: it starts services once the steps they
+// come after are ready, waits for each through its readiness gates, retries
+// attempts that time out, and reports how every step went — a lightweight
+// s6-rc that works with any backend.
+type Orchestrator struct {
+	// Steps is the plan
+	Steps []OrchestratorStep
+	// Concurrency is the maximum number of steps starting at once
+	Concurrency int
+	// Timeout bounds each attempt of steps without their own Timeout
+	Timeout time.Duration
+	// PollInterval is how often readiness gates are checked
+	PollInterval time.Duration
+}
+
+// NewOrchestrator creates an orchestrator for steps with default settings
+func NewOrchestrator(steps ...OrchestratorStep) *Orchestrator {
+	return &Orchestrator{
+		Steps:        steps,
+		Concurrency:  DefaultOrchestratorConcurrency,
+		Timeout:      DefaultOrchestratorTimeout,
+		PollInterval: DefaultReadyPollInterval,
+	}
+}
+
+// Run executes the plan. The report is returned whenever the plan was
+// valid; the error collects the failed steps into a MultiError, with
+// skipped steps reported as DependencyError. Unknown After references and
+// cycles are rejected before anything is started.
+func (o *Orchestrator) Run(ctx context.Context) (*OrchestratorReport, error) {
+	graph, err := o.graph()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	results := make([]StepResult, len(o.Steps))
+	steps := make(map[string]int, len(o.Steps))
+	for i, step := range o.Steps {
+		results[i].Name = step.Name
+		steps[step.Name] = i
+	}
+
+	errs := graph.run(ctx, o.Concurrency, func(ctx context.Context, name string) error {
+		i := steps[name]
+		o.runStep(ctx, &o.Steps[i], &results[i])
+		return results[i].Err
+	})
+
+	report := &OrchestratorReport{Results: results, Duration: time.Since(start)}
+	merr := &MultiError{}
+	for i := range results {
+		res := &results[i]
+		res.Err = errs[res.Name]
+		var depErr *DependencyError
+		res.Skipped = res.Attempts == 0 && errors.As(res.Err, &depErr)
+		if res.Err != nil {
+			merr.Add(fmt.Errorf("%s: %w", res.Name, res.Err))
+		}
+	}
+	return report, merr.Err()
+}
+
+// runStep starts the step's service and waits for its gates, retrying with
+// a restart when an attempt times out
+func (o *Orchestrator) runStep(ctx context.Context, step *OrchestratorStep, res *StepResult) {
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = o.Timeout
+	}
+	if timeout <= 0 {
+		timeout = DefaultOrchestratorTimeout
+	}
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultReadyPollInterval
+	}
+	gates := step.Gates
+	if len(gates) == 0 {
+		gates = []ReadinessGate{StateGate()}
+	}
+
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	for res.Attempts = 1; ; res.Attempts++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := o.attempt(attemptCtx, step.Client, gates, interval, res.Attempts > 1)
+		cancel()
+		if err == nil {
+			res.Err = nil
+			return
+		}
+		res.Err = err
+		if ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) || res.Attempts > step.Retries {
+			return
+		}
+	}
+}
+
+// attempt starts (or on retries restarts) the service and polls the gates
+func (o *Orchestrator) attempt(ctx context.Context, client ServiceClient, gates []ReadinessGate, interval time.Duration, retry bool) error {
+	start := client.Up
+	if retry {
+		start = client.Restart
+	}
+	if err := start(ctx); err != nil {
+		return err
+	}
+
+	return waitGates(ctx, client, gates, interval)
+}
+
+// waitGates polls the gates in turn every interval until all passed
+func waitGates(ctx context.Context, client ServiceClient, gates []ReadinessGate, interval time.Duration) error {
+	pending := gates
+	return pollUntil(ctx, interval, func() (bool, error) {
+		for len(pending) > 0 {
+			ok, err := pending[0](ctx, client)
+			if err != nil || !ok {
+				return false, err
+			}
+			pending = pending[1:]
+		}
+		return true, nil
+	})
+}
+
+// graph checks step names and After references and orders the steps,
+// rejecting cycles. A step comes after an Optional one as an After
+// relation, so its failure is ignored, and after any other as Requires.
+func (o *Orchestrator) graph() (*unitGraph, error) {
+	optional := make(map[string]bool, len(o.Steps))
+	for i, step := range o.Steps {
+		if step.Name == "" {
+			return nil, fmt.Errorf("orchestrator: step %d has no name", i)
+		}
+		if step.Client == nil {
+			return nil, fmt.Errorf("orchestrator: step %s has no client", step.Name)
+		}
+		if _, dup := optional[step.Name]; dup {
+			return nil, fmt.Errorf("orchestrator: step %s declared more than once", step.Name)
+		}
+		optional[step.Name] = step.Optional
+	}
+
+	units := make([]ServiceUnit, 0, len(o.Steps))
+	for _, step := range o.Steps {
+		u := ServiceUnit{Dir: step.Name}
+		for _, dep := range step.After {
+			opt, ok := optional[dep]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("orchestrator: step %s comes after unknown step %s", step.Name, dep)
+			case opt:
+				u.After = append(u.After, dep)
+			default:
+				u.Requires = append(u.Requires, dep)
+			}
+		}
+		units = append(units, u)
+	}
+	return newUnitGraph(units, func(name string) (string, error) { return name, nil })
+}
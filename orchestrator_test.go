@@ -0,0 +1,104 @@
+package svcmgr_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestOrchestrator(t *testing.T) {
+	db := svcmgrtest.NewFakeClient()
+	cache := svcmgrtest.NewFakeClient()
+	api := svcmgrtest.NewFakeClient()
+	worker := svcmgrtest.NewFakeClient()
+
+	// The cache never comes up on its own; the first restart fixes it
+	cache.OnOp(svcmgr.OpUp, nil)
+
+	var started []string
+	record := func(name string) svcmgr.ReadinessGate {
+		return func(context.Context, svcmgr.ServiceClient) (bool, error) {
+			if !slices.Contains(started, name) {
+				started = append(started, name)
+			}
+			return true, nil
+		}
+	}
+	failing := errors.New("migration failed")
+
+	o := svcmgr.NewOrchestrator(
+		svcmgr.OrchestratorStep{Name: "db", Client: db, Gates: []svcmgr.ReadinessGate{svcmgr.StateGate(), record("db")}},
+		svcmgr.OrchestratorStep{Name: "cache", Client: cache, Timeout: 50 * time.Millisecond, Retries: 1},
+		svcmgr.OrchestratorStep{Name: "api", Client: api, After: []string{"db", "cache"}, Gates: []svcmgr.ReadinessGate{record("api")}},
+		svcmgr.OrchestratorStep{
+			Name:   "migrate",
+			Client: worker,
+			After:  []string{"db"},
+			Gates: []svcmgr.ReadinessGate{func(context.Context, svcmgr.ServiceClient) (bool, error) {
+				return false, failing
+			}},
+		},
+		svcmgr.OrchestratorStep{Name: "jobs", Client: svcmgrtest.NewFakeClient(), After: []string{"migrate"}},
+	)
+	o.Concurrency = 1
+	o.PollInterval = time.Millisecond
+
+	report, err := o.Run(context.Background())
+	if report == nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(err, failing) || !errors.Is(err, svcmgr.ErrDependencyFailed) {
+		t.Errorf("Run error = %v, want the migration failure and a skipped dependent", err)
+	}
+	if got := report.Failed(); !slices.Equal(got, []string{"migrate", "jobs"}) {
+		t.Errorf("Failed() = %v\n%s", got, report)
+	}
+	if res := report.Results[1]; res.Attempts != 2 || res.Err != nil {
+		t.Errorf("cache result = %+v, want ready on the second attempt", res)
+	}
+	if !report.Results[4].Skipped {
+		t.Errorf("jobs not skipped: %+v", report.Results[4])
+	}
+	if !slices.Equal(started, []string{"db", "api"}) {
+		t.Errorf("ready order = %v", started)
+	}
+	if ops := cache.Ops(); !slices.Equal(ops, []svcmgr.Operation{svcmgr.OpUp, svcmgr.OpRestart}) {
+		t.Errorf("cache ops = %v", ops)
+	}
+}
+
+func TestOrchestratorRejectsCycles(t *testing.T) {
+	a, b := svcmgrtest.NewFakeClient(), svcmgrtest.NewFakeClient()
+	o := svcmgr.NewOrchestrator(
+		svcmgr.OrchestratorStep{Name: "a", Client: a, After: []string{"b"}},
+		svcmgr.OrchestratorStep{Name: "b", Client: b, After: []string{"a"}},
+	)
+	if _, err := o.Run(context.Background()); err == nil {
+		t.Fatal("Run accepted a cycle")
+	}
+	if len(a.Ops())+len(b.Ops()) != 0 {
+		t.Error("services were started despite the cycle")
+	}
+}
+
+func TestStateGate(t *testing.T) {
+	ctx := context.Background()
+	running := svcmgr.Status{State: svcmgr.StateRunning, PID: 42, Flags: svcmgr.Flags{WantUp: true}}
+	notify := svcmgrtest.NewFakeClient().WithCapabilities(svcmgr.ConfigS6().Capabilities())
+	notify.SetStatus(running)
+
+	gate := svcmgr.StateGate()
+	if ready, _ := gate(ctx, notify); ready {
+		t.Error("StateGate() ready before a backend with readiness notification reported ready")
+	}
+	// A client without CapabilityReporter is ready once running
+	plain := struct{ svcmgr.ServiceClient }{notify}
+	if ready, _ := gate(ctx, plain); !ready {
+		t.Error("StateGate() not ready for a running service of a backend without capabilities")
+	}
+}
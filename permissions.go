@@ -0,0 +1,208 @@
+//go:build linux || darwin
+
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+)
+
+// Access bits as used in permission checks
+const (
+	accessRead   = 4
+	accessWrite  = 2
+	accessSearch = 1
+)
+
+// PermissionError explains why the current process cannot access a
+// supervise file, and what would grant access
+type PermissionError struct {
+	// Op is the operation that would fail ("control" or "read status")
+	Op string
+	// Path is the file or directory lacking permission
+	Path string
+	// Need is the missing access ("read", "write" or "search")
+	Need string
+	// Owner and Group own Path, as names when they can be resolved
+	Owner string
+	Group string
+	// Mode is Path's permission bits
+	Mode fs.FileMode
+	// Hint suggests how to gain access, e.g. "join group runit"
+	Hint string
+}
+
+// Error returns a formatted error message
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("cannot %s: need %s access to %s (owner %s:%s, mode %04o); %s",
+		e.Op, e.Need, e.Path, e.Owner, e.Group, e.Mode.Perm(), e.Hint)
+}
+
+// Unwrap returns fs.ErrPermission
+func (e *PermissionError) Unwrap() error {
+	return fs.ErrPermission
+}
+
+// CanControl reports whether the current process may send control commands
+// to the service, returning a *PermissionError describing what is missing
+func (rc *ClientRunit) CanControl() error {
+	return canControl(rc.ServiceDir)
+}
+
+// CanReadStatus reports whether the current process may read the service's
+// status, returning a *PermissionError describing what is missing
+func (rc *ClientRunit) CanReadStatus() error {
+	return canReadStatus(rc.ServiceDir)
+}
+
+// CanControl reports whether the current process may send control commands
+// to the service, returning a *PermissionError describing what is missing
+func (cd *ClientDaemontools) CanControl() error {
+	return canControl(cd.ServiceDir)
+}
+
+// CanReadStatus reports whether the current process may read the service's
+// status, returning a *PermissionError describing what is missing
+func (cd *ClientDaemontools) CanReadStatus() error {
+	return canReadStatus(cd.ServiceDir)
+}
+
+// CanControl reports whether the current process may send control commands
+// to the service, returning a *PermissionError describing what is missing
+func (cs *ClientS6) CanControl() error {
+	return canControl(cs.ServiceDir)
+}
+
+// CanReadStatus reports whether the current process may read the service's
+// status, returning a *PermissionError describing what is missing
+func (cs *ClientS6) CanReadStatus() error {
+	return canReadStatus(cs.ServiceDir)
+}
+
+// canControl checks access to the supervise directory and its control file
+func canControl(serviceDir string) error {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+	if err := checkAccess("control", superviseDir, accessSearch); err != nil {
+		return err
+	}
+	return checkAccess("control", filepath.Join(superviseDir, ControlFile), accessWrite)
+}
+
+// canReadStatus checks access to the supervise directory and its status file
+func canReadStatus(serviceDir string) error {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+	if err := checkAccess("read status", superviseDir, accessSearch); err != nil {
+		return err
+	}
+	return checkAccess("read status", filepath.Join(superviseDir, StatusFile), accessRead)
+}
+
+// credentials are the effective user and groups permissions are checked for
+type credentials struct {
+	uid    int
+	groups []int
+}
+
+// currentCredentials returns the process's effective user and groups
+func currentCredentials() credentials {
+	groups, _ := os.Getgroups()
+	return credentials{uid: os.Geteuid(), groups: append(groups, os.Getegid())}
+}
+
+// checkAccess checks path for the access bits in want on behalf of the
+// current process
+func checkAccess(op, path string, want fs.FileMode) error {
+	return checkAccessAs(currentCredentials(), op, path, want)
+}
+
+// checkAccessAs checks the permission bits of path like the kernel does:
+// only the owner bits apply to the owner, only the group bits to group
+// members, and root may do anything
+func checkAccessAs(creds credentials, op, path string, want fs.FileMode) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot %s: %w: %s", op, ErrNotSupervised, path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot %s: %w", op, err)
+	}
+	if creds.uid == 0 {
+		return nil
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	perm := info.Mode().Perm()
+	uid, gid := int(st.Uid), int(st.Gid)
+	member := slices.Contains(creds.groups, gid)
+	ownerOK := perm>>6&want == want
+	groupOK := perm>>3&want == want
+
+	var allowed bool
+	switch {
+	case uid == creds.uid:
+		allowed = ownerOK
+	case member:
+		allowed = groupOK
+	default:
+		allowed = perm&want == want
+	}
+	if allowed {
+		return nil
+	}
+
+	e := &PermissionError{
+		Op:    op,
+		Path:  path,
+		Need:  accessName(want),
+		Owner: userName(uid),
+		Group: groupName(gid),
+		Mode:  perm,
+	}
+	switch {
+	case groupOK && !member && uid != creds.uid:
+		e.Hint = "join group " + e.Group
+	case ownerOK && uid != 0:
+		e.Hint = "run as user " + e.Owner + " or root"
+	default:
+		e.Hint = "run as root"
+	}
+	return e
+}
+
+// accessName names the access bits in want
+func accessName(want fs.FileMode) string {
+	switch want {
+	case accessRead:
+		return "read"
+	case accessWrite:
+		return "write"
+	default:
+		return "search"
+	}
+}
+
+// userName resolves uid to a user name, falling back to the number
+func userName(uid int) string {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(uid)
+}
+
+// groupName resolves gid to a group name, falling back to the number
+func groupName(gid int) string {
+	if g, err := user.LookupGroupId(strconv.Itoa(gid)); err == nil {
+		return g.Name
+	}
+	return strconv.Itoa(gid)
+}
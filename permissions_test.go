@@ -0,0 +1,88 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckAccess(t *testing.T) {
+	owner, group := os.Geteuid(), os.Getegid()
+	stranger := credentials{uid: owner + 1000}
+	member := credentials{uid: owner + 1000, groups: []int{group}}
+
+	tests := []struct {
+		name     string
+		mode     fs.FileMode
+		creds    credentials
+		want     fs.FileMode
+		wantHint string
+	}{
+		{name: "owner", mode: 0o600, creds: credentials{uid: owner}, want: accessWrite},
+		{name: "group member", mode: 0o660, creds: member, want: accessWrite},
+		{name: "other", mode: 0o644, creds: stranger, want: accessRead},
+		{name: "not in group", mode: 0o660, creds: stranger, want: accessWrite, wantHint: "join group " + groupName(group)},
+		{name: "owner only", mode: 0o600, creds: member, want: accessRead, wantHint: "run as"},
+		{name: "owner bits apply to owner", mode: 0o066, creds: credentials{uid: owner, groups: []int{group}}, want: accessWrite, wantHint: "run as"},
+		{name: "root", mode: 0o000, creds: credentials{uid: 0}, want: accessWrite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.creds.uid == 0 && tt.name != "root" {
+				t.Skip("files are owned by root, which bypasses permission checks")
+			}
+			path := filepath.Join(t.TempDir(), "control")
+			if err := os.WriteFile(path, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			err := checkAccessAs(tt.creds, "control", path, tt.want)
+			if tt.wantHint == "" {
+				if err != nil {
+					t.Errorf("checkAccessAs() = %v, want access", err)
+				}
+				return
+			}
+			var permErr *PermissionError
+			if !errors.As(err, &permErr) || !errors.Is(err, fs.ErrPermission) {
+				t.Fatalf("checkAccessAs() = %v, want *PermissionError", err)
+			}
+			if !strings.HasPrefix(permErr.Hint, tt.wantHint) || permErr.Path != path {
+				t.Errorf("PermissionError = %+v, want hint %q", permErr, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestCanControl(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewMockSupervisor(dir); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientRunit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CanControl(); err != nil {
+		t.Errorf("CanControl() = %v", err)
+	}
+	if err := client.CanReadStatus(); err != nil {
+		t.Errorf("CanReadStatus() = %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, SuperviseDir, ControlFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CanControl(); !errors.Is(err, ErrNotSupervised) {
+		t.Errorf("CanControl() without control file = %v, want ErrNotSupervised", err)
+	}
+}
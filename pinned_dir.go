@@ -0,0 +1,124 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/axondata/go-svcmgr/internal/unix"
+)
+
+// PinnedDir is a service directory resolved once and held open. Files in
+// its supervise directory (status, control, ok) are opened relative to the
+// held descriptor with symlinks refused (openat2 RESOLVE_NO_SYMLINKS), so
+// swapping a symlink under the service's path cannot redirect control
+// writes or status reads to another service.
+//
+// The supervise directory is resolved together with the service directory,
+// so a supervise symlink into /run, as some distributions ship, is followed
+// once, at pinning. Pinning is supported on Linux only.
+//
+// Example:
+//
+//	pin, err := svcmgr.PinServiceDir("/etc/service/web")
+//	if err != nil {
+//		return err
+//	}
+//	defer pin.Close()
+//	client, err := svcmgr.NewClient(pin.Path(), svcmgr.WithPinnedDir(pin))
+type PinnedDir struct {
+	path      string
+	dir       *os.File
+	supervise *os.File
+}
+
+// PinServiceDir opens serviceDir and its supervise directory and holds
+// them until Close
+func PinServiceDir(serviceDir string) (*PinnedDir, error) {
+	abs, err := filepath.Abs(serviceDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving service dir: %w", err)
+	}
+	if !pinSupported {
+		return nil, &OpError{Op: OpUnknown, Path: abs, Err: errors.ErrUnsupported}
+	}
+
+	dir, err := os.Open(abs)
+	if err != nil {
+		return nil, &OpError{Op: OpUnknown, Path: abs, Err: err}
+	}
+	if info, err := dir.Stat(); err != nil || !info.IsDir() {
+		_ = dir.Close()
+		if err == nil {
+			err = syscall.ENOTDIR
+		}
+		return nil, &OpError{Op: OpUnknown, Path: abs, Err: err}
+	}
+
+	supervise, err := openAt(dir, SuperviseDir, os.O_RDONLY|syscall.O_DIRECTORY, true)
+	if err != nil {
+		_ = dir.Close()
+		if errors.Is(err, os.ErrNotExist) {
+			err = ErrNotSupervised
+		}
+		return nil, &OpError{Op: OpUnknown, Path: filepath.Join(abs, SuperviseDir), Err: err}
+	}
+	return &PinnedDir{path: abs, dir: dir, supervise: supervise}, nil
+}
+
+// Path returns the absolute path the directory was pinned at
+func (p *PinnedDir) Path() string {
+	return p.path
+}
+
+// Ok reports whether a supervisor is running for the pinned service, as
+// Ok does for a path
+func (p *PinnedDir) Ok() (bool, error) {
+	name := OkFile
+	file, err := p.openSupervise(name, os.O_WRONLY|unix.ONonblock)
+	if errors.Is(err, os.ErrNotExist) {
+		// s6-supervise has no ok FIFO; its control FIFO serves the same purpose
+		name = ControlFile
+		file, err = p.openSupervise(name, os.O_WRONLY|unix.ONonblock)
+	}
+	if err != nil {
+		if errors.Is(err, syscall.ENXIO) || errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, &OpError{Op: OpStatus, Path: filepath.Join(p.path, SuperviseDir, name), Err: err}
+	}
+	_ = file.Close()
+	return true, nil
+}
+
+// Close releases the held directories
+func (p *PinnedDir) Close() error {
+	return errors.Join(p.supervise.Close(), p.dir.Close())
+}
+
+// openSupervise opens name, a single path element, in the supervise
+// directory without following symlinks
+func (p *PinnedDir) openSupervise(name string, flag int) (*os.File, error) {
+	return openAt(p.supervise, name, flag, false)
+}
+
+// openSuperviseFile opens the supervise file at path, relative to pin when
+// one is set
+func openSuperviseFile(pin *PinnedDir, path string, flag int) (*os.File, error) {
+	if pin != nil {
+		return pin.openSupervise(filepath.Base(path), flag)
+	}
+	return os.OpenFile(path, flag, 0)
+}
+
+// dialControl connects to a control socket, unless the directory is pinned
+func dialControl(path string, timeout time.Duration, pin *PinnedDir) (net.Conn, error) {
+	if pin != nil {
+		return nil, errors.ErrUnsupported
+	}
+	return net.DialTimeout("unix", path, timeout)
+}
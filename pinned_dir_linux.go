@@ -0,0 +1,44 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// pinSupported reports whether PinServiceDir is available
+const pinSupported = true
+
+// openAt opens name relative to dir. Unless follow is set, symlinks are
+// refused with openat2 RESOLVE_NO_SYMLINKS, or with O_NOFOLLOW on kernels
+// before 5.6, which is equivalent for the single path elements opened here.
+func openAt(dir *os.File, name string, flag int, follow bool) (*os.File, error) {
+	how := unix.OpenHow{Flags: uint64(flag | unix.O_CLOEXEC)}
+	if !follow {
+		how.Resolve = unix.RESOLVE_NO_SYMLINKS | unix.RESOLVE_BENEATH
+	}
+
+	var (
+		fd  int
+		err error
+	)
+	for {
+		fd, err = unix.Openat2(int(dir.Fd()), name, &how)
+		if errors.Is(err, unix.ENOSYS) {
+			if !follow {
+				flag |= unix.O_NOFOLLOW
+			}
+			fd, err = unix.Openat(int(dir.Fd()), name, flag|unix.O_CLOEXEC, 0)
+		}
+		if !errors.Is(err, unix.EINTR) {
+			break
+		}
+	}
+	path := filepath.Join(dir.Name(), name)
+	if err != nil {
+		return nil, &os.PathError{Op: "openat", Path: path, Err: err}
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
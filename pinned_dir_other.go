@@ -0,0 +1,16 @@
+//go:build !linux
+
+package svcmgr
+
+import (
+	"errors"
+	"os"
+)
+
+// pinSupported reports whether PinServiceDir is available
+const pinSupported = false
+
+// openAt is unavailable without openat2
+func openAt(dir *os.File, name string, flag int, follow bool) (*os.File, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,112 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestPinnedDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	var mocks []*MockSupervisor
+	for _, name := range []string{"a", "b"} {
+		m, err := NewMockSupervisor(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mocks = append(mocks, m)
+	}
+	if err := mocks[0].UpdateStatus(true, 111); err != nil {
+		t.Fatal(err)
+	}
+	if err := mocks[1].UpdateStatus(true, 222); err != nil {
+		t.Fatal(err)
+	}
+
+	// The service is enabled through a symlink, as in /etc/service
+	link := filepath.Join(root, "web")
+	if err := os.Symlink(mocks[0].ServiceDir, link); err != nil {
+		t.Fatal(err)
+	}
+
+	pin, err := PinServiceDir(link)
+	if err != nil {
+		t.Fatalf("PinServiceDir: %v", err)
+	}
+	defer func() { _ = pin.Close() }()
+	if pin.Path() != link {
+		t.Errorf("Path = %q, want %q", pin.Path(), link)
+	}
+
+	// A pin of another service is rejected
+	if _, err := NewClient(mocks[1].ServiceDir, WithPinnedDir(pin)); err == nil {
+		t.Error("NewClient accepted a pin of another service directory")
+	}
+
+	client, err := NewClient(link, WithServiceType(ServiceTypeRunit), WithPinnedDir(pin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the symlink to another service after pinning
+	tmp := link + ".new"
+	if err := os.Symlink(mocks[1].ServiceDir, tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.PID != 111 {
+		t.Errorf("Status PID = %d, want 111 from the pinned service", status.PID)
+	}
+
+	if err := client.Down(ctx); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	for i, m := range mocks {
+		data, err := os.ReadFile(m.ControlFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(data) == "d", i == 0; got != want {
+			t.Errorf("service %d control = %q, want command only in the pinned service", i, data)
+		}
+	}
+
+	if ok, err := pin.Ok(); err != nil || !ok {
+		t.Errorf("Ok = %v, %v; want true", ok, err)
+	}
+
+	// Symlinks inside the supervise directory are refused
+	status0 := filepath.Join(mocks[0].SuperviseDir, StatusFile)
+	if err := os.Remove(status0); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(mocks[1].SuperviseDir, StatusFile), status0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Status(ctx); !errors.Is(err, syscall.ELOOP) {
+		t.Errorf("Status through a symlink: got %v, want ELOOP", err)
+	}
+}
+
+func TestPinServiceDirNotSupervised(t *testing.T) {
+	if _, err := PinServiceDir(t.TempDir()); !errors.Is(err, ErrNotSupervised) {
+		t.Errorf("got %v, want ErrNotSupervised", err)
+	}
+	if _, err := PinServiceDir(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want ErrNotExist", err)
+	}
+}
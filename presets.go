@@ -0,0 +1,82 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// presets holds the registered ServiceConfig presets by lower-case name,
+// seeded with the built-in ones
+var presets = struct {
+	sync.RWMutex
+	m map[string]func() *ServiceConfig
+}{m: map[string]func() *ServiceConfig{
+	// Supervision system defaults
+	"runit":       ConfigRunit,
+	"daemontools": ConfigDaemontools,
+	"s6":          ConfigS6,
+	"systemd":     ConfigSystemd,
+
+	// Void Linux links services from /etc/sv into /var/service
+	"void": withServiceDir(ConfigRunit, DefaultEnabledDir),
+	// Artix's runit runs runsvdir on /run/runit/service
+	"artix": withServiceDir(ConfigRunit, "/run/runit/service"),
+	// Debian's runit package scans /etc/service
+	"debian-runit": withServiceDir(ConfigRunit, "/etc/service"),
+	// OpenRC's s6 integration (Alpine, Gentoo) scans /run/openrc/s6-scan
+	"alpine-s6": withServiceDir(ConfigS6, "/run/openrc/s6-scan"),
+	"gentoo-s6": withServiceDir(ConfigS6, "/run/openrc/s6-scan"),
+	// Gentoo's runit ebuild scans /etc/service
+	"gentoo-runit": withServiceDir(ConfigRunit, "/etc/service"),
+}}
+
+// withServiceDir returns a preset of base with its ServiceDir set to dir
+func withServiceDir(base func() *ServiceConfig, dir string) func() *ServiceConfig {
+	return func() *ServiceConfig {
+		c := base()
+		c.ServiceDir = dir
+		return c
+	}
+}
+
+// RegisterPreset makes a ServiceConfig preset available to Preset under name
+// (matched case-insensitively). fn is called for every lookup, so each caller
+// gets a config it may modify. Registering a name twice, including a
+// built-in preset's, is an error.
+func RegisterPreset(name string, fn func() *ServiceConfig) error {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" || fn == nil {
+		return errors.New("preset name and function are required")
+	}
+
+	presets.Lock()
+	defer presets.Unlock()
+	if _, ok := presets.m[key]; ok {
+		return fmt.Errorf("preset %q already registered", name)
+	}
+	presets.m[key] = fn
+	return nil
+}
+
+// Preset returns the ServiceConfig registered under name, such as "void",
+// "artix", "debian-runit", "alpine-s6" or "gentoo-runit"
+func Preset(name string) (*ServiceConfig, error) {
+	presets.RLock()
+	fn, ok := presets.m[strings.ToLower(strings.TrimSpace(name))]
+	presets.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown preset: %q", name)
+	}
+	return fn(), nil
+}
+
+// Presets returns the names of all registered presets in sorted order
+func Presets() []string {
+	presets.RLock()
+	defer presets.RUnlock()
+	return slices.Sorted(maps.Keys(presets.m))
+}
@@ -0,0 +1,70 @@
+package svcmgr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPreset(t *testing.T) {
+	tests := []struct {
+		name           string
+		wantType       ServiceType
+		wantServiceDir string
+	}{
+		{name: "runit", wantType: ServiceTypeRunit, wantServiceDir: "/etc/service"},
+		{name: "void", wantType: ServiceTypeRunit, wantServiceDir: "/var/service"},
+		{name: "Artix", wantType: ServiceTypeRunit, wantServiceDir: "/run/runit/service"},
+		{name: "alpine-s6", wantType: ServiceTypeS6, wantServiceDir: "/run/openrc/s6-scan"},
+		{name: "gentoo-runit", wantType: ServiceTypeRunit, wantServiceDir: "/etc/service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := Preset(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Type != tt.wantType || config.ServiceDir != tt.wantServiceDir {
+				t.Errorf("Preset(%q) = %v %q, want %v %q", tt.name, config.Type, config.ServiceDir, tt.wantType, tt.wantServiceDir)
+			}
+		})
+	}
+
+	// Each lookup returns a fresh config
+	first, _ := Preset("void")
+	first.ServiceDir = "/changed"
+	delete(first.SupportedOps, OpUp)
+	second, _ := Preset("void")
+	if second.ServiceDir != "/var/service" || !second.IsOperationSupported(OpUp) {
+		t.Error("modifying a preset config affected later lookups")
+	}
+
+	if _, err := Preset("plan9"); err == nil {
+		t.Error("Preset accepted an unknown name")
+	}
+}
+
+func TestRegisterPreset(t *testing.T) {
+	custom := func() *ServiceConfig {
+		c := ConfigRunit()
+		c.ServiceDir = "/opt/services"
+		return c
+	}
+	if err := RegisterPreset("test-custom", custom); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterPreset("TEST-CUSTOM", custom); err == nil {
+		t.Error("RegisterPreset accepted a duplicate name")
+	}
+	if err := RegisterPreset("", custom); err == nil {
+		t.Error("RegisterPreset accepted an empty name")
+	}
+
+	config, err := Preset("test-custom")
+	if err != nil || config.ServiceDir != "/opt/services" {
+		t.Errorf("Preset(test-custom) = %+v, %v", config, err)
+	}
+	if !slices.Contains(Presets(), "test-custom") {
+		t.Errorf("Presets() = %v, missing test-custom", Presets())
+	}
+}
@@ -0,0 +1,140 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ProcessNamespaces describes where a service's workload runs when it lives
+// in a different PID or mount namespace than the reader, as when the run
+// script execs into a container (unshare, nsenter, a container runtime)
+type ProcessNamespaces struct {
+	// WorkloadPID is the process in the foreign namespace, as seen from
+	// the reader: the supervised process itself, or its nearest descendant
+	// in a foreign PID namespace when the supervised process is a launcher
+	// such as "unshare --fork" or a runtime shim
+	WorkloadPID int `json:"workload_pid"`
+
+	// NSPID is WorkloadPID as seen inside its own PID namespace (often 1)
+	NSPID int `json:"ns_pid"`
+
+	// PIDNamespace and MountNamespace identify the workload's namespaces,
+	// e.g. "pid:[4026532301]"
+	PIDNamespace   string `json:"pid_ns"`
+	MountNamespace string `json:"mnt_ns"`
+
+	// ForeignPID and ForeignMount report which namespaces differ from the reader's
+	ForeignPID   bool `json:"foreign_pid"`
+	ForeignMount bool `json:"foreign_mount"`
+}
+
+// Containerized reports whether the workload runs in any foreign namespace
+func (n ProcessNamespaces) Containerized() bool {
+	return n.ForeignPID || n.ForeignMount
+}
+
+// Signal sends sig directly to the workload. Supervisors signal the
+// process they started, which a launcher may not forward into the
+// container; Signal reaches the workload itself.
+func (n ProcessNamespaces) Signal(sig syscall.Signal) error {
+	if n.WorkloadPID <= 0 {
+		return fmt.Errorf("signal workload: %w", ErrNotSupervised)
+	}
+	return syscall.Kill(n.WorkloadPID, sig)
+}
+
+// ReadProcessNamespaces reads the namespaces of pid from the proc
+// filesystem mounted at procDir (normally DefaultProcDir) and compares them
+// with the reader's own. When pid shares the reader's PID namespace, its
+// descendants are searched for the nearest one that does not, which becomes
+// the WorkloadPID. Reading another user's namespaces needs privileges.
+func ReadProcessNamespaces(procDir string, pid int) (ProcessNamespaces, error) {
+	selfPID, err := readNamespace(procDir, "self", "pid")
+	if err != nil {
+		return ProcessNamespaces{}, err
+	}
+	selfMnt, err := readNamespace(procDir, "self", "mnt")
+	if err != nil {
+		return ProcessNamespaces{}, err
+	}
+
+	workload := pid
+	pidNS, err := readNamespace(procDir, strconv.Itoa(pid), "pid")
+	if err != nil {
+		return ProcessNamespaces{}, err
+	}
+	if pidNS == selfPID {
+		// Look for a launcher's child that runs in its own PID namespace
+		if tree, err := ReadProcessTree(procDir, pid); err == nil {
+			for _, p := range tree[1:] {
+				if ns, err := readNamespace(procDir, strconv.Itoa(p.PID), "pid"); err == nil && ns != selfPID {
+					workload, pidNS = p.PID, ns
+					break
+				}
+			}
+		}
+	}
+
+	mntNS, err := readNamespace(procDir, strconv.Itoa(workload), "mnt")
+	if err != nil {
+		return ProcessNamespaces{}, err
+	}
+
+	n := ProcessNamespaces{
+		WorkloadPID:    workload,
+		NSPID:          workload,
+		PIDNamespace:   pidNS,
+		MountNamespace: mntNS,
+		ForeignPID:     pidNS != selfPID,
+		ForeignMount:   mntNS != selfMnt,
+	}
+	if nspid, err := readNSPID(procDir, workload); err == nil {
+		n.NSPID = nspid
+	}
+	return n, nil
+}
+
+// readNamespace returns the target of /proc/<pid>/ns/<kind>
+func readNamespace(procDir, pid, kind string) (string, error) {
+	return os.Readlink(filepath.Join(procDir, pid, "ns", kind))
+}
+
+// readNSPID returns the innermost PID from the NSpid line of /proc/<pid>/status
+func readNSPID(procDir string, pid int) (int, error) {
+	f, err := os.Open(filepath.Join(procDir, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "NSpid:")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			break
+		}
+		return strconv.Atoi(fields[len(fields)-1])
+	}
+	return 0, fmt.Errorf("%w: no NSpid in status of %d", ErrDecode, pid)
+}
+
+// processNamespaces returns the namespaces of pid when its workload is
+// containerized, nil otherwise or when they cannot be read
+func processNamespaces(procDir string, pid int) *ProcessNamespaces {
+	n, err := ReadProcessNamespaces(procDir, pid)
+	if err != nil || !n.Containerized() {
+		return nil
+	}
+	return &n
+}
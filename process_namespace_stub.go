@@ -0,0 +1,24 @@
+//go:build !linux
+
+package svcmgr
+
+// ProcessNamespaces describes where a service's workload runs when it lives
+// in a different PID or mount namespace. Namespaces only exist on Linux.
+type ProcessNamespaces struct {
+	WorkloadPID    int    `json:"workload_pid"`
+	NSPID          int    `json:"ns_pid"`
+	PIDNamespace   string `json:"pid_ns"`
+	MountNamespace string `json:"mnt_ns"`
+	ForeignPID     bool   `json:"foreign_pid"`
+	ForeignMount   bool   `json:"foreign_mount"`
+}
+
+// Containerized reports whether the workload runs in any foreign namespace
+func (n ProcessNamespaces) Containerized() bool {
+	return n.ForeignPID || n.ForeignMount
+}
+
+// processNamespaces always returns nil outside Linux
+func processNamespaces(string, int) *ProcessNamespaces {
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeFakeNamespaces links /proc/<pid>/ns/{pid,mnt} in procDir
+func writeFakeNamespaces(t *testing.T, procDir, pid, pidNS, mntNS string) {
+	t.Helper()
+	dir := filepath.Join(procDir, pid, "ns")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for kind, target := range map[string]string{"pid": pidNS, "mnt": mntNS} {
+		if err := os.Symlink(target, filepath.Join(dir, kind)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadProcessNamespaces(t *testing.T) {
+	procDir := t.TempDir()
+	writeFakeNamespaces(t, procDir, "self", "pid:[1]", "mnt:[1]")
+
+	// 10 is "unshare --fork", whose child 11 is PID 1 of a new namespace
+	writeFakeProc(t, procDir, 10, 1, "unshare", "--pid", "--fork", "app")
+	writeFakeNamespaces(t, procDir, "10", "pid:[1]", "mnt:[1]")
+	writeFakeProc(t, procDir, 11, 10, "app")
+	writeFakeNamespaces(t, procDir, "11", "pid:[2]", "mnt:[2]")
+	status := "Name:\tapp\nNSpid:\t11\t1\n"
+	if err := os.WriteFile(filepath.Join(procDir, "11", "status"), []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 20 shares the reader's namespaces
+	writeFakeProc(t, procDir, 20, 1, "plain")
+	writeFakeNamespaces(t, procDir, "20", "pid:[1]", "mnt:[1]")
+
+	n, err := ReadProcessNamespaces(procDir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProcessNamespaces{
+		WorkloadPID:    11,
+		NSPID:          1,
+		PIDNamespace:   "pid:[2]",
+		MountNamespace: "mnt:[2]",
+		ForeignPID:     true,
+		ForeignMount:   true,
+	}
+	if n != want {
+		t.Errorf("ReadProcessNamespaces(10) = %+v, want %+v", n, want)
+	}
+
+	if n := processNamespaces(procDir, 20); n != nil {
+		t.Errorf("plain process reported as containerized: %+v", n)
+	}
+	if n := processNamespaces(procDir, os.Getpid()); n != nil {
+		t.Errorf("process %s missing from fake proc reported: %+v", strconv.Itoa(os.Getpid()), n)
+	}
+}
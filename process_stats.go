@@ -0,0 +1,217 @@
+package svcmgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userHZ is the kernel's USER_HZ, the unit of the CPU times in /proc/<pid>/stat.
+// It is 100 on every architecture Linux supports.
+const userHZ = 100
+
+// /proc/<pid>/stat field indexes, counted from the state field that follows
+// the parenthesized command name (see proc(5))
+const (
+	procStatPPID    = 1
+	procStatUtime   = 11
+	procStatStime   = 12
+	procStatThreads = 17
+	procStatStart   = 19
+	procStatRSS     = 21
+)
+
+// ProcessStats holds resource usage of a service's main process
+type ProcessStats struct {
+	// PID is the process the statistics were read from
+	PID int `json:"pid"`
+
+	// RSS is the resident set size in bytes
+	RSS uint64 `json:"rss_bytes"`
+
+	// UserTime is the CPU time spent in user mode
+	UserTime time.Duration `json:"user_time"`
+
+	// SystemTime is the CPU time spent in kernel mode
+	SystemTime time.Duration `json:"system_time"`
+
+	// FDs is the number of open file descriptors, or -1 when the
+	// descriptor table cannot be read (e.g. a process of another user)
+	FDs int `json:"fds"`
+
+	// Threads is the number of threads
+	Threads int `json:"threads"`
+}
+
+// CPUTime returns the total CPU time consumed by the process
+func (p ProcessStats) CPUTime() time.Duration {
+	return p.UserTime + p.SystemTime
+}
+
+// ReadProcessStats reads resource usage for pid from the proc filesystem
+// mounted at procDir (normally DefaultProcDir)
+func ReadProcessStats(procDir string, pid int) (ProcessStats, error) {
+	pidDir := filepath.Join(procDir, strconv.Itoa(pid))
+
+	_, fields, err := readProcStat(procDir, pid)
+	if err != nil {
+		return ProcessStats{}, err
+	}
+	if len(fields) <= procStatRSS {
+		return ProcessStats{}, fmt.Errorf("%w: short %s/stat", ErrDecode, pidDir)
+	}
+
+	stats := ProcessStats{PID: pid, FDs: -1}
+	var values [4]int64
+	for i, field := range []int{procStatUtime, procStatStime, procStatThreads, procStatRSS} {
+		values[i], err = strconv.ParseInt(fields[field], 10, 64)
+		if err != nil {
+			return ProcessStats{}, fmt.Errorf("%w: %s/stat field %d: %w", ErrDecode, pidDir, field, err)
+		}
+	}
+	stats.UserTime = time.Duration(values[0]) * time.Second / userHZ
+	stats.SystemTime = time.Duration(values[1]) * time.Second / userHZ
+	stats.Threads = int(values[2])
+	if values[3] > 0 {
+		stats.RSS = uint64(values[3]) * uint64(os.Getpagesize())
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(pidDir, "fd")); err == nil {
+		stats.FDs = len(entries)
+	}
+
+	return stats, nil
+}
+
+// readProcStat reads /proc/<pid>/stat and returns the command name and the
+// fields following it, starting with the state field
+func readProcStat(procDir string, pid int) (string, []string, error) {
+	path := filepath.Join(procDir, strconv.Itoa(pid), "stat")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The command name may contain spaces and parentheses; fields resume
+	// after the last closing parenthesis
+	open := strings.IndexByte(string(data), '(')
+	end := strings.LastIndexByte(string(data), ')')
+	if open < 0 || end < open {
+		return "", nil, fmt.Errorf("%w: malformed %s", ErrDecode, path)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("%w: short %s", ErrDecode, path)
+	}
+	return string(data[open+1 : end]), fields, nil
+}
+
+// processStartTime returns when pid started, from its start time in
+// /proc/<pid>/stat (clock ticks since boot) and the boot time in /proc/stat.
+// The boot time has one-second resolution.
+func processStartTime(procDir string, pid int) (time.Time, error) {
+	_, fields, err := readProcStat(procDir, pid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(fields) <= procStatStart {
+		return time.Time{}, fmt.Errorf("%w: short %s/%d/stat", ErrDecode, procDir, pid)
+	}
+	ticks, err := strconv.ParseInt(fields[procStatStart], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s/%d/stat start time: %w", ErrDecode, procDir, pid, err)
+	}
+	boot, err := bootTime(procDir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / userHZ), nil
+}
+
+// bootTime reads the btime line of /proc/stat
+func bootTime(procDir string) (time.Time, error) {
+	path := filepath.Join(procDir, "stat")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for line := range strings.Lines(string(data)) {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: %s btime: %w", ErrDecode, path, err)
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: no btime in %s", ErrDecode, path)
+}
+
+// StatusExtended is a Status augmented with resource usage of the main process
+type StatusExtended struct {
+	Status
+
+	// Process holds the main process's resource usage; nil when the service
+	// has no process or its statistics could not be read
+	Process *ProcessStats
+
+	// ProcessErr explains why Process is nil for a service with a PID
+	ProcessErr error
+
+	// Namespaces is set when the workload runs in a different PID or mount
+	// namespace than the reader (Linux only). Process then describes the
+	// workload process rather than a launcher in front of it.
+	Namespaces *ProcessNamespaces
+}
+
+// statusExtendedWire is the serialized form of StatusExtended
+type statusExtendedWire struct {
+	statusWire
+	Process      *ProcessStats      `json:"process,omitempty"`
+	ProcessError string             `json:"process_error,omitempty"`
+	Namespaces   *ProcessNamespaces `json:"namespaces,omitempty"`
+}
+
+// MarshalJSON encodes the status using the Status schema with additional
+// "process", "process_error" and "namespaces" fields
+func (s StatusExtended) MarshalJSON() ([]byte, error) {
+	w := statusExtendedWire{statusWire: s.Status.toWire(), Process: s.Process, Namespaces: s.Namespaces}
+	if s.ProcessErr != nil {
+		w.ProcessError = s.ProcessErr.Error()
+	}
+	return json.Marshal(w)
+}
+
+// statusExtended reads the status through statusFn and adds process statistics
+func statusExtended(ctx context.Context, statusFn func(context.Context) (Status, error), procDir string) (StatusExtended, error) {
+	st, err := statusFn(ctx)
+	if err != nil {
+		return StatusExtended{}, err
+	}
+
+	ext := StatusExtended{Status: st}
+	if st.PID <= 0 {
+		return ext, nil
+	}
+
+	// Read the workload's statistics, not those of a launcher in front of
+	// a container
+	pid := st.PID
+	if ext.Namespaces = processNamespaces(procDir, st.PID); ext.Namespaces != nil {
+		pid = ext.Namespaces.WorkloadPID
+	}
+
+	// The process may exit between reading the status and its statistics
+	stats, err := ReadProcessStats(procDir, pid)
+	if err != nil {
+		ext.ProcessErr = err
+		return ext, nil
+	}
+	ext.Process = &stats
+	return ext, nil
+}
@@ -0,0 +1,113 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadProcessStats(t *testing.T) {
+	procDir := t.TempDir()
+	pidDir := filepath.Join(procDir, "42")
+	if err := os.MkdirAll(filepath.Join(pidDir, "fd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, fd := range []string{"0", "1", "2"} {
+		if err := os.WriteFile(filepath.Join(pidDir, "fd", fd), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Command name with spaces and parentheses; utime=250 stime=50 threads=7 rss=10 pages
+	stat := "42 (my (odd) cmd) S 1 42 42 0 -1 4194560 100 0 0 0 250 50 0 0 20 0 7 0 1000 4096000 10 18446744073709551615"
+	if err := os.WriteFile(filepath.Join(pidDir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := ReadProcessStats(procDir, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProcessStats{
+		PID:        42,
+		RSS:        10 * uint64(os.Getpagesize()),
+		UserTime:   2500 * time.Millisecond,
+		SystemTime: 500 * time.Millisecond,
+		FDs:        3,
+		Threads:    7,
+	}
+	if stats != want {
+		t.Errorf("ReadProcessStats() = %+v, want %+v", stats, want)
+	}
+	if stats.CPUTime() != 3*time.Second {
+		t.Errorf("CPUTime() = %v, want 3s", stats.CPUTime())
+	}
+
+	if _, err := ReadProcessStats(procDir, 43); !os.IsNotExist(err) {
+		t.Errorf("missing pid: err = %v, want not-exist", err)
+	}
+}
+
+func TestReadProcessStatsSelf(t *testing.T) {
+	stats, err := ReadProcessStats(DefaultProcDir, os.Getpid())
+	if err != nil {
+		t.Skipf("proc filesystem unavailable: %v", err)
+	}
+	if stats.RSS == 0 || stats.Threads == 0 || stats.FDs <= 0 {
+		t.Errorf("implausible stats for own process: %+v", stats)
+	}
+}
+
+func TestStatusExtended(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientRunit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	ext, err := client.StatusExtended(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.Process != nil || ext.ProcessErr != nil {
+		t.Errorf("down service: Process = %v, ProcessErr = %v", ext.Process, ext.ProcessErr)
+	}
+
+	// Report the test binary itself as the service's main process
+	if err := mock.UpdateStatus(true, os.Getpid()); err != nil {
+		t.Fatal(err)
+	}
+	ext, err = client.StatusExtended(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.State != StateRunning {
+		t.Errorf("State = %v, want running", ext.State)
+	}
+	if ext.Process == nil {
+		t.Fatalf("Process = nil, ProcessErr = %v", ext.ProcessErr)
+	}
+	if ext.Process.PID != os.Getpid() {
+		t.Errorf("Process.PID = %d, want %d", ext.Process.PID, os.Getpid())
+	}
+
+	data, err := json.Marshal(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"state":"running"`, `"process":{`, `"rss_bytes":`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("JSON %s missing %s", data, field)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ProcessInfo describes a process in a service's process tree
+type ProcessInfo struct {
+	// PID is the process ID
+	PID int `json:"pid"`
+
+	// PPID is the parent process ID
+	PPID int `json:"ppid"`
+
+	// Name is the command name from /proc/<pid>/stat
+	Name string `json:"name"`
+
+	// State is the single-letter process state (R, S, D, Z, T, ...)
+	State string `json:"state"`
+
+	// Args is the command line; empty for zombies and kernel threads
+	Args []string `json:"args,omitempty"`
+}
+
+// ReadProcessTree returns the process rootPID and all of its descendants
+// from the proc filesystem mounted at procDir (normally DefaultProcDir).
+// The root comes first, followed by descendants in breadth-first order.
+func ReadProcessTree(procDir string, rootPID int) ([]ProcessInfo, error) {
+	root, err := readProcessInfo(procDir, rootPID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]ProcessInfo)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == rootPID {
+			continue
+		}
+		// Processes may exit while the directory is being scanned
+		info, err := readProcessInfo(procDir, pid)
+		if err != nil {
+			continue
+		}
+		children[info.PPID] = append(children[info.PPID], info)
+	}
+
+	tree := []ProcessInfo{root}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i].PID]...)
+	}
+	return tree, nil
+}
+
+// readProcessInfo reads the stat and cmdline of a single process
+func readProcessInfo(procDir string, pid int) (ProcessInfo, error) {
+	name, fields, err := readProcStat(procDir, pid)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	ppid, err := strconv.Atoi(fields[procStatPPID])
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	info := ProcessInfo{PID: pid, PPID: ppid, Name: name, State: fields[0]}
+	if cmdline, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "cmdline")); err == nil && len(cmdline) > 0 {
+		for _, arg := range bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0}) {
+			info.Args = append(info.Args, string(arg))
+		}
+	}
+	return info, nil
+}
+
+// processTree reads the status through statusFn and returns the process tree
+// of its main process, or nil when the service has no process
+func processTree(ctx context.Context, statusFn func(context.Context) (Status, error), procDir string) ([]ProcessInfo, error) {
+	st, err := statusFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if st.PID <= 0 {
+		return nil, nil
+	}
+	return ReadProcessTree(procDir, st.PID)
+}
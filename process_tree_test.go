@@ -0,0 +1,85 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestReadProcessTree(t *testing.T) {
+	procDir := t.TempDir()
+	writeFakeProc(t, procDir, 10, 1, "/bin/sh", "./run")
+	writeFakeProc(t, procDir, 11, 10, "worker", "-n", "1")
+	writeFakeProc(t, procDir, 12, 10, "worker", "-n", "2")
+	writeFakeProc(t, procDir, 20, 11, "helper")
+	writeFakeProc(t, procDir, 30, 1, "unrelated")
+
+	tree, err := ReadProcessTree(procDir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pids []int
+	for _, p := range tree {
+		pids = append(pids, p.PID)
+	}
+	if !slices.Equal(pids, []int{10, 11, 12, 20}) {
+		t.Errorf("tree pids = %v, want [10 11 12 20]", pids)
+	}
+	if tree[0].PPID != 1 || tree[0].Name != "sh (x)" || tree[0].State != "S" {
+		t.Errorf("root = %+v", tree[0])
+	}
+	if !slices.Equal(tree[1].Args, []string{"worker", "-n", "1"}) {
+		t.Errorf("child args = %q", tree[1].Args)
+	}
+
+	if _, err := ReadProcessTree(procDir, 99); !os.IsNotExist(err) {
+		t.Errorf("missing root: err = %v, want not-exist", err)
+	}
+}
+
+func TestClientProcessTree(t *testing.T) {
+	dir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientRunit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tree, err := client.ProcessTree(ctx)
+	if err != nil || tree != nil {
+		t.Fatalf("down service: ProcessTree() = %v, %v", tree, err)
+	}
+
+	// Use the test binary as the main process, with one child
+	child := exec.Command("sleep", "30")
+	if err := child.Start(); err != nil {
+		t.Skipf("cannot start child: %v", err)
+	}
+	defer func() {
+		_ = child.Process.Kill()
+		_ = child.Wait()
+	}()
+
+	if err := mock.UpdateStatus(true, os.Getpid()); err != nil {
+		t.Fatal(err)
+	}
+	tree, err = client.ProcessTree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) == 0 || tree[0].PID != os.Getpid() {
+		t.Fatalf("tree root = %v, want pid %d", tree, os.Getpid())
+	}
+	if !slices.ContainsFunc(tree, func(p ProcessInfo) bool { return p.PID == child.Process.Pid }) {
+		t.Errorf("child %d missing from tree %v", child.Process.Pid, tree)
+	}
+}
@@ -0,0 +1,191 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReexecPollInterval is how often GracefulReexec checks for the new process
+const DefaultReexecPollInterval = 100 * time.Millisecond
+
+// RestartStrategy restarts a service through its client
+type RestartStrategy interface {
+	Restart(ctx context.Context, client ServiceClient) error
+}
+
+// RestartStrategyFunc adapts a function to the RestartStrategy interface
+type RestartStrategyFunc func(ctx context.Context, client ServiceClient) error
+
+// Restart calls f(ctx, client)
+func (f RestartStrategyFunc) Restart(ctx context.Context, client ServiceClient) error {
+	return f(ctx, client)
+}
+
+// DownUpRestart is the default strategy: the client's own Restart, which
+// stops the service and starts it again
+var DownUpRestart RestartStrategy = RestartStrategyFunc(func(ctx context.Context, client ServiceClient) error {
+	return client.Restart(ctx)
+})
+
+// GracefulReexec restarts services that support binary handoff (nginx,
+// unicorn, ...) without downtime: it signals the running process to re-exec
+// itself, waits for the new process to appear (and optionally become ready),
+// then signals the old process to exit.
+//
+// The service's run script must keep supervision alive across the handoff
+// (e.g. by supervising a wrapper), since the supervised PID exits at the end.
+type GracefulReexec struct {
+	// Signal asks the old process to start its replacement; zero means OpUSR2
+	Signal Operation
+
+	// OldSignal tells the old process to exit once the new one is up;
+	// zero means OpTerm (use OpQuit for a graceful nginx shutdown)
+	OldSignal Operation
+
+	// PIDFile, when set, is read to find the new process. Otherwise the new
+	// process is the first descendant of the old one running the same command.
+	PIDFile string
+
+	// Ready, when set, is polled with the new PID until it reports true
+	Ready func(ctx context.Context, pid int) (bool, error)
+
+	// PollInterval is how often the new process and readiness are checked
+	PollInterval time.Duration
+
+	// ProcDir is the proc filesystem used to find and check processes
+	ProcDir string
+}
+
+// Restart performs the handoff. The context bounds the whole exchange; on
+// failure the old process is left running.
+func (g GracefulReexec) Restart(ctx context.Context, client ServiceClient) error {
+	signal, oldSignal := g.Signal, g.OldSignal
+	if signal == OpUnknown {
+		signal = OpUSR2
+	}
+	if oldSignal == OpUnknown {
+		oldSignal = OpTerm
+	}
+	interval := g.PollInterval
+	if interval <= 0 {
+		interval = DefaultReexecPollInterval
+	}
+	procDir := g.ProcDir
+	if procDir == "" {
+		procDir = DefaultProcDir
+	}
+
+	st, err := client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	oldPID := st.PID
+	if oldPID <= 0 {
+		return errors.New("graceful reexec: service is not running")
+	}
+
+	if err := signalClient(ctx, client, signal); err != nil {
+		return err
+	}
+
+	var newPID int
+	err = pollUntil(ctx, interval, func() (bool, error) {
+		newPID = g.findNewPID(procDir, oldPID)
+		return newPID > 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("graceful reexec: waiting for new process of pid %d: %w", oldPID, err)
+	}
+
+	if g.Ready != nil {
+		err = pollUntil(ctx, interval, func() (bool, error) {
+			return g.Ready(ctx, newPID)
+		})
+		if err != nil {
+			return fmt.Errorf("graceful reexec: waiting for pid %d to become ready: %w", newPID, err)
+		}
+	}
+
+	return signalClient(ctx, client, oldSignal)
+}
+
+// findNewPID returns the replacement for oldPID, or 0 if it has not appeared
+func (g GracefulReexec) findNewPID(procDir string, oldPID int) int {
+	if g.PIDFile != "" {
+		data, err := os.ReadFile(g.PIDFile)
+		if err != nil {
+			return 0
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil || pid <= 0 || pid == oldPID {
+			return 0
+		}
+		if _, err := os.Stat(filepath.Join(procDir, strconv.Itoa(pid))); err != nil {
+			return 0
+		}
+		return pid
+	}
+
+	tree, err := ReadProcessTree(procDir, oldPID)
+	if err != nil {
+		return 0
+	}
+	for _, p := range tree[1:] {
+		if p.Name == tree[0].Name {
+			return p.PID
+		}
+	}
+	return 0
+}
+
+// pollUntil calls check every interval until it reports true, fails, or ctx ends
+func pollUntil(ctx context.Context, interval time.Duration, check func() (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// signalClient sends the signal operation op through client
+func signalClient(ctx context.Context, client Controller, op Operation) error {
+	switch op {
+	case OpTerm:
+		return client.Term(ctx)
+	case OpKill:
+		return client.Kill(ctx)
+	case OpHUP:
+		return client.HUP(ctx)
+	case OpAlarm:
+		return client.Alarm(ctx)
+	case OpInterrupt:
+		return client.Interrupt(ctx)
+	case OpQuit:
+		return client.Quit(ctx)
+	case OpUSR1:
+		return client.USR1(ctx)
+	case OpUSR2:
+		return client.USR2(ctx)
+	default:
+		return fmt.Errorf("operation %v is not a signal", op)
+	}
+}
@@ -0,0 +1,169 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// handoffService is a mock runit service whose fake /proc spawns a
+// replacement process once the re-exec signal arrives
+func handoffService(t *testing.T, pidFile string) (*MockSupervisor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	procDir := t.TempDir()
+
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 100); err != nil {
+		t.Fatal(err)
+	}
+	writeFakeProc(t, procDir, 100, 1, "nginx: master")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			data, _ := os.ReadFile(mock.ControlFile)
+			if len(data) > 0 && data[0] == OpUSR2.Byte() {
+				// writeFakeProc must not be called from this goroutine
+				child := filepath.Join(procDir, "101")
+				_ = os.MkdirAll(child, 0o755)
+				_ = os.WriteFile(filepath.Join(child, "stat"), []byte("101 (sh (x)) S 100 101 0 0"), 0o644)
+				if pidFile != "" {
+					_ = os.WriteFile(pidFile, []byte("101\n"), 0o644)
+				}
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	return mock, procDir
+}
+
+func TestGracefulReexec(t *testing.T) {
+	tests := []struct {
+		name    string
+		pidFile bool
+		old     Operation
+	}{
+		{name: "process tree", old: OpUnknown},
+		{name: "pid file", pidFile: true, old: OpQuit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pidFile string
+			if tt.pidFile {
+				pidFile = filepath.Join(t.TempDir(), "nginx.pid")
+			}
+			mock, procDir := handoffService(t, pidFile)
+
+			client, err := NewClientRunit(mock.ServiceDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var readyPID int
+			strategy := GracefulReexec{
+				OldSignal:    tt.old,
+				PIDFile:      pidFile,
+				ProcDir:      procDir,
+				PollInterval: time.Millisecond,
+				Ready: func(_ context.Context, pid int) (bool, error) {
+					readyPID = pid
+					return true, nil
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := strategy.Restart(ctx, client); err != nil {
+				t.Fatal(err)
+			}
+
+			if readyPID != 101 {
+				t.Errorf("readiness checked for pid %d, want 101", readyPID)
+			}
+			wantOld := tt.old
+			if wantOld == OpUnknown {
+				wantOld = OpTerm
+			}
+			data, err := os.ReadFile(mock.ControlFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == 0 || data[0] != wantOld.Byte() {
+				t.Errorf("last control byte = %q, want %q", data, wantOld.Byte())
+			}
+		})
+	}
+}
+
+func TestGracefulReexecTimeout(t *testing.T) {
+	dir := t.TempDir()
+	procDir := t.TempDir()
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 200); err != nil {
+		t.Fatal(err)
+	}
+	writeFakeProc(t, procDir, 200, 1, "unicorn")
+
+	client, err := NewClientRunit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = GracefulReexec{ProcDir: procDir, PollInterval: time.Millisecond}.Restart(ctx, client)
+	if err == nil {
+		t.Fatal("expected timeout when no new process appears")
+	}
+
+	// The old process must not have been told to exit
+	data, _ := os.ReadFile(mock.ControlFile)
+	if len(data) > 0 && data[0] == OpTerm.Byte() {
+		t.Error("old process was terminated despite failed handoff")
+	}
+}
+
+func TestManagerRestartWith(t *testing.T) {
+	var dirs []string
+	for i := range 3 {
+		dir := filepath.Join(t.TempDir(), "svc"+strconv.Itoa(i))
+		if _, err := NewMockSupervisor(dir); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	restarted := make(chan string, len(dirs))
+	strategy := RestartStrategyFunc(func(ctx context.Context, c ServiceClient) error {
+		restarted <- c.(*ClientRunit).ServiceDir
+		return nil
+	})
+
+	if err := NewManager().RestartWith(context.Background(), strategy, dirs...); err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted) != len(dirs) {
+		t.Errorf("restarted %d services, want %d", len(restarted), len(dirs))
+	}
+}
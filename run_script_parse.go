@@ -0,0 +1,370 @@
+package svcmgr
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunScript is what ParseRunScript recovered from a run script
+type RunScript struct {
+	// Builder reproduces the script's command, chpst options, working
+	// directory, umask, stderr redirection and schedule. Its name and
+	// directory are empty.
+	Builder *ServiceBuilder
+	// EnvDir is the environment directory the command is started with
+	// (chpst -e, envdir or s6-envdir), as written in the script; empty if
+	// none. Its variables are not part of the script: load them with
+	// ReadEnvDir and add them with WithEnvMap.
+	EnvDir string
+	// Unparsed holds the lines and chpst options that have no ServiceBuilder
+	// equivalent
+	Unparsed []string
+}
+
+// ParseRunScript extracts the settings of a run script, such as one written
+// by ServiceBuilder or a typical hand-written one, so that existing services
+// can be inspected and migrated. It understands umask, cd, export, stderr
+// redirection, the "sleep N || exit 1" written for an Every schedule, and a
+// final exec through chpst, setuidgid, s6-setuidgid, envdir, s6-envdir,
+// snooze, runcon and aa-exec. Backslash-continued lines are joined first.
+// Other shell constructs are reported in Unparsed.
+//
+// Example:
+//
+//	f, _ := os.Open("/etc/sv/web/run")
+//	rs, err := svcmgr.ParseRunScript(f)
+//	if err != nil {
+//		return err
+//	}
+//	if rs.EnvDir != "" {
+//		env, _ := svcmgr.ReadEnvDir(filepath.Join("/etc/sv/web", rs.EnvDir))
+//		rs.Builder.WithEnvMap(env)
+//	}
+//	systemd := svcmgr.NewBuilderSystemd(rs.Builder)
+func ParseRunScript(r io.Reader) (*RunScript, error) {
+	rs := &RunScript{Builder: NewServiceBuilder("", "").WithUmask(0)}
+	sc := bufio.NewScanner(r)
+	var continued string
+	for sc.Scan() {
+		line := continued + sc.Text()
+		continued = ""
+		if isContinued(line) {
+			continued = line[:len(line)-1]
+			continue
+		}
+		if err := rs.parse(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if continued != "" {
+		if err := rs.parse(continued); err != nil {
+			return nil, err
+		}
+	}
+	if len(rs.Builder.config.Cmd) == 0 {
+		return nil, errors.New("run script: no exec command found")
+	}
+	return rs, nil
+}
+
+// isContinued reports whether line ends in a backslash that joins it with
+// the next line. Comments are never continued.
+func isContinued(line string) bool {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return false
+	}
+	trailing := len(line) - len(strings.TrimRight(line, "\\"))
+	return trailing%2 == 1
+}
+
+// parse applies one logical line of a run script, recording it in Unparsed
+// if it is not understood
+func (rs *RunScript) parse(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	words, err := shellSplit(line)
+	if err != nil {
+		return fmt.Errorf("run script: %w", err)
+	}
+	if !rs.parseLine(line, words) {
+		rs.Unparsed = append(rs.Unparsed, line)
+	}
+	return nil
+}
+
+// parseLine applies one line of a run script, reporting whether it was understood
+func (rs *RunScript) parseLine(line string, words []string) bool {
+	b := rs.Builder
+	switch {
+	case words[0] == "set":
+		return true
+	case words[0] == "umask" && len(words) == 2:
+		mask, err := strconv.ParseUint(words[1], 8, 32)
+		if err != nil {
+			return false
+		}
+		b.WithUmask(fs.FileMode(mask))
+		return true
+	case words[0] == "cd" && (len(words) == 2 || isExitOnFailure(words[2:])):
+		b.WithCwd(words[1])
+		return true
+	case words[0] == "sleep" && len(words) == 5 && isExitOnFailure(words[2:]) && words[4] == "1":
+		// Only the exact form ServiceBuilder writes is a schedule; any other
+		// sleep is just a delay
+		seconds, err := strconv.ParseUint(words[1], 10, 63)
+		if err != nil || seconds == 0 {
+			return false
+		}
+		b.WithSchedule(Every(time.Duration(seconds) * time.Second))
+		return true
+	case words[0] == "export":
+		for _, word := range words[1:] {
+			key, value, ok := strings.Cut(word, "=")
+			if !ok {
+				return false
+			}
+			b.WithEnv(key, value)
+		}
+		return true
+	case words[0] == "exec":
+		return rs.parseExec(words[1:])
+	}
+	return false
+}
+
+// isExitOnFailure reports whether words are "|| exit N"
+func isExitOnFailure(words []string) bool {
+	return len(words) >= 2 && words[0] == "||" && words[1] == "exit"
+}
+
+// parseExec applies the arguments of an exec line: redirections and a
+// chain of wrappers ending in the command
+func (rs *RunScript) parseExec(words []string) bool {
+	b := rs.Builder
+	var args []string
+	for _, word := range words {
+		switch {
+		case word == "2>&1":
+		case strings.HasPrefix(word, "2>"):
+			b.WithStderrPath(strings.TrimPrefix(word, "2>"))
+		default:
+			args = append(args, word)
+		}
+	}
+	if len(args) == 0 {
+		// Only redirects the script's own output
+		return true
+	}
+
+	for len(args) > 0 {
+		switch filepath.Base(args[0]) {
+		case "chpst":
+			b.WithChpstPath(args[0])
+			var ok bool
+			if args, ok = rs.parseChpst(args[1:]); !ok {
+				return false
+			}
+		case "setuidgid", "s6-setuidgid":
+			if len(args) < 2 {
+				return false
+			}
+			b.WithChpstPath(args[0])
+			b.WithChpst(func(c *ChpstConfig) { c.User = args[1] })
+			args = args[2:]
+		case "envdir", "s6-envdir":
+			if len(args) < 2 {
+				return false
+			}
+			if filepath.Base(args[0]) == "s6-envdir" {
+				b.WithChpstPath(args[0])
+			}
+			rs.EnvDir = args[1]
+			args = args[2:]
+		case "snooze":
+			b.WithSnoozePath(args[0])
+			args = rs.parseSnooze(args[1:])
+		case "runcon":
+			if len(args) < 2 {
+				return false
+			}
+			b.WithSELinuxContext(args[1])
+			args = args[2:]
+		case "aa-exec":
+			if len(args) < 4 || args[1] != "-p" || args[3] != "--" {
+				return false
+			}
+			b.WithAppArmorProfile(args[2])
+			args = args[4:]
+		default:
+			b.WithCmd(args)
+			return true
+		}
+	}
+	return false
+}
+
+// chpst options, as in its getopt string
+const (
+	chpstFlagOpts  = "vP012"
+	chpstValueOpts = "uUbemdopfcrt/CnlL"
+)
+
+// parseChpst applies chpst options, returning the arguments after them.
+// Options are decoded like getopt, so "-vP" and "-uapi" are understood. It
+// reports false if an option is unknown or lacks its argument.
+func (rs *RunScript) parseChpst(args []string) ([]string, bool) {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && len(args[0]) > 1 {
+		word := args[0]
+		args = args[1:]
+		if word == "--" {
+			break
+		}
+		for i := 1; i < len(word); i++ {
+			opt := word[i]
+			if strings.IndexByte(chpstFlagOpts, opt) >= 0 {
+				// Options without an argument
+				rs.Unparsed = append(rs.Unparsed, "chpst -"+string(opt))
+				continue
+			}
+			if strings.IndexByte(chpstValueOpts, opt) < 0 {
+				return nil, false
+			}
+			value := word[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return nil, false
+				}
+				value, args = args[0], args[1:]
+			}
+			rs.applyChpst(opt, value)
+			break
+		}
+	}
+	return args, true
+}
+
+// applyChpst applies one chpst option and its argument
+func (rs *RunScript) applyChpst(opt byte, value string) {
+	b := rs.Builder
+	number := func(set func(c *ChpstConfig, n int64)) {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			rs.Unparsed = append(rs.Unparsed, "chpst -"+string(opt)+" "+value)
+			return
+		}
+		b.WithChpst(func(c *ChpstConfig) { set(c, n) })
+	}
+	switch opt {
+	case 'e':
+		rs.EnvDir = value
+	case 'u':
+		b.WithChpst(func(c *ChpstConfig) { c.User = value })
+	case 'U':
+		b.WithChpst(func(c *ChpstConfig) { c.Group = value })
+	case '/':
+		b.WithChpst(func(c *ChpstConfig) { c.Root = value })
+	case 'n':
+		number(func(c *ChpstConfig, n int64) { c.Nice = int(n) })
+	case 'm':
+		number(func(c *ChpstConfig, n int64) { c.LimitMem = n })
+	case 'o':
+		number(func(c *ChpstConfig, n int64) { c.LimitFiles = int(n) })
+	case 'p':
+		number(func(c *ChpstConfig, n int64) { c.LimitProcs = int(n) })
+	case 't':
+		number(func(c *ChpstConfig, n int64) { c.LimitCPU = int(n) })
+	default:
+		rs.Unparsed = append(rs.Unparsed, "chpst -"+string(opt)+" "+value)
+	}
+}
+
+// parseSnooze turns snooze options back into a calendar Schedule,
+// returning the arguments after them
+func (rs *RunScript) parseSnooze(args []string) []string {
+	var s Schedule
+	fields := map[byte]*string{'M': &s.Minute, 'H': &s.Hour, 'd': &s.Day, 'm': &s.Month, 'w': &s.Weekday}
+	for len(args) > 0 && len(args[0]) > 2 && args[0][0] == '-' {
+		opt, value := args[0][1], args[0][2:]
+		switch field, ok := fields[opt]; {
+		case ok:
+			// snooze's "/N" is cron's "*/N"
+			if strings.HasPrefix(value, "/") {
+				value = "*" + value
+			}
+			*field = value
+		case opt == 'R':
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				s.RandomDelay = time.Duration(n) * time.Second
+				break
+			}
+			fallthrough
+		default:
+			rs.Unparsed = append(rs.Unparsed, "snooze "+args[0])
+		}
+		args = args[1:]
+	}
+	rs.Builder.WithSchedule(s)
+	return args
+}
+
+// shellSplit splits a line into words like a POSIX shell, handling quotes
+// and backslashes but no expansions
+func shellSplit(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`", line[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case c == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,159 @@
+package svcmgr
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRunScriptRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *ServiceBuilder)
+	}{
+		{
+			name: "chpst",
+			build: func(b *ServiceBuilder) {
+				b.WithCmd([]string{"/usr/bin/web", "--listen", ":8080", "--name", "it's me"}).
+					WithCwd("/var/lib/web").
+					WithUmask(0o027).
+					WithEnv("PORT", "8080").
+					WithChpst(func(c *ChpstConfig) {
+						c.User = "web"
+						c.Group = "www"
+						c.Nice = 5
+						c.LimitMem = 1 << 30
+						c.LimitFiles = 4096
+						c.LimitProcs = 64
+						c.LimitCPU = 3600
+					})
+			},
+		},
+		{
+			name: "s6 with stderr file",
+			build: func(b *ServiceBuilder) {
+				b.WithCmd([]string{"/usr/bin/worker"}).
+					WithChpstPath("s6-envdir").
+					WithEnv("QUEUE", "jobs").
+					WithStderrPath("/var/log/worker errors")
+			},
+		},
+		{
+			name: "calendar job with MAC",
+			build: func(b *ServiceBuilder) {
+				schedule, _ := ParseCron("*/15 2 * * 1-5")
+				schedule.RandomDelay = time.Minute
+				b.WithCmd([]string{"/usr/bin/backup"}).
+					WithSchedule(schedule).
+					WithSELinuxContext("system_u:system_r:backup_t:s0").
+					WithAppArmorProfile("backup")
+			},
+		},
+		{
+			name: "interval job",
+			build: func(b *ServiceBuilder) {
+				b.WithCmd([]string{"/usr/bin/sync"}).WithSchedule(Every(10 * time.Minute))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := NewServiceBuilder("svc", "")
+			tt.build(orig)
+			script := orig.buildRunScript()
+
+			rs, err := ParseRunScript(strings.NewReader(script))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rs.Unparsed) > 0 {
+				t.Errorf("Unparsed = %q", rs.Unparsed)
+			}
+			if len(orig.config.Env) > 0 && rs.EnvDir != "./env" {
+				t.Errorf("EnvDir = %q, want ./env", rs.EnvDir)
+			}
+			rs.Builder.WithEnvMap(orig.config.Env)
+			if got := rs.Builder.buildRunScript(); got != script {
+				t.Errorf("rebuilt script:\n%s\nwant:\n%s", got, script)
+			}
+		})
+	}
+}
+
+func TestParseRunScriptHandWritten(t *testing.T) {
+	script := `#!/bin/sh
+# start the API
+set -e
+exec 2>&1
+export MODE=prod
+cd /srv/api || exit 1
+exec envdir /etc/api/env setuidgid api "/srv/api/bin/api" --config=/etc/api.conf
+`
+	rs, err := ParseRunScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := rs.Builder.Config()
+	if want := []string{"/srv/api/bin/api", "--config=/etc/api.conf"}; !slices.Equal(cfg.Cmd, want) {
+		t.Errorf("Cmd = %q, want %q", cfg.Cmd, want)
+	}
+	if cfg.Cwd != "/srv/api" || cfg.Umask != 0 || cfg.Env["MODE"] != "prod" {
+		t.Errorf("Cwd = %q, Umask = %o, Env = %v", cfg.Cwd, cfg.Umask, cfg.Env)
+	}
+	if cfg.Chpst == nil || cfg.Chpst.User != "api" || cfg.ChpstPath != "setuidgid" {
+		t.Errorf("Chpst = %+v via %q, want user api via setuidgid", cfg.Chpst, cfg.ChpstPath)
+	}
+	if rs.EnvDir != "/etc/api/env" || len(rs.Unparsed) != 0 {
+		t.Errorf("EnvDir = %q, Unparsed = %q", rs.EnvDir, rs.Unparsed)
+	}
+
+	rs, err = ParseRunScript(strings.NewReader("#!/bin/sh\n[ -f /etc/default/x ] && . /etc/default/x\nexec chpst -v -b x /bin/x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"[ -f /etc/default/x ] && . /etc/default/x", "chpst -v", "chpst -b x"}; !slices.Equal(rs.Unparsed, want) {
+		t.Errorf("Unparsed = %q, want %q", rs.Unparsed, want)
+	}
+
+	// Combined options are decoded like getopt; an unknown one leaves the line unparsed
+	rs, err = ParseRunScript(strings.NewReader("#!/bin/sh\nexec chpst -vP -uapi -n -5 /bin/x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := rs.Builder.Config(); cfg.Chpst == nil || cfg.Chpst.User != "api" || cfg.Chpst.Nice != -5 {
+		t.Errorf("Chpst = %+v, want user api and nice -5", cfg.Chpst)
+	}
+	if want := []string{"chpst -v", "chpst -P"}; !slices.Equal(rs.Unparsed, want) {
+		t.Errorf("Unparsed = %q, want %q", rs.Unparsed, want)
+	}
+	if _, err := ParseRunScript(strings.NewReader("exec chpst -X /bin/x\n")); err == nil {
+		t.Error("ParseRunScript accepted an unknown chpst option")
+	}
+
+	// Continuation lines are joined, and only the builder's sleep is a schedule
+	script = "#!/bin/sh\nsleep 5\nsleep 60 || exit 1\nexec chpst \\\n  -u api \\\n  /bin/x --flag\n"
+	rs, err = ParseRunScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = rs.Builder.Config()
+	if want := []string{"/bin/x", "--flag"}; !slices.Equal(cfg.Cmd, want) {
+		t.Errorf("Cmd = %q, want %q", cfg.Cmd, want)
+	}
+	if cfg.Chpst == nil || cfg.Chpst.User != "api" {
+		t.Errorf("Chpst = %+v, want user api", cfg.Chpst)
+	}
+	if cfg.Schedule == nil || cfg.Schedule.Every != time.Minute {
+		t.Errorf("Schedule = %+v, want every minute", cfg.Schedule)
+	}
+	if want := []string{"sleep 5"}; !slices.Equal(rs.Unparsed, want) {
+		t.Errorf("Unparsed = %q, want %q", rs.Unparsed, want)
+	}
+
+	for _, bad := range []string{"#!/bin/sh\necho hi\n", "exec 'unterminated\n"} {
+		if _, err := ParseRunScript(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseRunScript(%q) succeeded", bad)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package svcmgr
 
 import (
+	"fmt"
 	"io/fs"
+	"strings"
 	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
 )
 
 // Runit directory and file constants
@@ -16,6 +20,14 @@ const (
 	// StatusFile is the binary status file name
 	StatusFile = "status"
 
+	// OkFile is the FIFO the supervisor keeps open for reading while it runs
+	// (runit and daemontools only)
+	OkFile = "ok"
+
+	// LockFile is the file the supervisor holds an exclusive lock on while
+	// it runs
+	LockFile = "lock"
+
 	// StatusFileSize is the exact size of the binary status record in bytes
 	// Reference: https://github.com/g-pape/runit/blob/master/src/sv.c#L53
 	// char svstatus[20];
@@ -56,6 +68,18 @@ const (
 
 	// DefaultSvPath is the default path to the sv binary (for fallback mode)
 	DefaultSvPath = "sv"
+
+	// DefaultSvstatPath is the default path to the daemontools svstat binary (for fallback mode)
+	DefaultSvstatPath = "svstat"
+
+	// DefaultS6SvstatPath is the default path to the s6-svstat binary (for fallback mode)
+	DefaultS6SvstatPath = "s6-svstat"
+
+	// DefaultRunconPath is the default path to runcon, used to set SELinux contexts
+	DefaultRunconPath = "runcon"
+
+	// DefaultAaExecPath is the default path to aa-exec, used to set AppArmor profiles
+	DefaultAaExecPath = "aa-exec"
 )
 
 // File modes
@@ -179,6 +203,47 @@ func (op Operation) String() string {
 	}
 }
 
+// ParseOperation parses an operation name as returned by String, also
+// accepting the aliases "start", "stop" and "continue"
+func ParseOperation(s string) (Operation, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case opUpStr, "start":
+		return OpUp, nil
+	case opOnceStr:
+		return OpOnce, nil
+	case opDownStr, "stop":
+		return OpDown, nil
+	case opTermStr:
+		return OpTerm, nil
+	case opInterruptStr:
+		return OpInterrupt, nil
+	case opHUPStr:
+		return OpHUP, nil
+	case opAlarmStr:
+		return OpAlarm, nil
+	case opQuitStr:
+		return OpQuit, nil
+	case opKillStr:
+		return OpKill, nil
+	case opPauseStr:
+		return OpPause, nil
+	case opContStr, "continue":
+		return OpCont, nil
+	case opUSR1Str:
+		return OpUSR1, nil
+	case opUSR2Str:
+		return OpUSR2, nil
+	case opExitStr:
+		return OpExit, nil
+	case opStatusStr:
+		return OpStatus, nil
+	case opRestartStr:
+		return OpRestart, nil
+	default:
+		return OpUnknown, fmt.Errorf("unknown operation: %q", s)
+	}
+}
+
 // Byte returns the control byte for this operation
 func (op Operation) Byte() byte {
 	switch op {
@@ -221,8 +286,8 @@ const (
 	// Reference: https://github.com/g-pape/runit/blob/master/src/tai.h#L12
 	// #define tai_unix(t,u) ((void) ((t)->x = 4611686018427387914ULL + (uint64) (u)))
 	// This value is 2^62 + 10 seconds (TAI is 10 seconds ahead of UTC at Unix epoch)
-	// Calculated as: (1 << 62) + 10
-	TAI64Base = uint64(1<<62) + 10 // 4611686018427387914
+	// Calculated as: (1 << 62) + 10; see package tai64
+	TAI64Base = tai64.UnixEpoch // 4611686018427387914
 )
 
 // DefaultUmask is the default umask for created files
@@ -0,0 +1,223 @@
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// runit-init file constants
+const (
+	// DefaultRunitDir is the directory holding runit's stage scripts and control files
+	DefaultRunitDir = "/etc/runit"
+
+	// DefaultProcDir is the mount point of the proc filesystem
+	DefaultProcDir = "/proc"
+
+	// RunitStopitFile is the file whose executable bit tells runit to enter stage 3
+	RunitStopitFile = "stopit"
+
+	// RunitRebootFile is the file whose executable bit makes stage 3 reboot instead of halt
+	RunitRebootFile = "reboot"
+)
+
+// RunitStage identifies one of runit's three boot stages
+type RunitStage int
+
+const (
+	// RunitStageUnknown indicates the stage could not be determined
+	RunitStageUnknown RunitStage = iota
+	// RunitStage1 is one-time system initialization (/etc/runit/1)
+	RunitStage1
+	// RunitStage2 is normal operation, usually running runsvdir (/etc/runit/2)
+	RunitStage2
+	// RunitStage3 is system shutdown (/etc/runit/3)
+	RunitStage3
+)
+
+// String returns the stage number, or "unknown"
+func (s RunitStage) String() string {
+	if s < RunitStage1 || s > RunitStage3 {
+		return "unknown"
+	}
+	return strconv.Itoa(int(s))
+}
+
+// RunitInit controls runit running as PID 1, following runit-init(8) semantics
+type RunitInit struct {
+	// Dir is the runit directory containing the 1, 2, 3, stopit and reboot files
+	Dir string
+
+	// ProcDir is the proc filesystem used to find the running stage
+	ProcDir string
+
+	// PID is the process ID of runit; it is 1 when runit is the init system
+	PID int
+}
+
+// NewRunitInit returns a RunitInit for the standard /etc/runit layout
+func NewRunitInit() *RunitInit {
+	return &RunitInit{
+		Dir:     DefaultRunitDir,
+		ProcDir: DefaultProcDir,
+		PID:     1,
+	}
+}
+
+// ScriptPath returns the path of the script run for stage
+func (ri *RunitInit) ScriptPath(stage RunitStage) (string, error) {
+	if stage < RunitStage1 || stage > RunitStage3 {
+		return "", fmt.Errorf("invalid runit stage: %d", stage)
+	}
+	return filepath.Join(ri.Dir, stage.String()), nil
+}
+
+// ReadScript returns the contents of the script for stage
+func (ri *RunitInit) ReadScript(stage RunitStage) ([]byte, error) {
+	path, err := ri.ScriptPath(stage)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// WriteScript atomically replaces the script for stage with an executable file
+func (ri *RunitInit) WriteScript(stage RunitStage, content []byte) error {
+	path, err := ri.ScriptPath(stage)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ri.Dir, 0o755); err != nil {
+		return fmt.Errorf("creating runit dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o755); err != nil {
+		return fmt.Errorf("writing stage %s script: %w", stage, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("installing stage %s script: %w", stage, err)
+	}
+	return nil
+}
+
+// Halt asks runit to enter stage 3 and halt the system, like runit-init 0
+func (ri *RunitInit) Halt(ctx context.Context) error {
+	return ri.shutdown(ctx, false)
+}
+
+// Reboot asks runit to enter stage 3 and reboot the system, like runit-init 6
+func (ri *RunitInit) Reboot(ctx context.Context) error {
+	return ri.shutdown(ctx, true)
+}
+
+// shutdown marks stopit (and optionally reboot) executable and signals runit with SIGCONT
+func (ri *RunitInit) shutdown(ctx context.Context, reboot bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rebootMode := os.FileMode(0)
+	if reboot {
+		rebootMode = 0o100
+	}
+	if err := ri.setMode(RunitRebootFile, rebootMode); err != nil {
+		return err
+	}
+	if err := ri.setMode(RunitStopitFile, 0o100); err != nil {
+		return err
+	}
+
+	if err := syscall.Kill(ri.PID, syscall.SIGCONT); err != nil {
+		return fmt.Errorf("signaling runit (pid %d): %w", ri.PID, err)
+	}
+	return nil
+}
+
+// setMode creates the named control file if needed and sets its permissions
+func (ri *RunitInit) setMode(name string, mode os.FileMode) error {
+	path := filepath.Join(ri.Dir, name)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, mode)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	_ = file.Close()
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", path, err)
+	}
+	return nil
+}
+
+// ShutdownPending reports whether stopit is executable, meaning runit will
+// enter stage 3 on its next SIGCONT
+func (ri *RunitInit) ShutdownPending() (bool, error) {
+	info, err := os.Stat(filepath.Join(ri.Dir, RunitStopitFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.Mode()&0o100 != 0, nil
+}
+
+// Stage returns the stage runit is currently executing by looking for a
+// child of runit running one of the stage scripts. A runsvdir child is
+// stage 2, whose script usually execs it.
+func (ri *RunitInit) Stage() (RunitStage, error) {
+	entries, err := os.ReadDir(ri.ProcDir)
+	if err != nil {
+		return RunitStageUnknown, fmt.Errorf("reading %s: %w", ri.ProcDir, err)
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, err := ri.parentPID(pid); err != nil || ppid != ri.PID {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join(ri.ProcDir, entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+		args := bytes.Split(cmdline, []byte{0})
+		for _, arg := range args {
+			if stage := ri.stageOf(string(arg)); stage != RunitStageUnknown {
+				return stage, nil
+			}
+		}
+		if filepath.Base(string(args[0])) == "runsvdir" {
+			return RunitStage2, nil
+		}
+	}
+
+	return RunitStageUnknown, nil
+}
+
+// stageOf returns the stage whose script is at path
+func (ri *RunitInit) stageOf(path string) RunitStage {
+	for stage := RunitStage1; stage <= RunitStage3; stage++ {
+		if path == filepath.Join(ri.Dir, stage.String()) {
+			return stage
+		}
+	}
+	return RunitStageUnknown
+}
+
+// parentPID reads the parent process ID from /proc/<pid>/stat
+func (ri *RunitInit) parentPID(pid int) (int, error) {
+	_, fields, err := readProcStat(ri.ProcDir, pid)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(fields[procStatPPID])
+}
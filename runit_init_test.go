@@ -0,0 +1,131 @@
+package svcmgr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFakeProc(t *testing.T, procDir string, pid, ppid int, args ...string) {
+	t.Helper()
+	dir := filepath.Join(procDir, fmt.Sprint(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stat := fmt.Sprintf("%d (sh (x)) S %d %d 0 0", pid, ppid, pid)
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmdline := []byte(strings.Join(args, "\x00"))
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), cmdline, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunitInitStage(t *testing.T) {
+	runitDir := t.TempDir()
+	tests := []struct {
+		name  string
+		procs func(t *testing.T, procDir string)
+		want  RunitStage
+	}{
+		{
+			name:  "none",
+			procs: func(t *testing.T, procDir string) {},
+			want:  RunitStageUnknown,
+		},
+		{
+			name: "stage 2",
+			procs: func(t *testing.T, procDir string) {
+				writeFakeProc(t, procDir, 10, 1, "/bin/sh", filepath.Join(runitDir, "2"))
+				writeFakeProc(t, procDir, 11, 10, "runsvdir", "/var/service")
+			},
+			want: RunitStage2,
+		},
+		{
+			name: "stage 2 script exec'd runsvdir",
+			procs: func(t *testing.T, procDir string) {
+				writeFakeProc(t, procDir, 10, 1, "/usr/bin/runsvdir", "-P", "/var/service", "log: ...")
+			},
+			want: RunitStage2,
+		},
+		{
+			name: "runsvdir not child of runit",
+			procs: func(t *testing.T, procDir string) {
+				writeFakeProc(t, procDir, 10, 7, "runsvdir", "/home/user/service")
+			},
+			want: RunitStageUnknown,
+		},
+		{
+			name: "stage 3 script not child of runit",
+			procs: func(t *testing.T, procDir string) {
+				writeFakeProc(t, procDir, 20, 5, "/bin/sh", filepath.Join(runitDir, "3"))
+			},
+			want: RunitStageUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procDir := t.TempDir()
+			tt.procs(t, procDir)
+
+			ri := &RunitInit{Dir: runitDir, ProcDir: procDir, PID: 1}
+			got, err := ri.Stage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Stage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunitInitScripts(t *testing.T) {
+	ri := &RunitInit{Dir: t.TempDir(), ProcDir: t.TempDir(), PID: 1}
+
+	content := []byte("#!/bin/sh\nexec runsvdir /var/service\n")
+	if err := ri.WriteScript(RunitStage2, content); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ri.ReadScript(RunitStage2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("ReadScript() = %q, want %q", got, content)
+	}
+
+	info, err := os.Stat(filepath.Join(ri.Dir, "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&0o111 == 0 {
+		t.Errorf("stage script mode %v is not executable", info.Mode())
+	}
+
+	if _, err := ri.ScriptPath(RunitStageUnknown); err == nil {
+		t.Error("expected error for invalid stage")
+	}
+}
+
+func TestRunitInitShutdownPending(t *testing.T) {
+	ri := &RunitInit{Dir: t.TempDir(), PID: 1}
+
+	pending, err := ri.ShutdownPending()
+	if err != nil || pending {
+		t.Fatalf("ShutdownPending() = %v, %v; want false, nil", pending, err)
+	}
+
+	if err := ri.setMode(RunitStopitFile, 0o100); err != nil {
+		t.Fatal(err)
+	}
+	pending, err = ri.ShutdownPending()
+	if err != nil || !pending {
+		t.Fatalf("ShutdownPending() = %v, %v; want true, nil", pending, err)
+	}
+}
@@ -0,0 +1,102 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runsvchdir file constants
+const (
+	// RunsvdirDir is the subdirectory of the runit directory holding the service directory sets
+	RunsvdirDir = "runsvdir"
+
+	// RunsvdirCurrent is the symlink runsvdir follows to the active service directory set
+	RunsvdirCurrent = "current"
+
+	// RunsvdirPrevious is the symlink to the previously active service directory set
+	RunsvdirPrevious = "previous"
+)
+
+// runsvdirPath returns the path of name inside the runsvdir directory
+func (ri *RunitInit) runsvdirPath(name string) string {
+	return filepath.Join(ri.Dir, RunsvdirDir, name)
+}
+
+// CurrentServiceDir returns the name of the active service directory set
+// (the target of /etc/runit/runsvdir/current)
+func (ri *RunitInit) CurrentServiceDir() (string, error) {
+	return os.Readlink(ri.runsvdirPath(RunsvdirCurrent))
+}
+
+// PreviousServiceDir returns the name of the previously active service directory set
+func (ri *RunitInit) PreviousServiceDir() (string, error) {
+	return os.Readlink(ri.runsvdirPath(RunsvdirPrevious))
+}
+
+// ChangeServiceDir switches the active service directory set to name, like
+// runsvchdir(8). The new directory must exist inside /etc/runit/runsvdir.
+// The current and previous links are replaced with atomic renames; if the
+// switch fails part way, the original links are restored.
+func (ri *RunitInit) ChangeServiceDir(name string) error {
+	if name == "" || name == RunsvdirCurrent || name == RunsvdirPrevious || filepath.Base(name) != name {
+		return fmt.Errorf("invalid service directory name: %q", name)
+	}
+
+	info, err := os.Stat(ri.runsvdirPath(name))
+	if err != nil {
+		return fmt.Errorf("checking service directory %s: %w", name, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("service directory %s is not a directory", name)
+	}
+
+	current, err := ri.CurrentServiceDir()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading current service directory: %w", err)
+	}
+	if current == name {
+		return nil
+	}
+
+	if err := ri.replaceLink(RunsvdirCurrent, name); err != nil {
+		return err
+	}
+	if current == "" {
+		return nil
+	}
+	if err := ri.replaceLink(RunsvdirPrevious, current); err != nil {
+		// Roll back so runsvdir keeps its old view and previous stays consistent
+		if rbErr := ri.replaceLink(RunsvdirCurrent, current); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %w)", err, rbErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// RollbackServiceDir switches back to the previously active service directory set
+func (ri *RunitInit) RollbackServiceDir() error {
+	previous, err := ri.PreviousServiceDir()
+	if err != nil {
+		return fmt.Errorf("reading previous service directory: %w", err)
+	}
+	return ri.ChangeServiceDir(previous)
+}
+
+// replaceLink atomically points the named link at target via a temporary link and rename
+func (ri *RunitInit) replaceLink(name, target string) error {
+	link := ri.runsvdirPath(name)
+	tmp := link + ".new"
+
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("creating %s link: %w", name, err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replacing %s link: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunitInitChangeServiceDir(t *testing.T) {
+	ri := &RunitInit{Dir: t.TempDir(), PID: 1}
+	for _, name := range []string{"default", "single"} {
+		if err := os.MkdirAll(filepath.Join(ri.Dir, RunsvdirDir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ri.ChangeServiceDir("default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ri.ChangeServiceDir("single"); err != nil {
+		t.Fatal(err)
+	}
+
+	if current, _ := ri.CurrentServiceDir(); current != "single" {
+		t.Errorf("current = %q, want single", current)
+	}
+	if previous, _ := ri.PreviousServiceDir(); previous != "default" {
+		t.Errorf("previous = %q, want default", previous)
+	}
+
+	if err := ri.RollbackServiceDir(); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := ri.CurrentServiceDir(); current != "default" {
+		t.Errorf("current after rollback = %q, want default", current)
+	}
+
+	for _, name := range []string{"missing", "../default", "", RunsvdirCurrent} {
+		if err := ri.ChangeServiceDir(name); err == nil {
+			t.Errorf("ChangeServiceDir(%q) succeeded, want error", name)
+		}
+	}
+	if current, _ := ri.CurrentServiceDir(); current != "default" {
+		t.Errorf("current after rejected changes = %q, want default", current)
+	}
+}
@@ -0,0 +1,177 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+)
+
+// Sources of an S6Install
+const (
+	// S6SourceHeader means the version came from s6's config.h
+	S6SourceHeader = "header"
+	// S6SourcePackage means the version came from the package manager
+	S6SourcePackage = "package"
+	// S6SourceScanDir means only the status layout was found, from the
+	// status files of a scan directory
+	S6SourceScanDir = "scandir"
+)
+
+// DefaultS6PackageQueries are the package manager commands S6Detector runs
+// to find the installed s6 release; the first version number in the
+// output of the first that succeeds is taken
+var DefaultS6PackageQueries = [][]string{
+	{"dpkg-query", "-W", "-f=${Version}", "s6"},
+	{"rpm", "-q", "--qf", "%{VERSION}", "s6"},
+	{"apk", "list", "--installed", "s6"},
+	{"pacman", "-Q", "s6"},
+	{"xbps-query", "-p", "pkgver", "s6"},
+}
+
+// errS6NotDetected is returned by S6Detector.Detect when no source answers
+var errS6NotDetected = errors.New("s6 installation not detected")
+
+// s6VersionPattern matches a release number in package manager output
+var s6VersionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)*`)
+
+// S6Install describes the installed s6, as found by DetectS6. It is a
+// ClientOption selecting s6 with the detected status layout, so the parser
+// is chosen up front rather than inferred from each file's size.
+//
+// Example:
+//
+//	install, err := svcmgr.DetectS6(ctx, "/run/service")
+//	if err != nil {
+//		return err
+//	}
+//	client, err := svcmgr.NewClient("/run/service/web", install)
+type S6Install struct {
+	// Version is the release number; empty when only the layout was found
+	Version string
+	// Format is the status layout the release writes, or the one its
+	// status files were seen to have when they disagree
+	Format S6FormatVersion
+	// Source tells where the installation was found: S6SourceHeader,
+	// S6SourcePackage or S6SourceScanDir
+	Source string
+}
+
+// applyClient makes an S6Install usable as a ClientOption
+func (i S6Install) applyClient(c *clientConfig) {
+	c.serviceType = ServiceTypeS6
+	c.s6Format = i.Format
+}
+
+// Capabilities returns what s6 supports with the detected status layout
+func (i S6Install) Capabilities() Capabilities {
+	return s6Capabilities(ConfigS6(), i.Format)
+}
+
+// String describes the installation
+func (i S6Install) String() string {
+	if i.Version == "" {
+		return fmt.Sprintf("s6 (%s status layout, from %s)", i.Format, i.Source)
+	}
+	return fmt.Sprintf("s6 %s (%s status layout, from %s)", i.Version, i.Format, i.Source)
+}
+
+// S6Detector finds the installed s6 release
+type S6Detector struct {
+	// ConfigHeader is s6's config.h; empty means DefaultS6ConfigHeader
+	ConfigHeader string
+	// PackageQueries are the package manager commands to try; nil means
+	// DefaultS6PackageQueries
+	PackageQueries [][]string
+	// ScanDir, when set, is searched for status files whose size gives
+	// away the layout when no version is found
+	ScanDir string
+}
+
+// DetectS6 finds the installed s6 release with the default sources,
+// falling back to the status files in scanDir
+func DetectS6(ctx context.Context, scanDir string) (S6Install, error) {
+	return (&S6Detector{ScanDir: scanDir}).Detect(ctx)
+}
+
+// Detect tries, in order, the config.h header, the package manager and the
+// status files of ScanDir. When ScanDir holds status files whose size
+// contradicts the layout of the version found, as with a stale header or a
+// patched package, the observed layout wins: it is what the running
+// supervisors write.
+func (d *S6Detector) Detect(ctx context.Context) (S6Install, error) {
+	observed := S6FormatUnknown
+	if d.ScanDir != "" {
+		observed = scanDirS6Format(d.ScanDir)
+	}
+	install, err := d.detectVersion(ctx)
+	switch {
+	case err == nil:
+		if observed != S6FormatUnknown {
+			install.Format = observed
+		}
+		return install, nil
+	case ctx.Err() != nil:
+		return S6Install{}, err
+	case observed != S6FormatUnknown:
+		return S6Install{Format: observed, Source: S6SourceScanDir}, nil
+	}
+	return S6Install{}, errS6NotDetected
+}
+
+// detectVersion finds the s6 release from the config.h header or the
+// package manager
+func (d *S6Detector) detectVersion(ctx context.Context) (S6Install, error) {
+	if version, err := ReadS6Version(d.ConfigHeader); err == nil {
+		if format, err := S6FormatForVersion(version); err == nil {
+			return S6Install{Version: version, Format: format, Source: S6SourceHeader}, nil
+		}
+	}
+
+	queries := d.PackageQueries
+	if queries == nil {
+		queries = DefaultS6PackageQueries
+	}
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return S6Install{}, err
+		}
+		if _, err := exec.LookPath(query[0]); err != nil {
+			continue
+		}
+		out, err := exec.CommandContext(ctx, query[0], query[1:]...).Output()
+		if err != nil {
+			continue
+		}
+		version := s6VersionPattern.FindString(string(out))
+		if format, err := S6FormatForVersion(version); err == nil {
+			return S6Install{Version: version, Format: format, Source: S6SourcePackage}, nil
+		}
+	}
+	return S6Install{}, errS6NotDetected
+}
+
+// scanDirS6Format returns the layout of the first s6 status file of a known
+// size among the services of scanDir
+func scanDirS6Format(scanDir string) S6FormatVersion {
+	entries, err := os.ReadDir(scanDir)
+	if err != nil {
+		return S6FormatUnknown
+	}
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(scanDir, entry.Name(), SuperviseDir, StatusFile))
+		if err != nil {
+			continue
+		}
+		switch info.Size() {
+		case S6StatusSizePre220:
+			return S6FormatPre220
+		case S6StatusSizeCurrent:
+			return S6FormatCurrent
+		}
+	}
+	return S6FormatUnknown
+}
@@ -0,0 +1,96 @@
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestS6DetectorDetect(t *testing.T) {
+	dir := t.TempDir()
+	header := filepath.Join(dir, "config.h")
+	if err := os.WriteFile(header, []byte("#define S6_VERSION \"2.13.1.0\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scanDir := filepath.Join(dir, "service")
+	supervise := filepath.Join(scanDir, "web", SuperviseDir)
+	if err := os.MkdirAll(supervise, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(supervise, StatusFile), makeS6StatusPre220(0, 0), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.h")
+	failing := [][]string{{"sh", "-c", "exit 1"}}
+
+	tests := []struct {
+		name     string
+		detector S6Detector
+		want     S6Install
+		wantErr  bool
+	}{
+		{
+			name:     "header",
+			detector: S6Detector{ConfigHeader: header, PackageQueries: failing},
+			want:     S6Install{Version: "2.13.1.0", Format: S6FormatCurrent, Source: S6SourceHeader},
+		},
+		{
+			name:     "header contradicted by status files",
+			detector: S6Detector{ConfigHeader: header, PackageQueries: failing, ScanDir: scanDir},
+			want:     S6Install{Version: "2.13.1.0", Format: S6FormatPre220, Source: S6SourceHeader},
+		},
+		{
+			name:     "package",
+			detector: S6Detector{ConfigHeader: missing, PackageQueries: append(failing, []string{"sh", "-c", "echo 's6 2.10.0.3-1'"})},
+			want:     S6Install{Version: "2.10.0.3", Format: S6FormatPre220, Source: S6SourcePackage},
+		},
+		{
+			name:     "scandir",
+			detector: S6Detector{ConfigHeader: missing, PackageQueries: failing, ScanDir: scanDir},
+			want:     S6Install{Format: S6FormatPre220, Source: S6SourceScanDir},
+		},
+		{
+			name:     "nothing",
+			detector: S6Detector{ConfigHeader: missing, PackageQueries: failing, ScanDir: dir},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.detector.Detect(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Detect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestS6InstallClientOption(t *testing.T) {
+	install := S6Install{Version: "2.10.0.3", Format: S6FormatPre220, Source: S6SourceHeader}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(dir, install)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s6, ok := client.(*ClientS6)
+	if !ok {
+		t.Fatalf("NewClient() = %T, want *ClientS6", client)
+	}
+	if s6.S6Format != S6FormatPre220 {
+		t.Errorf("S6Format = %v, want %v", s6.S6Format, S6FormatPre220)
+	}
+	if s6.Capabilities().WantState || install.Capabilities().WantState {
+		t.Error("WantState reported for the pre-2.11.0 layout")
+	}
+	current := S6Install{Version: "2.13.1.0", Format: S6FormatCurrent, Source: S6SourceHeader}
+	if !current.Capabilities().WantState {
+		t.Error("WantState not reported for the current layout")
+	}
+}
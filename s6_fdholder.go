@@ -0,0 +1,269 @@
+package svcmgr
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+)
+
+// s6-fdholderd protocol constants
+const (
+	// S6FDHolderMaxIDLen is the longest identifier s6-fdholderd accepts
+	S6FDHolderMaxIDLen = 255
+
+	// s6FDHolderHeaderSize is the skalibs unixmessage header: 4-byte length, 2-byte fd count
+	s6FDHolderHeaderSize = 6
+
+	// s6FDHolderDefaultLifetime is used when Store is given no expiry
+	s6FDHolderDefaultLifetime = 100 * 365 * 24 * time.Hour
+
+	s6FDHolderStore    = 'S'
+	s6FDHolderRetrieve = 'R'
+	s6FDHolderDelete   = 'D'
+)
+
+// FDHolderClient stores and retrieves file descriptors in an s6-fdholderd
+// daemon over its unix socket, like s6-fdholder-store and s6-fdholder-retrieve.
+// Socket-activated services use it to keep listeners open across restarts.
+type FDHolderClient struct {
+	// SocketPath is the path to the s6-fdholderd unix socket
+	SocketPath string
+
+	// DialTimeout is the timeout for connecting to the daemon
+	DialTimeout time.Duration
+
+	// ReadTimeout is the timeout for reading the daemon's answer
+	ReadTimeout time.Duration
+
+	// WriteTimeout is the timeout for sending a request
+	WriteTimeout time.Duration
+}
+
+// NewFDHolderClient creates an FDHolderClient for the daemon listening on socketPath
+func NewFDHolderClient(socketPath string) *FDHolderClient {
+	return &FDHolderClient{
+		SocketPath:   socketPath,
+		DialTimeout:  DefaultDialTimeout,
+		ReadTimeout:  DefaultReadTimeout,
+		WriteTimeout: DefaultWriteTimeout,
+	}
+}
+
+// Store hands a duplicate of file to the daemon under id. The daemon closes
+// it at expires; a zero expires keeps it until it is deleted or retrieved with delete.
+func (c *FDHolderClient) Store(ctx context.Context, id string, file *os.File, expires time.Time) error {
+	if err := checkFDHolderID(id); err != nil {
+		return err
+	}
+	if expires.IsZero() {
+		expires = time.Now().Add(s6FDHolderDefaultLifetime)
+	}
+
+	msg := make([]byte, 0, 2+12+len(id)+1)
+	msg = append(msg, s6FDHolderStore)
+	msg = appendTAIN(msg, expires)
+	msg = append(msg, byte(len(id)))
+	msg = append(msg, id...)
+	msg = append(msg, 0)
+
+	_, err := c.roundTrip(ctx, msg, []int{int(file.Fd())})
+	return err
+}
+
+// Retrieve returns the descriptor stored under id, removing it from the
+// daemon when remove is true
+func (c *FDHolderClient) Retrieve(ctx context.Context, id string, remove bool) (*os.File, error) {
+	if err := checkFDHolderID(id); err != nil {
+		return nil, err
+	}
+
+	msg := make([]byte, 0, 3+len(id)+1)
+	msg = append(msg, s6FDHolderRetrieve, 0, byte(len(id)))
+	if remove {
+		msg[1] = 1
+	}
+	msg = append(msg, id...)
+	msg = append(msg, 0)
+
+	fds, err := c.roundTrip(ctx, msg, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(fds) != 1 {
+		for _, fd := range fds {
+			_ = syscall.Close(fd)
+		}
+		return nil, fmt.Errorf("s6-fdholderd returned %d descriptors for %q", len(fds), id)
+	}
+	return os.NewFile(uintptr(fds[0]), id), nil
+}
+
+// Delete removes the descriptor stored under id
+func (c *FDHolderClient) Delete(ctx context.Context, id string) error {
+	if err := checkFDHolderID(id); err != nil {
+		return err
+	}
+
+	msg := make([]byte, 0, 2+len(id)+1)
+	msg = append(msg, s6FDHolderDelete, byte(len(id)))
+	msg = append(msg, id...)
+	msg = append(msg, 0)
+
+	_, err := c.roundTrip(ctx, msg, nil)
+	return err
+}
+
+// StoreListener stores the socket underlying a TCP or unix listener
+func (c *FDHolderClient) StoreListener(ctx context.Context, id string, l net.Listener, expires time.Time) error {
+	filer, ok := l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return fmt.Errorf("listener %T does not expose its file descriptor", l)
+	}
+	file, err := filer.File()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return c.Store(ctx, id, file, expires)
+}
+
+// RetrieveListener retrieves a stored listening socket without removing it
+func (c *FDHolderClient) RetrieveListener(ctx context.Context, id string) (net.Listener, error) {
+	file, err := c.Retrieve(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return net.FileListener(file)
+}
+
+// roundTrip sends one request with optional descriptors and returns the descriptors in the answer.
+// The answer's first byte is 0 on success or an errno value. Each phase is
+// bounded by the earlier of ctx's deadline and its timeout, and cancelling
+// ctx aborts the exchange.
+func (c *FDHolderClient) roundTrip(ctx context.Context, msg []byte, fds []int) ([]int, error) {
+	dialer := net.Dialer{Timeout: c.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", c.SocketPath)
+	if err != nil {
+		return nil, &OpError{Op: OpUnknown, Path: c.SocketPath, Err: err}
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer func() { _ = stop() }()
+	uconn := conn.(*net.UnixConn)
+
+	// fail reports ctx's error when cancellation closed the connection
+	fail := func(err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		return &OpError{Op: OpUnknown, Path: c.SocketPath, Err: err}
+	}
+
+	_ = uconn.SetWriteDeadline(fdholderDeadline(ctx, c.WriteTimeout))
+	frame := make([]byte, s6FDHolderHeaderSize, s6FDHolderHeaderSize+len(msg))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(msg)))
+	binary.BigEndian.PutUint16(frame[4:6], uint16(len(fds)))
+	frame = append(frame, msg...)
+
+	var oob []byte
+	if len(fds) > 0 {
+		oob = syscall.UnixRights(fds...)
+	}
+	if _, _, err := uconn.WriteMsgUnix(frame, oob, nil); err != nil {
+		return nil, fail(err)
+	}
+
+	_ = uconn.SetReadDeadline(fdholderDeadline(ctx, c.ReadTimeout))
+	answer, got, err := readFDHolderMessage(uconn)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if len(answer) == 0 {
+		return nil, &OpError{Op: OpUnknown, Path: c.SocketPath, Err: io.ErrUnexpectedEOF}
+	}
+	if answer[0] != 0 {
+		for _, fd := range got {
+			_ = syscall.Close(fd)
+		}
+		return nil, &OpError{Op: OpUnknown, Path: c.SocketPath, Err: syscall.Errno(answer[0])}
+	}
+	return got, nil
+}
+
+// fdholderDeadline returns the earlier of ctx's deadline and timeout from
+// now, or the zero time when neither is set
+func fdholderDeadline(ctx context.Context, timeout time.Duration) time.Time {
+	deadline, ok := ctx.Deadline()
+	if timeout > 0 {
+		if d := time.Now().Add(timeout); !ok || d.Before(deadline) {
+			return d
+		}
+	}
+	return deadline
+}
+
+// readFDHolderMessage reads one framed message and any descriptors passed with it
+func readFDHolderMessage(conn *net.UnixConn) ([]byte, []int, error) {
+	header := make([]byte, s6FDHolderHeaderSize)
+	oob := make([]byte, syscall.CmsgSpace(4*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(header, oob)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n < s6FDHolderHeaderSize {
+		if _, err := io.ReadFull(conn, header[n:]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var fds []int
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range msgs {
+			rights, err := syscall.ParseUnixRights(&m)
+			if err != nil {
+				return nil, nil, err
+			}
+			fds = append(fds, rights...)
+		}
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		for _, fd := range fds {
+			_ = syscall.Close(fd)
+		}
+		return nil, nil, err
+	}
+	return body, fds, nil
+}
+
+// appendTAIN appends t as a 12-byte TAI64N label
+func appendTAIN(b []byte, t time.Time) []byte {
+	return tai64.FromTime(t).Append(b)
+}
+
+// checkFDHolderID validates an identifier against s6-fdholderd's limits
+func checkFDHolderID(id string) error {
+	if id == "" || len(id) > S6FDHolderMaxIDLen {
+		return fmt.Errorf("invalid fd holder id length %d", len(id))
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] == 0 {
+			return errors.New("fd holder id contains NUL byte")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeFDHolder implements the s6-fdholderd store/retrieve/delete protocol in memory
+func fakeFDHolder(t *testing.T) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "fdholder.sock")
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	held := map[string]int{}
+	go func() {
+		for {
+			conn, err := ln.AcceptUnix()
+			if err != nil {
+				return
+			}
+			msg, fds, err := readFDHolderMessage(conn)
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+
+			var reply []int
+			code := byte(0)
+			switch msg[0] {
+			case s6FDHolderStore:
+				id := string(msg[14 : 14+int(msg[13])])
+				held[id] = fds[0]
+			case s6FDHolderRetrieve:
+				id := string(msg[3 : 3+int(msg[2])])
+				fd, ok := held[id]
+				if !ok {
+					code = byte(syscall.ENOENT)
+					break
+				}
+				reply = []int{fd}
+				if msg[1] == 1 {
+					delete(held, id)
+				}
+			case s6FDHolderDelete:
+				id := string(msg[2 : 2+int(msg[1])])
+				if _, ok := held[id]; !ok {
+					code = byte(syscall.ENOENT)
+				}
+				delete(held, id)
+			}
+
+			frame := make([]byte, s6FDHolderHeaderSize, s6FDHolderHeaderSize+1)
+			binary.BigEndian.PutUint32(frame[0:4], 1)
+			binary.BigEndian.PutUint16(frame[4:6], uint16(len(reply)))
+			frame = append(frame, code)
+			var oob []byte
+			if len(reply) > 0 {
+				oob = syscall.UnixRights(reply...)
+			}
+			_, _, _ = conn.WriteMsgUnix(frame, oob, nil)
+			_ = conn.Close()
+		}
+	}()
+
+	return socketPath
+}
+
+func TestFDHolderClientListener(t *testing.T) {
+	client := NewFDHolderClient(fakeFDHolder(t))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	if err := client.StoreListener(ctx, "tcp:web", ln, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	_ = ln.Close()
+
+	restored, err := client.RetrieveListener(ctx, "tcp:web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = restored.Close() }()
+	if restored.Addr().String() != addr {
+		t.Errorf("restored listener on %s, want %s", restored.Addr(), addr)
+	}
+
+	if err := client.Delete(ctx, "tcp:web"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Retrieve(ctx, "tcp:web", false)
+	if !errors.Is(err, syscall.ENOENT) {
+		t.Errorf("Retrieve after delete error = %v, want ENOENT", err)
+	}
+}
+
+func TestFDHolderClientInvalidID(t *testing.T) {
+	client := NewFDHolderClient(filepath.Join(t.TempDir(), "none"))
+	for _, id := range []string{"", string(make([]byte, S6FDHolderMaxIDLen+1)), "a\x00b"} {
+		if err := client.Store(context.Background(), id, os.Stdin, time.Time{}); err == nil {
+			t.Errorf("Store(%q) succeeded, want error", id)
+		}
+	}
+}
+
+func TestFDHolderClientContext(t *testing.T) {
+	// A daemon that accepts but never answers
+	socketPath := filepath.Join(t.TempDir(), "fdholder.sock")
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.AcceptUnix()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	client := NewFDHolderClient(socketPath)
+	client.ReadTimeout = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := client.Delete(ctx, "tcp:web"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Delete past deadline error = %v, want context.DeadlineExceeded", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := client.Delete(ctx, "tcp:web"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Delete after cancel error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Delete took %v, want the context to bound it", elapsed)
+	}
+}
@@ -0,0 +1,184 @@
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/axondata/go-svcmgr/internal/unix"
+)
+
+// s6-svscan control constants
+const (
+	// S6SvscanDir is the subdirectory of a scan directory holding s6-svscan's control FIFO
+	S6SvscanDir = ".s6-svscan"
+
+	// s6-svscanctl command characters
+	s6SvscanRescan = 'a'
+	s6SvscanAbort  = 'b'
+	s6SvscanQuit   = 't'
+)
+
+// ScannerClient controls the scanner process that supervises a scan directory:
+// s6-svscan through its control FIFO (like s6-svscanctl), or runsvdir and
+// svscan through signals and the directory modification time they poll.
+type ScannerClient struct {
+	// ScanDir is the canonical path to the scan directory
+	ScanDir string
+
+	// Type is the supervision system running the scanner
+	Type ServiceType
+
+	// PID is the scanner's process ID for signal-based control.
+	// When zero, it is looked up in ProcDir by command line.
+	PID int
+
+	// ProcDir is the proc filesystem used to find the scanner PID
+	ProcDir string
+}
+
+// NewScannerClient creates a ScannerClient for the scan directory of the given supervision system
+func NewScannerClient(scanDir string, serviceType ServiceType) (*ScannerClient, error) {
+	switch serviceType {
+	case ServiceTypeRunit, ServiceTypeDaemontools, ServiceTypeS6:
+	default:
+		return nil, fmt.Errorf("unsupported scanner type: %v", serviceType)
+	}
+
+	absPath, err := filepath.Abs(scanDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving scan dir: %w", err)
+	}
+	if info, err := os.Stat(absPath); err != nil {
+		return nil, fmt.Errorf("checking scan dir: %w", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("scan dir %s is not a directory", absPath)
+	}
+
+	return &ScannerClient{
+		ScanDir: absPath,
+		Type:    serviceType,
+		ProcDir: DefaultProcDir,
+	}, nil
+}
+
+// Rescan makes the scanner pick up added and removed service directories.
+// s6-svscan rescans immediately; runsvdir and svscan notice the updated
+// directory modification time on their next poll (every five seconds).
+func (sc *ScannerClient) Rescan(ctx context.Context) error {
+	if sc.Type == ServiceTypeS6 {
+		return sc.sendS6(ctx, s6SvscanRescan)
+	}
+	now := time.Now()
+	if err := os.Chtimes(sc.ScanDir, now, now); err != nil {
+		return fmt.Errorf("touching scan dir: %w", err)
+	}
+	return nil
+}
+
+// Quit stops the scanner after terminating all supervised services
+func (sc *ScannerClient) Quit(ctx context.Context) error {
+	switch sc.Type {
+	case ServiceTypeS6:
+		return sc.sendS6(ctx, s6SvscanQuit)
+	case ServiceTypeRunit:
+		// runsvdir sends TERM to every runsv on SIGHUP before exiting
+		return sc.signal(syscall.SIGHUP)
+	default:
+		// svscan has no way to stop its supervisors; callers must down them first
+		return sc.signal(syscall.SIGTERM)
+	}
+}
+
+// Abort stops the scanner immediately, leaving supervisors and services running
+func (sc *ScannerClient) Abort(ctx context.Context) error {
+	if sc.Type == ServiceTypeS6 {
+		return sc.sendS6(ctx, s6SvscanAbort)
+	}
+	return sc.signal(syscall.SIGTERM)
+}
+
+// sendS6 writes a command to s6-svscan's control FIFO
+func (sc *ScannerClient) sendS6(ctx context.Context, cmd byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	controlPath := filepath.Join(sc.ScanDir, S6SvscanDir, ControlFile)
+	file, err := os.OpenFile(controlPath, os.O_WRONLY|unix.ONonblock, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ENXIO) {
+			err = ErrControlNotReady
+		}
+		return &OpError{Op: OpUnknown, Path: controlPath, Err: err}
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.Write([]byte{cmd}); err != nil {
+		return &OpError{Op: OpUnknown, Path: controlPath, Err: err}
+	}
+	return nil
+}
+
+// signal delivers sig to the scanner process
+func (sc *ScannerClient) signal(sig syscall.Signal) error {
+	pid := sc.PID
+	if pid == 0 {
+		var err error
+		if pid, err = sc.findPID(); err != nil {
+			return err
+		}
+	}
+	if err := syscall.Kill(pid, sig); err != nil {
+		return fmt.Errorf("signaling scanner (pid %d): %w", pid, err)
+	}
+	return nil
+}
+
+// findPID locates a runsvdir or svscan process whose arguments include the scan directory
+func (sc *ScannerClient) findPID() (int, error) {
+	name := "runsvdir"
+	if sc.Type == ServiceTypeDaemontools {
+		name = "svscan"
+	}
+
+	entries, err := os.ReadDir(sc.ProcDir)
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %w", sc.ProcDir, err)
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(sc.ProcDir, entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+		args := bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0})
+		if filepath.Base(string(args[0])) != name {
+			continue
+		}
+		// Relative arguments are relative to the scanner's own working directory
+		cwd, _ := os.Readlink(filepath.Join(sc.ProcDir, entry.Name(), "cwd"))
+		for _, arg := range args[1:] {
+			dir := string(arg)
+			if !filepath.IsAbs(dir) {
+				if cwd == "" {
+					continue
+				}
+				dir = filepath.Join(cwd, dir)
+			}
+			if filepath.Clean(dir) == sc.ScanDir {
+				return pid, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no %s process found for %s", name, sc.ScanDir)
+}
@@ -0,0 +1,118 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestScannerClientS6Commands(t *testing.T) {
+	scanDir := t.TempDir()
+	controlDir := filepath.Join(scanDir, S6SvscanDir)
+	if err := os.MkdirAll(controlDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fifo := filepath.Join(controlDir, ControlFile)
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewScannerClient(scanDir, ServiceTypeS6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := sc.Rescan(ctx); !errors.Is(err, ErrControlNotReady) {
+		t.Fatalf("Rescan without reader error = %v, want ErrControlNotReady", err)
+	}
+
+	reader, err := os.OpenFile(fifo, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if err := sc.Rescan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Abort(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 8)
+	n, _ := reader.Read(buf)
+	if got := string(buf[:n]); got != "ab" {
+		t.Errorf("control FIFO received %q, want %q", got, "ab")
+	}
+}
+
+func TestScannerClientRescanTouchesDir(t *testing.T) {
+	scanDir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(scanDir, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewScannerClient(scanDir, ServiceTypeRunit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Rescan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(scanDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().After(old) {
+		t.Errorf("scan dir mtime %v not updated", info.ModTime())
+	}
+}
+
+func TestScannerClientFindPID(t *testing.T) {
+	scanDir := t.TempDir()
+	procDir := t.TempDir()
+	writeFakeProc(t, procDir, 42, 1, "/usr/bin/runsvdir", "-P", scanDir)
+	writeFakeProc(t, procDir, 43, 1, "/usr/bin/svscan", scanDir)
+
+	sc, err := NewScannerClient(scanDir, ServiceTypeRunit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.ProcDir = procDir
+
+	pid, err := sc.findPID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != 42 {
+		t.Errorf("findPID() = %d, want 42", pid)
+	}
+
+	// A relative argument is resolved against the scanner's cwd, not ours
+	sc.ProcDir = t.TempDir()
+	writeFakeProc(t, sc.ProcDir, 50, 1, "runsvdir", filepath.Base(scanDir))
+	writeFakeProc(t, sc.ProcDir, 51, 1, "runsvdir", filepath.Base(scanDir))
+	writeFakeProc(t, sc.ProcDir, 52, 1, "runsvdir", filepath.Base(scanDir))
+	if err := os.Symlink(t.TempDir(), filepath.Join(sc.ProcDir, "51", "cwd")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Dir(scanDir), filepath.Join(sc.ProcDir, "52", "cwd")); err != nil {
+		t.Fatal(err)
+	}
+	pid, err = sc.findPID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != 52 {
+		t.Errorf("findPID() = %d, want 52", pid)
+	}
+}
@@ -0,0 +1,297 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSnoozePath is the default path to the snooze binary used by
+// scheduled run scripts
+const DefaultSnoozePath = "snooze"
+
+// Schedule describes when a scheduled job runs. Either Every or the
+// cron-style calendar fields are used, not both.
+//
+// Calendar fields use cron syntax: "*", a number, a range ("1-5"), a step
+// ("*/15", "0-30/10") or a comma separated list of those. Empty fields
+// mean "*". Weekday is 0-7 with both 0 and 7 meaning Sunday.
+//
+// For runit, daemontools and s6 the job becomes a long-running service whose
+// run script waits for the next run (with snooze for calendar schedules, sleep
+// for intervals) and then execs the command; the supervisor restarts it once
+// the command exits. For systemd a oneshot service and a .timer are generated.
+type Schedule struct {
+	// Every runs the job at this interval, measured from the end of the previous run
+	Every time.Duration
+
+	// Minute is the cron minute field (0-59)
+	Minute string
+	// Hour is the cron hour field (0-23)
+	Hour string
+	// Day is the cron day of month field (1-31)
+	Day string
+	// Month is the cron month field (1-12)
+	Month string
+	// Weekday is the cron day of week field (0-7)
+	Weekday string
+
+	// RandomDelay delays each calendar run by a random amount up to this duration
+	RandomDelay time.Duration
+
+	// Persistent runs a calendar job missed while the system was down as soon
+	// as possible (systemd only)
+	Persistent bool
+}
+
+// Every returns a schedule running a job at the given interval
+func Every(d time.Duration) Schedule {
+	return Schedule{Every: d}
+}
+
+// ParseCron parses a five field cron expression ("minute hour day month weekday")
+func ParseCron(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression %q: want 5 fields, got %d", expr, len(fields))
+	}
+	s := Schedule{Minute: fields[0], Hour: fields[1], Day: fields[2], Month: fields[3], Weekday: fields[4]}
+	if err := s.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	return s, nil
+}
+
+// isCalendar reports whether any calendar field is set
+func (s Schedule) isCalendar() bool {
+	return s.Minute != "" || s.Hour != "" || s.Day != "" || s.Month != "" || s.Weekday != ""
+}
+
+// calendarFields describes the calendar fields and their ranges
+func (s Schedule) calendarFields() []cronField {
+	return []cronField{
+		{name: "minute", value: s.Minute, min: 0, max: 59},
+		{name: "hour", value: s.Hour, min: 0, max: 23},
+		{name: "day", value: s.Day, min: 1, max: 31},
+		{name: "month", value: s.Month, min: 1, max: 12},
+		{name: "weekday", value: s.Weekday, min: 0, max: 7},
+	}
+}
+
+// Validate checks that the schedule is complete and its fields are well formed
+func (s Schedule) Validate() error {
+	switch {
+	case s.Every < 0 || s.RandomDelay < 0:
+		return errors.New("schedule: negative duration")
+	case s.Every > 0 && s.isCalendar():
+		return errors.New("schedule: Every and calendar fields are mutually exclusive")
+	case s.Every > 0 && s.Every < time.Second:
+		return fmt.Errorf("schedule: interval %v is shorter than a second", s.Every)
+	case s.Every == 0 && !s.isCalendar():
+		return errors.New("schedule: neither Every nor calendar fields set")
+	}
+	for _, f := range s.calendarFields() {
+		if _, err := f.parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnCalendar returns the systemd calendar event equivalent to the calendar
+// fields (see systemd.time(7))
+func (s Schedule) OnCalendar() (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	if !s.isCalendar() {
+		return "", errors.New("schedule: not a calendar schedule")
+	}
+
+	fields := s.calendarFields()
+	conv := make([]string, len(fields))
+	for i, f := range fields {
+		items, err := f.parse()
+		if err != nil {
+			return "", err
+		}
+		conv[i], err = f.systemd(items)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	event := fmt.Sprintf("*-%s-%s %s:%s:00", conv[3], conv[2], conv[1], conv[0])
+	if conv[4] != "*" {
+		event = conv[4] + " " + event
+	}
+	return event, nil
+}
+
+// snoozeArgs returns the snooze(1) options equivalent to the calendar fields
+func (s Schedule) snoozeArgs() ([]string, error) {
+	flags := []string{"-M", "-H", "-d", "-m", "-w"}
+	var args []string
+	for i, f := range s.calendarFields() {
+		items, err := f.parse()
+		if err != nil {
+			return nil, err
+		}
+		value := f.snooze(items)
+		// snooze's minute and hour default to 0, so those are always passed
+		if value == "*" && i > 1 {
+			continue
+		}
+		args = append(args, flags[i]+value)
+	}
+	if s.RandomDelay > 0 {
+		args = append(args, "-R"+strconv.FormatInt(durationSeconds(s.RandomDelay), 10))
+	}
+	return args, nil
+}
+
+// durationSeconds rounds d up to whole seconds
+func durationSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// cronField is one calendar field of a Schedule
+type cronField struct {
+	name     string
+	value    string
+	min, max int
+}
+
+// cronItem is one comma separated element of a cron field
+type cronItem struct {
+	// all is true for "*"
+	all bool
+	// lo and hi bound the range; equal for a single value
+	lo, hi int
+	// step is the repetition interval, or 0
+	step int
+}
+
+// parse splits the field into its items and checks their bounds
+func (f cronField) parse() ([]cronItem, error) {
+	value := f.value
+	if value == "" {
+		value = "*"
+	}
+
+	var items []cronItem
+	for _, part := range strings.Split(value, ",") {
+		var item cronItem
+		rng, step, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("schedule: %s field %q: invalid step", f.name, value)
+			}
+			item.step = n
+		}
+
+		if rng == "*" {
+			item.all = true
+			item.lo, item.hi = f.min, f.max
+		} else {
+			lo, hi, isRange := strings.Cut(rng, "-")
+			var err error
+			if item.lo, err = strconv.Atoi(lo); err != nil {
+				return nil, fmt.Errorf("schedule: %s field %q: invalid value", f.name, value)
+			}
+			item.hi = item.lo
+			if isRange {
+				if item.hi, err = strconv.Atoi(hi); err != nil {
+					return nil, fmt.Errorf("schedule: %s field %q: invalid value", f.name, value)
+				}
+			}
+		}
+		if item.lo < f.min || item.hi > f.max || item.lo > item.hi {
+			return nil, fmt.Errorf("schedule: %s field %q: out of range %d-%d", f.name, value, f.min, f.max)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// snooze formats the parsed field in snooze syntax, where "/N" matches
+// multiples of N and Sunday is only 0
+func (f cronField) snooze(items []cronItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		if f.name == "weekday" && !item.all && item.hi == 7 {
+			parts = append(parts, "0")
+			item.hi = 6
+			if item.lo == 7 {
+				continue
+			}
+		}
+		switch {
+		case item.all && item.step == 0:
+			return "*"
+		case item.step > 0 && item.lo == 0 && (item.all || item.hi == f.max):
+			parts = append(parts, "/"+strconv.Itoa(item.step))
+		case item.step > 0:
+			for v := item.lo; v <= item.hi; v += item.step {
+				parts = append(parts, strconv.Itoa(v))
+			}
+		case item.lo == item.hi:
+			parts = append(parts, strconv.Itoa(item.lo))
+		default:
+			parts = append(parts, fmt.Sprintf("%d-%d", item.lo, item.hi))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// weekdayNames maps cron weekday numbers to systemd's abbreviations
+var weekdayNames = [...]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// systemd formats the parsed field in systemd calendar syntax
+func (f cronField) systemd(items []cronItem) (string, error) {
+	weekday := f.name == "weekday"
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.all && item.step == 0:
+			if len(items) > 1 {
+				return "*", nil
+			}
+			parts = append(parts, "*")
+		case weekday && item.step > 0:
+			return "", fmt.Errorf("schedule: weekday field %q: steps are not supported by systemd", f.value)
+		case weekday && item.lo == item.hi:
+			parts = append(parts, weekdayNames[item.lo])
+		case weekday:
+			if item.lo == 0 && item.hi == 7 {
+				return "*", nil
+			}
+			// systemd weeks run Monday to Sunday, so Sunday is split off
+			if item.lo == 0 || item.hi == 7 {
+				parts = append(parts, "Sun")
+				item.lo = max(item.lo, 1)
+				item.hi = min(item.hi, 6)
+			}
+			if item.lo == item.hi {
+				parts = append(parts, weekdayNames[item.lo])
+			} else {
+				parts = append(parts, weekdayNames[item.lo]+".."+weekdayNames[item.hi])
+			}
+		case item.step > 0 && (item.all || item.hi == f.max):
+			parts = append(parts, fmt.Sprintf("%02d/%d", item.lo, item.step))
+		case item.step > 0:
+			// Bounded steps are expanded; systemd has no range-with-step form
+			for v := item.lo; v <= item.hi; v += item.step {
+				parts = append(parts, fmt.Sprintf("%02d", v))
+			}
+		case item.lo == item.hi:
+			parts = append(parts, fmt.Sprintf("%02d", item.lo))
+		default:
+			parts = append(parts, fmt.Sprintf("%02d..%02d", item.lo, item.hi))
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
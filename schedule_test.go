@@ -0,0 +1,143 @@
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  bool
+	}{
+		{name: "interval", schedule: Every(5 * time.Minute)},
+		{name: "calendar", schedule: Schedule{Minute: "0", Hour: "3"}},
+		{name: "empty", schedule: Schedule{}, wantErr: true},
+		{name: "both", schedule: Schedule{Every: time.Hour, Minute: "0"}, wantErr: true},
+		{name: "sub-second interval", schedule: Every(time.Millisecond), wantErr: true},
+		{name: "out of range", schedule: Schedule{Hour: "24"}, wantErr: true},
+		{name: "inverted range", schedule: Schedule{Day: "10-2"}, wantErr: true},
+		{name: "bad step", schedule: Schedule{Minute: "*/0"}, wantErr: true},
+		{name: "garbage", schedule: Schedule{Month: "jan"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleConversion(t *testing.T) {
+	tests := []struct {
+		cron           string
+		wantOnCalendar string
+		wantSnooze     []string
+	}{
+		{
+			cron:           "0 3 * * *",
+			wantOnCalendar: "*-*-* 03:00:00",
+			wantSnooze:     []string{"-M0", "-H3"},
+		},
+		{
+			cron:           "*/15 * * * *",
+			wantOnCalendar: "*-*-* *:00/15:00",
+			wantSnooze:     []string{"-M/15", "-H*"},
+		},
+		{
+			cron:           "30 8-17 * * 1-5",
+			wantOnCalendar: "Mon..Fri *-*-* 08..17:30:00",
+			wantSnooze:     []string{"-M30", "-H8-17", "-w1-5"},
+		},
+		{
+			cron:           "0 0 1,15 */3 0",
+			wantOnCalendar: "Sun *-01/3-01,15 00:00:00",
+			wantSnooze:     []string{"-M0", "-H0", "-d1,15", "-m1,4,7,10", "-w0"},
+		},
+		{
+			cron:           "5-20/5 12 * * 5-7",
+			wantOnCalendar: "Sun,Fri..Sat *-*-* 12:05,10,15,20:00",
+			wantSnooze:     []string{"-M5,10,15,20", "-H12", "-w0,5-6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cron, func(t *testing.T) {
+			s, err := ParseCron(tt.cron)
+			if err != nil {
+				t.Fatal(err)
+			}
+			calendar, err := s.OnCalendar()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calendar != tt.wantOnCalendar {
+				t.Errorf("OnCalendar() = %q, want %q", calendar, tt.wantOnCalendar)
+			}
+			snooze, err := s.snoozeArgs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(snooze, tt.wantSnooze) {
+				t.Errorf("snoozeArgs() = %q, want %q", snooze, tt.wantSnooze)
+			}
+		})
+	}
+
+	if _, err := ParseCron("0 3 * *"); err == nil {
+		t.Error("ParseCron accepted four fields")
+	}
+}
+
+func TestServiceBuilderSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     string
+	}{
+		{
+			name:     "interval",
+			schedule: Every(90 * time.Second),
+			want:     "#!/bin/sh\nexec 2>&1\numask 0022\nsleep 90 || exit 1\nexec /bin/backup --full\n",
+		},
+		{
+			name:     "calendar",
+			schedule: Schedule{Minute: "0", Hour: "*/6", RandomDelay: time.Minute},
+			want:     "#!/bin/sh\nexec 2>&1\numask 0022\nexec snooze -M0 -H/6 -R60 /bin/backup --full\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			b := NewServiceBuilder("backup", dir).
+				WithCmd([]string{"/bin/backup", "--full"}).
+				WithSchedule(tt.schedule)
+			if err := b.Build(); err != nil {
+				t.Fatal(err)
+			}
+			run, err := os.ReadFile(filepath.Join(dir, "backup", "run"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(run) != tt.want {
+				t.Errorf("run script:\n%s\nwant:\n%s", run, tt.want)
+			}
+		})
+	}
+
+	b := NewServiceBuilder("backup", t.TempDir()).
+		WithCmd([]string{"/bin/backup"}).
+		WithSchedule(Schedule{})
+	if err := b.Build(); err == nil || !strings.Contains(err.Error(), "schedule") {
+		t.Errorf("Build() with empty schedule = %v", err)
+	}
+}
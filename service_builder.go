@@ -3,8 +3,10 @@ package svcmgr
 import (
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/google/renameio/v2"
@@ -26,6 +28,7 @@ func NewServiceBuilder(name, dir string) *ServiceBuilder {
 			Umask:      DefaultUmask,
 			ChpstPath:  DefaultChpstPath,
 			SvlogdPath: DefaultSvlogdPath,
+			SnoozePath: DefaultSnoozePath,
 		},
 	}
 }
@@ -122,6 +125,56 @@ func (b *ServiceBuilder) WithStderrPath(path string) *ServiceBuilder {
 	return b
 }
 
+// WithSchedule runs the command as a scheduled job. The run script waits
+// for the next scheduled time before starting the command.
+func (b *ServiceBuilder) WithSchedule(schedule Schedule) *ServiceBuilder {
+	b.config.Schedule = &schedule
+	return b
+}
+
+// WithSnoozePath sets the path to the snooze binary
+func (b *ServiceBuilder) WithSnoozePath(path string) *ServiceBuilder {
+	b.config.SnoozePath = path
+	return b
+}
+
+// WithSELinuxContext runs the command in the given SELinux security context
+// (e.g. "system_u:system_r:httpd_t:s0"). Run scripts use runcon; systemd
+// units set SELinuxContext=.
+func (b *ServiceBuilder) WithSELinuxContext(label string) *ServiceBuilder {
+	b.config.SELinuxContext = label
+	return b
+}
+
+// WithAppArmorProfile confines the command with the given AppArmor profile.
+// Run scripts use aa-exec; systemd units set AppArmorProfile=.
+func (b *ServiceBuilder) WithAppArmorProfile(profile string) *ServiceBuilder {
+	b.config.AppArmorProfile = profile
+	return b
+}
+
+// WithMeta sets the metadata written to the service's ServiceMetaFile:
+// description, owner, deploy version and labels
+func (b *ServiceBuilder) WithMeta(fn func(*ServiceMeta)) *ServiceBuilder {
+	if b.config.Meta == nil {
+		b.config.Meta = &ServiceMeta{}
+	}
+	fn(b.config.Meta)
+	return b
+}
+
+// macArgs returns the runcon and aa-exec wrappers for mandatory access control
+func (c *ServiceBuilderConfig) macArgs() []string {
+	var args []string
+	if c.SELinuxContext != "" {
+		args = append(args, DefaultRunconPath, c.SELinuxContext)
+	}
+	if c.AppArmorProfile != "" {
+		args = append(args, DefaultAaExecPath, "-p", c.AppArmorProfile, "--")
+	}
+	return args
+}
+
 // buildArgs constructs the command-line arguments for chpst
 func (c *ChpstConfig) buildArgs() []string {
 	var args []string
@@ -188,6 +241,11 @@ func (b *ServiceBuilder) Build() error {
 	if len(b.config.Cmd) == 0 {
 		return fmt.Errorf("command not specified")
 	}
+	if b.config.Schedule != nil {
+		if err := b.config.Schedule.Validate(); err != nil {
+			return err
+		}
+	}
 
 	serviceDir := filepath.Join(b.config.Dir, b.config.Name)
 	if err := os.MkdirAll(serviceDir, DirMode); err != nil {
@@ -195,46 +253,24 @@ func (b *ServiceBuilder) Build() error {
 	}
 
 	if len(b.config.Env) > 0 {
-		envDir := filepath.Join(serviceDir, "env")
-		if err := os.MkdirAll(envDir, DirMode); err != nil {
+		if err := os.MkdirAll(filepath.Join(serviceDir, "env"), DirMode); err != nil {
 			return fmt.Errorf("creating env directory: %w", err)
 		}
-
-		for key, value := range b.config.Env {
-			envFile := filepath.Join(envDir, key)
-			if err := renameio.WriteFile(envFile, []byte(value), FileMode); err != nil {
-				return fmt.Errorf("writing env file %s: %w", key, err)
-			}
-		}
 	}
-
-	runScript := b.buildRunScript()
-	runFile := filepath.Join(serviceDir, "run")
-	if err := renameio.WriteFile(runFile, []byte(runScript), ExecMode); err != nil {
-		return fmt.Errorf("writing run script: %w", err)
-	}
-
-	if len(b.config.Finish) > 0 {
-		finishScript := b.buildFinishScript()
-		finishFile := filepath.Join(serviceDir, "finish")
-		if err := renameio.WriteFile(finishFile, []byte(finishScript), ExecMode); err != nil {
-			return fmt.Errorf("writing finish script: %w", err)
-		}
-	}
-
 	if b.config.Svlogd != nil {
-		logDir := filepath.Join(serviceDir, "log")
-		if err := os.MkdirAll(logDir, DirMode); err != nil {
+		if err := os.MkdirAll(filepath.Join(serviceDir, "log"), DirMode); err != nil {
 			return fmt.Errorf("creating log directory: %w", err)
 		}
+	}
 
-		logRunScript := b.buildLogRunScript()
-		logRunFile := filepath.Join(logDir, "run")
-		if err := renameio.WriteFile(logRunFile, []byte(logRunScript), ExecMode); err != nil {
-			return fmt.Errorf("writing log/run script: %w", err)
+	for _, f := range b.serviceFiles() {
+		if err := renameio.WriteFile(filepath.Join(serviceDir, f.path), f.data, f.mode); err != nil {
+			return fmt.Errorf("writing %s: %w", f.desc, err)
 		}
+	}
 
-		mainDir := filepath.Join(logDir, "main")
+	if b.config.Svlogd != nil {
+		mainDir := filepath.Join(serviceDir, "log", "main")
 		if err := os.MkdirAll(mainDir, DirMode); err != nil {
 			return fmt.Errorf("creating log/main directory: %w", err)
 		}
@@ -243,6 +279,49 @@ func (b *ServiceBuilder) Build() error {
 	return nil
 }
 
+// serviceFile is a file Build writes into the service directory
+type serviceFile struct {
+	// path is relative to the service directory
+	path string
+	data []byte
+	mode fs.FileMode
+	// desc names the file in errors
+	desc string
+}
+
+// serviceFiles returns the files Build writes, in write order
+func (b *ServiceBuilder) serviceFiles() []serviceFile {
+	var files []serviceFile
+	for _, key := range slices.Sorted(maps.Keys(b.config.Env)) {
+		files = append(files, serviceFile{
+			path: filepath.Join("env", key),
+			data: []byte(b.config.Env[key]),
+			mode: FileMode,
+			desc: "env file " + key,
+		})
+	}
+
+	files = append(files, serviceFile{path: "run", data: []byte(b.buildRunScript()), mode: ExecMode, desc: "run script"})
+
+	if len(b.config.Finish) > 0 {
+		files = append(files, serviceFile{path: "finish", data: []byte(b.buildFinishScript()), mode: ExecMode, desc: "finish script"})
+	}
+
+	if b.config.Svlogd != nil {
+		files = append(files, serviceFile{
+			path: filepath.Join("log", "run"),
+			data: []byte(b.buildLogRunScript()),
+			mode: ExecMode,
+			desc: "log/run script",
+		})
+	}
+
+	if b.config.Meta != nil {
+		files = append(files, serviceFile{path: ServiceMetaFile, data: b.config.Meta.encode(), mode: FileMode, desc: "metadata file"})
+	}
+	return files
+}
+
 // buildRunScript generates the run script for the service
 func (b *ServiceBuilder) buildRunScript() string {
 	var lines []string
@@ -263,8 +342,20 @@ func (b *ServiceBuilder) buildRunScript() string {
 		lines = append(lines, fmt.Sprintf("cd %s", shellQuote(b.config.Cwd)))
 	}
 
+	// Scheduled jobs wait for their next run; the supervisor restarts the
+	// run script after the command exits
+	var snooze []string
+	if schedule := b.config.Schedule; schedule != nil {
+		if schedule.Every > 0 {
+			lines = append(lines, fmt.Sprintf("sleep %d || exit 1", durationSeconds(schedule.Every)))
+		} else if args, err := schedule.snoozeArgs(); err == nil {
+			// Build has validated the schedule
+			snooze = append([]string{b.config.SnoozePath}, args...)
+		}
+	}
+
 	// Calculate capacity needed
-	capacity := len(b.config.Cmd)
+	capacity := len(snooze) + len(b.config.macArgs()) + len(b.config.Cmd)
 	if len(b.config.Env) > 0 {
 		capacity += 3 // chpst -e ./env
 	}
@@ -274,6 +365,10 @@ func (b *ServiceBuilder) buildRunScript() string {
 
 	cmdParts := make([]string, 0, capacity)
 
+	for _, part := range snooze {
+		cmdParts = append(cmdParts, shellQuote(part))
+	}
+
 	if len(b.config.Env) > 0 {
 		// Handle environment variables based on the tool being used
 		// s6 uses s6-envdir, while runit/daemontools use chpst/setuidgid with -e flag
@@ -291,6 +386,12 @@ func (b *ServiceBuilder) buildRunScript() string {
 		cmdParts = append(cmdParts, b.config.Chpst.buildArgs()...)
 	}
 
+	// The security context applies to the command itself, after chpst has
+	// changed users
+	for _, part := range b.config.macArgs() {
+		cmdParts = append(cmdParts, shellQuote(part))
+	}
+
 	for _, part := range b.config.Cmd {
 		cmdParts = append(cmdParts, shellQuote(part))
 	}
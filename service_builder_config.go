@@ -29,6 +29,16 @@ type ServiceBuilderConfig struct {
 	ChpstPath string
 	// SvlogdPath is the path to the svlogd binary
 	SvlogdPath string
+	// Schedule, when set, runs the command as a scheduled job instead of a daemon
+	Schedule *Schedule
+	// SnoozePath is the path to the snooze binary used for calendar schedules
+	SnoozePath string
+	// SELinuxContext is the SELinux security context to run the command in
+	SELinuxContext string
+	// AppArmorProfile is the AppArmor profile to confine the command with
+	AppArmorProfile string
+	// Meta, when set, is written to the service's ServiceMetaFile
+	Meta *ServiceMeta
 }
 
 // ChpstConfig configures chpst options for process control
@@ -88,6 +98,11 @@ func (c *ServiceBuilderConfig) Clone() *ServiceBuilderConfig {
 		StderrPath: c.StderrPath,
 		ChpstPath:  c.ChpstPath,
 		SvlogdPath: c.SvlogdPath,
+		SnoozePath: c.SnoozePath,
+
+		SELinuxContext:  c.SELinuxContext,
+		AppArmorProfile: c.AppArmorProfile,
+		Meta:            c.Meta.Clone(),
 	}
 
 	// Deep copy Cmd
@@ -132,5 +147,11 @@ func (c *ServiceBuilderConfig) Clone() *ServiceBuilderConfig {
 		}
 	}
 
+	// Copy Schedule
+	if c.Schedule != nil {
+		schedule := *c.Schedule
+		clone.Schedule = &schedule
+	}
+
 	return clone
 }
@@ -0,0 +1,103 @@
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestServiceBuilderMandatoryAccessControl(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*ServiceBuilder)
+		want  string
+	}{
+		{
+			name:  "selinux",
+			setup: func(b *ServiceBuilder) { b.WithSELinuxContext("system_u:system_r:httpd_t:s0") },
+			want:  "exec runcon system_u:system_r:httpd_t:s0 /bin/web\n",
+		},
+		{
+			name:  "apparmor",
+			setup: func(b *ServiceBuilder) { b.WithAppArmorProfile("web") },
+			want:  "exec aa-exec -p web -- /bin/web\n",
+		},
+		{
+			name: "after chpst",
+			setup: func(b *ServiceBuilder) {
+				b.WithChpst(func(c *ChpstConfig) { c.User = "www" }).WithAppArmorProfile("web")
+			},
+			want: "exec chpst -u www aa-exec -p web -- /bin/web\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewServiceBuilder("web", t.TempDir()).WithCmd([]string{"/bin/web"})
+			tt.setup(b)
+
+			script := b.buildRunScript()
+			want := "#!/bin/sh\nexec 2>&1\numask 0022\n" + tt.want
+			if script != want {
+				t.Errorf("run script:\n%s\nwant:\n%s", script, want)
+			}
+
+			clone := b.Config()
+			if clone.SELinuxContext != b.config.SELinuxContext || clone.AppArmorProfile != b.config.AppArmorProfile {
+				t.Errorf("Config() dropped MAC settings: %+v", clone)
+			}
+		})
+	}
+}
+
+func TestServiceBuilderVerify(t *testing.T) {
+	b := NewServiceBuilder("web", t.TempDir()).
+		WithCmd([]string{"/bin/web"}).
+		WithEnv("PORT", "8080").
+		WithSvlogd(func(*ConfigSvlogd) {})
+	if err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(b.config.Dir, "web")
+
+	drifts, err := b.Verify(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("freshly built directory drifted: %v", drifts)
+	}
+
+	// Hand edits to the live directory
+	if err := os.WriteFile(filepath.Join(dir, "env", "PORT"), []byte("9090"), FileMode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "env", "DEBUG"), []byte("1"), FileMode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "run"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "log", "run")); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err = b.Verify(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drift{
+		{Path: "env/PORT", Kind: DriftModified, Want: "8080", Got: "9090"},
+		{Path: "run", Kind: DriftMode, Want: "0755", Got: "0644"},
+		{Path: "log/run", Kind: DriftMissing},
+		{Path: "env/DEBUG", Kind: DriftUnexpected},
+	}
+	if !slices.Equal(drifts, want) {
+		t.Errorf("Verify() =\n%v\nwant\n%v", drifts, want)
+	}
+
+	if _, err := b.Verify(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Verify of a missing directory should fail")
+	}
+}
@@ -0,0 +1,99 @@
+package svcmgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// DriftKind classifies a difference between a live service directory and
+// its builder
+type DriftKind string
+
+const (
+	// DriftMissing is a file the builder would write that does not exist
+	DriftMissing DriftKind = "missing"
+	// DriftModified is a file whose content differs
+	DriftModified DriftKind = "modified"
+	// DriftMode is a file whose permission bits differ
+	DriftMode DriftKind = "mode"
+	// DriftUnexpected is an env file the builder would not write; envdir
+	// would still export it to the service
+	DriftUnexpected DriftKind = "unexpected"
+)
+
+// Drift is one difference found by ServiceBuilder.Verify
+type Drift struct {
+	// Path is relative to the service directory, e.g. "run" or "env/PORT"
+	Path string
+	// Kind classifies the difference
+	Kind DriftKind
+	// Want and Got are the expected and found content or mode; content is
+	// left empty for missing and unexpected files
+	Want, Got string
+}
+
+// String describes the drift on one line
+func (d Drift) String() string {
+	if d.Kind == DriftMode {
+		return fmt.Sprintf("%s: mode %s, want %s", d.Path, d.Got, d.Want)
+	}
+	return fmt.Sprintf("%s: %s", d.Path, d.Kind)
+}
+
+// Verify compares the live service directory dir against the files Build
+// would write — run, finish and log/run scripts and env files — and
+// reports each difference, for configuration-management compliance
+// checks. It returns no drift when dir matches. Errors are returned only
+// when dir itself cannot be read.
+func (b *ServiceBuilder) Verify(dir string) ([]Drift, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+
+	var drifts []Drift
+	want := b.serviceFiles()
+	for _, f := range want {
+		path := filepath.Join(dir, f.path)
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			drifts = append(drifts, Drift{Path: f.path, Kind: DriftMissing})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("verify: %w", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("verify: %w", err)
+		}
+		if !bytes.Equal(data, f.data) {
+			drifts = append(drifts, Drift{Path: f.path, Kind: DriftModified, Want: string(f.data), Got: string(data)})
+		}
+		if got := info.Mode().Perm(); got != f.mode.Perm() {
+			drifts = append(drifts, Drift{
+				Path: f.path,
+				Kind: DriftMode,
+				Want: fmt.Sprintf("%04o", f.mode.Perm()),
+				Got:  fmt.Sprintf("%04o", got),
+			})
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "env"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join("env", entry.Name())
+		if !slices.ContainsFunc(want, func(f serviceFile) bool { return f.path == path }) {
+			drifts = append(drifts, Drift{Path: path, Kind: DriftUnexpected})
+		}
+	}
+	return drifts, nil
+}
@@ -0,0 +1,90 @@
+package svcmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/google/renameio/v2"
+)
+
+// ServiceMetaFile is the metadata file in a service directory, written by
+// ServiceBuilder and read by ServiceScanner
+const ServiceMetaFile = "svcmgr-meta.json"
+
+// ServiceMeta is descriptive metadata kept alongside a service. Supervisors
+// ignore it; it exists for inventory and dashboard tooling.
+//
+// The file is JSON:
+//
+//	{
+//	  "description": "Public web frontend",
+//	  "owner": "web-team",
+//	  "version": "2024.06.1",
+//	  "labels": {"tier": "frontend"}
+//	}
+type ServiceMeta struct {
+	// Description says what the service is for
+	Description string `json:"description,omitempty"`
+	// Owner is the team or person responsible for the service
+	Owner string `json:"owner,omitempty"`
+	// Version is the deployed version of the service
+	Version string `json:"version,omitempty"`
+	// Labels are arbitrary key/value pairs to select services by
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Clone returns a deep copy of the metadata
+func (m *ServiceMeta) Clone() *ServiceMeta {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Labels = maps.Clone(m.Labels)
+	return &clone
+}
+
+// Matches reports whether the metadata carries every label of selector
+// with the same value; an empty selector matches everything, including
+// services without metadata
+func (m *ServiceMeta) Matches(selector map[string]string) bool {
+	for k, v := range selector {
+		if m == nil {
+			return false
+		}
+		if got, ok := m.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// encode returns the file contents of the metadata
+func (m *ServiceMeta) encode() []byte {
+	// Marshaling strings and a string map cannot fail
+	data, _ := json.MarshalIndent(m, "", "  ")
+	return append(data, '\n')
+}
+
+// ReadServiceMeta reads the metadata file of the service in serviceDir. A
+// service without one yields an error wrapping os.ErrNotExist.
+func ReadServiceMeta(serviceDir string) (*ServiceMeta, error) {
+	path := filepath.Join(serviceDir, ServiceMetaFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta ServiceMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &meta, nil
+}
+
+// WriteServiceMeta atomically replaces the metadata file of the service in
+// serviceDir, e.g. to record a new deploy version without rebuilding
+func WriteServiceMeta(serviceDir string, meta *ServiceMeta) error {
+	return renameio.WriteFile(filepath.Join(serviceDir, ServiceMetaFile), meta.encode(), FileMode)
+}
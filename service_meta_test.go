@@ -0,0 +1,97 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestServiceBuilderMeta(t *testing.T) {
+	root := t.TempDir()
+	builder := NewServiceBuilder("web", root).
+		WithCmd([]string{"/usr/bin/web"}).
+		WithMeta(func(m *ServiceMeta) {
+			m.Description = "Public web frontend"
+			m.Owner = "web-team"
+			m.Version = "2024.06.1"
+			m.Labels = map[string]string{"tier": "frontend"}
+		})
+	if err := builder.Build(); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "web")
+
+	meta, err := ReadServiceMeta(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := builder.Config().Meta; !reflect.DeepEqual(meta, want) {
+		t.Errorf("ReadServiceMeta() = %+v, want %+v", meta, want)
+	}
+	if drifts, err := builder.Verify(dir); err != nil || len(drifts) != 0 {
+		t.Errorf("Verify() = %v, %v; want no drift", drifts, err)
+	}
+
+	// A new deploy version shows up as drift from the builder
+	meta.Version = "2024.07.0"
+	if err := WriteServiceMeta(dir, meta); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err := builder.Verify(dir)
+	if err != nil || len(drifts) != 1 || drifts[0].Path != ServiceMetaFile {
+		t.Errorf("Verify() = %v, %v; want drift of %s", drifts, err, ServiceMetaFile)
+	}
+
+	if _, err := ReadServiceMeta(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadServiceMeta() without file: error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestServiceScannerSelector(t *testing.T) {
+	root := t.TempDir()
+	services := map[string]*ServiceMeta{
+		"web":    {Owner: "web-team", Labels: map[string]string{"tier": "frontend", "env": "prod"}},
+		"web-qa": {Labels: map[string]string{"tier": "frontend", "env": "qa"}},
+		"db":     {Labels: map[string]string{"tier": "backend"}},
+		"legacy": nil,
+	}
+	for name, meta := range services {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if meta != nil {
+			if err := WriteServiceMeta(dir, meta); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		selector map[string]string
+		want     []string
+	}{
+		{selector: nil, want: []string{"db", "legacy", "web", "web-qa"}},
+		{selector: map[string]string{"tier": "frontend"}, want: []string{"web", "web-qa"}},
+		{selector: map[string]string{"tier": "frontend", "env": "prod"}, want: []string{"web"}},
+		{selector: map[string]string{"tier": "cache"}, want: nil},
+	}
+	for _, tt := range tests {
+		infos, err := (&ServiceScanner{Root: root, Selector: tt.selector}).Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, info := range infos {
+			got = append(got, info.Name)
+			if !reflect.DeepEqual(info.Meta, services[info.Name]) {
+				t.Errorf("%s: Meta = %+v, want %+v", info.Name, info.Meta, services[info.Name])
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Scan(%v) = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
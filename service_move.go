@@ -0,0 +1,253 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/renameio/v2"
+)
+
+// DefaultMoveTimeout bounds each wait of MoveService: for the service to
+// stop, its old supervisor to exit and the scanner to pick up the new
+// directory. Scanners rescan every few seconds (runsvdir every 5).
+const DefaultMoveTimeout = 30 * time.Second
+
+// MoveOptions configures MoveService
+type MoveOptions struct {
+	// ScanDirs are the scan directories whose symlinks to the service are
+	// updated, such as DefaultEnabledDir
+	ScanDirs []string
+	// Timeout bounds each wait; zero means DefaultMoveTimeout
+	Timeout time.Duration
+	// PollInterval is how often progress is checked; zero means
+	// DefaultReadyPollInterval
+	PollInterval time.Duration
+}
+
+// MoveService renames or relocates the service directory src to dst, which
+// must be on the same filesystem, and points the symlinks in the scan
+// directories at it. Links named like src are renamed like dst; others keep
+// their name.
+//
+// A supervised service is brought down and waited for first. src is then
+// renamed to a hidden name, which scanners ignore, and the old supervisor
+// is waited for to exit before the directory reaches dst, so that two
+// supervisors never run the same service. Until the scanner has picked up
+// dst, a down file keeps the new supervisor from starting the service on
+// its own; it is then started again if it was running or wanted up.
+//
+// If a step fails or a wait times out before the scanner has picked up
+// dst, the move is rolled back: the directory returns to src, the links
+// are restored and a service that was up is started again.
+//
+// Example, renaming an enabled Void Linux service:
+//
+//	err := svcmgr.MoveService(ctx, "/etc/sv/api", "/etc/sv/api-v1", svcmgr.MoveOptions{
+//		ScanDirs: []string{svcmgr.DefaultEnabledDir},
+//	})
+func MoveService(ctx context.Context, src, dst string, opts MoveOptions) (err error) {
+	if src, err = filepath.Abs(src); err != nil {
+		return err
+	}
+	if dst, err = filepath.Abs(dst); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("move: %s already exists", dst)
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultMoveTimeout
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultReadyPollInterval
+	}
+	wait := func(what string, cond func() (bool, error)) error {
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if err := pollUntil(waitCtx, interval, cond); err != nil {
+			return fmt.Errorf("move: waiting for %s: %w", what, err)
+		}
+		return nil
+	}
+
+	links, err := serviceLinks(src, opts.ScanDirs)
+	if err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	// undo holds the steps reverting what was done so far, run last first
+	// when the move fails
+	var undo []func(context.Context) error
+	defer func() {
+		if err == nil || len(undo) == 0 {
+			return
+		}
+		rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		for i := len(undo) - 1; i >= 0; i-- {
+			if uerr := undo[i](rollbackCtx); uerr != nil {
+				err = errors.Join(err, fmt.Errorf("move: rolling back: %w", uerr))
+			}
+		}
+	}()
+
+	supervised, _ := Ok(src)
+	wasUp := false
+	if supervised {
+		client, err := NewClient(src)
+		if err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+		st, err := client.Status(ctx)
+		wasUp = err == nil && (st.State == StateRunning || st.Flags.WantUp)
+		if err := client.Down(ctx); err != nil {
+			return fmt.Errorf("move: stopping %s: %w", src, err)
+		}
+		if wasUp {
+			undo = append(undo, func(ctx context.Context) error {
+				// A supervisor that exited meanwhile is replaced by the
+				// scanner, which starts the service unless it has a down file
+				if ok, _ := Ok(src); !ok {
+					return nil
+				}
+				return client.Up(ctx)
+			})
+		}
+		stopped := func(st Status) bool { return st.State == StateDown || st.State == StateExited }
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, err = waitFunc(waitCtx, client, stopped)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("move: waiting for %s to stop: %w", src, err)
+		}
+	}
+
+	// Hide the service from scanners, whether it is linked or lives in a
+	// scan directory itself, and let its supervisor exit
+	for _, link := range links {
+		target, err := os.Readlink(link)
+		if err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+		if err := os.Remove(link); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("move: %w", err)
+		}
+		undo = append(undo, func(context.Context) error { return renameio.Symlink(target, link) })
+	}
+	hidden := filepath.Join(filepath.Dir(src), "."+filepath.Base(src)+".moving")
+	if err := os.Rename(src, hidden); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+	undo = append(undo, func(context.Context) error { return os.Rename(hidden, src) })
+	if supervised {
+		err := wait("the supervisor of "+src+" to exit", func() (bool, error) {
+			ok, err := Ok(hidden)
+			return !ok, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	downFile := filepath.Join(hidden, "down")
+	addedDown := false
+	if supervised {
+		if _, err := os.Stat(downFile); errors.Is(err, os.ErrNotExist) {
+			if err := renameio.WriteFile(downFile, nil, FileMode); err != nil {
+				return fmt.Errorf("move: writing down file: %w", err)
+			}
+			addedDown = true
+			undo = append(undo, func(context.Context) error { return os.Remove(filepath.Join(hidden, "down")) })
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), DirMode); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+	if err := os.Rename(hidden, dst); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+	undo = append(undo, func(context.Context) error { return os.Rename(dst, hidden) })
+	downFile = filepath.Join(dst, "down")
+
+	for _, link := range links {
+		if filepath.Base(link) == filepath.Base(src) {
+			link = filepath.Join(filepath.Dir(link), filepath.Base(dst))
+		}
+		if err := renameio.Symlink(dst, link); err != nil {
+			return fmt.Errorf("move: linking %s: %w", link, err)
+		}
+		undo = append(undo, func(context.Context) error { return os.Remove(link) })
+	}
+
+	scanned := len(links) > 0 || slices.ContainsFunc(opts.ScanDirs, func(d string) bool { return sameDir(d, filepath.Dir(dst)) })
+	if !supervised || !scanned {
+		undo = nil
+		if addedDown {
+			return os.Remove(downFile)
+		}
+		return nil
+	}
+
+	if err := wait(dst+" to be supervised", func() (bool, error) { return Ok(dst) }); err != nil {
+		return err
+	}
+	// A supervisor runs dst now; moving it back would run two
+	undo = nil
+	if addedDown {
+		if err := os.Remove(downFile); err != nil {
+			return fmt.Errorf("move: removing down file: %w", err)
+		}
+	}
+	if wasUp {
+		client, err := NewClient(dst)
+		if err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+		if err := client.Up(ctx); err != nil {
+			return fmt.Errorf("move: starting %s: %w", dst, err)
+		}
+	}
+	return nil
+}
+
+// serviceLinks returns the symlinks in scanDirs that resolve to dir
+func serviceLinks(dir string, scanDirs []string) ([]string, error) {
+	var links []string
+	for _, scanDir := range scanDirs {
+		entries, err := os.ReadDir(scanDir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink == 0 || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			link := filepath.Join(scanDir, entry.Name())
+			if target, err := filepath.EvalSymlinks(link); err == nil && sameDir(target, dir) {
+				links = append(links, link)
+			}
+		}
+	}
+	return links, nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	return err == nil && os.SameFile(ia, ib)
+}
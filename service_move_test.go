@@ -0,0 +1,180 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMoveService(t *testing.T) {
+	root := t.TempDir()
+	svDir, scanDir := filepath.Join(root, "sv"), filepath.Join(root, "service")
+	src, dst := filepath.Join(svDir, "api"), filepath.Join(svDir, "api-v1")
+	if err := NewServiceBuilder("api", svDir).WithCmd([]string{"/usr/bin/api"}).Build(); err != nil {
+		t.Fatal(err)
+	}
+	mock, err := NewMockSupervisor(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(scanDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnableService(svDir, scanDir, "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Play runsvdir and runsv: stop the service when asked, exit once the
+	// service is hidden and supervise the new directory once linked
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	hidden := filepath.Join(svDir, ".api.moving")
+	var sawDown, stopped bool
+	scanner := make(chan struct{})
+	go func() {
+		defer close(scanner)
+		for ctx.Err() == nil {
+			if data, _ := os.ReadFile(mock.ControlFile); !stopped && string(data) == "d" {
+				_ = mock.UpdateStatus(false, 0)
+				stopped = true
+			}
+			_ = os.Remove(filepath.Join(hidden, SuperviseDir, ControlFile))
+			if _, err := os.Stat(filepath.Join(scanDir, "api-v1")); err == nil {
+				if _, err := os.Stat(filepath.Join(dst, "down")); err == nil {
+					sawDown = true
+				}
+				control := filepath.Join(dst, SuperviseDir, ControlFile)
+				if _, err := os.Stat(control); os.IsNotExist(err) {
+					_ = os.WriteFile(control, nil, 0o644)
+				}
+				return
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+	}()
+
+	err = MoveService(ctx, src, dst, MoveOptions{ScanDirs: []string{scanDir}, PollInterval: 5 * time.Millisecond})
+	<-scanner
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("old directory still exists: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(scanDir, "api")); !os.IsNotExist(err) {
+		t.Errorf("old link still exists: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(scanDir, "api-v1")); err != nil || target != dst {
+		t.Errorf("new link = %q, %v, want %q", target, err, dst)
+	}
+	if !sawDown {
+		t.Error("the new supervisor could start the service before it was picked up")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "down")); !os.IsNotExist(err) {
+		t.Errorf("down file left behind: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, SuperviseDir, ControlFile)); string(data) != "u" {
+		t.Errorf("control = %q, want the service started again", data)
+	}
+}
+
+func TestMoveServiceRollback(t *testing.T) {
+	root := t.TempDir()
+	svDir, scanDir := filepath.Join(root, "sv"), filepath.Join(root, "service")
+	src, dst := filepath.Join(svDir, "api"), filepath.Join(svDir, "api-v1")
+	if err := NewServiceBuilder("api", svDir).WithCmd([]string{"/usr/bin/api"}).Build(); err != nil {
+		t.Fatal(err)
+	}
+	mock, err := NewMockSupervisor(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(scanDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnableService(svDir, scanDir, "api"); err != nil {
+		t.Fatal(err)
+	}
+	oldTarget, err := os.Readlink(filepath.Join(scanDir, "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Play runsv stopping the service and exiting, but no scanner picks
+	// up the new directory
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	hidden := filepath.Join(svDir, ".api.moving")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stopped := false
+		for ctx.Err() == nil {
+			if data, _ := os.ReadFile(mock.ControlFile); !stopped && string(data) == "d" {
+				_ = mock.UpdateStatus(false, 0)
+				stopped = true
+			}
+			_ = os.Remove(filepath.Join(hidden, SuperviseDir, ControlFile))
+			time.Sleep(2 * time.Millisecond)
+		}
+	}()
+
+	err = MoveService(ctx, src, dst, MoveOptions{
+		ScanDirs:     []string{scanDir},
+		Timeout:      200 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+	cancel()
+	<-done
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("MoveService() error = %v, want the supervision timeout", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(src, "run")); err != nil {
+		t.Errorf("directory not moved back: %v", err)
+	}
+	for _, path := range []string{dst, hidden, filepath.Join(scanDir, "api-v1"), filepath.Join(src, "down")} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", path, err)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(scanDir, "api")); err != nil || target != oldTarget {
+		t.Errorf("old link = %q, %v, want %q restored", target, err, oldTarget)
+	}
+}
+
+func TestMoveServiceUnsupervised(t *testing.T) {
+	root := t.TempDir()
+	src, dst := filepath.Join(root, "a", "web"), filepath.Join(root, "b", "web")
+	if err := NewServiceBuilder("web", filepath.Dir(src)).WithCmd([]string{"/usr/bin/web"}).Build(); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveService(context.Background(), src, dst, MoveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "run")); err != nil {
+		t.Errorf("moved run script: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "down")); !os.IsNotExist(err) {
+		t.Errorf("down file added to an unsupervised service: %v", err)
+	}
+
+	if err := os.MkdirAll(src, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveService(context.Background(), src, dst, MoveOptions{}); err == nil {
+		t.Error("MoveService() overwrote an existing destination")
+	}
+}
@@ -0,0 +1,166 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+)
+
+// DefaultScannerLogDirs are the log directories, relative to a service
+// directory, searched for rotated log files: ServiceBuilder's svlogd writes
+// to log itself, and many distributions use log/main
+var DefaultScannerLogDirs = []string{"log", filepath.Join("log", "main")}
+
+// ServiceInfo is the metadata ServiceScanner reports for one service
+type ServiceInfo struct {
+	// Name is the service's directory name
+	Name string `json:"name"`
+	// Dir is the path to the service directory
+	Dir string `json:"dir"`
+	// Type is the supervision system detected from the supervise
+	// directory, ServiceTypeUnknown when the service was never supervised
+	Type ServiceType `json:"type"`
+	// Enabled reports that the service has no down file, so it starts
+	// when its supervisor does
+	Enabled bool `json:"enabled"`
+	// HasLogger reports that the service has a log/run script
+	HasLogger bool `json:"has_logger"`
+	// Supervised reports that a supervisor is attached (see Ok)
+	Supervised bool `json:"supervised"`
+	// Stale reports that the supervise directory was left behind by a
+	// supervisor that no longer runs (see StaleSupervise)
+	Stale bool `json:"stale"`
+	// Status is the decoded status file; StateUnknown when it cannot be
+	// read, and StateExited when the supervise directory is stale
+	Status Status `json:"status"`
+	// LastRotation is the time of the newest rotated log file, zero when
+	// there is none
+	LastRotation time.Time `json:"last_rotation,omitzero"`
+	// Meta is the service's ServiceMetaFile, nil when it has none or it
+	// cannot be read
+	Meta *ServiceMeta `json:"meta,omitempty"`
+}
+
+// ServiceScanner walks a service tree and reports metadata about every
+// service in it, as the backend for inventory and dashboard tooling.
+// Unlike Services, directories that are not (yet) supervised are included.
+//
+// Example:
+//
+//	infos, err := (&svcmgr.ServiceScanner{Root: "/etc/sv"}).Scan()
+//	for _, info := range infos {
+//		fmt.Println(info.Name, info.Enabled, info.Supervised, info.Status.State)
+//	}
+//
+//	// Only the frontend services, as labeled in their metadata
+//	scanner := &svcmgr.ServiceScanner{Root: "/etc/sv", Selector: map[string]string{"tier": "frontend"}}
+type ServiceScanner struct {
+	// Root is the directory holding the services, such as a scan
+	// directory or DefaultSvDir
+	Root string
+	// LogDirs are searched, relative to each service directory, for
+	// rotated log files; nil means DefaultScannerLogDirs
+	LogDirs []string
+	// Selector, when set, limits Scan to services whose metadata carries
+	// all of these labels (see ServiceMeta.Matches)
+	Selector map[string]string
+}
+
+// Scan returns the metadata of every service under Root matching Selector,
+// in name order. Hidden entries and plain files are skipped. Errors reading
+// an individual service leave the affected fields at their zero values.
+func (s *ServiceScanner) Scan() ([]ServiceInfo, error) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", s.Root, err)
+	}
+
+	var infos []ServiceInfo
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		// Follow symlinks, which is how runit services are usually enabled
+		dir := filepath.Join(s.Root, entry.Name())
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		info := s.Inspect(dir)
+		if !info.Meta.Matches(s.Selector) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Inspect returns the metadata of the service in dir
+func (s *ServiceScanner) Inspect(dir string) ServiceInfo {
+	info := ServiceInfo{
+		Name:    filepath.Base(dir),
+		Dir:     dir,
+		Enabled: !exists(filepath.Join(dir, "down")),
+		Status:  Status{State: StateUnknown},
+	}
+	if st, err := os.Stat(filepath.Join(dir, "log", "run")); err == nil && !st.IsDir() {
+		info.HasLogger = true
+	}
+	info.Meta, _ = ReadServiceMeta(dir)
+
+	if st, err := os.Stat(filepath.Join(dir, SuperviseDir)); err == nil && st.IsDir() {
+		info.Type = detectServiceType(dir)
+		info.Supervised, _ = Ok(dir)
+		if status, err := readStatusFile(dir); err == nil {
+			info.Status = status
+		}
+		if !info.Supervised {
+			info.Stale, _ = StaleSupervise(dir)
+		}
+		if info.Stale {
+			// The status file describes the last supervised run
+			info.Status.State = StateExited
+		}
+	}
+
+	logDirs := s.LogDirs
+	if logDirs == nil {
+		logDirs = DefaultScannerLogDirs
+	}
+	for _, logDir := range logDirs {
+		if t := lastRotation(filepath.Join(dir, logDir)); t.After(info.LastRotation) {
+			info.LastRotation = t
+		}
+	}
+	return info
+}
+
+// lastRotation returns the time of the newest rotated log file in dir,
+// named by its TAI64N label as svlogd and multilog do
+func lastRotation(dir string) time.Time {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}
+	}
+	var last time.Time
+	for _, entry := range entries {
+		label, err := tai64.ParseFilename(entry.Name())
+		if err != nil {
+			continue
+		}
+		if t := label.Time(); t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
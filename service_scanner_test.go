@@ -0,0 +1,82 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+)
+
+func TestServiceScanner(t *testing.T) {
+	root := t.TempDir()
+
+	web, err := NewMockSupervisor(filepath.Join(root, "web"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := web.UpdateStatus(true, 1234); err != nil {
+		t.Fatal(err)
+	}
+
+	// A disabled, never supervised service with a logger and rotated logs
+	rotated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	worker := filepath.Join(root, "worker")
+	for _, path := range []string{
+		filepath.Join(worker, "run"),
+		filepath.Join(worker, "down"),
+		filepath.Join(worker, "log", "run"),
+		filepath.Join(worker, "log", "main", "current"),
+		filepath.Join(worker, "log", "main", tai64.FromTime(rotated.Add(-time.Hour)).String()+".s"),
+		filepath.Join(worker, "log", "main", tai64.FromTime(rotated).String()+".u"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Hidden entries and plain files are not services
+	if err := os.Mkdir(filepath.Join(root, ".tmp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "README"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := (&ServiceScanner{Root: root}).Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("Scan() returned %d services, want 2: %+v", len(infos), infos)
+	}
+
+	got := infos[0]
+	if got.Name != "web" || got.Type != ServiceTypeRunit || !got.Enabled || got.HasLogger || !got.Supervised {
+		t.Errorf("web = %+v", got)
+	}
+	if got.Status.State != StateRunning || got.Status.PID != 1234 {
+		t.Errorf("web status = %+v, want running with pid 1234", got.Status)
+	}
+
+	got = infos[1]
+	if got.Name != "worker" || got.Type != ServiceTypeUnknown || got.Enabled || !got.HasLogger || got.Supervised {
+		t.Errorf("worker = %+v", got)
+	}
+	if got.Status.State != StateUnknown {
+		t.Errorf("worker state = %v, want unknown", got.Status.State)
+	}
+	if !got.LastRotation.Equal(rotated) {
+		t.Errorf("worker LastRotation = %v, want %v", got.LastRotation, rotated)
+	}
+
+	if _, err := (&ServiceScanner{Root: filepath.Join(root, "missing")}).Scan(); err == nil {
+		t.Error("Scan() of a missing root succeeded")
+	}
+}
@@ -0,0 +1,77 @@
+package svcmgr
+
+import (
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Services lazily walks a scan directory (such as /etc/service or /run/service)
+// and yields each supervised service's path with its decoded status.
+// Directories without a supervise subdirectory and hidden entries are skipped.
+// A service whose status cannot be read is yielded with StateUnknown.
+//
+// Example:
+//
+//	for dir, status := range svcmgr.Services("/etc/service") {
+//		fmt.Println(dir, status.State)
+//	}
+func Services(root string) iter.Seq2[string, Status] {
+	services, _ := readServices(root)
+	return services
+}
+
+// readServices is Services, reading root up front and returning the error
+// when it cannot be read instead of yielding nothing
+func readServices(root string) (iter.Seq2[string, Status], error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return func(func(string, Status) bool) {}, err
+	}
+
+	return func(yield func(string, Status) bool) {
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			// Follow symlinks, which is how runit services are usually enabled
+			dir := filepath.Join(root, entry.Name())
+			if info, err := os.Stat(filepath.Join(dir, SuperviseDir)); err != nil || !info.IsDir() {
+				continue
+			}
+
+			status, err := readStatusFile(dir)
+			if err != nil {
+				status = Status{State: StateUnknown}
+			}
+			if !yield(dir, status) {
+				return
+			}
+		}
+	}, nil
+}
+
+// readStatusFile reads and decodes a service's status file, choosing the
+// decoder from the record size
+func readStatusFile(serviceDir string) (Status, error) {
+	data, err := os.ReadFile(filepath.Join(serviceDir, SuperviseDir, StatusFile))
+	if err != nil {
+		return Status{}, err
+	}
+	return decodeStatusFile(data)
+}
+
+// decodeStatusFile decodes a status record, choosing the decoder from its
+// size; records larger than runit's are negotiated by the s6 decoder
+func decodeStatusFile(data []byte) (Status, error) {
+	switch n := len(data); {
+	case n == DaemontoolsStatusSize:
+		return DecodeStatusDaemontools(data)
+	case n >= S6StatusSizePre220:
+		return DecodeStatusS6(data)
+	default:
+		return DecodeStatusRunit(data)
+	}
+}
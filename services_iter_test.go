@@ -0,0 +1,56 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServices(t *testing.T) {
+	root := t.TempDir()
+
+	types := map[string]ServiceType{
+		"a-runit": ServiceTypeRunit,
+		"b-dt":    ServiceTypeDaemontools,
+		"c-s6":    ServiceTypeS6,
+	}
+	for name, typ := range types {
+		mock, err := NewMockSupervisorWithType(filepath.Join(root, name), typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.UpdateStatus(true, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not services
+	if err := os.MkdirAll(filepath.Join(root, "plain"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".s6-svscan", SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for dir, status := range Services(root) {
+		names = append(names, filepath.Base(dir))
+		if status.State != StateRunning || status.PID != 100 {
+			t.Errorf("%s: state %v pid %d, want running 100", dir, status.State, status.PID)
+		}
+	}
+	if len(names) != 3 || names[0] != "a-runit" || names[2] != "c-s6" {
+		t.Errorf("services = %v", names)
+	}
+
+	// Breaking out of the loop stops the walk
+	count := 0
+	for range Services(root) {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("early break yielded %d services", count)
+	}
+}
@@ -0,0 +1,82 @@
+package svcmgr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownGrace is a typical grace period for ShutdownAll
+const DefaultShutdownGrace = 7 * time.Second
+
+// ShutdownAll stops every service under the scan directory root in the
+// standard appliance poweroff sequence, like runit's stage 3 with
+// "sv force-shutdown":
+//
+//  1. every service is brought down (TERM followed by CONT),
+//  2. services still running after grace are sent KILL,
+//  3. every supervisor is told to exit.
+//
+// Services are handled concurrently, with the backend of each detected
+// from its supervise directory. Stop the scanner (runsvdir, svscan,
+// s6-svscan) first, or it restarts the supervisors that exit. Errors from
+// individual services are collected into a MultiError; the sequence always
+// runs to the end so one stuck service cannot block the rest. A root that
+// cannot be read is an error, with no service stopped.
+func ShutdownAll(ctx context.Context, root string, grace time.Duration) error {
+	services, err := readServices(root)
+	if err != nil {
+		return fmt.Errorf("shutdown %s: %w", root, err)
+	}
+	var dirs []string
+	for dir := range services {
+		dirs = append(dirs, dir)
+	}
+
+	var (
+		mu   sync.Mutex
+		errs MultiError
+		wg   sync.WaitGroup
+	)
+	add := func(dir string, err error) {
+		if err != nil {
+			mu.Lock()
+			errs.Add(fmt.Errorf("%s: %w", dir, err))
+			mu.Unlock()
+		}
+	}
+
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			add(dir, shutdownOne(ctx, dir, grace))
+		}()
+	}
+	wg.Wait()
+	return errs.Err()
+}
+
+// shutdownOne runs the poweroff sequence for the service in dir
+func shutdownOne(ctx context.Context, dir string, grace time.Duration) error {
+	client, err := NewClient(dir)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	errs.Add(client.Down(ctx))
+
+	waitCtx, cancel := context.WithTimeout(ctx, grace)
+	_, err = waitFunc(waitCtx, client, func(st Status) bool {
+		return st.PID == 0 || st.State == StateDown || st.State == StateExited
+	})
+	cancel()
+	if err != nil {
+		errs.Add(client.Kill(ctx))
+	}
+
+	errs.Add(client.ExitSupervise(ctx))
+	return errs.Err()
+}
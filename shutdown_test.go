@@ -0,0 +1,56 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShutdownAll(t *testing.T) {
+	root := t.TempDir()
+	stopped, err := NewMockSupervisor(filepath.Join(root, "stopped"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stuck, err := NewMockSupervisor(filepath.Join(root, "stuck"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The stuck service keeps running however it is asked to stop
+	if err := stuck.UpdateStatus(true, 4242); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := ShutdownAll(context.Background(), root, 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	// Waiting out the stuck service's grace period, but no longer
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("ShutdownAll took %v with a 200ms grace period", elapsed)
+	}
+
+	// The mock control file keeps only the last command: every supervisor
+	// was told to exit
+	for _, mock := range []*MockSupervisor{stopped, stuck} {
+		data, err := os.ReadFile(mock.ControlFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "x" {
+			t.Errorf("%s last control command = %q, want \"x\"", filepath.Base(mock.ServiceDir), data)
+		}
+	}
+}
+
+func TestShutdownAllUnreadableRoot(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if err := ShutdownAll(context.Background(), missing, time.Millisecond); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ShutdownAll of a missing scan directory = %v, want ErrNotExist", err)
+	}
+}
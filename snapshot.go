@@ -0,0 +1,111 @@
+package svcmgr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Snapshot is the status of a set of services at a point in time
+type Snapshot struct {
+	// Time is when the snapshot was taken
+	Time time.Time
+	// Services maps service directories to their status
+	Services map[string]Status
+}
+
+// TakeSnapshot records the status of every service in the scan directories
+// roots, as discovered by Services. A root that cannot be read is an error,
+// so an unreadable scan directory is not mistaken for one without services.
+func TakeSnapshot(roots ...string) (Snapshot, error) {
+	s := Snapshot{Time: time.Now(), Services: make(map[string]Status)}
+	for _, root := range roots {
+		services, err := readServices(root)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("snapshot %s: %w", root, err)
+		}
+		for dir, st := range services {
+			s.Services[dir] = st
+		}
+	}
+	return s, nil
+}
+
+// ReadSnapshot reads a snapshot saved with Manager.Export in the
+// ExportJSONLines format. The snapshot time is left zero.
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	s := Snapshot{Services: make(map[string]Status)}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec exportRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return Snapshot{}, fmt.Errorf("%w: snapshot line %d: %v", ErrDecode, line, err)
+		}
+		s.Services[rec.Dir] = rec.Status
+	}
+	if err := scanner.Err(); err != nil {
+		return Snapshot{}, err
+	}
+	return s, nil
+}
+
+// ServiceDiff is a service whose status differs between two snapshots
+type ServiceDiff struct {
+	// Service is the service directory
+	Service string
+	// Old and New are the statuses in the earlier and later snapshot
+	Old, New Status
+	// Changes lists the differing fields, as by Status.Diff
+	Changes []StatusChange
+}
+
+// SnapshotDiff lists the differences between two snapshots, each sorted by
+// service directory
+type SnapshotDiff struct {
+	// Added are services only in the later snapshot
+	Added []string
+	// Removed are services only in the earlier snapshot
+	Removed []string
+	// Changed are services in both whose status differs
+	Changed []ServiceDiff
+}
+
+// Empty reports whether the snapshots had no differences
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff reports the services added, removed and changed from snapshot a to
+// snapshot b, e.g. to report what changed during a maintenance window.
+// Statuses are compared with Status.Equal, so uptime alone is no change.
+func Diff(a, b Snapshot) SnapshotDiff {
+	var d SnapshotDiff
+	for _, dir := range slices.Sorted(maps.Keys(a.Services)) {
+		next, ok := b.Services[dir]
+		if !ok {
+			d.Removed = append(d.Removed, dir)
+			continue
+		}
+		if prev := a.Services[dir]; !prev.Equal(next) {
+			d.Changed = append(d.Changed, ServiceDiff{
+				Service: dir,
+				Old:     prev,
+				New:     next,
+				Changes: prev.Diff(next),
+			})
+		}
+	}
+	for _, dir := range slices.Sorted(maps.Keys(b.Services)) {
+		if _, ok := a.Services[dir]; !ok {
+			d.Added = append(d.Added, dir)
+		}
+	}
+	return d
+}
@@ -0,0 +1,68 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := Snapshot{Services: map[string]Status{
+		"/service/web":   {State: StateRunning, PID: 10, Since: since},
+		"/service/db":    {State: StateRunning, PID: 20, Since: since},
+		"/service/cache": {State: StateRunning, PID: 30, Since: since},
+	}}
+	b := Snapshot{Services: map[string]Status{
+		"/service/web":   {State: StateRunning, PID: 10, Since: since, Uptime: time.Hour},
+		"/service/db":    {State: StateDown, Since: since.Add(time.Minute)},
+		"/service/queue": {State: StateRunning, PID: 40},
+	}}
+
+	d := Diff(a, b)
+	if strings.Join(d.Added, ",") != "/service/queue" || strings.Join(d.Removed, ",") != "/service/cache" {
+		t.Errorf("added %v, removed %v", d.Added, d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Service != "/service/db" || d.Changed[0].Changes[0].Field != "State" {
+		t.Errorf("changed = %+v", d.Changed)
+	}
+	if d.Empty() || !Diff(a, a).Empty() {
+		t.Error("Empty() is wrong")
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	in := `{"schema_version":1,"service":"web","dir":"/service/web","status":{"schema_version":1,"state":"running","pid":42}}` + "\n"
+	s, err := ReadSnapshot(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Services["/service/web"]; st.State != StateRunning || st.PID != 42 {
+		t.Errorf("snapshot = %+v", s)
+	}
+	if _, err := ReadSnapshot(strings.NewReader("{")); err == nil {
+		t.Error("ReadSnapshot of bad input should fail")
+	}
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	root := t.TempDir()
+	web := filepath.Join(root, "web")
+	if err := os.MkdirAll(filepath.Join(web, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s, err := TakeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Services[web]; !ok || len(s.Services) != 1 {
+		t.Errorf("snapshot = %+v, want only %s", s.Services, web)
+	}
+
+	if _, err := TakeSnapshot(root, filepath.Join(root, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("TakeSnapshot of a missing scan directory = %v, want ErrNotExist", err)
+	}
+}
@@ -0,0 +1,72 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// StaleSupervise reports whether the supervise directory of the service in
+// serviceDir was left behind by a supervisor that no longer runs: nothing
+// reads its ok (or, for s6, control) FIFO and nothing holds its lock file.
+// The status file of a stale directory describes the last supervised run,
+// not the service's current state. A service that was never supervised is
+// not stale.
+//
+// The lock is probed by taking it briefly, and only once no supervisor
+// reads the FIFO.
+func StaleSupervise(serviceDir string) (bool, error) {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+	if info, err := os.Stat(superviseDir); err != nil || !info.IsDir() {
+		return false, nil
+	}
+	if !exists(filepath.Join(superviseDir, StatusFile)) && !exists(filepath.Join(superviseDir, LockFile)) {
+		return false, nil
+	}
+	if ok, err := Ok(serviceDir); ok || err != nil {
+		return false, err
+	}
+	held, err := lockHeld(filepath.Join(superviseDir, LockFile))
+	return !held, err
+}
+
+// lockHeld reports whether another process holds a flock on path
+func lockHeld(path string) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, &OpError{Op: OpStatus, Path: path, Err: err}
+	}
+	defer func() { _ = f.Close() }()
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return true, nil
+	}
+	if err != nil {
+		return false, &OpError{Op: OpStatus, Path: path, Err: err}
+	}
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false, nil
+}
+
+// Stale reports whether the service's supervise directory was left behind
+// by a supervisor that no longer runs; see StaleSupervise
+func (rc *ClientRunit) Stale() (bool, error) {
+	return StaleSupervise(rc.ServiceDir)
+}
+
+// Stale reports whether the service's supervise directory was left behind
+// by a supervisor that no longer runs; see StaleSupervise
+func (cd *ClientDaemontools) Stale() (bool, error) {
+	return StaleSupervise(cd.ServiceDir)
+}
+
+// Stale reports whether the service's supervise directory was left behind
+// by a supervisor that no longer runs; see StaleSupervise
+func (cs *ClientS6) Stale() (bool, error) {
+	return StaleSupervise(cs.ServiceDir)
+}
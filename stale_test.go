@@ -0,0 +1,85 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// newStaleService creates a service whose supervise directory has a status
+// file, an ok FIFO nobody reads and an unlocked lock file
+func newStaleService(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "web")
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(filepath.Join(mock.SuperviseDir, OkFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mock.SuperviseDir, LockFile), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStaleSupervise(t *testing.T) {
+	t.Run("never supervised", func(t *testing.T) {
+		if stale, err := StaleSupervise(t.TempDir()); stale || err != nil {
+			t.Errorf("StaleSupervise() = %v, %v", stale, err)
+		}
+	})
+
+	t.Run("dead supervisor", func(t *testing.T) {
+		dir := newStaleService(t)
+		if stale, err := StaleSupervise(dir); !stale || err != nil {
+			t.Errorf("StaleSupervise() = %v, %v, want stale", stale, err)
+		}
+		client, err := NewClientRunit(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stale, err := client.Stale(); !stale || err != nil {
+			t.Errorf("Stale() = %v, %v, want stale", stale, err)
+		}
+
+		info := (&ServiceScanner{Root: filepath.Dir(dir)}).Inspect(dir)
+		if !info.Stale || info.Supervised || info.Status.State != StateExited {
+			t.Errorf("Inspect() = stale %v, supervised %v, state %v", info.Stale, info.Supervised, info.Status.State)
+		}
+	})
+
+	t.Run("ok FIFO read", func(t *testing.T) {
+		dir := newStaleService(t)
+		f, err := os.OpenFile(filepath.Join(dir, SuperviseDir, OkFile), os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if stale, err := StaleSupervise(dir); stale || err != nil {
+			t.Errorf("StaleSupervise() = %v, %v, want live", stale, err)
+		}
+	})
+
+	t.Run("lock held", func(t *testing.T) {
+		dir := newStaleService(t)
+		f, err := os.Open(filepath.Join(dir, SuperviseDir, LockFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+			t.Fatal(err)
+		}
+		if stale, err := StaleSupervise(dir); stale || err != nil {
+			t.Errorf("StaleSupervise() = %v, %v, want live", stale, err)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
 )
 
 // StateParser defines the interface for parsing supervision system status files
@@ -38,15 +40,12 @@ func (p *RunitStateParser) Parse(data []byte) (Status, error) {
 	st := Status{}
 
 	// Extract timestamp (bytes 0-7, big-endian TAI64)
-	tai64 := binary.BigEndian.Uint64(data[RunitTAI64Start:RunitTAI64End])
-	if tai64 > TAI64Offset {
-		unixSec := int64(tai64 - TAI64Offset)
-		if unixSec > 0 && unixSec < 253402300800 { // Year 9999
-			st.Since = time.Unix(unixSec, 0)
-			st.Uptime = time.Since(st.Since)
-			if st.Uptime < 0 {
-				st.Uptime = 0
-			}
+	sec := binary.BigEndian.Uint64(data[RunitTAI64Start:RunitTAI64End])
+	if t, ok := labelTime(tai64.Label{Sec: sec}); ok {
+		st.Since = t
+		st.Uptime = time.Since(st.Since)
+		if st.Uptime < 0 {
+			st.Uptime = 0
 		}
 	}
 
@@ -107,15 +106,12 @@ func (p *DaemontoolsStateParser) Parse(data []byte) (Status, error) {
 	st := Status{}
 
 	// Extract timestamp (bytes 0-7, big-endian TAI64)
-	tai64 := binary.BigEndian.Uint64(data[DaemontoolsTAI64Start:DaemontoolsTAI64End])
-	if tai64 > TAI64Offset {
-		unixSec := int64(tai64 - TAI64Offset)
-		if unixSec > 0 && unixSec < 253402300800 {
-			st.Since = time.Unix(unixSec, 0)
-			st.Uptime = time.Since(st.Since)
-			if st.Uptime < 0 {
-				st.Uptime = 0
-			}
+	sec := binary.BigEndian.Uint64(data[DaemontoolsTAI64Start:DaemontoolsTAI64End])
+	if t, ok := labelTime(tai64.Label{Sec: sec}); ok {
+		st.Since = t
+		st.Uptime = time.Since(st.Since)
+		if st.Uptime < 0 {
+			st.Uptime = 0
 		}
 	}
 
@@ -146,10 +142,12 @@ func (p *DaemontoolsStateParser) Parse(data []byte) (Status, error) {
 	return st, nil
 }
 
-// S6StateParserPre220 parses S6 status files for versions < 2.20.0 (35 bytes)
+// S6StateParserPre220 parses S6 status files for versions < 2.11.0.0 (35 bytes)
 type S6StateParserPre220 struct{}
 
-// Name returns the parser name
+// Name returns the parser name. It keeps the historical "s6-pre-2.20.0",
+// though the layout changed in 2.11.0.0, so callers matching on it keep
+// working.
 func (p *S6StateParserPre220) Name() string {
 	return "s6-pre-2.20.0"
 }
@@ -162,7 +160,7 @@ func (p *S6StateParserPre220) ValidateSize(size int) bool {
 // Parse parses the status data and returns a Status
 func (p *S6StateParserPre220) Parse(data []byte) (Status, error) {
 	if len(data) != S6StatusSizePre220 {
-		return Status{}, fmt.Errorf("invalid s6 pre-2.20.0 status size: %d bytes (expected %d)", len(data), S6StatusSizePre220)
+		return Status{}, fmt.Errorf("invalid s6 pre-2.11.0 status size: %d bytes (expected %d)", len(data), S6StatusSizePre220)
 	}
 
 	st := Status{
@@ -173,25 +171,19 @@ func (p *S6StateParserPre220) Parse(data []byte) (Status, error) {
 	st.PID = int(binary.BigEndian.Uint32(data[S6PIDStartPre220:S6PIDEndPre220]))
 
 	// Extract timestamp (bytes 0-7, big-endian TAI64)
-	tai64 := binary.BigEndian.Uint64(data[0:8])
-	if tai64 > TAI64Offset {
-		unixSec := int64(tai64 - TAI64Offset)
-		if unixSec > 0 && unixSec < 253402300800 {
-			st.Since = time.Unix(unixSec, 0)
-			st.Uptime = time.Since(st.Since)
-			if st.Uptime < 0 {
-				st.Uptime = 0
-			}
+	sec := binary.BigEndian.Uint64(data[0:8])
+	if t, ok := labelTime(tai64.Label{Sec: sec}); ok {
+		st.Since = t
+		st.Uptime = time.Since(st.Since)
+		if st.Uptime < 0 {
+			st.Uptime = 0
 		}
 	}
 
 	// Extract ready timestamp (bytes 12-19, big-endian TAI64)
 	readyTai64 := binary.BigEndian.Uint64(data[12:20])
-	if readyTai64 > TAI64Offset {
-		readyUnixSec := int64(readyTai64 - TAI64Offset)
-		if readyUnixSec > 0 && readyUnixSec < 253402300800 {
-			st.ReadySince = time.Unix(readyUnixSec, 0)
-		}
+	if t, ok := labelTime(tai64.Label{Sec: readyTai64}); ok {
+		st.ReadySince = t
 	}
 
 	// Parse flags from byte 34
@@ -211,7 +203,7 @@ func (p *S6StateParserPre220) Parse(data []byte) (Status, error) {
 	return st, nil
 }
 
-// S6StateParserCurrent parses S6 status files for versions >= 2.20.0 (43 bytes)
+// S6StateParserCurrent parses S6 status files for versions >= 2.11.0.0 (43 bytes)
 type S6StateParserCurrent struct{}
 
 // Name returns the parser name
@@ -239,25 +231,19 @@ func (p *S6StateParserCurrent) Parse(data []byte) (Status, error) {
 	st.PID = int(pid)
 
 	// Extract timestamp (bytes 0-7, big-endian TAI64)
-	tai64 := binary.BigEndian.Uint64(data[0:8])
-	if tai64 > TAI64Offset {
-		unixSec := int64(tai64 - TAI64Offset)
-		if unixSec > 0 && unixSec < 253402300800 {
-			st.Since = time.Unix(unixSec, 0)
-			st.Uptime = time.Since(st.Since)
-			if st.Uptime < 0 {
-				st.Uptime = 0
-			}
+	sec := binary.BigEndian.Uint64(data[0:8])
+	if t, ok := labelTime(tai64.Label{Sec: sec}); ok {
+		st.Since = t
+		st.Uptime = time.Since(st.Since)
+		if st.Uptime < 0 {
+			st.Uptime = 0
 		}
 	}
 
 	// Extract ready timestamp (bytes 12-19, big-endian TAI64)
 	readyTai64 := binary.BigEndian.Uint64(data[12:20])
-	if readyTai64 > TAI64Offset {
-		readyUnixSec := int64(readyTai64 - TAI64Offset)
-		if readyUnixSec > 0 && readyUnixSec < 253402300800 {
-			st.ReadySince = time.Unix(readyUnixSec, 0)
-		}
+	if t, ok := labelTime(tai64.Label{Sec: readyTai64}); ok {
+		st.ReadySince = t
 	}
 
 	// Parse flags from byte 42
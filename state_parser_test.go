@@ -177,11 +177,11 @@ func TestDaemontoolsStateParser(t *testing.T) {
 func TestS6StateParserPre220(t *testing.T) {
 	parser := &S6StateParserPre220{}
 
-	// Helper to create pre-2.20 S6 status data
+	// Helper to create pre-2.11 S6 status data
 	createPre220Data := func(pid uint32, flags byte) []byte {
 		data := make([]byte, S6StatusSizePre220)
 		// TAI64N timestamp at bytes 0-11
-		tai64 := uint64(time.Now().Unix()) + TAI64Offset
+		tai64 := uint64(time.Now().Unix()) + TAI64Base
 		binary.BigEndian.PutUint64(data[0:8], tai64)
 		binary.BigEndian.PutUint32(data[8:12], 0) // nanoseconds
 
@@ -278,11 +278,11 @@ func TestS6StateParserPre220(t *testing.T) {
 func TestS6StateParserCurrent(t *testing.T) {
 	parser := &S6StateParserCurrent{}
 
-	// Helper to create current S6 status data (>= 2.20.0)
+	// Helper to create current S6 status data (>= 2.11.0.0)
 	createCurrentData := func(pid uint64, pgid uint64, flags byte) []byte {
 		data := make([]byte, S6StatusSizeCurrent)
 		// TAI64N timestamp at bytes 0-11
-		tai64 := uint64(time.Now().Unix()) + TAI64Offset
+		tai64 := uint64(time.Now().Unix()) + TAI64Base
 		binary.BigEndian.PutUint64(data[0:8], tai64)
 		binary.BigEndian.PutUint32(data[8:12], 0) // nanoseconds
 
@@ -0,0 +1,215 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultStatsdAddr is the default statsd agent address
+	DefaultStatsdAddr = "127.0.0.1:8125"
+	// DefaultStatsdPrefix is the default metric name prefix
+	DefaultStatsdPrefix = "svcmgr."
+	// DefaultStatsdInterval is the default push interval
+	DefaultStatsdInterval = 10 * time.Second
+)
+
+// statsdMaxPacket keeps packets within a typical Ethernet MTU
+const statsdMaxPacket = 1432
+
+// StatsdEmitter periodically pushes per-service metrics to a statsd or
+// DogStatsD agent over UDP:
+//
+//	<prefix><service>.up:1|g            running (1) or not (0)
+//	<prefix><service>.uptime_seconds:42|g
+//	<prefix><service>.restarts:1|c      process changes since the last push
+//
+// With DogStatsD set, the service is sent as a "service:<name>" tag instead
+// of being part of the metric name (e.g. "svcmgr.up:1|g|#service:web").
+type StatsdEmitter struct {
+	// Addr is the agent's host:port
+	Addr string
+	// Prefix is prepended to every metric name
+	Prefix string
+	// Interval is the push interval used by Run
+	Interval time.Duration
+	// DogStatsD sends the service name and Tags as DogStatsD tags
+	DogStatsD bool
+	// Tags are extra DogStatsD tags ("env:prod") added to every metric
+	Tags []string
+
+	// Clients are the services to report, keyed by metric name
+	Clients map[string]ServiceClient
+	// ScanDir, when set, also reports every service in a scan directory
+	// such as /etc/service, named after its directory
+	ScanDir string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	lastPID map[string]int
+}
+
+// NewStatsdEmitter creates an emitter reporting clients to the agent at addr
+func NewStatsdEmitter(addr string, clients map[string]ServiceClient) *StatsdEmitter {
+	return &StatsdEmitter{
+		Addr:     addr,
+		Prefix:   DefaultStatsdPrefix,
+		Interval: DefaultStatsdInterval,
+		Clients:  clients,
+	}
+}
+
+// Run pushes metrics every Interval until ctx is done, returning ctx.Err().
+// Failed pushes are retried at the next interval.
+func (e *StatsdEmitter) Run(ctx context.Context) error {
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultStatsdInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = e.Emit(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Emit reads every service's status and pushes one round of metrics.
+// Services whose status cannot be read are skipped and reported in a
+// MultiError after the metrics for the others are sent.
+func (e *StatsdEmitter) Emit(ctx context.Context) error {
+	statuses := make(map[string]Status)
+	var errs MultiError
+	for name, client := range e.Clients {
+		st, err := client.Status(ctx)
+		if err != nil {
+			errs.Add(fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		statuses[name] = st
+	}
+	if e.ScanDir != "" {
+		for dir, st := range Services(e.ScanDir) {
+			if st.State != StateUnknown {
+				statuses[filepath.Base(dir)] = st
+			}
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var lines []string
+	for _, name := range slices.Sorted(maps.Keys(statuses)) {
+		lines = append(lines, e.lines(name, statuses[name])...)
+	}
+	if err := e.send(lines); err != nil {
+		errs.Add(err)
+	}
+	return errs.Err()
+}
+
+// Close closes the connection to the agent
+func (e *StatsdEmitter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// lines formats the metrics for one service and updates its restart tracking
+func (e *StatsdEmitter) lines(name string, st Status) []string {
+	if e.lastPID == nil {
+		e.lastPID = make(map[string]int)
+	}
+	restarts := 0
+	if last := e.lastPID[name]; last != 0 && st.PID != 0 && st.PID != last {
+		restarts = 1
+	}
+	if st.PID != 0 {
+		e.lastPID[name] = st.PID
+	}
+
+	up := 0
+	if st.State == StateRunning {
+		up = 1
+	}
+	return []string{
+		e.line(name, "up", strconv.Itoa(up), "g"),
+		e.line(name, "uptime_seconds", strconv.FormatInt(int64(st.Uptime.Seconds()), 10), "g"),
+		e.line(name, "restarts", strconv.Itoa(restarts), "c"),
+	}
+}
+
+// line formats a single metric in statsd or DogStatsD syntax
+func (e *StatsdEmitter) line(service, metric, value, kind string) string {
+	if !e.DogStatsD {
+		return e.Prefix + statsdName(service) + "." + metric + ":" + value + "|" + kind
+	}
+	tags := append([]string{"service:" + service}, e.Tags...)
+	return e.Prefix + metric + ":" + value + "|" + kind + "|#" + strings.Join(tags, ",")
+}
+
+// send writes lines to the agent, batching as many as fit in a packet
+func (e *StatsdEmitter) send(lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	if e.conn == nil {
+		addr := e.Addr
+		if addr == "" {
+			addr = DefaultStatsdAddr
+		}
+		conn, err := net.Dial("udp", addr)
+		if err != nil {
+			return fmt.Errorf("statsd: %w", err)
+		}
+		e.conn = conn
+	}
+
+	var errs []error
+	var packet strings.Builder
+	flush := func() {
+		if packet.Len() == 0 {
+			return
+		}
+		if _, err := e.conn.Write([]byte(packet.String())); err != nil {
+			errs = append(errs, fmt.Errorf("statsd: %w", err))
+		}
+		packet.Reset()
+	}
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacket {
+			flush()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	flush()
+	return errors.Join(errs...)
+}
+
+// statsdName replaces the characters statsd treats as separators
+func statsdName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_", "|", "_", "@", "_", " ", "_").Replace(name)
+}
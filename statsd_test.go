@@ -0,0 +1,69 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdEmitter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	scanDir := t.TempDir()
+	mock, err := NewMockSupervisor(filepath.Join(scanDir, "web.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() string {
+		t.Helper()
+		buf := make([]byte, statsdMaxPacket)
+		_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+
+	e := NewStatsdEmitter(pc.LocalAddr().String(), nil)
+	e.ScanDir = scanDir
+	defer e.Close()
+
+	ctx := context.Background()
+	if err := e.Emit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := read()
+	for _, want := range []string{"svcmgr.web_1.up:1|g", "svcmgr.web_1.uptime_seconds:", "svcmgr.web_1.restarts:0|c"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("packet missing %q:\n%s", want, got)
+		}
+	}
+
+	// A new PID counts as a restart; DogStatsD moves the service into a tag
+	if err := mock.UpdateStatus(true, 101); err != nil {
+		t.Fatal(err)
+	}
+	e.DogStatsD = true
+	e.Tags = []string{"env:test"}
+	if err := e.Emit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got = read()
+	if want := "svcmgr.restarts:1|c|#service:web.1,env:test"; !strings.Contains(got, want) {
+		t.Errorf("packet missing %q:\n%s", want, got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
 )
 
 // State represents the current state of a runit service
@@ -36,19 +38,36 @@ type S6FormatVersion int
 const (
 	// S6FormatUnknown indicates the format could not be determined
 	S6FormatUnknown S6FormatVersion = iota
-	// S6FormatPre220 is the old 35-byte format (S6 < 2.20.x)
+	// S6FormatPre220 is the old 35-byte format of s6 before 2.11.0.0; the
+	// name is historical
 	S6FormatPre220
-	// S6FormatCurrent is the new 43-byte format (S6 >= 2.20.0)
+	// S6FormatCurrent is the 43-byte format of s6 2.11.0.0 and later
 	S6FormatCurrent
+	// S6FormatExtended is a larger layout from a newer s6 release, decoded
+	// from the known fields it starts with (see negotiateS6Format)
+	S6FormatExtended
 )
 
 // TAI64 constants
 const (
 	// TAI64Offset is the TAI64 epoch offset (2^62)
 	// TAI64 stores seconds since 1970-01-01 00:00:00 TAI
-	TAI64Offset = uint64(1) << 62
+	//
+	// Deprecated: supervisors label the Unix epoch TAI64Base, 10 seconds
+	// later; convert timestamps with package tai64.
+	TAI64Offset = tai64.Epoch
 )
 
+// labelTime returns the time of a TAI64N label, reporting false for labels
+// before the Unix epoch, such as the all-zero ones written for timestamps
+// never set, and after year 9999
+func labelTime(l tai64.Label) (time.Time, bool) {
+	if l.Sec <= tai64.UnixEpoch || l.Sec > maxTAI64Sec {
+		return time.Time{}, false
+	}
+	return l.Time(), true
+}
+
 // S6 status flag bits (byte 0 of S6 status file)
 const (
 	S6FlagUp         = 1 << 0 // bit 0: service is up
@@ -119,6 +138,7 @@ type Status struct {
 	// Note: This value becomes stale immediately after reading as time progresses.
 	// It's included for convenience and compatibility with sv output format.
 	// For accurate time calculations, use Since with time.Since(status.Since).
+	// Clients and decoders compute it against their Clock when one is set.
 	Uptime time.Duration
 	// Ready indicates if the service has signaled readiness (S6 and potentially systemd)
 	// For S6: Set when the service has sent a readiness notification
@@ -135,13 +155,25 @@ type Status struct {
 	S6Format S6FormatVersion
 }
 
-// DecodeStatusRunit decodes a 20-byte runit status file
-func DecodeStatusRunit(data []byte) (Status, error) {
-	return decodeStatusRunit(data)
+// DecodeStatusRunit decodes a 20-byte runit status file.
+// Pass WithStrictDecode to reject corrupt records instead of decoding them best-effort.
+func DecodeStatusRunit(data []byte, opts ...DecodeOption) (Status, error) {
+	cfg := newDecodeConfig(opts)
+	if cfg.strict {
+		if err := validateStatusRunit(data); err != nil {
+			return Status{}, err
+		}
+	}
+	return decodeStatusRunitAt(data, cfg.now())
 }
 
-// decodeStatusRunit decodes a 20-byte runit status file
+// decodeStatusRunit decodes a 20-byte runit status file using the wall clock
 func decodeStatusRunit(data []byte) (Status, error) {
+	return decodeStatusRunitAt(data, time.Now())
+}
+
+// decodeStatusRunitAt decodes a 20-byte runit status file, computing Uptime relative to now
+func decodeStatusRunitAt(data []byte, now time.Time) (Status, error) {
 	if len(data) != RunitStatusSize {
 		return Status{}, fmt.Errorf("%w: runit status file must be %d bytes, got %d", ErrDecode, RunitStatusSize, len(data))
 	}
@@ -152,15 +184,12 @@ func decodeStatusRunit(data []byte) (Status, error) {
 	// Decode TAI64N timestamp
 	tai64Sec := binary.BigEndian.Uint64(data[RunitTAI64Start:RunitTAI64End])
 	tai64Nano := binary.BigEndian.Uint32(data[RunitNanoStart:RunitNanoEnd])
-	if tai64Sec > TAI64Offset {
-		unixSec := int64(tai64Sec - TAI64Offset)
-		if unixSec > 0 && unixSec < 253402300800 { // Sanity check: before year 10000
-			st.Since = time.Unix(unixSec, int64(tai64Nano))
-			st.Uptime = time.Since(st.Since)
-			// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
-			if st.Uptime < 0 {
-				st.Uptime = 0
-			}
+	if t, ok := labelTime(tai64.Label{Sec: tai64Sec, Nano: tai64Nano}); ok {
+		st.Since = t
+		st.Uptime = now.Sub(st.Since)
+		// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
+		if st.Uptime < 0 {
+			st.Uptime = 0
 		}
 	}
 
@@ -207,13 +236,25 @@ func decodeStatusRunit(data []byte) (Status, error) {
 	return st, nil
 }
 
-// DecodeStatusDaemontools decodes an 18-byte daemontools status file
-func DecodeStatusDaemontools(data []byte) (Status, error) {
-	return decodeStatusDaemontools(data)
+// DecodeStatusDaemontools decodes an 18-byte daemontools status file.
+// Pass WithStrictDecode to reject corrupt records instead of decoding them best-effort.
+func DecodeStatusDaemontools(data []byte, opts ...DecodeOption) (Status, error) {
+	cfg := newDecodeConfig(opts)
+	if cfg.strict {
+		if err := validateStatusDaemontools(data); err != nil {
+			return Status{}, err
+		}
+	}
+	return decodeStatusDaemontoolsAt(data, cfg.now())
 }
 
-// decodeStatusDaemontools decodes an 18-byte daemontools status file
+// decodeStatusDaemontools decodes an 18-byte daemontools status file using the wall clock
 func decodeStatusDaemontools(data []byte) (Status, error) {
+	return decodeStatusDaemontoolsAt(data, time.Now())
+}
+
+// decodeStatusDaemontoolsAt decodes an 18-byte daemontools status file, computing Uptime relative to now
+func decodeStatusDaemontoolsAt(data []byte, now time.Time) (Status, error) {
 	if len(data) != DaemontoolsStatusSize {
 		return Status{}, fmt.Errorf("%w: daemontools status file must be %d bytes, got %d", ErrDecode, DaemontoolsStatusSize, len(data))
 	}
@@ -225,15 +266,12 @@ func decodeStatusDaemontools(data []byte) (Status, error) {
 	// Decode TAI64N timestamp
 	tai64Sec := binary.BigEndian.Uint64(data[DaemontoolsTAI64Start:DaemontoolsTAI64End])
 	tai64Nano := binary.BigEndian.Uint32(data[DaemontoolsNanoStart:DaemontoolsNanoEnd])
-	if tai64Sec > TAI64Offset {
-		unixSec := int64(tai64Sec - TAI64Offset)
-		if unixSec > 0 && unixSec < 253402300800 { // Sanity check: before year 10000
-			st.Since = time.Unix(unixSec, int64(tai64Nano))
-			st.Uptime = time.Since(st.Since)
-			// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
-			if st.Uptime < 0 {
-				st.Uptime = 0
-			}
+	if t, ok := labelTime(tai64.Label{Sec: tai64Sec, Nano: tai64Nano}); ok {
+		st.Since = t
+		st.Uptime = now.Sub(st.Since)
+		// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
+		if st.Uptime < 0 {
+			st.Uptime = 0
 		}
 	}
 
@@ -281,28 +319,61 @@ func decodeStatusDaemontools(data []byte) (Status, error) {
 	return st, nil
 }
 
-// DecodeStatusS6 decodes an s6 status file (35 bytes)
-func DecodeStatusS6(data []byte) (Status, error) {
-	return decodeStatusS6(data)
+// DecodeStatusS6 decodes an s6 status file (35 or 43 bytes, or a larger
+// layout negotiated as S6FormatExtended). Pass WithStrictDecode to reject
+// corrupt records instead of decoding them best-effort, and WithS6Format to
+// pick the layout of files of unknown size by the s6 release.
+func DecodeStatusS6(data []byte, opts ...DecodeOption) (Status, error) {
+	cfg := newDecodeConfig(opts)
+	if cfg.strict {
+		if err := validateStatusS6(data, cfg.s6Format); err != nil {
+			return Status{}, err
+		}
+	}
+	return decodeStatusS6Hint(data, cfg.now(), cfg.s6Format)
+}
+
+// decodeStatusS6 decodes an s6 status file using the wall clock
+func decodeStatusS6(data []byte) (Status, error) {
+	return decodeStatusS6At(data, time.Now())
+}
+
+// decodeStatusS6At decodes an s6 status file, computing Uptime relative to now
+func decodeStatusS6At(data []byte, now time.Time) (Status, error) {
+	return decodeStatusS6Hint(data, now, S6FormatUnknown)
+}
+
+// decodeStatusS6Hint decodes an s6 status file of any size, negotiating the
+// layout of unknown sizes with hint as the expected format
+func decodeStatusS6Hint(data []byte, now time.Time, hint S6FormatVersion) (Status, error) {
+	layout, err := negotiateS6Format(data, hint)
+	if err != nil {
+		return Status{}, err
+	}
+	st, err := decodeStatusS6Layout(data[:s6FormatSize(layout)], now)
+	if err == nil && len(data) != s6FormatSize(layout) {
+		st.S6Format = S6FormatExtended
+	}
+	return st, err
 }
 
-// decodeStatusS6 decodes an s6 status file
+// decodeStatusS6Layout decodes an s6 status record of a known layout.
 // Supports two formats:
 //
-//	Old format < v2.20.0 (35 bytes):
+//	Old format < v2.11.0.0 (35 bytes):
 //	 bytes 0-11:  TAI64N timestamp
 //	 bytes 12-23: TAI64N ready timestamp
 //	 bytes 24-27: reserved/zeros
 //	 bytes 28-31: PID (big-endian uint32)
 //	 bytes 32-34: flags/status
-//	New format >= v2.20.0 (43 bytes):
+//	New format >= v2.11.0.0 (43 bytes):
 //	 bytes 0-11:  tain timestamp
 //	 bytes 12-23: tain readystamp
 //	 bytes 24-31: PID (big-endian uint64)
 //	 bytes 32-39: PGID (big-endian uint64)
 //	 bytes 40-41: wstat (big-endian uint16)
 //	 byte 42:     flags
-func decodeStatusS6(data []byte) (Status, error) {
+func decodeStatusS6Layout(data []byte, now time.Time) (Status, error) {
 	var st Status
 
 	// Only copy first 20 bytes to Raw field for compatibility
@@ -312,32 +383,26 @@ func decodeStatusS6(data []byte) (Status, error) {
 
 	switch len(data) {
 	case S6StatusSizePre220:
-		// S6 format < v2.20.0 S6
+		// S6 format < v2.11.0.0 S6
 		st.S6Format = S6FormatPre220
 		// PID is at bytes 28-31 as big-endian uint32
 		st.PID = int(binary.BigEndian.Uint32(data[S6PIDStartPre220:S6PIDEndPre220]))
 
 		// Extract timestamp (bytes 0-7, big-endian TAI64)
-		tai64 := binary.BigEndian.Uint64(data[0:8])
-		if tai64 > TAI64Offset {
-			unixSec := int64(tai64 - TAI64Offset)
-			if unixSec > 0 && unixSec < 253402300800 {
-				st.Since = time.Unix(unixSec, 0)
-				st.Uptime = time.Since(st.Since)
-				// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
-				if st.Uptime < 0 {
-					st.Uptime = 0
-				}
+		sec := binary.BigEndian.Uint64(data[0:8])
+		if t, ok := labelTime(tai64.Label{Sec: sec}); ok {
+			st.Since = t
+			st.Uptime = now.Sub(st.Since)
+			// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
+			if st.Uptime < 0 {
+				st.Uptime = 0
 			}
 		}
 
 		// Extract ready timestamp (bytes 12-19, big-endian TAI64)
 		readyTai64 := binary.BigEndian.Uint64(data[12:20])
-		if readyTai64 > TAI64Offset {
-			readyUnixSec := int64(readyTai64 - TAI64Offset)
-			if readyUnixSec > 0 && readyUnixSec < 253402300800 {
-				st.ReadySince = time.Unix(readyUnixSec, 0)
-			}
+		if t, ok := labelTime(tai64.Label{Sec: readyTai64}); ok {
+			st.ReadySince = t
 		}
 
 		// Parse flags from byte 34
@@ -365,26 +430,20 @@ func decodeStatusS6(data []byte) (Status, error) {
 		st.PID = int(pid)
 
 		// Extract timestamp (bytes 0-7, big-endian TAI64)
-		tai64 := binary.BigEndian.Uint64(data[0:8])
-		if tai64 > TAI64Offset {
-			unixSec := int64(tai64 - TAI64Offset)
-			if unixSec > 0 && unixSec < 253402300800 {
-				st.Since = time.Unix(unixSec, 0)
-				st.Uptime = time.Since(st.Since)
-				// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
-				if st.Uptime < 0 {
-					st.Uptime = 0
-				}
+		sec := binary.BigEndian.Uint64(data[0:8])
+		if t, ok := labelTime(tai64.Label{Sec: sec}); ok {
+			st.Since = t
+			st.Uptime = now.Sub(st.Since)
+			// Ensure uptime is never negative (can happen with corrupted/fuzzed data)
+			if st.Uptime < 0 {
+				st.Uptime = 0
 			}
 		}
 
 		// Extract ready timestamp (bytes 12-19, big-endian TAI64)
 		readyTai64 := binary.BigEndian.Uint64(data[12:20])
-		if readyTai64 > TAI64Offset {
-			readyUnixSec := int64(readyTai64 - TAI64Offset)
-			if readyUnixSec > 0 && readyUnixSec < 253402300800 {
-				st.ReadySince = time.Unix(readyUnixSec, 0)
-			}
+		if t, ok := labelTime(tai64.Label{Sec: readyTai64}); ok {
+			st.ReadySince = t
 		}
 
 		// Parse flags from byte 42
@@ -7,79 +7,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strconv"
-	"strings"
 	"testing"
 	"time"
 )
 
-// parseRunitSv parses output from runit's sv status command
-// Example: "run: /service/test: (pid 12345) 5s"
-func parseRunitSv(output string) (pid int, state string, err error) {
-	output = strings.TrimSpace(output)
-
-	// Parse state and PID
-	switch {
-	case strings.HasPrefix(output, "run:"):
-		state = "run"
-		re := regexp.MustCompile(`\(pid (\d+)\)`)
-		if matches := re.FindStringSubmatch(output); len(matches) > 1 {
-			pid, _ = strconv.Atoi(matches[1])
-		}
-	case strings.HasPrefix(output, "down:"):
-		state = opDownStr
-	case strings.HasPrefix(output, "pause:"):
-		state = "pause"
-	case strings.HasPrefix(output, "finish:"):
-		state = "finish"
-	default:
-		state = strings.SplitN(output, ":", 2)[0]
-	}
-
-	return pid, state, nil
-}
-
-// parseDaemontoolsSvstat parses output from daemontools' svstat command
-// Example: "/service/test: up (pid 12345) 5 seconds"
-func parseDaemontoolsSvstat(output string) (pid int, state string, err error) {
-	output = strings.TrimSpace(output)
-
-	switch {
-	case strings.Contains(output, ": up"):
-		state = "up"
-		re := regexp.MustCompile(`\(pid (\d+)\)`)
-		if matches := re.FindStringSubmatch(output); len(matches) > 1 {
-			pid, _ = strconv.Atoi(matches[1])
-		}
-	case strings.Contains(output, ": down"):
-		state = opDownStr
-	case strings.Contains(output, ": paused"):
-		state = "paused"
-	}
-
-	return pid, state, nil
-}
-
-// parseS6Svstat parses output from s6's s6-svstat command
-// Example: "up (pid 12345) 5 seconds"
-func parseS6Svstat(output string) (pid int, state string, err error) {
-	output = strings.TrimSpace(output)
-
-	// s6-svstat output is simpler
-	parts := strings.Fields(output)
-	if len(parts) > 0 {
-		state = parts[0]
-	}
-
-	re := regexp.MustCompile(`\(pid (\d+)\)`)
-	if matches := re.FindStringSubmatch(output); len(matches) > 1 {
-		pid, _ = strconv.Atoi(matches[1])
-	}
-
-	return pid, state, nil
-}
-
 // TestStatusDecodeAgainstRealTools compares our decoders against real supervision tools
 func TestStatusDecodeAgainstRealTools(t *testing.T) {
 	if testing.Short() {
@@ -92,28 +23,28 @@ func TestStatusDecodeAgainstRealTools(t *testing.T) {
 		svstatCmd   []string // Command and subcommand as array
 		serviceType ServiceType
 		decoder     func([]byte) (Status, error)
-		parser      func(string) (int, string, error)
+		parser      func(string) (Status, error)
 	}{
 		{
 			name:        "runit",
 			svstatCmd:   []string{"sv", "status"}, // sv needs 'status' subcommand
 			serviceType: ServiceTypeRunit,
 			decoder:     decodeStatusRunit,
-			parser:      parseRunitSv,
+			parser:      ParseSvStatus,
 		},
 		{
 			name:        "daemontools",
 			svstatCmd:   []string{"svstat"}, // svstat takes directory directly
 			serviceType: ServiceTypeDaemontools,
 			decoder:     decodeStatusDaemontools,
-			parser:      parseDaemontoolsSvstat,
+			parser:      ParseSvstat,
 		},
 		{
 			name:        "s6",
 			svstatCmd:   []string{"s6-svstat"}, // s6-svstat takes directory directly
 			serviceType: ServiceTypeS6,
 			decoder:     decodeStatusS6,
-			parser:      parseS6Svstat,
+			parser:      ParseS6Svstat,
 		},
 	}
 
@@ -231,46 +162,20 @@ exec sleep 3600
 					}
 
 					// Parse real tool output
-					realPID, realState, err := tool.parser(string(output))
+					real, err := tool.parser(string(output))
 					if err != nil {
 						t.Fatalf("Failed to parse %s output: %v", tool.svstatCmd[0], err)
 					}
 
 					// Compare PID
-					if status.PID != realPID {
-						t.Errorf("PID mismatch: our decoder=%d, %s=%d", status.PID, tool.svstatCmd[0], realPID)
+					if status.PID != real.PID {
+						t.Errorf("PID mismatch: our decoder=%d, %s=%d", status.PID, tool.svstatCmd[0], real.PID)
 						t.Logf("Status data (hex): %x", statusData)
 					}
 
-					// Compare state (map our states to tool states)
-					ourState := status.State.String()
-					switch tool.serviceType {
-					case ServiceTypeRunit:
-						// Map our states to runit svstat states
-						switch status.State {
-						case StateRunning:
-							ourState = "run"
-						case StateDown:
-							ourState = "down"
-						case StatePaused:
-							ourState = "pause"
-						case StateFinishing:
-							ourState = "finish"
-						}
-					case ServiceTypeDaemontools, ServiceTypeS6:
-						// Map our states to daemontools/s6 states
-						switch status.State {
-						case StateRunning:
-							ourState = "up"
-						case StateDown:
-							ourState = "down"
-						case StatePaused:
-							ourState = "paused"
-						}
-					}
-
-					if ourState != realState {
-						t.Errorf("State mismatch: our decoder=%s, %s=%s", ourState, tool.svstatCmd[0], realState)
+					// Compare state
+					if status.State != real.State {
+						t.Errorf("State mismatch: our decoder=%s, %s=%s", status.State, tool.svstatCmd[0], real.State)
 						t.Logf("Status data (hex): %x", statusData)
 						t.Logf("Our full status: %+v", status)
 					}
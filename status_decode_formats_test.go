@@ -291,7 +291,7 @@ func TestMockSupervisorEncodingRunit(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			statusData := make([]byte, 20)
 			now := time.Now()
-			tai64 := uint64(now.Unix()) + TAI64Offset
+			tai64 := uint64(now.Unix()) + TAI64Base
 
 			// Encode like the corrected mock supervisor (matching real runit format)
 			// TAI64N timestamp at bytes 0-11 (big-endian)
@@ -353,7 +353,7 @@ func TestMockSupervisorEncodingDaemontools(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			statusData := make([]byte, 18)
 			now := time.Now()
-			tai64 := uint64(now.Unix()) + TAI64Offset
+			tai64 := uint64(now.Unix()) + TAI64Base
 
 			// Encode like the corrected mock supervisor (matching real daemontools format)
 			// TAI64N timestamp at bytes 0-11 (big-endian)
@@ -409,7 +409,7 @@ func TestMockSupervisorEncodingS6(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			statusData := make([]byte, 35)
 			now := time.Now()
-			tai64 := uint64(now.Unix()) + TAI64Offset
+			tai64 := uint64(now.Unix()) + TAI64Base
 
 			// Encode using the old S6 format (35 bytes)
 			// bytes 0-11: TAI64N timestamp
@@ -99,7 +99,7 @@ func makeStatusData(pid int, want byte, paused byte, running byte, opts ...statu
 	statusData := make([]byte, RunitStatusSize)
 
 	now := time.Now()
-	tai64 := uint64(now.Unix()) + TAI64Offset
+	tai64 := uint64(now.Unix()) + TAI64Base
 
 	// TAI64N timestamp (big-endian)
 	binary.BigEndian.PutUint64(statusData[RunitTAI64Start:RunitTAI64End], tai64)
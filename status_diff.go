@@ -0,0 +1,69 @@
+package svcmgr
+
+import (
+	"strconv"
+	"time"
+)
+
+// StatusChange describes one field that differs between two statuses
+type StatusChange struct {
+	// Field is the Status field name, with Flags fields as "Flags.WantUp" etc.
+	Field string `json:"field"`
+	// Old is the formatted value in the earlier status
+	Old string `json:"old"`
+	// New is the formatted value in the later status
+	New string `json:"new"`
+}
+
+// Equal reports whether two statuses describe the same service state.
+// The volatile Uptime field and the Raw record are ignored; Since and
+// ReadySince are compared as instants.
+func (s Status) Equal(other Status) bool {
+	return s.State == other.State &&
+		s.PID == other.PID &&
+		s.Since.Equal(other.Since) &&
+		s.Ready == other.Ready &&
+		s.ReadySince.Equal(other.ReadySince) &&
+		s.Flags == other.Flags &&
+		s.S6Format == other.S6Format
+}
+
+// Changed reports whether next differs from s in any field compared by Equal
+func (s Status) Changed(next Status) bool {
+	return !s.Equal(next)
+}
+
+// Diff lists the fields compared by Equal that differ from s to next, in
+// declaration order. It returns nil when the statuses are equal.
+func (s Status) Diff(next Status) []StatusChange {
+	var changes []StatusChange
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, StatusChange{Field: field, Old: old, New: new})
+		}
+	}
+
+	add("State", s.State.String(), next.State.String())
+	add("PID", strconv.Itoa(s.PID), strconv.Itoa(next.PID))
+	if !s.Since.Equal(next.Since) {
+		changes = append(changes, StatusChange{Field: "Since", Old: formatDiffTime(s.Since), New: formatDiffTime(next.Since)})
+	}
+	add("Ready", strconv.FormatBool(s.Ready), strconv.FormatBool(next.Ready))
+	if !s.ReadySince.Equal(next.ReadySince) {
+		changes = append(changes, StatusChange{Field: "ReadySince", Old: formatDiffTime(s.ReadySince), New: formatDiffTime(next.ReadySince)})
+	}
+	add("Flags.WantUp", strconv.FormatBool(s.Flags.WantUp), strconv.FormatBool(next.Flags.WantUp))
+	add("Flags.WantDown", strconv.FormatBool(s.Flags.WantDown), strconv.FormatBool(next.Flags.WantDown))
+	add("Flags.NormallyUp", strconv.FormatBool(s.Flags.NormallyUp), strconv.FormatBool(next.Flags.NormallyUp))
+	add("S6Format", s.S6Format.String(), next.S6Format.String())
+
+	return changes
+}
+
+// formatDiffTime formats a timestamp for a StatusChange, with "" for the zero time
+func formatDiffTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,65 @@
+package svcmgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusEqualAndDiff(t *testing.T) {
+	since := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := Status{State: StateRunning, PID: 10, Since: since, Uptime: time.Second, Flags: Flags{WantUp: true}}
+
+	tests := []struct {
+		name       string
+		next       Status
+		wantFields []string
+	}{
+		{
+			name: "uptime and raw only",
+			next: func() Status {
+				s := base
+				s.Uptime = time.Hour
+				s.Raw[0] = 1
+				return s
+			}(),
+		},
+		{
+			name: "same instant in another zone",
+			next: func() Status {
+				s := base
+				s.Since = since.In(time.FixedZone("X", 3600))
+				return s
+			}(),
+		},
+		{
+			name:       "restart",
+			next:       Status{State: StateRunning, PID: 11, Since: since.Add(time.Minute), Flags: Flags{WantUp: true}},
+			wantFields: []string{"PID", "Since"},
+		},
+		{
+			name:       "stopped",
+			next:       Status{State: StateDown, Since: since, Flags: Flags{WantDown: true}},
+			wantFields: []string{"State", "PID", "Flags.WantUp", "Flags.WantDown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := base.Diff(tt.next)
+			if base.Equal(tt.next) != (len(tt.wantFields) == 0) {
+				t.Errorf("Equal() = %v with changes %v", base.Equal(tt.next), changes)
+			}
+			if base.Changed(tt.next) == base.Equal(tt.next) {
+				t.Error("Changed() must be the negation of Equal()")
+			}
+			if len(changes) != len(tt.wantFields) {
+				t.Fatalf("Diff() = %+v, want fields %v", changes, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if changes[i].Field != field {
+					t.Errorf("change %d field = %s, want %s", i, changes[i].Field, field)
+				}
+			}
+		})
+	}
+}
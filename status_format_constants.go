@@ -37,18 +37,23 @@ const (
 
 // S6 status file formats
 // S6 has two incompatible formats:
-// - Old format (35 bytes): Older S6 versions (exact cutoff unclear, but suspect < 2.20.0 as 2.12.0.x uses this)
-// - New format (43 bytes): Newer S6 versions (current upstream uses this, >= 2.20.0)
+// - Old format (35 bytes): s6 before 2.11.0.0
+// - New format (43 bytes): s6 2.11.0.0 and later, which added the process group ID
+// The Pre220 names are historical; they designate the layout before 2.11.0.0.
 const (
-	S6StatusSizePre220  = 35 // Pre-2.20.0 format size
-	S6StatusSizeCurrent = 43 // Current format size (>= 2.20.0)
+	S6StatusSizePre220  = 35 // Format size before s6 2.11.0.0
+	S6StatusSizeCurrent = 43 // Current format size (s6 >= 2.11.0.0)
 
 	// S6MaxStatusSize is the maximum size of any S6 status format.
 	// We use this when allocating buffers to ensure we can read any S6 status file version.
 	// This allows us to read the actual file size and then determine which format to use for decoding.
 	S6MaxStatusSize = S6StatusSizeCurrent
 
-	// Pre-2.20.0 S6 format (35 bytes)
+	// S6MaxNegotiatedStatusSize bounds the status files of unknown size read
+	// to negotiate the layout of newer s6 releases
+	S6MaxNegotiatedStatusSize = 512
+
+	// S6 format before 2.11.0.0 (35 bytes)
 	// bytes 0-11:  TAI64N timestamp
 	// bytes 12-23: TAI64N ready timestamp
 	// bytes 24-27: reserved/zeros
@@ -62,7 +67,7 @@ const (
 	S6PIDEndPre220         = 32 // PID end
 	S6FlagsBytePre220      = 34 // Flags byte
 
-	// Current S6 format (43 bytes, S6 >= 2.20.0)
+	// Current S6 format (43 bytes, S6 >= 2.11.0.0)
 	// bytes 0-11:  tain timestamp
 	// bytes 12-23: tain readystamp
 	// bytes 24-31: PID (big-endian uint64)
@@ -0,0 +1,320 @@
+package svcmgr
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StatusSchemaVersion is the version of the serialized Status and WatchEvent schema.
+// It is bumped whenever a field is removed or changes meaning; new optional
+// fields may be added without a version change.
+//
+// Version 1 layout:
+//
+//	{
+//	  "schema_version": 1,
+//	  "state": "running",           // State.String() value
+//	  "pid": 1234,                  // 0 when no process
+//	  "since": "RFC 3339 time",     // omitted when unknown
+//	  "uptime_seconds": 12.5,       // snapshot at read time
+//	  "ready": true,
+//	  "ready_since": "RFC 3339",    // omitted when unknown
+//	  "flags": {"want_up": true, "want_down": false, "normally_up": true},
+//	  "raw": "hex",                 // first 20 bytes of the status record
+//	  "s6_format": "current"        // omitted for non-s6 records
+//	}
+//
+// A WatchEvent serializes as {"schema_version": 1, "status": {...}} or
+// {"schema_version": 1, "error": "message"}.
+const StatusSchemaVersion = 1
+
+// S6FormatVersion string constants
+const (
+	s6FormatUnknownStr  = "unknown"
+	s6FormatPre220Str   = "pre-2.11.0"
+	s6FormatCurrentStr  = "current"
+	s6FormatExtendedStr = "extended"
+)
+
+// ParseState returns the State named by s, accepting the values produced by State.String
+func ParseState(s string) (State, error) {
+	switch s {
+	case stateUnknownStr:
+		return StateUnknown, nil
+	case stateDownStr:
+		return StateDown, nil
+	case stateStartingStr:
+		return StateStarting, nil
+	case stateRunningStr:
+		return StateRunning, nil
+	case statePausedStr:
+		return StatePaused, nil
+	case stateStoppingStr:
+		return StateStopping, nil
+	case stateFinishingStr:
+		return StateFinishing, nil
+	case stateCrashedStr:
+		return StateCrashed, nil
+	case stateExitedStr:
+		return StateExited, nil
+	default:
+		return StateUnknown, fmt.Errorf("unknown state: %q", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *State) UnmarshalText(text []byte) error {
+	st, err := ParseState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = st
+	return nil
+}
+
+// String returns the string representation of the S6 format version
+func (v S6FormatVersion) String() string {
+	switch v {
+	case S6FormatPre220:
+		return s6FormatPre220Str
+	case S6FormatCurrent:
+		return s6FormatCurrentStr
+	case S6FormatExtended:
+		return s6FormatExtendedStr
+	default:
+		return s6FormatUnknownStr
+	}
+}
+
+// flagsWire is the serialized form of Flags
+type flagsWire struct {
+	WantUp     bool `json:"want_up" yaml:"want_up"`
+	WantDown   bool `json:"want_down" yaml:"want_down"`
+	NormallyUp bool `json:"normally_up" yaml:"normally_up"`
+}
+
+// statusWire is the serialized form of Status (schema version 1)
+type statusWire struct {
+	SchemaVersion int        `json:"schema_version" yaml:"schema_version"`
+	State         string     `json:"state" yaml:"state"`
+	PID           int        `json:"pid" yaml:"pid"`
+	Since         *time.Time `json:"since,omitempty" yaml:"since,omitempty"`
+	UptimeSeconds float64    `json:"uptime_seconds" yaml:"uptime_seconds"`
+	Ready         bool       `json:"ready" yaml:"ready"`
+	ReadySince    *time.Time `json:"ready_since,omitempty" yaml:"ready_since,omitempty"`
+	Flags         flagsWire  `json:"flags" yaml:"flags"`
+	Raw           string     `json:"raw,omitempty" yaml:"raw,omitempty"`
+	S6Format      string     `json:"s6_format,omitempty" yaml:"s6_format,omitempty"`
+}
+
+// watchEventWire is the serialized form of WatchEvent (schema version 1)
+type watchEventWire struct {
+	SchemaVersion int         `json:"schema_version" yaml:"schema_version"`
+	Status        *statusWire `json:"status,omitempty" yaml:"status,omitempty"`
+	Error         string      `json:"error,omitempty" yaml:"error,omitempty"`
+	Dropped       uint64      `json:"dropped,omitempty" yaml:"dropped,omitempty"`
+}
+
+// toWire converts a Status to its serialized form
+func (s *Status) toWire() statusWire {
+	w := statusWire{
+		SchemaVersion: StatusSchemaVersion,
+		State:         s.State.String(),
+		PID:           s.PID,
+		UptimeSeconds: s.Uptime.Seconds(),
+		Ready:         s.Ready,
+		Flags:         flagsWire(s.Flags),
+	}
+	if !s.Since.IsZero() {
+		since := s.Since
+		w.Since = &since
+	}
+	if !s.ReadySince.IsZero() {
+		readySince := s.ReadySince
+		w.ReadySince = &readySince
+	}
+	if s.Raw != ([StatusFileSize]byte{}) {
+		w.Raw = hex.EncodeToString(s.Raw[:])
+	}
+	if s.S6Format != S6FormatUnknown {
+		w.S6Format = s.S6Format.String()
+	}
+	return w
+}
+
+// fromWire populates a Status from its serialized form
+func (s *Status) fromWire(w *statusWire) error {
+	if w.SchemaVersion > StatusSchemaVersion {
+		return fmt.Errorf("unsupported status schema version %d", w.SchemaVersion)
+	}
+
+	state, err := ParseState(w.State)
+	if err != nil {
+		return err
+	}
+
+	st := Status{
+		State:  state,
+		PID:    w.PID,
+		Uptime: time.Duration(w.UptimeSeconds * float64(time.Second)),
+		Ready:  w.Ready,
+		Flags:  Flags(w.Flags),
+	}
+	if w.Since != nil {
+		st.Since = *w.Since
+	}
+	if w.ReadySince != nil {
+		st.ReadySince = *w.ReadySince
+	}
+	if w.Raw != "" {
+		raw, err := hex.DecodeString(w.Raw)
+		if err != nil {
+			return fmt.Errorf("decoding raw status: %w", err)
+		}
+		if len(raw) != StatusFileSize {
+			return fmt.Errorf("raw status must be %d bytes, got %d", StatusFileSize, len(raw))
+		}
+		copy(st.Raw[:], raw)
+	}
+	switch w.S6Format {
+	case "":
+	case s6FormatPre220Str:
+		st.S6Format = S6FormatPre220
+	case s6FormatCurrentStr:
+		st.S6Format = S6FormatCurrent
+	case s6FormatExtendedStr:
+		st.S6Format = S6FormatExtended
+	default:
+		return fmt.Errorf("unknown s6 format: %q", w.S6Format)
+	}
+
+	*s = st
+	return nil
+}
+
+// MarshalJSON encodes the Status using the versioned schema described by StatusSchemaVersion
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.toWire())
+}
+
+// UnmarshalJSON decodes a Status from the versioned schema
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var w statusWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	return s.fromWire(&w)
+}
+
+// MarshalYAML returns the versioned schema representation for YAML encoders
+// (gopkg.in/yaml.v2 and v3 both honor this method)
+func (s Status) MarshalYAML() (interface{}, error) {
+	return s.toWire(), nil
+}
+
+// UnmarshalYAML decodes a Status from the versioned schema for YAML decoders
+func (s *Status) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w statusWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+	return s.fromWire(&w)
+}
+
+// MarshalJSON encodes Flags using the schema field names
+func (f Flags) MarshalJSON() ([]byte, error) {
+	return json.Marshal(flagsWire(f))
+}
+
+// UnmarshalJSON decodes Flags from the schema field names
+func (f *Flags) UnmarshalJSON(data []byte) error {
+	var w flagsWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*f = Flags(w)
+	return nil
+}
+
+// MarshalYAML returns the schema representation of Flags for YAML encoders
+func (f Flags) MarshalYAML() (interface{}, error) {
+	return flagsWire(f), nil
+}
+
+// UnmarshalYAML decodes Flags from the schema field names for YAML decoders
+func (f *Flags) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w flagsWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+	*f = Flags(w)
+	return nil
+}
+
+// toWire converts a WatchEvent to its serialized form
+func (e *WatchEvent) toWire() watchEventWire {
+	w := watchEventWire{SchemaVersion: StatusSchemaVersion, Dropped: e.Dropped}
+	if e.Err != nil {
+		w.Error = e.Err.Error()
+		return w
+	}
+	st := e.Status.toWire()
+	w.Status = &st
+	return w
+}
+
+// fromWire populates a WatchEvent from its serialized form
+func (e *WatchEvent) fromWire(w *watchEventWire) error {
+	if w.SchemaVersion > StatusSchemaVersion {
+		return fmt.Errorf("unsupported watch event schema version %d", w.SchemaVersion)
+	}
+
+	ev := WatchEvent{Dropped: w.Dropped}
+	if w.Error != "" {
+		ev.Err = errors.New(w.Error)
+	}
+	if w.Status != nil {
+		if err := ev.Status.fromWire(w.Status); err != nil {
+			return err
+		}
+	}
+	*e = ev
+	return nil
+}
+
+// MarshalJSON encodes the WatchEvent using the versioned schema.
+// Errors are carried as their message string.
+func (e WatchEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.toWire())
+}
+
+// UnmarshalJSON decodes a WatchEvent from the versioned schema
+func (e *WatchEvent) UnmarshalJSON(data []byte) error {
+	var w watchEventWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	return e.fromWire(&w)
+}
+
+// MarshalYAML returns the versioned schema representation for YAML encoders
+func (e WatchEvent) MarshalYAML() (interface{}, error) {
+	return e.toWire(), nil
+}
+
+// UnmarshalYAML decodes a WatchEvent from the versioned schema for YAML decoders
+func (e *WatchEvent) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w watchEventWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+	return e.fromWire(&w)
+}
@@ -0,0 +1,129 @@
+package svcmgr
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusJSONRoundTrip(t *testing.T) {
+	original, err := decodeStatusRunit(makeStatusData(4321, 'u', 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original.Ready = true
+	original.ReadySince = original.Since.Add(time.Second)
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{`"schema_version":1`, `"state":"running"`, `"pid":4321`, `"want_up":true`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("JSON %s missing %s", data, field)
+		}
+	}
+
+	var decoded Status
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.State != original.State || decoded.PID != original.PID || decoded.Flags != original.Flags {
+		t.Errorf("decoded %+v, want %+v", decoded, original)
+	}
+	if !decoded.Since.Equal(original.Since) || !decoded.ReadySince.Equal(original.ReadySince) {
+		t.Errorf("timestamps changed: got %v/%v, want %v/%v", decoded.Since, decoded.ReadySince, original.Since, original.ReadySince)
+	}
+	if decoded.Raw != original.Raw {
+		t.Errorf("Raw = %x, want %x", decoded.Raw, original.Raw)
+	}
+}
+
+func TestStatusJSONRejectsFutureSchema(t *testing.T) {
+	var st Status
+	err := json.Unmarshal([]byte(`{"schema_version":99,"state":"running"}`), &st)
+	if err == nil {
+		t.Fatal("expected error for unsupported schema version")
+	}
+}
+
+func TestWatchEventJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		event WatchEvent
+	}{
+		{name: "status", event: WatchEvent{Status: Status{State: StateDown, Flags: Flags{WantDown: true}}}},
+		{name: "error", event: WatchEvent{Err: errors.New("status file vanished")}},
+		{name: "dropped", event: WatchEvent{Status: Status{State: StateRunning, PID: 42}, Dropped: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded WatchEvent
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			if (decoded.Err != nil) != (tt.event.Err != nil) {
+				t.Fatalf("Err = %v, want %v", decoded.Err, tt.event.Err)
+			}
+			if tt.event.Err != nil && decoded.Err.Error() != tt.event.Err.Error() {
+				t.Errorf("Err = %q, want %q", decoded.Err, tt.event.Err)
+			}
+			if decoded.Status.State != tt.event.Status.State {
+				t.Errorf("State = %v, want %v", decoded.Status.State, tt.event.Status.State)
+			}
+			if decoded.Dropped != tt.event.Dropped {
+				t.Errorf("Dropped = %d, want %d", decoded.Dropped, tt.event.Dropped)
+			}
+		})
+	}
+}
+
+func TestStatusYAMLHooks(t *testing.T) {
+	original := Status{State: StatePaused, PID: 99, S6Format: S6FormatCurrent}
+
+	wire, err := original.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Emulate a YAML decoder by copying the marshaled representation
+	var decoded Status
+	err = decoded.UnmarshalYAML(func(v interface{}) error {
+		data, err := json.Marshal(wire)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.State != StatePaused || decoded.PID != 99 || decoded.S6Format != S6FormatCurrent {
+		t.Errorf("decoded %+v, want %+v", decoded, original)
+	}
+}
+
+func TestParseState(t *testing.T) {
+	for s := StateUnknown; s <= StateExited; s++ {
+		got, err := ParseState(s.String())
+		if err != nil {
+			t.Fatalf("ParseState(%q): %v", s, err)
+		}
+		if got != s {
+			t.Errorf("ParseState(%q) = %v", s, got)
+		}
+	}
+	if _, err := ParseState("bogus"); err == nil {
+		t.Error("expected error for unknown state")
+	}
+}
@@ -0,0 +1,52 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+)
+
+// LSBStatus is an exit code for the "status" action of an LSB init script.
+// See the Linux Standard Base Core Specification, "Init Script Actions".
+type LSBStatus int
+
+const (
+	// LSBRunning indicates the program is running or service is OK
+	LSBRunning LSBStatus = 0
+	// LSBDeadPIDFile indicates the program is dead but should be running
+	// (LSB: "program is dead and /var/run pid file exists")
+	LSBDeadPIDFile LSBStatus = 1
+	// LSBDeadLockFile indicates the program is dead and /var/lock lock file exists
+	LSBDeadLockFile LSBStatus = 2
+	// LSBNotRunning indicates the program is not running
+	LSBNotRunning LSBStatus = 3
+	// LSBUnknown indicates the program or service status is unknown
+	LSBUnknown LSBStatus = 4
+)
+
+// LSBStatusOf maps the result of ServiceClient.Status to an LSB status exit code,
+// so that init-script wrappers and monitoring checks can exit with it directly:
+//
+//	st, err := client.Status(ctx)
+//	os.Exit(int(svcmgr.LSBStatusOf(st, err)))
+//
+// A service that is not supervised or has no status file is reported as not
+// running; any other error is reported as unknown.
+func LSBStatusOf(st Status, err error) LSBStatus {
+	if err != nil {
+		if errors.Is(err, ErrNotSupervised) || errors.Is(err, os.ErrNotExist) {
+			return LSBNotRunning
+		}
+		return LSBUnknown
+	}
+
+	switch st.State {
+	case StateRunning, StatePaused, StateStarting, StateStopping, StateFinishing:
+		return LSBRunning
+	case StateCrashed:
+		return LSBDeadPIDFile
+	case StateDown, StateExited:
+		return LSBNotRunning
+	default:
+		return LSBUnknown
+	}
+}
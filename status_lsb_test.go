@@ -0,0 +1,37 @@
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestLSBStatusOf(t *testing.T) {
+	tests := []struct {
+		name string
+		st   Status
+		err  error
+		want LSBStatus
+	}{
+		{name: "running", st: Status{State: StateRunning}, want: LSBRunning},
+		{name: "paused", st: Status{State: StatePaused}, want: LSBRunning},
+		{name: "finishing", st: Status{State: StateFinishing}, want: LSBRunning},
+		{name: "crashed", st: Status{State: StateCrashed}, want: LSBDeadPIDFile},
+		{name: "down", st: Status{State: StateDown}, want: LSBNotRunning},
+		{name: "exited", st: Status{State: StateExited}, want: LSBNotRunning},
+		{name: "unknown state", st: Status{State: StateUnknown}, want: LSBUnknown},
+		{name: "not supervised", err: &OpError{Op: OpStatus, Err: ErrNotSupervised}, want: LSBNotRunning},
+		{name: "missing status file", err: fmt.Errorf("open: %w", os.ErrNotExist), want: LSBNotRunning},
+		{name: "decode error", err: ErrDecode, want: LSBUnknown},
+		{name: "other error", err: errors.New("boom"), want: LSBUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LSBStatusOf(tt.st, tt.err); got != tt.want {
+				t.Errorf("LSBStatusOf() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
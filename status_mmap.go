@@ -0,0 +1,156 @@
+//go:build linux || darwin
+
+package svcmgr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// MappedStatus reads a service's status file through a shared memory
+// mapping, for metric scrapers polling many services at sub-second
+// intervals. Each read costs one stat of the status file instead of an
+// open, read and close: runsv, supervise and s6-supervise replace the file
+// by renaming a new one over it, which the stat detects by inode, size and
+// mtime before the file is mapped again.
+//
+// A writer truncating the file in place while it is mapped would fault the
+// process; none of the supported supervisors do.
+//
+// Example:
+//
+//	ms, err := svcmgr.OpenMappedStatus("/etc/service/web")
+//	if err != nil {
+//		return err
+//	}
+//	defer ms.Close()
+//	for range time.Tick(250 * time.Millisecond) {
+//		status, err := ms.Status(ctx)
+//		...
+//	}
+type MappedStatus struct {
+	// Clock supplies the time used for Status.Uptime; nil means the wall clock
+	Clock Clock
+
+	path string
+
+	mu   sync.Mutex
+	info os.FileInfo
+	data []byte
+}
+
+// OpenMappedStatus maps the status file of the service in serviceDir
+func OpenMappedStatus(serviceDir string) (*MappedStatus, error) {
+	ms := &MappedStatus{path: filepath.Join(serviceDir, SuperviseDir, StatusFile)}
+	info, err := os.Stat(ms.path)
+	if err != nil {
+		return nil, &OpError{Op: OpStatus, Path: ms.path, Err: err}
+	}
+	if err := ms.remap(info); err != nil {
+		return nil, &OpError{Op: OpStatus, Path: ms.path, Err: err}
+	}
+	return ms, nil
+}
+
+// Status decodes the mapped status file, mapping it again first when it was
+// replaced or resized. The record size selects the runit, daemontools or s6
+// decoder.
+func (ms *MappedStatus) Status(ctx context.Context) (Status, error) {
+	if err := ctx.Err(); err != nil {
+		return Status{}, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.data == nil {
+		return Status{}, &OpError{Op: OpStatus, Path: ms.path, Err: os.ErrClosed}
+	}
+
+	info, err := os.Stat(ms.path)
+	if err != nil {
+		return Status{}, &OpError{Op: OpStatus, Path: ms.path, Err: err}
+	}
+	if !ms.current(info) {
+		if err := ms.remap(info); err != nil {
+			return Status{}, &OpError{Op: OpStatus, Path: ms.path, Err: err}
+		}
+	}
+
+	now := clockNow(ms.Clock)
+	var status Status
+	switch n := len(ms.data); {
+	case n == DaemontoolsStatusSize:
+		status, err = decodeStatusDaemontoolsAt(ms.data, now)
+	case n >= S6StatusSizePre220:
+		status, err = decodeStatusS6At(ms.data, now)
+	default:
+		status, err = decodeStatusRunitAt(ms.data, now)
+	}
+	if err != nil {
+		return Status{}, &OpError{Op: OpStatus, Path: ms.path, Err: err}
+	}
+	return status, nil
+}
+
+// Changed reports whether the status file was replaced or modified since it
+// was last mapped, letting a scraper skip decoding unchanged services
+func (ms *MappedStatus) Changed() (bool, error) {
+	info, err := os.Stat(ms.path)
+	if err != nil {
+		return false, &OpError{Op: OpStatus, Path: ms.path, Err: err}
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return !ms.current(info), nil
+}
+
+// Close unmaps the status file
+func (ms *MappedStatus) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(ms.data)
+	ms.data = nil
+	return err
+}
+
+// current reports whether info describes the mapped file, unmodified
+func (ms *MappedStatus) current(info os.FileInfo) bool {
+	return ms.info != nil && os.SameFile(ms.info, info) &&
+		info.Size() == ms.info.Size() && info.ModTime().Equal(ms.info.ModTime())
+}
+
+// remap replaces the mapping with one of the file at ms.path; the caller
+// holds ms.mu, except from OpenMappedStatus
+func (ms *MappedStatus) remap(info os.FileInfo) error {
+	f, err := os.Open(ms.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	// Stat the open file: it may have been replaced since info was taken
+	if info, err = f.Stat(); err != nil {
+		return err
+	}
+	size := info.Size()
+	if size == 0 || size > S6MaxNegotiatedStatusSize {
+		return fmt.Errorf("invalid status file size: %d bytes", size)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	if ms.data != nil {
+		_ = syscall.Munmap(ms.data)
+	}
+	ms.data, ms.info = data, info
+	return nil
+}
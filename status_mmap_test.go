@@ -0,0 +1,121 @@
+//go:build linux || darwin
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMappedStatus(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	supervise := filepath.Join(dir, SuperviseDir)
+	if err := os.Mkdir(supervise, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(supervise, StatusFile)
+
+	// replace writes a status file the way runsv does, renaming it into place
+	replace := func(data []byte) {
+		t.Helper()
+		tmp := path + ".new"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := OpenMappedStatus(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("OpenMappedStatus without status file: got %v, want ErrNotExist", err)
+	}
+
+	replace(makeStatusData(1234, 'u', 0, 1))
+	ms, err := OpenMappedStatus(dir)
+	if err != nil {
+		t.Fatalf("OpenMappedStatus: %v", err)
+	}
+	defer func() { _ = ms.Close() }()
+
+	status, err := ms.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.State != StateRunning || status.PID != 1234 {
+		t.Errorf("Status = %v pid %d, want running pid 1234", status.State, status.PID)
+	}
+	if changed, err := ms.Changed(); err != nil || changed {
+		t.Errorf("Changed = %v, %v; want false", changed, err)
+	}
+
+	// A replaced file is noticed and mapped again
+	replace(makeStatusData(0, 'd', 0, 0))
+	if changed, err := ms.Changed(); err != nil || !changed {
+		t.Errorf("Changed after replace = %v, %v; want true", changed, err)
+	}
+	if status, err = ms.Status(ctx); err != nil {
+		t.Fatalf("Status after replace: %v", err)
+	}
+	if status.State != StateDown || status.PID != 0 {
+		t.Errorf("Status after replace = %v pid %d, want down pid 0", status.State, status.PID)
+	}
+
+	// A daemontools record of another size selects its decoder
+	replace(make([]byte, DaemontoolsStatusSize))
+	if _, err = ms.Status(ctx); err != nil {
+		t.Errorf("Status of daemontools record: %v", err)
+	}
+
+	fixed := time.Now().Add(time.Hour)
+	ms.Clock = FixedClock(fixed)
+	replace(makeStatusData(99, 'u', 0, 1))
+	if status, err = ms.Status(ctx); err != nil {
+		t.Fatalf("Status with clock: %v", err)
+	}
+	if status.Uptime < 59*time.Minute {
+		t.Errorf("Uptime = %v with clock an hour ahead", status.Uptime)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ms.Status(ctx); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Status after removal: got %v, want ErrNotExist", err)
+	}
+
+	if err := ms.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := ms.Status(ctx); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Status after Close: got %v, want ErrClosed", err)
+	}
+}
+
+func BenchmarkMappedStatus(b *testing.B) {
+	dir := b.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SuperviseDir, StatusFile), makeStatusData(1234, 'u', 0, 1), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	ms, err := OpenMappedStatus(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = ms.Close() }()
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ms.Status(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package svcmgr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultS6ConfigHeader is the header s6 installs with its release number,
+// read by ReadS6Version
+const DefaultS6ConfigHeader = "/usr/include/s6/config.h"
+
+// s6VersionMacro is the macro holding the release number in s6's config.h
+const s6VersionMacro = "S6_VERSION"
+
+// s6FormatSize returns the size of a known s6 status layout
+func s6FormatSize(layout S6FormatVersion) int {
+	if layout == S6FormatPre220 {
+		return S6StatusSizePre220
+	}
+	return S6StatusSizeCurrent
+}
+
+// negotiateS6Format returns the known layout an s6 status file is decoded
+// with. Files of 35 or 43 bytes have their own layout. Larger files, from
+// s6 releases that appended fields, are decoded by the known layout they
+// start with: its timestamps must be valid TAI64N labels and its PIDs
+// plausible. hint, the layout of the installed s6 release (see
+// S6FormatForVersion), is the only candidate when set; otherwise the current
+// layout is tried before the pre-2.11.0.0 one. Anything else wraps ErrDecode.
+func negotiateS6Format(data []byte, hint S6FormatVersion) (S6FormatVersion, error) {
+	switch len(data) {
+	case S6StatusSizePre220:
+		return S6FormatPre220, nil
+	case S6StatusSizeCurrent:
+		return S6FormatCurrent, nil
+	}
+
+	candidates := []S6FormatVersion{S6FormatCurrent, S6FormatPre220}
+	if hint == S6FormatPre220 || hint == S6FormatCurrent {
+		candidates = []S6FormatVersion{hint}
+	}
+	for _, layout := range candidates {
+		size := s6FormatSize(layout)
+		if len(data) > size && validateS6Fields(data[:size]) == nil {
+			return layout, nil
+		}
+	}
+	return S6FormatUnknown, fmt.Errorf("%w: s6 status file of %d bytes matches no known layout", ErrDecode, len(data))
+}
+
+// S6FormatForVersion returns the status layout written by an s6 release,
+// such as "2.11.3.2" or "v2.13.1.0"
+func S6FormatForVersion(version string) (S6FormatVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(parts) < 2 {
+		return S6FormatUnknown, fmt.Errorf("invalid s6 version %q", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return S6FormatUnknown, fmt.Errorf("invalid s6 version %q", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return S6FormatUnknown, fmt.Errorf("invalid s6 version %q", version)
+	}
+	if major < 2 || (major == 2 && minor < 11) {
+		return S6FormatPre220, nil
+	}
+	return S6FormatCurrent, nil
+}
+
+// ReadS6Version returns the s6 release number defined in the config.h
+// header at path, DefaultS6ConfigHeader when empty. The header is only
+// installed with s6's development files.
+func ReadS6Version(path string) (string, error) {
+	if path == "" {
+		path = DefaultS6ConfigHeader
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 3 && fields[0] == "#define" && fields[1] == s6VersionMacro {
+			return strings.Trim(fields[2], `"`), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no %s in %s", s6VersionMacro, path)
+}
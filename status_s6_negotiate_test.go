@@ -0,0 +1,108 @@
+package svcmgr
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func makeS6StatusPre220(pid uint32, flags byte) []byte {
+	data := make([]byte, S6StatusSizePre220)
+	binary.BigEndian.PutUint64(data[0:8], uint64(time.Now().Unix())+TAI64Base)
+	binary.BigEndian.PutUint32(data[S6PIDStartPre220:S6PIDEndPre220], pid)
+	data[S6FlagsBytePre220] = flags
+	return data
+}
+
+func TestDecodeStatusS6Negotiated(t *testing.T) {
+	// A future release appending fields to the current layout
+	future := append(makeS6StatusCurrent(4321, 0x04|0x40), make([]byte, 13)...)
+	// An intermediate release appending to the old layout
+	old := append(makeS6StatusPre220(1234, 0x02), 0xFF, 0xFF)
+	garbage := make([]byte, 60)
+	for i := range garbage {
+		garbage[i] = 0xA5
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		opts       []DecodeOption
+		wantPID    int
+		wantFormat S6FormatVersion
+		wantErr    bool
+	}{
+		{name: "current", data: makeS6StatusCurrent(4321, 0x04), wantPID: 4321, wantFormat: S6FormatCurrent},
+		{name: "pre-2.11.0", data: makeS6StatusPre220(1234, 0x02), wantPID: 1234, wantFormat: S6FormatPre220},
+		{name: "extended current", data: future, wantPID: 4321, wantFormat: S6FormatExtended},
+		{name: "extended strict", data: future, opts: []DecodeOption{WithStrictDecode()}, wantPID: 4321, wantFormat: S6FormatExtended},
+		{name: "extended pre-2.11.0", data: old, wantPID: 1234, wantFormat: S6FormatExtended},
+		{name: "version rules out layout", data: old, opts: []DecodeOption{WithS6Format(S6FormatCurrent)}, wantErr: true},
+		{name: "garbage", data: garbage, wantErr: true},
+		{name: "truncated", data: makeS6StatusCurrent(1, 0)[:30], wantErr: true},
+		{name: "unknown flags", data: makeS6StatusCurrent(4321, 0x40), opts: []DecodeOption{WithStrictDecode()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := DecodeStatusS6(tt.data, tt.opts...)
+			if tt.wantErr {
+				if !errors.Is(err, ErrDecode) {
+					t.Errorf("error = %v, want ErrDecode", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.PID != tt.wantPID || st.S6Format != tt.wantFormat {
+				t.Errorf("PID, format = %d, %v; want %d, %v", st.PID, st.S6Format, tt.wantPID, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestS6FormatForVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    S6FormatVersion
+		wantErr bool
+	}{
+		{version: "2.10.0.3", want: S6FormatPre220},
+		{version: "v2.9.0.1", want: S6FormatPre220},
+		{version: "2.11.0.0", want: S6FormatCurrent},
+		{version: "2.11.3.2", want: S6FormatCurrent},
+		{version: "v2.12.0.4", want: S6FormatCurrent},
+		{version: "2.13.1.0", want: S6FormatCurrent},
+		{version: "3.0", want: S6FormatCurrent},
+		{version: "2", wantErr: true},
+		{version: "two.twenty", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := S6FormatForVersion(tt.version)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("S6FormatForVersion(%q) = %v, %v; want %v", tt.version, got, err, tt.want)
+		}
+	}
+}
+
+func TestReadS6Version(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.h")
+	header := "#ifndef S6_CONFIG_H\n#define S6_CONFIG_H\n\n#define S6_VERSION \"2.13.1.0\"\n#define S6_LIBEXECPREFIX \"/usr/libexec/\"\n#endif\n"
+	if err := os.WriteFile(path, []byte(header), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ReadS6Version(path)
+	if err != nil || v != "2.13.1.0" {
+		t.Errorf("ReadS6Version() = %q, %v", v, err)
+	}
+
+	if err := os.WriteFile(path, []byte("#define OTHER 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadS6Version(path); err == nil {
+		t.Error("ReadS6Version() without S6_VERSION: no error")
+	}
+}
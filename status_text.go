@@ -0,0 +1,295 @@
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status tool output keywords
+const (
+	textNormallyUp   = "normally up"
+	textNormallyDown = "normally down"
+	textWantUp       = "want up"
+	textWantDown     = "want down"
+	textPaused       = "paused"
+	textFinishing    = "finishing"
+)
+
+// textStatus collects the facts reported by a status tool before they are
+// mapped onto a Status
+type textStatus struct {
+	up           bool
+	finishing    bool
+	paused       bool
+	pid          int
+	uptime       time.Duration
+	normallyUp   bool
+	normallyDown bool
+	wantUp       bool
+	wantDown     bool
+	ready        bool
+	readyFor     time.Duration
+}
+
+// ParseSvStatus parses the output of runit's `sv status <dir>` into a Status.
+// Only the main service is considered; a trailing "; run: log: ..." section is ignored.
+//
+// Example: "run: /etc/service/web: (pid 123) 45s, normally down"
+func ParseSvStatus(output string) (Status, error) {
+	return parseSvStatusAt(output, time.Now())
+}
+
+// parseSvStatusAt is ParseSvStatus with Since computed relative to now
+func parseSvStatusAt(output string, now time.Time) (Status, error) {
+	line := firstLine(output)
+	if main, _, ok := strings.Cut(line, "; "); ok {
+		line = main
+	}
+
+	word, rest, ok := strings.Cut(line, ": ")
+	if !ok {
+		return Status{}, fmt.Errorf("%w: unrecognized sv output %q", ErrDecode, line)
+	}
+
+	var ts textStatus
+	switch word {
+	case "run":
+		ts.up = true
+	case "down":
+	case "finish":
+		ts.finishing = true
+	case "fail", "warning":
+		if strings.Contains(rest, "supervise") || strings.Contains(rest, "runsv not running") {
+			return Status{}, fmt.Errorf("%w: %s", ErrNotSupervised, rest)
+		}
+		return Status{}, fmt.Errorf("sv: %s", rest)
+	default:
+		return Status{}, fmt.Errorf("%w: unrecognized sv state %q", ErrDecode, word)
+	}
+
+	// Skip the service name, which may itself contain ": "
+	idx := strings.LastIndex(rest, ": ")
+	if idx < 0 {
+		return Status{}, fmt.Errorf("%w: unrecognized sv output %q", ErrDecode, line)
+	}
+	details := rest[idx+2:]
+
+	details, ts.pid = cutPID(details)
+	fields := strings.Split(details, ", ")
+	secs, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(fields[0]), "s"))
+	if err != nil {
+		return Status{}, fmt.Errorf("%w: unrecognized sv uptime %q", ErrDecode, fields[0])
+	}
+	ts.uptime = time.Duration(secs) * time.Second
+	ts.applyModifiers(fields[1:])
+
+	return ts.status(now), nil
+}
+
+// ParseSvstat parses the output of daemontools' `svstat <dir>` into a Status.
+//
+// Example: "/service/web: up (pid 123) 45 seconds, normally down"
+func ParseSvstat(output string) (Status, error) {
+	return parseSvstatAt(output, time.Now())
+}
+
+// parseSvstatAt is ParseSvstat with Since computed relative to now
+func parseSvstatAt(output string, now time.Time) (Status, error) {
+	line := firstLine(output)
+
+	idx := strings.LastIndex(line, ": ")
+	if idx < 0 {
+		return Status{}, fmt.Errorf("%w: unrecognized svstat output %q", ErrDecode, line)
+	}
+	details := line[idx+2:]
+	if strings.HasPrefix(details, "supervise not running") {
+		return Status{}, ErrNotSupervised
+	}
+
+	return parseUpDown("svstat", details, now)
+}
+
+// ParseS6Svstat parses the output of `s6-svstat <dir>` into a Status.
+//
+// Example: "up (pid 123 pgid 123) 45 seconds, normally down, ready 40 seconds"
+func ParseS6Svstat(output string) (Status, error) {
+	return parseS6SvstatAt(output, time.Now())
+}
+
+// parseS6SvstatAt is ParseS6Svstat with Since computed relative to now
+func parseS6SvstatAt(output string, now time.Time) (Status, error) {
+	line := firstLine(output)
+	if strings.HasPrefix(line, "s6-svstat: ") {
+		if strings.Contains(line, "supervisor not listening") || strings.Contains(line, "supervise") {
+			return Status{}, fmt.Errorf("%w: %s", ErrNotSupervised, line)
+		}
+		return Status{}, errors.New(line)
+	}
+	return parseUpDown("s6-svstat", line, now)
+}
+
+// parseUpDown parses the "up (pid N) S seconds, modifiers..." form shared by svstat and s6-svstat
+func parseUpDown(tool, details string, now time.Time) (Status, error) {
+	var ts textStatus
+	switch {
+	case strings.HasPrefix(details, "up "):
+		ts.up = true
+		details = details[len("up "):]
+	case strings.HasPrefix(details, "down "):
+		details = details[len("down "):]
+	default:
+		return Status{}, fmt.Errorf("%w: unrecognized %s output %q", ErrDecode, tool, details)
+	}
+
+	// Drop the parenthesized pid or exit status
+	if strings.HasPrefix(details, "(") {
+		if ts.up {
+			details, ts.pid = cutPID(details)
+		} else if _, after, ok := strings.Cut(details, ") "); ok {
+			details = after
+		}
+	}
+
+	fields := strings.Split(details, ", ")
+	secs, err := parseSeconds(fields[0])
+	if err != nil {
+		return Status{}, fmt.Errorf("%w: unrecognized %s uptime %q", ErrDecode, tool, fields[0])
+	}
+	ts.uptime = secs
+
+	for _, field := range fields[1:] {
+		if after, ok := strings.CutPrefix(field, "ready "); ok {
+			ts.ready = true
+			if d, err := parseSeconds(after); err == nil {
+				ts.readyFor = d
+			}
+		}
+	}
+	ts.applyModifiers(fields[1:])
+
+	return ts.status(now), nil
+}
+
+// applyModifiers records the comma-separated modifiers following the uptime
+func (ts *textStatus) applyModifiers(fields []string) {
+	for _, field := range fields {
+		switch strings.TrimSpace(field) {
+		case textNormallyUp:
+			ts.normallyUp = true
+		case textNormallyDown:
+			ts.normallyDown = true
+		case textWantUp:
+			ts.wantUp = true
+		case textWantDown:
+			ts.wantDown = true
+		case textPaused:
+			ts.paused = true
+		case textFinishing:
+			ts.finishing = true
+		}
+	}
+}
+
+// status maps the parsed facts onto a Status using the same rules as the binary decoders.
+// Status tools only print "want" and "normally" when they differ from the current state.
+func (ts *textStatus) status(now time.Time) Status {
+	st := Status{
+		PID:    ts.pid,
+		Uptime: ts.uptime,
+		Since:  now.Add(-ts.uptime),
+		Ready:  ts.ready,
+	}
+	if ts.ready {
+		st.ReadySince = now.Add(-ts.readyFor)
+	}
+
+	running := ts.up || ts.finishing
+	st.Flags.WantUp = ts.wantUp || (running && !ts.wantDown)
+	st.Flags.WantDown = !st.Flags.WantUp
+	st.Flags.NormallyUp = ts.normallyUp || (ts.up && !ts.normallyDown)
+
+	switch {
+	case ts.finishing:
+		st.State = StateFinishing
+	case ts.up && ts.paused:
+		st.State = StatePaused
+	case ts.up && ts.wantDown:
+		st.State = StateStopping
+	case ts.up:
+		st.State = StateRunning
+	case ts.wantUp:
+		st.State = StateCrashed
+	default:
+		st.State = StateDown
+	}
+
+	return st
+}
+
+// firstLine returns the first non-empty line of output, trimmed
+func firstLine(output string) string {
+	output = strings.TrimSpace(output)
+	line, _, _ := strings.Cut(output, "\n")
+	return strings.TrimSpace(line)
+}
+
+// cutPID extracts the pid from a leading "(pid N ...)" group and returns the remainder
+func cutPID(s string) (string, int) {
+	if !strings.HasPrefix(s, "(pid ") {
+		return s, 0
+	}
+	group, rest, ok := strings.Cut(s, ") ")
+	if !ok {
+		return s, 0
+	}
+	fields := strings.Fields(group[len("(pid "):])
+	if len(fields) == 0 {
+		return rest, 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSuffix(fields[0], ")"))
+	return rest, pid
+}
+
+// parseSeconds parses "45 seconds" or "45s"
+func parseSeconds(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, " seconds")
+	s = strings.TrimSuffix(s, " second")
+	s = strings.TrimSuffix(s, "s")
+	secs, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// runStatusTool executes a native status tool and parses its output.
+// Tools such as sv exit non-zero for some states, so output is parsed whenever present.
+// Since is computed from clock once the tool has exited.
+func runStatusTool(ctx context.Context, clock Clock, parse func(string, time.Time) (Status, error), path string, args ...string) (Status, error) {
+	cmd := exec.CommandContext(ctx, path, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	output := stdout.String()
+	if strings.TrimSpace(output) == "" {
+		output = stderr.String()
+	}
+	if strings.TrimSpace(output) == "" {
+		if runErr != nil {
+			return Status{}, fmt.Errorf("running %s: %w", path, runErr)
+		}
+		return Status{}, fmt.Errorf("%w: %s produced no output", ErrDecode, path)
+	}
+
+	return parse(output, clockNow(clock))
+}
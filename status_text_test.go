@@ -0,0 +1,166 @@
+package svcmgr
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseStatusText(t *testing.T) {
+	tests := []struct {
+		name       string
+		parse      func(string) (Status, error)
+		output     string
+		wantState  State
+		wantPID    int
+		wantUptime time.Duration
+		wantUp     bool
+		normallyUp bool
+		wantReady  bool
+	}{
+		{
+			name:       "sv running",
+			parse:      ParseSvStatus,
+			output:     "run: /etc/service/web: (pid 123) 45s\n",
+			wantState:  StateRunning,
+			wantPID:    123,
+			wantUptime: 45 * time.Second,
+			wantUp:     true,
+			normallyUp: true,
+		},
+		{
+			name:       "sv running with log",
+			parse:      ParseSvStatus,
+			output:     "run: web: (pid 123) 45s, normally down; run: log: (pid 124) 45s\n",
+			wantState:  StateRunning,
+			wantPID:    123,
+			wantUptime: 45 * time.Second,
+			wantUp:     true,
+		},
+		{
+			name:       "sv down",
+			parse:      ParseSvStatus,
+			output:     "down: /etc/service/web: 10s, normally up\n",
+			wantState:  StateDown,
+			wantUptime: 10 * time.Second,
+			normallyUp: true,
+		},
+		{
+			name:       "sv paused",
+			parse:      ParseSvStatus,
+			output:     "run: web: (pid 123) 5s, paused\n",
+			wantState:  StatePaused,
+			wantPID:    123,
+			wantUptime: 5 * time.Second,
+			wantUp:     true,
+			normallyUp: true,
+		},
+		{
+			name:       "sv stopping",
+			parse:      ParseSvStatus,
+			output:     "run: web: (pid 123) 5s, want down\n",
+			wantState:  StateStopping,
+			wantPID:    123,
+			wantUptime: 5 * time.Second,
+			normallyUp: true,
+		},
+		{
+			name:       "sv finishing",
+			parse:      ParseSvStatus,
+			output:     "finish: web: (pid 123) 2s\n",
+			wantState:  StateFinishing,
+			wantPID:    123,
+			wantUptime: 2 * time.Second,
+			wantUp:     true,
+		},
+		{
+			name:       "svstat up",
+			parse:      ParseSvstat,
+			output:     "/service/web: up (pid 123) 45 seconds\n",
+			wantState:  StateRunning,
+			wantPID:    123,
+			wantUptime: 45 * time.Second,
+			wantUp:     true,
+			normallyUp: true,
+		},
+		{
+			name:       "svstat down want up",
+			parse:      ParseSvstat,
+			output:     "/service/web: down 3 seconds, normally up, want up\n",
+			wantState:  StateCrashed,
+			wantUptime: 3 * time.Second,
+			wantUp:     true,
+			normallyUp: true,
+		},
+		{
+			name:       "s6-svstat up ready",
+			parse:      ParseS6Svstat,
+			output:     "up (pid 123 pgid 123) 45 seconds, ready 40 seconds\n",
+			wantState:  StateRunning,
+			wantPID:    123,
+			wantUptime: 45 * time.Second,
+			wantUp:     true,
+			normallyUp: true,
+			wantReady:  true,
+		},
+		{
+			name:       "s6-svstat down exit code",
+			parse:      ParseS6Svstat,
+			output:     "down (exitcode 0) 12 seconds, normally up, ready 12 seconds\n",
+			wantState:  StateDown,
+			wantUptime: 12 * time.Second,
+			normallyUp: true,
+			wantReady:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := tt.parse(tt.output)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if st.State != tt.wantState {
+				t.Errorf("State = %v, want %v", st.State, tt.wantState)
+			}
+			if st.PID != tt.wantPID {
+				t.Errorf("PID = %d, want %d", st.PID, tt.wantPID)
+			}
+			if st.Uptime != tt.wantUptime {
+				t.Errorf("Uptime = %v, want %v", st.Uptime, tt.wantUptime)
+			}
+			if st.Flags.WantUp != tt.wantUp {
+				t.Errorf("WantUp = %v, want %v", st.Flags.WantUp, tt.wantUp)
+			}
+			if st.Flags.NormallyUp != tt.normallyUp {
+				t.Errorf("NormallyUp = %v, want %v", st.Flags.NormallyUp, tt.normallyUp)
+			}
+			if st.Ready != tt.wantReady {
+				t.Errorf("Ready = %v, want %v", st.Ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestParseStatusTextErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (Status, error)
+		output  string
+		wantErr error
+	}{
+		{name: "sv not supervised", parse: ParseSvStatus, output: "fail: web: runsv not running\n", wantErr: ErrNotSupervised},
+		{name: "sv garbage", parse: ParseSvStatus, output: "hello\n", wantErr: ErrDecode},
+		{name: "svstat not supervised", parse: ParseSvstat, output: "/service/web: supervise not running\n", wantErr: ErrNotSupervised},
+		{name: "s6-svstat garbage", parse: ParseS6Svstat, output: "sideways 5 seconds\n", wantErr: ErrDecode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.output)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package svcmgr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/axondata/go-svcmgr/tai64"
+)
+
+// Strict decoding limits
+const (
+	// MaxPID is the largest PID a Linux kernel can hand out (PID_MAX_LIMIT on 64-bit).
+	// Status records claiming a larger PID are treated as corrupt in strict mode.
+	MaxPID = 1 << 22
+
+	// maxTAI64Sec is the TAI64 label for 9999-12-31T23:59:59Z, the latest
+	// timestamp the decoders accept
+	maxTAI64Sec = tai64.UnixEpoch + 253402300799
+
+	// s6KnownFlagsPre220 is the mask of flag bits defined for the format before 2.11.0.0
+	s6KnownFlagsPre220 = S6FlagUp | S6FlagNormallyUp | S6FlagWantUp | S6FlagReady | S6FlagPaused | S6FlagFinishing
+
+	// s6KnownFlagsCurrent is the mask of flag bits defined for the current format
+	// (paused, finishing, want up, ready)
+	s6KnownFlagsCurrent = 0x0F
+)
+
+// DecodeOption configures how status records are decoded
+type DecodeOption func(*decodeConfig)
+
+// decodeConfig holds the settings applied by DecodeOption values
+type decodeConfig struct {
+	strict   bool
+	clock    Clock
+	s6Format S6FormatVersion
+}
+
+// WithStrictDecode makes the decoder reject records with unknown or inconsistent
+// flag bytes, impossible timestamps, or out-of-range PIDs instead of decoding
+// them best-effort. Corruption is reported as an error wrapping ErrDecode.
+func WithStrictDecode() DecodeOption {
+	return func(c *decodeConfig) {
+		c.strict = true
+	}
+}
+
+// WithDecodeClock computes Uptime against clock instead of the wall clock
+func WithDecodeClock(clock Clock) DecodeOption {
+	return func(c *decodeConfig) {
+		c.clock = clock
+	}
+}
+
+// WithS6Format makes the s6 decoder expect layout, as returned by
+// S6FormatForVersion, for status files of unknown size
+func WithS6Format(layout S6FormatVersion) DecodeOption {
+	return func(c *decodeConfig) {
+		c.s6Format = layout
+	}
+}
+
+// now returns the current time from the configured clock
+func (c *decodeConfig) now() time.Time {
+	return clockNow(c.clock)
+}
+
+// newDecodeConfig applies opts to a zero decodeConfig
+func newDecodeConfig(opts []DecodeOption) decodeConfig {
+	var cfg decodeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// validateTAI64N checks that a 12-byte TAI64N label holds a representable time
+func validateTAI64N(label []byte) error {
+	l, err := tai64.DecodeN(label)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp: %w", ErrDecode, err)
+	}
+	if _, ok := labelTime(l); !ok {
+		return fmt.Errorf("%w: timestamp label %#016x out of range", ErrDecode, l.Sec)
+	}
+	return nil
+}
+
+// validatePID checks that a decoded PID is one the kernel could have issued
+func validatePID(pid uint64) error {
+	if pid > MaxPID {
+		return fmt.Errorf("%w: pid %d exceeds %d", ErrDecode, pid, MaxPID)
+	}
+	return nil
+}
+
+// validateStatusRunit performs the strict-mode checks for a 20-byte runit record.
+// runsv writes paused as 0 or 1, want as 'u', 'd' or 'x', term as 0 or 1, and
+// the run state as 0 (down), 1 (run) or 2 (finish).
+func validateStatusRunit(data []byte) error {
+	if len(data) != RunitStatusSize {
+		return fmt.Errorf("%w: runit status file must be %d bytes, got %d", ErrDecode, RunitStatusSize, len(data))
+	}
+	if err := validateTAI64N(data[RunitTAI64Start:RunitNanoEnd]); err != nil {
+		return err
+	}
+
+	pid := binary.LittleEndian.Uint32(data[RunitPIDStart:RunitPIDEnd])
+	if err := validatePID(uint64(pid)); err != nil {
+		return err
+	}
+
+	if p := data[RunitPausedFlag]; p > 1 {
+		return fmt.Errorf("%w: runit paused flag %#02x is not a known value", ErrDecode, p)
+	}
+	switch w := data[RunitWantFlag]; w {
+	case 'u', 'd', 'x':
+	default:
+		return fmt.Errorf("%w: runit want flag %#02x is not 'u', 'd' or 'x'", ErrDecode, w)
+	}
+	if term := data[RunitTermFlag]; term > 1 {
+		return fmt.Errorf("%w: runit term flag %#02x is not a known value", ErrDecode, term)
+	}
+
+	run := data[RunitRunFlag]
+	switch {
+	case run > 2:
+		return fmt.Errorf("%w: runit run state %#02x is not a known value", ErrDecode, run)
+	case run == 0 && pid != 0:
+		return fmt.Errorf("%w: runit reports state down with pid %d", ErrDecode, pid)
+	case run != 0 && pid == 0:
+		return fmt.Errorf("%w: runit reports a running process without a pid", ErrDecode)
+	}
+	return nil
+}
+
+// validateStatusDaemontools performs the strict-mode checks for an 18-byte daemontools record
+func validateStatusDaemontools(data []byte) error {
+	if len(data) != DaemontoolsStatusSize {
+		return fmt.Errorf("%w: daemontools status file must be %d bytes, got %d", ErrDecode, DaemontoolsStatusSize, len(data))
+	}
+	if err := validateTAI64N(data[DaemontoolsTAI64Start:DaemontoolsNanoEnd]); err != nil {
+		return err
+	}
+
+	pid := binary.LittleEndian.Uint32(data[DaemontoolsPIDStart:DaemontoolsPIDEnd])
+	if err := validatePID(uint64(pid)); err != nil {
+		return err
+	}
+
+	if p := data[DaemontoolsStatusFlag]; p > 1 {
+		return fmt.Errorf("%w: daemontools paused flag %#02x is not a known value", ErrDecode, p)
+	}
+	// supervise writes 0 when no want is pending, e.g. after svc -o
+	switch w := data[DaemontoolsWantFlag]; w {
+	case 0, 'u', 'd':
+	default:
+		return fmt.Errorf("%w: daemontools want flag %#02x is not 0, 'u' or 'd'", ErrDecode, w)
+	}
+	return nil
+}
+
+// validateStatusS6 performs the strict-mode checks for an s6 record. Larger
+// layouts negotiated as S6FormatExtended are checked on their known fields.
+func validateStatusS6(data []byte, hint S6FormatVersion) error {
+	layout, err := negotiateS6Format(data, hint)
+	if err != nil {
+		return err
+	}
+	extended := len(data) != s6FormatSize(layout)
+	data = data[:s6FormatSize(layout)]
+	if err := validateS6Fields(data); err != nil {
+		return err
+	}
+
+	flagsByte, known := S6FlagsByteCurrent, byte(s6KnownFlagsCurrent)
+	if layout == S6FormatPre220 {
+		flagsByte, known = S6FlagsBytePre220, s6KnownFlagsPre220
+	}
+	if flags := data[flagsByte]; flags&^known != 0 && !extended {
+		return fmt.Errorf("%w: s6 flags %#02x contain unknown bits", ErrDecode, flags)
+	}
+	return nil
+}
+
+// validateS6Fields checks the timestamps and PIDs of a 35 or 43-byte s6
+// record, leaving the flags, which newer releases may extend, unchecked
+func validateS6Fields(data []byte) error {
+	switch len(data) {
+	case S6StatusSizePre220:
+		if err := validateTAI64N(data[S6TimestampStartPre220:S6TimestampEndPre220]); err != nil {
+			return err
+		}
+		if err := validateS6ReadyStamp(data[S6ReadyStartPre220:S6ReadyEndPre220]); err != nil {
+			return err
+		}
+		pid := binary.BigEndian.Uint32(data[S6PIDStartPre220:S6PIDEndPre220])
+		return validatePID(uint64(pid))
+
+	case S6StatusSizeCurrent:
+		if err := validateTAI64N(data[S6TimestampStartCurrent:S6TimestampEndCurrent]); err != nil {
+			return err
+		}
+		if err := validateS6ReadyStamp(data[S6ReadyStartCurrent:S6ReadyEndCurrent]); err != nil {
+			return err
+		}
+		pid := binary.BigEndian.Uint64(data[S6PIDStartCurrent:S6PIDEndCurrent])
+		if err := validatePID(pid); err != nil {
+			return err
+		}
+		pgid := binary.BigEndian.Uint64(data[S6PGIDStartCurrent:S6PGIDEndCurrent])
+		return validatePID(pgid)
+
+	default:
+		return fmt.Errorf("%w: s6 status file must be 35 or 43 bytes, got %d", ErrDecode, len(data))
+	}
+}
+
+// validateS6ReadyStamp accepts an all-zero readystamp (never ready) or a valid TAI64N label
+func validateS6ReadyStamp(label []byte) error {
+	for _, b := range label {
+		if b != 0 {
+			return validateTAI64N(label)
+		}
+	}
+	return nil
+}
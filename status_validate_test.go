@@ -0,0 +1,133 @@
+package svcmgr
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func makeS6StatusCurrent(pid uint64, flags byte) []byte {
+	data := make([]byte, S6StatusSizeCurrent)
+	binary.BigEndian.PutUint64(data[0:8], uint64(time.Now().Unix())+TAI64Base)
+	binary.BigEndian.PutUint64(data[S6PIDStartCurrent:S6PIDEndCurrent], pid)
+	binary.BigEndian.PutUint64(data[S6PGIDStartCurrent:S6PGIDEndCurrent], pid)
+	data[S6FlagsByteCurrent] = flags
+	return data
+}
+
+func makeDaemontoolsStatus(pid uint32, want byte) []byte {
+	data := make([]byte, DaemontoolsStatusSize)
+	binary.BigEndian.PutUint64(data[DaemontoolsTAI64Start:DaemontoolsTAI64End], uint64(time.Now().Unix())+TAI64Base)
+	binary.LittleEndian.PutUint32(data[DaemontoolsPIDStart:DaemontoolsPIDEnd], pid)
+	data[DaemontoolsWantFlag] = want
+	return data
+}
+
+func TestDecodeStatusRunitStrict(t *testing.T) {
+	mutate := func(f func([]byte)) []byte {
+		data := makeStatusData(1234, 'u', 0, 1)
+		f(data)
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "running", data: makeStatusData(1234, 'u', 0, 1)},
+		{name: "down", data: makeStatusData(0, 'd', 0, 0)},
+		{name: "paused", data: makeStatusData(1234, 'u', 1, 1)},
+		{name: "unknown want flag", data: makeStatusData(1234, 'z', 0, 1), wantErr: true},
+		{name: "unknown run state", data: makeStatusData(1234, 'u', 0, 7), wantErr: true},
+		{name: "down with pid", data: makeStatusData(1234, 'u', 0, 0), wantErr: true},
+		{name: "running without pid", data: makeStatusData(0, 'u', 0, 1), wantErr: true},
+		{name: "paused flag above 1", data: makeStatusData(1234, 'u', 2, 1), wantErr: true},
+		{name: "unknown paused value", data: makeStatusData(1234, 'u', 9, 1), wantErr: true},
+		{
+			name:    "zero timestamp",
+			data:    mutate(func(b []byte) { binary.BigEndian.PutUint64(b[RunitTAI64Start:RunitTAI64End], 0) }),
+			wantErr: true,
+		},
+		{
+			name:    "nanoseconds overflow",
+			data:    mutate(func(b []byte) { binary.BigEndian.PutUint32(b[RunitNanoStart:RunitNanoEnd], 1_000_000_000) }),
+			wantErr: true,
+		},
+		{
+			name:    "pid beyond kernel limit",
+			data:    mutate(func(b []byte) { binary.LittleEndian.PutUint32(b[RunitPIDStart:RunitPIDEnd], MaxPID+1) }),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Lenient decoding never fails on a correctly sized record
+			if _, err := DecodeStatusRunit(tt.data); err != nil {
+				t.Fatalf("lenient decode failed: %v", err)
+			}
+
+			_, err := DecodeStatusRunit(tt.data, WithStrictDecode())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("strict decode error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrDecode) {
+				t.Errorf("strict decode error %v does not wrap ErrDecode", err)
+			}
+		})
+	}
+}
+
+func TestDecodeStatusS6Strict(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "running ready", data: makeS6StatusCurrent(1234, 0x04|0x08)},
+		{name: "down", data: makeS6StatusCurrent(0, 0)},
+		{name: "unknown flag bits", data: makeS6StatusCurrent(1234, 0x84), wantErr: true},
+		{name: "pid beyond kernel limit", data: makeS6StatusCurrent(1<<40, 0x04), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeStatusS6(tt.data, WithStrictDecode())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("strict decode error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeStatusDaemontoolsStrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantUp   bool
+		wantDown bool
+		wantErr  bool
+	}{
+		{name: "running", data: makeDaemontoolsStatus(1234, 'u'), wantUp: true},
+		{name: "down", data: makeDaemontoolsStatus(0, 'd'), wantDown: true},
+		{name: "one-shot without pending want", data: makeDaemontoolsStatus(1234, 0)},
+		{name: "unknown want flag", data: makeDaemontoolsStatus(1234, 'z'), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := DecodeStatusDaemontools(tt.data, WithStrictDecode())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("strict decode error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if st.Flags.WantUp != tt.wantUp || st.Flags.WantDown != tt.wantDown {
+				t.Errorf("WantUp, WantDown = %v, %v, want %v, %v", st.Flags.WantUp, st.Flags.WantDown, tt.wantUp, tt.wantDown)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package svcmgrhttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrUnauthenticated is returned by authenticators that find no valid
+// credentials in a request
+var ErrUnauthenticated = errors.New("svcmgrhttp: unauthenticated")
+
+// Authenticator identifies the caller of a request, returning a principal
+// name, or an error (normally ErrUnauthenticated) to reject the request
+type Authenticator interface {
+	Authenticate(r *http.Request) (string, error)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface
+type AuthenticatorFunc func(r *http.Request) (string, error)
+
+// Authenticate implements Authenticator
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (string, error) { return f(r) }
+
+// BearerTokens authenticates requests carrying one of a fixed set of tokens
+// in an "Authorization: Bearer" header. Tokens are compared in constant time.
+type BearerTokens struct {
+	// Tokens maps each accepted token to the principal it identifies
+	Tokens map[string]string
+	// QueryParam, when set, also accepts the token in this query parameter,
+	// for browser EventSource and WebSocket clients that cannot set headers.
+	// Query strings end up in access logs; prefer the header where possible.
+	QueryParam string
+}
+
+// Authenticate implements Authenticator
+func (b BearerTokens) Authenticate(r *http.Request) (string, error) {
+	token, ok := bearerToken(r.Header.Get("Authorization"))
+	if !ok && b.QueryParam != "" {
+		token = r.URL.Query().Get(b.QueryParam)
+		ok = token != ""
+	}
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	// Hash both sides so the comparison takes the same time for every length
+	sum := sha256.Sum256([]byte(token))
+	principal, found := "", 0
+	for t, p := range b.Tokens {
+		want := sha256.Sum256([]byte(t))
+		if subtle.ConstantTimeCompare(sum[:], want[:]) == 1 {
+			principal, found = p, 1
+		}
+	}
+	if found == 0 {
+		return "", ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+// bearerToken extracts the token from an Authorization header value
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// ClientCert authenticates requests by their verified TLS client
+// certificate (mTLS), identifying the caller by the certificate's subject
+// common name. The server must request and verify client certificates, e.g.
+// with tls.Config.ClientAuth set to tls.RequireAndVerifyClientCert.
+type ClientCert struct {
+	// Allowed lists the accepted common names; empty accepts any verified
+	// certificate
+	Allowed []string
+}
+
+// Authenticate implements Authenticator
+func (c ClientCert) Authenticate(r *http.Request) (string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", ErrUnauthenticated
+	}
+	name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	if len(c.Allowed) > 0 && !slices.Contains(c.Allowed, name) {
+		return "", ErrUnauthenticated
+	}
+	return name, nil
+}
+
+// AnyOf accepts a request that any of auths accepts, trying them in order
+func AnyOf(auths ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (string, error) {
+		for _, a := range auths {
+			if principal, err := a.Authenticate(r); err == nil {
+				return principal, nil
+			}
+		}
+		return "", ErrUnauthenticated
+	})
+}
+
+// principalKey is the context key of the authenticated principal
+type principalKey struct{}
+
+// Principal returns the caller authenticated by RequireAuth
+func Principal(ctx context.Context) (string, bool) {
+	p, ok := ctx.Value(principalKey{}).(string)
+	return p, ok
+}
+
+// RequireAuth wraps next so only requests accepted by auth reach it; others
+// get a 401. The principal is available to next through Principal.
+//
+// Example:
+//
+//	auth := svcmgrhttp.BearerTokens{Tokens: map[string]string{os.Getenv("API_TOKEN"): "ops"}}
+//	http.Handle("/healthz", svcmgrhttp.Healthz(mgr, services, nil))
+//	http.Handle("/console", svcmgrhttp.RequireAuth(auth, svcmgrhttp.Console(clients)))
+func RequireAuth(auth Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := auth.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="svcmgr"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
@@ -0,0 +1,64 @@
+package svcmgrhttp_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axondata/go-svcmgr/svcmgrhttp"
+)
+
+func TestRequireAuth(t *testing.T) {
+	var got string
+	handler := svcmgrhttp.RequireAuth(
+		svcmgrhttp.AnyOf(
+			svcmgrhttp.BearerTokens{Tokens: map[string]string{"s3cret": "ops"}, QueryParam: "access_token"},
+			svcmgrhttp.ClientCert{Allowed: []string{"dashboard"}},
+		),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = svcmgrhttp.Principal(r.Context())
+		}),
+	)
+
+	withCert := func(cn string) func(*http.Request) {
+		return func(r *http.Request) {
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}}}
+		}
+	}
+	tests := []struct {
+		name      string
+		url       string
+		setup     func(*http.Request)
+		wantCode  int
+		principal string
+	}{
+		{name: "no credentials", url: "/", wantCode: http.StatusUnauthorized},
+		{name: "bearer", url: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, wantCode: http.StatusOK, principal: "ops"},
+		{name: "bearer lower-case", url: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "bearer s3cret") }, wantCode: http.StatusOK, principal: "ops"},
+		{name: "wrong token", url: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, wantCode: http.StatusUnauthorized},
+		{name: "basic", url: "/", setup: func(r *http.Request) { r.SetBasicAuth("ops", "s3cret") }, wantCode: http.StatusUnauthorized},
+		{name: "query token", url: "/?access_token=s3cret", wantCode: http.StatusOK, principal: "ops"},
+		{name: "client cert", url: "/", setup: withCert("dashboard"), wantCode: http.StatusOK, principal: "dashboard"},
+		{name: "other client cert", url: "/", setup: withCert("intruder"), wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode || got != tt.principal {
+				t.Errorf("code = %d principal = %q, want %d %q", rec.Code, got, tt.wantCode, tt.principal)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package svcmgrhttp
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"slices"
+)
+
+// OpRead is the operation name authorizing status reads: health checks,
+// event streams and the status messages of a console
+const OpRead = "status"
+
+// Authorizer decides whether principal may perform op on service. Ops are
+// named as by Operation.String ("up", "restart", ...), with OpRead for
+// status reads.
+type Authorizer interface {
+	Authorize(principal, service, op string) bool
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(principal, service, op string) bool
+
+// Authorize implements Authorizer
+func (f AuthorizerFunc) Authorize(principal, service, op string) bool {
+	return f(principal, service, op)
+}
+
+// Role grants operations on services
+type Role struct {
+	// Ops are the allowed operation names; "*" allows all
+	Ops []string
+	// Services are filepath.Match patterns matched against service names,
+	// and the base name of service directories; empty matches all
+	Services []string
+}
+
+// Predefined roles
+var (
+	// RoleViewer may read the status of every service
+	RoleViewer = Role{Ops: []string{OpRead}}
+	// RoleOperator may perform every operation on every service
+	RoleOperator = Role{Ops: []string{"*"}}
+)
+
+// allows reports whether the role grants op on service
+func (r Role) allows(service, op string) bool {
+	if !slices.Contains(r.Ops, "*") && !slices.Contains(r.Ops, op) {
+		return false
+	}
+	if len(r.Services) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.Services, func(pattern string) bool {
+		if ok, _ := filepath.Match(pattern, service); ok {
+			return true
+		}
+		ok, _ := filepath.Match(pattern, filepath.Base(service))
+		return ok
+	})
+}
+
+// RBAC authorizes by role: each principal is bound to roles, and an
+// operation is allowed when any of them grants it. Unbound principals may
+// do nothing.
+//
+// Example, read-only dashboards and an on-call team limited to web services:
+//
+//	rbac := &svcmgrhttp.RBAC{
+//		Roles: map[string]svcmgrhttp.Role{
+//			"viewer":  svcmgrhttp.RoleViewer,
+//			"web-ops": {Ops: []string{"status", "restart", "hup"}, Services: []string{"web-*"}},
+//		},
+//		Bindings: map[string][]string{
+//			"dashboard": {"viewer"},
+//			"oncall":    {"viewer", "web-ops"},
+//		},
+//	}
+type RBAC struct {
+	// Roles are the available roles by name
+	Roles map[string]Role
+	// Bindings maps principals to role names
+	Bindings map[string][]string
+}
+
+// Authorize implements Authorizer
+func (a *RBAC) Authorize(principal, service, op string) bool {
+	for _, name := range a.Bindings[principal] {
+		if role, ok := a.Roles[name]; ok && role.allows(service, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizerKey is the context key of the Authorizer installed by RequireAuthz
+type authorizerKey struct{}
+
+// RequireAuthz makes the handlers in next check every service they read and
+// every operation they perform with authz, for the principal authenticated
+// by an enclosing RequireAuth. Healthz and Events answer 403 for services
+// the caller may not read, and Events streams only readable services when
+// none are selected; Console acknowledges forbidden commands with an error.
+//
+// Example:
+//
+//	http.Handle("/console", svcmgrhttp.RequireAuth(auth, svcmgrhttp.RequireAuthz(rbac, svcmgrhttp.Console(clients))))
+func RequireAuthz(authz Authorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authorizerKey{}, authz)))
+	})
+}
+
+// authorized reports whether the request's principal may perform op on
+// service; without RequireAuthz everything is allowed
+func authorized(r *http.Request, service, op string) bool {
+	authz, ok := r.Context().Value(authorizerKey{}).(Authorizer)
+	if !ok {
+		return true
+	}
+	principal, _ := Principal(r.Context())
+	return authz.Authorize(principal, service, op)
+}
+
+// forbidden responds 403
+func forbidden(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
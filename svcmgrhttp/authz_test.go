@@ -0,0 +1,96 @@
+package svcmgrhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrhttp"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func testRBAC() *svcmgrhttp.RBAC {
+	return &svcmgrhttp.RBAC{
+		Roles: map[string]svcmgrhttp.Role{
+			"viewer":   svcmgrhttp.RoleViewer,
+			"operator": svcmgrhttp.RoleOperator,
+			"web-ops":  {Ops: []string{svcmgrhttp.OpRead, "restart"}, Services: []string{"web-*"}},
+		},
+		Bindings: map[string][]string{
+			"dashboard": {"viewer"},
+			"admin":     {"operator"},
+			"oncall":    {"web-ops"},
+		},
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	rbac := testRBAC()
+	tests := []struct {
+		principal, service, op string
+		want                   bool
+	}{
+		{"dashboard", "db", "status", true},
+		{"dashboard", "db", "restart", false},
+		{"admin", "db", "kill", true},
+		{"oncall", "web-1", "restart", true},
+		{"oncall", "/etc/service/web-2", "status", true},
+		{"oncall", "web-1", "down", false},
+		{"oncall", "db", "restart", false},
+		{"stranger", "web-1", "status", false},
+	}
+	for _, tt := range tests {
+		if got := rbac.Authorize(tt.principal, tt.service, tt.op); got != tt.want {
+			t.Errorf("Authorize(%q, %q, %q) = %v, want %v", tt.principal, tt.service, tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestRequireAuthzEvents(t *testing.T) {
+	clients := map[string]svcmgr.ServiceClient{
+		"web-1": svcmgrtest.NewFakeClient(),
+		"db":    svcmgrtest.NewFakeClient(),
+	}
+	auth := svcmgrhttp.BearerTokens{Tokens: map[string]string{"oncall-token": "oncall"}}
+	handler := svcmgrhttp.RequireAuth(auth, svcmgrhttp.RequireAuthz(testRBAC(), svcmgrhttp.Events(clients, 0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/?service=db", nil)
+	req.Header.Set("Authorization", "Bearer oncall-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("unreadable service: status %d, want 403", rec.Code)
+	}
+}
+
+func TestRequireAuthzConsole(t *testing.T) {
+	clients := map[string]svcmgr.ServiceClient{
+		"web-1": svcmgrtest.NewFakeClient(),
+		"db":    svcmgrtest.NewFakeClient(),
+	}
+	auth := svcmgrhttp.BearerTokens{Tokens: map[string]string{"oncall-token": "oncall"}, QueryParam: "access_token"}
+	srv := httptest.NewServer(svcmgrhttp.RequireAuth(auth, svcmgrhttp.RequireAuthz(testRBAC(), svcmgrhttp.Console(clients))))
+	defer srv.Close()
+
+	// Without a selection only the readable services are streamed
+	ws := dialWebsocket(t, srv, "/?access_token=oncall-token")
+	if msg := ws.next(t); msg.Type != "status" || msg.Service != "web-1" {
+		t.Fatalf("first message = %+v, want web-1 status", msg)
+	}
+
+	ws.send(t, svcmgrhttp.ConsoleCommand{ID: "1", Service: "web-1", Op: "down"})
+	if msg := ws.next(t); msg.Type != "ack" || msg.OK || msg.Error != "Forbidden" {
+		t.Errorf("forbidden command ack = %+v", msg)
+	}
+	ws.send(t, svcmgrhttp.ConsoleCommand{ID: "2", Service: "web-1", Op: "restart"})
+	for {
+		msg := ws.next(t)
+		if msg.Type == "ack" {
+			if msg.ID != "2" || !msg.OK {
+				t.Errorf("allowed command ack = %+v", msg)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package svcmgrhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// ConsoleCommand is a control command sent by a console client
+type ConsoleCommand struct {
+	// ID is echoed in the acknowledgement
+	ID string `json:"id"`
+	// Service names the service
+	Service string `json:"service"`
+	// Op is the operation, named as by Operation.String ("up", "down",
+	// "term", "restart", ...)
+	Op string `json:"op"`
+}
+
+// ConsoleMessage is a message sent to a console client: a "status" event
+// or the "ack" of a command
+type ConsoleMessage struct {
+	// Type is "status" or "ack"
+	Type string `json:"type"`
+	// Service names the service
+	Service string `json:"service,omitempty"`
+	// Event is the watch event of a status message
+	Event *svcmgr.WatchEvent `json:"event,omitempty"`
+	// ID is the acknowledged command's ID
+	ID string `json:"id,omitempty"`
+	// OK reports whether the acknowledged command succeeded
+	OK bool `json:"ok,omitempty"`
+	// Error is why the acknowledged command failed
+	Error string `json:"error,omitempty"`
+}
+
+// DefaultConsoleConcurrency is how many commands of one console connection
+// run at once; further commands wait for a slot
+const DefaultConsoleConcurrency = 8
+
+// ConsoleOption configures Console
+type ConsoleOption func(*consoleConfig)
+
+// consoleConfig holds the settings of ConsoleOptions
+type consoleConfig struct {
+	allowedOrigins []string
+	concurrency    int
+}
+
+// WithAllowedOrigins lets browser pages served from origins, such as
+// "https://ops.example.com", open the console. Without it only pages from
+// the console's own host can; clients that send no Origin header are not
+// browsers and always can.
+func WithAllowedOrigins(origins ...string) ConsoleOption {
+	return func(c *consoleConfig) {
+		c.allowedOrigins = append(c.allowedOrigins, origins...)
+	}
+}
+
+// WithConsoleConcurrency sets how many commands of one connection run at
+// once; n <= 0 keeps DefaultConsoleConcurrency
+func WithConsoleConcurrency(n int) ConsoleOption {
+	return func(c *consoleConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// consoleOps maps operation names to the Controller methods performing them
+var consoleOps = map[string]func(svcmgr.Controller, context.Context) error{
+	svcmgr.OpUp.String():        svcmgr.Controller.Up,
+	svcmgr.OpDown.String():      svcmgr.Controller.Down,
+	svcmgr.OpOnce.String():      svcmgr.Controller.Once,
+	svcmgr.OpTerm.String():      svcmgr.Controller.Term,
+	svcmgr.OpKill.String():      svcmgr.Controller.Kill,
+	svcmgr.OpHUP.String():       svcmgr.Controller.HUP,
+	svcmgr.OpAlarm.String():     svcmgr.Controller.Alarm,
+	svcmgr.OpInterrupt.String(): svcmgr.Controller.Interrupt,
+	svcmgr.OpQuit.String():      svcmgr.Controller.Quit,
+	svcmgr.OpUSR1.String():      svcmgr.Controller.USR1,
+	svcmgr.OpUSR2.String():      svcmgr.Controller.USR2,
+	svcmgr.OpPause.String():     svcmgr.Controller.Pause,
+	svcmgr.OpCont.String():      svcmgr.Controller.Continue,
+	svcmgr.OpRestart.String():   svcmgr.Controller.Restart,
+}
+
+// Console returns a WebSocket handler for interactive consoles. Status
+// events for the selected services (the "service" query parameter, as for
+// Events) are sent downstream as ConsoleMessages of type "status"; the
+// client sends ConsoleCommands upstream, each answered with an "ack"
+// message carrying its ID once the operation completed. Commands run
+// concurrently, up to WithConsoleConcurrency per connection, and may target
+// any selected service. Supervisor exit is not offered. Browser pages from
+// other origins are refused unless allowed with WithAllowedOrigins.
+//
+// Example:
+//
+//	http.Handle("/console", svcmgrhttp.Console(clients))
+//
+//	// In the browser:
+//	// ws.send(JSON.stringify({id: "1", service: "web", op: "restart"}))
+func Console(clients map[string]svcmgr.ServiceClient, opts ...ConsoleOption) http.Handler {
+	cfg := consoleConfig{concurrency: DefaultConsoleConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names, ok := selectServices(w, r, clients)
+		if !ok {
+			return
+		}
+		conn, err := upgradeWebsocket(w, r, cfg.allowedOrigins)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		send := func(msg ConsoleMessage) bool {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return false
+			}
+			if err := conn.writeText(data); err != nil {
+				cancel()
+				return false
+			}
+			return true
+		}
+		err = watchServices(ctx, &wg, clients, names, func(ev Event) bool {
+			return send(ConsoleMessage{Type: "status", Service: ev.Service, Event: &ev.Event})
+		})
+		if err != nil {
+			_ = conn.writeFrame(wsClose, closePayload(1011, err.Error()))
+			return
+		}
+
+		// Unblock reads and writes once the console is done
+		context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+		sem := make(chan struct{}, cfg.concurrency)
+		selected := make(map[string]bool, len(names))
+		for _, name := range names {
+			selected[name] = true
+		}
+		for {
+			data, err := conn.read()
+			if err != nil {
+				return
+			}
+			var cmd ConsoleCommand
+			if err := json.Unmarshal(data, &cmd); err != nil {
+				send(ConsoleMessage{Type: "ack", Error: fmt.Sprintf("invalid command: %v", err)})
+				continue
+			}
+			op, ok := consoleOps[cmd.Op]
+			switch {
+			case !selected[cmd.Service]:
+				send(ConsoleMessage{Type: "ack", ID: cmd.ID, Service: cmd.Service, Error: fmt.Sprintf("unknown service %q", cmd.Service)})
+				continue
+			case !ok:
+				send(ConsoleMessage{Type: "ack", ID: cmd.ID, Service: cmd.Service, Error: fmt.Sprintf("unknown operation %q", cmd.Op)})
+				continue
+			case !authorized(r, cmd.Service, cmd.Op):
+				send(ConsoleMessage{Type: "ack", ID: cmd.ID, Service: cmd.Service, Error: http.StatusText(http.StatusForbidden)})
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				ack := ConsoleMessage{Type: "ack", ID: cmd.ID, Service: cmd.Service, OK: true}
+				if err := op(clients[cmd.Service], ctx); err != nil {
+					ack.OK, ack.Error = false, err.Error()
+				}
+				send(ack)
+			}()
+		}
+	})
+}
+
+// closePayload builds the body of a close frame (RFC 6455 5.5.1)
+func closePayload(code uint16, reason string) []byte {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return append([]byte{byte(code >> 8), byte(code)}, reason...)
+}
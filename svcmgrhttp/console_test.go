@@ -0,0 +1,221 @@
+package svcmgrhttp_test
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrhttp"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+// wsClient is a minimal WebSocket client for exercising Console
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWebsocket(t *testing.T, srv *httptest.Server, path string) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key))
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") == "" {
+		t.Fatalf("handshake response %d %v", resp.StatusCode, resp.Header)
+	}
+	return &wsClient{conn: conn, br: br}
+}
+
+// send writes v as a masked text frame
+func (c *wsClient) send(t *testing.T, v any) {
+	t.Helper()
+	data, _ := json.Marshal(v)
+	if len(data) >= 126 {
+		t.Fatal("test message too long")
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | byte(len(data))}
+	frame = append(frame, mask[:]...)
+	for i, b := range data {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// next reads the next message
+func (c *wsClient) next(t *testing.T) svcmgrhttp.ConsoleMessage {
+	t.Helper()
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatal(err)
+	}
+	if op := hdr[0] & 0x0F; op != 0x1 {
+		t.Fatalf("opcode %#x, want text", op)
+	}
+	var msg svcmgrhttp.ConsoleMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestConsole(t *testing.T) {
+	web := svcmgrtest.NewFakeClient()
+	clients := map[string]svcmgr.ServiceClient{"web": web, "db": svcmgrtest.NewFakeClient()}
+	srv := httptest.NewServer(svcmgrhttp.Console(clients))
+	defer srv.Close()
+
+	// Plain requests are refused
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("plain GET: status %d, want 426", resp.StatusCode)
+	}
+
+	ws := dialWebsocket(t, srv, "/?service=web")
+	msg := ws.next(t)
+	if msg.Type != "status" || msg.Service != "web" || msg.Event == nil || msg.Event.Status.State != svcmgr.StateDown {
+		t.Fatalf("first message = %+v, want web down", msg)
+	}
+
+	// A command is acknowledged and its effect streamed back
+	ws.send(t, svcmgrhttp.ConsoleCommand{ID: "1", Service: "web", Op: "up"})
+	var acked, running bool
+	for !acked || !running {
+		msg := ws.next(t)
+		switch msg.Type {
+		case "ack":
+			if msg.ID != "1" || !msg.OK {
+				t.Fatalf("ack = %+v", msg)
+			}
+			acked = true
+		case "status":
+			running = msg.Event.Status.State == svcmgr.StateRunning
+		}
+	}
+	if web.Ops()[0] != svcmgr.OpUp {
+		t.Errorf("ops = %v, want [up]", web.Ops())
+	}
+
+	// Unknown operations and unselected services are rejected
+	ws.send(t, svcmgrhttp.ConsoleCommand{ID: "2", Service: "web", Op: "exit"})
+	if msg := ws.next(t); msg.ID != "2" || msg.OK || msg.Error == "" {
+		t.Errorf("exit ack = %+v, want an error", msg)
+	}
+	ws.send(t, svcmgrhttp.ConsoleCommand{ID: "3", Service: "db", Op: "up"})
+	if msg := ws.next(t); msg.ID != "3" || msg.OK || msg.Error == "" {
+		t.Errorf("unselected service ack = %+v, want an error", msg)
+	}
+}
+
+func TestConsoleOrigin(t *testing.T) {
+	clients := map[string]svcmgr.ServiceClient{"web": svcmgrtest.NewFakeClient()}
+	srv := httptest.NewServer(svcmgrhttp.Console(clients, svcmgrhttp.WithAllowedOrigins("https://ops.example.com")))
+	defer srv.Close()
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{srv.URL, http.StatusSwitchingProtocols},
+		{"https://ops.example.com", http.StatusSwitchingProtocols},
+		{"https://evil.example.com", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/?service=web", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("Origin %q: status %d, want %d", tt.origin, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestConsoleRejectsInvalidFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"reserved bits", []byte{0xC1, 0x80, 0, 0, 0, 0}},
+		{"fragmented ping", []byte{0x09, 0x80, 0, 0, 0, 0}},
+		{"orphan continuation", []byte{0x80, 0x80, 0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := map[string]svcmgr.ServiceClient{"web": svcmgrtest.NewFakeClient()}
+			srv := httptest.NewServer(svcmgrhttp.Console(clients))
+			defer srv.Close()
+
+			ws := dialWebsocket(t, srv, "/?service=web")
+			ws.next(t)
+			if _, err := ws.conn.Write(tt.frame); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadAll(ws.br); err != nil {
+				t.Fatalf("connection not closed: %v", err)
+			}
+		})
+	}
+}
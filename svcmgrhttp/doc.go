@@ -0,0 +1,22 @@
+// Package svcmgrhttp provides HTTP handlers exposing supervised services.
+//
+// Healthz turns aggregate service state into a health endpoint for load
+// balancers and orchestrators:
+//
+//	m := svcmgr.NewManager()
+//	http.Handle("/healthz", svcmgrhttp.Healthz(m, []string{"/etc/service/web"}, svcmgrhttp.AllUp))
+//	log.Fatal(http.ListenAndServe(":8080", nil))
+//
+// Events streams status changes as Server-Sent Events for live dashboards:
+//
+//	http.Handle("/events", svcmgrhttp.Events(clients, 0))
+//
+// Console adds control commands over a WebSocket, acknowledged per message:
+//
+//	http.Handle("/console", svcmgrhttp.Console(clients))
+//
+// RequireAuth protects any of the handlers with bearer tokens or mTLS
+// client certificates before they are exposed beyond localhost, and
+// RequireAuthz limits each principal to the operations and services its
+// roles grant.
+package svcmgrhttp
@@ -0,0 +1,175 @@
+package svcmgrhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// DefaultKeepAlive is how often Events writes a comment to keep idle
+// connections open through proxies
+const DefaultKeepAlive = 15 * time.Second
+
+// Event is the JSON data of each server-sent event
+type Event struct {
+	// Service names the service
+	Service string `json:"service"`
+	// Event is the watch event, in the versioned WatchEvent schema
+	Event svcmgr.WatchEvent `json:"event"`
+}
+
+// Events returns a handler streaming the clients' watch events, keyed by
+// service name, as Server-Sent Events. Each service's current status is
+// sent first, then every change, as "status" events carrying an Event:
+//
+//	event: status
+//	data: {"service":"web","event":{...}}
+//
+// The "service" query parameter, which may be repeated, selects services;
+// without it all are streamed, and an unknown name is a 404. A comment is
+// written every keepAlive (zero means DefaultKeepAlive) so proxies do not
+// close idle streams. The stream ends when the client disconnects.
+//
+// Example:
+//
+//	http.Handle("/events", svcmgrhttp.Events(clients, 0))
+//
+//	// In the browser:
+//	// new EventSource("/events?service=web").addEventListener("status", ...)
+func Events(clients map[string]svcmgr.ServiceClient, keepAlive time.Duration) http.Handler {
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		names, ok := selectServices(w, r, clients)
+		if !ok {
+			return
+		}
+
+		events := make(chan Event)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		send := func(ev Event) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if err := watchServices(ctx, &wg, clients, names, send); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		for {
+			var err error
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			case ev := <-events:
+				var data []byte
+				if data, err = json.Marshal(ev); err == nil {
+					_, err = fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+				}
+			}
+			if err == nil {
+				err = rc.Flush()
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+// selectServices returns the services named by the request's "service"
+// query parameters, or all the caller may read, in sorted order. It
+// responds 404 for an unknown name and 403 for one the caller may not read,
+// returning false.
+func selectServices(w http.ResponseWriter, r *http.Request, clients map[string]svcmgr.ServiceClient) ([]string, bool) {
+	names := r.URL.Query()["service"]
+	for _, name := range names {
+		if _, ok := clients[name]; !ok {
+			http.Error(w, fmt.Sprintf("unknown service %q", name), http.StatusNotFound)
+			return nil, false
+		}
+		if !authorized(r, name, OpRead) {
+			forbidden(w)
+			return nil, false
+		}
+	}
+	if len(names) == 0 {
+		for name := range clients {
+			if authorized(r, name, OpRead) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), true
+}
+
+// watchServices watches the named clients from goroutines tracked by wg,
+// passing each service's current status and then every change to send
+// until ctx is done or send returns false. Cancel ctx to stop the
+// goroutines started before a watch fails.
+func watchServices(ctx context.Context, wg *sync.WaitGroup, clients map[string]svcmgr.ServiceClient, names []string, send func(Event) bool) error {
+	for _, name := range names {
+		client := clients[name]
+		watch, stop, err := client.Watch(ctx)
+		if err != nil {
+			return fmt.Errorf("watching %s: %w", name, err)
+		}
+		st, err := client.Status(ctx)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = stop() }()
+
+			if !send(Event{Service: name, Event: svcmgr.WatchEvent{Status: st, Err: err}}) {
+				return
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ev, ok := <-watch:
+					if !ok || !send(Event{Service: name, Event: ev}) {
+						return
+					}
+				}
+			}
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package svcmgrhttp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrhttp"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestEvents(t *testing.T) {
+	web := svcmgrtest.NewFakeClient()
+	db := svcmgrtest.NewFakeClient()
+	clients := map[string]svcmgr.ServiceClient{"web": web, "db": db}
+
+	srv := httptest.NewServer(svcmgrhttp.Events(clients, 0))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?service=web&service=nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown service: status %d, want 404", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?service=web", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	next := func() svcmgrhttp.Event {
+		t.Helper()
+		var name string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				name = v
+				continue
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				if name != "status" {
+					t.Fatalf("event type = %q, want status", name)
+				}
+				var ev svcmgrhttp.Event
+				if err := json.Unmarshal([]byte(data), &ev); err != nil {
+					t.Fatal(err)
+				}
+				return ev
+			}
+		}
+		t.Fatalf("stream ended: %v", scanner.Err())
+		return svcmgrhttp.Event{}
+	}
+
+	// The current status comes first
+	ev := next()
+	if ev.Service != "web" || ev.Event.Status.State != svcmgr.StateDown {
+		t.Errorf("first event = %+v, want web down", ev)
+	}
+
+	// Then each change, for the selected service only
+	db.SetStatus(svcmgr.Status{State: svcmgr.StateRunning, PID: 7})
+	web.SetStatus(svcmgr.Status{State: svcmgr.StateRunning, PID: 42})
+	ev = next()
+	if ev.Service != "web" || ev.Event.Status.State != svcmgr.StateRunning || ev.Event.Status.PID != 42 {
+		t.Errorf("change event = %+v, want web running as 42", ev)
+	}
+}
@@ -0,0 +1,100 @@
+package svcmgrhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// Policy decides whether a set of services is healthy. Services whose
+// status could not be read are absent from statuses.
+type Policy func(services []string, statuses map[string]svcmgr.Status) bool
+
+// AllUp is healthy when every service is running
+func AllUp(services []string, statuses map[string]svcmgr.Status) bool {
+	return countUp(statuses) == len(services)
+}
+
+// AnyUp is healthy when at least one service is running
+func AnyUp(_ []string, statuses map[string]svcmgr.Status) bool {
+	return countUp(statuses) > 0
+}
+
+// Quorum is healthy when at least n services are running
+func Quorum(n int) Policy {
+	return func(_ []string, statuses map[string]svcmgr.Status) bool {
+		return countUp(statuses) >= n
+	}
+}
+
+// Majority is healthy when more than half of the services are running
+func Majority(services []string, statuses map[string]svcmgr.Status) bool {
+	return countUp(statuses) > len(services)/2
+}
+
+// countUp counts the running services
+func countUp(statuses map[string]svcmgr.Status) int {
+	n := 0
+	for _, st := range statuses {
+		if st.State == svcmgr.StateRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// HealthzResponse is the JSON body served by Healthz
+type HealthzResponse struct {
+	// Healthy is the policy's verdict
+	Healthy bool `json:"healthy"`
+	// Services maps each service to its state, "unknown" when it could not be read
+	Services map[string]svcmgr.State `json:"services"`
+}
+
+// Healthz returns a handler that reads the services' status through m and
+// responds 200 when policy deems them healthy, 503 otherwise, with a
+// HealthzResponse body. A nil policy means AllUp. HEAD requests get the
+// status code without a body.
+func Healthz(m *svcmgr.Manager, services []string, policy Policy) http.Handler {
+	if policy == nil {
+		policy = AllUp
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		for _, svc := range services {
+			if !authorized(r, svc, OpRead) {
+				forbidden(w)
+				return
+			}
+		}
+
+		// Unreadable services are left out and count as down
+		statuses, _ := m.Status(r.Context(), services...)
+
+		resp := HealthzResponse{
+			Healthy:  policy(services, statuses),
+			Services: make(map[string]svcmgr.State, len(services)),
+		}
+		for _, svc := range services {
+			resp.Services[svc] = statuses[svc].State
+		}
+
+		code := http.StatusOK
+		if !resp.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
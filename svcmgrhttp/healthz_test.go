@@ -0,0 +1,66 @@
+package svcmgrhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrhttp"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestHealthz(t *testing.T) {
+	dir := t.TempDir()
+	var services []string
+	for i, running := range []bool{true, true, false} {
+		mock, err := svcmgrtest.NewMockSupervisor(filepath.Join(dir, string(rune('a'+i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.UpdateStatus(running, 100+i); err != nil {
+			t.Fatal(err)
+		}
+		services = append(services, mock.ServiceDir)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name     string
+		services []string
+		policy   svcmgrhttp.Policy
+		method   string
+		want     int
+	}{
+		{"all up", services[:2], nil, http.MethodGet, http.StatusOK},
+		{"one down", services, svcmgrhttp.AllUp, http.MethodGet, http.StatusServiceUnavailable},
+		{"majority", services, svcmgrhttp.Majority, http.MethodGet, http.StatusOK},
+		{"any", []string{services[2], missing}, svcmgrhttp.AnyUp, http.MethodGet, http.StatusServiceUnavailable},
+		{"quorum", services, svcmgrhttp.Quorum(2), http.MethodHead, http.StatusOK},
+		{"post", services, nil, http.MethodPost, http.StatusMethodNotAllowed},
+	}
+
+	m := svcmgr.NewManager()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			svcmgrhttp.Healthz(m, tt.services, tt.policy).ServeHTTP(rec, httptest.NewRequest(tt.method, "/healthz", nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.method != http.MethodGet {
+				return
+			}
+
+			var resp svcmgrhttp.HealthzResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Healthy != (tt.want == http.StatusOK) || len(resp.Services) != len(tt.services) {
+				t.Errorf("response = %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package svcmgrhttp
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is appended to the client's key in the handshake (RFC 6455 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWebsocketMessage bounds the messages read from clients
+const maxWebsocketMessage = 1 << 20
+
+// WebSocket opcodes (RFC 6455 5.2)
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// errWebsocketClosed is returned by read after the peer's close frame
+var errWebsocketClosed = errors.New("websocket closed")
+
+// websocketConn is the server side of a WebSocket connection, enough for
+// exchanging JSON text messages: fragmented messages are reassembled,
+// pings are answered and writes are serialized.
+type websocketConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	mu sync.Mutex // serializes frame writes
+}
+
+// sameOrigin reports whether the request's Origin header, when a browser
+// sent one, names the host the request was made to or one of allowed
+func sameOrigin(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range allowed {
+		if strings.EqualFold(origin, o) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// upgradeWebsocket performs the server handshake on an HTTP request.
+// Cross-origin requests not in allowedOrigins are refused, so a web page
+// cannot ride on the browser's credentials (cross-site WebSocket hijacking).
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*websocketConn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return nil, errors.New("not a websocket upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return nil, errors.New("unsupported websocket version")
+	}
+	if !sameOrigin(r, allowedOrigins) {
+		http.Error(w, "cross-origin websocket refused", http.StatusForbidden)
+		return nil, errors.New("cross-origin websocket")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("missing Sec-WebSocket-Key")
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, err
+	}
+	// Deadlines set by the server for the HTTP exchange no longer apply
+	_ = conn.SetDeadline(time.Time{})
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := brw.Flush(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &websocketConn{conn: conn, br: brw.Reader}, nil
+}
+
+// headerContains reports whether a comma-separated header lists token
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for part := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// read returns the next data message, answering control frames on the way
+func (c *websocketConn) read() ([]byte, error) {
+	var msg []byte
+	fragmented := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			_ = c.writeFrame(wsClose, payload)
+			return nil, errWebsocketClosed
+		case wsText, wsBinary:
+			if fragmented {
+				return nil, errors.New("websocket: data frame inside a fragmented message")
+			}
+		case wsContinuation:
+			if !fragmented {
+				return nil, errors.New("websocket: continuation frame without a message")
+			}
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %#x", opcode)
+		}
+		fragmented = true
+
+		msg = append(msg, payload...)
+		if len(msg) > maxWebsocketMessage {
+			return nil, errors.New("websocket: message too large")
+		}
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads one frame; client frames must be masked (RFC 6455 5.1)
+func (c *websocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(c.br, hdr[:]); err != nil {
+		return
+	}
+	fin = hdr[0]&0x80 != 0
+	opcode = hdr[0] & 0x0F
+	if hdr[0]&0x70 != 0 {
+		// No extension is negotiated, so the RSV bits must be clear
+		err = errors.New("websocket: reserved bits set")
+		return
+	}
+	if hdr[1]&0x80 == 0 {
+		err = errors.New("websocket: unmasked client frame")
+		return
+	}
+	// Control frames must not be fragmented and carry at most 125 bytes
+	// (RFC 6455 5.5)
+	if opcode&0x8 != 0 && (!fin || hdr[1]&0x7F > 125) {
+		err = errors.New("websocket: invalid control frame")
+		return
+	}
+
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWebsocketMessage {
+		err = errors.New("websocket: frame too large")
+		return
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// writeText sends data as a single text frame
+func (c *websocketConn) writeText(data []byte) error {
+	return c.writeFrame(wsText, data)
+}
+
+// writeFrame sends one unmasked, final frame
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	frame = append(frame, payload...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// Close closes the underlying connection
+func (c *websocketConn) Close() error {
+	return c.conn.Close()
+}
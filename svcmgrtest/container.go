@@ -0,0 +1,265 @@
+package svcmgrtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// Container defaults
+const (
+	// DefaultDockerPath is the docker CLI used to manage supervisor containers
+	DefaultDockerPath = "docker"
+
+	// DefaultContainerStartTimeout bounds installing and starting the supervisor
+	DefaultContainerStartTimeout = 2 * time.Minute
+
+	// ContainerScanDir is the scan directory inside the container
+	ContainerScanDir = "/service"
+
+	// containerReadyFile is created in the scan directory once the supervisor
+	// is installed; scanners ignore dot files
+	containerReadyFile = ".svcmgr-ready"
+)
+
+// ContainerConfig describes how a supervisor container is started
+type ContainerConfig struct {
+	// Image is the container image
+	Image string
+
+	// Setup is a shell command run before the scanner, e.g. to install it
+	Setup string
+
+	// Command is the scanner command, run with ContainerScanDir as its scan directory
+	Command string
+
+	// DockerPath is the docker CLI (or a compatible one such as podman)
+	DockerPath string
+
+	// StartTimeout bounds Setup and the scanner start
+	StartTimeout time.Duration
+}
+
+// DefaultContainerConfig returns a container configuration that installs and
+// runs the scanner of the given supervision system
+func DefaultContainerConfig(serviceType svcmgr.ServiceType) (ContainerConfig, error) {
+	cfg := ContainerConfig{
+		DockerPath:   DefaultDockerPath,
+		StartTimeout: DefaultContainerStartTimeout,
+	}
+
+	switch serviceType {
+	case svcmgr.ServiceTypeRunit:
+		cfg.Image = "alpine:3.20"
+		cfg.Setup = "apk add --no-cache runit >/dev/null"
+		cfg.Command = "runsvdir -P " + ContainerScanDir
+	case svcmgr.ServiceTypeS6:
+		cfg.Image = "alpine:3.20"
+		cfg.Setup = "apk add --no-cache s6 >/dev/null"
+		cfg.Command = "s6-svscan " + ContainerScanDir
+	case svcmgr.ServiceTypeDaemontools:
+		cfg.Image = "debian:bookworm-slim"
+		cfg.Setup = "apt-get update -qq && apt-get install -y -qq daemontools >/dev/null"
+		cfg.Command = "svscan " + ContainerScanDir
+	default:
+		return ContainerConfig{}, fmt.Errorf("no container support for service type %v", serviceType)
+	}
+
+	return cfg, nil
+}
+
+// SupervisorContainer is a running container whose scanner supervises the
+// services in a bind-mounted host directory. Clients on the host talk to the
+// services through ScanDir; PIDs in their status are container PIDs.
+type SupervisorContainer struct {
+	// Type is the supervision system running in the container
+	Type svcmgr.ServiceType
+
+	// ID is the container ID
+	ID string
+
+	// ScanDir is the host directory mounted at ContainerScanDir
+	ScanDir string
+
+	// Config is the configuration the container was started with
+	Config ContainerConfig
+}
+
+// StartSupervisorContainer starts a container running the scanner for
+// serviceType over scanDir and waits until the scanner has started
+func StartSupervisorContainer(ctx context.Context, serviceType svcmgr.ServiceType, scanDir string, cfg ContainerConfig) (*SupervisorContainer, error) {
+	absDir, err := filepath.Abs(scanDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving scan dir: %w", err)
+	}
+	if cfg.DockerPath == "" {
+		cfg.DockerPath = DefaultDockerPath
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = DefaultContainerStartTimeout
+	}
+
+	script := fmt.Sprintf("%s && touch %s && exec %s",
+		cfg.Setup, filepath.Join(ContainerScanDir, containerReadyFile), cfg.Command)
+	if cfg.Setup == "" {
+		script = fmt.Sprintf("touch %s && exec %s", filepath.Join(ContainerScanDir, containerReadyFile), cfg.Command)
+	}
+
+	out, err := runDocker(ctx, cfg.DockerPath, "run", "-d", "--rm",
+		"-v", absDir+":"+ContainerScanDir,
+		cfg.Image, "sh", "-c", script)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &SupervisorContainer{
+		Type:    serviceType,
+		ID:      strings.TrimSpace(string(out)),
+		ScanDir: absDir,
+		Config:  cfg,
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, cfg.StartTimeout)
+	defer cancel()
+	if err := waitForFile(startCtx, filepath.Join(absDir, containerReadyFile)); err != nil {
+		_ = c.Stop(context.Background())
+		return nil, fmt.Errorf("waiting for %v scanner: %w", serviceType, err)
+	}
+
+	return c, nil
+}
+
+// CreateService builds a service named name running cmd in the scan
+// directory and returns its host path. The scanner picks it up on its next
+// scan; use WaitSupervised to wait for it.
+func (c *SupervisorContainer) CreateService(name string, cmd []string) (string, error) {
+	builder := svcmgr.NewServiceBuilderWithConfig(name, c.ScanDir, configFor(c.Type))
+	builder.WithCmd(cmd)
+	if err := builder.Build(); err != nil {
+		return "", fmt.Errorf("building service %s: %w", name, err)
+	}
+	return filepath.Join(c.ScanDir, name), nil
+}
+
+// WaitSupervised waits until the service named name has a valid status file
+func (c *SupervisorContainer) WaitSupervised(ctx context.Context, name string) error {
+	return svcmgr.WaitSupervised(ctx, filepath.Join(c.ScanDir, name), c.Type)
+}
+
+// Client returns a client for the service named name
+func (c *SupervisorContainer) Client(name string) (svcmgr.ServiceClient, error) {
+	return svcmgr.NewClient(filepath.Join(c.ScanDir, name), svcmgr.WithServiceType(c.Type))
+}
+
+// Exec runs a command inside the container and returns its standard output
+func (c *SupervisorContainer) Exec(ctx context.Context, args ...string) ([]byte, error) {
+	return runDocker(ctx, c.Config.DockerPath, append([]string{"exec", c.ID}, args...)...)
+}
+
+// Stop removes the files the container created in the scan directory and
+// removes the container
+func (c *SupervisorContainer) Stop(ctx context.Context) error {
+	// Supervise directories are created by root inside the container; clear
+	// them from inside so the host can remove the scan directory afterwards
+	_, _ = c.Exec(ctx, "sh", "-c", "rm -rf "+ContainerScanDir+"/* "+ContainerScanDir+"/.[!.]*")
+
+	_, err := runDocker(ctx, c.Config.DockerPath, "rm", "-f", c.ID)
+	return err
+}
+
+// WithSupervisorContainer runs fn against a fresh container running the
+// scanner for serviceType, using DefaultContainerConfig and a temporary scan
+// directory. The test is skipped in short mode or when docker is unavailable.
+func WithSupervisorContainer(t testing.TB, serviceType svcmgr.ServiceType, fn func(c *SupervisorContainer)) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping supervisor container in short mode")
+	}
+
+	cfg, err := DefaultContainerConfig(serviceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dockerAvailable(cfg.DockerPath); err != nil {
+		t.Skipf("docker not available: %v", err)
+	}
+
+	c, err := StartSupervisorContainer(context.Background(), serviceType, t.TempDir(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := c.Stop(context.Background()); err != nil {
+			t.Errorf("stopping container: %v", err)
+		}
+	}()
+
+	fn(c)
+}
+
+// configFor returns the service configuration for a supervision system
+func configFor(serviceType svcmgr.ServiceType) *svcmgr.ServiceConfig {
+	switch serviceType {
+	case svcmgr.ServiceTypeS6:
+		return svcmgr.ConfigS6()
+	case svcmgr.ServiceTypeDaemontools:
+		return svcmgr.ConfigDaemontools()
+	default:
+		return svcmgr.ConfigRunit()
+	}
+}
+
+// dockerAvailable reports why the docker CLI cannot be used, if it cannot
+func dockerAvailable(dockerPath string) error {
+	if _, err := exec.LookPath(dockerPath); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := runDocker(ctx, dockerPath, "info", "--format", "{{.ServerVersion}}")
+	return err
+}
+
+// runDocker runs the docker CLI and returns its stdout
+func runDocker(ctx context.Context, dockerPath string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, dockerPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), fmt.Errorf("%s %s: %w: %s", dockerPath, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// waitForFile polls until path exists or ctx is done
+func waitForFile(ctx context.Context, path string) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package svcmgrtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestDefaultContainerConfig(t *testing.T) {
+	for _, typ := range []svcmgr.ServiceType{svcmgr.ServiceTypeRunit, svcmgr.ServiceTypeDaemontools, svcmgr.ServiceTypeS6} {
+		cfg, err := svcmgrtest.DefaultContainerConfig(typ)
+		if err != nil {
+			t.Fatalf("%v: %v", typ, err)
+		}
+		if cfg.Image == "" || cfg.Command == "" {
+			t.Errorf("%v: incomplete config %+v", typ, cfg)
+		}
+	}
+
+	if _, err := svcmgrtest.DefaultContainerConfig(svcmgr.ServiceTypeSystemd); err == nil {
+		t.Error("expected error for systemd")
+	}
+}
+
+func TestWithSupervisorContainer(t *testing.T) {
+	for _, typ := range []svcmgr.ServiceType{svcmgr.ServiceTypeRunit, svcmgr.ServiceTypeDaemontools, svcmgr.ServiceTypeS6} {
+		t.Run(typ.String(), func(t *testing.T) {
+			svcmgrtest.WithSupervisorContainer(t, typ, func(c *svcmgrtest.SupervisorContainer) {
+				if _, err := c.CreateService("sleeper", []string{"sleep", "3600"}); err != nil {
+					t.Fatal(err)
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				if err := c.WaitSupervised(ctx, "sleeper"); err != nil {
+					t.Fatal(err)
+				}
+
+				client, err := c.Client("sleeper")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := client.Wait(ctx, []svcmgr.State{svcmgr.StateRunning}); err != nil {
+					t.Fatalf("service never reached running: %v", err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Package svcmgrtest provides test helpers for code built on svcmgr.
+//
+// MockSupervisor lays out a fake supervise directory with a status file in
+// the binary format of runit, daemontools or s6, so svcmgr clients can be
+// created and their Status calls exercised without a supervisor installed:
+//
+//	mock, err := svcmgrtest.NewMockSupervisorWithType(t.TempDir(), svcmgr.ServiceTypeRunit)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	_ = mock.UpdateStatus(true, 1234)
+//
+//	client, _ := svcmgr.NewClient(mock.ServiceDir, svcmgr.ServiceTypeRunit)
+//	status, _ := client.Status(ctx) // StateRunning, PID 1234
+//
+// The control file is a regular file, so control operations succeed without
+// acting on anything; tests should drive state changes through UpdateStatus.
+//
+// FakeClient is an in-memory svcmgr.ServiceClient for pure unit tests of
+// orchestration logic. It records operations and applies programmable state
+// transitions without touching the filesystem:
+//
+//	fake := svcmgrtest.NewFakeClient()
+//	fake.FailOp(svcmgr.OpTerm, errors.New("permission denied"))
+//	err := deploy(ctx, fake)
+//	ops := fake.Ops() // e.g. [OpUp OpTerm]
+package svcmgrtest
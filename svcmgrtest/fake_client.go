@@ -0,0 +1,398 @@
+package svcmgrtest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// Transition computes a service's status after an operation from its
+// status before it
+type Transition func(svcmgr.Status) svcmgr.Status
+
+// FakeClient is an in-memory svcmgr.ServiceClient for unit tests of code
+// that orchestrates services. It needs no filesystem: operations are
+// recorded, move the service through programmable state transitions and
+// notify watchers immediately.
+//
+// By default it behaves like a runit service that starts and stops
+// instantly: Up, Once and Restart run a new process, Down stops it, Term
+// and Kill restart it while it is wanted up, and Pause and Continue toggle
+// the paused state. Override a transition with OnOp, inject failures with
+// FailOp, or set the status directly with SetStatus.
+type FakeClient struct {
+	mu          sync.Mutex
+	status      svcmgr.Status
+	ops         []svcmgr.Operation
+	errs        map[svcmgr.Operation]error
+	transitions map[svcmgr.Operation]Transition
+	watchers    map[chan svcmgr.WatchEvent]struct{}
+	tree        []svcmgr.ProcessInfo
+	caps        svcmgr.Capabilities
+	nextPID     int
+	clock       svcmgr.Clock
+}
+
+var (
+	_ svcmgr.ServiceClient      = (*FakeClient)(nil)
+	_ svcmgr.ProcessInspector   = (*FakeClient)(nil)
+	_ svcmgr.FuncWaiter         = (*FakeClient)(nil)
+	_ svcmgr.CapabilityReporter = (*FakeClient)(nil)
+)
+
+// NewFakeClient creates a fake runit service that is down
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		status:      svcmgr.Status{State: svcmgr.StateDown, Flags: svcmgr.Flags{WantDown: true}},
+		errs:        make(map[svcmgr.Operation]error),
+		transitions: make(map[svcmgr.Operation]Transition),
+		watchers:    make(map[chan svcmgr.WatchEvent]struct{}),
+		caps:        svcmgr.ConfigRunit().Capabilities(),
+		nextPID:     1000,
+	}
+}
+
+// WithClock sets the clock used for Status.Since and Uptime
+func (f *FakeClient) WithClock(clock svcmgr.Clock) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clock = clock
+	return f
+}
+
+// WithCapabilities sets the capabilities reported by Capabilities
+func (f *FakeClient) WithCapabilities(caps svcmgr.Capabilities) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.caps = caps
+	return f
+}
+
+// WithProcessTree sets the processes reported by ProcessTree
+func (f *FakeClient) WithProcessTree(tree []svcmgr.ProcessInfo) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tree = tree
+	return f
+}
+
+// OnOp replaces the transition for op; a nil transition leaves the status unchanged
+func (f *FakeClient) OnOp(op svcmgr.Operation, t Transition) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t == nil {
+		t = func(st svcmgr.Status) svcmgr.Status { return st }
+	}
+	f.transitions[op] = t
+}
+
+// FailOp makes op fail with err without changing the status; a nil err
+// clears the failure. The failed operation is still recorded. OpStatus
+// makes Status and StatusExtended fail.
+func (f *FakeClient) FailOp(op svcmgr.Operation, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, op)
+		return
+	}
+	f.errs[op] = err
+}
+
+// SetStatus sets the status and notifies watchers
+func (f *FakeClient) SetStatus(st svcmgr.Status) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setStatus(st)
+}
+
+// Ops returns the control operations performed so far, in order. Aliases
+// are recorded as the operation they stand for (Start as OpUp, Stop as
+// OpDown); status reads are not recorded.
+func (f *FakeClient) Ops() []svcmgr.Operation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.ops)
+}
+
+// ResetOps forgets the recorded operations
+func (f *FakeClient) ResetOps() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ops = nil
+}
+
+// do records op and applies its transition
+func (f *FakeClient) do(ctx context.Context, op svcmgr.Operation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ops = append(f.ops, op)
+	if err := f.errs[op]; err != nil {
+		return err
+	}
+	t, ok := f.transitions[op]
+	if !ok {
+		t = f.defaultTransition(op)
+	}
+	f.setStatus(t(f.status))
+	return nil
+}
+
+// defaultTransition returns the built-in behaviour for op; f.mu must be held
+func (f *FakeClient) defaultTransition(op svcmgr.Operation) Transition {
+	switch op {
+	case svcmgr.OpUp, svcmgr.OpRestart:
+		return func(st svcmgr.Status) svcmgr.Status { return f.started(st, true) }
+	case svcmgr.OpOnce:
+		return func(st svcmgr.Status) svcmgr.Status { return f.started(st, false) }
+	case svcmgr.OpDown, svcmgr.OpExit:
+		return func(st svcmgr.Status) svcmgr.Status { return f.stopped(st) }
+	case svcmgr.OpTerm, svcmgr.OpKill:
+		return func(st svcmgr.Status) svcmgr.Status {
+			if st.Flags.WantUp {
+				return f.started(st, true)
+			}
+			return f.stopped(st)
+		}
+	case svcmgr.OpPause:
+		return func(st svcmgr.Status) svcmgr.Status {
+			if st.State == svcmgr.StateRunning {
+				st.State = svcmgr.StatePaused
+			}
+			return st
+		}
+	case svcmgr.OpCont:
+		return func(st svcmgr.Status) svcmgr.Status {
+			if st.State == svcmgr.StatePaused {
+				st.State = svcmgr.StateRunning
+			}
+			return st
+		}
+	default:
+		// Other signals reach the process without changing its state
+		return func(st svcmgr.Status) svcmgr.Status { return st }
+	}
+}
+
+// started runs a new process; f.mu must be held
+func (f *FakeClient) started(st svcmgr.Status, wantUp bool) svcmgr.Status {
+	f.nextPID++
+	st.State = svcmgr.StateRunning
+	st.PID = f.nextPID
+	st.Since = f.now()
+	st.Flags.WantUp = wantUp
+	st.Flags.WantDown = false
+	return st
+}
+
+// stopped ends the process; f.mu must be held
+func (f *FakeClient) stopped(st svcmgr.Status) svcmgr.Status {
+	st.State = svcmgr.StateDown
+	st.PID = 0
+	st.Since = f.now()
+	st.Ready = false
+	st.Flags.WantUp = false
+	st.Flags.WantDown = true
+	return st
+}
+
+// setStatus stores st and notifies watchers when it changed; f.mu must be held
+func (f *FakeClient) setStatus(st svcmgr.Status) {
+	changed := !f.status.Equal(st)
+	f.status = st
+	if !changed {
+		return
+	}
+	for ch := range f.watchers {
+		select {
+		case ch <- svcmgr.WatchEvent{Status: f.current()}:
+		default:
+			// Drop the event for a watcher that is not keeping up, like the
+			// real watchers coalescing rapid changes
+		}
+	}
+}
+
+// current returns the status with Uptime filled in; f.mu must be held
+func (f *FakeClient) current() svcmgr.Status {
+	st := f.status
+	if !st.Since.IsZero() {
+		st.Uptime = max(f.now().Sub(st.Since), 0)
+	}
+	return st
+}
+
+// now returns the fake's time; f.mu must be held
+func (f *FakeClient) now() time.Time {
+	if f.clock == nil {
+		return time.Now()
+	}
+	return f.clock.Now()
+}
+
+// Status returns the current status
+func (f *FakeClient) Status(ctx context.Context) (svcmgr.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return svcmgr.Status{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.errs[svcmgr.OpStatus]; err != nil {
+		return svcmgr.Status{}, err
+	}
+	return f.current(), nil
+}
+
+// StatusExtended returns the current status without process statistics
+func (f *FakeClient) StatusExtended(ctx context.Context) (svcmgr.StatusExtended, error) {
+	st, err := f.Status(ctx)
+	return svcmgr.StatusExtended{Status: st}, err
+}
+
+// ProcessTree returns the processes set with WithProcessTree
+func (f *FakeClient) ProcessTree(ctx context.Context) ([]svcmgr.ProcessInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.tree), nil
+}
+
+// Up starts the service and keeps it up
+func (f *FakeClient) Up(ctx context.Context) error { return f.do(ctx, svcmgr.OpUp) }
+
+// Down stops the service
+func (f *FakeClient) Down(ctx context.Context) error { return f.do(ctx, svcmgr.OpDown) }
+
+// Term sends SIGTERM
+func (f *FakeClient) Term(ctx context.Context) error { return f.do(ctx, svcmgr.OpTerm) }
+
+// Kill sends SIGKILL
+func (f *FakeClient) Kill(ctx context.Context) error { return f.do(ctx, svcmgr.OpKill) }
+
+// HUP sends SIGHUP
+func (f *FakeClient) HUP(ctx context.Context) error { return f.do(ctx, svcmgr.OpHUP) }
+
+// Alarm sends SIGALRM
+func (f *FakeClient) Alarm(ctx context.Context) error { return f.do(ctx, svcmgr.OpAlarm) }
+
+// Interrupt sends SIGINT
+func (f *FakeClient) Interrupt(ctx context.Context) error { return f.do(ctx, svcmgr.OpInterrupt) }
+
+// Quit sends SIGQUIT
+func (f *FakeClient) Quit(ctx context.Context) error { return f.do(ctx, svcmgr.OpQuit) }
+
+// USR1 sends SIGUSR1
+func (f *FakeClient) USR1(ctx context.Context) error { return f.do(ctx, svcmgr.OpUSR1) }
+
+// USR2 sends SIGUSR2
+func (f *FakeClient) USR2(ctx context.Context) error { return f.do(ctx, svcmgr.OpUSR2) }
+
+// Once starts the service without restarting it on exit
+func (f *FakeClient) Once(ctx context.Context) error { return f.do(ctx, svcmgr.OpOnce) }
+
+// Pause sends SIGSTOP
+func (f *FakeClient) Pause(ctx context.Context) error { return f.do(ctx, svcmgr.OpPause) }
+
+// Continue sends SIGCONT
+func (f *FakeClient) Continue(ctx context.Context) error { return f.do(ctx, svcmgr.OpCont) }
+
+// Start is an alias for Up
+func (f *FakeClient) Start(ctx context.Context) error { return f.Up(ctx) }
+
+// Stop is an alias for Down
+func (f *FakeClient) Stop(ctx context.Context) error { return f.Down(ctx) }
+
+// Restart replaces the running process
+func (f *FakeClient) Restart(ctx context.Context) error { return f.do(ctx, svcmgr.OpRestart) }
+
+// ExitSupervise stops the service and its fake supervisor
+func (f *FakeClient) ExitSupervise(ctx context.Context) error { return f.do(ctx, svcmgr.OpExit) }
+
+// Watch reports every status change until ctx is done or the cleanup
+// function is called
+func (f *FakeClient) Watch(ctx context.Context) (<-chan svcmgr.WatchEvent, svcmgr.WatchCleanupFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan svcmgr.WatchEvent, 16)
+	f.mu.Lock()
+	f.watchers[ch] = struct{}{}
+	f.mu.Unlock()
+
+	var once sync.Once
+	closeWatch := func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.watchers, ch)
+			close(ch)
+			f.mu.Unlock()
+		})
+	}
+	// The watch ends with ctx even when cleanup is never called, and cleanup
+	// releases ctx so nothing is left waiting on it
+	stopAfter := context.AfterFunc(ctx, closeWatch)
+	return ch, func() error {
+		stopAfter()
+		closeWatch()
+		return nil
+	}, nil
+}
+
+// Wait blocks until the service reaches one of states, or changes at all
+// when states is empty
+func (f *FakeClient) Wait(ctx context.Context, states []svcmgr.State) (svcmgr.Status, error) {
+	if len(states) == 0 {
+		f.mu.Lock()
+		initial := f.status
+		f.mu.Unlock()
+		return f.WaitFunc(ctx, func(st svcmgr.Status) bool { return !initial.Equal(st) })
+	}
+	return f.WaitFunc(ctx, func(st svcmgr.Status) bool { return slices.Contains(states, st.State) })
+}
+
+// WaitFunc blocks until pred returns true for the service's status
+func (f *FakeClient) WaitFunc(ctx context.Context, pred func(svcmgr.Status) bool) (svcmgr.Status, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, stop, err := f.Watch(ctx)
+	if err != nil {
+		return svcmgr.Status{}, err
+	}
+	defer stop()
+
+	f.mu.Lock()
+	st := f.current()
+	f.mu.Unlock()
+	if pred(st) {
+		return st, nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return svcmgr.Status{}, ctx.Err()
+		case ev, ok := <-events:
+			if !ok {
+				return svcmgr.Status{}, ctx.Err()
+			}
+			if pred(ev.Status) {
+				return ev.Status, nil
+			}
+		}
+	}
+}
+
+// Capabilities returns the configured capabilities, runit's by default
+func (f *FakeClient) Capabilities() svcmgr.Capabilities {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.caps
+}
@@ -0,0 +1,108 @@
+package svcmgrtest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestFakeClient(t *testing.T) {
+	ctx := context.Background()
+	f := svcmgrtest.NewFakeClient()
+
+	if err := f.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	st, err := f.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != svcmgr.StateRunning || st.PID == 0 || !st.Flags.WantUp {
+		t.Fatalf("after Up: %+v", st)
+	}
+
+	// Term restarts a service that is wanted up
+	if err := f.Term(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if next, _ := f.Status(ctx); next.State != svcmgr.StateRunning || next.PID == st.PID {
+		t.Errorf("after Term: %+v", next)
+	}
+
+	f.OnOp(svcmgr.OpDown, func(st svcmgr.Status) svcmgr.Status {
+		st.State = svcmgr.StateFinishing
+		return st
+	})
+	boom := errors.New("boom")
+	f.FailOp(svcmgr.OpHUP, boom)
+	if err := f.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.HUP(ctx); !errors.Is(err, boom) {
+		t.Errorf("HUP = %v, want injected error", err)
+	}
+	if st, _ := f.Status(ctx); st.State != svcmgr.StateFinishing {
+		t.Errorf("custom Down transition not applied: %+v", st)
+	}
+
+	want := []svcmgr.Operation{svcmgr.OpUp, svcmgr.OpTerm, svcmgr.OpDown, svcmgr.OpHUP}
+	if got := f.Ops(); !slices.Equal(got, want) {
+		t.Errorf("Ops() = %v, want %v", got, want)
+	}
+}
+
+func TestFakeClientWait(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	f := svcmgrtest.NewFakeClient()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		f.SetStatus(svcmgr.Status{State: svcmgr.StateCrashed})
+	}()
+	st, err := f.Wait(ctx, []svcmgr.State{svcmgr.StateCrashed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != svcmgr.StateCrashed {
+		t.Errorf("Wait returned %+v", st)
+	}
+
+	events, stop, err := f.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Once(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Status.State != svcmgr.StateRunning || ev.Status.Flags.WantUp {
+		t.Errorf("watch event = %+v", ev)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-events; ok {
+		t.Error("events not closed after stop")
+	}
+
+	// Without cleanup the watch ends with its context
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	events, _, err = f.Watch(watchCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelWatch()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("unexpected event after the context was canceled")
+		}
+	case <-time.After(time.Second):
+		t.Error("events not closed after the context was canceled")
+	}
+}
@@ -0,0 +1,17 @@
+package svcmgrtest
+
+import "github.com/axondata/go-svcmgr"
+
+// ControlFault selects how the mock control endpoint behaves
+type ControlFault = svcmgr.MockControlFault
+
+const (
+	// ControlOK makes the control endpoint a regular file that accepts every write
+	ControlOK = svcmgr.MockControlOK
+	// ControlNoReader makes the control endpoint a FIFO with no reader, so
+	// non-blocking opens fail with ENXIO as if supervise were not running
+	ControlNoReader = svcmgr.MockControlNoReader
+	// ControlStalled makes the control endpoint a FIFO whose reader never
+	// drains it, so writes block until the client's write deadline expires
+	ControlStalled = svcmgr.MockControlStalled
+)
@@ -0,0 +1,156 @@
+package svcmgrtest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func newFaultClient(t *testing.T) (*svcmgrtest.MockSupervisor, svcmgr.ServiceClient) {
+	t.Helper()
+	mock, err := svcmgrtest.NewMockSupervisor(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = mock.Cleanup() })
+
+	client, err := svcmgr.NewClient(mock.ServiceDir,
+		svcmgr.WithServiceType(svcmgr.ServiceTypeRunit),
+		svcmgr.WithWriteTimeout(20*time.Millisecond),
+		svcmgr.WithControlRetry(2, time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mock, client
+}
+
+func TestControlFaults(t *testing.T) {
+	tests := []struct {
+		fault   svcmgrtest.ControlFault
+		wantErr error
+	}{
+		{fault: svcmgrtest.ControlOK},
+		{fault: svcmgrtest.ControlNoReader, wantErr: syscall.ENXIO},
+		{fault: svcmgrtest.ControlStalled, wantErr: os.ErrDeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fault.String(), func(t *testing.T) {
+			mock, client := newFaultClient(t)
+			if err := mock.SetControlFault(tt.fault); err != nil {
+				t.Fatal(err)
+			}
+
+			err := client.Up(context.Background())
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Up() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Up() = %v, want %v", err, tt.wantErr)
+			}
+
+			// Restoring the control file clears the fault
+			if err := mock.SetControlFault(svcmgrtest.ControlOK); err != nil {
+				t.Fatal(err)
+			}
+			if err := client.Up(context.Background()); err != nil {
+				t.Fatalf("Up() after restore = %v", err)
+			}
+		})
+	}
+}
+
+func TestStatusFaults(t *testing.T) {
+	mock, client := newFaultClient(t)
+	ctx := context.Background()
+
+	if err := mock.TruncateStatus(7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Status(ctx); err == nil {
+		t.Error("expected error for truncated status file")
+	}
+
+	if err := mock.CorruptStatus(); err != nil {
+		t.Fatal(err)
+	}
+	strict, err := svcmgr.NewClientRunit(mock.ServiceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict.StrictDecode = true
+	if _, err := strict.Status(ctx); !errors.Is(err, svcmgr.ErrDecode) {
+		t.Errorf("strict Status() on corrupt record = %v, want ErrDecode", err)
+	}
+}
+
+func TestUpdateStatusAfter(t *testing.T) {
+	mock, client := newFaultClient(t)
+	ctx := context.Background()
+
+	mock.UpdateStatusAfter(50*time.Millisecond, true, 99)
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != svcmgr.StateDown {
+		t.Fatalf("state before delay = %v, want down", status.State)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	for {
+		status, err = client.Status(waitCtx)
+		if err == nil && status.PID == 99 {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			t.Fatalf("delayed transition never happened: %+v", status)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancelUpdate := mock.UpdateStatusAfter(time.Hour, false, 0)
+	if !cancelUpdate() {
+		t.Error("cancel reported the transition as already applied")
+	}
+}
+
+func TestFlap(t *testing.T) {
+	mock, client := newFaultClient(t)
+
+	if err := mock.Flap(5*time.Millisecond, 101, 0, 102); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[int]bool)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(seen) < 3 && time.Now().Before(deadline) {
+		if status, err := client.Status(context.Background()); err == nil {
+			seen[status.PID] = true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mock.StopFlap()
+
+	for _, pid := range []int{101, 0, 102} {
+		if !seen[pid] {
+			t.Errorf("pid %d never observed; saw %v", pid, seen)
+		}
+	}
+
+	if err := mock.Flap(0, 1); err == nil {
+		t.Error("expected error for zero interval")
+	}
+}
@@ -0,0 +1,57 @@
+package svcmgrtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/axondata/go-svcmgr"
+)
+
+// MockCommand is the run command used for services created by CreateMockService
+var MockCommand = []string{"/bin/sh", "-c", "while true; do echo 'Mock service'; sleep 2; done"}
+
+// MockSupervisor maintains a fake supervise directory for a service.
+// It allows tests to run without actual supervisor processes.
+type MockSupervisor = svcmgr.MockSupervisor
+
+// NewMockSupervisor creates a mock runit supervise directory in serviceDir
+func NewMockSupervisor(serviceDir string) (*MockSupervisor, error) {
+	return svcmgr.NewMockSupervisor(serviceDir)
+}
+
+// NewMockSupervisorWithType creates a mock supervise directory in serviceDir
+// for a specific supervision system, with an initial "down" status
+func NewMockSupervisorWithType(serviceDir string, serviceType svcmgr.ServiceType) (*MockSupervisor, error) {
+	return svcmgr.NewMockSupervisorWithType(serviceDir, serviceType)
+}
+
+// CreateMockService builds a service named serviceName under baseDir using
+// config (runit when nil) and attaches a mock supervisor to it. The returned
+// cleanup function removes the whole service directory.
+func CreateMockService(baseDir, serviceName string, config *svcmgr.ServiceConfig) (serviceDir string, mock *MockSupervisor, cleanup func(), err error) {
+	if config == nil {
+		config = svcmgr.ConfigRunit()
+	}
+	serviceDir = filepath.Join(baseDir, serviceName)
+
+	builder := svcmgr.NewServiceBuilderWithConfig(serviceName, baseDir, config)
+	builder.WithCmd(MockCommand)
+
+	if err := builder.Build(); err != nil {
+		return "", nil, nil, fmt.Errorf("failed to build service: %w", err)
+	}
+
+	mock, err = NewMockSupervisorWithType(serviceDir, config.Type)
+	if err != nil {
+		_ = os.RemoveAll(serviceDir)
+		return "", nil, nil, fmt.Errorf("failed to create mock supervisor: %w", err)
+	}
+
+	cleanup = func() {
+		_ = mock.Cleanup()
+		_ = os.RemoveAll(serviceDir)
+	}
+
+	return serviceDir, mock, cleanup, nil
+}
@@ -0,0 +1,56 @@
+package svcmgrtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestMockSupervisorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *svcmgr.ServiceConfig
+	}{
+		{name: "default", config: nil},
+		{name: "runit", config: svcmgr.ConfigRunit()},
+		{name: "daemontools", config: svcmgr.ConfigDaemontools()},
+		{name: "s6", config: svcmgr.ConfigS6()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceDir, mock, cleanup, err := svcmgrtest.CreateMockService(t.TempDir(), "mock-"+tt.name, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+
+			client, err := svcmgr.NewClient(serviceDir, mock.ServiceType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			status, err := client.Status(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.State != svcmgr.StateDown || status.PID != 0 {
+				t.Errorf("initial status = %v pid %d, want down", status.State, status.PID)
+			}
+
+			if err := mock.UpdateStatus(true, 4242); err != nil {
+				t.Fatal(err)
+			}
+			status, err = client.Status(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.State != svcmgr.StateRunning || status.PID != 4242 {
+				t.Errorf("updated status = %v pid %d, want running pid 4242", status.State, status.PID)
+			}
+		})
+	}
+}
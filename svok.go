@@ -0,0 +1,101 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/axondata/go-svcmgr/internal/unix"
+)
+
+// Ok reports whether a supervisor is attached to the service directory,
+// mirroring sv check, svok and s6-svok. It opens the supervise/ok FIFO
+// (or supervise/control for s6) for writing without blocking: the open
+// only succeeds while the supervisor holds the read end.
+// A missing supervise directory is reported as false without error.
+func Ok(serviceDir string) (bool, error) {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+
+	fifo := filepath.Join(superviseDir, OkFile)
+	if _, err := os.Stat(fifo); errors.Is(err, os.ErrNotExist) {
+		// s6-supervise has no ok FIFO; its control FIFO serves the same purpose
+		fifo = filepath.Join(superviseDir, ControlFile)
+	}
+
+	file, err := os.OpenFile(fifo, os.O_WRONLY|unix.ONonblock, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ENXIO) || errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, &OpError{Op: OpStatus, Path: fifo, Err: err}
+	}
+	_ = file.Close()
+	return true, nil
+}
+
+// WaitFor blocks until every service in dirs reaches state, mirroring
+// s6-svwait -a and sv -w. The supervision system of each directory is
+// detected from its supervise directory. Errors from individual services
+// are collected into a MultiError. OnPoll and OnTransition options report
+// progress with the directory in WaitProgress.Service.
+func WaitFor(ctx context.Context, dirs []string, state State, opts ...WaitOption) error {
+	cfg := newWaitConfig(opts)
+
+	var (
+		mu   sync.Mutex
+		errs MultiError
+		wg   sync.WaitGroup
+	)
+
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+
+			err := waitForOne(ctx, dir, state, cfg)
+			mu.Lock()
+			errs.Add(err)
+			mu.Unlock()
+		}(dir)
+	}
+	wg.Wait()
+
+	return errs.Err()
+}
+
+// waitForOne waits for a single service directory to reach state
+func waitForOne(ctx context.Context, dir string, state State, cfg *waitConfig) error {
+	client, err := NewClient(dir, detectServiceType(dir))
+	if err != nil {
+		return err
+	}
+	_, err = waitStates(ctx, client, []State{state}, &tracker{cfg: cfg, service: dir})
+	return err
+}
+
+// detectServiceType infers the supervision system from the files in a
+// service's supervise directory, defaulting to runit
+func detectServiceType(serviceDir string) ServiceType {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+
+	if info, err := os.Stat(filepath.Join(superviseDir, StatusFile)); err == nil {
+		switch info.Size() {
+		case DaemontoolsStatusSize:
+			return ServiceTypeDaemontools
+		case S6StatusSizePre220, S6StatusSizeCurrent:
+			return ServiceTypeS6
+		case StatusFileSize:
+			return ServiceTypeRunit
+		}
+	}
+
+	// Without a status file only s6's event fifodir marks an s6 service; a
+	// bare supervise directory may be runit's before runsv creates its FIFOs
+	if info, err := os.Stat(filepath.Join(serviceDir, "event")); err == nil && info.IsDir() {
+		return ServiceTypeS6
+	}
+	return ServiceTypeRunit
+}
@@ -0,0 +1,114 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestOk(t *testing.T) {
+	dir := t.TempDir()
+
+	ok, err := Ok(dir)
+	if err != nil || ok {
+		t.Fatalf("Ok(unsupervised) = %v, %v; want false, nil", ok, err)
+	}
+
+	superviseDir := filepath.Join(dir, SuperviseDir)
+	if err := os.MkdirAll(superviseDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fifo := filepath.Join(superviseDir, OkFile)
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err = Ok(dir)
+	if err != nil || ok {
+		t.Fatalf("Ok(no reader) = %v, %v; want false, nil", ok, err)
+	}
+
+	// Emulate the supervisor holding the read end open
+	reader, err := os.OpenFile(fifo, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	ok, err = Ok(dir)
+	if err != nil || !ok {
+		t.Fatalf("Ok(reader) = %v, %v; want true, nil", ok, err)
+	}
+}
+
+func TestDetectServiceType(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  ServiceType
+	}{
+		{name: "runit", typ: ServiceTypeRunit},
+		{name: "daemontools", typ: ServiceTypeDaemontools},
+		{name: "s6", typ: ServiceTypeS6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := NewMockSupervisorWithType(dir, tt.typ); err != nil {
+				t.Fatal(err)
+			}
+			if got := detectServiceType(dir); got != tt.typ {
+				t.Errorf("detectServiceType() = %v, want %v", got, tt.typ)
+			}
+		})
+	}
+}
+
+func TestDetectServiceTypeWithoutStatus(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A bare supervise directory, as tooling or runsv leave it before the
+	// first status write, is not evidence of s6
+	if got := detectServiceType(dir); got != ServiceTypeRunit {
+		t.Errorf("detectServiceType(bare supervise) = %v, want %v", got, ServiceTypeRunit)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "event"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectServiceType(dir); got != ServiceTypeS6 {
+		t.Errorf("detectServiceType(event fifodir) = %v, want %v", got, ServiceTypeS6)
+	}
+}
+
+func TestWaitForAlreadyInState(t *testing.T) {
+	var dirs []string
+	for range 2 {
+		dir := t.TempDir()
+		if _, err := NewMockSupervisor(dir); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := WaitFor(ctx, dirs, StateDown); err != nil {
+		t.Fatalf("WaitFor: %v", err)
+	}
+
+	err := WaitFor(ctx, []string{filepath.Join(t.TempDir(), "missing")}, StateDown)
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("WaitFor(missing) error = %v, want MultiError", err)
+	}
+}
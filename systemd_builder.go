@@ -7,7 +7,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -21,10 +20,19 @@ type BuilderSystemd struct {
 	UseSudo bool
 	// SudoCommand is the sudo command to use (default: "sudo")
 	SudoCommand string
+	// Escalator, when set, elevates privileged commands instead of UseSudo
+	// and SudoCommand
+	Escalator Escalator
 	// UnitDir is the directory where unit files are written (default: /etc/systemd/system)
 	UnitDir string
 	// SystemctlPath is the path to systemctl binary
 	SystemctlPath string
+	// UserMode installs the unit for the calling user's service manager
+	// (systemctl --user) instead of the system manager
+	UserMode bool
+	// Template installs the unit as a template (name@.service) whose command
+	// may refer to the instance name with %i
+	Template bool
 }
 
 // NewBuilderSystemd creates a new BuilderSystemd from a ServiceBuilder
@@ -47,6 +55,39 @@ func (b *BuilderSystemd) WithSudo(use bool, command string) *BuilderSystemd {
 	return b
 }
 
+// WithEscalator elevates privileged commands through e instead of sudo
+func (b *BuilderSystemd) WithEscalator(e Escalator) *BuilderSystemd {
+	b.Escalator = e
+	return b
+}
+
+// WithUserMode targets the per-user service manager. Units are written to
+// the user unit directory (~/.config/systemd/user) without sudo.
+func (b *BuilderSystemd) WithUserMode(user bool) *BuilderSystemd {
+	b.UserMode = user
+	if user {
+		b.UseSudo = false
+		if dir, err := UserUnitDir(); err == nil {
+			b.UnitDir = dir
+		}
+	}
+	return b
+}
+
+// WithTemplate installs the unit as a template (name@.service)
+func (b *BuilderSystemd) WithTemplate(template bool) *BuilderSystemd {
+	b.Template = template
+	return b
+}
+
+// UnitName returns the unit file name, name.service or name@.service for templates
+func (b *BuilderSystemd) UnitName() string {
+	if b.Template {
+		return fmt.Sprintf("%s@.service", b.config.Name)
+	}
+	return fmt.Sprintf("%s.service", b.config.Name)
+}
+
 // WithUnitDir sets the systemd unit directory
 func (b *BuilderSystemd) WithUnitDir(dir string) *BuilderSystemd {
 	b.UnitDir = dir
@@ -64,7 +105,11 @@ func (b *BuilderSystemd) BuildSystemdUnit() (string, error) {
 
 	// [Unit] section
 	unit.WriteString("[Unit]\n")
-	unit.WriteString(fmt.Sprintf("Description=%s service\n", c.Name))
+	if b.Template {
+		unit.WriteString(fmt.Sprintf("Description=%s service (%%i)\n", c.Name))
+	} else {
+		unit.WriteString(fmt.Sprintf("Description=%s service\n", c.Name))
+	}
 	unit.WriteString("After=network.target\n")
 
 	// Add documentation link if available
@@ -73,49 +118,21 @@ func (b *BuilderSystemd) BuildSystemdUnit() (string, error) {
 
 	// [Service] section
 	unit.WriteString("[Service]\n")
-	unit.WriteString("Type=simple\n")
-	unit.WriteString("Restart=always\n")
-	unit.WriteString("RestartSec=1\n")
+	if c.Schedule != nil {
+		// Scheduled jobs are started by the timer and run to completion
+		unit.WriteString("Type=oneshot\n")
+	} else {
+		unit.WriteString("Type=simple\n")
+		unit.WriteString("Restart=always\n")
+		unit.WriteString("RestartSec=1\n")
+	}
 	unit.WriteString("KillMode=mixed\n")
 	unit.WriteString("KillSignal=SIGTERM\n")
 	unit.WriteString("TimeoutStopSec=10\n")
 
-	// Map ChpstConfig fields to systemd directives
-	if c.Chpst != nil {
-		if c.Chpst.User != "" {
-			unit.WriteString(fmt.Sprintf("User=%s\n", c.Chpst.User))
-		}
-		if c.Chpst.Group != "" {
-			unit.WriteString(fmt.Sprintf("Group=%s\n", c.Chpst.Group))
-		}
-		if c.Chpst.Nice != 0 {
-			unit.WriteString(fmt.Sprintf("Nice=%d\n", c.Chpst.Nice))
-		}
-		if c.Chpst.IONice != 0 {
-			// Map IONice to IOSchedulingClass and IOSchedulingPriority
-			// IONice 1-3 = best-effort, 4-7 = idle
-			if c.Chpst.IONice <= 3 {
-				unit.WriteString("IOSchedulingClass=2\n") // best-effort
-				unit.WriteString(fmt.Sprintf("IOSchedulingPriority=%d\n", c.Chpst.IONice))
-			} else {
-				unit.WriteString("IOSchedulingClass=3\n") // idle
-			}
-		}
-		if c.Chpst.LimitMem > 0 {
-			unit.WriteString(fmt.Sprintf("MemoryLimit=%d\n", c.Chpst.LimitMem))
-		}
-		if c.Chpst.LimitFiles > 0 {
-			unit.WriteString(fmt.Sprintf("LimitNOFILE=%d\n", c.Chpst.LimitFiles))
-		}
-		if c.Chpst.LimitProcs > 0 {
-			unit.WriteString(fmt.Sprintf("LimitNPROC=%d\n", c.Chpst.LimitProcs))
-		}
-		if c.Chpst.LimitCPU > 0 {
-			unit.WriteString(fmt.Sprintf("LimitCPU=%d\n", c.Chpst.LimitCPU))
-		}
-		if c.Chpst.Root != "" {
-			unit.WriteString(fmt.Sprintf("RootDirectory=%s\n", c.Chpst.Root))
-		}
+	// Map ChpstConfig fields and mandatory access control to systemd directives
+	for _, d := range chpstDirectives(c) {
+		unit.WriteString(d + "\n")
 	}
 
 	// Working directory
@@ -183,9 +200,72 @@ func (b *BuilderSystemd) BuildSystemdUnit() (string, error) {
 		unit.WriteString("StandardError=journal\n")
 	}
 
+	// Scheduled jobs are installed through their timer
+	if c.Schedule == nil {
+		unit.WriteString("\n")
+		unit.WriteString("[Install]\n")
+		unit.WriteString(fmt.Sprintf("WantedBy=%s\n", b.installTarget()))
+	}
+
+	return unit.String(), nil
+}
+
+// installTarget returns the target units are installed into
+func (b *BuilderSystemd) installTarget() string {
+	if b.UserMode {
+		// The user manager has no multi-user.target
+		return "default.target"
+	}
+	return "multi-user.target"
+}
+
+// TimerName returns the timer unit file name for scheduled jobs,
+// name.timer or name@.timer for templates
+func (b *BuilderSystemd) TimerName() string {
+	return strings.TrimSuffix(b.UnitName(), ".service") + ".timer"
+}
+
+// BuildSystemdTimer generates the timer unit file content for a scheduled job
+func (b *BuilderSystemd) BuildSystemdTimer() (string, error) {
+	c := b.config
+	if c.Schedule == nil {
+		return "", fmt.Errorf("service %s has no schedule", c.Name)
+	}
+	schedule := *c.Schedule
+	if err := schedule.Validate(); err != nil {
+		return "", err
+	}
+
+	var unit strings.Builder
+
+	unit.WriteString("[Unit]\n")
+	unit.WriteString(fmt.Sprintf("Description=%s timer\n", c.Name))
+	unit.WriteString("# Managed by go-runit systemd adapter\n")
+	unit.WriteString("\n")
+
+	unit.WriteString("[Timer]\n")
+	if schedule.Every > 0 {
+		// Like the run script's sleep, runs are spaced from the end of the previous one
+		seconds := durationSeconds(schedule.Every)
+		unit.WriteString(fmt.Sprintf("OnActiveSec=%ds\n", seconds))
+		unit.WriteString(fmt.Sprintf("OnUnitInactiveSec=%ds\n", seconds))
+	} else {
+		calendar, err := schedule.OnCalendar()
+		if err != nil {
+			return "", err
+		}
+		unit.WriteString(fmt.Sprintf("OnCalendar=%s\n", calendar))
+		if schedule.Persistent {
+			unit.WriteString("Persistent=true\n")
+		}
+	}
+	if schedule.RandomDelay > 0 {
+		unit.WriteString(fmt.Sprintf("RandomizedDelaySec=%ds\n", durationSeconds(schedule.RandomDelay)))
+	}
+
 	unit.WriteString("\n")
 	unit.WriteString("[Install]\n")
-	unit.WriteString("WantedBy=multi-user.target\n")
+	unit.WriteString("WantedBy=timers.target\n")
 
 	return unit.String(), nil
 }
@@ -204,14 +284,24 @@ func (b *BuilderSystemd) BuildWithContext(ctx context.Context) error {
 	}
 
 	// Determine unit file path
-	unitName := fmt.Sprintf("%s.service", b.config.Name)
-	unitPath := filepath.Join(b.UnitDir, unitName)
+	unitPath := filepath.Join(b.UnitDir, b.UnitName())
 
 	// Write unit file
 	if err := b.writeUnitFile(ctx, unitPath, unitContent); err != nil {
 		return fmt.Errorf("writing unit file: %w", err)
 	}
 
+	if b.config.Schedule != nil {
+		timerContent, err := b.BuildSystemdTimer()
+		if err != nil {
+			return fmt.Errorf("generating timer file: %w", err)
+		}
+		timerPath := filepath.Join(b.UnitDir, b.TimerName())
+		if err := b.writeUnitFile(ctx, timerPath, timerContent); err != nil {
+			return fmt.Errorf("writing timer file: %w", err)
+		}
+	}
+
 	// Reload systemd
 	if err := b.reloadSystemd(ctx); err != nil {
 		return fmt.Errorf("reloading systemd: %w", err)
@@ -222,14 +312,21 @@ func (b *BuilderSystemd) BuildWithContext(ctx context.Context) error {
 
 // writeUnitFile writes the unit file, using sudo if necessary
 func (b *BuilderSystemd) writeUnitFile(ctx context.Context, path string, content string) error {
-	if !b.UseSudo {
+	if b.UserMode {
+		// The user unit directory often does not exist yet
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+	}
+	escalator := b.client().escalator()
+	if escalator == nil || b.UserMode {
 		// Direct write if we have permissions
 		return renameio.WriteFile(path, []byte(content), 0o644)
 	}
 
-	// Use sudo tee to write the file
+	// Use an elevated tee to write the file
 	// Equivalent to: echo "content" | sudo tee /path/to/file
-	cmd := exec.CommandContext(ctx, b.SudoCommand, "tee", path)
+	cmd := escalatedCommand(ctx, escalator, "tee", path)
 	cmd.Stdin = strings.NewReader(content)
 
 	// Capture output to avoid printing to stdout
@@ -238,21 +335,27 @@ func (b *BuilderSystemd) writeUnitFile(ctx context.Context, path string, content
 	cmd.Stderr = &out
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("sudo tee failed: %w (output: %s)", err, out.String())
+		return fmt.Errorf("elevated tee failed: %w (output: %s)", err, out.String())
 	}
 
 	return nil
 }
 
+// client returns a ClientSystemd sharing the builder's privilege and manager settings
+func (b *BuilderSystemd) client() *ClientSystemd {
+	return &ClientSystemd{
+		ServiceName:   b.config.Name,
+		UseSudo:       b.UseSudo,
+		SudoCommand:   b.SudoCommand,
+		Escalator:     b.Escalator,
+		SystemctlPath: b.SystemctlPath,
+		UserMode:      b.UserMode,
+	}
+}
+
 // reloadSystemd runs systemctl daemon-reload
 func (b *BuilderSystemd) reloadSystemd(ctx context.Context) error {
-	var cmd *exec.Cmd
-
-	if b.UseSudo {
-		cmd = exec.CommandContext(ctx, b.SudoCommand, b.SystemctlPath, "daemon-reload")
-	} else {
-		cmd = exec.CommandContext(ctx, b.SystemctlPath, "daemon-reload")
-	}
+	cmd := b.client().systemctlCmd(ctx, "daemon-reload")
 
 	var out bytes.Buffer
 	cmd.Stdout = &out
@@ -265,16 +368,34 @@ func (b *BuilderSystemd) reloadSystemd(ctx context.Context) error {
 	return nil
 }
 
-// Enable enables the systemd service to start on boot
+// Enable enables the systemd service to start on boot, or the timer of a
+// scheduled job. Templates cannot be enabled directly; use EnableInstance.
 func (b *BuilderSystemd) Enable(ctx context.Context) error {
-	serviceName := fmt.Sprintf("%s.service", b.config.Name)
+	if b.Template {
+		return fmt.Errorf("template unit %s must be enabled per instance", b.UnitName())
+	}
+	if b.config.Schedule != nil {
+		return b.enable(ctx, b.TimerName())
+	}
+	return b.enable(ctx, b.UnitName())
+}
 
-	var cmd *exec.Cmd
-	if b.UseSudo {
-		cmd = exec.CommandContext(ctx, b.SudoCommand, b.SystemctlPath, "enable", serviceName)
-	} else {
-		cmd = exec.CommandContext(ctx, b.SystemctlPath, "enable", serviceName)
+// EnableInstance enables one instance (name@instance.service) of a template
+// unit, or its timer (name@instance.timer) for a scheduled job
+func (b *BuilderSystemd) EnableInstance(ctx context.Context, instance string) error {
+	if !b.Template {
+		return fmt.Errorf("unit %s is not a template", b.UnitName())
+	}
+	if b.config.Schedule != nil {
+		return b.enable(ctx, InstanceUnitName(b.config.Name, instance)+".timer")
 	}
+	return b.enable(ctx, InstanceUnitName(b.config.Name, instance)+".service")
+}
+
+// enable runs systemctl enable for the named unit
+func (b *BuilderSystemd) enable(ctx context.Context, serviceName string) error {
+
+	cmd := b.client().systemctlCmd(ctx, "enable", serviceName)
 
 	var out bytes.Buffer
 	cmd.Stdout = &out
@@ -289,36 +410,34 @@ func (b *BuilderSystemd) Enable(ctx context.Context) error {
 
 // Remove removes the systemd unit file
 func (b *BuilderSystemd) Remove(ctx context.Context) error {
-	serviceName := fmt.Sprintf("%s.service", b.config.Name)
+	serviceName := b.UnitName()
 	unitPath := filepath.Join(b.UnitDir, serviceName)
 
 	// Stop and disable the service first
-	client := &ClientSystemd{
-		ServiceName:   b.config.Name,
-		UseSudo:       b.UseSudo,
-		SudoCommand:   b.SudoCommand,
-		SystemctlPath: b.SystemctlPath,
-	}
+	client := b.client()
 
 	// Stop the service (ignore errors if it's not running)
-	_ = client.Stop(ctx)
-
-	// Disable the service (ignore errors if it's not enabled)
-	var cmd *exec.Cmd
-	if b.UseSudo {
-		cmd = exec.CommandContext(ctx, b.SudoCommand, b.SystemctlPath, "disable", serviceName)
+	if b.Template {
+		_ = (&TemplateSystemd{Template: b.config.Name, Client: client}).DownAll(ctx)
 	} else {
-		cmd = exec.CommandContext(ctx, b.SystemctlPath, "disable", serviceName)
+		_ = client.Stop(ctx)
 	}
+
+	// Disable the service (ignore errors if it's not enabled)
+	cmd := client.systemctlCmd(ctx, "disable", serviceName)
 	_ = cmd.Run()
 
-	// Remove the unit file
-	if b.UseSudo {
-		cmd = exec.CommandContext(ctx, b.SudoCommand, "rm", "-f", unitPath)
-	} else {
-		cmd = exec.CommandContext(ctx, "rm", "-f", unitPath)
+	files := []string{unitPath}
+	if b.config.Schedule != nil {
+		// Stop and disable the timer so it no longer triggers the job
+		timerName := b.TimerName()
+		_ = client.systemctlCmd(ctx, "disable", "--now", timerName).Run()
+		files = append(files, filepath.Join(b.UnitDir, timerName))
 	}
 
+	// Remove the unit files
+	cmd = client.command(ctx, "rm", append([]string{"-f"}, files...)...)
+
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("removing unit file: %w", err)
 	}
@@ -326,3 +445,51 @@ func (b *BuilderSystemd) Remove(ctx context.Context) error {
 	// Reload systemd
 	return b.reloadSystemd(ctx)
 }
+
+// chpstDirectives maps the chpst settings and mandatory access control of
+// c to systemd [Service] directives, as "Key=value"
+func chpstDirectives(c *ServiceBuilderConfig) []string {
+	var d []string
+	if c.Chpst != nil {
+		if c.Chpst.User != "" {
+			d = append(d, "User="+c.Chpst.User)
+		}
+		if c.Chpst.Group != "" {
+			d = append(d, "Group="+c.Chpst.Group)
+		}
+		if c.Chpst.Nice != 0 {
+			d = append(d, fmt.Sprintf("Nice=%d", c.Chpst.Nice))
+		}
+		if c.Chpst.IONice != 0 {
+			// Map IONice to IOSchedulingClass and IOSchedulingPriority
+			// IONice 1-3 = best-effort, 4-7 = idle
+			if c.Chpst.IONice <= 3 {
+				d = append(d, "IOSchedulingClass=2", fmt.Sprintf("IOSchedulingPriority=%d", c.Chpst.IONice))
+			} else {
+				d = append(d, "IOSchedulingClass=3")
+			}
+		}
+		if c.Chpst.LimitMem > 0 {
+			d = append(d, fmt.Sprintf("MemoryLimit=%d", c.Chpst.LimitMem))
+		}
+		if c.Chpst.LimitFiles > 0 {
+			d = append(d, fmt.Sprintf("LimitNOFILE=%d", c.Chpst.LimitFiles))
+		}
+		if c.Chpst.LimitProcs > 0 {
+			d = append(d, fmt.Sprintf("LimitNPROC=%d", c.Chpst.LimitProcs))
+		}
+		if c.Chpst.LimitCPU > 0 {
+			d = append(d, fmt.Sprintf("LimitCPU=%d", c.Chpst.LimitCPU))
+		}
+		if c.Chpst.Root != "" {
+			d = append(d, "RootDirectory="+c.Chpst.Root)
+		}
+	}
+	if c.SELinuxContext != "" {
+		d = append(d, "SELinuxContext="+c.SELinuxContext)
+	}
+	if c.AppArmorProfile != "" {
+		d = append(d, "AppArmorProfile="+c.AppArmorProfile)
+	}
+	return d
+}
@@ -0,0 +1,85 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilderSystemdSchedule(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  Schedule
+		wantTimer []string
+	}{
+		{
+			name:      "calendar",
+			schedule:  Schedule{Minute: "30", Hour: "2", Weekday: "1-5", Persistent: true},
+			wantTimer: []string{"OnCalendar=Mon..Fri *-*-* 02:30:00", "Persistent=true", "WantedBy=timers.target"},
+		},
+		{
+			name:      "interval",
+			schedule:  Schedule{Every: 10 * time.Minute, RandomDelay: 30 * time.Second},
+			wantTimer: []string{"OnActiveSec=600s", "OnUnitInactiveSec=600s", "RandomizedDelaySec=30s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := ServiceBuilderSystemd("backup", "/tmp")
+			b.WithCmd([]string{"/bin/backup"}).WithSchedule(tt.schedule)
+
+			if b.TimerName() != "backup.timer" {
+				t.Errorf("TimerName() = %q", b.TimerName())
+			}
+
+			unit, err := b.BuildSystemdUnit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(unit, "Type=oneshot") {
+				t.Errorf("unit is not oneshot:\n%s", unit)
+			}
+			for _, unwanted := range []string{"Restart=", "[Install]"} {
+				if strings.Contains(unit, unwanted) {
+					t.Errorf("scheduled unit contains %q:\n%s", unwanted, unit)
+				}
+			}
+
+			timer, err := b.BuildSystemdTimer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantTimer {
+				if !strings.Contains(timer, want+"\n") {
+					t.Errorf("timer missing %q:\n%s", want, timer)
+				}
+			}
+		})
+	}
+
+	b := ServiceBuilderSystemd("web", "/tmp")
+	b.WithCmd([]string{"/bin/web"})
+	if _, err := b.BuildSystemdTimer(); err == nil {
+		t.Error("BuildSystemdTimer without a schedule should fail")
+	}
+}
+
+func TestBuilderSystemdMandatoryAccessControl(t *testing.T) {
+	b := ServiceBuilderSystemd("web", "/tmp")
+	b.WithCmd([]string{"/bin/web"}).
+		WithSELinuxContext("system_u:system_r:httpd_t:s0").
+		WithAppArmorProfile("web")
+
+	unit, err := b.BuildSystemdUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"SELinuxContext=system_u:system_r:httpd_t:s0\n", "AppArmorProfile=web\n"} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// systemdStaticProperties are the unit properties that only change when the
+// unit file is edited, kept by ClientSystemd.CacheProperties
+var systemdStaticProperties = []string{
+	"Id", "Names", "Description", "FragmentPath", "DropInPaths", "Type",
+	"ExecStart", "ExecReload", "ExecStop", "User", "Group", "WorkingDirectory",
+	"Restart",
+}
+
+// systemdDynamicProperties are refreshed on every StatusSystemd call while
+// static properties are cached: the ones StatusSystemd decodes, plus
+// NeedDaemonReload to notice edited unit files
+var systemdDynamicProperties = []string{
+	"LoadState", "ActiveState", "SubState", "MainPID", "Result", "ControlGroup",
+	"ExecMainStartTimestampMonotonic", "NeedDaemonReload",
+}
+
+// propertyCache holds static properties by unit name. Clients derived by
+// copying a ClientSystemd for another unit share it.
+type propertyCache struct {
+	mu    sync.Mutex
+	units map[string]cachedUnit
+}
+
+// cachedUnit is the cache entry of one unit
+type cachedUnit struct {
+	props map[string]string
+	// modTime is the unit file's modification time when cached
+	modTime time.Time
+}
+
+// newPropertyCache returns an empty propertyCache
+func newPropertyCache() *propertyCache {
+	return &propertyCache{units: make(map[string]cachedUnit)}
+}
+
+// InvalidatePropertyCache drops the cached static properties, for callers
+// that edited the unit and reloaded systemd; the next StatusSystemd reads
+// every property again
+func (c *ClientSystemd) InvalidatePropertyCache() {
+	if c.cache == nil {
+		return
+	}
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	delete(c.cache.units, c.ServiceName)
+}
+
+// cachedStatusSystemd returns the status from the dynamic properties and
+// the cached static ones, or nil when nothing is cached or the cache is
+// stale: systemd reports a pending reload or the unit file was modified
+func (c *ClientSystemd) cachedStatusSystemd(ctx context.Context) (*StatusSystemd, error) {
+	static, ok := c.cachedProperties()
+	if !ok {
+		return nil, nil
+	}
+
+	output, err := c.execSystemctl(ctx, "show", "--no-pager", "-p", strings.Join(systemdDynamicProperties, ","))
+	if err != nil {
+		return nil, err
+	}
+	status := c.parseStatusSystemd(output)
+	if status.Properties["NeedDaemonReload"] == "yes" {
+		c.InvalidatePropertyCache()
+		return nil, nil
+	}
+	for key, value := range static {
+		if _, ok := status.Properties[key]; !ok {
+			status.Properties[key] = value
+		}
+	}
+	return status, nil
+}
+
+// cachedProperties returns the cached static properties, dropping them
+// when the unit file changed since they were read
+func (c *ClientSystemd) cachedProperties() (map[string]string, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	entry, ok := c.cache.units[c.ServiceName]
+	if !ok {
+		return nil, false
+	}
+	if path := entry.props["FragmentPath"]; path != "" {
+		if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(entry.modTime) {
+			delete(c.cache.units, c.ServiceName)
+			return nil, false
+		}
+	}
+	return entry.props, true
+}
+
+// cacheProperties keeps the static properties of a full systemctl show
+func (c *ClientSystemd) cacheProperties(props map[string]string) {
+	if c.cache == nil {
+		return
+	}
+	entry := cachedUnit{props: make(map[string]string, len(systemdStaticProperties))}
+	for _, key := range systemdStaticProperties {
+		if value, ok := props[key]; ok {
+			entry.props[key] = value
+		}
+	}
+	if path := entry.props["FragmentPath"]; path != "" {
+		if info, err := os.Stat(path); err == nil {
+			entry.modTime = info.ModTime()
+		}
+	}
+
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	c.cache.units[c.ServiceName] = entry
+}
+
+// cachedProperty returns a cached static property
+func (c *ClientSystemd) cachedProperty(key string) (string, bool) {
+	if !c.CacheProperties {
+		return "", false
+	}
+	props, ok := c.cachedProperties()
+	if !ok {
+		return "", false
+	}
+	value, ok := props[key]
+	return value, ok
+}
@@ -0,0 +1,127 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientSystemdCacheProperties(t *testing.T) {
+	dir := t.TempDir()
+	unitFile := filepath.Join(dir, "web.service")
+	if err := os.WriteFile(unitFile, []byte("[Service]\nExecStart=/bin/web\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reload := filepath.Join(dir, "reload")
+	if err := os.WriteFile(reload, []byte("no"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Full output without -p, dynamic properties only with it
+	calls := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+case "$*" in
+*" -p "*)
+	echo ActiveState=active
+	echo SubState=running
+	echo MainPID=77
+	echo NeedDaemonReload=$(cat ` + reload + `)
+	;;
+*)
+	echo Id=web.service
+	echo FragmentPath=` + unitFile + `
+	echo Type=simple
+	echo "ExecStart={ path=/bin/web ; argv[]=/bin/web ; }"
+	echo ActiveState=active
+	echo SubState=running
+	echo MainPID=76
+	echo MemoryCurrent=1024
+	;;
+esac
+`
+	systemctl := filepath.Join(dir, "systemctl")
+	if err := os.WriteFile(systemctl, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClientSystemd("web")
+	c.UseSudo = false
+	c.SystemctlPath = systemctl
+	c.CacheProperties = true
+
+	// status reads the status and reports whether it ended with a query of
+	// all properties
+	status := func() (*StatusSystemd, bool) {
+		t.Helper()
+		_ = os.Remove(calls)
+		st, err := c.StatusSystemd(context.Background())
+		if err != nil {
+			t.Fatalf("StatusSystemd: %v", err)
+		}
+		data, err := os.ReadFile(calls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		return st, !strings.Contains(lines[len(lines)-1], " -p ")
+	}
+
+	if st, full := status(); !full || st.MainPID != 76 {
+		t.Fatalf("first call: full %v pid %d, want a full query", full, st.MainPID)
+	}
+
+	st, full := status()
+	if full {
+		t.Fatal("second call queried all properties")
+	}
+	if st.MainPID != 77 || st.Properties["Type"] != "simple" || st.Properties["ExecStart"] == "" {
+		t.Errorf("cached status = pid %d, properties %v", st.MainPID, st.Properties)
+	}
+	if _, ok := st.Properties["MemoryCurrent"]; ok {
+		t.Error("cached status kept a dynamic property from the full query")
+	}
+	if v, ok := c.cachedProperty("ExecStart"); !ok || !strings.Contains(v, "/bin/web") {
+		t.Errorf("cachedProperty(ExecStart) = %q, %v", v, ok)
+	}
+
+	// Editing the unit file invalidates the cache
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(unitFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, full := status(); !full {
+		t.Error("unit file change did not invalidate the cache")
+	}
+	if _, full := status(); full {
+		t.Error("cache not refilled after invalidation")
+	}
+
+	// A pending daemon-reload invalidates the cache
+	if err := os.WriteFile(reload, []byte("yes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, full := status(); !full {
+		t.Error("NeedDaemonReload did not invalidate the cache")
+	}
+	if err := os.WriteFile(reload, []byte("no"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c.InvalidatePropertyCache()
+	if _, full := status(); !full {
+		t.Error("InvalidatePropertyCache did not invalidate the cache")
+	}
+
+	// Instances derived by copying do not see another unit's cache
+	other := *c
+	other.ServiceName = "db"
+	if _, ok := other.cachedProperty("ExecStart"); ok {
+		t.Error("copied client for another unit used web's cache")
+	}
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCgroupRoot is the mount point of the unified (v2) cgroup hierarchy
+const DefaultCgroupRoot = "/sys/fs/cgroup"
+
+// CgroupStats holds resource usage of a unit's cgroup, read directly from
+// the cgroup v2 interface files
+type CgroupStats struct {
+	// Path is the cgroup directory the statistics were read from
+	Path string
+
+	// MemoryCurrent is memory.current, the memory charged to the cgroup in bytes
+	MemoryCurrent uint64
+
+	// MemoryPeak is memory.peak in bytes; zero when the kernel does not provide it
+	MemoryPeak uint64
+
+	// CPUUsage is the total CPU time (cpu.stat usage_usec)
+	CPUUsage time.Duration
+
+	// CPUUser is the user-mode CPU time (cpu.stat user_usec)
+	CPUUser time.Duration
+
+	// CPUSystem is the kernel-mode CPU time (cpu.stat system_usec)
+	CPUSystem time.Duration
+
+	// Tasks is pids.current, the number of tasks in the cgroup; zero when
+	// the pids controller is not enabled
+	Tasks int
+}
+
+// ReadCgroupStats reads memory and CPU statistics for the cgroup at
+// cgroupPath (as reported by the ControlGroup property) below cgroupRoot
+func ReadCgroupStats(cgroupRoot, cgroupPath string) (*CgroupStats, error) {
+	dir := filepath.Join(cgroupRoot, filepath.Clean("/"+cgroupPath))
+	stats := &CgroupStats{Path: dir}
+
+	memory, err := readCgroupUint(dir, "memory.current")
+	if err != nil {
+		return nil, err
+	}
+	stats.MemoryCurrent = memory
+
+	if peak, err := readCgroupUint(dir, "memory.peak"); err == nil {
+		stats.MemoryPeak = peak
+	}
+	if tasks, err := readCgroupUint(dir, "pids.current"); err == nil {
+		stats.Tasks = int(tasks)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		usec, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s/cpu.stat %s: %w", ErrDecode, dir, key, err)
+		}
+		switch key {
+		case "usage_usec":
+			stats.CPUUsage = time.Duration(usec) * time.Microsecond
+		case "user_usec":
+			stats.CPUUser = time.Duration(usec) * time.Microsecond
+		case "system_usec":
+			stats.CPUSystem = time.Duration(usec) * time.Microsecond
+		}
+	}
+
+	return stats, nil
+}
+
+// readCgroupUint reads a single-value cgroup interface file
+func readCgroupUint(dir, name string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s/%s: %w", ErrDecode, dir, name, err)
+	}
+	return value, nil
+}
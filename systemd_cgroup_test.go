@@ -0,0 +1,78 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFakeCgroup(t *testing.T, root, path string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadCgroupStats(t *testing.T) {
+	root := t.TempDir()
+	writeFakeCgroup(t, root, "system.slice/web.service", map[string]string{
+		"memory.current": "1048576\n",
+		"memory.peak":    "2097152\n",
+		"pids.current":   "4\n",
+		"cpu.stat":       "usage_usec 3000000\nuser_usec 2000000\nsystem_usec 1000000\nnr_periods 0\n",
+	})
+
+	stats, err := ReadCgroupStats(root, "/system.slice/web.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CgroupStats{
+		Path:          filepath.Join(root, "system.slice/web.service"),
+		MemoryCurrent: 1 << 20,
+		MemoryPeak:    2 << 20,
+		CPUUsage:      3 * time.Second,
+		CPUUser:       2 * time.Second,
+		CPUSystem:     time.Second,
+		Tasks:         4,
+	}
+	if *stats != want {
+		t.Errorf("ReadCgroupStats() = %+v, want %+v", *stats, want)
+	}
+}
+
+func TestReadCgroupStatsOptionalFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFakeCgroup(t, root, "system.slice/db.service", map[string]string{
+		"memory.current": "4096",
+		"cpu.stat":       "usage_usec 10\n",
+	})
+
+	stats, err := ReadCgroupStats(root, "/system.slice/db.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MemoryPeak != 0 || stats.Tasks != 0 || stats.CPUUsage != 10*time.Microsecond {
+		t.Errorf("ReadCgroupStats() = %+v", *stats)
+	}
+
+	// A cgroup v1 host has no memory.current at the unit path
+	if _, err := ReadCgroupStats(root, "/system.slice/missing.service"); !os.IsNotExist(err) {
+		t.Errorf("missing cgroup: err = %v, want not-exist", err)
+	}
+
+	// The cgroup path never escapes the root
+	writeFakeCgroup(t, root, "x", map[string]string{"memory.current": "1", "cpu.stat": ""})
+	stats, err = ReadCgroupStats(filepath.Join(root, "system.slice"), "/../x")
+	if err == nil {
+		t.Errorf("escaped cgroup root: %+v", *stats)
+	}
+}
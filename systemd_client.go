@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -32,25 +33,95 @@ type ClientSystemd struct {
 	// SudoCommand is the sudo command to use (default: "sudo")
 	SudoCommand string
 
+	// Escalator, when set, elevates privileged commands instead of UseSudo
+	// and SudoCommand (e.g. Doas, Run0 or Polkit)
+	Escalator Escalator
+
 	// SystemctlPath is the path to systemctl binary
 	SystemctlPath string
 
-	// Timeout for systemctl operations
+	// Timeout bounds systemctl operations whose context has no deadline
 	Timeout time.Duration
 
-	// WatchInterval is the polling interval for Watch when other methods unavailable
+	// WatchInterval is the polling interval for Watch when D-Bus monitoring
+	// is unavailable
 	WatchInterval time.Duration
+
+	// WatchBuffer is the capacity of Watch channels; zero means
+	// DefaultWatchBuffer
+	WatchBuffer int
+
+	// WatchOverflow is what Watch does when its channel is full
+	WatchOverflow WatchOverflow
+
+	// UserMode targets the calling user's service manager (systemctl --user)
+	// instead of the system manager. Sudo is never used in user mode.
+	UserMode bool
+
+	// Clock computes Uptime; nil uses the wall clock
+	Clock Clock
+
+	// CgroupRoot is the cgroup v2 mount point used for StatusSystemd.Cgroup
+	CgroupRoot string
+
+	// PortablectlPath is the path to portablectl, used for portable service images
+	PortablectlPath string
+
+	// BusctlPath is the path to busctl, used by Watch to follow unit
+	// property changes on D-Bus
+	BusctlPath string
+
+	// SystemdRunPath is the path to systemd-run, used for transient units
+	SystemdRunPath string
+
+	// CacheProperties keeps static unit properties such as FragmentPath,
+	// ExecStart and Type across StatusSystemd calls, which then only query
+	// the dynamic ones. The cache is dropped when systemd reports a pending
+	// reload, when the unit file's mtime changes, and by
+	// InvalidatePropertyCache. Properties then holds only the static and
+	// dynamic properties.
+	CacheProperties bool
+
+	// KillWho selects the processes signal methods (Term, HUP, ...) reach.
+	// Empty signals MainPID directly; otherwise signals go through
+	// systemctl kill --kill-who.
+	KillWho KillWho
+
+	// Instrumentation, when set, observes the systemctl commands behind
+	// control operations and status reads (see WithInstrumentation)
+	Instrumentation Instrumentation
+
+	// cache holds the static properties when CacheProperties is set
+	cache *propertyCache
 }
 
+// KillWho selects the processes of a unit that systemctl kill signals
+type KillWho string
+
+// KillWho values, as accepted by systemctl kill --kill-who
+const (
+	// KillWhoMain signals the main process
+	KillWhoMain KillWho = "main"
+	// KillWhoControl signals the control process, e.g. a running ExecReload
+	KillWhoControl KillWho = "control"
+	// KillWhoAll signals every process in the unit's cgroup
+	KillWhoAll KillWho = "all"
+)
+
 // NewClientSystemd creates a new ClientSystemd for the specified service
 func NewClientSystemd(serviceName string) *ClientSystemd {
 	return &ClientSystemd{
-		ServiceName:   serviceName,
-		UseSudo:       os.Geteuid() != 0,
-		SudoCommand:   "sudo",
-		SystemctlPath: "systemctl",
-		Timeout:       10 * time.Second,
-		WatchInterval: 1 * time.Second,
+		ServiceName:     serviceName,
+		UseSudo:         os.Geteuid() != 0,
+		SudoCommand:     "sudo",
+		SystemctlPath:   "systemctl",
+		Timeout:         10 * time.Second,
+		WatchInterval:   1 * time.Second,
+		CgroupRoot:      DefaultCgroupRoot,
+		PortablectlPath: DefaultPortablectlPath,
+		BusctlPath:      DefaultBusctlPath,
+		SystemdRunPath:  DefaultSystemdRunPath,
+		cache:           newPropertyCache(),
 	}
 }
 
@@ -63,6 +134,28 @@ func (c *ClientSystemd) WithSudo(use bool, command string) *ClientSystemd {
 	return c
 }
 
+// WithEscalator elevates privileged commands through e instead of sudo
+func (c *ClientSystemd) WithEscalator(e Escalator) *ClientSystemd {
+	c.Escalator = e
+	return c
+}
+
+// WithUserMode targets the per-user service manager (systemctl --user)
+func (c *ClientSystemd) WithUserMode(user bool) *ClientSystemd {
+	c.UserMode = user
+	if user {
+		c.UseSudo = false
+	}
+	return c
+}
+
+// WithKillWho sends signals through systemctl kill --kill-who=who instead
+// of to MainPID directly
+func (c *ClientSystemd) WithKillWho(who KillWho) *ClientSystemd {
+	c.KillWho = who
+	return c
+}
+
 // WithTimeout sets the timeout for operations
 func (c *ClientSystemd) WithTimeout(d time.Duration) *ClientSystemd {
 	c.Timeout = d
@@ -71,19 +164,15 @@ func (c *ClientSystemd) WithTimeout(d time.Duration) *ClientSystemd {
 
 // execSystemctl executes a systemctl command with optional sudo
 func (c *ClientSystemd) execSystemctl(ctx context.Context, args ...string) (string, error) {
-	var cmd *exec.Cmd
+	ctx, cancel := withDefaultTimeout(ctx, c.Timeout)
+	defer cancel()
 
 	serviceName := fmt.Sprintf("%s.service", c.ServiceName)
 	fullArgs := make([]string, len(args))
 	copy(fullArgs, args)
 	fullArgs = append(fullArgs, serviceName)
 
-	if c.UseSudo {
-		sudoArgs := append([]string{c.SystemctlPath}, fullArgs...)
-		cmd = exec.CommandContext(ctx, c.SudoCommand, sudoArgs...)
-	} else {
-		cmd = exec.CommandContext(ctx, c.SystemctlPath, fullArgs...)
-	}
+	cmd := c.systemctlCmd(ctx, fullArgs...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -97,9 +186,46 @@ func (c *ClientSystemd) execSystemctl(ctx context.Context, args ...string) (stri
 	return stdout.String(), nil
 }
 
+// systemctlCmd builds a systemctl command for the system or user manager
+func (c *ClientSystemd) systemctlCmd(ctx context.Context, args ...string) *exec.Cmd {
+	if c.UserMode {
+		args = append([]string{"--user"}, args...)
+	}
+	return c.command(ctx, c.SystemctlPath, args...)
+}
+
+// command builds a command that runs elevated when configured.
+// User-mode commands run as the caller with the user manager's bus in the environment.
+func (c *ClientSystemd) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if c.UserMode {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Env = userManagerEnv(os.Environ())
+		return cmd
+	}
+	return escalatedCommand(ctx, c.escalator(), name, args...)
+}
+
+// escalator returns the configured Escalator, falling back to sudo when
+// UseSudo is set, or nil when commands run unelevated
+func (c *ClientSystemd) escalator() Escalator {
+	if c.Escalator != nil {
+		return c.Escalator
+	}
+	if c.UseSudo {
+		return Sudo{Path: c.SudoCommand}
+	}
+	return nil
+}
+
+// observe reports op on the unit, started at start, to Instrumentation
+func (c *ClientSystemd) observe(ctx context.Context, op Operation, start time.Time, err error) {
+	observeOperation(ctx, c.Instrumentation, c.ServiceName, op, start, 0, err)
+}
+
 // Up starts the service (sets want up)
-func (c *ClientSystemd) Up(ctx context.Context) error {
-	_, err := c.execSystemctl(ctx, "start")
+func (c *ClientSystemd) Up(ctx context.Context) (err error) {
+	defer func(start time.Time) { c.observe(ctx, OpUp, start, err) }(time.Now())
+	_, err = c.execSystemctl(ctx, "start")
 	return err
 }
 
@@ -109,8 +235,9 @@ func (c *ClientSystemd) Start(ctx context.Context) error {
 }
 
 // Down stops the service (sets want down)
-func (c *ClientSystemd) Down(ctx context.Context) error {
-	_, err := c.execSystemctl(ctx, "stop")
+func (c *ClientSystemd) Down(ctx context.Context) (err error) {
+	defer func(start time.Time) { c.observe(ctx, OpDown, start, err) }(time.Now())
+	_, err = c.execSystemctl(ctx, "stop")
 	return err
 }
 
@@ -120,8 +247,9 @@ func (c *ClientSystemd) Stop(ctx context.Context) error {
 }
 
 // Restart restarts the service
-func (c *ClientSystemd) Restart(ctx context.Context) error {
-	_, err := c.execSystemctl(ctx, "restart")
+func (c *ClientSystemd) Restart(ctx context.Context) (err error) {
+	defer func(start time.Time) { c.observe(ctx, OpRestart, start, err) }(time.Now())
+	_, err = c.execSystemctl(ctx, "restart")
 	return err
 }
 
@@ -129,8 +257,10 @@ func (c *ClientSystemd) Restart(ctx context.Context) error {
 // This uses the service's ExecReload= configuration if defined.
 // If the service doesn't support reload, this will return an error.
 // Note: This is NOT the same as sending SIGHUP - use HUP() for that.
-func (c *ClientSystemd) Reload(ctx context.Context) error {
-	_, err := c.execSystemctl(ctx, "reload")
+// It is reported to Instrumentation as OpHUP.
+func (c *ClientSystemd) Reload(ctx context.Context) (err error) {
+	defer func(start time.Time) { c.observe(ctx, OpHUP, start, err) }(time.Now())
+	_, err = c.execSystemctl(ctx, "reload")
 	return err
 }
 
@@ -169,13 +299,107 @@ func (c *ClientSystemd) USR2(ctx context.Context) error {
 }
 
 // StatusSystemd returns the systemd-specific status of the service
-func (c *ClientSystemd) StatusSystemd(ctx context.Context) (*StatusSystemd, error) {
+func (c *ClientSystemd) StatusSystemd(ctx context.Context) (_ *StatusSystemd, err error) {
+	defer func(start time.Time) { c.observe(ctx, OpStatus, start, err) }(time.Now())
+
+	if c.CacheProperties {
+		status, err := c.cachedStatusSystemd(ctx)
+		if status != nil || err != nil {
+			return status, err
+		}
+	}
+
 	// Get basic status
 	output, err := c.execSystemctl(ctx, "show", "--no-page")
 	if err != nil {
 		return nil, err
 	}
+	status := c.parseStatusSystemd(output)
+	if c.CacheProperties {
+		c.cacheProperties(status.Properties)
+	}
+	return status, nil
+}
+
+// StatusSystemdMany returns the systemd status of each of units, keyed by
+// the names as given, from a single systemctl show instead of one exec per
+// unit. Names without a unit type suffix are taken as services. Units that
+// do not exist are reported with LoadState "not-found".
+func (c *ClientSystemd) StatusSystemdMany(ctx context.Context, units ...string) (map[string]*StatusSystemd, error) {
+	results := make(map[string]*StatusSystemd, len(units))
+	if len(units) == 0 {
+		return results, nil
+	}
+
+	blocks, err := c.showMany(ctx, nil, units)
+	if err != nil {
+		return nil, err
+	}
+	for i, block := range blocks {
+		results[units[i]] = c.parseStatusSystemd(block)
+	}
+	return results, nil
+}
+
+// showMany runs a single systemctl show for units, limited to props when
+// given, and returns the output block of each unit in order
+func (c *ClientSystemd) showMany(ctx context.Context, props []string, units []string) ([]string, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	args := []string{"show", "--no-pager"}
+	if len(props) > 0 {
+		args = append(args, "-p", strings.Join(props, ","))
+	}
+	for _, unit := range units {
+		args = append(args, fullUnitName(unit))
+	}
+	cmd := c.systemctlCmd(ctx, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w (stderr: %s)", err, stderr.String())
+	}
+
+	// Units are printed in the order requested, separated by blank lines
+	blocks := strings.Split(strings.TrimSpace(stdout.String()), "\n\n")
+	if len(blocks) != len(units) {
+		return nil, fmt.Errorf("%w: systemctl show printed %d units, want %d", ErrDecode, len(blocks), len(units))
+	}
+	return blocks, nil
+}
+
+// StatusMany returns the status of each of units in runit format, read with
+// a single systemctl show (see StatusSystemdMany)
+func (c *ClientSystemd) StatusMany(ctx context.Context, units ...string) (map[string]Status, error) {
+	statuses, err := c.StatusSystemdMany(ctx, units...)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]Status, len(statuses))
+	for unit, st := range statuses {
+		results[unit] = *st.MapToStatus()
+	}
+	return results, nil
+}
+
+// unitTypes are the systemd unit type suffixes
+var unitTypes = []string{
+	".service", ".socket", ".timer", ".target", ".mount", ".automount",
+	".path", ".scope", ".slice", ".device", ".swap",
+}
+
+// fullUnitName appends ".service" to a name without a unit type suffix
+func fullUnitName(name string) string {
+	if slices.ContainsFunc(unitTypes, func(suffix string) bool { return strings.HasSuffix(name, suffix) }) {
+		return name
+	}
+	return name + ".service"
+}
 
+// parseStatusSystemd parses the key=value output of systemctl show for one unit
+func (c *ClientSystemd) parseStatusSystemd(output string) *StatusSystemd {
 	status := &StatusSystemd{
 		Properties: make(map[string]string),
 	}
@@ -207,6 +431,8 @@ func (c *ClientSystemd) StatusSystemd(ctx context.Context) (*StatusSystemd, erro
 				}
 			case "Result":
 				status.Result = value
+			case "ControlGroup":
+				status.ControlGroup = value
 			}
 		}
 	}
@@ -216,10 +442,18 @@ func (c *ClientSystemd) StatusSystemd(ctx context.Context) (*StatusSystemd, erro
 
 	// Calculate uptime if running
 	if status.Running && !status.StartTime.IsZero() {
-		status.Uptime = time.Since(status.StartTime)
+		status.Uptime = clockNow(c.Clock).Sub(status.StartTime)
 	}
 
-	return status, nil
+	// Resource usage is read from the cgroup directly; it is unavailable
+	// on cgroup v1 hosts and for units without a cgroup
+	if status.ControlGroup != "" && c.CgroupRoot != "" {
+		if cg, err := ReadCgroupStats(c.CgroupRoot, status.ControlGroup); err == nil {
+			status.Cgroup = cg
+		}
+	}
+
+	return status
 }
 
 // IsRunning checks if the service is currently running
@@ -276,6 +510,12 @@ type StatusSystemd struct {
 	// Result is the result of the last run (success, exit-code, signal, etc.)
 	Result string
 
+	// ControlGroup is the unit's cgroup path relative to the cgroup root
+	ControlGroup string
+
+	// Cgroup holds resource usage of the unit's cgroup; nil when unavailable
+	Cgroup *CgroupStats
+
 	// Properties contains all properties returned by systemctl show
 	Properties map[string]string
 }
@@ -305,6 +545,26 @@ func (s *StatusSystemd) MapToStatus() *Status {
 		if s.SubState == "running" {
 			status.State = StateRunning
 		}
+	case "reloading":
+		// The process keeps running while it reloads its configuration
+		status.State = StateRunning
+		status.Flags.WantUp = true
+	case "activating":
+		status.Flags.WantUp = true
+		if s.SubState == "auto-restart" {
+			// Waiting out RestartSec after the process exited
+			status.State = StateCrashed
+		} else {
+			status.State = StateStarting
+		}
+	case "deactivating":
+		status.Flags.WantDown = true
+		if s.SubState == "stop-post" {
+			// ExecStopPost, which the builder writes for finish commands
+			status.State = StateFinishing
+		} else {
+			status.State = StateStopping
+		}
 	case "inactive":
 		status.State = StateDown
 		status.Flags.WantDown = true
@@ -370,17 +630,39 @@ func (c *ClientSystemd) SendOperation(ctx context.Context, op Operation) error {
 	}
 }
 
-// signalMainPID gets the MainPID and sends a signal directly to it
-func (c *ClientSystemd) signalMainPID(ctx context.Context, signal string) error {
+// signalOps maps the signals sent by signalMainPID to the operations they
+// are reported as
+var signalOps = map[string]Operation{
+	"TERM": OpTerm,
+	"KILL": OpKill,
+	"HUP":  OpHUP,
+	"INT":  OpInterrupt,
+	"ALRM": OpAlarm,
+	"QUIT": OpQuit,
+	"USR1": OpUSR1,
+	"USR2": OpUSR2,
+	"STOP": OpPause,
+	"CONT": OpCont,
+}
+
+// signalMainPID gets the MainPID and sends a signal directly to it, or
+// signals the processes selected by KillWho through systemctl kill.
+// Signals without an operation are reported as OpUnknown.
+func (c *ClientSystemd) signalMainPID(ctx context.Context, signal string) (err error) {
+	defer func(start time.Time) { c.observe(ctx, signalOps[signal], start, err) }(time.Now())
+
+	if c.KillWho != "" {
+		_, err := c.execSystemctl(ctx, "kill", "--kill-who="+string(c.KillWho), "--signal="+systemctlSignal(signal))
+		return err
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, c.Timeout)
+	defer cancel()
+
 	// Get the MainPID
 	serviceName := fmt.Sprintf("%s.service", c.ServiceName)
 
-	var cmd *exec.Cmd
-	if c.UseSudo {
-		cmd = exec.CommandContext(ctx, c.SudoCommand, c.SystemctlPath, "show", "-p", "MainPID", "--value", serviceName)
-	} else {
-		cmd = exec.CommandContext(ctx, c.SystemctlPath, "show", "-p", "MainPID", "--value", serviceName)
-	}
+	cmd := c.systemctlCmd(ctx, "show", "-p", "MainPID", "--value", serviceName)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -396,11 +678,7 @@ func (c *ClientSystemd) signalMainPID(ctx context.Context, signal string) error
 	}
 
 	// Send the signal to the process
-	if c.UseSudo {
-		cmd = exec.CommandContext(ctx, c.SudoCommand, "kill", "-"+signal, pidStr)
-	} else {
-		cmd = exec.CommandContext(ctx, "kill", "-"+signal, pidStr)
-	}
+	cmd = c.command(ctx, "kill", "-"+signal, pidStr)
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("sending signal %s to PID %s: %w", signal, pidStr, err)
@@ -409,68 +687,55 @@ func (c *ClientSystemd) signalMainPID(ctx context.Context, signal string) error
 	return nil
 }
 
-// runOnce runs the service command once using systemd-run
-func (c *ClientSystemd) runOnce(ctx context.Context) error {
-	// First, we need to get the ExecStart command from the unit file
-	serviceName := fmt.Sprintf("%s.service", c.ServiceName)
-
-	var cmd *exec.Cmd
-	if c.UseSudo {
-		cmd = exec.CommandContext(ctx, c.SudoCommand, c.SystemctlPath, "show", "-p", "ExecStart", "--value", serviceName)
-	} else {
-		cmd = exec.CommandContext(ctx, c.SystemctlPath, "show", "-p", "ExecStart", "--value", serviceName)
+// systemctlSignal returns signal as systemctl kill --signal takes it:
+// names get the SIG prefix when they lack it, numbers pass through
+func systemctlSignal(signal string) string {
+	if _, err := strconv.Atoi(signal); err == nil || strings.HasPrefix(signal, "SIG") {
+		return signal
 	}
+	return "SIG" + signal
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("getting ExecStart: %w (stderr: %s)", err, stderr.String())
-	}
-
-	execStart := strings.TrimSpace(stdout.String())
-	if execStart == "" {
-		return fmt.Errorf("no ExecStart command found for service")
-	}
-
-	// Parse the ExecStart line (it's in a special format)
-	// Format: { path=/usr/bin/command ; argv[]=/usr/bin/command arg1 arg2 ; ignore_errors=no }
-	// We need to extract the actual command
-	if strings.HasPrefix(execStart, "{") && strings.HasSuffix(execStart, "}") {
-		// Parse systemd's structured format
-		parts := strings.Split(execStart[1:len(execStart)-1], ";")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if strings.HasPrefix(part, "argv[]=") {
-				execStart = strings.TrimPrefix(part, "argv[]=")
-				break
-			} else if strings.HasPrefix(part, "path=") {
-				// Fallback to just the path if argv[] is not found
-				execStart = strings.TrimPrefix(part, "path=")
-			}
-		}
-	}
+// runOnce runs the service command once as a transient unit, with the
+// service's user, group and working directory
+func (c *ClientSystemd) runOnce(ctx context.Context) (err error) {
+	defer func(start time.Time) { c.observe(ctx, OpOnce, start, err) }(time.Now())
 
-	// Run the command once using systemd-run
-	// --uid, --gid, --setenv can be extracted from the service if needed
-	runArgs := []string{"systemd-run", "--no-block", "--uid=" + os.Getenv("USER")}
+	ctx, cancel := withDefaultTimeout(ctx, c.Timeout)
+	defer cancel()
 
-	// Add the command
-	// Split execStart properly (this is simplified, may need shell parsing)
-	cmdParts := strings.Fields(execStart)
-	runArgs = append(runArgs, cmdParts...)
+	argv, err := c.execStartArgv(ctx)
+	if err != nil {
+		return err
+	}
 
-	if c.UseSudo {
-		cmd = exec.CommandContext(ctx, c.SudoCommand, runArgs...)
-	} else {
-		cmd = exec.CommandContext(ctx, runArgs[0], runArgs[1:]...)
+	props := make(map[string]string)
+	keys := []string{"User", "Group", "WorkingDirectory"}
+	for _, key := range keys {
+		if v, ok := c.cachedProperty(key); ok {
+			props[key] = v
+		}
+	}
+	if len(props) < len(keys) {
+		blocks, err := c.showMany(ctx, keys, []string{c.ServiceName})
+		if err != nil {
+			return fmt.Errorf("getting unit properties: %w", err)
+		}
+		props = c.parseStatusSystemd(blocks[0]).Properties
 	}
 
-	if err := cmd.Run(); err != nil {
+	// A leading "-" marks the directory optional, "!" is for ExecStart only
+	b := NewServiceBuilder(c.ServiceName, "").WithCmd(argv).
+		WithCwd(strings.TrimLeft(props["WorkingDirectory"], "-!"))
+	if props["User"] != "" || props["Group"] != "" {
+		b.WithChpst(func(cfg *ChpstConfig) {
+			cfg.User = props["User"]
+			cfg.Group = props["Group"]
+		})
+	}
+	if _, err := c.RunTransient(ctx, TransientSpec{Service: b}); err != nil {
 		return fmt.Errorf("running service once: %w", err)
 	}
-
 	return nil
 }
 
@@ -501,6 +766,18 @@ func (c *ClientSystemd) WaitForState(ctx context.Context, targetState string, ti
 	}
 }
 
+// StatusExtended returns the status together with resource usage of the
+// main process read from /proc
+func (c *ClientSystemd) StatusExtended(ctx context.Context) (StatusExtended, error) {
+	return statusExtended(ctx, c.Status, DefaultProcDir)
+}
+
+// ProcessTree returns the main process and all of its descendants from /proc,
+// or nil when the service has no process
+func (c *ClientSystemd) ProcessTree(ctx context.Context) ([]ProcessInfo, error) {
+	return processTree(ctx, c.Status, DefaultProcDir)
+}
+
 // Status returns the status of the service in runit format for interface compatibility
 func (c *ClientSystemd) Status(ctx context.Context) (Status, error) {
 	systemdStatus, err := c.StatusSystemd(ctx)
@@ -548,15 +825,27 @@ func (c *ClientSystemd) ExitSupervise(ctx context.Context) error {
 	return c.Disable(ctx)
 }
 
-// Watch monitors the systemd service for state changes
+// Watch monitors the systemd service for state changes. Changes are picked
+// up from PropertiesChanged signals of the unit through busctl monitor;
+// where monitoring is unavailable (busctl missing, or the system bus
+// refusing an unprivileged monitor) the status is polled every
+// WatchInterval instead.
 func (c *ClientSystemd) Watch(ctx context.Context) (<-chan WatchEvent, WatchCleanupFunc, error) {
-	// For systemd, we poll the status periodically since there's no file to watch
-	ch := make(chan WatchEvent, 10)
+	sender := newWatchSender(c.WatchBuffer, c.WatchOverflow)
+	ch := sender.ch
 
 	// Create stopper context for managing goroutine lifecycle
 	sctx := stopper.WithContext(ctx)
 
-	ticker := time.NewTicker(c.WatchInterval)
+	// busctl runs until the watch stops
+	monitorCtx, cancelMonitor := context.WithCancel(ctx)
+	changes := make(chan struct{}, 1)
+	monitorExited, err := c.monitorUnit(monitorCtx, changes)
+	interval := systemdWatchRecheck
+	if err != nil {
+		interval = c.WatchInterval
+	}
+	ticker := time.NewTicker(interval)
 
 	// Register cleanup with stopper
 	sctx.Defer(func() {
@@ -572,17 +861,31 @@ func (c *ClientSystemd) Watch(ctx context.Context) (<-chan WatchEvent, WatchClea
 		return sctx.Wait()
 	}
 
-	// Launch polling goroutine using stopper
+	// readAndSend sends the status when its state changed, reporting false
+	// once the watch is stopping
+	readAndSend := func() bool {
+		status, err := c.Status(ctx)
+		if err != nil {
+			return sender.send(WatchEvent{Err: err}, sctx.Stopping())
+		}
+
+		currentState := status.State.String()
+		if currentState == lastState {
+			return true
+		}
+		lastState = currentState
+		return sender.send(WatchEvent{Status: status}, sctx.Stopping())
+	}
+
+	// Launch the watch goroutine using stopper
 	sctx.Go(func(sctx *stopper.Context) error {
+		defer cancelMonitor()
+
 		// Get initial status
 		if status, err := c.Status(ctx); err == nil {
 			lastState = status.State.String()
-			if !sctx.IsStopping() {
-				select {
-				case ch <- WatchEvent{Status: status}:
-				case <-sctx.Stopping():
-					return nil
-				}
+			if !sctx.IsStopping() && !sender.send(WatchEvent{Status: status}, sctx.Stopping()) {
+				return nil
 			}
 		}
 
@@ -590,29 +893,17 @@ func (c *ClientSystemd) Watch(ctx context.Context) (<-chan WatchEvent, WatchClea
 			select {
 			case <-sctx.Stopping():
 				return nil
-			case <-ticker.C:
-				status, err := c.Status(ctx)
-				if err != nil {
-					if !sctx.IsStopping() {
-						select {
-						case ch <- WatchEvent{Err: err}:
-						case <-sctx.Stopping():
-							return nil
-						}
-					}
-					continue
+			case <-monitorExited:
+				// Fall back to polling
+				monitorExited = nil
+				ticker.Reset(c.WatchInterval)
+			case <-changes:
+				if !readAndSend() {
+					return nil
 				}
-
-				currentState := status.State.String()
-				if currentState != lastState {
-					lastState = currentState
-					if !sctx.IsStopping() {
-						select {
-						case ch <- WatchEvent{Status: status}:
-						case <-sctx.Stopping():
-							return nil
-						}
-					}
+			case <-ticker.C:
+				if !readAndSend() {
+					return nil
 				}
 			}
 		}
@@ -623,4 +914,9 @@ func (c *ClientSystemd) Watch(ctx context.Context) (<-chan WatchEvent, WatchClea
 }
 
 // Ensure ClientSystemd implements ServiceClient
-var _ ServiceClient = (*ClientSystemd)(nil)
+var (
+	_ ServiceClient      = (*ClientSystemd)(nil)
+	_ ProcessInspector   = (*ClientSystemd)(nil)
+	_ FuncWaiter         = (*ClientSystemd)(nil)
+	_ CapabilityReporter = (*ClientSystemd)(nil)
+)
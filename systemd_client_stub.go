@@ -9,7 +9,8 @@ import (
 
 // ClientSystemd provides control operations for systemd services (Linux only)
 type ClientSystemd struct {
-	ServiceName string
+	ServiceName     string
+	Instrumentation Instrumentation
 }
 
 // NewClientSystemd creates a new ClientSystemd (stub for non-Linux)
@@ -32,6 +33,21 @@ func (c *ClientSystemd) Status(_ context.Context) (Status, error) {
 	return Status{}, fmt.Errorf("systemd is only supported on Linux")
 }
 
+// StatusMany returns the status of several units (stub - systemd is only supported on Linux)
+func (c *ClientSystemd) StatusMany(_ context.Context, _ ...string) (map[string]Status, error) {
+	return nil, fmt.Errorf("systemd is only supported on Linux")
+}
+
+// StatusExtended returns the extended status (stub - systemd is only supported on Linux)
+func (c *ClientSystemd) StatusExtended(_ context.Context) (StatusExtended, error) {
+	return StatusExtended{}, fmt.Errorf("systemd is only supported on Linux")
+}
+
+// ProcessTree returns the process tree (stub - systemd is only supported on Linux)
+func (c *ClientSystemd) ProcessTree(_ context.Context) ([]ProcessInfo, error) {
+	return nil, fmt.Errorf("systemd is only supported on Linux")
+}
+
 // Term sends SIGTERM (stub - systemd is only supported on Linux)
 func (c *ClientSystemd) Term(_ context.Context) error {
 	return fmt.Errorf("systemd is only supported on Linux")
@@ -113,4 +129,19 @@ func (c *ClientSystemd) Watch(_ context.Context) (<-chan WatchEvent, WatchCleanu
 }
 
 // Ensure ClientSystemd implements ServiceClient
-var _ ServiceClient = (*ClientSystemd)(nil)
+var (
+	_ ServiceClient      = (*ClientSystemd)(nil)
+	_ ProcessInspector   = (*ClientSystemd)(nil)
+	_ FuncWaiter         = (*ClientSystemd)(nil)
+	_ CapabilityReporter = (*ClientSystemd)(nil)
+)
+
+// SystemdUnits returns systemd services with their relations (stub - systemd is only supported on Linux)
+func (m *Manager) SystemdUnits(_ context.Context, _ ...string) ([]ServiceUnit, error) {
+	return nil, fmt.Errorf("systemd is only supported on Linux")
+}
+
+// UpSystemdUnits starts systemd services in dependency order (stub - systemd is only supported on Linux)
+func (m *Manager) UpSystemdUnits(_ context.Context, _ ...string) error {
+	return fmt.Errorf("systemd is only supported on Linux")
+}
@@ -0,0 +1,176 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// unitDependencyProperties are the systemctl show properties read by
+// Dependencies
+var unitDependencyProperties = []string{"Id", "Wants", "Requires", "After", "Before"}
+
+// UnitDependencies are the relations of a systemd unit to other units, as
+// systemd resolved them (including implicit and default dependencies)
+type UnitDependencies struct {
+	// Unit is the unit's full name, e.g. "web.service"
+	Unit string
+	// Wants lists units started along with this one
+	Wants []string
+	// Requires lists units this one fails without
+	Requires []string
+	// After lists units started before this one
+	After []string
+	// Before lists units started after this one
+	Before []string
+}
+
+// Dependencies returns the unit's Wants, Requires, After and Before
+// relations, read from systemctl show
+func (c *ClientSystemd) Dependencies(ctx context.Context) (*UnitDependencies, error) {
+	output, err := c.execSystemctl(ctx, "show", "--no-pager", "-p", strings.Join(unitDependencyProperties, ","))
+	if err != nil {
+		return nil, err
+	}
+	deps := parseUnitDependencies(output)
+	if deps.Unit == "" {
+		deps.Unit = fullUnitName(c.ServiceName)
+	}
+	return deps, nil
+}
+
+// parseUnitDependencies parses the dependency properties of one unit from
+// systemctl show output; lists are space separated
+func parseUnitDependencies(output string) *UnitDependencies {
+	deps := &UnitDependencies{}
+	for line := range strings.Lines(output) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Id":
+			deps.Unit = value
+		case "Wants":
+			deps.Wants = strings.Fields(value)
+		case "Requires":
+			deps.Requires = strings.Fields(value)
+		case "After":
+			deps.After = strings.Fields(value)
+		case "Before":
+			deps.Before = strings.Fields(value)
+		}
+	}
+	return deps
+}
+
+// DependencyGraph returns the services named by units, with their relations
+// to each other read by a single systemctl show, in the form UpUnits orders
+// by. Relations to units outside the set are dropped; systemd resolves those
+// itself when starting a unit. Before relations become After relations of
+// the other unit. ServiceUnit.Dir holds the full unit name.
+func (c *ClientSystemd) DependencyGraph(ctx context.Context, units ...string) ([]ServiceUnit, error) {
+	if len(units) == 0 {
+		return nil, nil
+	}
+	blocks, err := c.showMany(ctx, unitDependencyProperties, units)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = fullUnitName(unit)
+	}
+	inSet := func(list []string) []string {
+		var out []string
+		for _, name := range list {
+			if slices.Contains(names, name) && !slices.Contains(out, name) {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+
+	graph := make([]ServiceUnit, len(units))
+	for i, block := range blocks {
+		deps := parseUnitDependencies(block)
+		graph[i].Dir = names[i]
+		graph[i].Wants = inSet(deps.Wants)
+		graph[i].Requires = inSet(deps.Requires)
+		graph[i].After = append(graph[i].After, inSet(deps.After)...)
+		for _, later := range inSet(deps.Before) {
+			j := slices.Index(names, later)
+			if !slices.Contains(graph[j].After, names[i]) {
+				graph[j].After = append(graph[j].After, names[i])
+			}
+		}
+	}
+	for i := range graph {
+		graph[i].After = slices.Compact(slices.Sorted(slices.Values(graph[i].After)))
+	}
+	return graph, nil
+}
+
+// SystemdUnits returns the systemd services named by units with their
+// relations to each other; see ClientSystemd.DependencyGraph
+func (m *Manager) SystemdUnits(ctx context.Context, units ...string) ([]ServiceUnit, error) {
+	ctx, cancel := withDefaultTimeout(ctx, m.Timeout)
+	defer cancel()
+	return m.systemdClient().DependencyGraph(ctx, units...)
+}
+
+// UpSystemdUnits starts the systemd services named by units in the order
+// their After, Before and Requires relations among each other impose, as
+// UpUnits does for service directories: each is started once those it is
+// ordered after are active, and is skipped with a DependencyError when a
+// required one failed. Only service units are supported.
+func (m *Manager) UpSystemdUnits(ctx context.Context, units ...string) error {
+	return m.upSystemdUnits(ctx, m.systemdClient(), units)
+}
+
+// upSystemdUnits is UpSystemdUnits with the units controlled through copies
+// of client
+func (m *Manager) upSystemdUnits(ctx context.Context, client *ClientSystemd, units []string) error {
+	graphCtx, cancel := withDefaultTimeout(ctx, m.Timeout)
+	graph, err := client.DependencyGraph(graphCtx, units...)
+	cancel()
+	if err != nil {
+		return err
+	}
+	resolve := func(name string) (string, error) { return fullUnitName(name), nil }
+	return m.upUnits(ctx, graph, resolve, func(ctx context.Context, unit string) error {
+		name, ok := strings.CutSuffix(unit, ".service")
+		if !ok {
+			return &OpError{Op: OpUp, Path: unit, Err: fmt.Errorf("not a service unit")}
+		}
+		c := *client
+		c.ServiceName = name
+		return upSystemdUnit(ctx, &c)
+	})
+}
+
+// upSystemdUnit starts a service and waits until it is active
+func upSystemdUnit(ctx context.Context, client *ClientSystemd) error {
+	unit := fullUnitName(client.ServiceName)
+	if err := client.Up(ctx); err != nil {
+		return &OpError{Op: OpUp, Path: unit, Err: err}
+	}
+
+	var last Status
+	err := pollUntil(ctx, DefaultReadyPollInterval, func() (bool, error) {
+		st, err := client.Status(ctx)
+		if err != nil {
+			return false, nil
+		}
+		last = st
+		return st.State == StateRunning, nil
+	})
+	if err != nil {
+		return &OpError{Op: OpUp, Path: unit, Err: fmt.Errorf("not active (state %v): %w", last.State, err)}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	path, argsFile := fakeSystemctl(t, `Id=web.service
+Wants=cache.service
+Requires=db.service sysinit.target
+After=db.service cache.service basic.target
+Before=shutdown.target
+`)
+	c := NewClientSystemd("web")
+	c.UseSudo = false
+	c.SystemctlPath = path
+
+	deps, err := c.Dependencies(context.Background())
+	if err != nil {
+		t.Fatalf("Dependencies: %v", err)
+	}
+	want := &UnitDependencies{
+		Unit:     "web.service",
+		Wants:    []string{"cache.service"},
+		Requires: []string{"db.service", "sysinit.target"},
+		After:    []string{"db.service", "cache.service", "basic.target"},
+		Before:   []string{"shutdown.target"},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("Dependencies = %+v, want %+v", deps, want)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "show --no-pager -p Id,Wants,Requires,After,Before web.service"; strings.TrimSpace(string(args)) != want {
+		t.Errorf("systemctl args = %q, want %q", strings.TrimSpace(string(args)), want)
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	path, _ := fakeSystemctl(t, `Id=web.service
+Wants=
+Requires=db.service sysinit.target
+After=db.service basic.target
+Before=
+
+Id=db.service
+Wants=
+Requires=
+After=basic.target
+Before=web.service worker.service
+
+Id=worker.service
+Wants=web.service
+Requires=
+After=
+Before=
+`)
+	c := NewClientSystemd("")
+	c.UseSudo = false
+	c.SystemctlPath = path
+
+	graph, err := c.DependencyGraph(context.Background(), "web", "db", "worker.service")
+	if err != nil {
+		t.Fatalf("DependencyGraph: %v", err)
+	}
+	want := []ServiceUnit{
+		{Dir: "web.service", Requires: []string{"db.service"}, After: []string{"db.service"}},
+		{Dir: "db.service"},
+		{Dir: "worker.service", Wants: []string{"web.service"}, After: []string{"db.service"}},
+	}
+	if !reflect.DeepEqual(graph, want) {
+		t.Errorf("DependencyGraph = %+v, want %+v", graph, want)
+	}
+}
+
+func TestUpSystemdUnits(t *testing.T) {
+	dir := t.TempDir()
+	started := filepath.Join(dir, "started")
+	path := filepath.Join(dir, "systemctl")
+	script := `#!/bin/sh
+case "$*" in
+"--user show --no-pager -p Id,Wants,Requires,After,Before "*)
+	printf 'Id=web.service\nRequires=db.service\nAfter=db.service\n\nId=db.service\nRequires=\nAfter=\n' ;;
+"--user start db.service")
+	exit 1 ;;
+"--user start"*)
+	echo "$3" >> ` + started + ` ;;
+"--user show"*)
+	printf 'ActiveState=active\nSubState=running\nMainPID=42\n' ;;
+*)
+	exit 1 ;;
+esac
+`
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClientSystemd("").WithUserMode(true)
+	c.SystemctlPath = path
+
+	err := NewManager(WithSystemdClient(c)).UpSystemdUnits(context.Background(), "web", "db")
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) || depErr.Service != "web.service" || depErr.Dependency != "db.service" {
+		t.Fatalf("UpSystemdUnits error = %v, want web.service skipped for db.service", err)
+	}
+	if data, err := os.ReadFile(started); err == nil {
+		t.Errorf("started %q, want nothing started", data)
+	}
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestKillWho(t *testing.T) {
+	tests := []struct {
+		name string
+		who  KillWho
+		op   func(*ClientSystemd, context.Context) error
+		want string
+	}{
+		{"term main", KillWhoMain, (*ClientSystemd).Term, "kill --kill-who=main --signal=SIGTERM web.service"},
+		{"hup control", KillWhoControl, (*ClientSystemd).HUP, "kill --kill-who=control --signal=SIGHUP web.service"},
+		{"kill all", KillWhoAll, (*ClientSystemd).Kill, "kill --kill-who=all --signal=SIGKILL web.service"},
+		{"prefixed name", KillWhoMain, signalWith("SIGUSR1"), "kill --kill-who=main --signal=SIGUSR1 web.service"},
+		{"number", KillWhoMain, signalWith("9"), "kill --kill-who=main --signal=9 web.service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, argsFile := fakeSystemctl(t, "")
+			c := NewClientSystemd("web").WithKillWho(tt.who)
+			c.UseSudo = false
+			c.SystemctlPath = path
+
+			if err := tt.op(c, context.Background()); err != nil {
+				t.Fatalf("signal: %v", err)
+			}
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(string(args)); got != tt.want {
+				t.Errorf("systemctl args = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// signalWith returns an op sending sig through ClientSystemd.Signal
+func signalWith(sig string) func(*ClientSystemd, context.Context) error {
+	return func(c *ClientSystemd, ctx context.Context) error {
+		return c.Signal(ctx, sig)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultPortablectlPath is the default path to the portablectl binary
+const DefaultPortablectlPath = "portablectl"
+
+// PortableOptions configures attaching and detaching portable service images
+// (see portablectl(1))
+type PortableOptions struct {
+	// Profile is the security profile applied to attached units
+	// ("default", "nonetwork", "strict", "trusted"); empty uses portablectl's default
+	Profile string
+
+	// Runtime attaches below /run so the attachment does not survive a reboot
+	Runtime bool
+
+	// Copy selects how unit files are placed: "copy", "symlink", "auto" or
+	// "mixed"; empty uses portablectl's default
+	Copy string
+
+	// Extensions lists extension images layered on top of the image
+	Extensions []string
+
+	// Now starts the units after attaching, or stops them before detaching
+	Now bool
+
+	// Enable enables the units after attaching, or disables them before detaching
+	Enable bool
+}
+
+// PortableImage describes an image known to systemd-portabled
+type PortableImage struct {
+	// Name is the image name
+	Name string
+	// Type is the image type (directory, raw, subvolume, ...)
+	Type string
+	// ReadOnly reports whether the image is read-only
+	ReadOnly bool
+	// State is the attachment state (detached, attached, running, ...)
+	State string
+}
+
+// AttachPortable attaches the portable service image and its units matching
+// the client's service name, like "portablectl attach image name"
+func (c *ClientSystemd) AttachPortable(ctx context.Context, image string, opts PortableOptions) error {
+	args := append([]string{"attach"}, opts.args(true)...)
+	_, err := c.execPortablectl(ctx, append(args, image, c.ServiceName)...)
+	return err
+}
+
+// DetachPortable detaches the portable service image, like "portablectl detach image"
+func (c *ClientSystemd) DetachPortable(ctx context.Context, image string, opts PortableOptions) error {
+	args := append([]string{"detach"}, opts.args(false)...)
+	_, err := c.execPortablectl(ctx, append(args, image)...)
+	return err
+}
+
+// PortableImages lists the images known to systemd-portabled
+func (c *ClientSystemd) PortableImages(ctx context.Context) ([]PortableImage, error) {
+	out, err := c.execPortablectl(ctx, "list", "--no-legend", "--no-pager")
+	if err != nil {
+		return nil, err
+	}
+	return parsePortableImages(out), nil
+}
+
+// AttachPortable attaches the portable service image for the builder's
+// service instead of writing a unit file; daemon-reload is handled by portabled
+func (b *BuilderSystemd) AttachPortable(ctx context.Context, image string, opts PortableOptions) error {
+	return b.client().AttachPortable(ctx, image, opts)
+}
+
+// DetachPortable detaches the builder's portable service image
+func (b *BuilderSystemd) DetachPortable(ctx context.Context, image string, opts PortableOptions) error {
+	return b.client().DetachPortable(ctx, image, opts)
+}
+
+// args returns the portablectl options for attach (or detach)
+func (o PortableOptions) args(attach bool) []string {
+	var args []string
+	if attach && o.Profile != "" {
+		args = append(args, "--profile="+o.Profile)
+	}
+	if attach && o.Copy != "" {
+		args = append(args, "--copy="+o.Copy)
+	}
+	if o.Runtime {
+		args = append(args, "--runtime")
+	}
+	for _, ext := range o.Extensions {
+		args = append(args, "--extension="+ext)
+	}
+	if o.Now {
+		args = append(args, "--now")
+	}
+	if o.Enable {
+		args = append(args, "--enable")
+	}
+	return args
+}
+
+// execPortablectl runs portablectl with optional sudo
+func (c *ClientSystemd) execPortablectl(ctx context.Context, args ...string) (string, error) {
+	if c.UserMode {
+		return "", errors.New("portable services are not supported by the user service manager")
+	}
+	ctx, cancel := withDefaultTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	path := c.PortablectlPath
+	if path == "" {
+		path = DefaultPortablectlPath
+	}
+	cmd := c.command(ctx, path, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("portablectl %s: %w (stderr: %s)", args[0], err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// parsePortableImages parses "portablectl list --no-legend" output:
+// NAME TYPE RO CRTIME MTIME USAGE STATE, where the times contain spaces
+func parsePortableImages(out string) []PortableImage {
+	var images []PortableImage
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		images = append(images, PortableImage{
+			Name:     fields[0],
+			Type:     fields[1],
+			ReadOnly: fields[2] == "yes",
+			State:    fields[len(fields)-1],
+		})
+	}
+	return images
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPortableOptionsArgs(t *testing.T) {
+	opts := PortableOptions{
+		Profile:    "strict",
+		Copy:       "symlink",
+		Runtime:    true,
+		Extensions: []string{"/var/lib/extensions/debug.raw"},
+		Now:        true,
+		Enable:     true,
+	}
+
+	attach := []string{"--profile=strict", "--copy=symlink", "--runtime", "--extension=/var/lib/extensions/debug.raw", "--now", "--enable"}
+	if got := opts.args(true); !slices.Equal(got, attach) {
+		t.Errorf("attach args = %q, want %q", got, attach)
+	}
+	// Profile and copy mode only apply when attaching
+	detach := []string{"--runtime", "--extension=/var/lib/extensions/debug.raw", "--now", "--enable"}
+	if got := opts.args(false); !slices.Equal(got, detach) {
+		t.Errorf("detach args = %q, want %q", got, detach)
+	}
+}
+
+func TestParsePortableImages(t *testing.T) {
+	out := `foobar directory no  Mon 2026-03-02 10:00:00 UTC Mon 2026-03-02 10:00:00 UTC n/a attached
+web    raw       yes Tue 2026-03-03 11:00:00 UTC Tue 2026-03-03 11:00:00 UTC 1.2G running
+`
+	want := []PortableImage{
+		{Name: "foobar", Type: "directory", State: "attached"},
+		{Name: "web", Type: "raw", ReadOnly: true, State: "running"},
+	}
+	if got := parsePortableImages(out); !slices.Equal(got, want) {
+		t.Errorf("parsePortableImages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPortableUserMode(t *testing.T) {
+	c := NewClientSystemd("web").WithUserMode(true)
+	err := c.AttachPortable(t.Context(), "/var/lib/portables/web.raw", PortableOptions{})
+	if err == nil || !strings.Contains(err.Error(), "user service manager") {
+		t.Errorf("AttachPortable in user mode = %v", err)
+	}
+}
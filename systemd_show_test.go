@@ -0,0 +1,212 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeSystemctl writes a systemctl stand-in that records its arguments in
+// args and prints output
+func fakeSystemctl(t *testing.T, output string) (path, args string) {
+	t.Helper()
+	dir := t.TempDir()
+	path = filepath.Join(dir, "systemctl")
+	args = filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + args + "\ncat <<'EOF'\n" + output + "EOF\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path, args
+}
+
+func TestStatusSystemdMany(t *testing.T) {
+	out := `Id=web.service
+LoadState=loaded
+ActiveState=active
+SubState=running
+MainPID=4242
+
+Id=db.service
+LoadState=loaded
+ActiveState=failed
+SubState=failed
+MainPID=0
+Result=exit-code
+
+Id=sync.timer
+LoadState=not-found
+ActiveState=inactive
+SubState=dead
+MainPID=0
+`
+	path, argsFile := fakeSystemctl(t, out)
+	c := NewClientSystemd("")
+	c.UseSudo = false
+	c.SystemctlPath = path
+
+	got, err := c.StatusSystemdMany(context.Background(), "web", "db.service", "sync.timer")
+	if err != nil {
+		t.Fatalf("StatusSystemdMany: %v", err)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "show --no-pager web.service db.service sync.timer"; strings.TrimSpace(string(args)) != want {
+		t.Errorf("systemctl args = %q, want %q", strings.TrimSpace(string(args)), want)
+	}
+
+	tests := []struct {
+		unit    string
+		running bool
+		pid     int
+		load    string
+		result  string
+	}{
+		{"web", true, 4242, "loaded", ""},
+		{"db.service", false, 0, "loaded", "exit-code"},
+		{"sync.timer", false, 0, "not-found", ""},
+	}
+	for _, tt := range tests {
+		st, ok := got[tt.unit]
+		if !ok {
+			t.Errorf("no status for %s", tt.unit)
+			continue
+		}
+		if st.Running != tt.running || st.MainPID != tt.pid || st.LoadState != tt.load || st.Result != tt.result {
+			t.Errorf("%s = running %v pid %d load %q result %q, want %v %d %q %q",
+				tt.unit, st.Running, st.MainPID, st.LoadState, st.Result, tt.running, tt.pid, tt.load, tt.result)
+		}
+	}
+
+	statuses, err := c.StatusMany(context.Background(), "web", "db.service", "sync.timer")
+	if err != nil {
+		t.Fatalf("StatusMany: %v", err)
+	}
+	if statuses["web"].State != StateRunning || statuses["web"].PID != 4242 {
+		t.Errorf("StatusMany web = %v pid %d, want running 4242", statuses["web"].State, statuses["web"].PID)
+	}
+}
+
+func TestManagerStatusSystemdUserMode(t *testing.T) {
+	path, argsFile := fakeSystemctl(t, "Id=web.service\nActiveState=active\nSubState=running\nMainPID=7\n")
+	client := NewClientSystemd("").WithUserMode(true)
+	client.SystemctlPath = path
+
+	mgr := NewManager(WithSystemdClient(client))
+	got, err := mgr.StatusSystemd(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("StatusSystemd: %v", err)
+	}
+	if got["web"].PID != 7 {
+		t.Errorf("StatusSystemd web pid = %d, want 7", got["web"].PID)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "--user show --no-pager web.service"; strings.TrimSpace(string(args)) != want {
+		t.Errorf("systemctl args = %q, want %q", strings.TrimSpace(string(args)), want)
+	}
+}
+
+func TestStatusSystemdManyMismatch(t *testing.T) {
+	path, _ := fakeSystemctl(t, "Id=web.service\nActiveState=active\n")
+	c := NewClientSystemd("")
+	c.UseSudo = false
+	c.SystemctlPath = path
+
+	if _, err := c.StatusSystemdMany(context.Background(), "web", "db"); err == nil {
+		t.Error("StatusSystemdMany accepted output for fewer units than requested")
+	}
+	if got, err := c.StatusSystemdMany(context.Background()); err != nil || len(got) != 0 {
+		t.Errorf("StatusSystemdMany() = %v, %v; want empty", got, err)
+	}
+}
+
+func TestFullUnitName(t *testing.T) {
+	tests := map[string]string{
+		"web":          "web.service",
+		"web.service":  "web.service",
+		"my.app":       "my.app.service",
+		"backup.timer": "backup.timer",
+	}
+	for in, want := range tests {
+		if got := fullUnitName(in); got != want {
+			t.Errorf("fullUnitName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStatusSystemdMapToStatus(t *testing.T) {
+	tests := []struct {
+		active, sub      string
+		state            State
+		wantUp, wantDown bool
+	}{
+		{"active", "running", StateRunning, false, false},
+		{"reloading", "reload", StateRunning, true, false},
+		{"activating", "start-pre", StateStarting, true, false},
+		{"activating", "start", StateStarting, true, false},
+		{"activating", "auto-restart", StateCrashed, true, false},
+		{"deactivating", "stop-sigterm", StateStopping, false, true},
+		{"deactivating", "stop-post", StateFinishing, false, true},
+		{"inactive", "dead", StateDown, false, true},
+		{"failed", "failed", StateDown, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.active+"/"+tt.sub, func(t *testing.T) {
+			s := &StatusSystemd{ActiveState: tt.active, SubState: tt.sub}
+			st := s.MapToStatus()
+			if st.State != tt.state {
+				t.Errorf("State = %v, want %v", st.State, tt.state)
+			}
+			if st.Flags.WantUp != tt.wantUp || st.Flags.WantDown != tt.wantDown {
+				t.Errorf("WantUp, WantDown = %v, %v, want %v, %v", st.Flags.WantUp, st.Flags.WantDown, tt.wantUp, tt.wantDown)
+			}
+		})
+	}
+}
+
+func TestSystemdInstrumentation(t *testing.T) {
+	path, _ := fakeSystemctl(t, "ActiveState=active\nSubState=running\nMainPID=4242\n")
+
+	var events []OperationEvent
+	inst := InstrumentationFunc(func(ev OperationEvent) { events = append(events, ev) })
+	client, err := NewClient("/etc/service/web", ServiceTypeSystemd, WithInstrumentation(inst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.(*ClientSystemd)
+	c.UseSudo = false
+	c.SystemctlPath = path
+
+	if err := c.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.SystemctlPath = filepath.Join(t.TempDir(), "missing")
+	downErr := c.Down(context.Background())
+
+	want := []Operation{OpUp, OpStatus, OpDown}
+	if len(events) != len(want) {
+		t.Fatalf("observed %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, ev := range events {
+		if ev.Op != want[i] || ev.Service != "web" {
+			t.Errorf("event %d = %+v, want %v of web", i, ev, want[i])
+		}
+	}
+	if downErr == nil || !errors.Is(events[2].Err, downErr) {
+		t.Errorf("down event error = %v, want the returned %v", events[2].Err, downErr)
+	}
+}
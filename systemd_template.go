@@ -0,0 +1,116 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// InstanceUnitName returns the instantiated unit name template@instance (without .service)
+func InstanceUnitName(template, instance string) string {
+	return template + "@" + instance
+}
+
+// ParseInstanceUnitName splits an instantiated unit name such as "getty@tty1"
+// or "getty@tty1.service" into its template and instance names.
+// ok is false if name is not an instance of a template.
+func ParseInstanceUnitName(name string) (template, instance string, ok bool) {
+	name = strings.TrimSuffix(name, ".service")
+	template, instance, ok = strings.Cut(name, "@")
+	if !ok || template == "" || instance == "" {
+		return "", "", false
+	}
+	return template, instance, true
+}
+
+// TemplateSystemd controls all instances of a systemd template unit (name@.service)
+type TemplateSystemd struct {
+	// Template is the template name without "@.service"
+	Template string
+
+	// Client is the prototype whose settings (sudo, user mode, timeouts)
+	// are copied to each instance client
+	Client *ClientSystemd
+}
+
+// NewTemplateSystemd creates a TemplateSystemd for the named template
+func NewTemplateSystemd(template string) *TemplateSystemd {
+	return &TemplateSystemd{
+		Template: template,
+		Client:   NewClientSystemd(template),
+	}
+}
+
+// Instance returns a client for one instance of the template
+func (t *TemplateSystemd) Instance(instance string) *ClientSystemd {
+	c := *t.Client
+	c.ServiceName = InstanceUnitName(t.Template, instance)
+	return &c
+}
+
+// Instances lists the names of the template's loaded instances, active or not
+func (t *TemplateSystemd) Instances(ctx context.Context) ([]string, error) {
+	cmd := t.Client.systemctlCmd(ctx, "list-units", "--all", "--plain", "--no-legend", "--no-pager",
+		"--type=service", t.Template+"@*.service")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing instances of %s: %w (stderr: %s)", t.Template, err, stderr.String())
+	}
+
+	return parseTemplateInstances(t.Template, stdout.String()), nil
+}
+
+// Each calls fn with a client for every loaded instance, collecting errors into a MultiError.
+// fn has the shape of a ClientSystemd method expression such as (*ClientSystemd).Up.
+func (t *TemplateSystemd) Each(ctx context.Context, fn func(*ClientSystemd, context.Context) error) error {
+	instances, err := t.Instances(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	for _, instance := range instances {
+		if err := fn(t.Instance(instance), ctx); err != nil {
+			errs.Add(fmt.Errorf("%s: %w", InstanceUnitName(t.Template, instance), err))
+		}
+	}
+	return errs.Err()
+}
+
+// UpAll starts every loaded instance
+func (t *TemplateSystemd) UpAll(ctx context.Context) error {
+	return t.Each(ctx, (*ClientSystemd).Up)
+}
+
+// DownAll stops every loaded instance
+func (t *TemplateSystemd) DownAll(ctx context.Context) error {
+	return t.Each(ctx, (*ClientSystemd).Down)
+}
+
+// RestartAll restarts every loaded instance
+func (t *TemplateSystemd) RestartAll(ctx context.Context) error {
+	return t.Each(ctx, (*ClientSystemd).Restart)
+}
+
+// parseTemplateInstances extracts instance names from systemctl list-units --plain output
+func parseTemplateInstances(template, output string) []string {
+	var instances []string
+	for _, line := range strings.Split(output, "\n") {
+		// Failed units are prefixed with a "●" marker column
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "●"))
+		if len(fields) == 0 {
+			continue
+		}
+		tmpl, instance, ok := ParseInstanceUnitName(fields[0])
+		if ok && tmpl == template {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
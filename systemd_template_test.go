@@ -0,0 +1,78 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseInstanceUnitName(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantTemplate string
+		wantInstance string
+		wantOK       bool
+	}{
+		{name: "getty@tty1.service", wantTemplate: "getty", wantInstance: "tty1", wantOK: true},
+		{name: "worker@queue-a", wantTemplate: "worker", wantInstance: "queue-a", wantOK: true},
+		{name: "getty@.service"},
+		{name: "nginx.service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, instance, ok := ParseInstanceUnitName(tt.name)
+			if template != tt.wantTemplate || instance != tt.wantInstance || ok != tt.wantOK {
+				t.Errorf("ParseInstanceUnitName(%q) = %q, %q, %v", tt.name, template, instance, ok)
+			}
+		})
+	}
+}
+
+func TestParseTemplateInstances(t *testing.T) {
+	output := `worker@a.service loaded active running Worker a
+worker@b.service loaded inactive dead Worker b
+● worker@c.service loaded failed failed Worker c
+workers@x.service loaded active running Other
+`
+	got := parseTemplateInstances("worker", output)
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("parseTemplateInstances() = %v, want %v", got, want)
+	}
+}
+
+func TestTemplateSystemdInstance(t *testing.T) {
+	tmpl := NewTemplateSystemd("worker")
+	tmpl.Client.WithSudo(false, "").WithUserMode(true)
+
+	c := tmpl.Instance("a")
+	if c.ServiceName != "worker@a" || !c.UserMode {
+		t.Errorf("Instance() = %+v", c)
+	}
+	if tmpl.Client.ServiceName != "worker" {
+		t.Errorf("prototype modified: %q", tmpl.Client.ServiceName)
+	}
+}
+
+func TestBuilderSystemdTemplate(t *testing.T) {
+	b := ServiceBuilderSystemd("worker", "/tmp").WithTemplate(true)
+	b.WithCmd([]string{"/bin/worker", "--queue", "%i"})
+
+	if b.UnitName() != "worker@.service" {
+		t.Errorf("UnitName() = %q", b.UnitName())
+	}
+	unit, err := b.BuildSystemdUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Description=worker service (%i)", "ExecStart=/bin/worker --queue %i"} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+	if err := b.Enable(t.Context()); err == nil {
+		t.Error("Enable on template should fail")
+	}
+}
@@ -0,0 +1,202 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransientSpec describes a command to run as a transient service unit
+type TransientSpec struct {
+	// Unit names the unit (without .service); empty picks a unique
+	// svcmgr-run-* name
+	Unit string
+	// Service supplies the command and how it runs: user and group,
+	// environment, working directory, umask, limits, nice and I/O priority,
+	// root directory and MAC labels
+	Service *ServiceBuilder
+	// Properties are further unit properties as Key=Value, e.g. "CPUQuota=50%"
+	Properties []string
+}
+
+// TransientUnit is a running transient unit started by RunTransient
+type TransientUnit struct {
+	// Unit is the unit's full name
+	Unit string
+
+	client ClientSystemd
+}
+
+// RunTransient starts spec's command as a transient service through
+// systemd-run, returning once it was executed. The command is passed to
+// systemd as an argument vector, never re-parsed from a string. A unit that
+// fails stays loaded until Wait or Stop collects it.
+func (c *ClientSystemd) RunTransient(ctx context.Context, spec TransientSpec) (*TransientUnit, error) {
+	if spec.Service == nil || len(spec.Service.config.Cmd) == 0 {
+		return nil, fmt.Errorf("command not specified")
+	}
+	name := spec.Unit
+	if name == "" {
+		name = "svcmgr-run-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	args := []string{"--unit=" + name, "--quiet", "--service-type=exec"}
+	if c.UserMode {
+		args = append([]string{"--user"}, args...)
+	}
+	args = append(args, transientArgs(spec.Service.config)...)
+	for _, prop := range spec.Properties {
+		args = append(args, "--property="+prop)
+	}
+	args = append(args, "--")
+	args = append(args, spec.Service.config.Cmd...)
+
+	cmd := c.command(ctx, c.systemdRunPath(), args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running %s: %w (stderr: %s)", name, err, stderr.String())
+	}
+
+	u := &TransientUnit{Unit: fullUnitName(name), client: *c}
+	u.client.ServiceName = strings.TrimSuffix(u.Unit, ".service")
+	return u, nil
+}
+
+// Wait waits until the unit exits, returning an error when it failed. A
+// failed unit is reset so systemd unloads it.
+func (u *TransientUnit) Wait(ctx context.Context) error {
+	var failed *StatusSystemd
+	err := pollUntil(ctx, DefaultReadyPollInterval, func() (bool, error) {
+		output, err := u.client.execSystemctl(ctx, "show", "--no-pager", "-p", "LoadState,ActiveState,SubState,Result,ExecMainStatus")
+		if err != nil {
+			return false, err
+		}
+		status := u.client.parseStatusSystemd(output)
+		switch status.ActiveState {
+		case "failed":
+			failed = status
+			return true, nil
+		case "inactive":
+			// Units that exited successfully are unloaded right away
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for %s: %w", u.Unit, err)
+	}
+	if failed != nil {
+		_, _ = u.client.execSystemctl(context.WithoutCancel(ctx), "reset-failed")
+		return fmt.Errorf("%s failed: %s (exit status %s)", u.Unit, failed.Properties["Result"], failed.Properties["ExecMainStatus"])
+	}
+	return nil
+}
+
+// Stop stops the unit and collects it
+func (u *TransientUnit) Stop(ctx context.Context) error {
+	if err := u.client.Down(ctx); err != nil {
+		return err
+	}
+	// Only needed when the unit failed; fails harmlessly otherwise
+	_, _ = u.client.execSystemctl(ctx, "reset-failed")
+	return nil
+}
+
+// transientArgs returns the systemd-run options applying a service
+// configuration to a transient unit, in the directives BuildSystemdUnit
+// writes to unit files
+func transientArgs(c *ServiceBuilderConfig) []string {
+	var args []string
+	if c.Cwd != "" {
+		args = append(args, "--working-directory="+c.Cwd)
+	}
+	// Sorted for reproducible command lines
+	keys := make([]string, 0, len(c.Env))
+	for key := range c.Env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		args = append(args, "--setenv="+key+"="+c.Env[key])
+	}
+
+	var props []string
+	for _, d := range chpstDirectives(c) {
+		if user, ok := strings.CutPrefix(d, "User="); ok {
+			args = append(args, "--uid="+user)
+		} else if group, ok := strings.CutPrefix(d, "Group="); ok {
+			args = append(args, "--gid="+group)
+		} else {
+			props = append(props, d)
+		}
+	}
+	// Builders default to 0022, which is also systemd's default
+	if c.Umask != 0 && c.Umask != 0o022 {
+		props = append(props, fmt.Sprintf("UMask=%04o", c.Umask))
+	}
+	for _, prop := range props {
+		args = append(args, "--property="+prop)
+	}
+	return args
+}
+
+// execStartArgv reads the argument vector of the service's first ExecStart
+// command from D-Bus, where it is a string array rather than the
+// space-joined rendering of systemctl show
+func (c *ClientSystemd) execStartArgv(ctx context.Context) ([]string, error) {
+	args := []string{"get-property", "--json=short", "org.freedesktop.systemd1",
+		unitObjectPath(fullUnitName(c.ServiceName)), "org.freedesktop.systemd1.Service", "ExecStart"}
+	if c.UserMode {
+		args = append([]string{"--user"}, args...)
+	}
+	path := c.BusctlPath
+	if path == "" {
+		path = DefaultBusctlPath
+	}
+	cmd := c.command(ctx, path, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("getting ExecStart: %w (stderr: %s)", err, stderr.String())
+	}
+
+	// Each command is a (path, argv, ignore_errors, timestamps..., pid,
+	// code, status) tuple
+	var prop struct {
+		Data [][]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &prop); err != nil {
+		return nil, fmt.Errorf("%w: ExecStart: %v", ErrDecode, err)
+	}
+	if len(prop.Data) == 0 || len(prop.Data[0]) < 2 {
+		return nil, fmt.Errorf("no ExecStart command found for service")
+	}
+	var argv []string
+	if err := json.Unmarshal(prop.Data[0][1], &argv); err != nil {
+		return nil, fmt.Errorf("%w: ExecStart argv: %v", ErrDecode, err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("no ExecStart command found for service")
+	}
+	return argv, nil
+}
+
+// systemdRunPath returns the configured systemd-run binary
+func (c *ClientSystemd) systemdRunPath() string {
+	if c.SystemdRunPath == "" {
+		return DefaultSystemdRunPath
+	}
+	return c.SystemdRunPath
+}
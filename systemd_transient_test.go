@@ -0,0 +1,110 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCommand writes an executable shell script named name running body
+func fakeCommand(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readArgs returns the argument lines recorded by fake commands
+func readArgs(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestRunTransient(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	c := NewClientSystemd("")
+	c.UseSudo = false
+	c.SystemdRunPath = fakeCommand(t, dir, "systemd-run", `echo "run $*" >> `+log+"\n")
+	c.SystemctlPath = fakeCommand(t, dir, "systemctl", `echo "systemctl $*" >> `+log+`
+case "$1" in
+show) printf 'LoadState=loaded\nActiveState=failed\nSubState=failed\nResult=exit-code\nExecMainStatus=3\n' ;;
+esac
+`)
+
+	b := NewServiceBuilder("report", "").
+		WithCmd([]string{"/bin/report", "--title", "two words", "$HOME"}).
+		WithEnv("B", "2").WithEnv("A", "1").
+		WithChpst(func(c *ChpstConfig) {
+			c.User = "app"
+			c.LimitFiles = 1024
+			c.Nice = 5
+		})
+	unit, err := c.RunTransient(context.Background(), TransientSpec{Unit: "report", Service: b, Properties: []string{"CPUQuota=50%"}})
+	if err != nil {
+		t.Fatalf("RunTransient: %v", err)
+	}
+	if unit.Unit != "report.service" {
+		t.Errorf("Unit = %q, want report.service", unit.Unit)
+	}
+
+	err = unit.Wait(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exit-code") || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Wait error = %v, want exit-code failure with status 3", err)
+	}
+
+	want := []string{
+		"run --unit=report --quiet --service-type=exec --setenv=A=1 --setenv=B=2 --uid=app " +
+			"--property=Nice=5 --property=LimitNOFILE=1024 --property=CPUQuota=50% -- /bin/report --title two words $HOME",
+		"systemctl show --no-pager -p LoadState,ActiveState,SubState,Result,ExecMainStatus report.service",
+		"systemctl reset-failed report.service",
+	}
+	if got := readArgs(t, log); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunTransientNoCommand(t *testing.T) {
+	c := NewClientSystemd("")
+	if _, err := c.RunTransient(context.Background(), TransientSpec{Service: NewServiceBuilder("empty", "")}); err == nil {
+		t.Error("RunTransient without a command succeeded")
+	}
+}
+
+func TestRunOnceArgv(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	c := NewClientSystemd("web")
+	c.UseSudo = false
+	c.BusctlPath = fakeCommand(t, dir, "busctl", `echo "busctl $*" >> `+log+`
+echo '{"type":"a(sasbttttuii)","data":[["/bin/web",["/bin/web","--name","a b"],false,0,0,0,0,0,0,0]]}'
+`)
+	c.SystemctlPath = fakeCommand(t, dir, "systemctl", `printf 'User=www\nGroup=\nWorkingDirectory=-/srv/web\n'`+"\n")
+	c.SystemdRunPath = fakeCommand(t, dir, "systemd-run", `printf '%s|' "$@" >> `+log+"\necho >> "+log+"\n")
+
+	if err := c.Once(context.Background()); err != nil {
+		t.Fatalf("Once: %v", err)
+	}
+
+	got := readArgs(t, log)
+	if len(got) != 2 {
+		t.Fatalf("commands = %q, want busctl and systemd-run", got)
+	}
+	if want := "busctl get-property --json=short org.freedesktop.systemd1 /org/freedesktop/systemd1/unit/web_2eservice org.freedesktop.systemd1.Service ExecStart"; got[0] != want {
+		t.Errorf("busctl args = %q, want %q", got[0], want)
+	}
+	// Arguments with spaces reach systemd-run intact
+	if !strings.HasSuffix(got[1], "--working-directory=/srv/web|--uid=www|--|/bin/web|--name|a b|") {
+		t.Errorf("systemd-run args = %q", got[1])
+	}
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables used to reach the per-user service manager
+const (
+	envXDGRuntimeDir  = "XDG_RUNTIME_DIR"
+	envXDGConfigHome  = "XDG_CONFIG_HOME"
+	envDBusSessionBus = "DBUS_SESSION_BUS_ADDRESS"
+)
+
+// UserUnitDir returns the directory for the calling user's unit files:
+// $XDG_CONFIG_HOME/systemd/user, or ~/.config/systemd/user
+func UserUnitDir() (string, error) {
+	if dir := os.Getenv(envXDGConfigHome); dir != "" {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving user unit dir: %w", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// userManagerEnv fills in XDG_RUNTIME_DIR and DBUS_SESSION_BUS_ADDRESS when
+// missing, as in cron jobs or ssh sessions without a login session,
+// so that systemctl --user can reach the user manager
+func userManagerEnv(env []string) []string {
+	runtimeDir := lookupEnv(env, envXDGRuntimeDir)
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+		env = append(env, envXDGRuntimeDir+"="+runtimeDir)
+	}
+	if lookupEnv(env, envDBusSessionBus) == "" {
+		bus := filepath.Join(runtimeDir, "bus")
+		if _, err := os.Stat(bus); !errors.Is(err, os.ErrNotExist) {
+			env = append(env, envDBusSessionBus+"=unix:path="+bus)
+		}
+	}
+	return env
+}
+
+// lookupEnv returns the value of key in a KEY=value environment list
+func lookupEnv(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(env[i], key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
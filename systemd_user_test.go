@@ -0,0 +1,110 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUserManagerEnv(t *testing.T) {
+	runtimeDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(runtimeDir, "bus"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  []string
+		want []string
+	}{
+		{
+			name: "complete",
+			env:  []string{"XDG_RUNTIME_DIR=/run/user/7", "DBUS_SESSION_BUS_ADDRESS=unix:path=/x"},
+			want: []string{"XDG_RUNTIME_DIR=/run/user/7", "DBUS_SESSION_BUS_ADDRESS=unix:path=/x"},
+		},
+		{
+			name: "missing bus",
+			env:  []string{"XDG_RUNTIME_DIR=" + runtimeDir},
+			want: []string{"XDG_RUNTIME_DIR=" + runtimeDir, "DBUS_SESSION_BUS_ADDRESS=unix:path=" + filepath.Join(runtimeDir, "bus")},
+		},
+		{
+			name: "missing runtime dir",
+			env:  []string{"PATH=/bin"},
+			want: []string{"PATH=/bin", fmt.Sprintf("XDG_RUNTIME_DIR=/run/user/%d", os.Getuid())},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := userManagerEnv(tt.env)
+			// The bus address for /run/user/<uid> depends on the host
+			got = slices.DeleteFunc(got, func(kv string) bool {
+				return strings.HasPrefix(kv, "DBUS_SESSION_BUS_ADDRESS=unix:path=/run/user/")
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("userManagerEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSystemdUserModeCommands(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/test/.config")
+
+	client := NewClientSystemd("web").WithSudo(true, "").WithUserMode(true)
+	cmd := client.systemctlCmd(t.Context(), "start", "web.service")
+	if want := []string{"systemctl", "--user", "start", "web.service"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("Args = %v, want %v", cmd.Args, want)
+	}
+
+	builder := ServiceBuilderSystemd("web", "/tmp").WithUserMode(true)
+	builder.WithCmd([]string{"/bin/web"})
+	if builder.UnitDir != "/home/test/.config/systemd/user" {
+		t.Errorf("UnitDir = %q", builder.UnitDir)
+	}
+	unit, err := builder.BuildSystemdUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(unit, "WantedBy=default.target") {
+		t.Errorf("user unit missing default.target:\n%s", unit)
+	}
+}
+
+func TestSystemdEscalator(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *ClientSystemd
+		want   []string
+	}{
+		{
+			name:   "legacy sudo",
+			client: NewClientSystemd("web").WithSudo(true, "/usr/local/bin/sudo"),
+			want:   []string{"/usr/local/bin/sudo", "systemctl", "start", "web.service"},
+		},
+		{
+			name:   "escalator overrides sudo",
+			client: NewClientSystemd("web").WithSudo(true, "").WithEscalator(Doas{}),
+			want:   []string{"doas", "systemctl", "start", "web.service"},
+		},
+		{
+			name:   "unelevated",
+			client: NewClientSystemd("web").WithSudo(false, ""),
+			want:   []string{"systemctl", "start", "web.service"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.client.systemctlCmd(t.Context(), "start", "web.service")
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("Args = %v, want %v", cmd.Args, tt.want)
+			}
+		})
+	}
+}
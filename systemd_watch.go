@@ -0,0 +1,88 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultBusctlPath is the default path to the busctl binary
+const DefaultBusctlPath = "busctl"
+
+// systemdWatchRecheck is how often Watch re-reads the status while D-Bus
+// signals drive it, in case the monitor misses a change
+const systemdWatchRecheck = 30 * time.Second
+
+// unitObjectPath returns the D-Bus object path of a unit, escaping every
+// byte other than ASCII letters and digits (and a leading digit) as _xx,
+// as systemd's bus_label_escape does
+func unitObjectPath(unit string) string {
+	var b strings.Builder
+	b.WriteString("/org/freedesktop/systemd1/unit/")
+	for i := 0; i < len(unit); i++ {
+		ch := unit[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 0 && ch >= '0' && ch <= '9' {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "_%02x", ch)
+	}
+	return b.String()
+}
+
+// monitorUnit starts busctl monitor for PropertiesChanged signals of the
+// service's unit, sending on changes (without blocking) for each signal.
+// exited is closed once busctl exits: on ctx cancellation, or early when
+// the bus refuses to monitor, as it does for unprivileged callers on the
+// system bus.
+func (c *ClientSystemd) monitorUnit(ctx context.Context, changes chan<- struct{}) (exited <-chan struct{}, err error) {
+	path := c.BusctlPath
+	if path == "" {
+		path = DefaultBusctlPath
+	}
+	if _, err := exec.LookPath(path); err != nil {
+		return nil, err
+	}
+
+	match := fmt.Sprintf("type='signal',sender='org.freedesktop.systemd1',"+
+		"interface='org.freedesktop.DBus.Properties',member='PropertiesChanged',path='%s'",
+		unitObjectPath(fullUnitName(c.ServiceName)))
+	args := []string{"monitor", "--json=short", "--match=" + match}
+	if c.UserMode {
+		args = append([]string{"--user"}, args...)
+	}
+	cmd := c.command(ctx, path, args...)
+	// Let an escalating wrapper such as sudo pass the signal on to busctl
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = time.Second
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// One JSON message per line
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for scanner.Scan() {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+		_ = cmd.Wait()
+	}()
+	return done, nil
+}
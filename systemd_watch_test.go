@@ -0,0 +1,128 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnitObjectPath(t *testing.T) {
+	tests := map[string]string{
+		"web.service":        "/org/freedesktop/systemd1/unit/web_2eservice",
+		"getty@tty1.service": "/org/freedesktop/systemd1/unit/getty_40tty1_2eservice",
+		"1password.service":  "/org/freedesktop/systemd1/unit/_31password_2eservice",
+		"my-app2.service":    "/org/freedesktop/systemd1/unit/my_2dapp2_2eservice",
+	}
+	for unit, want := range tests {
+		if got := unitObjectPath(unit); got != want {
+			t.Errorf("unitObjectPath(%q) = %q, want %q", unit, got, want)
+		}
+	}
+}
+
+// fakeSystemdWatch installs a systemctl reporting the ActiveState stored in
+// the returned state file, and a busctl that prints a signal whenever the
+// returned trigger file appears
+func fakeSystemdWatch(t *testing.T, c *ClientSystemd) (state, trigger, busArgs string) {
+	t.Helper()
+	dir := t.TempDir()
+	state = filepath.Join(dir, "state")
+	trigger = filepath.Join(dir, "trigger")
+	busArgs = filepath.Join(dir, "busargs")
+	if err := os.WriteFile(state, []byte("active"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	systemctl := filepath.Join(dir, "systemctl")
+	script := "#!/bin/sh\necho ActiveState=$(cat " + state + ")\necho SubState=running\necho MainPID=5\n"
+	if err := os.WriteFile(systemctl, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	busctl := filepath.Join(dir, "busctl")
+	script = "#!/bin/sh\necho \"$@\" > " + busArgs + "\nwhile :; do\n\tif [ -e " + trigger + " ]; then rm " + trigger + "; echo '{\"type\":\"signal\"}'; fi\n\tsleep 0.02\ndone\n"
+	if err := os.WriteFile(busctl, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c.UseSudo = false
+	c.SystemctlPath = systemctl
+	c.BusctlPath = busctl
+	return state, trigger, busArgs
+}
+
+// nextState returns the state of the next watch event
+func nextState(t *testing.T, events <-chan WatchEvent, timeout time.Duration) (State, bool) {
+	t.Helper()
+	select {
+	case ev := <-events:
+		if ev.Err != nil {
+			t.Fatalf("watch error: %v", ev.Err)
+		}
+		return ev.Status.State, true
+	case <-time.After(timeout):
+		return StateUnknown, false
+	}
+}
+
+func TestClientSystemdWatchSignals(t *testing.T) {
+	c := NewClientSystemd("web")
+	state, trigger, busArgs := fakeSystemdWatch(t, c)
+	// Polling alone would not notice changes within the test
+	c.WatchInterval = time.Hour
+
+	events, cleanup, err := c.Watch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cleanup() }()
+
+	if st, ok := nextState(t, events, 2*time.Second); !ok || st != StateRunning {
+		t.Fatalf("initial event = %v, %v; want running", st, ok)
+	}
+
+	if err := os.WriteFile(state, []byte("inactive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(trigger, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if st, ok := nextState(t, events, 2*time.Second); !ok || st != StateDown {
+		t.Fatalf("event after signal = %v, %v; want down", st, ok)
+	}
+
+	args, err := os.ReadFile(busArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "monitor") || !strings.Contains(string(args), "path='/org/freedesktop/systemd1/unit/web_2eservice'") {
+		t.Errorf("busctl args = %q", args)
+	}
+}
+
+func TestClientSystemdWatchPollsWithoutBusctl(t *testing.T) {
+	c := NewClientSystemd("web")
+	state, _, _ := fakeSystemdWatch(t, c)
+	c.BusctlPath = filepath.Join(t.TempDir(), "missing")
+	c.WatchInterval = 20 * time.Millisecond
+
+	events, cleanup, err := c.Watch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cleanup() }()
+
+	if st, ok := nextState(t, events, 2*time.Second); !ok || st != StateRunning {
+		t.Fatalf("initial event = %v, %v; want running", st, ok)
+	}
+	if err := os.WriteFile(state, []byte("inactive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if st, ok := nextState(t, events, 2*time.Second); !ok || st != StateDown {
+		t.Fatalf("polled event = %v, %v; want down", st, ok)
+	}
+}
@@ -0,0 +1,126 @@
+// Package tai64 encodes and decodes TAI64 and TAI64N timestamps, the
+// external time format of daemontools, runit and s6.
+//
+// A TAI64 label is 8 big-endian bytes counting seconds from 2^62 before
+// 1970-01-01 00:00:00 TAI; TAI64N adds 4 bytes of nanoseconds. Text labels
+// are "@" followed by the label in lower-case hex, as written by tai64n and
+// used in the names of log files rotated by svlogd and multilog:
+//
+//	@4000000065a1b2c30b3f1a44.s
+//
+// Conversions follow libtai and skalibs in treating TAI as 10 seconds ahead
+// of UTC, without a leap second table, so labels round-trip through
+// tai64nlocal.
+package tai64
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Epoch is the label of 1970-01-01 00:00:00 TAI
+	Epoch uint64 = 1 << 62
+	// UnixEpoch is the label of the Unix epoch, 1970-01-01 00:00:00 UTC
+	UnixEpoch = Epoch + 10
+
+	// Size is the length of a binary TAI64 label
+	Size = 8
+	// SizeN is the length of a binary TAI64N label
+	SizeN = 12
+)
+
+// ErrInvalid indicates a malformed label
+var ErrInvalid = errors.New("tai64: invalid label")
+
+// Label is a TAI64N timestamp
+type Label struct {
+	// Sec is the TAI64 second count
+	Sec uint64
+	// Nano is the nanosecond within the second, below 1e9
+	Nano uint32
+}
+
+// FromTime returns the label of t
+func FromTime(t time.Time) Label {
+	return Label{Sec: uint64(t.Unix()) + UnixEpoch, Nano: uint32(t.Nanosecond())}
+}
+
+// Time returns the label as a time.Time
+func (l Label) Time() time.Time {
+	return time.Unix(int64(l.Sec-UnixEpoch), int64(l.Nano))
+}
+
+// IsZero reports whether the label is all zero, which supervisors write
+// for timestamps that were never set
+func (l Label) IsZero() bool {
+	return l == Label{}
+}
+
+// Append appends the 12-byte binary TAI64N form of l to b
+func (l Label) Append(b []byte) []byte {
+	b = binary.BigEndian.AppendUint64(b, l.Sec)
+	return binary.BigEndian.AppendUint32(b, l.Nano)
+}
+
+// String returns the text TAI64N label, "@" followed by 24 hex digits
+func (l Label) String() string {
+	return "@" + hex.EncodeToString(l.Append(make([]byte, 0, SizeN)))
+}
+
+// Decode reads an 8-byte binary TAI64 label
+func Decode(b []byte) (Label, error) {
+	if len(b) != Size {
+		return Label{}, fmt.Errorf("%w: %d bytes, want %d", ErrInvalid, len(b), Size)
+	}
+	return Label{Sec: binary.BigEndian.Uint64(b)}, nil
+}
+
+// DecodeN reads a 12-byte binary TAI64N label
+func DecodeN(b []byte) (Label, error) {
+	if len(b) != SizeN {
+		return Label{}, fmt.Errorf("%w: %d bytes, want %d", ErrInvalid, len(b), SizeN)
+	}
+	l := Label{Sec: binary.BigEndian.Uint64(b), Nano: binary.BigEndian.Uint32(b[Size:])}
+	if l.Nano >= 1e9 {
+		return Label{}, fmt.Errorf("%w: %d nanoseconds", ErrInvalid, l.Nano)
+	}
+	return l, nil
+}
+
+// Parse reads a text label: "@" followed by 16 hex digits (TAI64) or 24
+// (TAI64N). The "@" is optional.
+func Parse(s string) (Label, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "@"))
+	if err != nil {
+		return Label{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	switch len(b) {
+	case Size:
+		return Decode(b)
+	case SizeN:
+		return DecodeN(b)
+	default:
+		return Label{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+}
+
+// ParseFilename reads the label from the name of a log file rotated by
+// svlogd or multilog, such as "@4000000065a1b2c30b3f1a44.s". The ".s"
+// (complete) or ".u" (unfinished) suffix is optional.
+func ParseFilename(name string) (Label, error) {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		switch name[i:] {
+		case ".s", ".u":
+			name = name[:i]
+		}
+	}
+	if !strings.HasPrefix(name, "@") {
+		return Label{}, fmt.Errorf("%w: %q", ErrInvalid, name)
+	}
+	return Parse(name)
+}
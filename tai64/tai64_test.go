@@ -0,0 +1,96 @@
+package tai64
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLabelRoundTrip(t *testing.T) {
+	tm := time.Date(2024, 1, 12, 21, 30, 11, 188684868, time.UTC)
+	l := FromTime(tm)
+	if got := l.Time(); !got.Equal(tm) {
+		t.Errorf("Time() = %v, want %v", got, tm)
+	}
+
+	got, err := DecodeN(l.Append(nil))
+	if err != nil || got != l {
+		t.Errorf("DecodeN(Append()) = %+v, %v, want %+v", got, err, l)
+	}
+
+	got, err = Parse(l.String())
+	if err != nil || got != l {
+		t.Errorf("Parse(%q) = %+v, %v, want %+v", l.String(), got, err, l)
+	}
+}
+
+func TestUnixEpoch(t *testing.T) {
+	// tai64nlocal maps this label to 1970-01-01 00:00:00 UTC
+	l, err := Parse("@400000000000000a00000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.Time().Equal(time.Unix(0, 0)) {
+		t.Errorf("Time() = %v, want the Unix epoch", l.Time())
+	}
+	if !(Label{}).IsZero() || l.IsZero() {
+		t.Error("IsZero() wrong")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Label
+		wantErr bool
+	}{
+		{in: "@4000000065a1b2c30b3f1a44", want: Label{Sec: 0x4000000065a1b2c3, Nano: 0x0b3f1a44}},
+		{in: "4000000065a1b2c30b3f1a44", want: Label{Sec: 0x4000000065a1b2c3, Nano: 0x0b3f1a44}},
+		{in: "@4000000065a1b2c3", want: Label{Sec: 0x4000000065a1b2c3}},
+		{in: "@4000000065a1b2c3ffffffff", wantErr: true},
+		{in: "@4000000065a1b2", wantErr: true},
+		{in: "@zz00000065a1b2c30b3f1a44", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("Parse() error = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Parse() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilename(t *testing.T) {
+	want := Label{Sec: 0x4000000065a1b2c3, Nano: 0x0b3f1a44}
+	for _, name := range []string{"@4000000065a1b2c30b3f1a44.s", "@4000000065a1b2c30b3f1a44.u", "@4000000065a1b2c30b3f1a44"} {
+		if got, err := ParseFilename(name); err != nil || got != want {
+			t.Errorf("ParseFilename(%q) = %+v, %v", name, got, err)
+		}
+	}
+	for _, name := range []string{"current", "lock", "4000000065a1b2c30b3f1a44.s", "@4000000065a1b2c30b3f1a44.gz"} {
+		if _, err := ParseFilename(name); err == nil {
+			t.Errorf("ParseFilename(%q) succeeded", name)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	l, err := Decode([]byte{0x40, 0, 0, 0, 0, 0, 0, 0x0a})
+	if err != nil || l != (Label{Sec: UnixEpoch}) {
+		t.Errorf("Decode() = %+v, %v", l, err)
+	}
+	if _, err := Decode(make([]byte, SizeN)); err == nil {
+		t.Error("Decode accepted a 12-byte label")
+	}
+	if _, err := DecodeN(make([]byte, Size)); err == nil {
+		t.Error("DecodeN accepted an 8-byte label")
+	}
+}
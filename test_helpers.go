@@ -73,53 +73,21 @@ func StartSupervisor(ctx context.Context, serviceType ServiceType, serviceDir st
 
 	sp.Started = true
 
-	// Wait for supervise directory to be created
-	superviseDir := filepath.Join(serviceDir, "supervise")
-	statusFile := filepath.Join(superviseDir, "status")
-	for i := 0; i < 50; i++ { // Wait up to 5 seconds
-		if _, err := os.Stat(superviseDir); err == nil {
-			// Supervise directory exists, now wait for status file
-			// Get expected size based on service type
-			var expectedSize int64
-			switch serviceType {
-			case ServiceTypeRunit:
-				expectedSize = 20
-			case ServiceTypeDaemontools:
-				expectedSize = 18
-			case ServiceTypeS6:
-				expectedSize = 35
-			default:
-				expectedSize = 20 // Default to runit size
-			}
-
-			for j := 0; j < 20; j++ { // Wait up to 2 seconds for status file
-				if info, err := os.Stat(statusFile); err == nil {
-					size := info.Size()
-					if size == expectedSize {
-						// Status file exists and has correct size
-						// Give it a tiny bit more time to ensure it's fully written
-						time.Sleep(50 * time.Millisecond)
-						return sp, nil
-					} else if size > 0 {
-						if debugEnv := os.Getenv("DEBUG_RUNIT"); debugEnv != "" {
-							fmt.Fprintf(os.Stderr, "[DEBUG] Status file %s has size %d bytes (waiting for %d)\n", statusFile, size, expectedSize)
-						}
-					}
-				}
-				time.Sleep(100 * time.Millisecond)
-			}
+	// Wait for the supervisor to write its first status file
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := WaitSupervised(waitCtx, serviceDir, serviceType); err != nil {
+		if _, statErr := os.Stat(filepath.Join(serviceDir, SuperviseDir)); statErr == nil {
 			// Supervise dir exists but no valid status file yet
 			if debugEnv := os.Getenv("DEBUG_RUNIT"); debugEnv != "" {
 				fmt.Fprintf(os.Stderr, "[DEBUG] Warning: supervise dir exists but status file not ready for %s\n", serviceDir)
 			}
 			return sp, nil
 		}
-		time.Sleep(100 * time.Millisecond)
+		_ = sp.Stop()
+		return nil, fmt.Errorf("supervisor started but supervise directory not created after 5 seconds")
 	}
-
-	// If we got here, supervise directory wasn't created
-	_ = sp.Stop()
-	return nil, fmt.Errorf("supervisor started but supervise directory not created after 5 seconds")
+	return sp, nil
 }
 
 // Stop stops the supervisor process
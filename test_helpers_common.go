@@ -2,7 +2,7 @@ package svcmgr
 
 import (
 	"context"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -225,48 +225,16 @@ func WaitForState(t *testing.T, client ServiceClient, expectedState State, timeo
 }
 
 // WaitForStatusFile waits for a valid status file to be created
+//
+// Deprecated: Use WaitSupervised, which takes a context and watches for the
+// status file instead of polling.
 func WaitForStatusFile(serviceDir string, serviceType ServiceType, timeout time.Duration) error {
-	statusFile := filepath.Join(serviceDir, "supervise", "status")
-	deadline := time.Now().Add(timeout)
-
-	// Get expected size based on service type
-	var expectedSize int64
-	switch serviceType {
-	case ServiceTypeRunit:
-		expectedSize = RunitStatusSize
-	case ServiceTypeDaemontools:
-		expectedSize = DaemontoolsStatusSize
-	case ServiceTypeS6:
-		// S6 can have two different sizes
-		expectedSize = S6StatusSizeCurrent // We'll check for both sizes
-	default:
-		expectedSize = 20 // Default to runit size
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := WaitSupervised(ctx, serviceDir, serviceType); err != nil {
+		return fmt.Errorf("status file not created within %v: %w", timeout, err)
 	}
-
-	for time.Now().Before(deadline) {
-		if info, err := os.Stat(statusFile); err == nil {
-			size := info.Size()
-			// For S6, check both possible sizes
-			if serviceType == ServiceTypeS6 {
-				if size == S6StatusSizePre220 || size == S6StatusSizeCurrent {
-					// Give it a bit more time to ensure it's fully written
-					time.Sleep(50 * time.Millisecond)
-					return nil
-				}
-			} else if size == expectedSize {
-				// Give it a bit more time to ensure it's fully written
-				time.Sleep(50 * time.Millisecond)
-				return nil
-			}
-		}
-		time.Sleep(100 * time.Millisecond)
-	}
-
-	// Check what's actually there for better error message
-	if info, err := os.Stat(statusFile); err == nil {
-		return fmt.Errorf("status file exists but has wrong size: got %d, expected %d", info.Size(), expectedSize)
-	}
-	return fmt.Errorf("status file not created within %v", timeout)
+	return nil
 }
 
 // DiagnosticInfo contains detailed diagnostic information about a service failure
@@ -284,62 +252,45 @@ type DiagnosticInfo struct {
 	LastLogLines []string
 }
 
-// CollectServiceDiagnostics gathers comprehensive diagnostic information for debugging
+// CollectServiceDiagnostics gathers diagnostic information for debugging from
+// the same checks as Doctor
+//
+// Deprecated: Use the Doctor method of ClientRunit, ClientDaemontools or
+// ClientS6, which returns a structured DoctorReport.
 func CollectServiceDiagnostics(serviceDir string, serviceType ServiceType) (*DiagnosticInfo, error) {
+	report, err := doctor(context.Background(), serviceDir, func(context.Context) (Status, error) {
+		return readStatusFile(serviceDir)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	diag := &DiagnosticInfo{
 		ServiceDir:  serviceDir,
 		ServiceType: serviceType,
+		StatusFile:  filepath.Join(serviceDir, SuperviseDir, StatusFile),
+		RunScript:   report.Run.Path,
+		LogScript:   report.LogRun.Path,
+		StatusHex:   report.StatusHex,
 	}
-
-	// Read status file
-	statusFile := filepath.Join(serviceDir, "supervise", "status")
-	diag.StatusFile = statusFile
-
-	if data, err := os.ReadFile(statusFile); err == nil {
-		diag.StatusHex = hex.Dump(data)
-	} else {
-		diag.StatusError = err
+	if report.StatusError != "" {
+		diag.StatusError = errors.New(report.StatusError)
 	}
-
-	// Read run script
-	runScript := filepath.Join(serviceDir, "run")
-	if content, err := os.ReadFile(runScript); err == nil {
+	if content, err := os.ReadFile(diag.RunScript); err == nil {
 		diag.RunContent = string(content)
 	}
-	diag.RunScript = runScript
-
-	// Read log/run script if exists
-	logScript := filepath.Join(serviceDir, "log", "run")
-	if content, err := os.ReadFile(logScript); err == nil {
+	if content, err := os.ReadFile(diag.LogScript); err == nil {
 		diag.LogContent = string(content)
 	}
-	diag.LogScript = logScript
 
-	// Try to get process information
-	switch serviceType {
-	case ServiceTypeRunit:
-		if out, err := exec.Command("sv", "status", serviceDir).CombinedOutput(); err == nil {
-			diag.ProcessInfo = string(out)
-		}
-	case ServiceTypeDaemontools:
-		if out, err := exec.Command("svstat", serviceDir).CombinedOutput(); err == nil {
-			diag.ProcessInfo = string(out)
-		}
-	case ServiceTypeS6:
-		if out, err := exec.Command("s6-svstat", serviceDir).CombinedOutput(); err == nil {
-			diag.ProcessInfo = string(out)
-		}
+	if report.Status != nil {
+		diag.ProcessInfo = fmt.Sprintf("%s: pid %d, since %s\n", report.Status.State, report.Status.PID, report.Status.Since.Format(time.RFC3339))
 	}
-
-	// Try to read last log lines if log exists
-	logFile := filepath.Join(serviceDir, "log", "current")
-	if content, err := os.ReadFile(logFile); err == nil {
-		lines := strings.Split(string(content), "\n")
-		if len(lines) > 20 {
-			diag.LastLogLines = lines[len(lines)-20:]
-		} else {
-			diag.LastLogLines = lines
-		}
+	for _, p := range report.Problems {
+		diag.ProcessInfo += "problem: " + p + "\n"
+	}
+	for _, line := range report.LogLines {
+		diag.LastLogLines = append(diag.LastLogLines, line.Text)
 	}
 
 	return diag, nil
@@ -0,0 +1,88 @@
+package svcmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// pidStartSlack is how much later than Status.Since a process may have
+// started and still be the one the status file names; it covers the
+// one-second resolution of the boot time and the gap between fork and the
+// supervisor's status write
+const pidStartSlack = 2 * time.Second
+
+// supervisorNames are the command names of the per-service supervisors
+var supervisorNames = []string{"runsv", "supervise", "s6-supervise"}
+
+// verifyPID clears a PID that names no process, or a process started after
+// the status was recorded (the PID was reused), and downgrades the state
+// claiming it: to StateCrashed when the service wants to be up, otherwise
+// to StateUnknown, since the record no longer describes the service. Start
+// times are read from procDir when it is available.
+func verifyPID(st Status, serviceDir, procDir string) Status {
+	if st.PID <= 0 || (processExists(st.PID) && !pidReused(st, serviceDir, procDir)) {
+		return st
+	}
+	st.PID = 0
+	if st.Flags.WantUp {
+		st.State = StateCrashed
+	} else {
+		st.State = StateUnknown
+	}
+	return st
+}
+
+// processExists reports whether pid names a process; EPERM means it exists
+// but belongs to another user
+func processExists(pid int) bool {
+	return !errors.Is(syscall.Kill(pid, 0), syscall.ESRCH)
+}
+
+// pidReused reports whether st.PID now names a process that started after
+// st.Since and is not a child of the supervisor of serviceDir; without a
+// proc filesystem it cannot tell and reports false.
+//
+// The start time alone is no proof: it is derived from the boot time,
+// which moves whenever the wall clock is stepped, as NTP does after boot on
+// machines without a hardware clock, making every process started before
+// the step look late.
+func pidReused(st Status, serviceDir, procDir string) bool {
+	if st.Since.IsZero() {
+		return false
+	}
+	started, err := processStartTime(procDir, st.PID)
+	if err != nil || !started.After(st.Since.Add(pidStartSlack)) {
+		return false
+	}
+	return !supervisedBy(procDir, st.PID, serviceDir)
+}
+
+// supervisedBy reports whether the parent of pid is the supervisor of
+// serviceDir, which runs in the service directory. When its working
+// directory cannot be read, as for another user's process, a parent named
+// like a supervisor is accepted.
+func supervisedBy(procDir string, pid int, serviceDir string) bool {
+	_, fields, err := readProcStat(procDir, pid)
+	if err != nil {
+		return false
+	}
+	ppid, err := strconv.Atoi(fields[procStatPPID])
+	if err != nil || ppid <= 1 {
+		return false
+	}
+	cwd, err := os.Readlink(filepath.Join(procDir, strconv.Itoa(ppid), "cwd"))
+	if err != nil {
+		name, _, err := readProcStat(procDir, ppid)
+		return err == nil && slices.Contains(supervisorNames, name)
+	}
+	dir, err := filepath.EvalSymlinks(serviceDir)
+	if err != nil {
+		dir, _ = filepath.Abs(serviceDir)
+	}
+	return cwd == dir
+}
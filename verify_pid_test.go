@@ -0,0 +1,144 @@
+//go:build linux
+
+package svcmgr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// deadPID returns the PID of a process that has exited and been reaped
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run true: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestVerifyPID(t *testing.T) {
+	dead := deadPID(t)
+	tests := []struct {
+		name      string
+		in        Status
+		wantState State
+		wantPID   int
+	}{
+		{"alive", Status{State: StateRunning, PID: os.Getpid(), Since: time.Now(), Flags: Flags{WantUp: true}}, StateRunning, os.Getpid()},
+		{"no pid", Status{State: StateDown}, StateDown, 0},
+		{"dead, wants up", Status{State: StateRunning, PID: dead, Flags: Flags{WantUp: true}}, StateCrashed, 0},
+		{"dead, wants down", Status{State: StateStopping, PID: dead}, StateUnknown, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := verifyPID(tt.in, "", DefaultProcDir)
+			if got.State != tt.wantState || got.PID != tt.wantPID {
+				t.Errorf("verifyPID = %v pid %d, want %v pid %d", got.State, got.PID, tt.wantState, tt.wantPID)
+			}
+		})
+	}
+}
+
+func TestVerifyPIDReused(t *testing.T) {
+	// A proc filesystem booted at 1000 where this process started 5s later
+	procDir := t.TempDir()
+	pid := os.Getpid()
+	if err := os.WriteFile(filepath.Join(procDir, "stat"), []byte("cpu  1 2 3\nbtime 1000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeStat := func(pid, ppid int, comm string) {
+		t.Helper()
+		fields := make([]string, 25)
+		for i := range fields {
+			fields[i] = "0"
+		}
+		fields[procStatPPID] = strconv.Itoa(ppid)
+		fields[procStatStart] = "500"
+		stat := fmt.Sprintf("%d (%s) %s\n", pid, comm, strings.Join(fields, " "))
+		if err := os.MkdirAll(filepath.Join(procDir, strconv.Itoa(pid)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"), []byte(stat), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Parents: the service's supervisor, running in the service directory,
+	// one whose working directory is unreadable, and another service's
+	serviceDir := t.TempDir()
+	writeStat(100, 1, "runsv")
+	if err := os.Symlink(serviceDir, filepath.Join(procDir, "100", "cwd")); err != nil {
+		t.Fatal(err)
+	}
+	writeStat(200, 1, "runsv")
+	writeStat(300, 1, "runsv")
+	if err := os.Symlink(t.TempDir(), filepath.Join(procDir, "300", "cwd")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		since     time.Time
+		parent    int
+		wantState State
+	}{
+		{"started with the service", time.Unix(1005, 0), 0, StateRunning},
+		{"started within the slack", time.Unix(1004, 0), 0, StateRunning},
+		{"started long after", time.Unix(1001, 0), 0, StateCrashed},
+		{"no since", time.Time{}, 0, StateRunning},
+		// A clock stepped forward after boot moves the boot time, so the
+		// start time looks late for a process the supervisor still runs
+		{"clock stepped forward", time.Unix(1001, 0), 100, StateRunning},
+		{"supervisor working directory unreadable", time.Unix(1001, 0), 200, StateRunning},
+		{"child of another supervisor", time.Unix(1001, 0), 300, StateCrashed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeStat(pid, tt.parent, "svc")
+			in := Status{State: StateRunning, PID: pid, Since: tt.since, Flags: Flags{WantUp: true}}
+			if got := verifyPID(in, serviceDir, procDir); got.State != tt.wantState {
+				t.Errorf("verifyPID = %v, want %v", got.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestWithVerifyPID(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "svc")
+	mock, err := NewMockSupervisor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.UpdateStatus(true, deadPID(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := NewClient(dir, WithServiceType(ServiceTypeRunit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st, err := plain.Status(ctx); err != nil || st.State != StateRunning {
+		t.Fatalf("Status without verification = %v, %v; want running", st.State, err)
+	}
+
+	verified, err := NewClient(dir, WithServiceType(ServiceTypeRunit), WithVerifyPID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := verified.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != StateCrashed || st.PID != 0 {
+		t.Errorf("Status with verification = %v pid %d, want crashed pid 0", st.State, st.PID)
+	}
+}
@@ -0,0 +1,181 @@
+package svcmgr
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WaitProgress is an intermediate status seen while waiting
+type WaitProgress struct {
+	// Service is the key of the service in WaitAll or its directory in
+	// WaitFor, empty for WaitWithProgress
+	Service string
+	// Status is the status just read
+	Status Status
+	// Previous is the state before this one; equal to Status.State for
+	// polls without a transition
+	Previous State
+	// Elapsed is the time since the wait started
+	Elapsed time.Duration
+}
+
+// WaitOption configures WaitWithProgress, WaitAll and WaitFor
+type WaitOption func(*waitConfig)
+
+// waitConfig holds the callbacks set by WaitOptions
+type waitConfig struct {
+	onPoll       func(WaitProgress)
+	onTransition func(WaitProgress)
+	start        time.Time
+	mu           sync.Mutex // serializes callbacks across concurrent waits
+}
+
+// newWaitConfig applies opts to a config starting now
+func newWaitConfig(opts []WaitOption) *waitConfig {
+	cfg := &waitConfig{start: time.Now()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// OnPoll calls fn with every status read while waiting: on each change and
+// at least once per second, so CLIs can animate a spinner or log progress
+// instead of appearing hung until the deadline
+func OnPoll(fn func(WaitProgress)) WaitOption {
+	return func(c *waitConfig) {
+		c.onPoll = fn
+	}
+}
+
+// OnTransition calls fn whenever the state changes while waiting, and once
+// with the initial state
+func OnTransition(fn func(WaitProgress)) WaitOption {
+	return func(c *waitConfig) {
+		c.onTransition = fn
+	}
+}
+
+// tracker wraps a wait predicate to report progress to the callbacks
+type tracker struct {
+	cfg     *waitConfig
+	service string
+	seen    bool
+	prev    State
+}
+
+// observe reports st to the callbacks
+func (t *tracker) observe(st Status) {
+	p := WaitProgress{Service: t.service, Status: st, Previous: t.prev, Elapsed: time.Since(t.cfg.start)}
+	if !t.seen {
+		p.Previous = st.State
+	}
+	transition := !t.seen || st.State != t.prev
+	t.seen, t.prev = true, st.State
+
+	t.cfg.mu.Lock()
+	defer t.cfg.mu.Unlock()
+	if t.cfg.onPoll != nil {
+		t.cfg.onPoll(p)
+	}
+	if transition && t.cfg.onTransition != nil {
+		t.cfg.onTransition(p)
+	}
+}
+
+// waitStates blocks until client reaches one of states, or changes at all
+// when states is empty, reporting progress through t. A client that can
+// neither wait for a predicate nor read its status only has its final
+// status reported.
+func waitStates(ctx context.Context, client ReadinessWaiter, states []State, t *tracker) (Status, error) {
+	var initial *Status
+	pred := func(st Status) bool {
+		t.observe(st)
+		if len(states) > 0 {
+			return slices.Contains(states, st.State)
+		}
+		if initial == nil {
+			initial = &st
+			return false
+		}
+		return !initial.Equal(st)
+	}
+	switch c := client.(type) {
+	case FuncWaiter:
+		return c.WaitFunc(ctx, pred)
+	case StatusReader:
+		return waitFunc(ctx, c, pred)
+	}
+	st, err := client.Wait(ctx, states)
+	if err == nil {
+		t.observe(st)
+	}
+	return st, err
+}
+
+// waitFunc blocks until pred holds for the status of client, through its
+// WaitFunc when it is a FuncWaiter, otherwise by polling Status every
+// DefaultReadyPollInterval. A failed status read ends the wait.
+func waitFunc(ctx context.Context, client StatusReader, pred func(Status) bool) (Status, error) {
+	if fw, ok := client.(FuncWaiter); ok {
+		return fw.WaitFunc(ctx, pred)
+	}
+	var st Status
+	err := pollUntil(ctx, DefaultReadyPollInterval, func() (bool, error) {
+		cur, err := client.Status(ctx)
+		if err != nil {
+			return false, err
+		}
+		st = cur
+		return pred(cur), nil
+	})
+	return st, err
+}
+
+// WaitWithProgress is ReadinessWaiter.Wait with progress reporting: it
+// blocks until the service reaches one of states, or its status changes at
+// all when states is empty, calling the OnPoll and OnTransition callbacks of
+// opts along the way.
+//
+// Example:
+//
+//	st, err := svcmgr.WaitWithProgress(ctx, client, []svcmgr.State{svcmgr.StateRunning},
+//		svcmgr.OnTransition(func(p svcmgr.WaitProgress) {
+//			log.Printf("%v -> %v after %v", p.Previous, p.Status.State, p.Elapsed)
+//		}))
+func WaitWithProgress(ctx context.Context, client ReadinessWaiter, states []State, opts ...WaitOption) (Status, error) {
+	return waitStates(ctx, client, states, &tracker{cfg: newWaitConfig(opts)})
+}
+
+// WaitAll waits concurrently for every client to reach one of states, as
+// WaitWithProgress does, returning the statuses reached by service key.
+// Progress callbacks carry the key in WaitProgress.Service; calls are
+// serialized. Services that fail or do not get there before ctx is done are
+// reported in a MultiError.
+func WaitAll(ctx context.Context, clients map[string]ReadinessWaiter, states []State, opts ...WaitOption) (map[string]Status, error) {
+	cfg := newWaitConfig(opts)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	statuses := make(map[string]Status, len(clients))
+	merr := &MultiError{}
+	for name, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st, err := waitStates(ctx, client, states, &tracker{cfg: cfg, service: name})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				merr.Add(&OpError{Op: OpStatus, Path: name, Err: err})
+				return
+			}
+			statuses[name] = st
+		}()
+	}
+	wg.Wait()
+	return statuses, merr.Err()
+}
@@ -0,0 +1,155 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// seqWaiter feeds a fixed sequence of statuses to WaitFunc predicates,
+// then blocks until ctx is done
+type seqWaiter struct {
+	seq []Status
+}
+
+func (w *seqWaiter) Wait(ctx context.Context, states []State) (Status, error) {
+	return w.WaitFunc(ctx, func(st Status) bool { return slices.Contains(states, st.State) })
+}
+
+func (w *seqWaiter) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	for _, st := range w.seq {
+		if pred(st) {
+			return st, nil
+		}
+	}
+	<-ctx.Done()
+	return Status{}, ctx.Err()
+}
+
+func TestWaitWithProgress(t *testing.T) {
+	w := &seqWaiter{seq: []Status{
+		{State: StateDown},
+		{State: StateStarting},
+		{State: StateStarting, PID: 10},
+		{State: StateRunning, PID: 10},
+		{State: StateDown},
+	}}
+
+	var polls int
+	var transitions []WaitProgress
+	st, err := WaitWithProgress(context.Background(), w, []State{StateRunning},
+		OnPoll(func(WaitProgress) { polls++ }),
+		OnTransition(func(p WaitProgress) { transitions = append(transitions, p) }))
+	if err != nil {
+		t.Fatalf("WaitWithProgress() error = %v", err)
+	}
+	if st.State != StateRunning || st.PID != 10 {
+		t.Errorf("WaitWithProgress() = %+v, want running with PID 10", st)
+	}
+	if polls != 4 {
+		t.Errorf("OnPoll called %d times, want 4", polls)
+	}
+
+	want := []struct{ prev, state State }{
+		{StateDown, StateDown},
+		{StateDown, StateStarting},
+		{StateStarting, StateRunning},
+	}
+	if len(transitions) != len(want) {
+		t.Fatalf("OnTransition called %d times, want %d", len(transitions), len(want))
+	}
+	for i, w := range want {
+		if got := transitions[i]; got.Previous != w.prev || got.Status.State != w.state {
+			t.Errorf("transition %d = %v -> %v, want %v -> %v", i, got.Previous, got.Status.State, w.prev, w.state)
+		}
+	}
+}
+
+func TestWaitWithProgressAnyChange(t *testing.T) {
+	w := &seqWaiter{seq: []Status{
+		{State: StateRunning, PID: 10},
+		{State: StateRunning, PID: 10},
+		{State: StateRunning, PID: 11},
+	}}
+	st, err := WaitWithProgress(context.Background(), w, nil)
+	if err != nil {
+		t.Fatalf("WaitWithProgress() error = %v", err)
+	}
+	if st.PID != 11 {
+		t.Errorf("WaitWithProgress() PID = %d, want 11", st.PID)
+	}
+}
+
+func TestWaitAll(t *testing.T) {
+	clients := map[string]ReadinessWaiter{
+		"web": &seqWaiter{seq: []Status{{State: StateStarting}, {State: StateRunning, PID: 1}}},
+		"db":  &seqWaiter{seq: []Status{{State: StateRunning, PID: 2}}},
+		"job": &seqWaiter{seq: []Status{{State: StateCrashed}}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	statuses, err := WaitAll(ctx, clients, []State{StateRunning},
+		OnPoll(func(p WaitProgress) {
+			mu.Lock()
+			seen[p.Service]++
+			mu.Unlock()
+		}))
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 1 {
+		t.Fatalf("WaitAll() error = %v, want MultiError with one error", err)
+	}
+	var opErr *OpError
+	if !errors.As(merr.Errors[0], &opErr) || opErr.Path != "job" || !errors.Is(opErr, context.DeadlineExceeded) {
+		t.Errorf("WaitAll() error = %v, want deadline for job", merr.Errors[0])
+	}
+	if len(statuses) != 2 || statuses["web"].PID != 1 || statuses["db"].PID != 2 {
+		t.Errorf("WaitAll() statuses = %+v", statuses)
+	}
+	if seen["web"] != 2 || seen["db"] != 1 || seen["job"] != 1 {
+		t.Errorf("OnPoll calls by service = %v", seen)
+	}
+}
+
+// seqReader returns a fixed sequence of statuses from Status, repeating the
+// last one; it has no WaitFunc
+type seqReader struct {
+	mu  sync.Mutex
+	seq []Status
+}
+
+func (r *seqReader) Status(context.Context) (Status, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.seq[0]
+	if len(r.seq) > 1 {
+		r.seq = r.seq[1:]
+	}
+	return st, nil
+}
+
+func (r *seqReader) Wait(ctx context.Context, states []State) (Status, error) {
+	return waitFunc(ctx, r, func(st Status) bool { return slices.Contains(states, st.State) })
+}
+
+func TestWaitWithProgressPollsStatus(t *testing.T) {
+	r := &seqReader{seq: []Status{{State: StateDown}, {State: StateStarting}, {State: StateRunning, PID: 10}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var transitions int
+	st, err := WaitWithProgress(ctx, r, []State{StateRunning},
+		OnTransition(func(WaitProgress) { transitions++ }))
+	if err != nil {
+		t.Fatalf("WaitWithProgress() error = %v", err)
+	}
+	if st.PID != 10 || transitions != 3 {
+		t.Errorf("WaitWithProgress() = %+v after %d transitions, want PID 10 after 3", st, transitions)
+	}
+}
@@ -26,3 +26,23 @@ func (c *ClientS6) Wait(ctx context.Context, states []State) (Status, error) {
 func (c *ClientSystemd) Wait(ctx context.Context, states []State) (Status, error) {
 	return Status{}, errors.New("wait not supported on this platform")
 }
+
+// WaitFunc for ClientRunit - not supported on this platform
+func (c *ClientRunit) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return Status{}, errors.New("wait not supported on this platform")
+}
+
+// WaitFunc for ClientDaemontools - not supported on this platform
+func (c *ClientDaemontools) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return Status{}, errors.New("wait not supported on this platform")
+}
+
+// WaitFunc for ClientS6 - not supported on this platform
+func (c *ClientS6) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return Status{}, errors.New("wait not supported on this platform")
+}
+
+// WaitFunc for ClientSystemd - not supported on this platform
+func (c *ClientSystemd) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return Status{}, errors.New("wait not supported on this platform")
+}
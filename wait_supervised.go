@@ -0,0 +1,110 @@
+package svcmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// waitSupervisedRecheck is how often WaitSupervised checks again between
+// file events, for supervise symlinks whose target appears later
+const waitSupervisedRecheck = time.Second
+
+// WaitSupervised blocks until a supervisor has taken over the service in
+// serviceDir: its supervise directory exists and holds a status file that
+// decodes as serviceType's format (any format for ServiceTypeUnknown). It
+// watches the service and supervise directories instead of polling, so it
+// returns as soon as the status file is written, and falls back to polling
+// where watching is unavailable.
+//
+// Example:
+//
+//	if err := svcmgr.EnableService(dir, "/etc/service", "web"); err != nil {
+//		return err
+//	}
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+//	defer cancel()
+//	if err := svcmgr.WaitSupervised(ctx, "/etc/service/web", svcmgr.ServiceTypeRunit); err != nil {
+//		return err
+//	}
+func WaitSupervised(ctx context.Context, serviceDir string, serviceType ServiceType) error {
+	superviseDir := filepath.Join(serviceDir, SuperviseDir)
+	statusPath := filepath.Join(superviseDir, StatusFile)
+
+	// The supervisor creates the supervise directory before the status file
+	err := waitFileEvent(ctx, serviceDir, SuperviseDir, func() (bool, error) {
+		info, err := os.Stat(superviseDir)
+		return err == nil && info.IsDir(), nil
+	})
+	if err != nil {
+		return &OpError{Op: OpStatus, Path: superviseDir, Err: err}
+	}
+
+	err = waitFileEvent(ctx, superviseDir, StatusFile, func() (bool, error) {
+		return validStatusFile(statusPath, serviceType), nil
+	})
+	if err != nil {
+		return &OpError{Op: OpStatus, Path: statusPath, Err: err}
+	}
+	return nil
+}
+
+// validStatusFile reports whether path holds a complete status record in
+// serviceType's format
+func validStatusFile(path string, serviceType ServiceType) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	switch serviceType {
+	case ServiceTypeRunit:
+		_, err = DecodeStatusRunit(data)
+	case ServiceTypeDaemontools:
+		_, err = DecodeStatusDaemontools(data)
+	case ServiceTypeS6:
+		_, err = DecodeStatusS6(data)
+	default:
+		_, err = decodeStatusFile(data)
+	}
+	return err == nil
+}
+
+// waitFileEvent calls check whenever the file name in dir changes, and at
+// least every waitSupervisedRecheck, until it reports true, fails, or ctx
+// ends. Without file watching, it polls every DefaultReadyPollInterval.
+func waitFileEvent(ctx context.Context, dir, name string, check func() (bool, error)) error {
+	if done, err := check(); done || err != nil {
+		return err
+	}
+
+	watcher, err := newFileWatcher(dir, name)
+	if err != nil {
+		// Also the case while dir itself does not exist yet
+		return pollUntil(ctx, DefaultReadyPollInterval, check)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	ticker := time.NewTicker(waitSupervisedRecheck)
+	defer ticker.Stop()
+
+	for {
+		// Checked after the watch was added, so no change is missed
+		if done, err := check(); done || err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-watcher.Changes():
+			if !ok {
+				return pollUntil(ctx, DefaultReadyPollInterval, check)
+			}
+		case <-watcher.Errors():
+			return pollUntil(ctx, DefaultReadyPollInterval, check)
+		case <-ticker.C:
+		}
+	}
+}
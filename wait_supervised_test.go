@@ -0,0 +1,80 @@
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitSupervised(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType ServiceType
+		record      []byte
+		wantErr     bool
+	}{
+		{"runit", ServiceTypeRunit, makeStatusData(42, 'u', 0, 1), false},
+		{"any format", ServiceTypeUnknown, makeStatusData(42, 'u', 0, 1), false},
+		{"daemontools", ServiceTypeDaemontools, make([]byte, DaemontoolsStatusSize), false},
+		{"wrong format", ServiceTypeDaemontools, makeStatusData(42, 'u', 0, 1), true},
+		{"partial record", ServiceTypeUnknown, make([]byte, 7), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			supervise := filepath.Join(dir, SuperviseDir)
+
+			// Play the supervisor: create supervise, then write status
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				if err := os.Mkdir(supervise, 0o755); err != nil {
+					return
+				}
+				time.Sleep(20 * time.Millisecond)
+				tmp := filepath.Join(supervise, StatusFile+".new")
+				if err := os.WriteFile(tmp, tt.record, 0o644); err != nil {
+					return
+				}
+				_ = os.Rename(tmp, filepath.Join(supervise, StatusFile))
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			err := WaitSupervised(ctx, dir, tt.serviceType)
+			if tt.wantErr {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("WaitSupervised = %v, want deadline exceeded", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WaitSupervised: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+				t.Errorf("WaitSupervised took %v after the status file appeared", elapsed)
+			}
+		})
+	}
+}
+
+func TestWaitSupervisedAlreadySupervised(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, SuperviseDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SuperviseDir, StatusFile), makeStatusData(1, 'u', 0, 1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Returns without waiting even when ctx is done
+	if err := WaitSupervised(ctx, dir, ServiceTypeRunit); err != nil {
+		t.Errorf("WaitSupervised: %v", err)
+	}
+}
@@ -96,3 +96,46 @@ func TestWaitNilSafety(t *testing.T) {
 
 	// Test passed if we didn't panic
 }
+
+// TestWaitFunc verifies that WaitFunc returns once the predicate matches
+func TestWaitFunc(t *testing.T) {
+	serviceDir, mock, cleanup, err := CreateMockService("test-wait-func", ConfigRunit())
+	if err != nil {
+		t.Fatalf("Failed to create mock service: %v", err)
+	}
+	defer cleanup()
+
+	client, err := NewClient(serviceDir, ServiceTypeRunit)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	fw, ok := client.(FuncWaiter)
+	if !ok {
+		t.Fatalf("%T does not implement FuncWaiter", client)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = mock.UpdateStatus(true, 4321)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	status, err := fw.WaitFunc(ctx, func(s Status) bool {
+		return s.State == StateRunning && s.PID == 4321
+	})
+	if err != nil {
+		t.Fatalf("WaitFunc: %v", err)
+	}
+	if status.PID != 4321 {
+		t.Errorf("Expected PID 4321, got %d", status.PID)
+	}
+
+	// A predicate that never matches ends with the context
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	if _, err := fw.WaitFunc(ctx2, func(Status) bool { return false }); err == nil {
+		t.Error("Expected context error from unsatisfiable WaitFunc")
+	}
+}
@@ -1,7 +1,96 @@
 package svcmgr
 
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
 // WatchEvent represents a status change event from watching a service
 type WatchEvent struct {
 	Status Status
 	Err    error
+	// Dropped counts the events the watch discarded before this one under
+	// its overflow policy (see WithWatchBuffer); it only grows, so a change
+	// between events reveals a gap
+	Dropped uint64
+}
+
+// watchAll watches every client, keyed by service name, calling fn from one
+// goroutine per service with the status before each event (the status read
+// when watching started, then the last event's status) until ctx is done.
+// It returns ctx.Err(), the first error from starting a watch, or
+// ErrWatchClosed if every watch ended on its own first.
+func watchAll(ctx context.Context, clients map[string]ServiceClient, fn func(name string, prev Status, ev WatchEvent)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for name, client := range clients {
+		events, stop, err := client.Watch(ctx)
+		if err != nil {
+			cancel()
+			wg.Wait()
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		prev, _ := client.Status(ctx)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = stop() }()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ev, ok := <-events:
+					if !ok {
+						return
+					}
+					fn(name, prev, ev)
+					if ev.Err == nil {
+						prev = ev.Status
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrWatchClosed
+}
+
+// WatchMany watches every client, keyed by service name, calling fn with
+// each service's events until ctx is done. Calls to fn are serialized, so
+// it may update shared state without locking. It returns ctx.Err(), the
+// first error from starting a watch, or ErrWatchClosed if every watch ended
+// before ctx was done.
+//
+// Example:
+//
+//	err := svcmgr.WatchMany(ctx, clients, func(name string, ev svcmgr.WatchEvent) {
+//		fmt.Println(name, ev.Status.State)
+//	})
+func WatchMany(ctx context.Context, clients map[string]ServiceClient, fn func(name string, ev WatchEvent)) error {
+	var mu sync.Mutex
+	return watchAll(ctx, clients, func(name string, _ Status, ev WatchEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn(name, ev)
+	})
+}
+
+// fileWatcher reports changes to one file in a directory, such as the
+// status file of a supervise directory. It is backed by fsnotify, or by raw
+// inotify syscalls when built with the svcmgr_inotify tag on Linux.
+type fileWatcher interface {
+	// Changes receives a value when the file may have changed;
+	// changes arriving before the previous one was received are coalesced
+	Changes() <-chan struct{}
+	// Errors receives watch errors
+	Errors() <-chan error
+	// Close stops watching and closes both channels
+	Close() error
 }
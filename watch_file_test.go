@@ -0,0 +1,80 @@
+//go:build linux || darwin
+
+package svcmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Runs against fsnotify by default and raw inotify with -tags svcmgr_inotify
+func TestFileWatcher(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newFileWatcher(dir, StatusFile)
+	if err != nil {
+		t.Fatalf("newFileWatcher: %v", err)
+	}
+
+	expectChange := func(what string) {
+		t.Helper()
+		select {
+		case _, ok := <-w.Changes():
+			if !ok {
+				t.Fatalf("%s: changes closed", what)
+			}
+		case err := <-w.Errors():
+			t.Fatalf("%s: watch error: %v", what, err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: no change reported", what)
+		}
+	}
+
+	// Other files are not reported
+	if err := os.WriteFile(filepath.Join(dir, "pid"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Changes():
+		t.Fatal("change reported for another file")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	status := filepath.Join(dir, StatusFile)
+	if err := os.WriteFile(status, make([]byte, StatusFileSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectChange("write")
+
+	// Drain coalesced events from the write before testing the rename
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-w.Changes():
+	default:
+	}
+
+	tmp := filepath.Join(dir, StatusFile+".new")
+	if err := os.WriteFile(tmp, make([]byte, StatusFileSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, status); err != nil {
+		t.Fatal(err)
+	}
+	expectChange("rename")
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-w.Changes():
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("changes not closed after Close")
+		}
+	}
+}
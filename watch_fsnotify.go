@@ -0,0 +1,47 @@
+//go:build darwin || (linux && !svcmgr_inotify)
+
+package svcmgr
+
+import (
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsnotifyWatcher is the default fileWatcher
+type fsnotifyWatcher struct {
+	w       *fsnotify.Watcher
+	changes chan struct{}
+}
+
+// newFileWatcher watches the file name in dir
+func newFileWatcher(dir, name string) (fileWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(dir); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+
+	fw := &fsnotifyWatcher{w: w, changes: make(chan struct{}, 1)}
+	go func() {
+		defer close(fw.changes)
+		for event := range w.Events {
+			if filepath.Base(event.Name) == name {
+				select {
+				case fw.changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return fw, nil
+}
+
+func (fw *fsnotifyWatcher) Changes() <-chan struct{} { return fw.changes }
+
+func (fw *fsnotifyWatcher) Errors() <-chan error { return fw.w.Errors }
+
+func (fw *fsnotifyWatcher) Close() error { return fw.w.Close() }
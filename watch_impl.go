@@ -8,7 +8,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"vawter.tech/stopper"
 )
 
@@ -17,13 +16,14 @@ type watchClient interface {
 	ServiceClient
 	getServiceDir() string
 	getStatusFileSize() int
+	getWatchBuffer() (int, WatchOverflow)
 }
 
 // watchState manages the state of a watch operation
 type watchState struct {
 	mu              sync.Mutex
 	lastRaw         []byte
-	debouncer       *time.Timer
+	debouncer       *debouncer
 	spinStartTime   time.Time
 	spinCount       int
 	backoffInterval time.Duration
@@ -35,17 +35,13 @@ type watchState struct {
 func watchImpl(ctx context.Context, client watchClient) (<-chan WatchEvent, WatchCleanupFunc, error) {
 	superviseDir := filepath.Join(client.getServiceDir(), SuperviseDir)
 
-	watcher, err := fsnotify.NewWatcher()
+	watcher, err := newFileWatcher(superviseDir, StatusFile)
 	if err != nil {
 		return nil, nil, &OpError{Op: OpStatus, Path: superviseDir, Err: err}
 	}
 
-	if err := watcher.Add(superviseDir); err != nil {
-		_ = watcher.Close()
-		return nil, nil, &OpError{Op: OpStatus, Path: superviseDir, Err: err}
-	}
-
-	ch := make(chan WatchEvent, 10)
+	sender := newWatchSender(client.getWatchBuffer())
+	ch := sender.ch
 
 	// Create stopper context for managing goroutine lifecycle
 	sctx := stopper.WithContext(ctx)
@@ -77,10 +73,7 @@ func watchImpl(ctx context.Context, client watchClient) (<-chan WatchEvent, Watc
 		status, err := client.Status(ctx)
 		if err != nil {
 			if !sctx.IsStopping() {
-				select {
-				case ch <- WatchEvent{Err: err}:
-				case <-sctx.Stopping():
-				}
+				sender.send(WatchEvent{Err: err}, sctx.Stopping())
 			}
 			return
 		}
@@ -113,10 +106,7 @@ func watchImpl(ctx context.Context, client watchClient) (<-chan WatchEvent, Watc
 			state.backoffInterval = 0
 
 			if !sctx.IsStopping() {
-				select {
-				case ch <- WatchEvent{Status: status}:
-				case <-sctx.Stopping():
-				}
+				sender.send(WatchEvent{Status: status}, sctx.Stopping())
 			}
 		} else {
 			// Track spinning behavior
@@ -135,55 +125,44 @@ func watchImpl(ctx context.Context, client watchClient) (<-chan WatchEvent, Watc
 		}
 	}
 
+	state.debouncer = newDebouncer(readAndSend)
+
 	// Initial read
 	readAndSend()
 
 	// Launch watcher goroutine using stopper
 	sctx.Go(func(sctx *stopper.Context) error {
 		// Register debouncer cleanup
-		sctx.Defer(func() {
-			state.mu.Lock()
-			if state.debouncer != nil {
-				state.debouncer.Stop()
-			}
-			state.mu.Unlock()
-		})
+		sctx.Defer(state.debouncer.stop)
 
 		for !sctx.IsStopping() {
 			select {
 			case <-sctx.Stopping():
 				return nil
 
-			case event, ok := <-watcher.Events:
+			case _, ok := <-watcher.Changes():
 				if !ok {
 					return nil
 				}
 
-				if filepath.Base(event.Name) == StatusFile {
-					state.mu.Lock()
-
-					// If in backoff mode, use longer debounce
-					debounceTime := 10 * time.Millisecond
-					if state.backoffInterval > 0 {
-						debounceTime = state.backoffInterval
-					}
+				state.mu.Lock()
 
-					// Cancel existing debouncer
-					if state.debouncer != nil {
-						state.debouncer.Stop()
-					}
-					state.debouncer = time.AfterFunc(debounceTime, readAndSend)
-					state.mu.Unlock()
+				// If in backoff mode, use longer debounce
+				debounceTime := 10 * time.Millisecond
+				if state.backoffInterval > 0 {
+					debounceTime = state.backoffInterval
 				}
+				state.mu.Unlock()
+
+				// Supersedes a pending read
+				state.debouncer.trigger(debounceTime)
 
-			case err, ok := <-watcher.Errors:
+			case err, ok := <-watcher.Errors():
 				if !ok {
 					return nil
 				}
 				if err != nil && !sctx.IsStopping() {
-					select {
-					case ch <- WatchEvent{Err: err}:
-					case <-sctx.Stopping():
+					if !sender.send(WatchEvent{Err: err}, sctx.Stopping()) {
 						return nil
 					}
 				}
@@ -201,6 +180,10 @@ func (c *ClientRunit) getServiceDir() string {
 	return c.ServiceDir
 }
 
+func (c *ClientRunit) getWatchBuffer() (int, WatchOverflow) {
+	return c.WatchBuffer, c.WatchOverflow
+}
+
 func (c *ClientRunit) getStatusFileSize() int {
 	return StatusFileSize
 }
@@ -209,6 +192,10 @@ func (c *ClientDaemontools) getServiceDir() string {
 	return c.ServiceDir
 }
 
+func (c *ClientDaemontools) getWatchBuffer() (int, WatchOverflow) {
+	return c.WatchBuffer, c.WatchOverflow
+}
+
 func (c *ClientDaemontools) getStatusFileSize() int {
 	return DaemontoolsStatusSize
 }
@@ -217,6 +204,10 @@ func (c *ClientS6) getServiceDir() string {
 	return c.ServiceDir
 }
 
+func (c *ClientS6) getWatchBuffer() (int, WatchOverflow) {
+	return c.WatchBuffer, c.WatchOverflow
+}
+
 func (c *ClientS6) getStatusFileSize() int {
 	return S6MaxStatusSize
 }
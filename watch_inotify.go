@@ -0,0 +1,104 @@
+//go:build linux && svcmgr_inotify
+
+package svcmgr
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"syscall"
+)
+
+// inotifyMask selects the events that can signal a changed file: runsv,
+// supervise and s6-supervise write status files in place or rename a
+// temporary file over them, and create their supervise directories
+const inotifyMask = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+	syscall.IN_MOVED_TO | syscall.IN_ATTRIB | syscall.IN_DELETE
+
+// inotifyWatcher is a fileWatcher on raw inotify syscalls, selected by the
+// svcmgr_inotify build tag. It needs no goroutine beyond its reader and
+// decodes events in a fixed buffer, allocating nothing per event.
+type inotifyWatcher struct {
+	f       *os.File
+	name    string
+	changes chan struct{}
+	errors  chan error
+	done    chan struct{}
+}
+
+// newFileWatcher watches the file name in dir
+func newFileWatcher(dir, name string) (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		_ = syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	// A non-blocking descriptor is registered with the runtime poller, so
+	// Close unblocks a pending Read
+	w := &inotifyWatcher{
+		f:       os.NewFile(uintptr(fd), "inotify"),
+		name:    name,
+		changes: make(chan struct{}, 1),
+		errors:  make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	go w.read()
+	return w, nil
+}
+
+// read decodes events until the watcher is closed
+func (w *inotifyWatcher) read() {
+	defer close(w.done)
+	defer close(w.errors)
+	defer close(w.changes)
+
+	var buf [64 * (syscall.SizeofInotifyEvent + syscall.NAME_MAX + 1)]byte
+	for {
+		n, err := w.f.Read(buf[:])
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				select {
+				case w.errors <- err:
+				default:
+				}
+			}
+			return
+		}
+
+		changed := false
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			name := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+nameLen]
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+			// Events were lost, so the file may have changed
+			if mask&syscall.IN_Q_OVERFLOW != 0 || string(name) == w.name {
+				changed = true
+			}
+			off += syscall.SizeofInotifyEvent + nameLen
+		}
+		if changed {
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (w *inotifyWatcher) Changes() <-chan struct{} { return w.changes }
+
+func (w *inotifyWatcher) Errors() <-chan error { return w.errors }
+
+// Close stops watching and waits for the reader to exit
+func (w *inotifyWatcher) Close() error {
+	err := w.f.Close()
+	<-w.done
+	return err
+}
@@ -0,0 +1,74 @@
+package svcmgr_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axondata/go-svcmgr"
+	"github.com/axondata/go-svcmgr/svcmgrtest"
+)
+
+func TestWatchMany(t *testing.T) {
+	web, db := svcmgrtest.NewFakeClient(), svcmgrtest.NewFakeClient()
+	clients := map[string]svcmgr.ServiceClient{"web": web, "db": db}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// fn is serialized, so the map needs no lock
+	got := make(map[string]svcmgr.State)
+	done := make(chan error, 1)
+	go func() {
+		done <- svcmgr.WatchMany(ctx, clients, func(name string, ev svcmgr.WatchEvent) {
+			got[name] = ev.Status.State
+			if len(got) == 2 {
+				cancel()
+			}
+		})
+	}()
+
+	// Let both watches start before changing the statuses
+	time.Sleep(20 * time.Millisecond)
+	web.SetStatus(svcmgr.Status{State: svcmgr.StateRunning, PID: 10})
+	db.SetStatus(svcmgr.Status{State: svcmgr.StateFinishing})
+
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("WatchMany() = %v, want context.Canceled", err)
+	}
+	if got["web"] != svcmgr.StateRunning || got["db"] != svcmgr.StateFinishing {
+		t.Errorf("events = %v", got)
+	}
+}
+
+// closingWatch is a client whose watch ends after one event
+type closingWatch struct {
+	svcmgr.ServiceClient
+}
+
+func (c closingWatch) Watch(ctx context.Context) (<-chan svcmgr.WatchEvent, svcmgr.WatchCleanupFunc, error) {
+	events := make(chan svcmgr.WatchEvent, 1)
+	events <- svcmgr.WatchEvent{Status: svcmgr.Status{State: svcmgr.StateRunning}}
+	close(events)
+	return events, func() error { return nil }, nil
+}
+
+func TestWatchManyClosed(t *testing.T) {
+	clients := map[string]svcmgr.ServiceClient{
+		"web": closingWatch{svcmgrtest.NewFakeClient()},
+		"db":  closingWatch{svcmgrtest.NewFakeClient()},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events int
+	err := svcmgr.WatchMany(ctx, clients, func(string, svcmgr.WatchEvent) { events++ })
+	if !errors.Is(err, svcmgr.ErrWatchClosed) {
+		t.Fatalf("WatchMany() = %v, want ErrWatchClosed", err)
+	}
+	if events != 2 {
+		t.Errorf("got %d events, want 2", events)
+	}
+}
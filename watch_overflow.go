@@ -0,0 +1,97 @@
+package svcmgr
+
+import "sync"
+
+// DefaultWatchBuffer is the default capacity of Watch channels
+const DefaultWatchBuffer = 10
+
+// WatchOverflow selects what Watch does with an event when its channel's
+// buffer is full because the consumer is slow
+type WatchOverflow int
+
+const (
+	// WatchOverflowBlock waits until the consumer makes room, so no event is
+	// lost but the watcher stalls meanwhile (the default)
+	WatchOverflowBlock WatchOverflow = iota
+	// WatchOverflowDropOldest discards the oldest buffered event to make
+	// room for the new one
+	WatchOverflowDropOldest
+	// WatchOverflowCoalesce discards every buffered event, leaving only the
+	// new one, the latest status
+	WatchOverflowCoalesce
+)
+
+// String returns the policy name
+func (o WatchOverflow) String() string {
+	switch o {
+	case WatchOverflowBlock:
+		return "block"
+	case WatchOverflowDropOldest:
+		return "drop-oldest"
+	case WatchOverflowCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// watchSender delivers events to a Watch channel according to an overflow
+// policy, stamping each with the number of events dropped so far
+type watchSender struct {
+	ch       chan WatchEvent
+	overflow WatchOverflow
+
+	mu      sync.Mutex // serializes senders so drops are counted in order
+	dropped uint64
+}
+
+// newWatchSender creates a sender with a channel of the given capacity;
+// buffer <= 0 means DefaultWatchBuffer
+func newWatchSender(buffer int, overflow WatchOverflow) *watchSender {
+	if buffer <= 0 {
+		buffer = DefaultWatchBuffer
+	}
+	return &watchSender{ch: make(chan WatchEvent, buffer), overflow: overflow}
+}
+
+// send delivers ev, reporting false when stopping was closed before a
+// blocking send completed
+func (s *watchSender) send(ev WatchEvent, stopping <-chan struct{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		ev.Dropped = s.dropped
+		select {
+		case s.ch <- ev:
+			return true
+		default:
+		}
+
+		switch s.overflow {
+		case WatchOverflowDropOldest:
+			// The consumer may take the oldest event itself meanwhile
+			select {
+			case <-s.ch:
+				s.dropped++
+			default:
+			}
+		case WatchOverflowCoalesce:
+			for drained := false; !drained; {
+				select {
+				case <-s.ch:
+					s.dropped++
+				default:
+					drained = true
+				}
+			}
+		default:
+			select {
+			case s.ch <- ev:
+				return true
+			case <-stopping:
+				return false
+			}
+		}
+	}
+}
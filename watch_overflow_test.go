@@ -0,0 +1,79 @@
+package svcmgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchSenderOverflow(t *testing.T) {
+	tests := []struct {
+		overflow    WatchOverflow
+		wantPIDs    []int
+		wantDropped []uint64
+	}{
+		{WatchOverflowDropOldest, []int{3, 4, 5}, []uint64{0, 1, 2}},
+		{WatchOverflowCoalesce, []int{4, 5}, []uint64{3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overflow.String(), func(t *testing.T) {
+			s := newWatchSender(3, tt.overflow)
+			for pid := 1; pid <= 5; pid++ {
+				if !s.send(WatchEvent{Status: Status{PID: pid}}, nil) {
+					t.Fatalf("send %d failed", pid)
+				}
+			}
+			close(s.ch)
+
+			var pids []int
+			var dropped []uint64
+			for ev := range s.ch {
+				pids = append(pids, ev.Status.PID)
+				dropped = append(dropped, ev.Dropped)
+			}
+			if len(pids) != len(tt.wantPIDs) {
+				t.Fatalf("received PIDs %v, want %v", pids, tt.wantPIDs)
+			}
+			for i := range pids {
+				if pids[i] != tt.wantPIDs[i] || dropped[i] != tt.wantDropped[i] {
+					t.Errorf("event %d = PID %d dropped %d, want PID %d dropped %d",
+						i, pids[i], dropped[i], tt.wantPIDs[i], tt.wantDropped[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWatchSenderBlock(t *testing.T) {
+	s := newWatchSender(1, WatchOverflowBlock)
+	if !s.send(WatchEvent{Status: Status{PID: 1}}, nil) {
+		t.Fatal("first send failed")
+	}
+
+	stopping := make(chan struct{})
+	done := make(chan bool)
+	go func() { done <- s.send(WatchEvent{Status: Status{PID: 2}}, stopping) }()
+
+	select {
+	case <-done:
+		t.Fatal("send did not block on a full channel")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if ev := <-s.ch; ev.Status.PID != 1 {
+		t.Errorf("first event PID = %d, want 1", ev.Status.PID)
+	}
+	if !<-done {
+		t.Error("blocked send failed after the consumer made room")
+	}
+
+	go func() { done <- s.send(WatchEvent{Status: Status{PID: 3}}, stopping) }()
+	close(stopping)
+	if <-done {
+		t.Error("send on a full channel succeeded after stopping")
+	}
+}
+
+func TestWatchSenderDefaultBuffer(t *testing.T) {
+	if s := newWatchSender(0, WatchOverflowBlock); cap(s.ch) != DefaultWatchBuffer {
+		t.Errorf("capacity = %d, want %d", cap(s.ch), DefaultWatchBuffer)
+	}
+}
@@ -21,3 +21,8 @@ func (c *ClientDaemontools) Watch(ctx context.Context) (<-chan WatchEvent, Watch
 func (c *ClientS6) Watch(ctx context.Context) (<-chan WatchEvent, WatchCleanupFunc, error) {
 	return nil, nil, errors.New("watch not supported on this platform")
 }
+
+// newFileWatcher is not supported on this platform
+func newFileWatcher(dir, name string) (fileWatcher, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -35,3 +35,33 @@ func (c *ClientS6) Wait(ctx context.Context, states []State) (Status, error) {
 func (c *ClientSystemd) Wait(ctx context.Context, states []State) (Status, error) {
 	return waitImpl(ctx, c, states)
 }
+
+// WaitFunc blocks until pred returns true for the service's status or the
+// context is cancelled, and returns the matching status. The status is
+// re-evaluated on every change and at least once per second, so predicates
+// may depend on Uptime.
+//
+// Example:
+//
+//	// Wait until the service has been up for ten seconds
+//	status, err := client.WaitFunc(ctx, func(s Status) bool {
+//		return s.State == StateRunning && s.Uptime >= 10*time.Second
+//	})
+func (c *ClientRunit) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return waitFuncImpl(ctx, c, pred)
+}
+
+// WaitFunc for ClientDaemontools
+func (c *ClientDaemontools) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return waitFuncImpl(ctx, c, pred)
+}
+
+// WaitFunc for ClientS6
+func (c *ClientS6) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return waitFuncImpl(ctx, c, pred)
+}
+
+// WaitFunc for ClientSystemd
+func (c *ClientSystemd) WaitFunc(ctx context.Context, pred func(Status) bool) (Status, error) {
+	return waitFuncImpl(ctx, c, pred)
+}
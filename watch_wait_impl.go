@@ -4,10 +4,30 @@ package svcmgr
 
 import (
 	"context"
+	"time"
 )
 
+// waitFuncRecheck is how often WaitFunc re-reads the status between watch
+// events, so predicates on time-dependent fields such as Uptime are noticed
+const waitFuncRecheck = time.Second
+
+// statusWatcher is the part of ServiceClient that waiting needs
+type statusWatcher interface {
+	StatusReader
+	Watcher
+}
+
+// watchClosed returns the error of a wait whose watch channel closed:
+// ctx's error when it ended the watch, ErrWatchClosed otherwise
+func watchClosed(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrWatchClosed
+}
+
 // waitImpl provides a common implementation for Wait across all client types
-func waitImpl(ctx context.Context, client ServiceClient, states []State) (Status, error) {
+func waitImpl(ctx context.Context, client statusWatcher, states []State) (Status, error) {
 	// If states is empty, wait for any change
 	if len(states) == 0 {
 		events, cleanup, err := client.Watch(ctx)
@@ -17,7 +37,10 @@ func waitImpl(ctx context.Context, client ServiceClient, states []State) (Status
 		defer func() { _ = cleanup() }()
 
 		select {
-		case event := <-events:
+		case event, ok := <-events:
+			if !ok {
+				return Status{}, watchClosed(ctx)
+			}
 			if event.Err != nil {
 				return Status{}, event.Err
 			}
@@ -47,7 +70,10 @@ func waitImpl(ctx context.Context, client ServiceClient, states []State) (Status
 
 	for {
 		select {
-		case event := <-events:
+		case event, ok := <-events:
+			if !ok {
+				return Status{}, watchClosed(ctx)
+			}
 			if event.Err != nil {
 				return Status{}, event.Err
 			}
@@ -61,3 +87,47 @@ func waitImpl(ctx context.Context, client ServiceClient, states []State) (Status
 		}
 	}
 }
+
+// waitFuncImpl provides a common implementation for WaitFunc across all client types
+func waitFuncImpl(ctx context.Context, client statusWatcher, pred func(Status) bool) (Status, error) {
+	// Start watching before the first read so no change is missed
+	events, cleanup, err := client.Watch(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer func() { _ = cleanup() }()
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	if pred(status) {
+		return status, nil
+	}
+
+	ticker := time.NewTicker(waitFuncRecheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return Status{}, watchClosed(ctx)
+			}
+			if event.Err != nil {
+				return Status{}, event.Err
+			}
+			status = event.Status
+		case <-ticker.C:
+			if status, err = client.Status(ctx); err != nil {
+				return Status{}, err
+			}
+		case <-ctx.Done():
+			return Status{}, ctx.Err()
+		}
+
+		if pred(status) {
+			return status, nil
+		}
+	}
+}
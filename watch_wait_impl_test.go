@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package svcmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// closedWatcher reports a fixed status and a watch that ends at once, as
+// when the supervise directory disappears
+type closedWatcher struct {
+	status Status
+}
+
+func (w closedWatcher) Status(context.Context) (Status, error) { return w.status, nil }
+
+func (w closedWatcher) Watch(context.Context) (<-chan WatchEvent, WatchCleanupFunc, error) {
+	events := make(chan WatchEvent)
+	close(events)
+	return events, func() error { return nil }, nil
+}
+
+func TestWaitWatchClosed(t *testing.T) {
+	client := closedWatcher{status: Status{State: StateDown}}
+	ctx := context.Background()
+
+	if _, err := waitFuncImpl(ctx, client, func(st Status) bool { return st.State == StateRunning }); !errors.Is(err, ErrWatchClosed) {
+		t.Errorf("waitFuncImpl() error = %v, want ErrWatchClosed", err)
+	}
+	if _, err := waitImpl(ctx, client, []State{StateRunning}); !errors.Is(err, ErrWatchClosed) {
+		t.Errorf("waitImpl() error = %v, want ErrWatchClosed", err)
+	}
+	if _, err := waitImpl(ctx, client, nil); !errors.Is(err, ErrWatchClosed) {
+		t.Errorf("waitImpl(any change) error = %v, want ErrWatchClosed", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := waitFuncImpl(canceled, client, func(Status) bool { return false }); !errors.Is(err, context.Canceled) {
+		t.Errorf("waitFuncImpl(canceled) error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,233 @@
+package svcmgr
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Webhook defaults
+const (
+	// DefaultWebhookRetries is the number of retries after a failed delivery
+	DefaultWebhookRetries = 3
+	// DefaultWebhookRetryDelay is the delay before the first retry, doubled
+	// for each further retry
+	DefaultWebhookRetryDelay = time.Second
+	// DefaultWebhookTimeout bounds a single delivery attempt
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the request body as
+// "sha256=<hex>" when WebhookNotifier.Secret is set
+const WebhookSignatureHeader = "X-Svcmgr-Signature"
+
+// WebhookPayload is the JSON body POSTed for a state change
+type WebhookPayload struct {
+	// SchemaVersion is StatusSchemaVersion
+	SchemaVersion int `json:"schema_version"`
+	// Service names the service that changed
+	Service string `json:"service"`
+	// Time is when the change was observed
+	Time time.Time `json:"time"`
+	// Previous is the status before the change
+	Previous Status `json:"previous"`
+	// Current is the status after the change
+	Current Status `json:"current"`
+	// Changes lists the fields that changed
+	Changes []StatusChange `json:"changes"`
+}
+
+// WebhookFilter decides whether a change from prev to next is delivered
+type WebhookFilter func(service string, prev, next Status) bool
+
+// StateChanged delivers changes of State only, ignoring PID and flag changes
+func StateChanged(_ string, prev, next Status) bool {
+	return prev.State != next.State
+}
+
+// EnteredStates delivers changes into one of states
+func EnteredStates(states ...State) WebhookFilter {
+	return func(_ string, prev, next Status) bool {
+		return prev.State != next.State && slices.Contains(states, next.State)
+	}
+}
+
+// WebhookNotifier POSTs a WebhookPayload to every URL when a watched
+// service changes state, so chat and paging integrations need no custom
+// watch loop. Failed deliveries are retried with exponential backoff.
+type WebhookNotifier struct {
+	// URLs receive every delivered change
+	URLs []string
+	// Secret, when set, signs each body with HMAC-SHA256 in WebhookSignatureHeader
+	Secret []byte
+	// Filter selects the changes delivered; nil means StateChanged
+	Filter WebhookFilter
+	// Retries is the number of retries after a failed delivery
+	Retries int
+	// RetryDelay is the delay before the first retry
+	RetryDelay time.Duration
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration
+	// Client sends the requests; nil uses http.DefaultClient
+	Client *http.Client
+	// Clock timestamps payloads; nil uses the wall clock
+	Clock Clock
+}
+
+// NewWebhookNotifier creates a notifier delivering to urls with default retries
+func NewWebhookNotifier(urls ...string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URLs:       urls,
+		Retries:    DefaultWebhookRetries,
+		RetryDelay: DefaultWebhookRetryDelay,
+		Timeout:    DefaultWebhookTimeout,
+	}
+}
+
+// Watch watches every client, keyed by service name, and delivers the
+// changes passing Filter until ctx is done, returning ctx.Err(), or
+// ErrWatchClosed if every watch ended first. Delivery failures after all
+// retries are dropped so one unreachable endpoint does not stall the
+// others; use Notify directly to observe them.
+func (n *WebhookNotifier) Watch(ctx context.Context, clients map[string]ServiceClient) error {
+	return watchAll(ctx, clients, func(name string, prev Status, ev WatchEvent) {
+		if ev.Err == nil && n.accept(name, prev, ev.Status) {
+			_ = n.Notify(ctx, n.Payload(name, prev, ev.Status))
+		}
+	})
+}
+
+// Payload builds the payload for a change of service from prev to next
+func (n *WebhookNotifier) Payload(service string, prev, next Status) WebhookPayload {
+	return WebhookPayload{
+		SchemaVersion: StatusSchemaVersion,
+		Service:       service,
+		Time:          clockNow(n.Clock),
+		Previous:      prev,
+		Current:       next,
+		Changes:       prev.Diff(next),
+	}
+}
+
+// Notify delivers p to every URL, retrying failures. Errors for URLs that
+// still fail after all retries are collected into a MultiError.
+func (n *WebhookNotifier) Notify(ctx context.Context, p WebhookPayload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, body)
+}
+
+// NotifyAlert delivers a fired alert to every URL like Notify
+func (n *WebhookNotifier) NotifyAlert(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, body)
+}
+
+// send delivers body to every URL concurrently
+func (n *WebhookNotifier) send(ctx context.Context, body []byte) error {
+	var (
+		mu   sync.Mutex
+		errs MultiError
+		wg   sync.WaitGroup
+	)
+	for _, url := range n.URLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.deliver(ctx, url, body); err != nil {
+				mu.Lock()
+				errs.Add(err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs.Err()
+}
+
+// accept applies the filter
+func (n *WebhookNotifier) accept(service string, prev, next Status) bool {
+	if n.Filter == nil {
+		return StateChanged(service, prev, next)
+	}
+	return n.Filter(service, prev, next)
+}
+
+// deliver POSTs body to url, retrying with exponential backoff
+func (n *WebhookNotifier) deliver(ctx context.Context, url string, body []byte) error {
+	delay := n.RetryDelay
+	if delay <= 0 {
+		delay = DefaultWebhookRetryDelay
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = n.post(ctx, url, body); err == nil {
+			return nil
+		}
+		if attempt >= n.Retries {
+			return fmt.Errorf("webhook %s: %w", url, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook %s: %w", url, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes a single delivery attempt; any non-2xx response is a failure
+func (n *WebhookNotifier) post(ctx context.Context, url string, body []byte) error {
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(n.Secret, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for body, for
+// receivers verifying deliveries with hmac.Equal
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
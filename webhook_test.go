@@ -0,0 +1,116 @@
+package svcmgr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	secret := []byte("s3cret")
+	var calls atomic.Int32
+	var got WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt to exercise retries
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(WebhookSignatureHeader); sig != SignWebhook(secret, body) {
+			t.Errorf("signature = %q", sig)
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL)
+	n.Secret = secret
+	n.RetryDelay = time.Millisecond
+	n.Clock = FixedClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	prev := Status{State: StateRunning, PID: 10}
+	next := Status{State: StateDown}
+	if err := n.Notify(context.Background(), n.Payload("web", prev, next)); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("delivered in %d attempts, want 2", calls.Load())
+	}
+	if got.Service != "web" || got.Current.State != StateDown || got.Previous.PID != 10 || len(got.Changes) == 0 {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestWebhookNotifierGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL)
+	n.Retries = 1
+	n.RetryDelay = time.Millisecond
+	err := n.Notify(context.Background(), n.Payload("web", Status{}, Status{State: StateRunning}))
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 1 {
+		t.Errorf("Notify = %v, want MultiError with one failure", err)
+	}
+}
+
+func TestWebhookNotifierAttemptTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	n := NewWebhookNotifier(srv.URL)
+	n.Retries = 1
+	n.RetryDelay = time.Millisecond
+	n.Timeout = 50 * time.Millisecond
+
+	// The caller's deadline does not replace the per-attempt Timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	err := n.Notify(ctx, n.Payload("web", Status{}, Status{State: StateRunning}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Notify = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("two attempts took %v with a 50ms Timeout", elapsed)
+	}
+}
+
+func TestWebhookFilters(t *testing.T) {
+	running := Status{State: StateRunning, PID: 1}
+	tests := []struct {
+		name       string
+		filter     WebhookFilter
+		prev, next Status
+		want       bool
+	}{
+		{"state changed", StateChanged, running, Status{State: StateDown}, true},
+		{"pid only", StateChanged, running, Status{State: StateRunning, PID: 2}, false},
+		{"entered crashed", EnteredStates(StateCrashed), running, Status{State: StateCrashed}, true},
+		{"entered other", EnteredStates(StateCrashed), running, Status{State: StateDown}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter("web", tt.prev, tt.next); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}